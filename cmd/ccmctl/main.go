@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/klogr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/apis"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/ccmctl"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba"
+)
+
+const usage = `ccmctl inspects and repairs the loadbalancers of services.
+
+Usage:
+  ccmctl [flags] describe <namespace/name>          show the clb or nlb of the service with its listeners and server groups
+  ccmctl [flags] diff <namespace/name>              show the changes a reconcile of the service would make
+  ccmctl [flags] orphans                            list the loadbalancers and server groups of the cluster not owned by any service
+  ccmctl [flags] remove-finalizer <namespace/name>  remove the finalizers of the service if its loadbalancer is cleaned up
+  ccmctl [flags] adopt <namespace/name> <lb-id>     make the loadbalancer managed by the service
+
+Flags:
+`
+
+func main() {
+	klog.InitFlags(nil)
+	fs := pflag.NewFlagSet("ccmctl", pflag.ExitOnError)
+	fs.AddGoFlagSet(flag.CommandLine)
+	ctrlCfg.ControllerCFG.BindFlags(fs)
+	output := fs.StringP("output", "o", ccmctl.OutputText, "The output format of diff and orphans, one of text, json and yaml.")
+	force := fs.Bool("force", false, "Skip the safety checks of remove-finalizer and adopt.")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		exit(err)
+	}
+	ctrl.SetLogger(klogr.New())
+
+	args := fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	switch *output {
+	case ccmctl.OutputText, ccmctl.OutputJSON, ccmctl.OutputYAML:
+	default:
+		exit(fmt.Errorf("unknown output format %q", *output))
+	}
+
+	if err := ctrlCfg.CloudCFG.LoadCloudCFG(); err != nil {
+		exit(fmt.Errorf("load cloud config: %s", err.Error()))
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		exit(err)
+	}
+	if err := apis.AddToScheme(scheme); err != nil {
+		exit(err)
+	}
+	kubeClient, err := client.New(config.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		exit(fmt.Errorf("new kube client: %s", err.Error()))
+	}
+
+	ctl, err := ccmctl.New(kubeClient, alibaba.NewAlibabaCloud(), os.Stdout)
+	if err != nil {
+		exit(err)
+	}
+	ctl.Output = *output
+	ctl.Force = *force
+
+	if err := run(context.Background(), ctl, args); err != nil {
+		exit(err)
+	}
+}
+
+func run(ctx context.Context, ctl *ccmctl.Ctl, args []string) error {
+	expect := func(n int) error {
+		if len(args) != n+1 {
+			return fmt.Errorf("%s expects %d arguments, got %d", args[0], n, len(args)-1)
+		}
+		return nil
+	}
+	switch args[0] {
+	case "describe":
+		if err := expect(1); err != nil {
+			return err
+		}
+		return ctl.Describe(ctx, args[1])
+	case "diff":
+		if err := expect(1); err != nil {
+			return err
+		}
+		return ctl.Diff(ctx, args[1])
+	case "orphans":
+		if err := expect(0); err != nil {
+			return err
+		}
+		return ctl.Orphans(ctx)
+	case "remove-finalizer":
+		if err := expect(1); err != nil {
+			return err
+		}
+		return ctl.RemoveFinalizers(ctx, args[1])
+	case "adopt":
+		if err := expect(2); err != nil {
+			return err
+		}
+		return ctl.Adopt(ctx, args[1], args[2])
+	}
+	return fmt.Errorf("unknown command %q, run ccmctl --help for usage", args[0])
+}
+
+func exit(err error) {
+	fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+	os.Exit(1)
+}
@@ -1,26 +1,111 @@
 package health
 
-var (
-	CRDReady      bool // CRDReady
-	CheckFuncList = []Checker{
-		&DefaultHealthCheck{}, &CustomizeHealthCheck{},
-	}
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
+// Checker is a health check registered by a subsystem
 type Checker interface {
 	Check() error
 }
 
-type DefaultHealthCheck struct {
+// CheckerFunc adapts a function to a Checker
+type CheckerFunc func() error
+
+func (f CheckerFunc) Check() error {
+	return f()
 }
 
-func (dh *DefaultHealthCheck) Check() error {
-	return nil
+var (
+	lock            sync.Mutex
+	livenessChecks  = map[string]Checker{}
+	readinessChecks = map[string]Checker{}
+)
+
+// RegisterLiveness registers a check of /healthz, the controller is restarted if the check fails.
+func RegisterLiveness(name string, c Checker) {
+	lock.Lock()
+	defer lock.Unlock()
+	livenessChecks[name] = c
 }
 
-type CustomizeHealthCheck struct {
+// RegisterReadiness registers a check of /readyz.
+func RegisterReadiness(name string, c Checker) {
+	lock.Lock()
+	defer lock.Unlock()
+	readinessChecks[name] = c
 }
 
-func (ch *CustomizeHealthCheck) Check() error {
+// AddToManager adds the registered checks to the manager. Every check is listed by /healthz?verbose
+// and /readyz?verbose, and the reason of a failed check is served at /healthz/<name> or /readyz/<name>.
+func AddToManager(mgr manager.Manager) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	// the endpoints are served only if they have checks
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
+		return err
+	}
+	for _, name := range sortedNames(livenessChecks) {
+		if err := mgr.AddHealthzCheck(name, toHealthz(livenessChecks[name])); err != nil {
+			return fmt.Errorf("add healthz check %s: %s", name, err.Error())
+		}
+	}
+	for _, name := range sortedNames(readinessChecks) {
+		if err := mgr.AddReadyzCheck(name, toHealthz(readinessChecks[name])); err != nil {
+			return fmt.Errorf("add readyz check %s: %s", name, err.Error())
+		}
+	}
 	return nil
 }
+
+func toHealthz(c Checker) healthz.Checker {
+	return func(_ *http.Request) error {
+		return c.Check()
+	}
+}
+
+func sortedNames(checks map[string]Checker) []string {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type cachedChecker struct {
+	checker Checker
+	ttl     time.Duration
+
+	lock      sync.Mutex
+	checkTime time.Time
+	err       error
+}
+
+// NewCachedChecker caches the result of the checker for ttl, it is used by the checks calling the
+// apis which are rate limited, e.g. the cloud apis.
+func NewCachedChecker(c Checker, ttl time.Duration) Checker {
+	return &cachedChecker{checker: c, ttl: ttl}
+}
+
+func (c *cachedChecker) Check() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.checkTime.IsZero() && time.Since(c.checkTime) < c.ttl {
+		return c.err
+	}
+	c.err = c.checker.Check()
+	c.checkTime = time.Now()
+	return c.err
+}
@@ -0,0 +1,167 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+type fakeManager struct {
+	manager.Manager
+	healthz map[string]healthz.Checker
+	readyz  map[string]healthz.Checker
+}
+
+func newFakeManager() *fakeManager {
+	return &fakeManager{healthz: map[string]healthz.Checker{}, readyz: map[string]healthz.Checker{}}
+}
+
+func (m *fakeManager) AddHealthzCheck(name string, check healthz.Checker) error {
+	if _, ok := m.healthz[name]; ok {
+		return fmt.Errorf("healthz checker %s already exists", name)
+	}
+	m.healthz[name] = check
+	return nil
+}
+
+func (m *fakeManager) AddReadyzCheck(name string, check healthz.Checker) error {
+	if _, ok := m.readyz[name]; ok {
+		return fmt.Errorf("readyz checker %s already exists", name)
+	}
+	m.readyz[name] = check
+	return nil
+}
+
+func resetChecks(t *testing.T) {
+	lock.Lock()
+	defer lock.Unlock()
+	liveness, readiness := livenessChecks, readinessChecks
+	livenessChecks, readinessChecks = map[string]Checker{}, map[string]Checker{}
+	t.Cleanup(func() {
+		lock.Lock()
+		defer lock.Unlock()
+		livenessChecks, readinessChecks = liveness, readiness
+	})
+}
+
+func TestAddToManager(t *testing.T) {
+	failed := fmt.Errorf("failed")
+	cases := []struct {
+		name         string
+		liveness     map[string]error
+		readiness    map[string]error
+		expectHealth map[string]error
+		expectReady  map[string]error
+		expectErr    bool
+	}{
+		{
+			name:         "ping only",
+			expectHealth: map[string]error{"ping": nil},
+			expectReady:  map[string]error{"ping": nil},
+		},
+		{
+			name:         "registered checks",
+			liveness:     map[string]error{"workqueue": nil},
+			readiness:    map[string]error{"cloud-token": failed, "albconfig-crd": nil},
+			expectHealth: map[string]error{"ping": nil, "workqueue": nil},
+			expectReady:  map[string]error{"ping": nil, "cloud-token": failed, "albconfig-crd": nil},
+		},
+		{
+			name:      "check conflicting with ping",
+			readiness: map[string]error{"ping": nil},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resetChecks(t)
+			for name, err := range c.liveness {
+				err := err
+				RegisterLiveness(name, CheckerFunc(func() error { return err }))
+			}
+			for name, err := range c.readiness {
+				err := err
+				RegisterReadiness(name, CheckerFunc(func() error { return err }))
+			}
+
+			mgr := newFakeManager()
+			err := AddToManager(mgr)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			check := func(checks map[string]healthz.Checker) map[string]error {
+				ret := make(map[string]error)
+				for name, check := range checks {
+					ret[name] = check(&http.Request{})
+				}
+				return ret
+			}
+			assert.Equal(t, c.expectHealth, check(mgr.healthz))
+			assert.Equal(t, c.expectReady, check(mgr.readyz))
+		})
+	}
+}
+
+func TestRegisterReplacesCheck(t *testing.T) {
+	resetChecks(t)
+	RegisterReadiness("cloud-api", CheckerFunc(func() error { return fmt.Errorf("old") }))
+	RegisterReadiness("cloud-api", CheckerFunc(func() error { return nil }))
+	RegisterReadiness("cloud-token", CheckerFunc(func() error { return nil }))
+
+	assert.Equal(t, []string{"cloud-api", "cloud-token"}, sortedNames(readinessChecks))
+	assert.NoError(t, readinessChecks["cloud-api"].Check())
+	assert.Empty(t, livenessChecks)
+}
+
+func TestCachedChecker(t *testing.T) {
+	cases := []struct {
+		name        string
+		ttl         time.Duration
+		results     []error
+		expectCalls int
+		expect      []error
+	}{
+		{
+			name:        "result is cached within ttl",
+			ttl:         time.Hour,
+			results:     []error{fmt.Errorf("unreachable"), nil},
+			expectCalls: 1,
+			expect:      []error{fmt.Errorf("unreachable"), fmt.Errorf("unreachable")},
+		},
+		{
+			name:        "result expires after ttl",
+			ttl:         0,
+			results:     []error{fmt.Errorf("unreachable"), nil},
+			expectCalls: 2,
+			expect:      []error{fmt.Errorf("unreachable"), nil},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := 0
+			checker := NewCachedChecker(CheckerFunc(func() error {
+				err := c.results[calls]
+				calls++
+				return err
+			}), c.ttl)
+			var errs []error
+			for range c.results {
+				errs = append(errs, checker.Check())
+			}
+			assert.Equal(t, c.expectCalls, calls)
+			assert.Equal(t, c.expect, errs)
+		})
+	}
+}
+
+func TestSortedNames(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, sortedNames(map[string]Checker{"b": nil, "c": nil, "a": nil}))
+	assert.Empty(t, sortedNames(map[string]Checker{}))
+}
@@ -0,0 +1,53 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultWorkqueueStuckThreshold is how long a worker can process an item before the workqueue
+	// is considered stuck, which is much longer than the slowest reconcile, e.g. creating an alb.
+	DefaultWorkqueueStuckThreshold = 30 * time.Minute
+
+	longestRunningProcessorMetric = "workqueue_longest_running_processor_seconds"
+)
+
+// NewWorkqueueChecker returns a check failing if a worker of a workqueue has been processing an item
+// longer than threshold, the workqueues are observed by the metrics in the gatherer.
+func NewWorkqueueChecker(gatherer prometheus.Gatherer, threshold time.Duration) Checker {
+	return CheckerFunc(func() error {
+		families, err := gatherer.Gather()
+		if err != nil {
+			return fmt.Errorf("gather workqueue metrics: %s", err.Error())
+		}
+		var stuck []string
+		for _, family := range families {
+			if family.GetName() != longestRunningProcessorMetric {
+				continue
+			}
+			for _, m := range family.GetMetric() {
+				seconds := m.GetGauge().GetValue()
+				if seconds <= threshold.Seconds() {
+					continue
+				}
+				name := ""
+				for _, l := range m.GetLabel() {
+					if l.GetName() == "name" {
+						name = l.GetValue()
+					}
+				}
+				stuck = append(stuck, fmt.Sprintf("%s(%s)", name, time.Duration(seconds)*time.Second))
+			}
+		}
+		if len(stuck) != 0 {
+			sort.Strings(stuck)
+			return fmt.Errorf("workqueues stuck longer than %s: %s", threshold, strings.Join(stuck, ", "))
+		}
+		return nil
+	})
+}
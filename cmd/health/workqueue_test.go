@@ -0,0 +1,66 @@
+package health
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkqueueChecker(t *testing.T) {
+	cases := []struct {
+		name      string
+		processor map[string]float64
+		otherName string
+		expectErr string
+	}{
+		{
+			name: "no workqueue",
+		},
+		{
+			name:      "workqueues within threshold",
+			processor: map[string]float64{"albconfig": 60, "service": 0},
+		},
+		{
+			name:      "stuck workqueues are listed by name",
+			processor: map[string]float64{"service": 3600, "albconfig": 1801.5, "node": 10},
+			expectErr: "workqueues stuck longer than 30m0s: albconfig(30m1s), service(1h0m0s)",
+		},
+		{
+			name:      "other metrics are ignored",
+			otherName: "workqueue_unfinished_work_seconds",
+			processor: map[string]float64{"service": 3600},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			metricName := longestRunningProcessorMetric
+			if c.otherName != "" {
+				metricName = c.otherName
+			}
+			registry := prometheus.NewRegistry()
+			gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: metricName}, []string{"name"})
+			registry.MustRegister(gauge)
+			for name, seconds := range c.processor {
+				gauge.WithLabelValues(name).Set(seconds)
+			}
+
+			err := NewWorkqueueChecker(registry, DefaultWorkqueueStuckThreshold).Check()
+			if c.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, c.expectErr)
+		})
+	}
+}
+
+func TestWorkqueueCheckerGatherError(t *testing.T) {
+	gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return nil, fmt.Errorf("duplicate metrics")
+	})
+	assert.EqualError(t, NewWorkqueueChecker(gatherer, time.Minute).Check(), "gather workqueue metrics: duplicate metrics")
+}
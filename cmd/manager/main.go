@@ -1,12 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"runtime"
 
-	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/apis"
@@ -14,12 +13,14 @@ import (
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	"k8s.io/cloud-provider-alibaba-cloud/version"
 	"k8s.io/klog/v2/klogr"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"k8s.io/cloud-provider-alibaba-cloud/cmd/health"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
@@ -45,6 +46,13 @@ func main() {
 	}
 	ctrl.SetLogger(klogr.New().V(ctrlCfg.ControllerCFG.LogLevel))
 
+	shutdownTracing, err := tracing.Setup(ctrlCfg.ControllerCFG.TracingEndpoint,
+		ctrlCfg.ControllerCFG.TracingSampleRatio, ctrlCfg.CloudCFG.Global.ClusterID)
+	if err != nil {
+		log.Error(err, "unable to setup tracing")
+		os.Exit(1)
+	}
+
 	// Get a config to talk to the api-server
 	cfg := config.GetConfigOrDie()
 	cfg.QPS = ctrlCfg.ControllerCFG.RuntimeConfig.QPS
@@ -85,20 +93,18 @@ func main() {
 
 	// Start the Cmd
 	log.Info("Starting the Cmd.")
-	if err := mgr.AddHealthzCheck("default", func(req *http.Request) error {
-		errs := make([]error, 0)
-		for _, fun := range health.CheckFuncList {
-			if err := fun.Check(); err != nil {
-				errs = append(errs, err)
-			}
-		}
-		return utilerrors.NewAggregate(errs)
-	}); err != nil {
-		log.Error(err, "failed to add default health check: %w", err.Error())
+	health.RegisterLiveness("workqueue", health.NewWorkqueueChecker(metrics.Registry, health.DefaultWorkqueueStuckThreshold))
+	if err := health.AddToManager(mgr); err != nil {
+		log.Error(err, "failed to add health checks")
 		os.Exit(1)
 	}
 
-	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
+	err = mgr.Start(signals.SetupSignalHandler())
+	// flush the spans not exported yet
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Error(err, "fail to shutdown tracing")
+	}
+	if err != nil {
 		log.Error(err, "Manager exited non-zero: %s", err.Error())
 		os.Exit(1)
 	}
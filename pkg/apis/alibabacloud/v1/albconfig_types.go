@@ -65,8 +65,44 @@ type IngressStatus struct {
 	// LoadBalancer contains the current status of the load-balancer.
 	// +optional
 	LoadBalancer LoadBalancerStatus `json:"loadBalancer,omitempty" protobuf:"bytes,1,opt,name=loadBalancer"`
+
+	// ObservedGeneration is the generation of the AlbConfig the status was computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,2,opt,name=observedGeneration"`
+
+	// Conditions describe the result of the latest reconcile, see the AlbConfigCondition* types.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,3,rep,name=conditions"`
+
+	// Listeners contains the current status of the listeners.
+	// +optional
+	Listeners []ListenerStatus `json:"listeners,omitempty" protobuf:"bytes,4,rep,name=listeners"`
+
+	// LastError is the error of the latest failed reconcile, it is cleared once a reconcile succeeds.
+	// +optional
+	LastError string `json:"lastError,omitempty" protobuf:"bytes,5,opt,name=lastError"`
+}
+
+// ListenerStatus represents the status of a listener.
+type ListenerStatus struct {
+	Port       int32  `json:"port" protobuf:"varint,1,opt,name=port"`
+	Protocol   string `json:"protocol" protobuf:"bytes,2,opt,name=protocol"`
+	ListenerId string `json:"listenerId,omitempty" protobuf:"bytes,3,opt,name=listenerId"`
 }
 
+const (
+	// AlbConfigConditionReady is True when all the resources of the AlbConfig are applied.
+	AlbConfigConditionReady = "Ready"
+	// AlbConfigConditionLoadBalancerProvisioned is True when the load balancer is created or reused.
+	AlbConfigConditionLoadBalancerProvisioned = "LoadBalancerProvisioned"
+	// AlbConfigConditionListenersSynced is True when the listeners are applied.
+	AlbConfigConditionListenersSynced = "ListenersSynced"
+	// AlbConfigConditionRulesSynced is True when the server groups and listener rules are applied.
+	AlbConfigConditionRulesSynced = "RulesSynced"
+)
+
 // LoadBalancer is a nested struct in alb response
 type LoadBalancerSpec struct {
 	Id                           string                       `json:"id" protobuf:"bytes,1,opt,name=id"`
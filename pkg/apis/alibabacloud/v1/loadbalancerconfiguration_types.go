@@ -0,0 +1,171 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&LoadBalancerConfiguration{}, &LoadBalancerConfigurationList{})
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=lbconfig
+
+// LoadBalancerConfiguration is the typed alternative to the annotations of LoadBalancer services. A
+// service references the configuration in its namespace by the
+// service.beta.kubernetes.io/alibaba-cloud-loadbalancer-configuration annotation, and the annotations
+// of the service take precedence over the configuration.
+type LoadBalancerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Spec is the configuration of the loadbalancers of the services.
+	// +optional
+	Spec LoadBalancerConfigurationSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LoadBalancerConfigurationList is a collection of LoadBalancerConfiguration.
+type LoadBalancerConfigurationList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Items is the list of LoadBalancerConfiguration.
+	Items []LoadBalancerConfiguration `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// LoadBalancerConfigurationSpec describes the clb or nlb of the services. An unset field is left to the
+// annotations of the service and the defaults of the controller.
+type LoadBalancerConfigurationSpec struct {
+	// +optional
+	LoadBalancer *ServiceLoadBalancerSpec `json:"loadBalancer,omitempty" protobuf:"bytes,1,opt,name=loadBalancer"`
+	// +optional
+	Listener *ServiceListenerSpec `json:"listener,omitempty" protobuf:"bytes,2,opt,name=listener"`
+	// +optional
+	HealthCheck *ServiceHealthCheckSpec `json:"healthCheck,omitempty" protobuf:"bytes,3,opt,name=healthCheck"`
+	// +optional
+	Backend *ServiceBackendSpec `json:"backend,omitempty" protobuf:"bytes,4,opt,name=backend"`
+}
+
+// ServiceLoadBalancerSpec is the attributes of the loadbalancer instance
+type ServiceLoadBalancerSpec struct {
+	// +kubebuilder:validation:Enum=internet;intranet
+	AddressType string `json:"addressType,omitempty" protobuf:"bytes,1,opt,name=addressType"`
+	// +kubebuilder:validation:Enum=ipv4;ipv6;dualstack
+	IPVersion string `json:"ipVersion,omitempty" protobuf:"bytes,2,opt,name=ipVersion"`
+	// Spec is the spec of a clb paid by spec, e.g. slb.s1.small
+	Spec string `json:"spec,omitempty" protobuf:"bytes,3,opt,name=spec"`
+	// +kubebuilder:validation:Enum=PayBySpec;PayByCLCU
+	InstanceChargeType string `json:"instanceChargeType,omitempty" protobuf:"bytes,4,opt,name=instanceChargeType"`
+	// +kubebuilder:validation:Enum=paybytraffic;paybybandwidth
+	ChargeType string `json:"chargeType,omitempty" protobuf:"bytes,5,opt,name=chargeType"`
+	// +kubebuilder:validation:Minimum=1
+	Bandwidth       *int   `json:"bandwidth,omitempty" protobuf:"varint,6,opt,name=bandwidth"`
+	VSwitchId       string `json:"vSwitchId,omitempty" protobuf:"bytes,7,opt,name=vSwitchId"`
+	ResourceGroupId string `json:"resourceGroupId,omitempty" protobuf:"bytes,8,opt,name=resourceGroupId"`
+	MasterZoneId    string `json:"masterZoneId,omitempty" protobuf:"bytes,9,opt,name=masterZoneId"`
+	SlaveZoneId     string `json:"slaveZoneId,omitempty" protobuf:"bytes,10,opt,name=slaveZoneId"`
+	// ZoneMappings are the zones and vswitches of a nlb
+	ZoneMappings []ZoneMapping `json:"zoneMappings,omitempty" protobuf:"bytes,11,rep,name=zoneMappings"`
+	// SecurityGroupIds are the security groups of a nlb
+	SecurityGroupIds   []string `json:"securityGroupIds,omitempty" protobuf:"bytes,12,rep,name=securityGroupIds"`
+	CrossZoneEnabled   *bool    `json:"crossZoneEnabled,omitempty" protobuf:"varint,13,opt,name=crossZoneEnabled"`
+	DeletionProtection *bool    `json:"deletionProtection,omitempty" protobuf:"varint,14,opt,name=deletionProtection"`
+	// +kubebuilder:validation:Enum=ConsoleProtection;NonProtection
+	ModificationProtection string `json:"modificationProtection,omitempty" protobuf:"bytes,15,opt,name=modificationProtection"`
+	// AdditionalTags are added to the loadbalancer
+	AdditionalTags map[string]string `json:"additionalTags,omitempty" protobuf:"bytes,16,rep,name=additionalTags"`
+}
+
+// ServiceListenerSpec is the attributes shared by the listeners of the loadbalancer
+type ServiceListenerSpec struct {
+	// Scheduler is the scheduling algorithm, e.g. rr or wrr
+	Scheduler string `json:"scheduler,omitempty" protobuf:"bytes,1,opt,name=scheduler"`
+	AclStatus *bool  `json:"aclStatus,omitempty" protobuf:"varint,2,opt,name=aclStatus"`
+	AclId     string `json:"aclId,omitempty" protobuf:"bytes,3,opt,name=aclId"`
+	// +kubebuilder:validation:Enum=white;black
+	AclType string `json:"aclType,omitempty" protobuf:"bytes,4,opt,name=aclType"`
+	// ProtocolPorts are the protocols of the listeners by port, e.g. https on 443
+	ProtocolPorts   []ProtocolPort `json:"protocolPorts,omitempty" protobuf:"bytes,5,rep,name=protocolPorts"`
+	CertId          string         `json:"certId,omitempty" protobuf:"bytes,6,opt,name=certId"`
+	TLSCipherPolicy string         `json:"tlsCipherPolicy,omitempty" protobuf:"bytes,7,opt,name=tlsCipherPolicy"`
+	Http2Enabled    *bool          `json:"http2Enabled,omitempty" protobuf:"varint,8,opt,name=http2Enabled"`
+	ProxyProtocol   *bool          `json:"proxyProtocol,omitempty" protobuf:"varint,9,opt,name=proxyProtocol"`
+	// +kubebuilder:validation:Minimum=1
+	IdleTimeout *int `json:"idleTimeout,omitempty" protobuf:"varint,10,opt,name=idleTimeout"`
+	// +kubebuilder:validation:Minimum=1
+	RequestTimeout *int `json:"requestTimeout,omitempty" protobuf:"varint,11,opt,name=requestTimeout"`
+	// +kubebuilder:validation:Minimum=10
+	EstablishedTimeout *int `json:"establishedTimeout,omitempty" protobuf:"varint,12,opt,name=establishedTimeout"`
+	// +kubebuilder:validation:Minimum=0
+	PersistenceTimeout *int  `json:"persistenceTimeout,omitempty" protobuf:"varint,13,opt,name=persistenceTimeout"`
+	ConnectionDrain    *bool `json:"connectionDrain,omitempty" protobuf:"varint,14,opt,name=connectionDrain"`
+	// +kubebuilder:validation:Minimum=10
+	ConnectionDrainTimeout *int  `json:"connectionDrainTimeout,omitempty" protobuf:"varint,15,opt,name=connectionDrainTimeout"`
+	StickySession          *bool `json:"stickySession,omitempty" protobuf:"varint,16,opt,name=stickySession"`
+	// +kubebuilder:validation:Enum=insert;server
+	StickySessionType string `json:"stickySessionType,omitempty" protobuf:"bytes,17,opt,name=stickySessionType"`
+	Cookie            string `json:"cookie,omitempty" protobuf:"bytes,18,opt,name=cookie"`
+	// +kubebuilder:validation:Minimum=1
+	CookieTimeout      *int  `json:"cookieTimeout,omitempty" protobuf:"varint,19,opt,name=cookieTimeout"`
+	XForwardedForProto *bool `json:"xForwardedForProto,omitempty" protobuf:"varint,20,opt,name=xForwardedForProto"`
+}
+
+// ProtocolPort is the protocol of the listener on the port
+type ProtocolPort struct {
+	// +kubebuilder:validation:Enum=tcp;udp;http;https;tcpssl
+	Protocol string `json:"protocol" protobuf:"bytes,1,opt,name=protocol"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port" protobuf:"varint,2,opt,name=port"`
+}
+
+// ServiceHealthCheckSpec is the health check of the listeners or server groups
+type ServiceHealthCheckSpec struct {
+	// Enabled switches the health check of the listeners of a clb and the server groups of a nlb
+	Enabled *bool `json:"enabled,omitempty" protobuf:"varint,1,opt,name=enabled"`
+	// +kubebuilder:validation:Enum=tcp;http
+	Type   string `json:"type,omitempty" protobuf:"bytes,2,opt,name=type"`
+	URI    string `json:"uri,omitempty" protobuf:"bytes,3,opt,name=uri"`
+	Domain string `json:"domain,omitempty" protobuf:"bytes,4,opt,name=domain"`
+	// +kubebuilder:validation:Enum=head;get
+	Method string `json:"method,omitempty" protobuf:"bytes,5,opt,name=method"`
+	// HTTPCodes are the healthy http codes, e.g. http_2xx
+	HTTPCodes []string `json:"httpCodes,omitempty" protobuf:"bytes,6,rep,name=httpCodes"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ConnectPort *int `json:"connectPort,omitempty" protobuf:"varint,7,opt,name=connectPort"`
+	// +kubebuilder:validation:Minimum=1
+	ConnectTimeout *int `json:"connectTimeout,omitempty" protobuf:"varint,8,opt,name=connectTimeout"`
+	// +kubebuilder:validation:Minimum=1
+	Timeout *int `json:"timeout,omitempty" protobuf:"varint,9,opt,name=timeout"`
+	// +kubebuilder:validation:Minimum=1
+	Interval *int `json:"interval,omitempty" protobuf:"varint,10,opt,name=interval"`
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=10
+	HealthyThreshold *int `json:"healthyThreshold,omitempty" protobuf:"varint,11,opt,name=healthyThreshold"`
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=10
+	UnhealthyThreshold *int `json:"unhealthyThreshold,omitempty" protobuf:"varint,12,opt,name=unhealthyThreshold"`
+}
+
+// ServiceBackendSpec is the backends added to the server groups
+type ServiceBackendSpec struct {
+	// NodeSelector selects the nodes added as backends by their labels
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" protobuf:"bytes,1,rep,name=nodeSelector"`
+	// RemoveUnscheduled removes the unschedulable nodes from the backends
+	RemoveUnscheduled *bool `json:"removeUnscheduled,omitempty" protobuf:"varint,2,opt,name=removeUnscheduled"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight             *int  `json:"weight,omitempty" protobuf:"varint,3,opt,name=weight"`
+	IgnoreWeightUpdate *bool `json:"ignoreWeightUpdate,omitempty" protobuf:"varint,4,opt,name=ignoreWeightUpdate"`
+	PreserveClientIp   *bool `json:"preserveClientIp,omitempty" protobuf:"varint,5,opt,name=preserveClientIp"`
+}
@@ -6,6 +6,7 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -106,6 +107,33 @@ func (in *AlbConfigSpec) DeepCopy() *AlbConfigSpec {
 func (in *IngressStatus) DeepCopyInto(out *IngressStatus) {
 	*out = *in
 	in.LoadBalancer.DeepCopyInto(&out.LoadBalancer)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]ListenerStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerStatus) DeepCopyInto(out *ListenerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatus.
+func (in *ListenerStatus) DeepCopy() *ListenerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -123,3 +151,320 @@ func (in *IngressStatus) DeepCopy() *IngressStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerConfiguration) DeepCopyInto(out *LoadBalancerConfiguration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerConfiguration.
+func (in *LoadBalancerConfiguration) DeepCopy() *LoadBalancerConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerConfiguration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerConfigurationList) DeepCopyInto(out *LoadBalancerConfigurationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LoadBalancerConfiguration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerConfigurationList.
+func (in *LoadBalancerConfigurationList) DeepCopy() *LoadBalancerConfigurationList {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerConfigurationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerConfigurationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerConfigurationSpec) DeepCopyInto(out *LoadBalancerConfigurationSpec) {
+	*out = *in
+	if in.LoadBalancer != nil {
+		in, out := &in.LoadBalancer, &out.LoadBalancer
+		*out = new(ServiceLoadBalancerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Listener != nil {
+		in, out := &in.Listener, &out.Listener
+		*out = new(ServiceListenerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(ServiceHealthCheckSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backend != nil {
+		in, out := &in.Backend, &out.Backend
+		*out = new(ServiceBackendSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerConfigurationSpec.
+func (in *LoadBalancerConfigurationSpec) DeepCopy() *LoadBalancerConfigurationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerConfigurationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceLoadBalancerSpec) DeepCopyInto(out *ServiceLoadBalancerSpec) {
+	*out = *in
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(int)
+		**out = **in
+	}
+	if in.ZoneMappings != nil {
+		in, out := &in.ZoneMappings, &out.ZoneMappings
+		*out = make([]ZoneMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityGroupIds != nil {
+		in, out := &in.SecurityGroupIds, &out.SecurityGroupIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CrossZoneEnabled != nil {
+		in, out := &in.CrossZoneEnabled, &out.CrossZoneEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeletionProtection != nil {
+		in, out := &in.DeletionProtection, &out.DeletionProtection
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AdditionalTags != nil {
+		in, out := &in.AdditionalTags, &out.AdditionalTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLoadBalancerSpec.
+func (in *ServiceLoadBalancerSpec) DeepCopy() *ServiceLoadBalancerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceLoadBalancerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceListenerSpec) DeepCopyInto(out *ServiceListenerSpec) {
+	*out = *in
+	if in.AclStatus != nil {
+		in, out := &in.AclStatus, &out.AclStatus
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProtocolPorts != nil {
+		in, out := &in.ProtocolPorts, &out.ProtocolPorts
+		*out = make([]ProtocolPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.Http2Enabled != nil {
+		in, out := &in.Http2Enabled, &out.Http2Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProxyProtocol != nil {
+		in, out := &in.ProxyProtocol, &out.ProxyProtocol
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IdleTimeout != nil {
+		in, out := &in.IdleTimeout, &out.IdleTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.RequestTimeout != nil {
+		in, out := &in.RequestTimeout, &out.RequestTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.EstablishedTimeout != nil {
+		in, out := &in.EstablishedTimeout, &out.EstablishedTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.PersistenceTimeout != nil {
+		in, out := &in.PersistenceTimeout, &out.PersistenceTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.ConnectionDrain != nil {
+		in, out := &in.ConnectionDrain, &out.ConnectionDrain
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ConnectionDrainTimeout != nil {
+		in, out := &in.ConnectionDrainTimeout, &out.ConnectionDrainTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.StickySession != nil {
+		in, out := &in.StickySession, &out.StickySession
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CookieTimeout != nil {
+		in, out := &in.CookieTimeout, &out.CookieTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.XForwardedForProto != nil {
+		in, out := &in.XForwardedForProto, &out.XForwardedForProto
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceListenerSpec.
+func (in *ServiceListenerSpec) DeepCopy() *ServiceListenerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceListenerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceHealthCheckSpec) DeepCopyInto(out *ServiceHealthCheckSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTPCodes != nil {
+		in, out := &in.HTTPCodes, &out.HTTPCodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConnectPort != nil {
+		in, out := &in.ConnectPort, &out.ConnectPort
+		*out = new(int)
+		**out = **in
+	}
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(int)
+		**out = **in
+	}
+	if in.HealthyThreshold != nil {
+		in, out := &in.HealthyThreshold, &out.HealthyThreshold
+		*out = new(int)
+		**out = **in
+	}
+	if in.UnhealthyThreshold != nil {
+		in, out := &in.UnhealthyThreshold, &out.UnhealthyThreshold
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceHealthCheckSpec.
+func (in *ServiceHealthCheckSpec) DeepCopy() *ServiceHealthCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceHealthCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceBackendSpec) DeepCopyInto(out *ServiceBackendSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RemoveUnscheduled != nil {
+		in, out := &in.RemoveUnscheduled, &out.RemoveUnscheduled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+	if in.IgnoreWeightUpdate != nil {
+		in, out := &in.IgnoreWeightUpdate, &out.IgnoreWeightUpdate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PreserveClientIp != nil {
+		in, out := &in.PreserveClientIp, &out.PreserveClientIp
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceBackendSpec.
+func (in *ServiceBackendSpec) DeepCopy() *ServiceBackendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBackendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,189 @@
+package ccmctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/clbv1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/nlbv2"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+
+	KindCLB            = "CLB"
+	KindNLB            = "NLB"
+	KindVServerGroup   = "VServerGroup"
+	KindNLBServerGroup = "NLBServerGroup"
+)
+
+// Ctl inspects and repairs the clbs and nlbs managed by the service and nlb controllers. The models
+// are built by the model builders of the controllers, so the resources are seen the same way as a
+// reconcile sees them.
+type Ctl struct {
+	kubeClient client.Client
+	cloud      prvd.Provider
+	clb        *clbv1.Inspector
+	nlb        *nlbv2.Inspector
+
+	// Out is where the results are written to
+	Out io.Writer
+	// Output is the format of the plans and the orphaned resources, one of text, json and yaml
+	Output string
+	// Force skips the safety checks of RemoveFinalizers and Adopt
+	Force bool
+}
+
+func New(kubeClient client.Client, cloud prvd.Provider, out io.Writer) (*Ctl, error) {
+	clb, err := clbv1.NewInspector(kubeClient, cloud)
+	if err != nil {
+		return nil, fmt.Errorf("new clb inspector: %s", err.Error())
+	}
+	nlb, err := nlbv2.NewInspector(kubeClient, cloud)
+	if err != nil {
+		return nil, fmt.Errorf("new nlb inspector: %s", err.Error())
+	}
+	return &Ctl{
+		kubeClient: kubeClient,
+		cloud:      cloud,
+		clb:        clb,
+		nlb:        nlb,
+		Out:        out,
+		Output:     OutputText,
+	}, nil
+}
+
+// getService gets the service by a key in the format of namespace/name, the namespace is default if
+// it is omitted.
+func (c *Ctl) getService(ctx context.Context, key string) (*v1.Service, error) {
+	name := types.NamespacedName{Namespace: v1.NamespaceDefault, Name: key}
+	if i := strings.Index(key, "/"); i >= 0 {
+		name.Namespace, name.Name = key[:i], key[i+1:]
+	}
+	if name.Namespace == "" || name.Name == "" {
+		return nil, fmt.Errorf("invalid service %q, namespace/name is expected", key)
+	}
+	svc := &v1.Service{}
+	if err := c.kubeClient.Get(ctx, name, svc); err != nil {
+		return nil, fmt.Errorf("get service %s: %s", name, err.Error())
+	}
+	return svc, nil
+}
+
+func (c *Ctl) listServices(ctx context.Context) ([]v1.Service, error) {
+	svcs := &v1.ServiceList{}
+	if err := c.kubeClient.List(ctx, svcs); err != nil {
+		return nil, fmt.Errorf("list services: %s", err.Error())
+	}
+	return svcs.Items, nil
+}
+
+// kindOf returns the kind of the loadbalancer the service needs, or an empty string if the service
+// is not handled by the service or nlb controller.
+func kindOf(svc *v1.Service) string {
+	// the type of a service being deleted is decided by its finalizer
+	switch {
+	case helper.HasFinalizer(svc, helper.NLBFinalizer) || helper.NeedNLB(svc):
+		return KindNLB
+	case helper.HasFinalizer(svc, helper.ServiceFinalizer) || helper.NeedCLB(svc):
+		return KindCLB
+	}
+	return ""
+}
+
+func defaultLoadBalancerName(svc *v1.Service) string {
+	return (&annotation.AnnotationRequest{Service: svc}).GetDefaultLoadBalancerName()
+}
+
+// ownerOf returns the service the loadbalancer or server group belongs to. A resource created by the
+// controllers is tagged with the default loadbalancer name of its service, and a reused loadbalancer
+// is specified by the annotation and the label of the service.
+func ownerOf(id string, tags []tag.Tag, svcs []v1.Service) *v1.Service {
+	owner := tagValue(tags, helper.TAGKEY)
+	for i := range svcs {
+		svc := &svcs[i]
+		if owner != "" && owner == defaultLoadBalancerName(svc) {
+			return svc
+		}
+		if id == "" {
+			continue
+		}
+		if svc.Labels[helper.LabelLoadBalancerId] == id ||
+			(&annotation.AnnotationRequest{Service: svc}).Get(annotation.LoadBalancerId) == id {
+			return svc
+		}
+	}
+	return nil
+}
+
+func findService(svcs []v1.Service, namespace, name string) *v1.Service {
+	for i := range svcs {
+		if svcs[i].Namespace == namespace && svcs[i].Name == name {
+			return &svcs[i]
+		}
+	}
+	return nil
+}
+
+func tagValue(tags []tag.Tag, key string) string {
+	for _, t := range tags {
+		if t.Key == key {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+func clusterTags() []tag.Tag {
+	return []tag.Tag{{Key: util.ClusterTagKey, Value: base.CLUSTER_ID}}
+}
+
+func formatTags(tags []tag.Tag) string {
+	var ret []string
+	for _, t := range tags {
+		ret = append(ret, fmt.Sprintf("%s=%s", t.Key, t.Value))
+	}
+	return strings.Join(ret, ",")
+}
+
+// print writes obj in the json or yaml format
+func (c *Ctl) print(obj interface{}) error {
+	var (
+		data []byte
+		err  error
+	)
+	if c.Output == OutputJSON {
+		data, err = json.MarshalIndent(obj, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(obj)
+	}
+	if err != nil {
+		return fmt.Errorf("marshal %T: %s", obj, err.Error())
+	}
+	_, err = c.Out.Write(data)
+	return err
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
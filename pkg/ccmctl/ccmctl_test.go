@@ -0,0 +1,179 @@
+package ccmctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func newService(name, uid string) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  v1.NamespaceDefault,
+			Name:       name,
+			UID:        types.UID(uid),
+			Finalizers: []string{helper.ServiceFinalizer},
+		},
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeLoadBalancer,
+			Ports: []v1.ServicePort{{
+				Name:       "tcp",
+				Port:       80,
+				TargetPort: intstr.FromInt(80),
+				NodePort:   30080,
+				Protocol:   v1.ProtocolTCP,
+			}},
+		},
+	}
+}
+
+// createCLB creates a clb with a vserver group as the service controller does
+func createCLB(t *testing.T, cloud *fake.FakeCloud, svc *v1.Service) string {
+	ctx := context.TODO()
+	mdl := &model.LoadBalancer{LoadBalancerAttribute: model.LoadBalancerAttribute{
+		LoadBalancerName: defaultLoadBalancerName(svc),
+		Tags: []tag.Tag{
+			{Key: helper.TAGKEY, Value: defaultLoadBalancerName(svc)},
+			{Key: util.ClusterTagKey, Value: base.CLUSTER_ID},
+		},
+	}}
+	assert.NoError(t, cloud.CreateLoadBalancer(ctx, mdl, ""))
+	lbId := mdl.LoadBalancerAttribute.LoadBalancerId
+	vg := &model.VServerGroup{VGroupName: (&model.VGroupNamedKey{
+		Prefix:      model.DEFAULT_PREFIX,
+		CID:         base.CLUSTER_ID,
+		Namespace:   svc.Namespace,
+		ServiceName: svc.Name,
+		VGroupPort:  "80",
+	}).Key()}
+	assert.NoError(t, cloud.CreateVServerGroup(ctx, vg, lbId))
+	return lbId
+}
+
+func newCtl(t *testing.T, cloud *fake.FakeCloud, svcs ...*v1.Service) (*Ctl, client.Client, *bytes.Buffer) {
+	builder := fakeclient.NewClientBuilder()
+	for _, svc := range svcs {
+		builder = builder.WithObjects(svc)
+	}
+	kubeClient := builder.Build()
+	out := &bytes.Buffer{}
+	ctl, err := New(kubeClient, cloud, out)
+	assert.NoError(t, err)
+	return ctl, kubeClient, out
+}
+
+func TestDescribe(t *testing.T) {
+	cloud := fake.NewFakeCloud(nil)
+	svc := newService("test", "2b1ed0a4-4b05-4e1c-a8e8-7b8a8b5d1a3e")
+	lbId := createCLB(t, cloud, svc)
+	ctl, _, out := newCtl(t, cloud, svc)
+
+	assert.NoError(t, ctl.Describe(context.TODO(), "test"))
+	assert.Contains(t, out.String(), "default/test")
+	assert.Contains(t, out.String(), lbId)
+	assert.Contains(t, out.String(), "this service")
+
+	assert.Error(t, ctl.Describe(context.TODO(), "default/not-found"))
+}
+
+func TestOrphans(t *testing.T) {
+	cloud := fake.NewFakeCloud(nil)
+	svc := newService("test", "2b1ed0a4-4b05-4e1c-a8e8-7b8a8b5d1a3e")
+	deleted := newService("deleted", "8f0a7e55-62c4-4d5e-a3c1-5f0d0d6e2b9c")
+	createCLB(t, cloud, svc)
+	orphanId := createCLB(t, cloud, deleted)
+	// a clb which is not created by the cluster is ignored
+	assert.NoError(t, cloud.CreateLoadBalancer(context.TODO(), &model.LoadBalancer{}, ""))
+
+	ctl, _, out := newCtl(t, cloud, svc)
+	ctl.Output = OutputJSON
+	assert.NoError(t, ctl.Orphans(context.TODO()))
+
+	var orphans []Orphan
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &orphans))
+	assert.Len(t, orphans, 1)
+	assert.Equal(t, KindCLB, orphans[0].Kind)
+	assert.Equal(t, orphanId, orphans[0].ID)
+}
+
+func TestOrphans_VServerGroup(t *testing.T) {
+	cloud := fake.NewFakeCloud(nil)
+	svc := newService("test", "2b1ed0a4-4b05-4e1c-a8e8-7b8a8b5d1a3e")
+	lbId := createCLB(t, cloud, svc)
+	vg := &model.VServerGroup{VGroupName: "k8s/443/deleted/default/" + base.CLUSTER_ID}
+	assert.NoError(t, cloud.CreateVServerGroup(context.TODO(), vg, lbId))
+
+	ctl, _, out := newCtl(t, cloud, svc)
+	ctl.Output = OutputJSON
+	assert.NoError(t, ctl.Orphans(context.TODO()))
+
+	var orphans []Orphan
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &orphans))
+	assert.Len(t, orphans, 1)
+	assert.Equal(t, KindVServerGroup, orphans[0].Kind)
+	assert.Equal(t, vg.VGroupId, orphans[0].ID)
+	assert.Equal(t, lbId, orphans[0].Parent)
+}
+
+func TestRemoveFinalizers(t *testing.T) {
+	cloud := fake.NewFakeCloud(nil)
+	svc := newService("test", "2b1ed0a4-4b05-4e1c-a8e8-7b8a8b5d1a3e")
+	createCLB(t, cloud, svc)
+	svc.Spec.Type = v1.ServiceTypeClusterIP
+	ctl, kubeClient, _ := newCtl(t, cloud, svc)
+
+	// the clb is not deleted yet
+	assert.Error(t, ctl.RemoveFinalizers(context.TODO(), "default/test"))
+
+	ctl.Force = true
+	assert.NoError(t, ctl.RemoveFinalizers(context.TODO(), "default/test"))
+	got := &v1.Service{}
+	assert.NoError(t, kubeClient.Get(context.TODO(), util.NamespacedName(svc), got))
+	assert.Empty(t, got.Finalizers)
+}
+
+func TestRemoveFinalizers_NeedLoadBalancer(t *testing.T) {
+	cloud := fake.NewFakeCloud(nil)
+	svc := newService("test", "2b1ed0a4-4b05-4e1c-a8e8-7b8a8b5d1a3e")
+	ctl, _, _ := newCtl(t, cloud, svc)
+	assert.Error(t, ctl.RemoveFinalizers(context.TODO(), "default/test"))
+}
+
+func TestAdopt(t *testing.T) {
+	cloud := fake.NewFakeCloud(nil)
+	old := newService("test", "2b1ed0a4-4b05-4e1c-a8e8-7b8a8b5d1a3e")
+	lbId := createCLB(t, cloud, old)
+	// the service is recreated with another uid
+	svc := newService("test", "8f0a7e55-62c4-4d5e-a3c1-5f0d0d6e2b9c")
+	other := newService("other", "0c1d7b9e-5a34-4f5b-9d1e-2c3b4a5d6e7f")
+	otherLbId := createCLB(t, cloud, other)
+	ctl, _, _ := newCtl(t, cloud, svc, other)
+
+	// the clb of another service is not adopted
+	assert.Error(t, ctl.Adopt(context.TODO(), "default/test", otherLbId))
+	assert.Error(t, ctl.Adopt(context.TODO(), "default/test", "lb-not-found"))
+
+	assert.NoError(t, ctl.Adopt(context.TODO(), "default/test", lbId))
+	lb := cloud.GetLoadBalancer(lbId)
+	assert.Contains(t, lb.LoadBalancerAttribute.Tags, tag.Tag{Key: helper.TAGKEY, Value: defaultLoadBalancerName(svc)})
+
+	remote, err := ctl.clb.RemoteModel(context.TODO(), svc)
+	assert.NoError(t, err)
+	assert.Equal(t, lbId, remote.LoadBalancerAttribute.LoadBalancerId)
+}
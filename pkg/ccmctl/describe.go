@@ -0,0 +1,134 @@
+package ccmctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	v1 "k8s.io/api/core/v1"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// Describe prints the loadbalancer of the service with its listeners and server groups. The
+// listeners and server groups which are not managed by the service are printed as well, with the
+// service or the user managing them.
+func (c *Ctl) Describe(ctx context.Context, key string) error {
+	svc, err := c.getService(ctx, key)
+	if err != nil {
+		return err
+	}
+	kind := kindOf(svc)
+	if kind == "" {
+		return fmt.Errorf("service %s does not need a clb or nlb", util.Key(svc))
+	}
+
+	w := tabwriter.NewWriter(c.Out, 0, 4, 2, ' ', 0)
+	describeService(w, svc, kind)
+	switch kind {
+	case KindCLB:
+		mdl, err := c.clb.RemoteModel(ctx, svc)
+		if err != nil {
+			return err
+		}
+		describeCLB(w, svc, mdl)
+	case KindNLB:
+		mdl, err := c.nlb.RemoteModel(ctx, svc)
+		if err != nil {
+			return err
+		}
+		describeNLB(w, svc, mdl)
+	}
+	return w.Flush()
+}
+
+func describeService(w io.Writer, svc *v1.Service, kind string) {
+	anno := &annotation.AnnotationRequest{Service: svc}
+	mode := "created by the controller"
+	if anno.Get(annotation.LoadBalancerId) != "" {
+		mode = fmt.Sprintf("reused, override listeners: %t", anno.IsForceOverride())
+	}
+	_, _ = fmt.Fprintf(w, "Service:\t%s\n", util.Key(svc))
+	_, _ = fmt.Fprintf(w, "Kind:\t%s\n", kind)
+	_, _ = fmt.Fprintf(w, "Mode:\t%s\n", mode)
+	_, _ = fmt.Fprintf(w, "Finalizers:\t%s\n", orNone(strings.Join(svc.Finalizers, ",")))
+	_, _ = fmt.Fprintf(w, "Label %s:\t%s\n", helper.LabelLoadBalancerId, orNone(svc.Labels[helper.LabelLoadBalancerId]))
+	if svc.DeletionTimestamp != nil {
+		_, _ = fmt.Fprintf(w, "Deleting since:\t%s\n", svc.DeletionTimestamp.String())
+	}
+}
+
+// managedBy returns the service managing a listener or server group by its named key
+func managedBy(svc *v1.Service, namespace, name string) string {
+	if namespace == svc.Namespace && name == svc.Name {
+		return "this service"
+	}
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+func describeCLB(w io.Writer, svc *v1.Service, mdl *model.LoadBalancer) {
+	lb := mdl.LoadBalancerAttribute
+	if lb.LoadBalancerId == "" {
+		_, _ = fmt.Fprintf(w, "LoadBalancer:\t<none>\n")
+		return
+	}
+	_, _ = fmt.Fprintf(w, "LoadBalancer:\t%s\n", lb.LoadBalancerId)
+	_, _ = fmt.Fprintf(w, "Name:\t%s\n", lb.LoadBalancerName)
+	_, _ = fmt.Fprintf(w, "Address:\t%s (%s)\n", lb.Address, lb.AddressType)
+	_, _ = fmt.Fprintf(w, "Tags:\t%s\n", orNone(formatTags(lb.Tags)))
+
+	_, _ = fmt.Fprintf(w, "\nLISTENER\tSTATUS\tVSERVER GROUP\tMANAGED BY\n")
+	for _, l := range mdl.Listeners {
+		owner := "user"
+		if !l.IsUserManaged && l.NamedKey != nil {
+			owner = managedBy(svc, l.NamedKey.Namespace, l.NamedKey.ServiceName)
+		}
+		_, _ = fmt.Fprintf(w, "%s:%d\t%s\t%s\t%s\n", l.Protocol, l.ListenerPort, l.Status, orNone(l.VGroupId), owner)
+	}
+
+	_, _ = fmt.Fprintf(w, "\nVSERVER GROUP\tNAME\tBACKENDS\tMANAGED BY\n")
+	for _, vg := range mdl.VServerGroups {
+		owner := "user"
+		if !vg.IsUserManaged && vg.NamedKey != nil {
+			owner = managedBy(svc, vg.NamedKey.Namespace, vg.NamedKey.ServiceName)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", vg.VGroupId, vg.VGroupName, len(vg.Backends), owner)
+	}
+}
+
+func describeNLB(w io.Writer, svc *v1.Service, mdl *nlbmodel.NetworkLoadBalancer) {
+	lb := mdl.LoadBalancerAttribute
+	if lb == nil || lb.LoadBalancerId == "" {
+		_, _ = fmt.Fprintf(w, "LoadBalancer:\t<none>\n")
+		return
+	}
+	_, _ = fmt.Fprintf(w, "LoadBalancer:\t%s\n", lb.LoadBalancerId)
+	_, _ = fmt.Fprintf(w, "Name:\t%s\n", lb.Name)
+	_, _ = fmt.Fprintf(w, "Address:\t%s (%s)\n", lb.DNSName, lb.AddressType)
+	_, _ = fmt.Fprintf(w, "Tags:\t%s\n", orNone(formatTags(lb.Tags)))
+
+	_, _ = fmt.Fprintf(w, "\nLISTENER\tSTATUS\tSERVER GROUP\tMANAGED BY\n")
+	for _, l := range mdl.Listeners {
+		owner := "user"
+		if !l.IsUserManaged && l.NamedKey != nil {
+			owner = managedBy(svc, l.NamedKey.Namespace, l.NamedKey.ServiceName)
+		}
+		_, _ = fmt.Fprintf(w, "%s:%s\t%s\t%s\t%s\n", l.ListenerProtocol, l.PortString(), l.ListenerStatus,
+			orNone(l.ServerGroupId), owner)
+	}
+
+	_, _ = fmt.Fprintf(w, "\nSERVER GROUP\tNAME\tSERVERS\tMANAGED BY\n")
+	for _, sg := range mdl.ServerGroups {
+		owner := "user"
+		if !sg.IsUserManaged && sg.NamedKey != nil {
+			owner = managedBy(svc, sg.NamedKey.Namespace, sg.NamedKey.ServiceName)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", sg.ServerGroupId, sg.ServerGroupName, len(sg.Servers), owner)
+	}
+}
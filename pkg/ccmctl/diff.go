@@ -0,0 +1,39 @@
+package ccmctl
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// Diff prints the changes a reconcile of the service would make to its loadbalancer, e.g. the
+// attributes modified in the console which would be reverted.
+func (c *Ctl) Diff(ctx context.Context, key string) error {
+	svc, err := c.getService(ctx, key)
+	if err != nil {
+		return err
+	}
+	plan, err := c.plan(ctx, svc)
+	if err != nil {
+		return err
+	}
+	if c.Output == OutputText && len(plan.Changes) == 0 && plan.Error == "" {
+		_, err := fmt.Fprintf(c.Out, "service %s is in sync with loadbalancer %s\n", util.Key(svc), orNone(plan.LoadBalancerID))
+		return err
+	}
+	return c.print(plan)
+}
+
+func (c *Ctl) plan(ctx context.Context, svc *v1.Service) (*dryrun.Plan, error) {
+	switch kindOf(svc) {
+	case KindCLB:
+		return c.clb.Plan(ctx, svc), nil
+	case KindNLB:
+		return c.nlb.Plan(ctx, svc), nil
+	}
+	return nil, fmt.Errorf("service %s does not need a clb or nlb", util.Key(svc))
+}
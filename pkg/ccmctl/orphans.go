@@ -0,0 +1,173 @@
+package ccmctl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	v1 "k8s.io/api/core/v1"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+)
+
+// Orphan is a cloud resource tagged with the cluster id, or named after a service of the cluster,
+// which is not owned by any service.
+type Orphan struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Parent is the loadbalancer of a vserver group
+	Parent string `json:"parent,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Orphans prints the clbs, nlbs and server groups of the cluster whose services are deleted. They are
+// left behind if the finalizers of the services are removed before the resources are cleaned up.
+func (c *Ctl) Orphans(ctx context.Context) error {
+	orphans, err := c.findOrphans(ctx)
+	if err != nil {
+		return err
+	}
+	if c.Output != OutputText {
+		return c.print(orphans)
+	}
+	if len(orphans) == 0 {
+		_, err := fmt.Fprintf(c.Out, "no orphaned resources found in cluster %s\n", base.CLUSTER_ID)
+		return err
+	}
+	w := tabwriter.NewWriter(c.Out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "KIND\tID\tNAME\tPARENT\tREASON\n")
+	for _, o := range orphans {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", o.Kind, o.ID, orNone(o.Name), orNone(o.Parent), o.Reason)
+	}
+	return w.Flush()
+}
+
+func (c *Ctl) findOrphans(ctx context.Context) ([]Orphan, error) {
+	svcs, err := c.listServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []Orphan
+	clbs, err := c.cloud.ListLoadBalancers(ctx, clusterTags())
+	if err != nil {
+		return nil, fmt.Errorf("list clbs: %s", err.Error())
+	}
+	// the vserver groups are checked in the clbs of the cluster and the clbs reused by the services
+	lbIds := map[string]bool{}
+	for _, lb := range clbs {
+		attr := lb.LoadBalancerAttribute
+		if ownerOf(attr.LoadBalancerId, attr.Tags, svcs) == nil {
+			orphans = append(orphans, Orphan{
+				Kind:   KindCLB,
+				ID:     attr.LoadBalancerId,
+				Name:   attr.LoadBalancerName,
+				Reason: lbOrphanReason(attr.Tags),
+			})
+			continue
+		}
+		lbIds[attr.LoadBalancerId] = true
+	}
+	for i := range svcs {
+		if id := svcs[i].Labels[helper.LabelLoadBalancerId]; id != "" && kindOf(&svcs[i]) == KindCLB {
+			lbIds[id] = true
+		}
+	}
+	for id := range lbIds {
+		vgs, err := c.cloud.DescribeVServerGroups(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("describe vserver groups of clb %s: %s", id, err.Error())
+		}
+		for _, vg := range vgs {
+			if vg.IsUserManaged || vg.NamedKey == nil || vg.NamedKey.CID != base.CLUSTER_ID {
+				continue
+			}
+			if findService(svcs, vg.NamedKey.Namespace, vg.NamedKey.ServiceName) == nil {
+				orphans = append(orphans, Orphan{
+					Kind:   KindVServerGroup,
+					ID:     vg.VGroupId,
+					Name:   vg.VGroupName,
+					Parent: id,
+					Reason: serviceNotFound(vg.NamedKey.Namespace, vg.NamedKey.ServiceName),
+				})
+			}
+		}
+	}
+
+	nlbs, err := c.cloud.ListNLBs(ctx, clusterTags())
+	if err != nil {
+		return nil, fmt.Errorf("list nlbs: %s", err.Error())
+	}
+	for _, lb := range nlbs {
+		attr := lb.LoadBalancerAttribute
+		if attr == nil {
+			continue
+		}
+		if ownerOf(attr.LoadBalancerId, attr.Tags, svcs) == nil {
+			orphans = append(orphans, Orphan{
+				Kind:   KindNLB,
+				ID:     attr.LoadBalancerId,
+				Name:   attr.Name,
+				Reason: lbOrphanReason(attr.Tags),
+			})
+		}
+	}
+
+	sgs, err := c.cloud.ListNLBServerGroups(ctx, clusterTags())
+	if err != nil {
+		return nil, fmt.Errorf("list nlb server groups: %s", err.Error())
+	}
+	for _, sg := range sgs {
+		if reason := sgOrphanReason(sg, svcs); reason != "" {
+			orphans = append(orphans, Orphan{
+				Kind:   KindNLBServerGroup,
+				ID:     sg.ServerGroupId,
+				Name:   sg.ServerGroupName,
+				Reason: reason,
+			})
+		}
+	}
+
+	sort.SliceStable(orphans, func(i, j int) bool {
+		if orphans[i].Kind != orphans[j].Kind {
+			return orphans[i].Kind < orphans[j].Kind
+		}
+		return orphans[i].ID < orphans[j].ID
+	})
+	return orphans, nil
+}
+
+func lbOrphanReason(tags []tag.Tag) string {
+	if owner := tagValue(tags, helper.TAGKEY); owner != "" {
+		return fmt.Sprintf("no service owns %s=%s", helper.TAGKEY, owner)
+	}
+	return "no service reuses the loadbalancer"
+}
+
+// sgOrphanReason returns why the server group is orphaned, or an empty string if it is owned by a
+// service. A server group is tagged with the default loadbalancer name of its service, and the
+// server groups created by the old versions are matched by their names.
+func sgOrphanReason(sg *nlbmodel.ServerGroup, svcs []v1.Service) string {
+	if owner := tagValue(sg.Tags, helper.TAGKEY); owner != "" {
+		if ownerOf("", sg.Tags, svcs) == nil {
+			return fmt.Sprintf("no service owns %s=%s", helper.TAGKEY, owner)
+		}
+		return ""
+	}
+	if sg.NamedKey == nil || sg.NamedKey.CID != base.CLUSTER_ID {
+		return ""
+	}
+	if findService(svcs, sg.NamedKey.Namespace, sg.NamedKey.ServiceName) == nil {
+		return serviceNotFound(sg.NamedKey.Namespace, sg.NamedKey.ServiceName)
+	}
+	return ""
+}
+
+func serviceNotFound(namespace, name string) string {
+	return fmt.Sprintf("service %s/%s not found", namespace, name)
+}
@@ -0,0 +1,144 @@
+package ccmctl
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// RemoveFinalizers removes the finalizers of the service and nlb controllers from the service, e.g.
+// when the service is stuck in deleting because its loadbalancer is deleted in the console. Unless
+// forced, the finalizers are removed only if the service no longer needs a loadbalancer and nothing
+// is left to clean up in the cloud.
+func (c *Ctl) RemoveFinalizers(ctx context.Context, key string) error {
+	svc, err := c.getService(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !helper.HasFinalizer(svc, helper.ServiceFinalizer) && !helper.HasFinalizer(svc, helper.NLBFinalizer) {
+		_, err := fmt.Fprintf(c.Out, "service %s has no finalizers of the controllers\n", util.Key(svc))
+		return err
+	}
+
+	if !c.Force {
+		if !helper.NeedDeleteLoadBalancer(svc) {
+			return fmt.Errorf("service %s still needs a loadbalancer, delete the service or change its type first", util.Key(svc))
+		}
+		if kindOf(svc) != "" {
+			plan, err := c.plan(ctx, svc)
+			if err != nil {
+				return err
+			}
+			if plan.Error != "" {
+				return fmt.Errorf("check the resources of service %s: %s", util.Key(svc), plan.Error)
+			}
+			if len(plan.Changes) != 0 {
+				return fmt.Errorf("service %s has %d resources to clean up in loadbalancer %s, "+
+					"run diff to see them or use --force", util.Key(svc), len(plan.Changes), plan.LoadBalancerID)
+			}
+		}
+	}
+
+	if err := helper.NewDefaultFinalizerManager(c.kubeClient).
+		RemoveFinalizers(ctx, svc, helper.ServiceFinalizer, helper.NLBFinalizer); err != nil {
+		return fmt.Errorf("remove finalizers of service %s: %s", util.Key(svc), err.Error())
+	}
+	_, err = fmt.Fprintf(c.Out, "finalizers of service %s removed\n", util.Key(svc))
+	return err
+}
+
+// Adopt makes the loadbalancer managed by the service as if it was created by the service, e.g.
+// after the service is recreated with another uid. The loadbalancer is tagged with the default
+// loadbalancer name of the service, and the controller takes it over in the next reconcile. Unless
+// forced, a loadbalancer owned by another service is not adopted.
+func (c *Ctl) Adopt(ctx context.Context, key, lbId string) error {
+	svc, err := c.getService(ctx, key)
+	if err != nil {
+		return err
+	}
+	kind := kindOf(svc)
+	if kind == "" || helper.NeedDeleteLoadBalancer(svc) {
+		return fmt.Errorf("service %s does not need a clb or nlb", util.Key(svc))
+	}
+	if id := (&annotation.AnnotationRequest{Service: svc}).Get(annotation.LoadBalancerId); id != "" {
+		return fmt.Errorf("service %s reuses loadbalancer %s by annotation, it can not adopt another one", util.Key(svc), id)
+	}
+
+	var (
+		current string
+		tags    []tag.Tag
+	)
+	switch kind {
+	case KindCLB:
+		remote, err := c.clb.RemoteModel(ctx, svc)
+		if err != nil {
+			return err
+		}
+		current = remote.LoadBalancerAttribute.LoadBalancerId
+		mdl := &model.LoadBalancer{LoadBalancerAttribute: model.LoadBalancerAttribute{LoadBalancerId: lbId}}
+		if err := c.cloud.DescribeLoadBalancer(ctx, mdl); err != nil {
+			return fmt.Errorf("describe clb %s: %s", lbId, err.Error())
+		}
+		if tags, err = c.cloud.ListCLBTagResources(ctx, lbId); err != nil {
+			return fmt.Errorf("list tags of clb %s: %s", lbId, err.Error())
+		}
+	case KindNLB:
+		remote, err := c.nlb.RemoteModel(ctx, svc)
+		if err != nil {
+			return err
+		}
+		if remote.LoadBalancerAttribute != nil {
+			current = remote.LoadBalancerAttribute.LoadBalancerId
+		}
+		mdl := &nlbmodel.NetworkLoadBalancer{LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{LoadBalancerId: lbId}}
+		if err := c.cloud.DescribeNLB(ctx, mdl); err != nil {
+			return fmt.Errorf("describe nlb %s: %s", lbId, err.Error())
+		}
+		if mdl.LoadBalancerAttribute.LoadBalancerId == "" {
+			return fmt.Errorf("nlb %s not found", lbId)
+		}
+		tags = mdl.LoadBalancerAttribute.Tags
+	}
+
+	if current == lbId {
+		_, err := fmt.Fprintf(c.Out, "loadbalancer %s is already managed by service %s\n", lbId, util.Key(svc))
+		return err
+	}
+	if current != "" {
+		return fmt.Errorf("service %s already has loadbalancer %s", util.Key(svc), current)
+	}
+	if !c.Force {
+		svcs, err := c.listServices(ctx)
+		if err != nil {
+			return err
+		}
+		if owner := ownerOf(lbId, tags, svcs); owner != nil && !isSameService(owner, svc) {
+			return fmt.Errorf("loadbalancer %s is owned by service %s, use --force to adopt it", lbId, util.Key(owner))
+		}
+	}
+
+	ownerTags := append([]tag.Tag{{Key: helper.TAGKEY, Value: defaultLoadBalancerName(svc)}}, clusterTags()...)
+	switch kind {
+	case KindCLB:
+		err = c.cloud.TagCLBResource(ctx, lbId, ownerTags)
+	case KindNLB:
+		err = c.cloud.TagNLBResource(ctx, lbId, nlbmodel.LoadBalancerTagType, ownerTags)
+	}
+	if err != nil {
+		return fmt.Errorf("tag loadbalancer %s: %s", lbId, err.Error())
+	}
+	_, err = fmt.Fprintf(c.Out, "loadbalancer %s adopted by service %s, it is reconciled in the next sync\n", lbId, util.Key(svc))
+	return err
+}
+
+func isSameService(a, b *v1.Service) bool {
+	return a.Namespace == b.Namespace && a.Name == b.Name
+}
@@ -14,6 +14,10 @@ const (
 	DefaultServiceMaxConcurrentReconciles = 3
 	DefaultNodeMaxConcurrentReconciles    = 1
 	DefaultRouteMaxConcurrentReconciles   = 1
+	DefaultPrivateZoneGCPeriod            = 600
+	DefaultSecretCertGCPeriod             = 600
+	DefaultRateLimitQPS                   = 50
+	DefaultRateLimitBurst                 = 100
 )
 
 var CloudCFG = &CloudConfig{}
@@ -51,11 +55,40 @@ type CloudConfig struct {
 		// pvtz controller
 		PrivateZoneID        string `json:"privateZoneId"`
 		PrivateZoneRecordTTL int64  `json:"privateZoneRecordTTL"`
+		// PrivateZoneGCPeriod is the period in seconds to remove the orphan records owned by the cluster
+		PrivateZoneGCPeriod int64 `json:"privateZoneGCPeriod"`
+
+		// alb ingress controller
+		// SecretCertGCPeriod is the period in seconds to remove the unused certificates uploaded from secrets
+		SecretCertGCPeriod int64 `json:"secretCertGCPeriod"`
 
 		FeatureGates string `json:"featureGates"`
+
+		// RateLimit is the client side rate limit of the openapi calls
+		RateLimit RateLimitConfig `json:"rateLimit"`
 	}
 }
 
+// RateLimitConfig configures the token buckets shared by all the openapi clients.
+type RateLimitConfig struct {
+	Disabled bool `json:"disabled"`
+	// Default is the bucket of the products which are not configured in Products
+	Default RateLimit `json:"default"`
+	// Products are the buckets per product, e.g. "slb", "nlb", "ecs"
+	Products map[string]RateLimit `json:"products"`
+	// APIs are the buckets per api, keyed by "product/Action", e.g. "slb/DescribeLoadBalancers".
+	// The calls of the api take a token from both the api bucket and the product bucket.
+	APIs map[string]RateLimit `json:"apis"`
+	// Priorities overrides the priority class of the apis, keyed by "product/Action",
+	// the value is one of high, normal and low.
+	Priorities map[string]string `json:"priorities"`
+}
+
+type RateLimit struct {
+	QPS   float64 `json:"qps"`
+	Burst int     `json:"burst"`
+}
+
 func (cc *CloudConfig) LoadCloudCFG() error {
 	content, err := os.ReadFile(ControllerCFG.CloudConfigPath)
 	if err != nil {
@@ -79,6 +112,18 @@ func (cc *CloudConfig) SetDefaultValue() {
 	if cc.Global.RouteMaxConcurrentReconciles == 0 {
 		cc.Global.RouteMaxConcurrentReconciles = DefaultRouteMaxConcurrentReconciles
 	}
+	if cc.Global.PrivateZoneGCPeriod == 0 {
+		cc.Global.PrivateZoneGCPeriod = DefaultPrivateZoneGCPeriod
+	}
+	if cc.Global.SecretCertGCPeriod == 0 {
+		cc.Global.SecretCertGCPeriod = DefaultSecretCertGCPeriod
+	}
+	if cc.Global.RateLimit.Default.QPS == 0 {
+		cc.Global.RateLimit.Default.QPS = DefaultRateLimitQPS
+	}
+	if cc.Global.RateLimit.Default.Burst == 0 {
+		cc.Global.RateLimit.Default.Burst = DefaultRateLimitBurst
+	}
 	CloudCFG.Global.ResourceGroupID = strings.TrimSpace(CloudCFG.Global.ResourceGroupID)
 	CloudCFG.Global.RouteTableIDS = strings.TrimSpace(CloudCFG.Global.RouteTableIDS)
 }
@@ -103,6 +148,13 @@ func (cc *CloudConfig) PrintInfo() {
 		klog.Infof("using feature gate: %s", cc.Global.FeatureGates)
 	}
 
+	if cc.Global.RateLimit.Disabled {
+		klog.Infof("openapi rate limit is disabled")
+	} else {
+		klog.Infof("openapi rate limit: default qps %v, burst %d", cc.Global.RateLimit.Default.QPS,
+			cc.Global.RateLimit.Default.Burst)
+	}
+
 	klog.Infof("NodeMaxConcurrentReconciles: %d, ServiceMaxConcurrentReconciles: %d, RouteMaxConcurrentReconciles: %d",
 		cc.Global.NodeMaxConcurrentReconciles, cc.Global.ServiceMaxConcurrentReconciles, cc.Global.RouteMaxConcurrentReconciles)
 }
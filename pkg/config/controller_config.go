@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -26,13 +27,27 @@ const (
 	flagSkipDisableSourceDestCheck      = "skip-disable-source-dest-check"
 	flagNodeEventAggregationWaitSeconds = "node-event-aggregation-wait-seconds"
 	flagMaxThrottlingRetryTimes         = "max-throttling-retry-times"
+	flagNodeTerminationTaintKey         = "node-termination-taint-key"
+	flagNodeTerminationTaintEffect      = "node-termination-taint-effect"
+	flagNodeExpirationWindow            = "node-expiration-window"
+	flagRouteGCGracePeriod              = "route-gc-grace-period"
+	flagRouteGCDryRun                   = "route-gc-dry-run"
+	flagTracingEndpoint                 = "tracing-endpoint"
+	flagTracingSampleRatio              = "tracing-sample-ratio"
+	flagDriftDetectionPeriod            = "drift-detection-period"
+	flagDriftPolicy                     = "drift-policy"
+	flagDriftIgnoreFields               = "drift-ignore-fields"
+	flagDefaultLBConfiguration          = "default-loadbalancer-configuration"
 
 	flagDryRun                         = "dry-run"
+	flagDryRunPlanDir                  = "dry-run-plan-dir"
+	flagDryRunPlanFormat               = "dry-run-plan-format"
 	flagServiceMaxConcurrentReconciles = "concurrent-service-syncs"
 	flagRouteReconciliationPeriod      = "route-reconciliation-period"
 	flagNodeMonitorPeriod              = "node-monitor-period"
 	flagServerGroupBatchSize           = "sg-batch-size"
 	flagNetwork                        = "network"
+	flagHealthCheckTimeout             = "readiness-gate-health-check-timeout"
 
 	defaultCloudProvider                  = "alibabacloud"
 	defaultClusterName                    = "kubernetes"
@@ -43,11 +58,29 @@ const (
 	defaultNodeMonitorPeriod              = 5 * time.Minute
 	defaultServerGroupBatchSize           = 40
 	defaultNetwork                        = "vpc"
+	defaultHealthCheckTimeout             = 5 * time.Minute
+	defaultNodeTerminationTaintKey        = "node.alibabacloud.com/instance-terminating"
+	defaultNodeTerminationTaintEffect     = "NoSchedule"
+	defaultNodeExpirationWindow           = 72 * time.Hour
+	defaultRouteGCGracePeriod             = 30 * time.Minute
+	defaultDryRunPlanFormat               = "yaml"
+	defaultTracingSampleRatio             = 0.1
+	defaultDriftPolicy                    = DriftPolicyReport
+	defaultLBConfiguration                = "kube-system/default"
 
 	defaultMaxConcurrentActions    = 10
 	defaultMaxThrottlingRetryTimes = 10
 )
 
+const (
+	// DriftPolicyReport reports the drift of the loadbalancers by events and metrics
+	DriftPolicyReport = "report"
+	// DriftPolicyEnforce reports the drift and reconciles the services to correct it
+	DriftPolicyEnforce = "enforce"
+	// DriftPolicyDisabled disables the drift detection of a service
+	DriftPolicyDisabled = "disabled"
+)
+
 var ControllerCFG = &ControllerConfig{
 	CloudConfig: CloudCFG,
 }
@@ -55,22 +88,54 @@ var ControllerCFG = &ControllerConfig{
 // Flag stores the configuration for global usage
 type ControllerConfig struct {
 	config.KubeCloudSharedConfiguration
-	CloudConfigPath                 string
-	Controllers                     []string
-	FeatureGates                    string
-	ServerGroupBatchSize            int
-	MaxConcurrentActions            int
-	MaxThrottlingRetryTimes         int
-	LogLevel                        int
-	DryRun                          bool
+	CloudConfigPath         string
+	Controllers             []string
+	FeatureGates            string
+	ServerGroupBatchSize    int
+	MaxConcurrentActions    int
+	MaxThrottlingRetryTimes int
+	LogLevel                int
+	DryRun                  bool
+	// DryRunPlanDir is the directory the dry-run plans are written to, one file per Service or AlbConfig.
+	// The plans are written to configmaps in kube-system if it is empty
+	DryRunPlanDir string
+	// DryRunPlanFormat is the format of the dry-run plans, json or yaml
+	DryRunPlanFormat                string
 	NetWork                         string
 	NodeReconcileBatchSize          int
 	RouteReconcileBatchSize         int
 	SkipDisableSourceDestCheck      bool
 	NodeEventAggregationWaitSeconds int
 	EnableIMDSv2                    bool
-	RuntimeConfig                   RuntimeConfig
-	CloudConfig                     *CloudConfig
+	HealthCheckTimeout              time.Duration
+	// NodeTerminationTaintKey is the taint added to the nodes whose spot instances are about to be reclaimed
+	// or whose subscriptions are about to expire, an empty key disables the taint
+	NodeTerminationTaintKey    string
+	NodeTerminationTaintEffect string
+	// NodeExpirationWindow is how long before the expiry of a subscription the node is marked as terminating
+	NodeExpirationWindow time.Duration
+	// RouteGCGracePeriod is how long a route whose next hop is not a node of the cluster is kept before
+	// it is deleted, 0 disables the garbage collection
+	RouteGCGracePeriod time.Duration
+	// RouteGCDryRun only reports the stale routes instead of deleting them
+	RouteGCDryRun bool
+	// TracingEndpoint is the zipkin compatible endpoint the spans are exported to, an empty endpoint
+	// disables the tracing. It requires a binary built with the zipkin build tag
+	TracingEndpoint string
+	// TracingSampleRatio is the ratio of the reconciles to be traced
+	TracingSampleRatio float64
+	// DriftDetectionPeriod is how often the loadbalancers of the services are compared with their
+	// local models to find the changes made out of the controllers, 0 disables the detection
+	DriftDetectionPeriod time.Duration
+	// DriftPolicy is the default policy of the drift, report or enforce
+	DriftPolicy string
+	// DriftIgnoreFields are the resources or fields, e.g. Backend or Listener.AclId, whose changes are not drift
+	DriftIgnoreFields []string
+	// DefaultLBConfiguration is the namespace/name of the LoadBalancerConfiguration applied to all the
+	// services, overridden by the configuration referenced by a service and by its annotations
+	DefaultLBConfiguration string
+	RuntimeConfig          RuntimeConfig
+	CloudConfig            *CloudConfig
 }
 
 func (cfg *ControllerConfig) BindFlags(fs *pflag.FlagSet) {
@@ -81,11 +146,15 @@ func (cfg *ControllerConfig) BindFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVar(&cfg.Controllers, flagControllers, []string{"node", "route", "service", "nlb"}, "A list of controllers to enable.")
 	fs.BoolVar(&cfg.UseServiceAccountCredentials, flagUseServiceAccountCredentials, false, "If true, use individual service account credentials for each controller.")
 	fs.BoolVar(&cfg.ConfigureCloudRoutes, flagConfigureCloudRoutes, defaultConfigureCloudRoutes, "Should CIDRs allocated by allocate-node-cidrs be configured on the cloud provider.")
-	fs.StringVar(&cfg.ClusterCIDR, flagClusterCidr, "", "CIDR Range for Pods in cluster. Requires --allocate-node-cidrs to be true.")
+	fs.StringVar(&cfg.ClusterCIDR, flagClusterCidr, "", "CIDR Range for Pods in cluster. Requires --allocate-node-cidrs to be true. "+
+		"Comma-separated IPv4 and IPv6 CIDRs are allowed for dual-stack clusters.")
 	fs.BoolVar(&cfg.AllocateNodeCIDRs, flagAllocateNodeCIDRs, false, "Should CIDRs for Pods be allocated and set on the cloud provider.")
 	fs.IntVar(&cfg.CloudConfig.Global.ServiceMaxConcurrentReconciles, flagServiceMaxConcurrentReconciles, defaultServiceMaxConcurrentReconciles,
 		"[Deprecated, please use cloud-config config file instead] Maximum number of concurrently running reconcile loops for service")
 	fs.BoolVar(&cfg.DryRun, flagDryRun, false, "whether to perform a dry run")
+	fs.StringVar(&cfg.DryRunPlanDir, flagDryRunPlanDir, "",
+		"The directory the dry-run plans are written to, one file per Service or AlbConfig. Empty to write the plans to the ccm-dry-run-plan-* configmaps in kube-system.")
+	fs.StringVar(&cfg.DryRunPlanFormat, flagDryRunPlanFormat, defaultDryRunPlanFormat, "The format of the dry-run plans, json or yaml.")
 	fs.StringVar(&cfg.NetWork, flagNetwork, defaultNetwork, "Set network type for controller.")
 	fs.DurationVar(&cfg.RouteReconciliationPeriod.Duration, flagRouteReconciliationPeriod, defaultRouteReconciliationPeriod,
 		"The period for reconciling routes created for nodes by cloud provider. The minimum value is 1 minute")
@@ -100,6 +169,28 @@ func (cfg *ControllerConfig) BindFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&cfg.RouteReconcileBatchSize, "route-reconcile-batch-size", 50, "The batch size for syncing route status. The value range is 1-50")
 	fs.BoolVar(&cfg.SkipDisableSourceDestCheck, flagSkipDisableSourceDestCheck, false, "Skip disable source dest check for nodes")
 	fs.IntVar(&cfg.NodeEventAggregationWaitSeconds, flagNodeEventAggregationWaitSeconds, 1, "The wait second for aggregating node events in node & route controller")
+	fs.DurationVar(&cfg.HealthCheckTimeout, flagHealthCheckTimeout, defaultHealthCheckTimeout,
+		"The max time to wait for a backend to pass the health check of the load balancer before its readiness gate is marked as timeout")
+	fs.StringVar(&cfg.NodeTerminationTaintKey, flagNodeTerminationTaintKey, defaultNodeTerminationTaintKey,
+		"The taint key added to nodes whose spot instances are about to be reclaimed or whose subscriptions are about to expire. Empty to disable the taint.")
+	fs.StringVar(&cfg.NodeTerminationTaintEffect, flagNodeTerminationTaintEffect, defaultNodeTerminationTaintEffect,
+		"The effect of the node termination taint, one of NoSchedule, PreferNoSchedule and NoExecute.")
+	fs.DurationVar(&cfg.NodeExpirationWindow, flagNodeExpirationWindow, defaultNodeExpirationWindow,
+		"How long before the expiry of a subscription instance its node is marked as terminating. 0 to disable.")
+	fs.DurationVar(&cfg.RouteGCGracePeriod, flagRouteGCGracePeriod, defaultRouteGCGracePeriod,
+		"How long a route in the cluster cidr whose next hop instance is not a node of the cluster is kept before it is deleted. 0 to disable.")
+	fs.BoolVar(&cfg.RouteGCDryRun, flagRouteGCDryRun, false, "Only report the stale routes in logs and metrics instead of deleting them.")
+	fs.StringVar(&cfg.TracingEndpoint, flagTracingEndpoint, "",
+		"The zipkin compatible endpoint the spans of the reconciles and openapi calls are exported to, e.g. http://otel-collector:9411/api/v2/spans. Empty to disable tracing. Requires a binary built with -tags zipkin.")
+	fs.Float64Var(&cfg.TracingSampleRatio, flagTracingSampleRatio, defaultTracingSampleRatio, "The ratio of the reconciles to be traced, in range [0, 1].")
+	fs.DurationVar(&cfg.DriftDetectionPeriod, flagDriftDetectionPeriod, 0,
+		"How often the loadbalancers of services are compared with their desired state to detect the changes made out of the controller, e.g. in the console. 0 to disable.")
+	fs.StringVar(&cfg.DriftPolicy, flagDriftPolicy, defaultDriftPolicy,
+		"The default policy of the detected drift, report to record events and metrics only, or enforce to correct the drift.")
+	fs.StringSliceVar(&cfg.DriftIgnoreFields, flagDriftIgnoreFields, nil,
+		"The resources or fields whose changes are not regarded as drift, e.g. Backend,Listener.AclId.")
+	fs.StringVar(&cfg.DefaultLBConfiguration, flagDefaultLBConfiguration, defaultLBConfiguration,
+		"The namespace/name of the LoadBalancerConfiguration applied to all services, ignored if it does not exist. Empty to disable.")
 	cfg.RuntimeConfig.BindFlags(fs)
 }
 
@@ -125,6 +216,48 @@ func (cfg *ControllerConfig) Validate() error {
 		cfg.NodeEventAggregationWaitSeconds = 0
 	}
 
+	if cfg.HealthCheckTimeout <= 0 {
+		cfg.HealthCheckTimeout = defaultHealthCheckTimeout
+	}
+
+	switch cfg.NodeTerminationTaintEffect {
+	case "NoSchedule", "PreferNoSchedule", "NoExecute":
+	default:
+		return fmt.Errorf("--%s must be one of NoSchedule, PreferNoSchedule and NoExecute", flagNodeTerminationTaintEffect)
+	}
+
+	if cfg.NodeExpirationWindow < 0 {
+		cfg.NodeExpirationWindow = 0
+	}
+
+	if cfg.RouteGCGracePeriod < 0 {
+		cfg.RouteGCGracePeriod = 0
+	}
+
+	if cfg.DriftDetectionPeriod < 0 {
+		cfg.DriftDetectionPeriod = 0
+	}
+
+	switch cfg.DriftPolicy {
+	case DriftPolicyReport, DriftPolicyEnforce:
+	default:
+		return fmt.Errorf("--%s must be one of %s and %s", flagDriftPolicy, DriftPolicyReport, DriftPolicyEnforce)
+	}
+
+	if cfg.DefaultLBConfiguration != "" && len(strings.Split(cfg.DefaultLBConfiguration, "/")) != 2 {
+		return fmt.Errorf("--%s must be in the form of namespace/name", flagDefaultLBConfiguration)
+	}
+
+	if cfg.TracingSampleRatio < 0 || cfg.TracingSampleRatio > 1 {
+		return fmt.Errorf("--%s must be in range [0, 1]", flagTracingSampleRatio)
+	}
+
+	switch cfg.DryRunPlanFormat {
+	case "json", "yaml":
+	default:
+		return fmt.Errorf("--%s must be one of json and yaml", flagDryRunPlanFormat)
+	}
+
 	if cfg.MaxConcurrentActions <= 0 {
 		return fmt.Errorf("--max-concurrent-actions must be set to a positive integer")
 	}
@@ -6,6 +6,7 @@ import (
 	discovery "k8s.io/api/discovery/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"time"
 )
 
@@ -83,13 +84,19 @@ func (c *RuntimeConfig) BindFlags(fs *pflag.FlagSet) {
 
 func BuildRuntimeOptions(rtCfg RuntimeConfig) manager.Options {
 	return manager.Options{
-		ClientDisableCacheFor: []client.Object{
-			&v1.Node{},
-			&v1.Service{},
-			&v1.Endpoints{},
-			&discovery.EndpointSlice{},
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				DisableFor: []client.Object{
+					&v1.Node{},
+					&v1.Service{},
+					&v1.Endpoints{},
+					&discovery.EndpointSlice{},
+				},
+			},
+		},
+		Metrics: metricsserver.Options{
+			BindAddress: rtCfg.MetricsBindAddress,
 		},
-		MetricsBindAddress:         rtCfg.MetricsBindAddress,
 		HealthProbeBindAddress:     rtCfg.HealthProbeBindAddress,
 		PprofBindAddress:           rtCfg.PprofBindAddress,
 		LeaderElection:             rtCfg.LeaderElect,
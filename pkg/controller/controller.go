@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/gateway"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/node"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/pvtz"
@@ -22,6 +23,7 @@ func init() {
 		"ingress": ingress.Add,
 		"pvtz":    pvtz.Add,
 		"nlb":     nlbv2.Add,
+		"gateway": gateway.Add,
 	}
 }
 
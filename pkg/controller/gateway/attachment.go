@@ -0,0 +1,225 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	gatewayGroup  gatewayv1.Group = "gateway.networking.k8s.io"
+	gatewayKind   gatewayv1.Kind  = "Gateway"
+	httpRouteKind gatewayv1.Kind  = "HTTPRoute"
+
+	RouteReasonNoMatchingListenerHostname gatewayv1.RouteConditionReason = "NoMatchingListenerHostname"
+)
+
+// listenerResult is the result of validating a listener of the Gateway.
+type listenerResult struct {
+	listener   gatewayv1.Listener
+	conditions []metav1.Condition
+	valid      bool
+	routes     []*gatewayv1.HTTPRoute
+}
+
+// parentResult is the result of attaching a route to the Gateway through one of its parentRefs.
+type parentResult struct {
+	ref       gatewayv1.ParentReference
+	listeners []gatewayv1.SectionName
+	// reason and message are set if the route is not attached to any listener
+	reason  gatewayv1.RouteConditionReason
+	message string
+}
+
+// routeResult contains the results of all the parentRefs of a route which refer to the Gateway.
+type routeResult struct {
+	route   *gatewayv1.HTTPRoute
+	parents []*parentResult
+}
+
+func (r *routeResult) attached() bool {
+	for _, p := range r.parents {
+		if len(p.listeners) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateListeners accepts the HTTP and HTTPS listeners, a listener is conflicted if it shares the port
+// with an earlier listener of a different protocol, as an alb listener only serves one protocol.
+func validateListeners(gw *gatewayv1.Gateway) []*listenerResult {
+	var results []*listenerResult
+	protocolByPort := make(map[gatewayv1.PortNumber]gatewayv1.ProtocolType)
+	for _, l := range gw.Spec.Listeners {
+		res := &listenerResult{listener: l, valid: true}
+		accepted := metav1.Condition{
+			Type:   string(gatewayv1.ListenerConditionAccepted),
+			Status: metav1.ConditionTrue,
+			Reason: string(gatewayv1.ListenerReasonAccepted),
+		}
+		conflicted := metav1.Condition{
+			Type:   string(gatewayv1.ListenerConditionConflicted),
+			Status: metav1.ConditionFalse,
+			Reason: string(gatewayv1.ListenerReasonNoConflicts),
+		}
+		resolvedRefs := metav1.Condition{
+			Type:   string(gatewayv1.ListenerConditionResolvedRefs),
+			Status: metav1.ConditionTrue,
+			Reason: string(gatewayv1.ListenerReasonResolvedRefs),
+		}
+
+		switch l.Protocol {
+		case gatewayv1.HTTPProtocolType, gatewayv1.HTTPSProtocolType:
+			if p, ok := protocolByPort[l.Port]; ok && p != l.Protocol {
+				res.valid = false
+				conflicted.Status = metav1.ConditionTrue
+				conflicted.Reason = string(gatewayv1.ListenerReasonProtocolConflict)
+				conflicted.Message = fmt.Sprintf("port %d is used by a %s listener", l.Port, p)
+			} else {
+				protocolByPort[l.Port] = l.Protocol
+			}
+		default:
+			res.valid = false
+			accepted.Status = metav1.ConditionFalse
+			accepted.Reason = string(gatewayv1.ListenerReasonUnsupportedProtocol)
+			accepted.Message = fmt.Sprintf("protocol %s is not supported, supported protocols: HTTP, HTTPS", l.Protocol)
+		}
+
+		if l.TLS != nil {
+			if l.TLS.Mode != nil && *l.TLS.Mode == gatewayv1.TLSModePassthrough {
+				res.valid = false
+				accepted.Status = metav1.ConditionFalse
+				accepted.Reason = string(gatewayv1.ListenerReasonUnsupportedProtocol)
+				accepted.Message = "tls passthrough is not supported"
+			}
+			// alb serves certificates from CAS, they are discovered by the hostname of the listener
+			// or configured on the listener of the albconfig
+			if len(l.TLS.CertificateRefs) != 0 {
+				resolvedRefs.Status = metav1.ConditionFalse
+				resolvedRefs.Reason = string(gatewayv1.ListenerReasonInvalidCertificateRef)
+				resolvedRefs.Message = "certificateRefs are not supported, certificates are discovered in CAS by the listener hostname or configured in the albconfig"
+			}
+		}
+
+		res.conditions = []metav1.Condition{accepted, conflicted, resolvedRefs}
+		results = append(results, res)
+	}
+	return results
+}
+
+// isParentRefToGateway returns whether the parentRef of the route refers to the Gateway.
+func isParentRefToGateway(route *gatewayv1.HTTPRoute, ref gatewayv1.ParentReference, gw *gatewayv1.Gateway) bool {
+	if ref.Group != nil && *ref.Group != gatewayGroup {
+		return false
+	}
+	if ref.Kind != nil && *ref.Kind != gatewayKind {
+		return false
+	}
+	namespace := route.Namespace
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	return namespace == gw.Namespace && string(ref.Name) == gw.Name
+}
+
+// attachRoutes attaches the routes to the valid listeners of the Gateway by their parentRefs, allowedRoutes
+// of the listeners and hostnames.
+func (r *gatewayReconciler) attachRoutes(ctx context.Context, gw *gatewayv1.Gateway, listeners []*listenerResult, routes []*gatewayv1.HTTPRoute) ([]*routeResult, error) {
+	var results []*routeResult
+	for _, route := range routes {
+		res := &routeResult{route: route}
+		for _, ref := range route.Spec.ParentRefs {
+			if !isParentRefToGateway(route, ref, gw) {
+				continue
+			}
+			parent := &parentResult{ref: ref}
+			matched, allowed := false, false
+			for _, l := range listeners {
+				if !l.valid {
+					continue
+				}
+				if ref.SectionName != nil && *ref.SectionName != l.listener.Name {
+					continue
+				}
+				if ref.Port != nil && *ref.Port != l.listener.Port {
+					continue
+				}
+				matched = true
+				ok, err := r.isRouteAllowed(ctx, gw, l.listener, route)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+				allowed = true
+				if _, ok := albconfigmanager.ComputeGatewayRouteHostnames(l.listener, route); !ok {
+					continue
+				}
+				parent.listeners = append(parent.listeners, l.listener.Name)
+				// a route may attach to the same listener through more than one parentRef
+				if n := len(l.routes); n == 0 || l.routes[n-1] != route {
+					l.routes = append(l.routes, route)
+				}
+			}
+			if len(parent.listeners) == 0 {
+				switch {
+				case !matched:
+					parent.reason = gatewayv1.RouteReasonNoMatchingParent
+					parent.message = "no valid listener matches the sectionName and port of the parentRef"
+				case !allowed:
+					parent.reason = gatewayv1.RouteReasonNotAllowedByListeners
+					parent.message = "the route is not allowed by the allowedRoutes of the listeners"
+				default:
+					parent.reason = RouteReasonNoMatchingListenerHostname
+					parent.message = "no hostname of the route matches the hostnames of the listeners"
+				}
+			}
+			res.parents = append(res.parents, parent)
+		}
+		if len(res.parents) != 0 {
+			results = append(results, res)
+		}
+	}
+	return results, nil
+}
+
+// isRouteAllowed checks the namespace of the route against allowedRoutes of the listener, routes are
+// only allowed from the namespace of the Gateway by default.
+func (r *gatewayReconciler) isRouteAllowed(ctx context.Context, gw *gatewayv1.Gateway, l gatewayv1.Listener, route *gatewayv1.HTTPRoute) (bool, error) {
+	from := gatewayv1.NamespacesFromSame
+	var selector *metav1.LabelSelector
+	if l.AllowedRoutes != nil && l.AllowedRoutes.Namespaces != nil {
+		if l.AllowedRoutes.Namespaces.From != nil {
+			from = *l.AllowedRoutes.Namespaces.From
+		}
+		selector = l.AllowedRoutes.Namespaces.Selector
+	}
+
+	switch from {
+	case gatewayv1.NamespacesFromAll:
+		return true, nil
+	case gatewayv1.NamespacesFromSelector:
+		if selector == nil {
+			return false, nil
+		}
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return false, nil
+		}
+		ns := &corev1.Namespace{}
+		if err := r.kubeClient.Get(ctx, types.NamespacedName{Name: route.Namespace}, ns); err != nil {
+			return false, err
+		}
+		return s.Matches(labels.Set(ns.Labels)), nil
+	default:
+		return route.Namespace == gw.Namespace, nil
+	}
+}
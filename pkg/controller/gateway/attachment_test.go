@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func newTestGateway(listeners ...gatewayv1.Listener) *gatewayv1.Gateway {
+	gw := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "gw"}}
+	gw.Spec.GatewayClassName = "alb"
+	gw.Spec.Listeners = listeners
+	return gw
+}
+
+func newTestListener(name string, port gatewayv1.PortNumber, protocol gatewayv1.ProtocolType) gatewayv1.Listener {
+	return gatewayv1.Listener{Name: gatewayv1.SectionName(name), Port: port, Protocol: protocol}
+}
+
+func TestValidateListeners(t *testing.T) {
+	passthrough := gatewayv1.TLSModePassthrough
+	https := newTestListener("https", 443, gatewayv1.HTTPSProtocolType)
+	https.TLS = &gatewayv1.GatewayTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "tls"}}}
+	tlsPassthrough := newTestListener("passthrough", 8443, gatewayv1.HTTPSProtocolType)
+	tlsPassthrough.TLS = &gatewayv1.GatewayTLSConfig{Mode: &passthrough}
+
+	results := validateListeners(newTestGateway(
+		newTestListener("http", 80, gatewayv1.HTTPProtocolType),
+		newTestListener("http-foo", 80, gatewayv1.HTTPProtocolType),
+		newTestListener("conflicted", 80, gatewayv1.HTTPSProtocolType),
+		newTestListener("tcp", 9000, gatewayv1.ProtocolType("TCP")),
+		https,
+		tlsPassthrough,
+	))
+	assert.Len(t, results, 6)
+
+	cases := []struct {
+		valid        bool
+		accepted     metav1.ConditionStatus
+		conflicted   metav1.ConditionStatus
+		resolvedRefs metav1.ConditionStatus
+	}{
+		// listeners of the same protocol share the port
+		{true, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionTrue},
+		{true, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionTrue},
+		{false, metav1.ConditionTrue, metav1.ConditionTrue, metav1.ConditionTrue},
+		{false, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue},
+		// the certificates are discovered in CAS instead of the certificateRefs
+		{true, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse},
+		{false, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue},
+	}
+	for i, c := range cases {
+		res := results[i]
+		assert.Equal(t, c.valid, res.valid, res.listener.Name)
+		assert.Equal(t, c.accepted, meta.FindStatusCondition(res.conditions, string(gatewayv1.ListenerConditionAccepted)).Status, res.listener.Name)
+		assert.Equal(t, c.conflicted, meta.FindStatusCondition(res.conditions, string(gatewayv1.ListenerConditionConflicted)).Status, res.listener.Name)
+		assert.Equal(t, c.resolvedRefs, meta.FindStatusCondition(res.conditions, string(gatewayv1.ListenerConditionResolvedRefs)).Status, res.listener.Name)
+	}
+}
+
+func TestAttachRoutes(t *testing.T) {
+	all := gatewayv1.NamespacesFromAll
+	selector := gatewayv1.NamespacesFromSelector
+	foo := gatewayv1.Hostname("foo.example.com")
+
+	public := newTestListener("public", 80, gatewayv1.HTTPProtocolType)
+	public.AllowedRoutes = &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{From: &all}}
+	selected := newTestListener("selected", 8080, gatewayv1.HTTPProtocolType)
+	selected.AllowedRoutes = &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{
+		From:     &selector,
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+	}}
+	private := newTestListener("private", 8081, gatewayv1.HTTPProtocolType)
+	private.Hostname = &foo
+	invalid := newTestListener("invalid", 9000, gatewayv1.ProtocolType("TCP"))
+	gw := newTestGateway(public, selected, private, invalid)
+
+	sectionRoute := func(name, namespace, section string, hostnames ...gatewayv1.Hostname) *gatewayv1.HTTPRoute {
+		route := newTestRoute(name, "gw")
+		route.Namespace = namespace
+		route.Spec.Hostnames = hostnames
+		gwNamespace := gatewayv1.Namespace(corev1.NamespaceDefault)
+		route.Spec.ParentRefs[0].Namespace = &gwNamespace
+		if section != "" {
+			sectionName := gatewayv1.SectionName(section)
+			route.Spec.ParentRefs[0].SectionName = &sectionName
+		}
+		return route
+	}
+	routes := []*gatewayv1.HTTPRoute{
+		sectionRoute("all-listeners", corev1.NamespaceDefault, ""),
+		sectionRoute("team-a", "team-a", ""),
+		sectionRoute("team-b", "team-b", "selected"),
+		sectionRoute("unknown-section", corev1.NamespaceDefault, "unknown"),
+		sectionRoute("invalid-section", corev1.NamespaceDefault, "invalid"),
+		sectionRoute("bar", corev1.NamespaceDefault, "private", "bar.example.com"),
+		// the route refers to another gateway
+		newTestRoute("other", "other-gw"),
+	}
+
+	r := &gatewayReconciler{
+		kubeClient: newTestClient(t,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: corev1.NamespaceDefault}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"team": "b"}}},
+		),
+		logger: logr.Discard(),
+	}
+	listeners := validateListeners(gw)
+	results, err := r.attachRoutes(context.TODO(), gw, listeners, routes)
+	assert.NoError(t, err)
+	assert.Len(t, results, 6)
+
+	byName := make(map[string]*parentResult)
+	for _, res := range results {
+		assert.Len(t, res.parents, 1)
+		byName[res.route.Name] = res.parents[0]
+	}
+	assert.Equal(t, []gatewayv1.SectionName{"public", "private"}, byName["all-listeners"].listeners)
+	assert.Equal(t, []gatewayv1.SectionName{"public", "selected"}, byName["team-a"].listeners)
+	assert.Empty(t, byName["team-b"].listeners)
+	assert.Equal(t, gatewayv1.RouteReasonNotAllowedByListeners, byName["team-b"].reason)
+	assert.Equal(t, gatewayv1.RouteReasonNoMatchingParent, byName["unknown-section"].reason)
+	assert.Equal(t, gatewayv1.RouteReasonNoMatchingParent, byName["invalid-section"].reason)
+	assert.Equal(t, RouteReasonNoMatchingListenerHostname, byName["bar"].reason)
+
+	var attached []string
+	for _, l := range listeners {
+		for _, route := range l.routes {
+			attached = append(attached, string(l.listener.Name)+"/"+route.Name)
+		}
+	}
+	assert.Equal(t, []string{"public/all-listeners", "public/team-a", "selected/team-a", "private/all-listeners"}, attached)
+}
@@ -0,0 +1,118 @@
+package gateway
+
+import (
+	"context"
+
+	discovery "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// mapGatewayClass enqueues the Gateways of the GatewayClass.
+func (r *gatewayReconciler) mapGatewayClass(ctx context.Context, obj client.Object) []reconcile.Request {
+	gwList := &gatewayv1.GatewayList{}
+	if err := r.kubeClient.List(ctx, gwList); err != nil {
+		r.logger.Error(err, "list gateways failed")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, gw := range gwList.Items {
+		if string(gw.Spec.GatewayClassName) == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name}})
+		}
+	}
+	return requests
+}
+
+// mapAlbConfig enqueues the Gateways of the GatewayClasses which reference the AlbConfig.
+func (r *gatewayReconciler) mapAlbConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	classList := &gatewayv1.GatewayClassList{}
+	if err := r.kubeClient.List(ctx, classList); err != nil {
+		r.logger.Error(err, "list gateway classes failed")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range classList.Items {
+		class := &classList.Items[i]
+		ref := class.Spec.ParametersRef
+		if class.Spec.ControllerName != ControllerName || ref == nil || ref.Kind != albConfigKind || ref.Name != obj.GetName() {
+			continue
+		}
+		namespace := albconfigmanager.ALBConfigNamespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		if namespace == obj.GetNamespace() && string(ref.Group) == v1.SchemeGroupVersion.Group {
+			requests = append(requests, r.mapGatewayClass(ctx, class)...)
+		}
+	}
+	return requests
+}
+
+// mapHTTPRoute enqueues the Gateways referenced by the parentRefs of the route, and the Gateways
+// which have written the status of the route, so that a detached route gets its status removed.
+func (r *gatewayReconciler) mapHTTPRoute(_ context.Context, obj client.Object) []reconcile.Request {
+	route, ok := obj.(*gatewayv1.HTTPRoute)
+	if !ok {
+		return nil
+	}
+	return routeParentRequests(route)
+}
+
+// mapBackend enqueues the Gateways of the routes referencing the service.
+func (r *gatewayReconciler) mapBackend(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.mapService(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+}
+
+// mapEndpointSlice enqueues the Gateways of the routes referencing the service of the EndpointSlice.
+func (r *gatewayReconciler) mapEndpointSlice(ctx context.Context, obj client.Object) []reconcile.Request {
+	name, ok := obj.GetLabels()[discovery.LabelServiceName]
+	if !ok || name == "" {
+		return nil
+	}
+	return r.mapService(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: name})
+}
+
+func (r *gatewayReconciler) mapService(ctx context.Context, key types.NamespacedName) []reconcile.Request {
+	routes := &gatewayv1.HTTPRouteList{}
+	if err := r.kubeClient.List(ctx, routes, client.InNamespace(key.Namespace)); err != nil {
+		r.logger.Error(err, "list httproutes failed")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		for _, ref := range routeBackendRefs(route) {
+			if isServiceRef(route, ref) && string(ref.Name) == key.Name {
+				requests = append(requests, routeParentRequests(route)...)
+				break
+			}
+		}
+	}
+	return requests
+}
+
+func routeParentRequests(route *gatewayv1.HTTPRoute) []reconcile.Request {
+	refs := append([]gatewayv1.ParentReference(nil), route.Spec.ParentRefs...)
+	for _, s := range route.Status.Parents {
+		if s.ControllerName == ControllerName {
+			refs = append(refs, s.ParentRef)
+		}
+	}
+	var requests []reconcile.Request
+	for _, ref := range refs {
+		if (ref.Group != nil && *ref.Group != gatewayGroup) || (ref.Kind != nil && *ref.Kind != gatewayKind) {
+			continue
+		}
+		namespace := route.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: string(ref.Name)}})
+	}
+	return requests
+}
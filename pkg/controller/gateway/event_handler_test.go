@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func newTestClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1.SchemeBuilder.AddToScheme(scheme))
+	assert.NoError(t, gatewayv1.SchemeBuilder.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&gatewayv1.Gateway{}, &gatewayv1.GatewayClass{}, &gatewayv1.HTTPRoute{}).Build()
+}
+
+func newTestRoute(name string, gateway string, backends ...string) *gatewayv1.HTTPRoute {
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: name},
+	}
+	route.Spec.ParentRefs = []gatewayv1.ParentReference{{Name: gatewayv1.ObjectName(gateway)}}
+	rule := gatewayv1.HTTPRouteRule{}
+	for _, b := range backends {
+		port := gatewayv1.PortNumber(80)
+		rule.BackendRefs = append(rule.BackendRefs, gatewayv1.HTTPBackendRef{
+			BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{
+				Name: gatewayv1.ObjectName(b),
+				Port: &port,
+			}},
+		})
+	}
+	route.Spec.Rules = []gatewayv1.HTTPRouteRule{rule}
+	return route
+}
+
+func TestMapEndpointSlice(t *testing.T) {
+	r := &gatewayReconciler{
+		kubeClient: newTestClient(t, newTestRoute("route-a", "gw-a", "svc-a"), newTestRoute("route-b", "gw-b", "svc-b")),
+		logger:     logr.Discard(),
+	}
+	es := &discovery.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: corev1.NamespaceDefault,
+			Name:      "svc-a-x8k2p",
+			Labels:    map[string]string{discovery.LabelServiceName: "svc-a"},
+		},
+	}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "gw-a"}}},
+		r.mapEndpointSlice(context.TODO(), es))
+
+	// the slices without the service name label are not mapped
+	es.Labels = nil
+	assert.Empty(t, r.mapEndpointSlice(context.TODO(), es))
+
+	// the services are mapped by name
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "svc-b"}}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "gw-b"}}},
+		r.mapBackend(context.TODO(), svc))
+}
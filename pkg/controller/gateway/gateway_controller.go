@@ -0,0 +1,463 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdkutils "github.com/aliyun/alibaba-cloud-sdk-go/sdk/utils"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/applier"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/backend"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	servicemanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/service_manager"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// ControllerName is the controllerName of the GatewayClasses managed by this controller
+	ControllerName gatewayv1.GatewayController = "alibabacloud.com/alb"
+
+	gatewayControllerName          = "gateway-controller"
+	defaultMaxConcurrentReconciles = 3
+	albConfigKind                  = "AlbConfig"
+)
+
+func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
+	logger := ctrl.Log.WithName("controllers").WithName(gatewayControllerName)
+	secretCertManager := albconfigmanager.NewCASSecretCertManager(mgr.GetClient(), ctx.Provider(), logger)
+	storer := &cacheStore{client: mgr.GetClient()}
+	r := &gatewayReconciler{
+		cloud:            ctx.Provider(),
+		kubeClient:       mgr.GetClient(),
+		eventRecorder:    mgr.GetEventRecorderFor("gateway"),
+		logger:           logger,
+		gatewayBuilder:   albconfigmanager.NewDefaultAlbConfigManagerBuilder(mgr.GetClient(), ctx.Provider(), secretCertManager, logger),
+		albconfigApplier: applier.NewAlbConfigManagerApplier(storer, mgr.GetClient(), ctx.Provider(), util.IngressTagKeyPrefix, logger),
+		serverBuilder:    servicemanager.NewDefaultServiceStackBuilder(backend.NewBackendManager(storer, mgr.GetClient(), ctx.Provider(), logger)),
+		serverApplier:    applier.NewServiceManagerApplier(mgr.GetClient(), ctx.Provider(), logger),
+		finalizerManager: helper.NewDefaultFinalizerManager(mgr.GetClient()),
+	}
+
+	recoverPanic := true
+	c, err := controller.New(gatewayControllerName, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: defaultMaxConcurrentReconciles,
+		RecoverPanic:            &recoverPanic,
+	})
+	if err != nil {
+		return err
+	}
+	return r.setupWatches(c, mgr)
+}
+
+type gatewayReconciler struct {
+	cloud            prvd.Provider
+	kubeClient       client.Client
+	eventRecorder    record.EventRecorder
+	logger           logr.Logger
+	gatewayBuilder   albconfigmanager.GatewayBuilder
+	albconfigApplier applier.AlbConfigManagerApplier
+	serverBuilder    servicemanager.Builder
+	serverApplier    applier.ServiceManagerApplier
+	finalizerManager helper.FinalizerManager
+}
+
+func (r *gatewayReconciler) setupWatches(c controller.Controller, mgr manager.Manager) error {
+	watches := []struct {
+		source  source.Source
+		handler handler.EventHandler
+	}{
+		{source.Kind(mgr.GetCache(), &gatewayv1.Gateway{}), &handler.EnqueueRequestForObject{}},
+		{source.Kind(mgr.GetCache(), &gatewayv1.GatewayClass{}), handler.EnqueueRequestsFromMapFunc(r.mapGatewayClass)},
+		{source.Kind(mgr.GetCache(), &gatewayv1.HTTPRoute{}), handler.EnqueueRequestsFromMapFunc(r.mapHTTPRoute)},
+		{source.Kind(mgr.GetCache(), &v1.AlbConfig{}), handler.EnqueueRequestsFromMapFunc(r.mapAlbConfig)},
+		{source.Kind(mgr.GetCache(), &corev1.Service{}), handler.EnqueueRequestsFromMapFunc(r.mapBackend)},
+		{source.Kind(mgr.GetCache(), &discovery.EndpointSlice{}), handler.EnqueueRequestsFromMapFunc(r.mapEndpointSlice)},
+	}
+	for _, w := range watches {
+		if err := c.Watch(w.source, w.handler); err != nil {
+			return fmt.Errorf("watch resource %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func (r *gatewayReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	traceID := sdkutils.GetTimeInFormatISO8601()
+	ctx = context.WithValue(ctx, util.TraceID, traceID)
+
+	startTime := time.Now()
+	r.logger.Info("start reconcile",
+		"request", req.String(),
+		"traceID", traceID,
+		"startTime", startTime)
+	err := r.reconcile(ctx, req)
+	if err != nil {
+		r.logger.Error(err, "finish reconcile",
+			"request", req.String(),
+			"traceID", traceID,
+			"elapsedTime", time.Since(startTime).Milliseconds())
+		return reconcile.Result{}, err
+	}
+	r.logger.Info("finish reconcile",
+		"request", req.String(),
+		"traceID", traceID,
+		"elapsedTime", time.Since(startTime).Milliseconds())
+	return reconcile.Result{}, nil
+}
+
+func (r *gatewayReconciler) reconcile(ctx context.Context, req reconcile.Request) error {
+	gw := &gatewayv1.Gateway{}
+	if err := r.kubeClient.Get(ctx, req.NamespacedName, gw); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	class := &gatewayv1.GatewayClass{}
+	err := r.kubeClient.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, class)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	owned := err == nil && class.Spec.ControllerName == ControllerName
+	if !owned || !gw.DeletionTimestamp.IsZero() {
+		// the gateway is deleted or handed over to another controller
+		return r.cleanupGatewayResources(ctx, gw)
+	}
+	return r.reconcileGatewayResources(ctx, gw, class)
+}
+
+func (r *gatewayReconciler) cleanupGatewayResources(ctx context.Context, gw *gatewayv1.Gateway) error {
+	if !helper.HasFinalizer(gw, util.GatewayFinalizer) {
+		return nil
+	}
+	stack := core.NewDefaultManager(core.StackID(albconfigmanager.GatewayGroupID(gw)))
+	if err := r.albconfigApplier.ApplyStack(ctx, stack); err != nil {
+		r.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedApplyModel, helper.GetLogMessage(err))
+		return err
+	}
+
+	routes, err := r.listHTTPRoutes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, route := range routes {
+		if err := r.updateRouteStatus(ctx, route, gw, nil, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := r.finalizerManager.RemoveFinalizers(ctx, gw, util.GatewayFinalizer); err != nil {
+		r.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedRemoveFinalizer, helper.GetLogMessage(err))
+		return err
+	}
+	return nil
+}
+
+func (r *gatewayReconciler) reconcileGatewayResources(ctx context.Context, gw *gatewayv1.Gateway, class *gatewayv1.GatewayClass) error {
+	if err := r.finalizerManager.AddFinalizers(ctx, gw, util.GatewayFinalizer); err != nil {
+		r.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedAddFinalizer, helper.GetLogMessage(err))
+		return err
+	}
+
+	albconfig, paramErr := r.loadAlbConfig(ctx, class)
+	if paramErr != nil && !isInvalidParameters(paramErr) {
+		return paramErr
+	}
+	if err := r.updateGatewayClassStatus(ctx, class, paramErr); err != nil {
+		return err
+	}
+
+	listeners := validateListeners(gw)
+	routes, err := r.listHTTPRoutes(ctx)
+	if err != nil {
+		return err
+	}
+	results, err := r.attachRoutes(ctx, gw, listeners, routes)
+	if err != nil {
+		return err
+	}
+
+	var (
+		lb        *albmodel.AlbLoadBalancer
+		routeErrs map[types.NamespacedName]error
+		buildErr  error
+	)
+	if paramErr == nil {
+		gwCtx := &albconfigmanager.GatewayStackContext{
+			Gateway:   gw,
+			AlbConfig: albconfig,
+			Routes:    make(map[gatewayv1.SectionName][]*gatewayv1.HTTPRoute),
+		}
+		for _, l := range listeners {
+			if !l.valid {
+				continue
+			}
+			gwCtx.Listeners = append(gwCtx.Listeners, l.listener)
+			gwCtx.Routes[l.listener.Name] = l.routes
+		}
+		lb, routeErrs, buildErr = r.buildAndApply(ctx, gwCtx)
+	}
+
+	if err := r.updateGatewayStatus(ctx, gw, listeners, lb, paramErr, buildErr); err != nil {
+		r.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedUpdateStatus, helper.GetLogMessage(err))
+		return err
+	}
+	for _, res := range results {
+		refsErr, err := r.resolveBackendRefs(ctx, res.route)
+		if err != nil {
+			return err
+		}
+		if err := r.updateRouteStatus(ctx, res.route, gw, res.parents, routeErrs[util.NamespacedName(res.route)], refsErr); err != nil {
+			return err
+		}
+	}
+	// remove the stale status of the routes which no longer refer to the gateway
+	attached := make(map[types.NamespacedName]bool)
+	for _, res := range results {
+		attached[util.NamespacedName(res.route)] = true
+	}
+	for _, route := range routes {
+		if attached[util.NamespacedName(route)] {
+			continue
+		}
+		if err := r.updateRouteStatus(ctx, route, gw, nil, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	if paramErr != nil {
+		r.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(paramErr))
+		return nil
+	}
+	if buildErr != nil {
+		return buildErr
+	}
+	if err := r.syncServers(ctx, results, routeErrs); err != nil {
+		return err
+	}
+	r.eventRecorder.Event(gw, corev1.EventTypeNormal, helper.IngressEventReasonSuccessfullyReconciled, "Successfully reconciled")
+	return nil
+}
+
+func (r *gatewayReconciler) buildAndApply(ctx context.Context, gwCtx *albconfigmanager.GatewayStackContext) (*albmodel.AlbLoadBalancer, map[types.NamespacedName]error, error) {
+	gw := gwCtx.Gateway
+	traceID := ctx.Value(util.TraceID)
+
+	buildStartTime := time.Now()
+	r.logger.Info("start build and apply stack",
+		"gateway", util.NamespacedName(gw).String(),
+		"traceID", traceID,
+		"startTime", buildStartTime)
+
+	stack, lb, routeErrs, err := r.gatewayBuilder.BuildGateway(ctx, gwCtx)
+	if err != nil {
+		r.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
+		return nil, nil, err
+	}
+	r.logger.Info("successfully built stack",
+		"gateway", util.NamespacedName(gw).String(),
+		"traceID", traceID,
+		"buildElapsedTime", time.Since(buildStartTime).Milliseconds())
+
+	applyStartTime := time.Now()
+	if err := r.albconfigApplier.ApplyStack(ctx, stack); err != nil {
+		r.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedApplyModel, helper.GetLogMessage(err))
+		return nil, nil, err
+	}
+	r.logger.Info("successfully applied stack",
+		"gateway", util.NamespacedName(gw).String(),
+		"traceID", traceID,
+		"applyElapsedTime", time.Since(applyStartTime).Milliseconds())
+	return lb, routeErrs, nil
+}
+
+// syncServers registers the endpoints of the services referenced by the accepted routes to the server groups,
+// the server groups are tagged with the route name just like the ones of an ingress.
+func (r *gatewayReconciler) syncServers(ctx context.Context, results []*routeResult, routeErrs map[types.NamespacedName]error) error {
+	servicePortToRouteNames := make(map[types.NamespacedName]map[int32][]string)
+	for _, res := range results {
+		route := res.route
+		if !res.attached() || routeErrs[util.NamespacedName(route)] != nil {
+			continue
+		}
+		for _, ref := range routeBackendRefs(route) {
+			if !isServiceRef(route, ref) || ref.Port == nil {
+				continue
+			}
+			key := types.NamespacedName{Namespace: route.Namespace, Name: string(ref.Name)}
+			if _, ok := servicePortToRouteNames[key]; !ok {
+				servicePortToRouteNames[key] = make(map[int32][]string)
+			}
+			port := int32(*ref.Port)
+			if !containsString(servicePortToRouteNames[key][port], route.Name) {
+				servicePortToRouteNames[key][port] = append(servicePortToRouteNames[key][port], route.Name)
+			}
+		}
+	}
+
+	retry := false
+	for key, portToRouteNames := range servicePortToRouteNames {
+		svcStackCtx := &albmodel.ServiceStackContext{
+			ClusterID:                 r.cloud.ClusterID(),
+			ServiceNamespace:          key.Namespace,
+			ServiceName:               key.Name,
+			ServicePortToIngressNames: portToRouteNames,
+		}
+		svc := &corev1.Service{}
+		if err := r.kubeClient.Get(ctx, key, svc); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			svcStackCtx.IsServiceNotFound = true
+		} else {
+			svcStackCtx.Service = svc
+		}
+
+		serverStack, err := r.serverBuilder.Build(ctx, svcStackCtx)
+		if err != nil {
+			return fmt.Errorf("build service stack model error: %v", err)
+		}
+		if err := r.serverApplier.Apply(ctx, r.cloud, serverStack); err != nil {
+			return err
+		}
+		if serverStack.ContainsPotentialReadyEndpoints {
+			retry = true
+		}
+	}
+	if retry {
+		return fmt.Errorf("retry potential ready endpoints")
+	}
+	return nil
+}
+
+// invalidParametersError indicates the parametersRef of the GatewayClass is invalid.
+type invalidParametersError struct {
+	msg string
+}
+
+func (e *invalidParametersError) Error() string {
+	return e.msg
+}
+
+func isInvalidParameters(err error) bool {
+	_, ok := err.(*invalidParametersError)
+	return ok
+}
+
+// loadAlbConfig loads the AlbConfig referenced by the parametersRef of the GatewayClass, the namespace
+// defaults to the namespace of the AlbConfigs created for ingresses.
+func (r *gatewayReconciler) loadAlbConfig(ctx context.Context, class *gatewayv1.GatewayClass) (*v1.AlbConfig, error) {
+	ref := class.Spec.ParametersRef
+	if ref == nil {
+		return nil, &invalidParametersError{msg: "parametersRef is required to reference an AlbConfig"}
+	}
+	if string(ref.Group) != v1.SchemeGroupVersion.Group || ref.Kind != albConfigKind {
+		return nil, &invalidParametersError{msg: fmt.Sprintf("parametersRef %s/%s is not supported, only %s/%s is supported",
+			ref.Group, ref.Kind, v1.SchemeGroupVersion.Group, albConfigKind)}
+	}
+	key := types.NamespacedName{Namespace: albconfigmanager.ALBConfigNamespace, Name: ref.Name}
+	if ref.Namespace != nil {
+		key.Namespace = string(*ref.Namespace)
+	}
+	albconfig := &v1.AlbConfig{}
+	if err := r.kubeClient.Get(ctx, key, albconfig); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, &invalidParametersError{msg: fmt.Sprintf("albconfig %s not found", key)}
+		}
+		return nil, err
+	}
+	if albconfig.Spec.LoadBalancer == nil {
+		return nil, &invalidParametersError{msg: fmt.Sprintf("albconfig %s has no load balancer config", key)}
+	}
+	return albconfig, nil
+}
+
+func (r *gatewayReconciler) listHTTPRoutes(ctx context.Context) ([]*gatewayv1.HTTPRoute, error) {
+	routeList := &gatewayv1.HTTPRouteList{}
+	if err := r.kubeClient.List(ctx, routeList); err != nil {
+		return nil, err
+	}
+	var routes []*gatewayv1.HTTPRoute
+	for i := range routeList.Items {
+		routes = append(routes, &routeList.Items[i])
+	}
+	return routes, nil
+}
+
+// resolveBackendRefs returns the reason why the backends of the route can not be resolved.
+func (r *gatewayReconciler) resolveBackendRefs(ctx context.Context, route *gatewayv1.HTTPRoute) (*parentResult, error) {
+	for _, ref := range routeBackendRefs(route) {
+		if !isServiceRef(route, ref) {
+			return &parentResult{
+				reason:  gatewayv1.RouteReasonInvalidKind,
+				message: fmt.Sprintf("backend %s is not a service in the namespace of the route", ref.Name),
+			}, nil
+		}
+		svc := &corev1.Service{}
+		err := r.kubeClient.Get(ctx, types.NamespacedName{Namespace: route.Namespace, Name: string(ref.Name)}, svc)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return &parentResult{
+					reason:  gatewayv1.RouteReasonBackendNotFound,
+					message: fmt.Sprintf("service %s/%s not found", route.Namespace, ref.Name),
+				}, nil
+			}
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// routeBackendRefs returns the backends and the mirror backends of the route.
+func routeBackendRefs(route *gatewayv1.HTTPRoute) []gatewayv1.BackendObjectReference {
+	var refs []gatewayv1.BackendObjectReference
+	for _, rule := range route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			refs = append(refs, ref.BackendObjectReference)
+		}
+		for _, f := range rule.Filters {
+			if f.Type == gatewayv1.HTTPRouteFilterRequestMirror && f.RequestMirror != nil {
+				refs = append(refs, f.RequestMirror.BackendRef)
+			}
+		}
+	}
+	return refs
+}
+
+func isServiceRef(route *gatewayv1.HTTPRoute, ref gatewayv1.BackendObjectReference) bool {
+	if ref.Group != nil && *ref.Group != "" {
+		return false
+	}
+	if ref.Kind != nil && *ref.Kind != "Service" {
+		return false
+	}
+	return ref.Namespace == nil || string(*ref.Namespace) == route.Namespace
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package gateway
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// gatewayReasonInvalidParameters is the reason of the Accepted condition of a Gateway whose parameters
+// are invalid, it is defined by Gateway API v1.1 and later
+const gatewayReasonInvalidParameters gatewayv1.GatewayConditionReason = "InvalidParameters"
+
+func (r *gatewayReconciler) updateGatewayClassStatus(ctx context.Context, class *gatewayv1.GatewayClass, paramErr error) error {
+	old := class.DeepCopy()
+	accepted := metav1.Condition{
+		Type:               string(gatewayv1.GatewayClassConditionStatusAccepted),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1.GatewayClassReasonAccepted),
+		ObservedGeneration: class.Generation,
+	}
+	if paramErr != nil {
+		accepted.Status = metav1.ConditionFalse
+		accepted.Reason = string(gatewayv1.GatewayClassReasonInvalidParameters)
+		accepted.Message = paramErr.Error()
+	}
+	meta.SetStatusCondition(&class.Status.Conditions, accepted)
+	if equality.Semantic.DeepEqual(old.Status, class.Status) {
+		return nil
+	}
+	return r.kubeClient.Status().Patch(ctx, class, client.MergeFrom(old))
+}
+
+// updateGatewayStatus reports the address of the load balancer and the results of the listeners.
+// A Gateway is programmed once its stack is applied and the load balancer gets a dns name.
+func (r *gatewayReconciler) updateGatewayStatus(ctx context.Context, gw *gatewayv1.Gateway, listeners []*listenerResult,
+	lb *albmodel.AlbLoadBalancer, paramErr, buildErr error) error {
+	old := gw.DeepCopy()
+
+	accepted := metav1.Condition{
+		Type:   string(gatewayv1.GatewayConditionAccepted),
+		Status: metav1.ConditionTrue,
+		Reason: string(gatewayv1.GatewayReasonAccepted),
+	}
+	programmed := metav1.Condition{
+		Type:   string(gatewayv1.GatewayConditionProgrammed),
+		Status: metav1.ConditionTrue,
+		Reason: string(gatewayv1.GatewayReasonProgrammed),
+	}
+	var dnsName string
+	if lb != nil && lb.Status != nil {
+		dnsName = lb.Status.DNSName
+	}
+	switch {
+	case paramErr != nil:
+		accepted.Status = metav1.ConditionFalse
+		accepted.Reason = string(gatewayReasonInvalidParameters)
+		accepted.Message = paramErr.Error()
+		programmed.Status = metav1.ConditionFalse
+		programmed.Reason = string(gatewayv1.GatewayReasonInvalid)
+		programmed.Message = paramErr.Error()
+	case buildErr != nil:
+		programmed.Status = metav1.ConditionFalse
+		programmed.Reason = string(gatewayv1.GatewayReasonInvalid)
+		programmed.Message = buildErr.Error()
+	case dnsName == "":
+		programmed.Status = metav1.ConditionFalse
+		programmed.Reason = string(gatewayv1.GatewayReasonPending)
+		programmed.Message = "waiting for the dns name of the load balancer"
+	}
+	setConditions(&gw.Status.Conditions, gw.Generation, accepted, programmed)
+
+	gw.Status.Addresses = nil
+	if dnsName != "" {
+		addressType := gatewayv1.HostnameAddressType
+		gw.Status.Addresses = []gatewayv1.GatewayStatusAddress{{Type: &addressType, Value: dnsName}}
+	}
+
+	var statuses []gatewayv1.ListenerStatus
+	for _, l := range listeners {
+		status := gatewayv1.ListenerStatus{
+			Name:           l.listener.Name,
+			SupportedKinds: []gatewayv1.RouteGroupKind{{Group: groupPtr(gatewayGroup), Kind: httpRouteKind}},
+			AttachedRoutes: int32(len(l.routes)),
+		}
+		for _, s := range old.Status.Listeners {
+			if s.Name == l.listener.Name {
+				status.Conditions = append([]metav1.Condition(nil), s.Conditions...)
+			}
+		}
+		listenerProgrammed := metav1.Condition{
+			Type:    string(gatewayv1.ListenerConditionProgrammed),
+			Status:  programmed.Status,
+			Reason:  programmed.Reason,
+			Message: programmed.Message,
+		}
+		if !l.valid {
+			listenerProgrammed.Status = metav1.ConditionFalse
+			listenerProgrammed.Reason = string(gatewayv1.ListenerReasonInvalid)
+			listenerProgrammed.Message = "the listener is invalid"
+		} else if programmed.Status == metav1.ConditionTrue {
+			listenerProgrammed.Reason = string(gatewayv1.ListenerReasonProgrammed)
+		}
+		setConditions(&status.Conditions, gw.Generation, append(l.conditions, listenerProgrammed)...)
+		statuses = append(statuses, status)
+	}
+	gw.Status.Listeners = statuses
+
+	if equality.Semantic.DeepEqual(old.Status, gw.Status) {
+		return nil
+	}
+	return r.kubeClient.Status().Patch(ctx, gw, client.MergeFrom(old))
+}
+
+// updateRouteStatus replaces the status written by this controller for the parentRefs of the route to
+// the Gateway, the status is removed if parents is empty. Status of other parents are kept.
+func (r *gatewayReconciler) updateRouteStatus(ctx context.Context, route *gatewayv1.HTTPRoute, gw *gatewayv1.Gateway,
+	parents []*parentResult, routeErr error, refsErr *parentResult) error {
+	old := route.DeepCopy()
+
+	var statuses []gatewayv1.RouteParentStatus
+	for _, s := range old.Status.Parents {
+		if s.ControllerName == ControllerName && isParentRefToGateway(route, s.ParentRef, gw) {
+			continue
+		}
+		statuses = append(statuses, s)
+	}
+	for _, p := range parents {
+		status := gatewayv1.RouteParentStatus{
+			ParentRef:      p.ref,
+			ControllerName: ControllerName,
+		}
+		for _, s := range old.Status.Parents {
+			if s.ControllerName == ControllerName && equality.Semantic.DeepEqual(s.ParentRef, p.ref) {
+				status.Conditions = append([]metav1.Condition(nil), s.Conditions...)
+			}
+		}
+
+		accepted := metav1.Condition{
+			Type:   string(gatewayv1.RouteConditionAccepted),
+			Status: metav1.ConditionTrue,
+			Reason: string(gatewayv1.RouteReasonAccepted),
+		}
+		switch {
+		case len(p.listeners) == 0:
+			accepted.Status = metav1.ConditionFalse
+			accepted.Reason = string(p.reason)
+			accepted.Message = p.message
+		case routeErr != nil:
+			accepted.Status = metav1.ConditionFalse
+			accepted.Reason = string(gatewayv1.RouteReasonUnsupportedValue)
+			accepted.Message = routeErr.Error()
+		}
+		resolvedRefs := metav1.Condition{
+			Type:   string(gatewayv1.RouteConditionResolvedRefs),
+			Status: metav1.ConditionTrue,
+			Reason: string(gatewayv1.RouteReasonResolvedRefs),
+		}
+		if refsErr != nil {
+			resolvedRefs.Status = metav1.ConditionFalse
+			resolvedRefs.Reason = string(refsErr.reason)
+			resolvedRefs.Message = refsErr.message
+		}
+		setConditions(&status.Conditions, route.Generation, accepted, resolvedRefs)
+		statuses = append(statuses, status)
+	}
+	route.Status.Parents = statuses
+
+	if equality.Semantic.DeepEqual(old.Status, route.Status) {
+		return nil
+	}
+	// the parents are written by every gateway the route refers to, a conflict makes the reconcile retry
+	// instead of dropping the status written by another gateway
+	return r.kubeClient.Status().Patch(ctx, route, client.MergeFromWithOptions(old, client.MergeFromWithOptimisticLock{}))
+}
+
+func setConditions(conditions *[]metav1.Condition, generation int64, newConditions ...metav1.Condition) {
+	for _, c := range newConditions {
+		c.ObservedGeneration = generation
+		meta.SetStatusCondition(conditions, c)
+	}
+}
+
+func groupPtr(g gatewayv1.Group) *gatewayv1.Group {
+	return &g
+}
@@ -0,0 +1,160 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestUpdateRouteStatus(t *testing.T) {
+	route := newTestRoute("route", "gw", "svc")
+	route.Generation = 2
+	route.Spec.ParentRefs = append(route.Spec.ParentRefs, gatewayv1.ParentReference{Name: "other-gw"})
+	// the status written by another controller is kept
+	otherStatus := gatewayv1.RouteParentStatus{
+		ParentRef:      gatewayv1.ParentReference{Name: "other-gw"},
+		ControllerName: "example.com/other",
+		Conditions: []metav1.Condition{{
+			Type:               string(gatewayv1.RouteConditionAccepted),
+			Status:             metav1.ConditionTrue,
+			Reason:             string(gatewayv1.RouteReasonAccepted),
+			LastTransitionTime: metav1.Now(),
+		}},
+	}
+	route.Status.Parents = []gatewayv1.RouteParentStatus{otherStatus}
+	gw := newTestGateway(newTestListener("http", 80, gatewayv1.HTTPProtocolType))
+
+	r := &gatewayReconciler{kubeClient: newTestClient(t, route), logger: logr.Discard()}
+	ctx := context.TODO()
+	get := func() *gatewayv1.HTTPRoute {
+		current := &gatewayv1.HTTPRoute{}
+		assert.NoError(t, r.kubeClient.Get(ctx, util.NamespacedName(route), current))
+		return current
+	}
+	ourStatus := func(route *gatewayv1.HTTPRoute) *gatewayv1.RouteParentStatus {
+		for i, s := range route.Status.Parents {
+			if s.ControllerName == ControllerName {
+				return &route.Status.Parents[i]
+			}
+		}
+		return nil
+	}
+
+	parents := []*parentResult{{ref: route.Spec.ParentRefs[0], listeners: []gatewayv1.SectionName{"http"}}}
+	assert.NoError(t, r.updateRouteStatus(ctx, get(), gw, parents, nil, nil))
+	current := get()
+	assert.Len(t, current.Status.Parents, 2)
+	status := ourStatus(current)
+	assert.NotNil(t, status)
+	accepted := meta.FindStatusCondition(status.Conditions, string(gatewayv1.RouteConditionAccepted))
+	assert.Equal(t, metav1.ConditionTrue, accepted.Status)
+	assert.Equal(t, int64(2), accepted.ObservedGeneration)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, string(gatewayv1.RouteConditionResolvedRefs)))
+
+	// the rules which can not be translated and the unresolved backends are reported
+	refsErr := &parentResult{reason: gatewayv1.RouteReasonBackendNotFound, message: "service default/svc not found"}
+	assert.NoError(t, r.updateRouteStatus(ctx, get(), gw, parents, errors.New("path match type RegularExpression is not supported"), refsErr))
+	status = ourStatus(get())
+	accepted = meta.FindStatusCondition(status.Conditions, string(gatewayv1.RouteConditionAccepted))
+	assert.Equal(t, metav1.ConditionFalse, accepted.Status)
+	assert.Equal(t, string(gatewayv1.RouteReasonUnsupportedValue), accepted.Reason)
+	resolvedRefs := meta.FindStatusCondition(status.Conditions, string(gatewayv1.RouteConditionResolvedRefs))
+	assert.Equal(t, metav1.ConditionFalse, resolvedRefs.Status)
+	assert.Equal(t, string(gatewayv1.RouteReasonBackendNotFound), resolvedRefs.Reason)
+
+	// a route not attached to any listener reports the reason of the parentRef
+	notAttached := []*parentResult{{ref: route.Spec.ParentRefs[0], reason: gatewayv1.RouteReasonNotAllowedByListeners, message: "not allowed"}}
+	assert.NoError(t, r.updateRouteStatus(ctx, get(), gw, notAttached, nil, nil))
+	accepted = meta.FindStatusCondition(ourStatus(get()).Conditions, string(gatewayv1.RouteConditionAccepted))
+	assert.Equal(t, metav1.ConditionFalse, accepted.Status)
+	assert.Equal(t, string(gatewayv1.RouteReasonNotAllowedByListeners), accepted.Reason)
+
+	// the status is removed once the route no longer refers to the gateway
+	assert.NoError(t, r.updateRouteStatus(ctx, get(), gw, nil, nil, nil))
+	current = get()
+	assert.Nil(t, ourStatus(current))
+	assert.Len(t, current.Status.Parents, 1)
+	assert.Equal(t, otherStatus.ControllerName, current.Status.Parents[0].ControllerName)
+
+	// a stale route is rejected instead of dropping the status written by other gateways
+	stale := get()
+	assert.NoError(t, r.updateRouteStatus(ctx, get(), gw, parents, nil, nil))
+	assert.Error(t, r.updateRouteStatus(ctx, stale, gw, parents, errors.New("unsupported"), nil))
+}
+
+func TestUpdateGatewayStatus(t *testing.T) {
+	gw := newTestGateway(
+		newTestListener("http", 80, gatewayv1.HTTPProtocolType),
+		newTestListener("tcp", 9000, gatewayv1.ProtocolType("TCP")),
+	)
+	gw.Generation = 3
+	r := &gatewayReconciler{kubeClient: newTestClient(t, gw), logger: logr.Discard()}
+	ctx := context.TODO()
+	get := func() *gatewayv1.Gateway {
+		current := &gatewayv1.Gateway{}
+		assert.NoError(t, r.kubeClient.Get(ctx, util.NamespacedName(gw), current))
+		return current
+	}
+	listeners := validateListeners(gw)
+	listeners[0].routes = []*gatewayv1.HTTPRoute{newTestRoute("route", "gw")}
+
+	// the gateway is pending until the load balancer has a dns name
+	lb := &albmodel.AlbLoadBalancer{}
+	assert.NoError(t, r.updateGatewayStatus(ctx, get(), listeners, lb, nil, nil))
+	current := get()
+	programmed := meta.FindStatusCondition(current.Status.Conditions, string(gatewayv1.GatewayConditionProgrammed))
+	assert.Equal(t, metav1.ConditionFalse, programmed.Status)
+	assert.Equal(t, string(gatewayv1.GatewayReasonPending), programmed.Reason)
+	assert.Empty(t, current.Status.Addresses)
+
+	lb.Status = &albmodel.LoadBalancerStatus{DNSName: "alb-test.cn-hangzhou.alb.aliyuncs.com"}
+	assert.NoError(t, r.updateGatewayStatus(ctx, get(), listeners, lb, nil, nil))
+	current = get()
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, string(gatewayv1.GatewayConditionAccepted)))
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, string(gatewayv1.GatewayConditionProgrammed)))
+	assert.Equal(t, int64(3), meta.FindStatusCondition(current.Status.Conditions, string(gatewayv1.GatewayConditionProgrammed)).ObservedGeneration)
+	assert.Len(t, current.Status.Addresses, 1)
+	assert.Equal(t, "alb-test.cn-hangzhou.alb.aliyuncs.com", current.Status.Addresses[0].Value)
+	assert.Len(t, current.Status.Listeners, 2)
+	assert.Equal(t, int32(1), current.Status.Listeners[0].AttachedRoutes)
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Listeners[0].Conditions, string(gatewayv1.ListenerConditionProgrammed)))
+	assert.False(t, meta.IsStatusConditionTrue(current.Status.Listeners[1].Conditions, string(gatewayv1.ListenerConditionProgrammed)))
+
+	// an invalid albconfig fails the gateway and removes the address
+	paramErr := &invalidParametersError{msg: "albconfig kube-system/alb not found"}
+	assert.NoError(t, r.updateGatewayStatus(ctx, get(), listeners, nil, paramErr, nil))
+	current = get()
+	accepted := meta.FindStatusCondition(current.Status.Conditions, string(gatewayv1.GatewayConditionAccepted))
+	assert.Equal(t, metav1.ConditionFalse, accepted.Status)
+	assert.Equal(t, string(gatewayReasonInvalidParameters), accepted.Reason)
+	assert.Empty(t, current.Status.Addresses)
+}
+
+func TestUpdateGatewayClassStatus(t *testing.T) {
+	class := &gatewayv1.GatewayClass{ObjectMeta: metav1.ObjectMeta{Name: "alb", Generation: 1}}
+	class.Spec.ControllerName = ControllerName
+	r := &gatewayReconciler{kubeClient: newTestClient(t, class), logger: logr.Discard()}
+	ctx := context.TODO()
+	get := func() *gatewayv1.GatewayClass {
+		current := &gatewayv1.GatewayClass{}
+		assert.NoError(t, r.kubeClient.Get(ctx, util.NamespacedName(class), current))
+		return current
+	}
+
+	assert.NoError(t, r.updateGatewayClassStatus(ctx, get(), &invalidParametersError{msg: "parametersRef is required"}))
+	accepted := meta.FindStatusCondition(get().Status.Conditions, string(gatewayv1.GatewayClassConditionStatusAccepted))
+	assert.Equal(t, metav1.ConditionFalse, accepted.Status)
+	assert.Equal(t, "parametersRef is required", accepted.Message)
+
+	assert.NoError(t, r.updateGatewayClassStatus(ctx, get(), nil))
+	assert.True(t, meta.IsStatusConditionTrue(get().Status.Conditions, string(gatewayv1.GatewayClassConditionStatusAccepted)))
+}
@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ store.Storer = &cacheStore{}
+
+// cacheStore serves the backend manager of the ingress controller from the cache of the manager,
+// so that the gateway controller does not need to run the informers of the ingress store.
+type cacheStore struct {
+	client client.Client
+}
+
+func (s *cacheStore) GetService(key string) (*corev1.Service, error) {
+	svc := &corev1.Service{}
+	return svc, s.get(key, svc)
+}
+
+func (s *cacheStore) GetServiceEndpointSlices(key string) ([]*discovery.EndpointSlice, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+	esList := &discovery.EndpointSliceList{}
+	if err := s.client.List(context.TODO(), esList, client.InNamespace(namespace),
+		client.MatchingLabels{discovery.LabelServiceName: name}); err != nil {
+		return nil, err
+	}
+	if len(esList.Items) == 0 {
+		return nil, store.NotExistsError(key)
+	}
+	slices := make([]*discovery.EndpointSlice, 0, len(esList.Items))
+	for i := range esList.Items {
+		slices = append(slices, &esList.Items[i])
+	}
+	return slices, nil
+}
+
+func (s *cacheStore) GetPod(key string) (*corev1.Pod, error) {
+	pod := &corev1.Pod{}
+	return pod, s.get(key, pod)
+}
+
+func (s *cacheStore) ListIngresses() []*store.Ingress {
+	return nil
+}
+
+func (s *cacheStore) Run(_ chan struct{}) {}
+
+func (s *cacheStore) get(key string, obj client.Object) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	return s.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, obj)
+}
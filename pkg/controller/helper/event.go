@@ -28,6 +28,8 @@ const (
 	DeleteTimestampChanged    = "DeleteTimestampChanged"
 	PreservedOnDelete         = "PreservedOnDelete"
 	FeatureNotSupported       = "FeatureNotSupported"
+	DriftDetected             = "LoadBalancerDriftDetected"
+	SkippedSourceRanges       = "SkippedSourceRanges"
 )
 
 // NodeEventReason
@@ -44,6 +46,9 @@ const (
 	FailedCreateRoute  = "CreateRouteFailed"
 	FailedSyncRoute    = "SyncRouteFailed"
 	SucceedCreateRoute = "CreatedRoute"
+	StaleRoute         = "StaleRoute"
+	FailedDeleteRoute  = "DeleteRouteFailed"
+	SucceedDeleteRoute = "DeletedRoute"
 )
 
 var re = regexp.MustCompile(".*(Message:.*)")
@@ -81,6 +86,8 @@ const (
 	IngressEventReasonFailedBuildModel       = "FailedBuildModel"
 	IngressEventReasonFailedApplyModel       = "FailedApplyModel"
 	IngressEventReasonSuccessfullyReconciled = "SuccessfullyReconciled"
+	IngressEventReasonDefaultBackendConflict = "DefaultBackendConflict"
+	IngressEventReasonIgnoredAnnotations     = "IgnoredAnnotations"
 )
 
 // EventType type of event associated with an informer
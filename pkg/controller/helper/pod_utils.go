@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -24,6 +25,13 @@ const (
 	ConditionMessageServerRegistered = "The backend has been added to the server group"
 	ConditionReasonInvalidServer     = "ServerInvalidServer"
 	ConditionMessageInvalidServer    = "The backend failed to add to the server group because of the server is invalid"
+
+	ConditionReasonServerHealthy       = "ServerHealthy"
+	ConditionMessageServerHealthy      = "The backend has passed the health check of the load balancer"
+	ConditionReasonServerUnhealthy     = "ServerUnhealthy"
+	ConditionMessageServerUnhealthy    = "The backend has been added to the server group and is waiting for passing the health check"
+	ConditionReasonHealthCheckTimeout  = "ServerHealthCheckTimeout"
+	ConditionMessageHealthCheckTimeout = "The backend did not pass the health check of the load balancer within %s"
 )
 
 // BuildTargetHealthPodConditionType constructs the condition type for TargetHealth pod condition.
@@ -118,6 +126,46 @@ func UpdateReadinessConditionForPod(ctx context.Context, kubeClient client.Clien
 	return nil
 }
 
+// UpdateHealthConditionForPod updates the readiness condition of pod by the health check result of its backend.
+// A pod which is not healthy is waiting for the health check until timeout, true is returned in this case so that
+// the caller can requeue it.
+func UpdateHealthConditionForPod(ctx context.Context, kubeClient client.Client, pod *corev1.Pod,
+	cond corev1.PodConditionType, healthy bool, timeout time.Duration) (bool, error) {
+	if !IsPodHasReadinessGate(pod, string(cond)) {
+		return false, nil
+	}
+	if healthy {
+		return false, UpdateReadinessConditionForPod(ctx, kubeClient, pod, cond, corev1.ConditionTrue,
+			ConditionReasonServerHealthy, ConditionMessageServerHealthy)
+	}
+	if IsPodHealthCheckTimeout(pod, cond, timeout) {
+		return false, UpdateReadinessConditionForPod(ctx, kubeClient, pod, cond, corev1.ConditionFalse,
+			ConditionReasonHealthCheckTimeout, fmt.Sprintf(ConditionMessageHealthCheckTimeout, timeout))
+	}
+	return true, UpdateReadinessConditionForPod(ctx, kubeClient, pod, cond, corev1.ConditionFalse,
+		ConditionReasonServerUnhealthy, ConditionMessageServerUnhealthy)
+}
+
+// IsPodHealthCheckTimeout returns whether the containers of pod have been ready for longer than timeout
+// while the readiness condition is still false.
+func IsPodHealthCheckTimeout(pod *corev1.Pod, cond corev1.PodConditionType, timeout time.Duration) bool {
+	containersReadyCond := GetPodCondition(pod, corev1.ContainersReady)
+	if containersReadyCond == nil || containersReadyCond.Status != corev1.ConditionTrue {
+		return false
+	}
+	start := containersReadyCond.LastTransitionTime.Time
+	if existedCond := GetPodCondition(pod, cond); existedCond != nil {
+		if existedCond.Status == corev1.ConditionTrue {
+			// the backend turns unhealthy just now
+			return false
+		}
+		if existedCond.LastTransitionTime.After(start) {
+			start = existedCond.LastTransitionTime.Time
+		}
+	}
+	return time.Since(start) > timeout
+}
+
 func buildPodConditionPatch(pod *corev1.Pod, condition corev1.PodCondition) (client.Patch, error) {
 	oldData, err := json.Marshal(corev1.Pod{
 		Status: corev1.PodStatus{
@@ -0,0 +1,79 @@
+package helper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getReadinessGatePod(cond v1.PodConditionType, containersReadySince time.Time) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: v1.NamespaceDefault},
+		Spec: v1.PodSpec{
+			ReadinessGates: []v1.PodReadinessGate{{ConditionType: cond}},
+		},
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{
+				{
+					Type:               v1.ContainersReady,
+					Status:             v1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(containersReadySince),
+				},
+			},
+		},
+	}
+}
+
+func TestUpdateHealthConditionForPod(t *testing.T) {
+	cond := BuildReadinessGatePodConditionTypeWithPrefix(TargetHealthPodConditionServiceTypePrefix, "svc")
+	key := types.NamespacedName{Namespace: v1.NamespaceDefault, Name: "pod"}
+	cases := []struct {
+		name    string
+		since   time.Time
+		healthy bool
+		pending bool
+		status  v1.ConditionStatus
+		reason  string
+	}{
+		{name: "healthy", since: time.Now(), healthy: true, status: v1.ConditionTrue, reason: ConditionReasonServerHealthy},
+		{name: "waiting", since: time.Now(), pending: true, status: v1.ConditionFalse, reason: ConditionReasonServerUnhealthy},
+		{name: "timeout", since: time.Now().Add(-time.Hour), status: v1.ConditionFalse, reason: ConditionReasonHealthCheckTimeout},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pod := getReadinessGatePod(cond, c.since)
+			kubeClient := fakeclient.NewClientBuilder().WithObjects(pod).WithStatusSubresource(pod).Build()
+
+			pending, err := UpdateHealthConditionForPod(context.TODO(), kubeClient, pod, cond, c.healthy, 5*time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, c.pending, pending)
+
+			updated := &v1.Pod{}
+			assert.NoError(t, kubeClient.Get(context.TODO(), key, updated))
+			podCond := GetPodCondition(updated, cond)
+			if assert.NotNil(t, podCond) {
+				assert.Equal(t, c.status, podCond.Status)
+				assert.Equal(t, c.reason, podCond.Reason)
+			}
+		})
+	}
+}
+
+func TestIsPodHealthCheckTimeout(t *testing.T) {
+	cond := BuildReadinessGatePodConditionTypeWithPrefix(TargetHealthPodConditionServiceTypePrefix, "svc")
+	pod := getReadinessGatePod(cond, time.Now().Add(-time.Hour))
+	assert.True(t, IsPodHealthCheckTimeout(pod, cond, 5*time.Minute))
+
+	// a backend which was healthy before waits for a whole timeout again
+	UpdatePodCondition(pod, v1.PodCondition{Type: cond, Status: v1.ConditionTrue})
+	assert.False(t, IsPodHealthCheckTimeout(pod, cond, 5*time.Minute))
+
+	UpdatePodCondition(pod, v1.PodCondition{Type: cond, Status: v1.ConditionFalse, LastTransitionTime: metav1.Now()})
+	assert.False(t, IsPodHealthCheckTimeout(pod, cond, 5*time.Minute))
+}
@@ -0,0 +1,128 @@
+package helper
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// DefaultReadinessCheckPeriod is the interval the readiness gates of the waiting pods are rechecked
+const DefaultReadinessCheckPeriod = 10 * time.Second
+
+// ReadinessCheck updates the readiness gates of the backend pods of a service by the health check results,
+// true is returned if there are pods still waiting for passing the health check.
+type ReadinessCheck func(ctx context.Context) (bool, error)
+
+type readinessCheckEntry struct {
+	check      ReadinessCheck
+	generation int64
+}
+
+// ReadinessChecker rechecks the readiness gates of the pods waiting for the health check periodically,
+// so that the service is not reconciled again only for the health check of its backends.
+type ReadinessChecker struct {
+	name    string
+	period  time.Duration
+	workers int
+	queue   workqueue.RateLimitingInterface
+
+	lock       sync.Mutex
+	generation int64
+	checks     map[types.NamespacedName]readinessCheckEntry
+}
+
+// NewReadinessChecker returns a ReadinessChecker, which should be added to the manager to start.
+func NewReadinessChecker(name string, workers int, period time.Duration) *ReadinessChecker {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &ReadinessChecker{
+		name:    name,
+		period:  period,
+		workers: workers,
+		queue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
+		checks:  make(map[types.NamespacedName]readinessCheckEntry),
+	}
+}
+
+// Add schedules the readiness check of the service, it replaces the pending check of the service.
+func (c *ReadinessChecker) Add(key types.NamespacedName, check ReadinessCheck) {
+	c.lock.Lock()
+	c.generation++
+	c.checks[key] = readinessCheckEntry{check: check, generation: c.generation}
+	c.lock.Unlock()
+	c.queue.AddAfter(key, c.period)
+}
+
+// Remove cancels the pending readiness check of the service.
+func (c *ReadinessChecker) Remove(key types.NamespacedName) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.checks, key)
+}
+
+// Has returns whether there is a pending readiness check of the service.
+func (c *ReadinessChecker) Has(key types.NamespacedName) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.checks[key]
+	return ok
+}
+
+func (c *ReadinessChecker) Start(ctx context.Context) error {
+	for i := 0; i < c.workers; i++ {
+		go wait.UntilWithContext(ctx, func(ctx context.Context) {
+			for c.processNextItem(ctx) {
+			}
+		}, time.Second)
+	}
+	<-ctx.Done()
+	c.queue.ShutDown()
+	return nil
+}
+
+func (c *ReadinessChecker) NeedLeaderElection() bool {
+	return true
+}
+
+func (c *ReadinessChecker) processNextItem(ctx context.Context) bool {
+	item, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(item)
+
+	key := item.(types.NamespacedName)
+	c.lock.Lock()
+	entry, ok := c.checks[key]
+	c.lock.Unlock()
+	if !ok {
+		c.queue.Forget(key)
+		return true
+	}
+
+	waiting, err := entry.check(ctx)
+	if err != nil {
+		klog.Errorf("%s: check readiness gates of service %s error: %s", c.name, key, err.Error())
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	if waiting {
+		c.queue.AddAfter(key, c.period)
+		return true
+	}
+
+	c.lock.Lock()
+	// the check may be replaced by a newer reconcile while it is running
+	if current, ok := c.checks[key]; ok && current.generation == entry.generation {
+		delete(c.checks, key)
+	}
+	c.lock.Unlock()
+	return true
+}
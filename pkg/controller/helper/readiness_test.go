@@ -0,0 +1,77 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestReadinessChecker(t *testing.T) *ReadinessChecker {
+	c := NewReadinessChecker("test", 1, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = c.Start(ctx)
+	}()
+	return c
+}
+
+func TestReadinessCheckerRechecksUntilNotWaiting(t *testing.T) {
+	c := newTestReadinessChecker(t)
+	key := types.NamespacedName{Namespace: "default", Name: "svc"}
+
+	var calls int32
+	c.Add(key, func(ctx context.Context) (bool, error) {
+		n := atomic.AddInt32(&calls, 1)
+		switch n {
+		case 1:
+			return true, nil
+		case 2:
+			return false, fmt.Errorf("throttled")
+		default:
+			return false, nil
+		}
+	})
+	assert.True(t, c.Has(key))
+	assert.Eventually(t, func() bool { return !c.Has(key) }, 5*time.Second, 10*time.Millisecond)
+	// still waiting, error and done
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestReadinessCheckerRemove(t *testing.T) {
+	c := newTestReadinessChecker(t)
+	key := types.NamespacedName{Namespace: "default", Name: "svc"}
+
+	var calls int32
+	c.Add(key, func(ctx context.Context) (bool, error) {
+		atomic.AddInt32(&calls, 1)
+		return true, nil
+	})
+	c.Remove(key)
+	assert.False(t, c.Has(key))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestReadinessCheckerReplace(t *testing.T) {
+	c := newTestReadinessChecker(t)
+	key := types.NamespacedName{Namespace: "default", Name: "svc"}
+
+	var old, replaced int32
+	c.Add(key, func(ctx context.Context) (bool, error) {
+		atomic.AddInt32(&old, 1)
+		return true, nil
+	})
+	c.Add(key, func(ctx context.Context) (bool, error) {
+		atomic.AddInt32(&replaced, 1)
+		return false, nil
+	})
+	assert.Eventually(t, func() bool { return !c.Has(key) }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&old))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&replaced))
+}
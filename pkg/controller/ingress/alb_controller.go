@@ -10,12 +10,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
@@ -27,7 +29,9 @@ import (
 	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -58,6 +62,7 @@ func NewAlbConfigReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*al
 	if err != nil {
 		return nil, err
 	}
+	secretCertManager := albconfigmanager.NewCASSecretCertManager(mgr.GetClient(), ctx.Provider(), logger)
 	n := &albconfigReconciler{
 		cloud:            ctx.Provider(),
 		k8sClient:        mgr.GetClient(),
@@ -67,13 +72,14 @@ func NewAlbConfigReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*al
 		stackMarshaller:  NewDefaultStackMarshaller(),
 		logger:           logger,
 		updateCh:         channels.NewRingChannel(1024),
-		albconfigBuilder: albconfigmanager.NewDefaultAlbConfigManagerBuilder(mgr.GetClient(), ctx.Provider(), logger),
+		albconfigBuilder: albconfigmanager.NewDefaultAlbConfigManagerBuilder(mgr.GetClient(), ctx.Provider(), secretCertManager, logger),
 
 		serverApplier: applier.NewServiceManagerApplier(
 			mgr.GetClient(),
 			ctx.Provider(),
 			logger),
 		stopLock:              &sync.Mutex{},
+		secretCertManager:     secretCertManager,
 		groupFinalizerManager: albconfigmanager.NewDefaultFinalizerManager(helper.NewDefaultFinalizerManager(mgr.GetClient())),
 		k8sFinalizerManager:   helper.NewDefaultFinalizerManager(mgr.GetClient()),
 
@@ -123,6 +129,7 @@ type albconfigReconciler struct {
 	// ngxErrCh is used to detect errors with the NGINX processes
 	ngxErrCh                chan error
 	stopLock                *sync.Mutex
+	secretCertManager       albconfigmanager.SecretCertManager
 	groupFinalizerManager   albconfigmanager.FinalizerManager
 	k8sFinalizerManager     helper.FinalizerManager
 	syncQueue               *helper.Queue
@@ -254,23 +261,34 @@ func (g *albconfigReconciler) syncServers(obj interface{}) error {
 			"elapsedTime", time.Since(startTime).Milliseconds())
 	}()
 
-	ings := g.store.ListIngresses()
-	if len(ings) == 0 {
-		g.logger.Info("service not used by ingress, skip", "key", e.Key)
-		return nil
-	}
 	request := reconcile.Request{}
 	svc := evt.Obj.(*corev1.Service)
 	request.Namespace = svc.Namespace
 	request.Name = svc.Name
 
-	servicePortToIngressNames := g.getServicePortToIngressNames(request, ings)
+	albconfigs := &v1.AlbConfigList{}
+	if err := g.k8sClient.List(ctx, albconfigs, client.InNamespace(svc.Namespace)); err != nil {
+		return err
+	}
+	ings := g.store.ListIngresses()
+	if len(ings) == 0 && len(albconfigs.Items) == 0 {
+		g.logger.Info("service not used by ingress, skip", "key", e.Key)
+		return nil
+	}
+
+	servicePortToIngressNames := g.getServicePortToIngressNames(request, ings, albconfigs.Items)
 	if len(servicePortToIngressNames) > 0 {
 		svcStackContext, err := g.buildServiceStackContext(ctx, request, servicePortToIngressNames)
 		if err != nil {
 			return err
 		}
 
+		if ctrlCfg.ControllerCFG.DryRun {
+			plan := dryrun.NewPlan("AlbServerGroups", request.NamespacedName)
+			plan.SetError(g.buildAndApplyServers(dryrun.WithPlan(ctx, plan), svcStackContext))
+			return dryrun.AddPlan(g.k8sClient, plan)
+		}
+
 		if err = g.buildAndApplyServers(ctx, svcStackContext); err != nil {
 			return err
 		}
@@ -279,7 +297,7 @@ func (g *albconfigReconciler) syncServers(obj interface{}) error {
 	return nil
 }
 
-func (g *albconfigReconciler) getServicePortToIngressNames(request reconcile.Request, ingList []*store.Ingress) map[int32][]string {
+func (g *albconfigReconciler) getServicePortToIngressNames(request reconcile.Request, ingList []*store.Ingress, albconfigs []v1.AlbConfig) map[int32][]string {
 
 	var servicePortToIngressNames = make(map[int32]map[string]struct{})
 
@@ -292,6 +310,10 @@ func (g *albconfigReconciler) getServicePortToIngressNames(request reconcile.Req
 	}
 
 	for _, ing := range ingList {
+		// the backends and the mirror service of an ingress are in its namespace
+		if ing.Namespace != request.Namespace {
+			continue
+		}
 		if ing.Spec.DefaultBackend != nil {
 			if ing.Spec.DefaultBackend.Service.Name == request.Name {
 				processIngressBackend(*ing.Spec.DefaultBackend, ing.Name)
@@ -309,6 +331,44 @@ func (g *albconfigReconciler) getServicePortToIngressNames(request reconcile.Req
 				}
 			}
 		}
+
+		mirror, err := albconfigmanager.ComputeIngressTrafficMirrorService(&ing.Ingress)
+		if err != nil {
+			g.logger.Error(err, "compute traffic mirror service failed", "ingress", util.NamespacedName(&ing.Ingress))
+			continue
+		}
+		if mirror != nil && mirror.ServiceName == request.Name {
+			processIngressBackend(networking.IngressBackend{
+				Service: &networking.IngressServiceBackend{
+					Name: mirror.ServiceName,
+					Port: networking.ServiceBackendPort{Number: int32(mirror.ServicePort)},
+				},
+			}, ing.Name)
+		}
+	}
+
+	// the server groups forwarded by the default actions of the albconfigs
+	for _, albconfig := range albconfigs {
+		for _, ls := range albconfig.Spec.Listeners {
+			if ls == nil {
+				continue
+			}
+			for _, action := range ls.DefaultActions {
+				if action.Type != util.RuleActionTypeForward || action.ForwardConfig == nil {
+					continue
+				}
+				for _, tg := range action.ForwardConfig.TargetGroups {
+					if tg.ServiceName == request.Name && tg.ServicePort.Type == intstr.Int {
+						processIngressBackend(networking.IngressBackend{
+							Service: &networking.IngressServiceBackend{
+								Name: tg.ServiceName,
+								Port: networking.ServiceBackendPort{Number: tg.ServicePort.IntVal},
+							},
+						}, albconfigmanager.AlbConfigDefaultActionOwner(albconfig.Name))
+					}
+				}
+			}
+		}
 	}
 
 	var servicePortToIngressNameList = make(map[int32][]string)
@@ -487,7 +547,9 @@ func (g *albconfigReconciler) Reconcile(ctx context.Context, req reconcile.Reque
 			"elapsedTime", time.Since(startTime).Milliseconds())
 	}()
 
+	ctx, span := tracing.StartReconcile(ctx, "AlbConfig", req.NamespacedName)
 	err = g.reconcile(ctx, req)
+	tracing.End(span, err)
 	return reconcile.Result{}, err
 }
 
@@ -502,6 +564,10 @@ func (g *albconfigReconciler) reconcile(ctx context.Context, request reconcile.R
 		return err
 	}
 
+	if ctrlCfg.ControllerCFG.DryRun {
+		return g.dryRun(ctx, albconfig, ingGroup)
+	}
+
 	if !albconfig.DeletionTimestamp.IsZero() {
 		if err := g.cleanupAlbLoadBalancerResources(ctx, albconfig, ingGroup); err != nil {
 			return err
@@ -556,34 +622,34 @@ func (g *albconfigReconciler) reconcileAlbLoadBalancerResources(ctx context.Cont
 		return err
 	}
 
+	oldStatus := albconfig.Status.DeepCopy()
 	_, lb, err := g.buildAndApply(ctx, albconfig, ingGroup)
 	if err != nil {
+		if updateErr := g.updateAlbConfigStatus(ctx, albconfig, oldStatus); updateErr != nil {
+			g.logger.Error(updateErr, "AlbConfig Status Update", "albconfig", albconfig.Name)
+		}
 		return err
 	}
-	if lb.Status == nil || lb.Status.DNSName == "" {
-		return nil
-	}
-	for _, ing := range ingGroup.Members {
-		if ing.Status.LoadBalancer.Ingress != nil && len(ing.Status.LoadBalancer.Ingress) > 0 && ing.Status.LoadBalancer.Ingress[0].Hostname == lb.Status.DNSName {
-			continue
-		}
-		lbi := networking.IngressLoadBalancerIngress{
-			Hostname: lb.Status.DNSName,
-		}
-		ing.Status.LoadBalancer.Ingress = []networking.IngressLoadBalancerIngress{lbi}
-		err = g.k8sClient.Status().Update(ctx, ing, &client.SubResourceUpdateOptions{})
-		if err != nil {
-			g.logger.Error(err, "Ingress Status Update %s, error: %s", ing.Name)
-			continue
+	if lb.Status != nil && lb.Status.DNSName != "" {
+		for _, ing := range ingGroup.Members {
+			if ing.Status.LoadBalancer.Ingress != nil && len(ing.Status.LoadBalancer.Ingress) > 0 && ing.Status.LoadBalancer.Ingress[0].Hostname == lb.Status.DNSName {
+				continue
+			}
+			lbi := networking.IngressLoadBalancerIngress{
+				Hostname: lb.Status.DNSName,
+			}
+			ing.Status.LoadBalancer.Ingress = []networking.IngressLoadBalancerIngress{lbi}
+			err = g.k8sClient.Status().Update(ctx, ing, &client.SubResourceUpdateOptions{})
+			if err != nil {
+				g.logger.Error(err, "Ingress Status Update %s, error: %s", ing.Name)
+				continue
+			}
 		}
+		albconfig.Status.LoadBalancer.Id = lb.Status.LoadBalancerID
+		albconfig.Status.LoadBalancer.DNSName = lb.Status.DNSName
 	}
-	if albconfig.Status.LoadBalancer.DNSName == lb.Status.DNSName {
-		return nil
-	}
-	albconfig.Status.LoadBalancer.Id = lb.Status.LoadBalancerID
-	albconfig.Status.LoadBalancer.DNSName = lb.Status.DNSName
 
-	err = g.k8sClient.Status().Update(ctx, albconfig, &client.SubResourceUpdateOptions{})
+	err = g.updateAlbConfigStatus(ctx, albconfig, oldStatus)
 	if err != nil {
 		g.logger.Error(err, "LB Status Update %s, error: %s", albconfig.Name)
 		return err
@@ -591,6 +657,26 @@ func (g *albconfigReconciler) reconcileAlbLoadBalancerResources(ctx context.Cont
 	return nil
 }
 
+// dryRun builds and applies the stack of the albconfig against the dry-run provider, which records the
+// changes to the alb resources in the plan. Neither the finalizers nor the status are changed.
+func (g *albconfigReconciler) dryRun(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) error {
+	plan := dryrun.NewPlan("AlbConfig", util.NamespacedName(albconfig))
+	_, lb, err := g.buildAndApply(dryrun.WithPlan(ctx, plan), albconfig, ingGroup)
+	plan.SetError(err)
+	if lb != nil && lb.Status != nil {
+		plan.LoadBalancerID = lb.Status.LoadBalancerID
+	}
+	return dryrun.AddPlan(g.k8sClient, plan)
+}
+
+// updateAlbConfigStatus writes the status of the albconfig if it differs from oldStatus.
+func (g *albconfigReconciler) updateAlbConfigStatus(ctx context.Context, albconfig *v1.AlbConfig, oldStatus *v1.IngressStatus) error {
+	if equality.Semantic.DeepEqual(oldStatus, &albconfig.Status) {
+		return nil
+	}
+	return g.k8sClient.Status().Update(ctx, albconfig, &client.SubResourceUpdateOptions{})
+}
+
 func (g *albconfigReconciler) buildAndApply(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) (core.Manager, *albmodel.AlbLoadBalancer, error) {
 	traceID := ctx.Value(util.TraceID)
 
@@ -602,12 +688,21 @@ func (g *albconfigReconciler) buildAndApply(ctx context.Context, albconfig *v1.A
 
 	stack, lb, err := g.albconfigBuilder.Build(ctx, albconfig, ingGroup)
 	if err != nil {
+		applier.SetAlbConfigReadyCondition(albconfig, err)
 		g.recordIngressGroupEvent(ctx, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
 		return nil, nil, err
 	}
+	_, conflicts := albconfigmanager.ComputeListenerDefaultBackends(albconfig, ingGroup.Members)
+	for _, c := range conflicts {
+		g.eventRecorder.Event(c.Ingress, corev1.EventTypeWarning, helper.IngressEventReasonDefaultBackendConflict, c.Message)
+	}
+	for _, c := range albconfigmanager.ComputeIgnoredActionAnnotations(albconfig, ingGroup.Members) {
+		g.eventRecorder.Event(c.Ingress, corev1.EventTypeWarning, helper.IngressEventReasonIgnoredAnnotations, c.Message)
+	}
 
 	stackJSON, err := g.stackMarshaller.Marshal(stack)
 	if err != nil {
+		applier.SetAlbConfigReadyCondition(albconfig, err)
 		g.recordIngressGroupEvent(ctx, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
 		return nil, nil, err
 	}
@@ -619,7 +714,7 @@ func (g *albconfigReconciler) buildAndApply(ctx context.Context, albconfig *v1.A
 		"buildElapsedTime", time.Since(buildStartTime).Milliseconds())
 
 	applyStartTime := time.Now()
-	if err := g.albconfigApplier.Apply(ctx, stack); err != nil {
+	if err := g.albconfigApplier.Apply(ctx, albconfig, stack); err != nil {
 		g.recordIngressGroupEvent(ctx, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedApplyModel, helper.GetLogMessage(err))
 		return nil, nil, err
 	}
@@ -0,0 +1,182 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+type fakeIngressStore struct {
+	store.Storer
+	ings []*store.Ingress
+}
+
+func (s *fakeIngressStore) ListIngresses() []*store.Ingress {
+	return s.ings
+}
+
+type recordingServerBuilder struct {
+	built []*albmodel.ServiceStackContext
+}
+
+func (b *recordingServerBuilder) Build(_ context.Context, svcStackCtx *albmodel.ServiceStackContext) (*albmodel.ServiceManager, error) {
+	b.built = append(b.built, svcStackCtx)
+	return &albmodel.ServiceManager{Namespace: svcStackCtx.ServiceNamespace, Name: svcStackCtx.ServiceName}, nil
+}
+
+type nopServerApplier struct{}
+
+func (nopServerApplier) Apply(_ context.Context, _ prvd.Provider, _ *albmodel.ServiceManager) error {
+	return nil
+}
+
+func newDefaultActionAlbConfig(namespace, name, serviceName string) *v1.AlbConfig {
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	albconfig.Spec.Listeners = []*v1.ListenerSpec{{
+		Port:     intstr.FromInt(80),
+		Protocol: "HTTP",
+		DefaultActions: []v1.Action{{
+			Type: util.RuleActionTypeForward,
+			ForwardConfig: &v1.ForwardActionConfig{TargetGroups: []v1.TargetGroupTuple{
+				{ServiceName: serviceName, ServicePort: intstr.FromInt(8080)},
+			}},
+		}},
+	}}
+	return albconfig
+}
+
+func TestSyncServers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1.SchemeBuilder.AddToScheme(scheme))
+
+	newService := func(namespace string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "default-svc"}}
+	}
+	ing := &store.Ingress{Ingress: networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ing"}}}
+	ing.Spec.DefaultBackend = &networking.IngressBackend{Service: &networking.IngressServiceBackend{
+		Name: "default-svc",
+		Port: networking.ServiceBackendPort{Number: 80},
+	}}
+	mirrorIng := &store.Ingress{Ingress: networking.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "mirror",
+		Annotations: map[string]string{annotations.AlbTrafficMirrorToService: `{"serviceName":"default-svc","servicePort":8081}`},
+	}}}
+
+	cases := []struct {
+		name       string
+		albconfigs []client.Object
+		ings       []*store.Ingress
+		svc        *corev1.Service
+		expect     map[int32][]string
+	}{
+		{
+			name:       "service forwarded by the albconfig in its namespace",
+			albconfigs: []client.Object{newDefaultActionAlbConfig("kube-system", "alb", "default-svc")},
+			svc:        newService("kube-system"),
+			expect:     map[int32][]string{8080: {albconfigmanager.AlbConfigDefaultActionOwner("alb")}},
+		},
+		{
+			name:       "service with the same name in another namespace is not synced",
+			albconfigs: []client.Object{newDefaultActionAlbConfig("kube-system", "alb", "default-svc")},
+			svc:        newService("default"),
+		},
+		{
+			name:       "service forwarded by both the albconfig and the defaultBackend of an ingress",
+			albconfigs: []client.Object{newDefaultActionAlbConfig("default", "alb", "default-svc")},
+			ings:       []*store.Ingress{ing},
+			svc:        newService("default"),
+			expect: map[int32][]string{
+				80:   {"ing"},
+				8080: {albconfigmanager.AlbConfigDefaultActionOwner("alb")},
+			},
+		},
+		{
+			name:   "service mirrored by an ingress",
+			ings:   []*store.Ingress{mirrorIng},
+			svc:    newService("default"),
+			expect: map[int32][]string{8081: {"mirror"}},
+		},
+		{
+			name: "service with the name of a mirror in another namespace is not synced",
+			ings: []*store.Ingress{mirrorIng, ing},
+			svc:  newService("kube-system"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			kubeClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(append(c.albconfigs, c.svc)...).Build()
+			builder := &recordingServerBuilder{}
+			g := &albconfigReconciler{
+				cloud:         fakecloud.NewFakeCloud(nil),
+				k8sClient:     kubeClient,
+				logger:        logr.Discard(),
+				store:         &fakeIngressStore{ings: c.ings},
+				serverBuilder: builder,
+				serverApplier: nopServerApplier{},
+			}
+
+			err := g.syncServers(helper.Element{
+				Key:   c.svc.Namespace + "/" + c.svc.Name,
+				Event: helper.Event{Type: helper.UpdateEvent, Obj: c.svc},
+			})
+			assert.NoError(t, err)
+			if c.expect == nil {
+				assert.Empty(t, builder.built)
+				return
+			}
+			assert.Len(t, builder.built, 1)
+			assert.Equal(t, c.svc.Namespace, builder.built[0].ServiceNamespace)
+			assert.Equal(t, c.expect, builder.built[0].ServicePortToIngressNames)
+		})
+	}
+}
+
+func TestUpdateAlbConfigStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1.SchemeBuilder.AddToScheme(scheme))
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb"}}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(albconfig).WithStatusSubresource(albconfig).Build()
+	g := &albconfigReconciler{k8sClient: kubeClient, logger: logr.Discard()}
+	ctx := context.TODO()
+
+	current := &v1.AlbConfig{}
+	assert.NoError(t, kubeClient.Get(ctx, client.ObjectKeyFromObject(albconfig), current))
+	resourceVersion := current.ResourceVersion
+
+	// the status is not written if nothing changed
+	assert.NoError(t, g.updateAlbConfigStatus(ctx, current, current.Status.DeepCopy()))
+	assert.NoError(t, kubeClient.Get(ctx, client.ObjectKeyFromObject(albconfig), current))
+	assert.Equal(t, resourceVersion, current.ResourceVersion)
+
+	oldStatus := current.Status.DeepCopy()
+	current.Status.LoadBalancer.Id = "alb-test"
+	assert.NoError(t, g.updateAlbConfigStatus(ctx, current, oldStatus))
+	assert.NoError(t, kubeClient.Get(ctx, client.ObjectKeyFromObject(albconfig), current))
+	assert.NotEqual(t, resourceVersion, current.ResourceVersion)
+	assert.Equal(t, "alb-test", current.Status.LoadBalancer.Id)
+}
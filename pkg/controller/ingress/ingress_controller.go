@@ -2,8 +2,13 @@ package ingress
 
 import (
 	"context"
+	"time"
 
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
+	albconfigmanager "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/builder/albconfig_manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
@@ -12,5 +17,34 @@ func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
 	if err != nil {
 		return err
 	}
-	return albconfigReconciler.SetupWithManager(context.Background(), mgr)
+	if err := albconfigReconciler.SetupWithManager(context.Background(), mgr); err != nil {
+		return err
+	}
+	return mgr.Add(&secretCertGCRunner{
+		secretCertManager: albconfigReconciler.secretCertManager,
+		period:            time.Duration(ctrlCfg.CloudCFG.Global.SecretCertGCPeriod) * time.Second,
+		logger:            albconfigReconciler.logger,
+	})
+}
+
+// secretCertGCRunner sweeps periodically the certificates uploaded from secrets to remove the ones
+// no longer referenced by the ingresses of the cluster
+type secretCertGCRunner struct {
+	secretCertManager albconfigmanager.SecretCertManager
+	period            time.Duration
+	logger            logr.Logger
+}
+
+// Start function will not be called until the resource lock is acquired
+func (r *secretCertGCRunner) Start(ctx context.Context) error {
+	if r.period <= 0 {
+		r.logger.Info("gc of certificates uploaded from secrets is disabled", "period", r.period)
+		return nil
+	}
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.secretCertManager.GC(ctx); err != nil {
+			r.logger.Error(err, "gc certificates uploaded from secrets failed")
+		}
+	}, r.period)
+	return nil
 }
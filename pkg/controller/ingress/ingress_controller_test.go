@@ -0,0 +1,43 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type countingSecretCertManager struct {
+	gcCalls chan struct{}
+}
+
+func (m *countingSecretCertManager) Ensure(_ context.Context, _ types.NamespacedName) (string, error) {
+	return "", nil
+}
+
+func (m *countingSecretCertManager) GC(_ context.Context) error {
+	m.gcCalls <- struct{}{}
+	return assert.AnError
+}
+
+func TestSecretCertGCRunner(t *testing.T) {
+	m := &countingSecretCertManager{gcCalls: make(chan struct{}, 10)}
+
+	// a disabled runner returns at once
+	r := &secretCertGCRunner{secretCertManager: m, logger: logr.Discard()}
+	assert.NoError(t, r.Start(context.TODO()))
+	assert.Len(t, m.gcCalls, 0)
+
+	// the gc is retried on the next period after a failure, until the runner is stopped
+	r.period = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan error)
+	go func() { done <- r.Start(ctx) }()
+	<-m.gcCalls
+	<-m.gcCalls
+	cancel()
+	assert.NoError(t, <-done)
+}
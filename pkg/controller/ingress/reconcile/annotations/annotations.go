@@ -41,6 +41,21 @@ const (
 	HealthThreshold      = AnnotationLoadBalancerPrefix + "healthy-threshold-count"      // HealthCheckDomain health check domain
 	UnHealthThreshold    = AnnotationLoadBalancerPrefix + "unhealthy-threshold-count"    // HealthCheckHTTPCode health check http code
 	HealthCheckHTTPCode  = AnnotationLoadBalancerPrefix + "healthcheck-httpcode"
+	HealthCheckHost      = AnnotationLoadBalancerPrefix + "healthcheck-host"         // HealthCheckHost host header of health check requests
+	HealthCheckPort      = AnnotationLoadBalancerPrefix + "healthcheck-connect-port" // HealthCheckPort health check port, 0 means the port of backend servers
+	HealthCheckVersion   = AnnotationLoadBalancerPrefix + "healthcheck-http-version" // HealthCheckVersion HTTP1.0 or HTTP1.1
+	// ServerGroup Attribute, these annotations can also be set on a Service to override the ones of the Ingress
+	StickySession          = AnnotationLoadBalancerPrefix + "sticky-session"              // StickySession enable sticky session
+	StickySessionType      = AnnotationLoadBalancerPrefix + "sticky-session-type"         // StickySessionType Insert or Server
+	CookieTimeout          = AnnotationLoadBalancerPrefix + "cookie-timeout"              // CookieTimeout cookie timeout of Insert sticky session, 1~86400
+	Cookie                 = AnnotationLoadBalancerPrefix + "cookie"                      // Cookie cookie name of Server sticky session
+	BackendScheduler       = AnnotationLoadBalancerPrefix + "backend-scheduler"           // BackendScheduler wrr, wlc, sch or uch
+	BackendSchedulerUch    = AnnotationLoadBalancerPrefix + "backend-scheduler-uch-value" // BackendSchedulerUch query string parameter hashed by uch
+	BackendProtocol        = AnnotationLoadBalancerPrefix + "backend-protocol"            // BackendProtocol http, https or grpc
+	ConnectionDrainEnabled = AnnotationLoadBalancerPrefix + "connection-drain-enabled"    // ConnectionDrainEnabled enable connection drain
+	ConnectionDrainTimeout = AnnotationLoadBalancerPrefix + "connection-drain-timeout"    // ConnectionDrainTimeout connection drain timeout, 0~900
+	SlowStartEnabled       = AnnotationLoadBalancerPrefix + "slow-start-enabled"          // SlowStartEnabled enable slow start
+	SlowStartDuration      = AnnotationLoadBalancerPrefix + "slow-start-duration"         // SlowStartDuration slow start duration, 30~900
 	// VServerBackend Attribute
 	BackendLabel      = AnnotationLoadBalancerPrefix + "backend-label"              // BackendLabel backend labels
 	BackendType       = "service.beta.kubernetes.io/backend-type"                   // BackendType backend type
@@ -63,6 +78,25 @@ const (
 	AlbCanaryByCookie      = AnnotationAlbPrefix + "canary-by-cookie"
 	AlbCanaryWeight        = AnnotationAlbPrefix + "canary-weight"
 	AlbSslRedirect         = AnnotationAlbPrefix + "ssl-redirect"
+
+	NginxRewriteTarget = AnnotationNginxPrefix + "rewrite-target"
+	AlbRewriteTarget   = AnnotationAlbPrefix + "rewrite-target"
+
+	// AlbInsertHeader json map of header name to value, e.g. {"X-Env":{"value":"prod","valueType":"UserDefined"}}
+	AlbInsertHeader = AnnotationAlbPrefix + "insert-header"
+	// AlbRemoveHeader comma separated request header names to remove
+	AlbRemoveHeader = AnnotationAlbPrefix + "remove-header"
+	// AlbResponseInsertHeader json map of response header name to value, in the format of AlbInsertHeader
+	AlbResponseInsertHeader = AnnotationAlbPrefix + "response-insert-header"
+	// AlbResponseRemoveHeader comma separated response header names to remove
+	AlbResponseRemoveHeader = AnnotationAlbPrefix + "response-remove-header"
+	// AlbTrafficMirrorToService json service reference, e.g. {"serviceName":"mirror-svc","servicePort":80}
+	AlbTrafficMirrorToService = AnnotationAlbPrefix + "traffic-mirror-to-service"
+	// AlbQpsLimit maximum queries per second of the rule, [1, 100000]
+	AlbQpsLimit = AnnotationAlbPrefix + "qps-limit"
+	// AlbConditionsPrefix json list of extra rule conditions for the backend service named by the suffix,
+	// e.g. alb.ingress.kubernetes.io/conditions.svc-a: [{"type":"Method","methodConfig":{"values":["GET"]}}]
+	AlbConditionsPrefix = AnnotationAlbPrefix + "conditions."
 )
 
 type ParseOptions struct {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/tracking"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
@@ -18,7 +19,10 @@ import (
 )
 
 type AlbConfigManagerApplier interface {
-	Apply(ctx context.Context, stack core.Manager) error
+	// Apply applies the stack and records the result in the status of the albconfig.
+	Apply(ctx context.Context, albconfig *v1.AlbConfig, stack core.Manager) error
+	// ApplyStack applies a stack which is not built from an albconfig, e.g. the stack of a Gateway.
+	ApplyStack(ctx context.Context, stack core.Manager) error
 }
 
 var _ AlbConfigManagerApplier = &defaultAlbConfigManagerApplier{}
@@ -50,7 +54,24 @@ type ResourceApply interface {
 	PostApply(ctx context.Context) error
 }
 
-func (m *defaultAlbConfigManagerApplier) Apply(ctx context.Context, stack core.Manager) error {
+// conditionResourceApply binds a ResourceApply to the albconfig condition reporting its result.
+type conditionResourceApply struct {
+	ResourceApply
+	conditionType string
+}
+
+func (m *defaultAlbConfigManagerApplier) Apply(ctx context.Context, albconfig *v1.AlbConfig, stack core.Manager) error {
+	status := newApplyStatus()
+	err := m.apply(ctx, stack, status)
+	status.writeTo(albconfig, stack, err)
+	return err
+}
+
+func (m *defaultAlbConfigManagerApplier) ApplyStack(ctx context.Context, stack core.Manager) error {
+	return m.apply(ctx, stack, newApplyStatus())
+}
+
+func (m *defaultAlbConfigManagerApplier) apply(ctx context.Context, stack core.Manager, status *applyStatus) error {
 
 	// Reuse LoadBalancer
 	var resLBs []*albmodel.AlbLoadBalancer
@@ -66,29 +87,39 @@ func (m *defaultAlbConfigManagerApplier) Apply(ctx context.Context, stack core.M
 		if isReuseLb {
 			if resLb.Spec.ForceOverride != nil && !*resLb.Spec.ForceOverride {
 				applier := NewAlbLoadBalancerApplier(m.albProvider, m.trackingProvider, stack, m.logger)
-				return applier.Apply(ctx)
+				err := applier.Apply(ctx)
+				status.observe(v1.AlbConfigConditionLoadBalancerProvisioned, err)
+				status.skip(v1.AlbConfigConditionListenersSynced, ReasonListenersNotOverridden)
+				status.skip(v1.AlbConfigConditionRulesSynced, ReasonListenersNotOverridden)
+				return err
 			}
 		}
 	}
 
-	appliers := []ResourceApply{
-		NewServerGroupApplier(m.kubeClient, m.backendManager, m.albProvider, m.trackingProvider, stack, m.logger),
-		NewAlbLoadBalancerApplier(m.albProvider, m.trackingProvider, stack, m.logger),
-		NewListenerApplier(m.albProvider, stack, m.logger),
-		NewListenerRuleApplier(m.albProvider, stack, m.logger),
+	appliers := []conditionResourceApply{
+		{NewServerGroupApplier(m.kubeClient, m.backendManager, m.albProvider, m.trackingProvider, stack, m.logger), v1.AlbConfigConditionRulesSynced},
+		{NewAlbLoadBalancerApplier(m.albProvider, m.trackingProvider, stack, m.logger), v1.AlbConfigConditionLoadBalancerProvisioned},
+		{NewListenerApplier(m.albProvider, stack, m.logger), v1.AlbConfigConditionListenersSynced},
+		{NewListenerRuleApplier(m.albProvider, stack, m.logger), v1.AlbConfigConditionRulesSynced},
 	}
 
-	for _, applier := range appliers {
+	for i, applier := range appliers {
 		if err := applier.Apply(ctx); err != nil {
+			status.observe(applier.conditionType, err)
+			// the resources of the following appliers are not applied
+			for _, next := range appliers[i+1:] {
+				status.reset(next.conditionType)
+			}
 			return err
 		}
+		status.observe(applier.conditionType, nil)
 	}
 
 	for i := len(appliers) - 1; i >= 0; i-- {
 		if err := appliers[i].PostApply(ctx); err != nil {
+			status.observe(appliers[i].conditionType, err)
 			return err
 		}
 	}
-
 	return nil
 }
@@ -0,0 +1,265 @@
+package applier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// newTestStack builds a stack with an alb, a listener on each port forwarding to a server group
+// without backends, and a rule on each listener.
+func newTestStack(ports ...int) core.Manager {
+	stack := core.NewDefaultManager(core.StackID{Name: "test"})
+	lb := albmodel.NewAlbLoadBalancer(stack, "lb", albmodel.ALBLoadBalancerSpec{
+		AddressType:      util.LoadBalancerAddressTypeInternet,
+		LoadBalancerName: "test",
+		VpcId:            fakecloud.DefaultVpcID,
+		ZoneMapping: []albmodel.ZoneMapping{
+			{VSwitchId: "vsw-a", ZoneId: "cn-hangzhou-a"},
+			{VSwitchId: "vsw-b", ZoneId: "cn-hangzhou-b"},
+		},
+	})
+	for _, port := range ports {
+		sgp := albmodel.NewServerGroup(stack, fmt.Sprintf("sgp-%d", port), albmodel.ServerGroupSpec{
+			// the server groups of the default actions have no backends
+			ServerGroupNamedKey: albmodel.ServerGroupNamedKey{IngressName: fmt.Sprintf("test%s%d", util.DefaultListenerFlag, port)},
+			ALBServerGroupSpec: albmodel.ALBServerGroupSpec{
+				ServerGroupName: fmt.Sprintf("sgp-%d", port),
+				Protocol:        util.ServerGroupProtocolHTTP,
+				VpcId:           fakecloud.DefaultVpcID,
+			},
+		})
+		forward := []albmodel.Action{{
+			Type:          util.RuleActionTypeForward,
+			ForwardConfig: &albmodel.ForwardActionConfig{ServerGroups: []albmodel.ServerGroupTuple{{ServerGroupID: sgp.ServerGroupID()}}},
+		}}
+		ls := albmodel.NewListener(stack, fmt.Sprintf("%d", port), albmodel.ListenerSpec{
+			LoadBalancerID: lb.LoadBalancerID(),
+			ALBListenerSpec: albmodel.ALBListenerSpec{
+				ListenerPort:     port,
+				ListenerProtocol: util.ListenerProtocolHTTP,
+				DefaultActions:   forward,
+			},
+		})
+		albmodel.NewListenerRule(stack, fmt.Sprintf("%d:1", port), albmodel.ListenerRuleSpec{
+			ListenerID: ls.ListenerID(),
+			ALBListenerRuleSpec: albmodel.ALBListenerRuleSpec{
+				Priority:    1,
+				RuleName:    fmt.Sprintf("rule-%d", port),
+				RuleActions: forward,
+				RuleConditions: []albmodel.Condition{{
+					Type:       util.RuleConditionFieldPath,
+					PathConfig: albmodel.PathConfig{Values: []string{"/foo"}},
+				}},
+			},
+		})
+	}
+	return stack
+}
+
+func TestAlbConfigManagerApplierApplyStack(t *testing.T) {
+	cloud := fakecloud.NewFakeCloud(nil)
+	applier := NewAlbConfigManagerApplier(nil, fake.NewClientBuilder().Build(), cloud, util.IngressTagKeyPrefix, logr.Discard())
+	ctx := context.TODO()
+	listALBs := func() []albmodel.AlbLoadBalancerWithTags {
+		lbs, err := cloud.ListALBsWithTags(ctx, nil)
+		assert.NoError(t, err)
+		return lbs
+	}
+	listListeners := func(lbID string) map[int]int {
+		lss, err := cloud.ListALBListeners(ctx, lbID)
+		assert.NoError(t, err)
+		rules := make(map[int]int)
+		for _, ls := range lss {
+			lrs, err := cloud.ListALBListenerRules(ctx, ls.ListenerId)
+			assert.NoError(t, err)
+			rules[ls.ListenerPort] = len(lrs)
+		}
+		return rules
+	}
+	serverGroupNames := func() []string {
+		sgps, err := cloud.ListALBServerGroupsWithTags(ctx, nil)
+		assert.NoError(t, err)
+		var names []string
+		for _, sgp := range sgps {
+			names = append(names, sgp.ServerGroupName)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// the alb is created with the listener, the rule and the server group
+	assert.NoError(t, applier.ApplyStack(ctx, newTestStack(80)))
+	lbs := listALBs()
+	assert.Len(t, lbs, 1)
+	lbID := lbs[0].LoadBalancerId
+	assert.Equal(t, map[int]int{80: 1}, listListeners(lbID))
+	assert.Equal(t, []string{"sgp-80"}, serverGroupNames())
+
+	// applying the same stack again creates nothing
+	assert.NoError(t, applier.ApplyStack(ctx, newTestStack(80)))
+	assert.Equal(t, 1, cloud.CallCount("CreateALB"))
+	assert.Equal(t, 1, cloud.CallCount("CreateALBListener"))
+	assert.Equal(t, 1, cloud.CallCount("CreateALBServerGroup"))
+
+	// the listener is replaced once the throttled request is retried
+	cloud.InjectThrottling("CreateALBListener", 1)
+	assert.Error(t, applier.ApplyStack(ctx, newTestStack(8080)))
+	assert.NoError(t, applier.ApplyStack(ctx, newTestStack(8080)))
+	assert.Len(t, listALBs(), 1)
+	assert.Equal(t, map[int]int{8080: 1}, listListeners(lbID))
+	assert.Equal(t, []string{"sgp-8080"}, serverGroupNames())
+
+	// the resources are deleted with an empty stack
+	assert.NoError(t, applier.ApplyStack(ctx, core.NewDefaultManager(core.StackID{Name: "test"})))
+	assert.Empty(t, listALBs())
+	assert.Empty(t, serverGroupNames())
+}
+
+func TestAlbConfigManagerApplierApplyConditions(t *testing.T) {
+	conditionStatus := func(albconfig *v1.AlbConfig) map[string]metav1.ConditionStatus {
+		status := make(map[string]metav1.ConditionStatus)
+		for _, c := range albconfig.Status.Conditions {
+			status[c.Type] = c.Status
+		}
+		return status
+	}
+
+	cases := []struct {
+		name         string
+		inject       string
+		expectErr    bool
+		expect       map[string]metav1.ConditionStatus
+		expectReason map[string]string
+	}{
+		{
+			name: "applied",
+			expect: map[string]metav1.ConditionStatus{
+				v1.AlbConfigConditionReady:                   metav1.ConditionTrue,
+				v1.AlbConfigConditionLoadBalancerProvisioned: metav1.ConditionTrue,
+				v1.AlbConfigConditionListenersSynced:         metav1.ConditionTrue,
+				v1.AlbConfigConditionRulesSynced:             metav1.ConditionTrue,
+			},
+		},
+		{
+			name:      "listener failed after the load balancer is provisioned",
+			inject:    "CreateALBListener",
+			expectErr: true,
+			expect: map[string]metav1.ConditionStatus{
+				v1.AlbConfigConditionReady:                   metav1.ConditionFalse,
+				v1.AlbConfigConditionLoadBalancerProvisioned: metav1.ConditionTrue,
+				v1.AlbConfigConditionListenersSynced:         metav1.ConditionFalse,
+				v1.AlbConfigConditionRulesSynced:             metav1.ConditionUnknown,
+			},
+			expectReason: map[string]string{
+				v1.AlbConfigConditionListenersSynced: ReasonApplyFailed,
+				v1.AlbConfigConditionRulesSynced:     ReasonNotApplied,
+			},
+		},
+		{
+			name:      "load balancer failed",
+			inject:    "CreateALB",
+			expectErr: true,
+			expect: map[string]metav1.ConditionStatus{
+				v1.AlbConfigConditionReady:                   metav1.ConditionFalse,
+				v1.AlbConfigConditionLoadBalancerProvisioned: metav1.ConditionFalse,
+				v1.AlbConfigConditionListenersSynced:         metav1.ConditionUnknown,
+				v1.AlbConfigConditionRulesSynced:             metav1.ConditionUnknown,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cloud := fakecloud.NewFakeCloud(nil)
+			if c.inject != "" {
+				cloud.InjectError(c.inject, fakecloud.NewAPIError(c.inject, http.StatusBadRequest, "QuotaExceeded", "quota exceeded"), 1)
+			}
+			applier := NewAlbConfigManagerApplier(nil, fake.NewClientBuilder().Build(), cloud, util.IngressTagKeyPrefix, logr.Discard())
+			albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb", Generation: 2}}
+
+			err := applier.Apply(context.TODO(), albconfig, newTestStack(80))
+			assert.Equal(t, c.expectErr, err != nil)
+			assert.Equal(t, c.expect, conditionStatus(albconfig))
+			for conditionType, reason := range c.expectReason {
+				assert.Equal(t, reason, meta.FindStatusCondition(albconfig.Status.Conditions, conditionType).Reason)
+			}
+			assert.Equal(t, int64(2), albconfig.Status.ObservedGeneration)
+			assert.Len(t, albconfig.Status.Listeners, 1)
+			assert.Equal(t, int32(80), albconfig.Status.Listeners[0].Port)
+			// the listener id is only reported once the listener is created
+			assert.Equal(t, c.expect[v1.AlbConfigConditionListenersSynced] == metav1.ConditionTrue,
+				albconfig.Status.Listeners[0].ListenerId != "")
+		})
+	}
+}
+
+func TestAlbConfigManagerApplierRecreateServerGroupOnProtocolChange(t *testing.T) {
+	cloud := fakecloud.NewFakeCloud(nil)
+	applier := NewAlbConfigManagerApplier(nil, fake.NewClientBuilder().Build(), cloud, util.IngressTagKeyPrefix, logr.Discard())
+	ctx := context.TODO()
+	newStack := func(protocol string) core.Manager {
+		stack := newTestStack(80)
+		var sgps []*albmodel.ServerGroup
+		assert.NoError(t, stack.ListResources(&sgps))
+		for _, sgp := range sgps {
+			sgp.Spec.Protocol = protocol
+		}
+		return stack
+	}
+	serverGroups := func() []albmodel.ServerGroupWithTags {
+		sgps, err := cloud.ListALBServerGroupsWithTags(ctx, nil)
+		assert.NoError(t, err)
+		return sgps
+	}
+	forwardedServerGroup := func() string {
+		lbs, err := cloud.ListALBsWithTags(ctx, nil)
+		assert.NoError(t, err)
+		lss, err := cloud.ListALBListeners(ctx, lbs[0].LoadBalancerId)
+		assert.NoError(t, err)
+		lrs, err := cloud.ListALBListenerRules(ctx, lss[0].ListenerId)
+		assert.NoError(t, err)
+		return lrs[0].RuleActions[0].ForwardGroupConfig.ServerGroupTuples[0].ServerGroupId
+	}
+
+	assert.NoError(t, applier.ApplyStack(ctx, newStack(util.ServerGroupProtocolHTTP)))
+	sgps := serverGroups()
+	assert.Len(t, sgps, 1)
+	oldID := sgps[0].ServerGroupId
+	assert.Equal(t, oldID, forwardedServerGroup())
+
+	// the protocol can not be updated, a new server group takes over the rule and the old one is deleted
+	assert.NoError(t, applier.ApplyStack(ctx, newStack(util.ServerGroupProtocolHTTPS)))
+	sgps = serverGroups()
+	assert.Len(t, sgps, 1)
+	assert.NotEqual(t, oldID, sgps[0].ServerGroupId)
+	assert.Equal(t, util.ServerGroupProtocolHTTPS, sgps[0].Protocol)
+	assert.Equal(t, sgps[0].ServerGroupId, forwardedServerGroup())
+	assert.Equal(t, 2, cloud.CallCount("CreateALBServerGroup"))
+	assert.Equal(t, 0, cloud.CallCount("UpdateALBServerGroup"))
+
+	// other attributes are updated in place
+	stack := newStack(util.ServerGroupProtocolHTTPS)
+	var resSGPs []*albmodel.ServerGroup
+	assert.NoError(t, stack.ListResources(&resSGPs))
+	resSGPs[0].Spec.SlowStartConfig = albmodel.SlowStartConfig{SlowStartEnabled: true, SlowStartDuration: 30}
+	assert.NoError(t, applier.ApplyStack(ctx, stack))
+	sgps = serverGroups()
+	assert.Len(t, sgps, 1)
+	assert.True(t, sgps[0].SlowStartConfig.SlowStartEnabled)
+	assert.Equal(t, 2, cloud.CallCount("CreateALBServerGroup"))
+	assert.Equal(t, 1, cloud.CallCount("UpdateALBServerGroup"))
+}
@@ -3,6 +3,7 @@ package applier
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
@@ -95,31 +96,59 @@ func (s *listenerApplier) applyListenersOnLB(ctx context.Context, lbID string, r
 			"traceID", traceID)
 	}
 
+	// the listeners upgrading to QUIC refer to the QUIC listeners, so the QUIC listeners are applied
+	// before and deleted after the other ones
+	unmatchedQuicSDKLSs, unmatchedSDKLSs := partitionSDKQuicListeners(unmatchedSDKLSs)
+	unmatchedQuicResLSs, unmatchedResLSs := partitionResQuicListeners(unmatchedResLSs)
+	matchedQuicResAndSDKLSs, matchedResAndSDKLSs := partitionQuicListenerPairs(matchedResAndSDKLSs)
+
+	if err := s.deleteListeners(ctx, unmatchedSDKLSs); err != nil {
+		return err
+	}
+	if err := s.createListeners(ctx, unmatchedQuicResLSs); err != nil {
+		return err
+	}
+	if err := s.updateListeners(ctx, matchedQuicResAndSDKLSs); err != nil {
+		return err
+	}
+	if err := s.createListeners(ctx, unmatchedResLSs); err != nil {
+		return err
+	}
+	if err := s.updateListeners(ctx, matchedResAndSDKLSs); err != nil {
+		return err
+	}
+	return s.deleteListeners(ctx, unmatchedQuicSDKLSs)
+}
+
+func (s *listenerApplier) deleteListeners(ctx context.Context, sdkLSs []albsdk.Listener) error {
 	var (
 		errDelete error
 		wgDelete  sync.WaitGroup
 	)
-	for _, sdkLS := range unmatchedSDKLSs {
+	for _, sdkLS := range sdkLSs {
 		wgDelete.Add(1)
 		go func(sdkLS albsdk.Listener) {
 			util.RandomSleepFunc(util.ConcurrentMaxSleepMillisecondTime)
 
 			defer wgDelete.Done()
-			if err := s.albProvider.DeleteALB(ctx, sdkLS.ListenerId); errDelete == nil && err != nil {
+			if err := s.albProvider.DeleteALBListener(ctx, sdkLS.ListenerId); errDelete == nil && err != nil {
 				errDelete = err
 			}
 		}(sdkLS)
 	}
 	wgDelete.Wait()
-	if errDelete != nil {
-		return errDelete
-	}
+	return errDelete
+}
 
+func (s *listenerApplier) createListeners(ctx context.Context, resLSs []*albmodel.Listener) error {
 	var (
 		errCreate error
 		wgCreate  sync.WaitGroup
 	)
-	for _, resLS := range unmatchedResLSs {
+	for _, resLS := range resLSs {
+		if err := resolveQuicListenerID(ctx, resLS); err != nil {
+			return err
+		}
 		wgCreate.Add(1)
 		go func(resLS *albmodel.Listener) {
 			util.RandomSleepFunc(util.ConcurrentMaxSleepMillisecondTime)
@@ -133,15 +162,18 @@ func (s *listenerApplier) applyListenersOnLB(ctx context.Context, lbID string, r
 		}(resLS)
 	}
 	wgCreate.Wait()
-	if errCreate != nil {
-		return errCreate
-	}
+	return errCreate
+}
 
+func (s *listenerApplier) updateListeners(ctx context.Context, resAndSDKLSs []resAndSDKListenerPair) error {
 	var (
 		errUpdate error
 		wgUpdate  sync.WaitGroup
 	)
-	for _, resAndSDKLS := range matchedResAndSDKLSs {
+	for _, resAndSDKLS := range resAndSDKLSs {
+		if err := resolveQuicListenerID(ctx, resAndSDKLS.resLS); err != nil {
+			return err
+		}
 		wgUpdate.Add(1)
 		go func(resLs *albmodel.Listener, sdkLs *albsdk.Listener) {
 			util.RandomSleepFunc(util.ConcurrentMaxSleepMillisecondTime)
@@ -155,13 +187,63 @@ func (s *listenerApplier) applyListenersOnLB(ctx context.Context, lbID string, r
 		}(resAndSDKLS.resLS, resAndSDKLS.sdkLS)
 	}
 	wgUpdate.Wait()
-	if errUpdate != nil {
-		return errUpdate
-	}
+	return errUpdate
+}
 
+// resolveQuicListenerID sets the id of the QUIC listener which the listener upgrades to, the QUIC
+// listener must be applied before.
+func resolveQuicListenerID(ctx context.Context, resLS *albmodel.Listener) error {
+	if resLS.Spec.QuicListenerID == nil {
+		return nil
+	}
+	quicLsID, err := resLS.Spec.QuicListenerID.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	resLS.Spec.QuicConfig.QuicListenerId = quicLsID
 	return nil
 }
 
+func isQuicListenerProtocol(protocol string) bool {
+	return strings.EqualFold(protocol, util.ListenerProtocolQUIC)
+}
+
+func partitionSDKQuicListeners(sdkLSs []albsdk.Listener) ([]albsdk.Listener, []albsdk.Listener) {
+	var quicLSs, otherLSs []albsdk.Listener
+	for _, ls := range sdkLSs {
+		if isQuicListenerProtocol(ls.ListenerProtocol) {
+			quicLSs = append(quicLSs, ls)
+		} else {
+			otherLSs = append(otherLSs, ls)
+		}
+	}
+	return quicLSs, otherLSs
+}
+
+func partitionResQuicListeners(resLSs []*albmodel.Listener) ([]*albmodel.Listener, []*albmodel.Listener) {
+	var quicLSs, otherLSs []*albmodel.Listener
+	for _, ls := range resLSs {
+		if isQuicListenerProtocol(ls.Spec.ListenerProtocol) {
+			quicLSs = append(quicLSs, ls)
+		} else {
+			otherLSs = append(otherLSs, ls)
+		}
+	}
+	return quicLSs, otherLSs
+}
+
+func partitionQuicListenerPairs(pairs []resAndSDKListenerPair) ([]resAndSDKListenerPair, []resAndSDKListenerPair) {
+	var quicPairs, otherPairs []resAndSDKListenerPair
+	for _, pair := range pairs {
+		if isQuicListenerProtocol(pair.resLS.Spec.ListenerProtocol) {
+			quicPairs = append(quicPairs, pair)
+		} else {
+			otherPairs = append(otherPairs, pair)
+		}
+	}
+	return quicPairs, otherPairs
+}
+
 func (s *listenerApplier) findSDKListenersOnLB(ctx context.Context, lbID string) ([]albsdk.Listener, error) {
 	listeners, err := s.albProvider.ListALBListeners(ctx, lbID)
 	if err != nil {
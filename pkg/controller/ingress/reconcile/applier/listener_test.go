@@ -0,0 +1,110 @@
+package applier
+
+import (
+	"context"
+	"testing"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// newQuicTestStack builds a stack with an HTTPS listener on 443, which upgrades to the QUIC listener
+// on 8443 if quic is set.
+func newQuicTestStack(quic bool) core.Manager {
+	stack := core.NewDefaultManager(core.StackID{Name: "test"})
+	lb := albmodel.NewAlbLoadBalancer(stack, "lb", albmodel.ALBLoadBalancerSpec{
+		AddressType:      util.LoadBalancerAddressTypeInternet,
+		LoadBalancerName: "test",
+		VpcId:            fakecloud.DefaultVpcID,
+		ZoneMapping: []albmodel.ZoneMapping{
+			{VSwitchId: "vsw-a", ZoneId: "cn-hangzhou-a"},
+			{VSwitchId: "vsw-b", ZoneId: "cn-hangzhou-b"},
+		},
+	})
+	sgp := albmodel.NewServerGroup(stack, "sgp", albmodel.ServerGroupSpec{
+		ServerGroupNamedKey: albmodel.ServerGroupNamedKey{IngressName: "test" + util.DefaultListenerFlag + "443"},
+		ALBServerGroupSpec: albmodel.ALBServerGroupSpec{
+			ServerGroupName: "sgp",
+			Protocol:        util.ServerGroupProtocolHTTP,
+			VpcId:           fakecloud.DefaultVpcID,
+		},
+	})
+	forward := []albmodel.Action{{
+		Type:          util.RuleActionTypeForward,
+		ForwardConfig: &albmodel.ForwardActionConfig{ServerGroups: []albmodel.ServerGroupTuple{{ServerGroupID: sgp.ServerGroupID()}}},
+	}}
+	certs := []albmodel.Certificate{{IsDefault: true, CertificateId: "cert-1"}}
+
+	httpsSpec := albmodel.ListenerSpec{
+		LoadBalancerID: lb.LoadBalancerID(),
+		ALBListenerSpec: albmodel.ALBListenerSpec{
+			ListenerPort:     443,
+			ListenerProtocol: util.ListenerProtocolHTTPS,
+			DefaultActions:   forward,
+			Certificates:     certs,
+		},
+	}
+	if quic {
+		quicLs := albmodel.NewListener(stack, "8443", albmodel.ListenerSpec{
+			LoadBalancerID: lb.LoadBalancerID(),
+			ALBListenerSpec: albmodel.ALBListenerSpec{
+				ListenerPort:     8443,
+				ListenerProtocol: util.ListenerProtocolQUIC,
+				DefaultActions:   forward,
+				Certificates:     certs,
+			},
+		})
+		httpsSpec.QuicListenerID = quicLs.ListenerID()
+		httpsSpec.QuicConfig.QuicUpgradeEnabled = true
+	}
+	albmodel.NewListener(stack, "443", httpsSpec)
+	return stack
+}
+
+func TestListenerApplierQuicUpgrade(t *testing.T) {
+	cloud := fakecloud.NewFakeCloud(nil)
+	applier := NewAlbConfigManagerApplier(nil, fake.NewClientBuilder().Build(), cloud, util.IngressTagKeyPrefix, logr.Discard())
+	ctx := context.TODO()
+	listListeners := func() map[string]albsdk.Listener {
+		lbs, err := cloud.ListALBsWithTags(ctx, nil)
+		assert.NoError(t, err)
+		assert.Len(t, lbs, 1)
+		lss, err := cloud.ListALBListeners(ctx, lbs[0].LoadBalancerId)
+		assert.NoError(t, err)
+		ret := make(map[string]albsdk.Listener)
+		for _, ls := range lss {
+			ret[ls.ListenerProtocol] = ls
+		}
+		return ret
+	}
+
+	// the QUIC listener is created before the HTTPS listener upgrading to it
+	assert.NoError(t, applier.ApplyStack(ctx, newQuicTestStack(true)))
+	lss := listListeners()
+	assert.Len(t, lss, 2)
+	assert.Equal(t, albsdk.QuicConfig{QuicUpgradeEnabled: true, QuicListenerId: lss[util.ListenerProtocolQUIC].ListenerId},
+		lss[util.ListenerProtocolHTTPS].QuicConfig)
+
+	// the upgrade is disabled before the QUIC listener is deleted, the alb is kept
+	assert.NoError(t, applier.ApplyStack(ctx, newQuicTestStack(false)))
+	lss = listListeners()
+	assert.Len(t, lss, 1)
+	assert.False(t, lss[util.ListenerProtocolHTTPS].QuicConfig.QuicUpgradeEnabled)
+	assert.Equal(t, 1, cloud.CallCount("DeleteALBListener"))
+	assert.Equal(t, 0, cloud.CallCount("DeleteALB"))
+
+	// an existing HTTPS listener upgrades to a QUIC listener added later
+	assert.NoError(t, applier.ApplyStack(ctx, newQuicTestStack(true)))
+	lss = listListeners()
+	assert.Len(t, lss, 2)
+	assert.Equal(t, albsdk.QuicConfig{QuicUpgradeEnabled: true, QuicListenerId: lss[util.ListenerProtocolQUIC].ListenerId},
+		lss[util.ListenerProtocolHTTPS].QuicConfig)
+	assert.Equal(t, 3, cloud.CallCount("CreateALBListener"))
+}
@@ -175,6 +175,12 @@ func matchResAndSDKListenerRules(resLRs []*albmodel.ListenerRule, sdkLRs []albsd
 	for _, priority := range resLRPriorities.Intersection(sdkLRPriorities).List() {
 		resLR := resLRByPriority[priority]
 		sdkLR := sdkLRByPriority[priority]
+		// the direction of a rule can not be updated, the rule is recreated
+		if albmodel.RuleDirection(resLR.Spec.Direction) != albmodel.RuleDirection(sdkLR.Direction) {
+			unmatchedResLRs = append(unmatchedResLRs, resLR)
+			unmatchedSDKLRs = append(unmatchedSDKLRs, sdkLR)
+			continue
+		}
 		matchedResAndSDKLRs = append(matchedResAndSDKLRs, albmodel.ResAndSDKListenerRulePair{
 			ResLR: resLR,
 			SdkLR: &sdkLR,
@@ -0,0 +1,120 @@
+package applier
+
+import (
+	"context"
+	"testing"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func TestMatchResAndSDKListenerRules(t *testing.T) {
+	resRule := func(priority int, direction string) *albmodel.ListenerRule {
+		lr := &albmodel.ListenerRule{}
+		lr.Spec.Priority = priority
+		lr.Spec.Direction = direction
+		return lr
+	}
+	cases := []struct {
+		name             string
+		resLRs           []*albmodel.ListenerRule
+		sdkLRs           []albsdk.Rule
+		expectMatched    int
+		expectUnmatchRes int
+		expectUnmatchSDK int
+	}{
+		{
+			name:          "rules without a direction are request rules",
+			resLRs:        []*albmodel.ListenerRule{resRule(1, "")},
+			sdkLRs:        []albsdk.Rule{{Priority: 1, Direction: util.RuleDirectionRequest}},
+			expectMatched: 1,
+		},
+		{
+			name: "same direction",
+			resLRs: []*albmodel.ListenerRule{
+				resRule(1, util.RuleDirectionRequest),
+				resRule(2, util.RuleDirectionResponse),
+			},
+			sdkLRs: []albsdk.Rule{
+				{Priority: 1, Direction: util.RuleDirectionRequest},
+				{Priority: 2, Direction: util.RuleDirectionResponse},
+			},
+			expectMatched: 2,
+		},
+		{
+			name:             "a rule changing its direction is recreated",
+			resLRs:           []*albmodel.ListenerRule{resRule(1, util.RuleDirectionResponse)},
+			sdkLRs:           []albsdk.Rule{{Priority: 1, Direction: util.RuleDirectionRequest}},
+			expectUnmatchRes: 1,
+			expectUnmatchSDK: 1,
+		},
+		{
+			name:             "different priorities",
+			resLRs:           []*albmodel.ListenerRule{resRule(1, "")},
+			sdkLRs:           []albsdk.Rule{{Priority: 2, Direction: util.RuleDirectionRequest}},
+			expectUnmatchRes: 1,
+			expectUnmatchSDK: 1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matched, unmatchedRes, unmatchedSDK := matchResAndSDKListenerRules(c.resLRs, c.sdkLRs)
+			assert.Len(t, matched, c.expectMatched)
+			assert.Len(t, unmatchedRes, c.expectUnmatchRes)
+			assert.Len(t, unmatchedSDK, c.expectUnmatchSDK)
+		})
+	}
+}
+
+// newRuleTestStack adds a rule with the direction on the HTTPS listener of the QUIC test stack.
+func newRuleTestStack(t *testing.T, direction string) core.Manager {
+	stack := newQuicTestStack(false)
+	var listeners []*albmodel.Listener
+	assert.NoError(t, stack.ListResources(&listeners))
+	lrs := albmodel.ListenerRuleSpec{ListenerID: listeners[0].ListenerID()}
+	lrs.Priority = 1
+	lrs.RuleName = "rule"
+	lrs.Direction = direction
+	lrs.RuleConditions = []albmodel.Condition{{Type: util.RuleConditionFieldPath, PathConfig: albmodel.PathConfig{Values: []string{"/foo"}}}}
+	lrs.RuleActions = []albmodel.Action{{Type: util.RuleActionTypeRemoveHeader, RemoveHeaderConfig: &albmodel.RemoveHeaderConfig{Key: "Server"}}}
+	if direction != util.RuleDirectionResponse {
+		lrs.RuleActions = listeners[0].Spec.DefaultActions
+	}
+	albmodel.NewListenerRule(stack, "443:1", lrs)
+	return stack
+}
+
+func TestListenerRuleApplierDirection(t *testing.T) {
+	cloud := fakecloud.NewFakeCloud(nil)
+	applier := NewAlbConfigManagerApplier(nil, fake.NewClientBuilder().Build(), cloud, util.IngressTagKeyPrefix, logr.Discard())
+	ctx := context.TODO()
+	listRules := func() []albsdk.Rule {
+		lbs, err := cloud.ListALBsWithTags(ctx, nil)
+		assert.NoError(t, err)
+		lss, err := cloud.ListALBListeners(ctx, lbs[0].LoadBalancerId)
+		assert.NoError(t, err)
+		rules, err := cloud.ListALBListenerRules(ctx, lss[0].ListenerId)
+		assert.NoError(t, err)
+		return rules
+	}
+
+	assert.NoError(t, applier.ApplyStack(ctx, newRuleTestStack(t, util.RuleDirectionResponse)))
+	rules := listRules()
+	assert.Len(t, rules, 1)
+	assert.Equal(t, util.RuleDirectionResponse, rules[0].Direction)
+	responseRuleID := rules[0].RuleId
+
+	// the direction can not be updated, the response rule is replaced by a request rule
+	assert.NoError(t, applier.ApplyStack(ctx, newRuleTestStack(t, "")))
+	rules = listRules()
+	assert.Len(t, rules, 1)
+	assert.Equal(t, util.RuleDirectionRequest, rules[0].Direction)
+	assert.NotEqual(t, responseRuleID, rules[0].RuleId)
+}
@@ -8,6 +8,7 @@ import (
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
@@ -56,8 +57,23 @@ func (s *serverApplier) Apply(ctx context.Context) error {
 	s.logger.V(util.SynLogLevel).Info("apply servers",
 		"endpoints", s.endpoints,
 		"traceID", traceID)
-	// todo matched endpoints need check and update weight
-	_, unmatchedResEndpoints, unmatchedSDKEndpoints := matchEndpointWithTargets(s.endpoints, servers, s.trafficPolicy)
+	matchedEndpointAndTargets, unmatchedResEndpoints, unmatchedSDKEndpoints := matchEndpointWithTargets(s.endpoints, servers, s.trafficPolicy)
+
+	// weights of the matched servers change when endpoints start or stop terminating
+	var weightChangedEndpoints []albmodel.BackendItem
+	for _, pair := range matchedEndpointAndTargets {
+		if pair.endpoint.Weight != pair.target.Weight {
+			weightChangedEndpoints = append(weightChangedEndpoints, pair.endpoint)
+		}
+	}
+	if len(weightChangedEndpoints) != 0 {
+		s.logger.V(util.SynLogLevel).Info("apply servers",
+			"weightChangedEndpoints", weightChangedEndpoints,
+			"traceID", traceID)
+		if err := s.albProvider.UpdateALBServers(ctx, s.serverGroupID, weightChangedEndpoints); err != nil {
+			return err
+		}
+	}
 
 	if len(unmatchedResEndpoints) != 0 {
 		s.logger.V(util.SynLogLevel).Info("apply servers",
@@ -175,6 +191,10 @@ func isEniTrafficPolicy(trafficPolicy string) bool {
 // returns whether further probe is needed or not
 func updateTargetHealthPodCondition(ctx context.Context, kubeClient client.Client, targetHealthCondType v1.PodConditionType,
 	endpoints []albmodel.BackendItem) error {
+	if ctrlCfg.ControllerCFG.DryRun {
+		// the pods are not changed in dry run mode
+		return nil
+	}
 	klog.Infof("start updateTargetHealthPodCondition")
 	for _, endpointAndTarget := range endpoints {
 		if len(endpointAndTarget.Pod.Spec.ReadinessGates) == 0 {
@@ -273,12 +273,23 @@ func matchResAndSDKServerGroupsSGP(resSGPs []*albmodel.ServerGroup, sdkSGPs []al
 	for _, resID := range resSGPIDs.Intersection(sdkSGPIDs).List() {
 		resSGP := resSGPsByID[resID]
 		sdkSGPs := sdkSGPsByID[resID]
+		matched := false
 		for _, sdkSGP := range sdkSGPs {
+			// the protocol of a server group can not be updated, the server group is recreated
+			// and the old one is deleted after the rules are moved to the new one
+			if len(sdkSGP.Protocol) != 0 && !strings.EqualFold(resSGP.Spec.Protocol, sdkSGP.Protocol) {
+				unmatchedSDKSGPs = append(unmatchedSDKSGPs, sdkSGP)
+				continue
+			}
+			matched = true
 			matchedResAndSDKSGPs = append(matchedResAndSDKSGPs, resAndSDKServerGroupPairSGP{
 				ResSGP: resSGP,
 				SdkSGP: sdkSGP,
 			})
 		}
+		if !matched {
+			unmatchedResSGPs = append(unmatchedResSGPs, resSGP)
+		}
 	}
 	for _, resID := range resSGPIDs.Difference(sdkSGPIDs).List() {
 		unmatchedResSGPs = append(unmatchedResSGPs, resSGPsByID[resID])
@@ -0,0 +1,76 @@
+package applier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/tracking"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func newTestServerGroup(t *testing.T, cloud *fakecloud.FakeCloud) string {
+	stack := core.NewDefaultManager(core.StackID{Name: "test"})
+	sgp := albmodel.NewServerGroup(stack, "sgp", albmodel.ServerGroupSpec{
+		ALBServerGroupSpec: albmodel.ALBServerGroupSpec{ServerGroupName: "test", Protocol: "HTTP"},
+	})
+	status, err := cloud.CreateALBServerGroup(context.TODO(), sgp, tracking.NewDefaultProvider(util.IngressTagKeyPrefix, cloud.ClusterID()))
+	assert.NoError(t, err)
+	return status.ServerGroupID
+}
+
+func TestServerApplierUpdateWeight(t *testing.T) {
+	cloud := fakecloud.NewFakeCloud(nil)
+	kubeClient := fake.NewClientBuilder().Build()
+	sgpID := newTestServerGroup(t, cloud)
+	ctx := context.TODO()
+
+	endpoints := []albmodel.BackendItem{
+		{ServerId: "eni-1", ServerIp: "10.0.0.1", Port: 8080, Type: albmodel.ENIBackendType, Weight: util.DefaultServerWeight, Pod: &corev1.Pod{}},
+		{ServerId: "eni-2", ServerIp: "10.0.0.2", Port: 8080, Type: albmodel.ENIBackendType, Weight: util.DefaultServerWeight, Pod: &corev1.Pod{}},
+	}
+	apply := func(eps []albmodel.BackendItem) map[string]int {
+		assert.NoError(t, NewServerApplier(kubeClient, cloud, sgpID, eps, util.TrafficPolicyEni, logr.Discard()).Apply(ctx))
+		servers, err := cloud.ListALBServers(ctx, sgpID)
+		assert.NoError(t, err)
+		weights := make(map[string]int)
+		for _, s := range servers {
+			weights[s.ServerId] = s.Weight
+		}
+		return weights
+	}
+
+	assert.Equal(t, map[string]int{"eni-1": util.DefaultServerWeight, "eni-2": util.DefaultServerWeight}, apply(endpoints))
+	assert.Equal(t, 1, cloud.CallCount("RegisterALBServers"))
+	assert.Zero(t, cloud.CallCount("UpdateALBServers"))
+
+	// the weight of a terminating endpoint is set to zero in place
+	terminating := append([]albmodel.BackendItem(nil), endpoints...)
+	terminating[1].Weight = 0
+	assert.Equal(t, map[string]int{"eni-1": util.DefaultServerWeight, "eni-2": 0}, apply(terminating))
+	assert.Equal(t, 1, cloud.CallCount("UpdateALBServers"))
+	assert.Equal(t, 1, cloud.CallCount("RegisterALBServers"))
+	assert.Zero(t, cloud.CallCount("DeregisterALBServers"))
+	assert.Zero(t, cloud.CallCount("ReplaceALBServers"))
+
+	// nothing is updated if the weights are not changed
+	apply(terminating)
+	assert.Equal(t, 1, cloud.CallCount("UpdateALBServers"))
+
+	// the weight is restored and a new endpoint is registered in the same apply
+	added := append(endpoints, albmodel.BackendItem{ServerId: "eni-3", ServerIp: "10.0.0.3", Port: 8080, Type: albmodel.ENIBackendType, Weight: util.DefaultServerWeight, Pod: &corev1.Pod{}})
+	assert.Equal(t, map[string]int{"eni-1": util.DefaultServerWeight, "eni-2": util.DefaultServerWeight, "eni-3": util.DefaultServerWeight}, apply(added))
+	assert.Equal(t, 2, cloud.CallCount("UpdateALBServers"))
+	assert.Equal(t, 2, cloud.CallCount("RegisterALBServers"))
+
+	// a failure of the weight update is returned
+	cloud.InjectError("UpdateALBServers", assert.AnError, 1)
+	assert.Error(t, NewServerApplier(kubeClient, cloud, sgpID, terminating, util.TrafficPolicyEni, logr.Discard()).Apply(ctx))
+}
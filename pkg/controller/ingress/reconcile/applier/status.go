@@ -0,0 +1,132 @@
+package applier
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+)
+
+const (
+	ReasonApplied                = "Applied"
+	ReasonApplyFailed            = "ApplyFailed"
+	ReasonNotApplied             = "NotApplied"
+	ReasonListenersNotOverridden = "ListenersNotOverridden"
+	ReasonReconciled             = "Reconciled"
+	ReasonReconcileFailed        = "ReconcileFailed"
+)
+
+var albConfigSyncConditionTypes = []string{
+	v1.AlbConfigConditionLoadBalancerProvisioned,
+	v1.AlbConfigConditionListenersSynced,
+	v1.AlbConfigConditionRulesSynced,
+}
+
+// applyStatus collects the result of each applier during an Apply.
+type applyStatus struct {
+	conditions map[string]metav1.Condition
+}
+
+func newApplyStatus() *applyStatus {
+	return &applyStatus{conditions: make(map[string]metav1.Condition)}
+}
+
+// observe records the result of an applier, a failure is never overridden by a later success.
+func (s *applyStatus) observe(conditionType string, err error) {
+	if c, ok := s.conditions[conditionType]; ok && c.Status == metav1.ConditionFalse {
+		return
+	}
+	if err != nil {
+		s.conditions[conditionType] = metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  ReasonApplyFailed,
+			Message: err.Error(),
+		}
+		return
+	}
+	s.conditions[conditionType] = metav1.Condition{
+		Type:   conditionType,
+		Status: metav1.ConditionTrue,
+		Reason: ReasonApplied,
+	}
+}
+
+// reset forgets the success recorded for a condition whose remaining resources are not applied.
+func (s *applyStatus) reset(conditionType string) {
+	if c, ok := s.conditions[conditionType]; ok && c.Status == metav1.ConditionFalse {
+		return
+	}
+	delete(s.conditions, conditionType)
+}
+
+// skip records a resource which is intentionally not managed by the controller.
+func (s *applyStatus) skip(conditionType string, reason string) {
+	s.conditions[conditionType] = metav1.Condition{
+		Type:   conditionType,
+		Status: metav1.ConditionTrue,
+		Reason: reason,
+	}
+}
+
+func (s *applyStatus) writeTo(albconfig *v1.AlbConfig, stack core.Manager, err error) {
+	for _, t := range albConfigSyncConditionTypes {
+		c, ok := s.conditions[t]
+		if !ok {
+			c = metav1.Condition{
+				Type:    t,
+				Status:  metav1.ConditionUnknown,
+				Reason:  ReasonNotApplied,
+				Message: "not applied because a previous step failed",
+			}
+		}
+		c.ObservedGeneration = albconfig.Generation
+		meta.SetStatusCondition(&albconfig.Status.Conditions, c)
+	}
+	SetAlbConfigReadyCondition(albconfig, err)
+
+	var resLSs []*albmodel.Listener
+	_ = stack.ListResources(&resLSs)
+	listeners := make([]v1.ListenerStatus, 0, len(resLSs))
+	for _, ls := range resLSs {
+		lsStatus := v1.ListenerStatus{
+			Port:     int32(ls.Spec.ListenerPort),
+			Protocol: ls.Spec.ListenerProtocol,
+		}
+		if ls.Status != nil {
+			lsStatus.ListenerId = ls.Status.ListenerID
+		}
+		listeners = append(listeners, lsStatus)
+	}
+	sort.Slice(listeners, func(i, j int) bool {
+		return listeners[i].Port < listeners[j].Port
+	})
+	albconfig.Status.Listeners = listeners
+}
+
+// SetAlbConfigReadyCondition sets the Ready condition and the last error of the albconfig
+// according to the result of the reconcile.
+func SetAlbConfigReadyCondition(albconfig *v1.AlbConfig, err error) {
+	albconfig.Status.ObservedGeneration = albconfig.Generation
+	if err != nil {
+		albconfig.Status.LastError = err.Error()
+		meta.SetStatusCondition(&albconfig.Status.Conditions, metav1.Condition{
+			Type:               v1.AlbConfigConditionReady,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: albconfig.Generation,
+			Reason:             ReasonReconcileFailed,
+			Message:            err.Error(),
+		})
+		return
+	}
+	albconfig.Status.LastError = ""
+	meta.SetStatusCondition(&albconfig.Status.Conditions, metav1.Condition{
+		Type:               v1.AlbConfigConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: albconfig.Generation,
+		Reason:             ReasonReconciled,
+	})
+}
@@ -0,0 +1,142 @@
+package applier
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func TestApplyStatusObserve(t *testing.T) {
+	failed := fmt.Errorf("failed")
+	cases := []struct {
+		name    string
+		observe func(s *applyStatus)
+		expect  metav1.ConditionStatus
+		reason  string
+	}{
+		{
+			name:    "success",
+			observe: func(s *applyStatus) { s.observe(v1.AlbConfigConditionRulesSynced, nil) },
+			expect:  metav1.ConditionTrue,
+			reason:  ReasonApplied,
+		},
+		{
+			name: "failure is not overridden by a later success",
+			observe: func(s *applyStatus) {
+				s.observe(v1.AlbConfigConditionRulesSynced, failed)
+				s.observe(v1.AlbConfigConditionRulesSynced, nil)
+			},
+			expect: metav1.ConditionFalse,
+			reason: ReasonApplyFailed,
+		},
+		{
+			name: "success is overridden by a later failure",
+			observe: func(s *applyStatus) {
+				s.observe(v1.AlbConfigConditionRulesSynced, nil)
+				s.observe(v1.AlbConfigConditionRulesSynced, failed)
+			},
+			expect: metav1.ConditionFalse,
+			reason: ReasonApplyFailed,
+		},
+		{
+			name: "reset success",
+			observe: func(s *applyStatus) {
+				s.observe(v1.AlbConfigConditionRulesSynced, nil)
+				s.reset(v1.AlbConfigConditionRulesSynced)
+			},
+			expect: metav1.ConditionUnknown,
+			reason: ReasonNotApplied,
+		},
+		{
+			name: "failure is kept by reset",
+			observe: func(s *applyStatus) {
+				s.observe(v1.AlbConfigConditionRulesSynced, failed)
+				s.reset(v1.AlbConfigConditionRulesSynced)
+			},
+			expect: metav1.ConditionFalse,
+			reason: ReasonApplyFailed,
+		},
+		{
+			name:    "skipped",
+			observe: func(s *applyStatus) { s.skip(v1.AlbConfigConditionRulesSynced, ReasonListenersNotOverridden) },
+			expect:  metav1.ConditionTrue,
+			reason:  ReasonListenersNotOverridden,
+		},
+		{
+			name:    "not observed",
+			observe: func(s *applyStatus) {},
+			expect:  metav1.ConditionUnknown,
+			reason:  ReasonNotApplied,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+			s := newApplyStatus()
+			c.observe(s)
+			s.writeTo(albconfig, core.NewDefaultManager(core.StackID{Name: "test"}), nil)
+
+			cond := meta.FindStatusCondition(albconfig.Status.Conditions, v1.AlbConfigConditionRulesSynced)
+			assert.NotNil(t, cond)
+			assert.Equal(t, c.expect, cond.Status)
+			assert.Equal(t, c.reason, cond.Reason)
+			assert.Equal(t, int64(3), cond.ObservedGeneration)
+		})
+	}
+}
+
+func TestApplyStatusWriteListeners(t *testing.T) {
+	stack := core.NewDefaultManager(core.StackID{Name: "test"})
+	for _, port := range []int{443, 80} {
+		albmodel.NewListener(stack, fmt.Sprintf("%d", port), albmodel.ListenerSpec{
+			LoadBalancerID:  core.LiteralStringToken("alb-test"),
+			ALBListenerSpec: albmodel.ALBListenerSpec{ListenerPort: port, ListenerProtocol: util.ListenerProtocolHTTP},
+		})
+	}
+	var lss []*albmodel.Listener
+	assert.NoError(t, stack.ListResources(&lss))
+	for _, ls := range lss {
+		if ls.Spec.ListenerPort == 80 {
+			ls.SetStatus(albmodel.ListenerStatus{ListenerID: "lsn-80"})
+		}
+	}
+
+	albconfig := &v1.AlbConfig{}
+	albconfig.Status.Listeners = []v1.ListenerStatus{{Port: 8080, ListenerId: "lsn-8080"}}
+	newApplyStatus().writeTo(albconfig, stack, nil)
+	// the listeners are replaced and sorted by port, the listener not applied yet has no id
+	assert.Equal(t, []v1.ListenerStatus{
+		{Port: 80, Protocol: util.ListenerProtocolHTTP, ListenerId: "lsn-80"},
+		{Port: 443, Protocol: util.ListenerProtocolHTTP},
+	}, albconfig.Status.Listeners)
+}
+
+func TestSetAlbConfigReadyCondition(t *testing.T) {
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+
+	SetAlbConfigReadyCondition(albconfig, fmt.Errorf("quota exceeded"))
+	assert.Equal(t, int64(2), albconfig.Status.ObservedGeneration)
+	assert.Equal(t, "quota exceeded", albconfig.Status.LastError)
+	cond := meta.FindStatusCondition(albconfig.Status.Conditions, v1.AlbConfigConditionReady)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, ReasonReconcileFailed, cond.Reason)
+	assert.Equal(t, "quota exceeded", cond.Message)
+
+	albconfig.Generation = 3
+	SetAlbConfigReadyCondition(albconfig, nil)
+	assert.Equal(t, int64(3), albconfig.Status.ObservedGeneration)
+	assert.Empty(t, albconfig.Status.LastError)
+	cond = meta.FindStatusCondition(albconfig.Status.Conditions, v1.AlbConfigConditionReady)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, ReasonReconciled, cond.Reason)
+	assert.Empty(t, cond.Message)
+	assert.Equal(t, int64(3), cond.ObservedGeneration)
+}
@@ -3,6 +3,8 @@ package backend
 import (
 	"context"
 	"fmt"
+	"net"
+
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/backend"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
@@ -13,12 +15,15 @@ import (
 	pkgModel "k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 
 	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/go-logr/logr"
@@ -40,6 +45,11 @@ type PodEndpoint struct {
 	Port     int
 	NodeName *string
 	Pod      *corev1.Pod
+	// Terminating is true for the endpoints which are terminating but still serving,
+	// they are kept in the server group with zero weight to drain the connections.
+	Terminating bool
+	// ZoneHints are the zones of the topology aware hints of the endpoint.
+	ZoneHints []string
 }
 
 type NodePortEndpoint alb.BackendItem
@@ -79,61 +89,101 @@ func (r *defaultEndpointResolver) findServiceAndServicePort(ctx context.Context,
 }
 
 func (r *defaultEndpointResolver) resolvePodEndpoints(ctx context.Context, svc *corev1.Service, svcPort corev1.ServicePort) ([]PodEndpoint, bool, error) {
-	epsKey := util.NamespacedName(svc)
-	eps := &corev1.Endpoints{}
-	if err := r.k8sClient.Get(ctx, epsKey, eps); err != nil {
-		if apierrors.IsNotFound(err) {
+	esList, err := r.store.GetServiceEndpointSlices(util.NamespacedName(svc).String())
+	if err != nil {
+		if _, ok := err.(store.NotExistsError); ok || apierrors.IsNotFound(err) {
 			return nil, false, fmt.Errorf("%w: %v", ErrNotFound, err.Error())
 		}
 		return nil, false, err
 	}
+
+	// the endpoints of dual-stack services are split into one slice per address family,
+	// only the family matching the server group is used so that a pod is not registered twice
+	addressType := serviceAddressType(svc)
+
 	var endpoints []PodEndpoint
+	// used for deduplicate, an endpoint may appear in several slices while it is being moved
+	// https://kubernetes.io/docs/concepts/services-networking/endpoint-slices/#duplicate-endpoints
+	endpointMap := make(map[string]bool)
 	containsPotentialReadyEndpoints := false
 
-	for _, ep := range eps.Subsets {
-		var backendPort int
-		for _, p := range ep.Ports {
-			if p.Name == svcPort.Name {
-				backendPort = int(p.Port)
-				break
-			}
+	for _, es := range esList {
+		if es.AddressType != addressType {
+			continue
 		}
 
-		for _, addr := range ep.Addresses {
-			if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+		backendPort, ok := findEndpointSlicePort(es, svcPort.Name)
+		if !ok {
+			continue
+		}
+
+		for _, ep := range es.Endpoints {
+			if ep.TargetRef == nil || ep.TargetRef.Kind != "Pod" || len(ep.Addresses) == 0 {
 				continue
 			}
-			pod, err := r.findPodByReference(ctx, svc.Namespace, *addr.TargetRef)
-			if err != nil {
-				return nil, false, err
+			addr := ep.Addresses[0]
+			if endpointMap[addr] {
+				continue
 			}
-			endpoints = append(endpoints, buildPodEndpoint(addr, backendPort, pod))
-		}
-		// readiness gates
-		for _, epAddr := range ep.NotReadyAddresses {
-			if epAddr.TargetRef == nil || epAddr.TargetRef.Kind != "Pod" {
+
+			terminating := ep.Conditions.Terminating != nil && *ep.Conditions.Terminating
+			// terminating endpoints which are no longer serving can not accept any connection
+			if terminating && (ep.Conditions.Serving == nil || !*ep.Conditions.Serving) {
 				continue
 			}
-			pod, err := r.findPodByReference(ctx, svc.Namespace, *epAddr.TargetRef)
+
+			pod, err := r.findPodByReference(ctx, svc.Namespace, *ep.TargetRef)
 			if err != nil {
+				// the terminating pod may have been removed from the store before its endpoint
+				if _, ok := err.(store.NotExistsError); ok && terminating {
+					continue
+				}
 				klog.Errorf("findPodByReference error: %s", err.Error())
 				return nil, false, err
 			}
 
-			if !helper.IsPodHasReadinessGate(pod, helper.TargetHealthPodConditionALBTypePrefix) {
-				continue
+			// readiness gates
+			if !terminating && ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				if !helper.IsPodHasReadinessGate(pod, helper.TargetHealthPodConditionALBTypePrefix) {
+					continue
+				}
+				if !helper.IsPodContainersReady(pod) {
+					containsPotentialReadyEndpoints = true
+					continue
+				}
 			}
-			if !helper.IsPodContainersReady(pod) {
-				containsPotentialReadyEndpoints = true
-				continue
-			}
-			endpoints = append(endpoints, buildPodEndpoint(epAddr, backendPort, pod))
-		}
 
+			endpointMap[addr] = true
+			endpoints = append(endpoints, buildPodEndpoint(addr, ep, backendPort, pod, terminating))
+		}
 	}
 
 	return endpoints, containsPotentialReadyEndpoints, nil
 }
+
+// serviceAddressType returns the address type of the endpoints registered to the server groups,
+// IPv6 is used only by the single-stack IPv6 services.
+func serviceAddressType(svc *corev1.Service) discovery.AddressType {
+	for _, family := range svc.Spec.IPFamilies {
+		if family == corev1.IPv4Protocol {
+			return discovery.AddressTypeIPv4
+		}
+	}
+	if len(svc.Spec.IPFamilies) != 0 {
+		return discovery.AddressTypeIPv6
+	}
+	return discovery.AddressTypeIPv4
+}
+
+func findEndpointSlicePort(es *discovery.EndpointSlice, portName string) (int, bool) {
+	for _, p := range es.Ports {
+		if p.Port != nil && pointer.StringDeref(p.Name, "") == portName {
+			return int(*p.Port), true
+		}
+	}
+	return 0, false
+}
+
 func (r *defaultEndpointResolver) findPodByReference(ctx context.Context, namespace string, podRef corev1.ObjectReference) (*corev1.Pod, error) {
 	podKey := fmt.Sprintf("%s/%s", namespace, podRef.Name)
 	return r.store.GetPod(podKey)
@@ -149,7 +199,7 @@ func (r *defaultEndpointResolver) ResolveENIEndpoints(ctx context.Context, svcKe
 		return nil, containsPotentialReadyEndpoints, err
 	}
 
-	eps, err := r.transPodEndpointsToEnis(podEndpoints)
+	eps, err := r.transPodEndpointsToEnis(ctx, podEndpoints)
 	if err != nil {
 		return nil, containsPotentialReadyEndpoints, err
 	}
@@ -179,6 +229,14 @@ func (r *defaultEndpointResolver) ResolveLocalEndpoints(ctx context.Context, svc
 	}
 	nodesByName := nodesByName(nodes)
 
+	// a node is drained only when all of its endpoints are terminating
+	nodeServing := make(map[string]bool)
+	for _, podEndPoint := range podEndPoints {
+		if podEndPoint.NodeName != nil && !podEndPoint.Terminating {
+			nodeServing[*podEndPoint.NodeName] = true
+		}
+	}
+
 	ecsEndpoints := make([]NodePortEndpoint, 0)
 	eciEndpoints := make([]PodEndpoint, 0)
 
@@ -206,11 +264,15 @@ func (r *defaultEndpointResolver) ResolveLocalEndpoints(ctx context.Context, svc
 			return nil, containsPotentialReadyEndpoints, err
 		}
 
-		ecsEndpoints = append(ecsEndpoints, buildNodePortEndpoint(id, "", int(svcNodePort), alb.ECSBackendType, util.DefaultServerWeight, podEndPoint.Pod))
+		weight := 0
+		if nodeServing[node.Name] {
+			weight = util.DefaultServerWeight
+		}
+		ecsEndpoints = append(ecsEndpoints, buildNodePortEndpoint(id, "", int(svcNodePort), alb.ECSBackendType, weight, podEndPoint.Pod))
 	}
 
 	if len(eciEndpoints) != 0 {
-		eniEps, err := r.transPodEndpointsToEnis(eciEndpoints)
+		eniEps, err := r.transPodEndpointsToEnis(ctx, eciEndpoints)
 		if err != nil {
 			return nil, containsPotentialReadyEndpoints, err
 		}
@@ -241,12 +303,16 @@ func (r *defaultEndpointResolver) ResolveClusterEndpoints(ctx context.Context, s
 		return nil, containsPotentialReadyEndpoints, err
 	}
 	nodesByName := nodesByName(nodes)
+	hintZones := topologyHintZones(podEndPoints)
 
 	ecsEndpoints := make([]NodePortEndpoint, 0)
 	for _, node := range nodes {
 		if helper.IsExcludedNode(&node) {
 			continue
 		}
+		if !isNodeInZones(&node, hintZones) {
+			continue
+		}
 		_, id, err := helper.NodeFromProviderID(node.Spec.ProviderID)
 		if err != nil {
 			return nil, containsPotentialReadyEndpoints, fmt.Errorf("normal parse providerid: %s. "+
@@ -273,7 +339,7 @@ func (r *defaultEndpointResolver) ResolveClusterEndpoints(ctx context.Context, s
 	}
 
 	if len(eciEndpoints) != 0 {
-		eniEndpointsFromEci, err := r.transPodEndpointsToEnis(eciEndpoints)
+		eniEndpointsFromEci, err := r.transPodEndpointsToEnis(ctx, eciEndpoints)
 		if err != nil {
 			return nil, containsPotentialReadyEndpoints, err
 		}
@@ -283,6 +349,38 @@ func (r *defaultEndpointResolver) ResolveClusterEndpoints(ctx context.Context, s
 	return ecsEndpoints, containsPotentialReadyEndpoints, nil
 }
 
+// topologyHintZones returns the zones hinted by the endpoints. Following kube-proxy, the hints are
+// ignored unless every serving endpoint carries them.
+func topologyHintZones(podEndpoints []PodEndpoint) sets.String {
+	zones := sets.NewString()
+	for _, ep := range podEndpoints {
+		if ep.Terminating {
+			continue
+		}
+		if len(ep.ZoneHints) == 0 {
+			return nil
+		}
+		zones.Insert(ep.ZoneHints...)
+	}
+	if zones.Len() == 0 {
+		return nil
+	}
+	return zones
+}
+
+// isNodeInZones returns true if the node is in one of the zones. Nodes without zone label and empty zones
+// always match, so that the server group falls back to all of the nodes.
+func isNodeInZones(node *corev1.Node, zones sets.String) bool {
+	if zones.Len() == 0 {
+		return true
+	}
+	zone, ok := node.Labels[corev1.LabelTopologyZone]
+	if !ok {
+		return true
+	}
+	return zones.Has(zone)
+}
+
 func nodesByName(nodes []corev1.Node) map[string]corev1.Node {
 	nodesByName := make(map[string]corev1.Node)
 	for _, node := range nodes {
@@ -291,18 +389,22 @@ func nodesByName(nodes []corev1.Node) map[string]corev1.Node {
 	return nodesByName
 }
 
-func (r *defaultEndpointResolver) transPodEndpointsToEnis(backends []PodEndpoint) ([]NodePortEndpoint, error) {
+func (r *defaultEndpointResolver) transPodEndpointsToEnis(ctx context.Context, backends []PodEndpoint) ([]NodePortEndpoint, error) {
 	vpcId, err := r.cloud.VpcID()
 	if err != nil {
 		return nil, fmt.Errorf("get vpc id from metadata error:%s", err.Error())
 	}
 
 	var ips []string
+	ipVersion := pkgModel.IPv4
 	for _, b := range backends {
 		ips = append(ips, b.IP)
+		if net.ParseIP(b.IP).To4() == nil {
+			ipVersion = pkgModel.IPv6
+		}
 	}
 
-	result, err := r.cloud.DescribeNetworkInterfaces(vpcId, ips, pkgModel.IPv4)
+	result, err := r.cloud.DescribeNetworkInterfaces(ctx, vpcId, ips, ipVersion)
 	if err != nil {
 		return nil, fmt.Errorf("call DescribeNetworkInterfaces: %s", err.Error())
 	}
@@ -313,20 +415,36 @@ func (r *defaultEndpointResolver) transPodEndpointsToEnis(backends []PodEndpoint
 			return nil, fmt.Errorf("can not find eniid for ip %s in vpc %s", backends[i].IP, vpcId)
 		}
 		// for ENI backend type, port should be set to targetPort (default value), no need to update
-		nodePortEndpoints = append(nodePortEndpoints, buildNodePortEndpoint(eniid, backends[i].IP, backends[i].Port, alb.ENIBackendType, util.DefaultServerWeight, backends[i].Pod))
+		nodePortEndpoints = append(nodePortEndpoints, buildNodePortEndpoint(eniid, backends[i].IP, backends[i].Port, alb.ENIBackendType, podEndpointWeight(backends[i]), backends[i].Pod))
 	}
 
 	return nodePortEndpoints, nil
 
 }
 
-func buildPodEndpoint(epAddr corev1.EndpointAddress, port int, pod *corev1.Pod) PodEndpoint {
-	return PodEndpoint{
-		IP:       epAddr.IP,
-		Port:     port,
-		NodeName: epAddr.NodeName,
-		Pod:      pod,
+func buildPodEndpoint(addr string, ep discovery.Endpoint, port int, pod *corev1.Pod, terminating bool) PodEndpoint {
+	podEndpoint := PodEndpoint{
+		IP:          addr,
+		Port:        port,
+		NodeName:    ep.NodeName,
+		Pod:         pod,
+		Terminating: terminating,
+	}
+	if ep.Hints != nil {
+		for _, zone := range ep.Hints.ForZones {
+			podEndpoint.ZoneHints = append(podEndpoint.ZoneHints, zone.Name)
+		}
+	}
+	return podEndpoint
+}
+
+// podEndpointWeight returns the weight of the server of the endpoint,
+// terminating endpoints get zero weight so that no new connection is sent to them.
+func podEndpointWeight(ep PodEndpoint) int {
+	if ep.Terminating {
+		return 0
 	}
+	return util.DefaultServerWeight
 }
 
 func buildNodePortEndpoint(instanceID string, serverIP string, port int, tp string, weight int, pod *corev1.Pod) NodePortEndpoint {
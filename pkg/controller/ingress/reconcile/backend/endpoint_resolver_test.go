@@ -0,0 +1,307 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/store"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+const testNamespace = "default"
+
+// fakeStore serves the endpoint slices and pods of the tests
+type fakeStore struct {
+	slices []*discovery.EndpointSlice
+	pods   map[string]*corev1.Pod
+}
+
+var _ store.Storer = &fakeStore{}
+
+func (s *fakeStore) GetService(key string) (*corev1.Service, error) {
+	return nil, store.NotExistsError(key)
+}
+
+func (s *fakeStore) GetServiceEndpointSlices(key string) ([]*discovery.EndpointSlice, error) {
+	if len(s.slices) == 0 {
+		return nil, store.NotExistsError(key)
+	}
+	return s.slices, nil
+}
+
+func (s *fakeStore) GetPod(key string) (*corev1.Pod, error) {
+	pod, ok := s.pods[key]
+	if !ok {
+		return nil, store.NotExistsError(key)
+	}
+	return pod, nil
+}
+
+func (s *fakeStore) ListIngresses() []*store.Ingress {
+	return nil
+}
+
+func (s *fakeStore) Run(_ chan struct{}) {}
+
+func newTestService(families ...corev1.IPFamily) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "svc"},
+		Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeNodePort,
+			IPFamilies: families,
+			Ports: []corev1.ServicePort{{
+				Name: "http", Port: 80, TargetPort: intstr.FromInt(8080), NodePort: 30080,
+			}},
+		},
+	}
+}
+
+func newTestNode(name, zone string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone}},
+		Spec:       corev1.NodeSpec{ProviderID: "cn-hangzhou.i-" + name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func newTestPod(name, node string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+}
+
+type testEndpoint struct {
+	ip          string
+	pod         string
+	node        string
+	ready       bool
+	serving     bool
+	terminating bool
+	zones       []string
+}
+
+func newTestSlice(name string, addressType discovery.AddressType, eps ...testEndpoint) *discovery.EndpointSlice {
+	es := &discovery.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+		AddressType: addressType,
+		Ports:       []discovery.EndpointPort{{Name: pointer.String("http"), Port: pointer.Int32(8080)}},
+	}
+	for _, ep := range eps {
+		endpoint := discovery.Endpoint{
+			Addresses: []string{ep.ip},
+			NodeName:  pointer.String(ep.node),
+			TargetRef: &corev1.ObjectReference{Kind: "Pod", Namespace: testNamespace, Name: ep.pod},
+			Conditions: discovery.EndpointConditions{
+				Ready:       pointer.Bool(ep.ready),
+				Serving:     pointer.Bool(ep.serving),
+				Terminating: pointer.Bool(ep.terminating),
+			},
+		}
+		if len(ep.zones) != 0 {
+			endpoint.Hints = &discovery.EndpointHints{}
+			for _, z := range ep.zones {
+				endpoint.Hints.ForZones = append(endpoint.Hints.ForZones, discovery.ForZone{Name: z})
+			}
+		}
+		es.Endpoints = append(es.Endpoints, endpoint)
+	}
+	return es
+}
+
+func newTestResolver(svc *corev1.Service, s *fakeStore, cloud prvd.Provider, objs ...client.Object) *defaultEndpointResolver {
+	objs = append(objs, svc)
+	kubeClient := fake.NewClientBuilder().WithObjects(objs...).Build()
+	return NewDefaultEndpointResolver(s, kubeClient, cloud, logr.Discard())
+}
+
+func TestResolvePodEndpoints(t *testing.T) {
+	pods := map[string]*corev1.Pod{}
+	for _, name := range []string{"ready", "terminating", "not-serving", "not-ready"} {
+		pods[testNamespace+"/"+name] = newTestPod(name, "node-a")
+	}
+
+	cases := []struct {
+		name     string
+		svc      *corev1.Service
+		slices   []*discovery.EndpointSlice
+		expected []PodEndpoint
+	}{
+		{
+			name: "terminating endpoints are kept only while serving",
+			svc:  newTestService(),
+			slices: []*discovery.EndpointSlice{newTestSlice("s1", discovery.AddressTypeIPv4,
+				testEndpoint{ip: "10.0.0.1", pod: "ready", node: "node-a", ready: true, serving: true},
+				testEndpoint{ip: "10.0.0.2", pod: "terminating", node: "node-a", serving: true, terminating: true},
+				testEndpoint{ip: "10.0.0.3", pod: "not-serving", node: "node-a", terminating: true},
+				testEndpoint{ip: "10.0.0.4", pod: "not-ready", node: "node-a"},
+				// the pod of a terminating endpoint may be removed before the endpoint
+				testEndpoint{ip: "10.0.0.5", pod: "removed", node: "node-a", serving: true, terminating: true},
+			)},
+			expected: []PodEndpoint{
+				{IP: "10.0.0.1", Port: 8080, NodeName: pointer.String("node-a"), Pod: pods[testNamespace+"/ready"]},
+				{IP: "10.0.0.2", Port: 8080, NodeName: pointer.String("node-a"), Pod: pods[testNamespace+"/terminating"], Terminating: true},
+			},
+		},
+		{
+			name: "duplicate endpoints in several slices are registered once",
+			svc:  newTestService(),
+			slices: []*discovery.EndpointSlice{
+				newTestSlice("s1", discovery.AddressTypeIPv4, testEndpoint{ip: "10.0.0.1", pod: "ready", node: "node-a", ready: true, serving: true}),
+				newTestSlice("s2", discovery.AddressTypeIPv4, testEndpoint{ip: "10.0.0.1", pod: "ready", node: "node-a", ready: true, serving: true}),
+			},
+			expected: []PodEndpoint{
+				{IP: "10.0.0.1", Port: 8080, NodeName: pointer.String("node-a"), Pod: pods[testNamespace+"/ready"]},
+			},
+		},
+		{
+			name: "ipv4 is used by dual-stack services",
+			svc:  newTestService(corev1.IPv6Protocol, corev1.IPv4Protocol),
+			slices: []*discovery.EndpointSlice{
+				newTestSlice("v6", discovery.AddressTypeIPv6, testEndpoint{ip: "fd00::1", pod: "ready", node: "node-a", ready: true, serving: true}),
+				newTestSlice("v4", discovery.AddressTypeIPv4, testEndpoint{ip: "10.0.0.1", pod: "ready", node: "node-a", ready: true, serving: true}),
+			},
+			expected: []PodEndpoint{
+				{IP: "10.0.0.1", Port: 8080, NodeName: pointer.String("node-a"), Pod: pods[testNamespace+"/ready"]},
+			},
+		},
+		{
+			name: "ipv6 is used by single-stack ipv6 services",
+			svc:  newTestService(corev1.IPv6Protocol),
+			slices: []*discovery.EndpointSlice{
+				newTestSlice("v6", discovery.AddressTypeIPv6, testEndpoint{ip: "fd00::1", pod: "ready", node: "node-a", ready: true, serving: true}),
+				newTestSlice("v4", discovery.AddressTypeIPv4, testEndpoint{ip: "10.0.0.1", pod: "ready", node: "node-a", ready: true, serving: true}),
+			},
+			expected: []PodEndpoint{
+				{IP: "fd00::1", Port: 8080, NodeName: pointer.String("node-a"), Pod: pods[testNamespace+"/ready"]},
+			},
+		},
+		{
+			name: "topology hints are kept",
+			svc:  newTestService(),
+			slices: []*discovery.EndpointSlice{newTestSlice("s1", discovery.AddressTypeIPv4,
+				testEndpoint{ip: "10.0.0.1", pod: "ready", node: "node-a", ready: true, serving: true, zones: []string{"zone-a"}},
+			)},
+			expected: []PodEndpoint{
+				{IP: "10.0.0.1", Port: 8080, NodeName: pointer.String("node-a"), Pod: pods[testNamespace+"/ready"], ZoneHints: []string{"zone-a"}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := newTestResolver(c.svc, &fakeStore{slices: c.slices, pods: pods}, nil)
+			eps, potentialReady, err := r.resolvePodEndpoints(context.TODO(), c.svc, c.svc.Spec.Ports[0])
+			assert.NoError(t, err)
+			assert.False(t, potentialReady)
+			assert.Equal(t, c.expected, eps)
+		})
+	}
+
+	// no endpoint slice is a not found error
+	svc := newTestService()
+	r := newTestResolver(svc, &fakeStore{pods: pods}, nil)
+	_, _, err := r.resolvePodEndpoints(context.TODO(), svc, svc.Spec.Ports[0])
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestResolveClusterEndpointsWithTopologyHints(t *testing.T) {
+	svc := newTestService()
+	pods := map[string]*corev1.Pod{
+		testNamespace + "/p1": newTestPod("p1", "node-a"),
+		testNamespace + "/p2": newTestPod("p2", "node-b"),
+	}
+	nodes := []client.Object{newTestNode("node-a", "zone-a"), newTestNode("node-b", "zone-b"), newTestNode("node-c", "zone-c")}
+	resolve := func(eps ...testEndpoint) []string {
+		r := newTestResolver(svc, &fakeStore{slices: []*discovery.EndpointSlice{newTestSlice("s1", discovery.AddressTypeIPv4, eps...)}, pods: pods}, nil, nodes...)
+		backends, _, err := r.ResolveClusterEndpoints(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "svc"}, intstr.FromInt(80))
+		assert.NoError(t, err)
+		var ids []string
+		for _, b := range backends {
+			assert.Equal(t, 30080, b.Port)
+			ids = append(ids, b.ServerId)
+		}
+		return ids
+	}
+
+	// the nodes of the hinted zones are registered
+	assert.ElementsMatch(t, []string{"i-node-a", "i-node-b"}, resolve(
+		testEndpoint{ip: "10.0.0.1", pod: "p1", node: "node-a", ready: true, serving: true, zones: []string{"zone-a"}},
+		testEndpoint{ip: "10.0.0.2", pod: "p2", node: "node-b", ready: true, serving: true, zones: []string{"zone-b"}},
+	))
+	// the hints are ignored unless every serving endpoint carries them
+	assert.ElementsMatch(t, []string{"i-node-a", "i-node-b", "i-node-c"}, resolve(
+		testEndpoint{ip: "10.0.0.1", pod: "p1", node: "node-a", ready: true, serving: true, zones: []string{"zone-a"}},
+		testEndpoint{ip: "10.0.0.2", pod: "p2", node: "node-b", ready: true, serving: true},
+	))
+	// terminating endpoints don't count
+	assert.ElementsMatch(t, []string{"i-node-a"}, resolve(
+		testEndpoint{ip: "10.0.0.1", pod: "p1", node: "node-a", ready: true, serving: true, zones: []string{"zone-a"}},
+		testEndpoint{ip: "10.0.0.2", pod: "p2", node: "node-b", serving: true, terminating: true},
+	))
+}
+
+func TestResolveLocalEndpointsDrainsTerminatingNodes(t *testing.T) {
+	svc := newTestService()
+	pods := map[string]*corev1.Pod{
+		testNamespace + "/p1": newTestPod("p1", "node-a"),
+		testNamespace + "/p2": newTestPod("p2", "node-b"),
+		testNamespace + "/p3": newTestPod("p3", "node-b"),
+	}
+	slices := []*discovery.EndpointSlice{newTestSlice("s1", discovery.AddressTypeIPv4,
+		testEndpoint{ip: "10.0.0.1", pod: "p1", node: "node-a", serving: true, terminating: true},
+		testEndpoint{ip: "10.0.0.2", pod: "p2", node: "node-b", serving: true, terminating: true},
+		testEndpoint{ip: "10.0.0.3", pod: "p3", node: "node-b", ready: true, serving: true},
+	)}
+	r := newTestResolver(svc, &fakeStore{slices: slices, pods: pods}, nil,
+		newTestNode("node-a", "zone-a"), newTestNode("node-b", "zone-b"))
+
+	backends, _, err := r.ResolveLocalEndpoints(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "svc"}, intstr.FromInt(80))
+	assert.NoError(t, err)
+	weights := map[string]int{}
+	for _, b := range backends {
+		weights[b.ServerId] = b.Weight
+	}
+	// a node is drained only when all of its endpoints are terminating
+	assert.Equal(t, map[string]int{"i-node-a": 0, "i-node-b": util.DefaultServerWeight}, weights)
+}
+
+func TestResolveENIEndpoints(t *testing.T) {
+	svc := newTestService()
+	pods := map[string]*corev1.Pod{
+		testNamespace + "/p1": newTestPod("p1", "node-a"),
+		testNamespace + "/p2": newTestPod("p2", "node-a"),
+	}
+	slices := []*discovery.EndpointSlice{newTestSlice("s1", discovery.AddressTypeIPv4,
+		testEndpoint{ip: "10.0.0.1", pod: "p1", node: "node-a", ready: true, serving: true},
+		testEndpoint{ip: "10.0.0.2", pod: "p2", node: "node-a", serving: true, terminating: true},
+	)}
+	cloud := fakecloud.NewFakeCloud(nil)
+	cloud.AddENI(prvd.EniAttribute{NetworkInterfaceID: "eni-1", PrivateIPAddress: "10.0.0.1"}, fakecloud.DefaultVpcID)
+	cloud.AddENI(prvd.EniAttribute{NetworkInterfaceID: "eni-2", PrivateIPAddress: "10.0.0.2"}, fakecloud.DefaultVpcID)
+	r := newTestResolver(svc, &fakeStore{slices: slices, pods: pods}, cloud)
+
+	backends, _, err := r.ResolveENIEndpoints(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "svc"}, intstr.FromString("http"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []NodePortEndpoint{
+		{ServerId: "eni-1", ServerIp: "10.0.0.1", Port: 8080, Type: alb.ENIBackendType, Weight: util.DefaultServerWeight, Pod: pods[testNamespace+"/p1"]},
+		// terminating endpoints get zero weight to drain the connections
+		{ServerId: "eni-2", ServerIp: "10.0.0.2", Port: 8080, Type: alb.ENIBackendType, Weight: 0, Pod: pods[testNamespace+"/p2"]},
+	}, backends)
+}
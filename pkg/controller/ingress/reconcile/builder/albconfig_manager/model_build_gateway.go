@@ -0,0 +1,677 @@
+package albconfigmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"k8s.io/utils/pointer"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// GatewayStackNamePrefix distinguishes the stacks of Gateways from the stacks of AlbConfigs in the same namespace
+	GatewayStackNamePrefix = "gateway-"
+
+	GatewayRedirectCodeDefault = 302
+	// GatewayBackendWeightMax is the max weight of a server group in a forward action of alb
+	GatewayBackendWeightMax = 100
+
+	RedirectProtocolDefault = "${protocol}"
+	RedirectHostDefault     = "${host}"
+	RedirectPortDefault     = "${port}"
+	RedirectPathDefault     = "${path}"
+	RedirectQueryDefault    = "${query}"
+	RewritePathDefault      = "${path}"
+)
+
+// GatewayStackContext contains the Gateway and the routes attached to its listeners.
+type GatewayStackContext struct {
+	Gateway *gatewayv1.Gateway
+	// AlbConfig is referenced by the parametersRef of the GatewayClass, it provides the configuration
+	// of the load balancer and the defaults of the listeners on the same ports.
+	AlbConfig *v1.AlbConfig
+	// Listeners are the valid listeners of the Gateway
+	Listeners []gatewayv1.Listener
+	// Routes are the routes attached to each listener, keyed by the listener name
+	Routes map[gatewayv1.SectionName][]*gatewayv1.HTTPRoute
+}
+
+// GatewayBuilder builds the alb stack of a Gateway.
+type GatewayBuilder interface {
+	// BuildGateway builds the stack of the Gateway. Routes which can not be translated to alb rules
+	// are left out of the stack and returned with the reasons.
+	BuildGateway(ctx context.Context, gwCtx *GatewayStackContext) (core.Manager, *alb.AlbLoadBalancer, map[types.NamespacedName]error, error)
+}
+
+var _ GatewayBuilder = &defaultAlbConfigManagerBuilder{}
+
+// GatewayGroupID returns the id of the stack of the Gateway.
+func GatewayGroupID(gw *gatewayv1.Gateway) GroupID {
+	return GroupID{Namespace: gw.Namespace, Name: GatewayStackNamePrefix + gw.Name}
+}
+
+func (b defaultAlbConfigManagerBuilder) BuildGateway(ctx context.Context, gwCtx *GatewayStackContext) (core.Manager, *alb.AlbLoadBalancer, map[types.NamespacedName]error, error) {
+	if gwCtx.AlbConfig == nil || gwCtx.AlbConfig.Spec.LoadBalancer == nil {
+		return nil, nil, nil, errors.New("albconfig of the gateway class has no load balancer config")
+	}
+	gw := gwCtx.Gateway
+	albconfig := &v1.AlbConfig{}
+	albconfig.Namespace = gw.Namespace
+	albconfig.Name = gw.Name
+	albconfig.DeletionTimestamp = gw.DeletionTimestamp
+	albconfig.Spec.LoadBalancer = gwCtx.AlbConfig.Spec.LoadBalancer.DeepCopy()
+	albconfig.Spec.Listeners = gwCtx.AlbConfig.Spec.Listeners
+	if albconfig.Spec.LoadBalancer.DeletionProtectionEnabled == nil {
+		albconfig.Spec.LoadBalancer.DeletionProtectionEnabled = pointer.Bool(true)
+	}
+
+	task, err := b.newModelBuildTask(albconfig, &Group{ID: GatewayGroupID(gw)})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	routeErrs := make(map[types.NamespacedName]error)
+	if err := task.runGateway(ctx, gwCtx, routeErrs); err != nil {
+		return nil, nil, nil, err
+	}
+	return task.stack, task.loadBalancer, routeErrs, nil
+}
+
+// gatewayRouteRule is a listener rule built from a match of a route, it is ordered by the
+// precedence defined by the Gateway API before the priorities are assigned.
+type gatewayRouteRule struct {
+	route    *gatewayv1.HTTPRoute
+	match    gatewayv1.HTTPRouteMatch
+	spec     alb.ListenerRuleSpec
+	ruleIdx  int
+	matchIdx int
+}
+
+func (t *defaultModelBuildTask) runGateway(ctx context.Context, gwCtx *GatewayStackContext, routeErrs map[types.NamespacedName]error) error {
+	if !t.albconfig.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	lb, err := t.buildAlbLoadBalancer(ctx, t.albconfig)
+	if err != nil {
+		return err
+	}
+
+	listenersByPort := make(map[int32][]gatewayv1.Listener)
+	var ports []int32
+	for _, l := range gwCtx.Listeners {
+		port := int32(l.Port)
+		if _, ok := listenersByPort[port]; !ok {
+			ports = append(ports, port)
+		}
+		listenersByPort[port] = append(listenersByPort[port], l)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+
+	for _, port := range ports {
+		listeners := listenersByPort[port]
+		ls, err := t.buildGatewayListener(ctx, lb.LoadBalancerID(), port, listeners)
+		if err != nil {
+			return err
+		}
+
+		var rules []*gatewayRouteRule
+		for _, l := range listeners {
+			for _, route := range gwCtx.Routes[l.Name] {
+				key := util.NamespacedName(route)
+				if _, failed := routeErrs[key]; failed {
+					continue
+				}
+				hosts, _ := ComputeGatewayRouteHostnames(l, route)
+				routeRules, err := t.buildGatewayRouteRules(ctx, ls.ListenerID(), route, hosts)
+				if err != nil {
+					routeErrs[key] = err
+					continue
+				}
+				rules = append(rules, routeRules...)
+			}
+		}
+		// a route failed on a later listener may have contributed rules to this one
+		var validRules []*gatewayRouteRule
+		for _, r := range rules {
+			if _, failed := routeErrs[util.NamespacedName(r.route)]; !failed {
+				validRules = append(validRules, r)
+			}
+		}
+		sortGatewayRouteRules(validRules)
+
+		for i, r := range validRules {
+			priority := i + 1
+			r.spec.Priority = priority
+			r.spec.RuleName = fmt.Sprintf("%v-%v-%v", ListenerRuleNamePrefix, port, priority)
+			_ = alb.NewListenerRule(t.stack, fmt.Sprintf("%v:%v", port, priority), r.spec)
+		}
+	}
+	return nil
+}
+
+func (t *defaultModelBuildTask) buildGatewayListener(ctx context.Context, lbID core.StringToken, port int32, listeners []gatewayv1.Listener) (*alb.Listener, error) {
+	lsSpec := &v1.ListenerSpec{}
+	// the listener of the albconfig on the same port provides the defaults, e.g. timeouts and certificates
+	for _, ls := range t.albconfig.Spec.Listeners {
+		if ls != nil && int32(ls.Port.IntValue()) == port {
+			lsCopy := *ls
+			// the services of the default actions are not synced for the gateways
+			lsCopy.DefaultActions = nil
+			lsSpec = &lsCopy
+			break
+		}
+	}
+	lsSpec.Port = intstr.FromInt(int(port))
+	lsSpec.Protocol = string(listeners[0].Protocol)
+
+	ls, err := t.buildListener(ctx, lbID, lsSpec, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ls.Spec.ListenerProtocol != string(ProtocolHTTPS) || len(ls.Spec.Certificates) != 0 {
+		return ls, nil
+	}
+
+	var hosts []string
+	for _, l := range listeners {
+		if l.Hostname != nil && *l.Hostname != "" {
+			hosts = append(hosts, string(*l.Hostname))
+		}
+	}
+	var certIDs []string
+	if len(hosts) != 0 {
+		certIDs, err = t.certDiscovery.Discover(ctx, hosts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(certIDs) == 0 {
+		return nil, fmt.Errorf("https listener: %d must provider one default cert, no cert was discovered for hosts: %v", port, hosts)
+	}
+	certIDs = removeDuplicateElement(certIDs)
+	sort.Strings(certIDs)
+	for i, cid := range certIDs {
+		ls.Spec.Certificates = append(ls.Spec.Certificates, alb.Certificate{
+			IsDefault:     i == 0,
+			CertificateId: cid,
+		})
+	}
+	return ls, nil
+}
+
+// ComputeGatewayRouteHostnames returns the hostnames of the route which are accepted by the listener,
+// the second value is false if the route and the listener do not share any hostname.
+func ComputeGatewayRouteHostnames(l gatewayv1.Listener, route *gatewayv1.HTTPRoute) ([]string, bool) {
+	listenerHost := ""
+	if l.Hostname != nil {
+		listenerHost = string(*l.Hostname)
+	}
+	if len(route.Spec.Hostnames) == 0 {
+		if listenerHost == "" {
+			return nil, true
+		}
+		return []string{listenerHost}, true
+	}
+
+	var hosts []string
+	for _, h := range route.Spec.Hostnames {
+		host := string(h)
+		switch {
+		case listenerHost == "" || host == listenerHost:
+			hosts = append(hosts, host)
+		case hostnameMatchesWildcard(host, listenerHost):
+			hosts = append(hosts, host)
+		case hostnameMatchesWildcard(listenerHost, host):
+			hosts = append(hosts, listenerHost)
+		}
+	}
+	hosts = removeDuplicateElement(hosts)
+	return hosts, len(hosts) != 0
+}
+
+// hostnameMatchesWildcard returns whether host is covered by a wildcard hostname like *.example.com.
+func hostnameMatchesWildcard(host, wildcard string) bool {
+	if !strings.HasPrefix(wildcard, "*.") {
+		return false
+	}
+	suffix := strings.TrimPrefix(wildcard, "*")
+	return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+}
+
+func (t *defaultModelBuildTask) buildGatewayRouteRules(ctx context.Context, lsID core.StringToken, route *gatewayv1.HTTPRoute, hosts []string) ([]*gatewayRouteRule, error) {
+	if err := validateGatewayHTTPRoute(route); err != nil {
+		return nil, err
+	}
+
+	// server groups are named after the route as if it were an ingress, so that the backend servers
+	// are synced by the service manager in the same way, and the health check annotations are shared
+	ing := networking.Ingress{}
+	ing.Namespace = route.Namespace
+	ing.Name = route.Name
+	ing.Annotations = route.Annotations
+
+	// conditions are built first as building the actions adds the server groups to the stack
+	var rules []*gatewayRouteRule
+	for ruleIdx, rule := range route.Spec.Rules {
+		matches := rule.Matches
+		if len(matches) == 0 {
+			matches = []gatewayv1.HTTPRouteMatch{{}}
+		}
+		for matchIdx, match := range matches {
+			conditions, err := t.buildGatewayRuleConditions(ctx, hosts, match)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, &gatewayRouteRule{
+				route: route,
+				match: match,
+				spec: alb.ListenerRuleSpec{
+					ListenerID: lsID,
+					ALBListenerRuleSpec: alb.ALBListenerRuleSpec{
+						RuleConditions: conditions,
+					},
+				},
+				ruleIdx:  ruleIdx,
+				matchIdx: matchIdx,
+			})
+		}
+	}
+	for ruleIdx, rule := range route.Spec.Rules {
+		actions, err := t.buildGatewayRuleActions(ctx, ing, rule)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rules {
+			if r.ruleIdx == ruleIdx {
+				r.spec.RuleActions = actions
+			}
+		}
+	}
+	return rules, nil
+}
+
+// validateGatewayHTTPRoute checks the features of the route which can not be expressed by alb rules,
+// it runs before any resource of the route is added to the stack.
+func validateGatewayHTTPRoute(route *gatewayv1.HTTPRoute) error {
+	for _, rule := range route.Spec.Rules {
+		for _, match := range rule.Matches {
+			if match.Path != nil && match.Path.Type != nil && *match.Path.Type == gatewayv1.PathMatchRegularExpression {
+				return errors.Errorf("path match type %s is not supported", *match.Path.Type)
+			}
+			for _, h := range match.Headers {
+				if h.Type != nil && *h.Type != gatewayv1.HeaderMatchExact {
+					return errors.Errorf("header match type %s is not supported", *h.Type)
+				}
+			}
+			for _, q := range match.QueryParams {
+				if q.Type != nil && *q.Type != gatewayv1.QueryParamMatchExact {
+					return errors.Errorf("query param match type %s is not supported", *q.Type)
+				}
+			}
+			if match.Method != nil && !supportedRuleConditionMethods.Has(string(*match.Method)) {
+				return errors.Errorf("method must be within %v: %s", supportedRuleConditionMethods.List(), *match.Method)
+			}
+		}
+
+		redirect := false
+		for _, f := range rule.Filters {
+			switch f.Type {
+			case gatewayv1.HTTPRouteFilterRequestRedirect:
+				if f.RequestRedirect == nil {
+					return errors.New("missing requestRedirect of RequestRedirect filter")
+				}
+				if f.RequestRedirect.Path != nil && f.RequestRedirect.Path.Type != gatewayv1.FullPathHTTPPathModifier {
+					return errors.Errorf("redirect path modifier %s is not supported", f.RequestRedirect.Path.Type)
+				}
+				redirect = true
+			case gatewayv1.HTTPRouteFilterURLRewrite:
+				if f.URLRewrite == nil {
+					return errors.New("missing urlRewrite of URLRewrite filter")
+				}
+				if f.URLRewrite.Path != nil && f.URLRewrite.Path.Type != gatewayv1.FullPathHTTPPathModifier {
+					return errors.Errorf("rewrite path modifier %s is not supported", f.URLRewrite.Path.Type)
+				}
+			case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+				if f.RequestHeaderModifier == nil {
+					return errors.New("missing requestHeaderModifier of RequestHeaderModifier filter")
+				}
+			case gatewayv1.HTTPRouteFilterRequestMirror:
+				if f.RequestMirror == nil {
+					return errors.New("missing requestMirror of RequestMirror filter")
+				}
+				if err := validateGatewayBackendRef(route, f.RequestMirror.BackendRef); err != nil {
+					return err
+				}
+			default:
+				return errors.Errorf("filter type %s is not supported", f.Type)
+			}
+		}
+		// alb only accepts a redirect action alone
+		if redirect && (len(rule.Filters) != 1 || len(rule.BackendRefs) != 0) {
+			return errors.New("RequestRedirect filter can not be used with other filters or backendRefs")
+		}
+
+		mirrors := 0
+		for _, f := range rule.Filters {
+			if f.Type == gatewayv1.HTTPRouteFilterRequestMirror {
+				mirrors++
+			}
+		}
+		if mirrors > 1 {
+			return errors.New("at most one RequestMirror filter is supported")
+		}
+
+		for _, b := range rule.BackendRefs {
+			if len(b.Filters) != 0 {
+				return errors.New("filters of backendRefs are not supported")
+			}
+			if err := validateGatewayBackendRef(route, b.BackendObjectReference); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateGatewayBackendRef only accepts services in the namespace of the route, as ReferenceGrant is not supported.
+func validateGatewayBackendRef(route *gatewayv1.HTTPRoute, ref gatewayv1.BackendObjectReference) error {
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != "Service") {
+		return errors.Errorf("backend %s must be a Service", ref.Name)
+	}
+	if ref.Namespace != nil && string(*ref.Namespace) != route.Namespace {
+		return errors.Errorf("backend %s/%s must be in the namespace of the route", *ref.Namespace, ref.Name)
+	}
+	if ref.Port == nil {
+		return errors.Errorf("port of backend %s must be specified", ref.Name)
+	}
+	return nil
+}
+
+func (t *defaultModelBuildTask) buildGatewayRuleConditions(ctx context.Context, hosts []string, match gatewayv1.HTTPRouteMatch) ([]alb.Condition, error) {
+	var conditions []alb.Condition
+	if len(hosts) != 0 {
+		conditions = append(conditions, t.buildHostHeaderCondition(ctx, hosts))
+	}
+
+	pathType, pathValue := gatewayPathMatch(match)
+	var paths []string
+	var err error
+	switch pathType {
+	case gatewayv1.PathMatchExact:
+		paths, err = t.buildPathPatternsForExactPathType(pathValue)
+	default:
+		paths, err = t.buildPathPatternsForPrefixPathType(pathValue)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conditions = append(conditions, t.buildPathPatternCondition(ctx, paths))
+
+	for _, h := range match.Headers {
+		conditions = append(conditions, t.buildHeaderCondition(ctx, string(h.Name), []string{h.Value}))
+	}
+	if len(match.QueryParams) != 0 {
+		var values []alb.Value
+		for _, q := range match.QueryParams {
+			values = append(values, alb.Value{Key: string(q.Name), Value: q.Value})
+		}
+		conditions = append(conditions, alb.Condition{
+			Type:              util.RuleConditionFieldQueryString,
+			QueryStringConfig: alb.QueryStringConfig{Values: values},
+		})
+	}
+	if match.Method != nil {
+		conditions = append(conditions, alb.Condition{
+			Type:         util.RuleConditionFieldMethod,
+			MethodConfig: alb.MethodConfig{Values: []string{string(*match.Method)}},
+		})
+	}
+	return conditions, nil
+}
+
+func gatewayPathMatch(match gatewayv1.HTTPRouteMatch) (gatewayv1.PathMatchType, string) {
+	pathType, pathValue := gatewayv1.PathMatchPathPrefix, "/"
+	if match.Path != nil {
+		if match.Path.Type != nil {
+			pathType = *match.Path.Type
+		}
+		if match.Path.Value != nil {
+			pathValue = *match.Path.Value
+		}
+	}
+	return pathType, pathValue
+}
+
+// buildGatewayRuleActions translates the filters and backendRefs of a rule to alb actions, ordered as
+// buildRuleActions does.
+func (t *defaultModelBuildTask) buildGatewayRuleActions(ctx context.Context, ing networking.Ingress, rule gatewayv1.HTTPRouteRule) ([]alb.Action, error) {
+	var (
+		rewriteActions []alb.Action
+		insertActions  []alb.Action
+		removeActions  []alb.Action
+		mirrorActions  []alb.Action
+	)
+	for _, f := range rule.Filters {
+		switch f.Type {
+		case gatewayv1.HTTPRouteFilterRequestRedirect:
+			return []alb.Action{buildGatewayRedirectAction(f.RequestRedirect)}, nil
+		case gatewayv1.HTTPRouteFilterURLRewrite:
+			rewriteActions = append(rewriteActions, buildGatewayRewriteAction(f.URLRewrite))
+		case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+			for _, h := range f.RequestHeaderModifier.Set {
+				insertActions = append(insertActions, buildGatewayInsertHeaderAction(h, true))
+			}
+			for _, h := range f.RequestHeaderModifier.Add {
+				insertActions = append(insertActions, buildGatewayInsertHeaderAction(h, false))
+			}
+			for _, k := range f.RequestHeaderModifier.Remove {
+				removeActions = append(removeActions, alb.Action{
+					Type:               util.RuleActionTypeRemoveHeader,
+					RemoveHeaderConfig: &alb.RemoveHeaderConfig{Key: k},
+				})
+			}
+		case gatewayv1.HTTPRouteFilterRequestMirror:
+			sgp, err := t.buildGatewayServerGroup(ctx, ing, f.RequestMirror.BackendRef)
+			if err != nil {
+				return nil, err
+			}
+			mirrorActions = append(mirrorActions, alb.Action{
+				Type: util.RuleActionTypeTrafficMirror,
+				TrafficMirrorConfig: &alb.TrafficMirrorConfig{
+					TargetType: util.TrafficMirrorTargetTypeForwardGroupMirror,
+					MirrorGroupConfig: alb.MirrorGroupConfig{
+						ServerGroupTuples: []alb.ServerGroupTuple{{
+							ServerGroupID: sgp.ServerGroupID(),
+						}},
+					},
+				},
+			})
+		}
+	}
+
+	finalAction, err := t.buildGatewayForwardAction(ctx, ing, rule.BackendRefs)
+	if err != nil {
+		return nil, err
+	}
+	if finalAction.Type != util.RuleActionTypeForward {
+		return []alb.Action{finalAction}, nil
+	}
+
+	var actions []alb.Action
+	actions = append(actions, rewriteActions...)
+	actions = append(actions, insertActions...)
+	actions = append(actions, removeActions...)
+	actions = append(actions, mirrorActions...)
+	return append(actions, finalAction), nil
+}
+
+func buildGatewayRedirectAction(f *gatewayv1.HTTPRequestRedirectFilter) alb.Action {
+	cfg := &alb.RedirectConfig{
+		Protocol: RedirectProtocolDefault,
+		Host:     RedirectHostDefault,
+		Port:     RedirectPortDefault,
+		Path:     RedirectPathDefault,
+		Query:    RedirectQueryDefault,
+		HttpCode: fmt.Sprintf("%d", GatewayRedirectCodeDefault),
+	}
+	if f.Scheme != nil {
+		cfg.Protocol = strings.ToUpper(*f.Scheme)
+	}
+	if f.Hostname != nil {
+		cfg.Host = string(*f.Hostname)
+	}
+	if f.Port != nil {
+		cfg.Port = fmt.Sprintf("%d", *f.Port)
+	}
+	if f.Path != nil && f.Path.ReplaceFullPath != nil {
+		cfg.Path = *f.Path.ReplaceFullPath
+	}
+	if f.StatusCode != nil {
+		cfg.HttpCode = fmt.Sprintf("%d", *f.StatusCode)
+	}
+	return alb.Action{
+		Type:           util.RuleActionTypeRedirect,
+		RedirectConfig: cfg,
+	}
+}
+
+func buildGatewayRewriteAction(f *gatewayv1.HTTPURLRewriteFilter) alb.Action {
+	cfg := &alb.RewriteConfig{
+		Host:  RewriteHostDefault,
+		Path:  RewritePathDefault,
+		Query: RewriteQueryDefault,
+	}
+	if f.Hostname != nil {
+		cfg.Host = string(*f.Hostname)
+	}
+	if f.Path != nil && f.Path.ReplaceFullPath != nil {
+		cfg.Path = *f.Path.ReplaceFullPath
+	}
+	return alb.Action{
+		Type:          util.RuleActionTypeRewrite,
+		RewriteConfig: cfg,
+	}
+}
+
+func buildGatewayInsertHeaderAction(h gatewayv1.HTTPHeader, cover bool) alb.Action {
+	return alb.Action{
+		Type: util.RuleActionTypeInsertHeader,
+		InsertHeaderConfig: &alb.InsertHeaderConfig{
+			Key:          string(h.Name),
+			Value:        h.Value,
+			ValueType:    util.InsertHeaderValueTypeUserDefined,
+			CoverEnabled: cover,
+		},
+	}
+}
+
+// buildGatewayForwardAction forwards to the backendRefs with their weights scaled into the range of alb,
+// a rule without backends responds 500 as required by the Gateway API.
+func (t *defaultModelBuildTask) buildGatewayForwardAction(ctx context.Context, ing networking.Ingress, refs []gatewayv1.HTTPBackendRef) (alb.Action, error) {
+	if len(refs) == 0 {
+		action, err := t.buildFixedResponseAction(ctx, alb.Action{
+			FixedResponseConfig: &alb.FixedResponseConfig{
+				ContentType: "text/plain",
+				HttpCode:    "500",
+			},
+		})
+		if err != nil {
+			return alb.Action{}, err
+		}
+		return *action, nil
+	}
+
+	maxWeight := int32(0)
+	for _, ref := range refs {
+		if w := gatewayBackendWeight(ref); w > maxWeight {
+			maxWeight = w
+		}
+	}
+
+	var serverGroupTuples []alb.ServerGroupTuple
+	for _, ref := range refs {
+		sgp, err := t.buildGatewayServerGroup(ctx, ing, ref.BackendObjectReference)
+		if err != nil {
+			return alb.Action{}, err
+		}
+		weight := int(gatewayBackendWeight(ref))
+		if maxWeight > GatewayBackendWeightMax {
+			scaled := int64(weight) * GatewayBackendWeightMax / int64(maxWeight)
+			if scaled == 0 && weight != 0 {
+				scaled = 1
+			}
+			weight = int(scaled)
+		}
+		serverGroupTuples = append(serverGroupTuples, alb.ServerGroupTuple{
+			ServerGroupID: sgp.ServerGroupID(),
+			Weight:        weight,
+		})
+	}
+	return alb.Action{
+		Type: util.RuleActionTypeForward,
+		ForwardConfig: &alb.ForwardActionConfig{
+			ServerGroups: serverGroupTuples,
+		},
+	}, nil
+}
+
+func gatewayBackendWeight(ref gatewayv1.HTTPBackendRef) int32 {
+	if ref.Weight == nil {
+		return 1
+	}
+	return *ref.Weight
+}
+
+func (t *defaultModelBuildTask) buildGatewayServerGroup(ctx context.Context, ing networking.Ingress, ref gatewayv1.BackendObjectReference) (*alb.ServerGroup, error) {
+	svc := new(corev1.Service)
+	svc.Namespace = ing.Namespace
+	svc.Name = string(ref.Name)
+	return t.buildServerGroup(ctx, &ing, svc, int(*ref.Port))
+}
+
+// sortGatewayRouteRules orders the rules by the precedence of the Gateway API, alb evaluates the rules
+// with smaller priorities first.
+func sortGatewayRouteRules(rules []*gatewayRouteRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		aType, aPath := gatewayPathMatch(a.match)
+		bType, bPath := gatewayPathMatch(b.match)
+		if (aType == gatewayv1.PathMatchExact) != (bType == gatewayv1.PathMatchExact) {
+			return aType == gatewayv1.PathMatchExact
+		}
+		if len(aPath) != len(bPath) {
+			return len(aPath) > len(bPath)
+		}
+		if (a.match.Method != nil) != (b.match.Method != nil) {
+			return a.match.Method != nil
+		}
+		if len(a.match.Headers) != len(b.match.Headers) {
+			return len(a.match.Headers) > len(b.match.Headers)
+		}
+		if len(a.match.QueryParams) != len(b.match.QueryParams) {
+			return len(a.match.QueryParams) > len(b.match.QueryParams)
+		}
+		if !a.route.CreationTimestamp.Equal(&b.route.CreationTimestamp) {
+			return a.route.CreationTimestamp.Before(&b.route.CreationTimestamp)
+		}
+		if ak, bk := util.NamespacedName(a.route).String(), util.NamespacedName(b.route).String(); ak != bk {
+			return ak < bk
+		}
+		if a.ruleIdx != b.ruleIdx {
+			return a.ruleIdx < b.ruleIdx
+		}
+		return a.matchIdx < b.matchIdx
+	})
+}
@@ -0,0 +1,418 @@
+package albconfigmanager
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func hostnamePtr(h string) *gatewayv1.Hostname {
+	hostname := gatewayv1.Hostname(h)
+	return &hostname
+}
+
+func preciseHostnamePtr(h string) *gatewayv1.PreciseHostname {
+	hostname := gatewayv1.PreciseHostname(h)
+	return &hostname
+}
+
+func newGatewayRoute(name string, hostnames ...string) *gatewayv1.HTTPRoute {
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: name},
+	}
+	for _, h := range hostnames {
+		route.Spec.Hostnames = append(route.Spec.Hostnames, gatewayv1.Hostname(h))
+	}
+	return route
+}
+
+func pathMatch(pathType gatewayv1.PathMatchType, value string) gatewayv1.HTTPRouteMatch {
+	return gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Type: &pathType, Value: &value}}
+}
+
+func backendRef(name string, weight *int32) gatewayv1.HTTPBackendRef {
+	port := gatewayv1.PortNumber(80)
+	return gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(name), Port: &port},
+		Weight:                 weight,
+	}}
+}
+
+func TestComputeGatewayRouteHostnames(t *testing.T) {
+	cases := []struct {
+		name           string
+		listenerHost   string
+		routeHosts     []string
+		expectHosts    []string
+		expectAttached bool
+	}{
+		{
+			name:           "no hostnames",
+			expectAttached: true,
+		},
+		{
+			name:           "listener hostname only",
+			listenerHost:   "foo.example.com",
+			expectHosts:    []string{"foo.example.com"},
+			expectAttached: true,
+		},
+		{
+			name:           "route hostnames only",
+			routeHosts:     []string{"foo.example.com", "*.bar.com"},
+			expectHosts:    []string{"foo.example.com", "*.bar.com"},
+			expectAttached: true,
+		},
+		{
+			name:           "route hostnames within the listener wildcard",
+			listenerHost:   "*.example.com",
+			routeHosts:     []string{"foo.example.com", "foo.bar.com", "example.com"},
+			expectHosts:    []string{"foo.example.com"},
+			expectAttached: true,
+		},
+		{
+			name:           "listener hostname within the route wildcard",
+			listenerHost:   "foo.example.com",
+			routeHosts:     []string{"*.example.com", "foo.example.com"},
+			expectHosts:    []string{"foo.example.com"},
+			expectAttached: true,
+		},
+		{
+			name:         "no shared hostname",
+			listenerHost: "foo.example.com",
+			routeHosts:   []string{"bar.example.com", "*.bar.com"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			l := gatewayv1.Listener{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType}
+			if c.listenerHost != "" {
+				l.Hostname = hostnamePtr(c.listenerHost)
+			}
+			hosts, attached := ComputeGatewayRouteHostnames(l, newGatewayRoute("route", c.routeHosts...))
+			assert.Equal(t, c.expectAttached, attached)
+			if len(c.expectHosts) == 0 {
+				assert.Empty(t, hosts)
+			} else {
+				assert.Equal(t, c.expectHosts, hosts)
+			}
+		})
+	}
+}
+
+func TestValidateGatewayHTTPRoute(t *testing.T) {
+	regex := gatewayv1.PathMatchRegularExpression
+	headerRegex := gatewayv1.HeaderMatchRegularExpression
+	method := gatewayv1.HTTPMethod("TRACE")
+	otherNs := gatewayv1.Namespace("other")
+	kind := gatewayv1.Kind("ServiceImport")
+	prefixModifier := &gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier}
+
+	cases := []struct {
+		name      string
+		rule      gatewayv1.HTTPRouteRule
+		expectErr bool
+	}{
+		{
+			name: "supported matches and filters",
+			rule: gatewayv1.HTTPRouteRule{
+				Matches: []gatewayv1.HTTPRouteMatch{pathMatch(gatewayv1.PathMatchExact, "/foo")},
+				Filters: []gatewayv1.HTTPRouteFilter{
+					{Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier, RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{}},
+					{Type: gatewayv1.HTTPRouteFilterURLRewrite, URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Hostname: preciseHostnamePtr("foo.example.com")}},
+				},
+				BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("svc", nil)},
+			},
+		},
+		{
+			name:      "regular expression path",
+			rule:      gatewayv1.HTTPRouteRule{Matches: []gatewayv1.HTTPRouteMatch{pathMatch(regex, "/fo+")}},
+			expectErr: true,
+		},
+		{
+			name: "regular expression header",
+			rule: gatewayv1.HTTPRouteRule{Matches: []gatewayv1.HTTPRouteMatch{{
+				Headers: []gatewayv1.HTTPHeaderMatch{{Type: &headerRegex, Name: "x-env", Value: "te.t"}},
+			}}},
+			expectErr: true,
+		},
+		{
+			name:      "unsupported method",
+			rule:      gatewayv1.HTTPRouteRule{Matches: []gatewayv1.HTTPRouteMatch{{Method: &method}}},
+			expectErr: true,
+		},
+		{
+			name: "redirect with backends",
+			rule: gatewayv1.HTTPRouteRule{
+				Filters:     []gatewayv1.HTTPRouteFilter{{Type: gatewayv1.HTTPRouteFilterRequestRedirect, RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{}}},
+				BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("svc", nil)},
+			},
+			expectErr: true,
+		},
+		{
+			name: "prefix rewrite",
+			rule: gatewayv1.HTTPRouteRule{
+				Filters: []gatewayv1.HTTPRouteFilter{{Type: gatewayv1.HTTPRouteFilterURLRewrite, URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Path: prefixModifier}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "missing filter config",
+			rule: gatewayv1.HTTPRouteRule{
+				Filters: []gatewayv1.HTTPRouteFilter{{Type: gatewayv1.HTTPRouteFilterRequestMirror}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "backend in another namespace",
+			rule: gatewayv1.HTTPRouteRule{BackendRefs: []gatewayv1.HTTPBackendRef{func() gatewayv1.HTTPBackendRef {
+				ref := backendRef("svc", nil)
+				ref.Namespace = &otherNs
+				return ref
+			}()}},
+			expectErr: true,
+		},
+		{
+			name: "backend not a service",
+			rule: gatewayv1.HTTPRouteRule{BackendRefs: []gatewayv1.HTTPBackendRef{func() gatewayv1.HTTPBackendRef {
+				ref := backendRef("svc", nil)
+				ref.Kind = &kind
+				return ref
+			}()}},
+			expectErr: true,
+		},
+		{
+			name: "backend without port",
+			rule: gatewayv1.HTTPRouteRule{BackendRefs: []gatewayv1.HTTPBackendRef{func() gatewayv1.HTTPBackendRef {
+				ref := backendRef("svc", nil)
+				ref.Port = nil
+				return ref
+			}()}},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			route := newGatewayRoute("route")
+			route.Spec.Rules = []gatewayv1.HTTPRouteRule{c.rule}
+			err := validateGatewayHTTPRoute(route)
+			if c.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func newTestGatewayStackContext(routes ...*gatewayv1.HTTPRoute) *GatewayStackContext {
+	gw := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "gw"}}
+	gw.Spec.Listeners = []gatewayv1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType}}
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Namespace: ALBConfigNamespace, Name: "alb"}}
+	albconfig.Spec.LoadBalancer = &v1.LoadBalancerSpec{Id: "alb-test"}
+	albconfig.Spec.Listeners = []*v1.ListenerSpec{{Port: intstr.FromInt(80), Protocol: string(ProtocolHTTP), IdleTimeout: 30}}
+	return &GatewayStackContext{
+		Gateway:   gw,
+		AlbConfig: albconfig,
+		Listeners: gw.Spec.Listeners,
+		Routes:    map[gatewayv1.SectionName][]*gatewayv1.HTTPRoute{"http": routes},
+	}
+}
+
+func listGatewayRules(t *testing.T, stack interface{ ListResources(interface{}) error }) map[int]*alb.ListenerRule {
+	var rules []*alb.ListenerRule
+	assert.NoError(t, stack.ListResources(&rules))
+	byPriority := make(map[int]*alb.ListenerRule)
+	for _, r := range rules {
+		byPriority[r.Spec.Priority] = r
+	}
+	return byPriority
+}
+
+func pathValues(r *alb.ListenerRule) []string {
+	for _, c := range r.Spec.RuleConditions {
+		if c.Type == util.RuleConditionFieldPath {
+			return c.PathConfig.Values
+		}
+	}
+	return nil
+}
+
+func TestBuildGateway(t *testing.T) {
+	weight := func(w int32) *int32 { return &w }
+	method := gatewayv1.HTTPMethod("GET")
+
+	// the older route is ordered first among the rules of the same precedence
+	older := newGatewayRoute("older", "foo.example.com")
+	older.CreationTimestamp = metav1.Unix(100, 0)
+	older.Spec.Rules = []gatewayv1.HTTPRouteRule{
+		{
+			Matches:     []gatewayv1.HTTPRouteMatch{pathMatch(gatewayv1.PathMatchPathPrefix, "/")},
+			BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("svc-a", weight(1000)), backendRef("svc-b", weight(1))},
+		},
+		{
+			Matches: []gatewayv1.HTTPRouteMatch{pathMatch(gatewayv1.PathMatchPathPrefix, "/api")},
+			Filters: []gatewayv1.HTTPRouteFilter{{
+				Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{Hostname: preciseHostnamePtr("bar.example.com")},
+			}},
+		},
+	}
+	newer := newGatewayRoute("newer")
+	newer.CreationTimestamp = metav1.Unix(200, 0)
+	exact := pathMatch(gatewayv1.PathMatchExact, "/api")
+	withMethod := pathMatch(gatewayv1.PathMatchPathPrefix, "/api")
+	withMethod.Method = &method
+	newer.Spec.Rules = []gatewayv1.HTTPRouteRule{
+		{Matches: []gatewayv1.HTTPRouteMatch{withMethod, exact}, BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("svc-a", nil)}},
+		// a rule without backends responds 500
+		{Matches: []gatewayv1.HTTPRouteMatch{pathMatch(gatewayv1.PathMatchPathPrefix, "/empty")}},
+	}
+	unsupported := newGatewayRoute("unsupported")
+	unsupported.Spec.Rules = []gatewayv1.HTTPRouteRule{{Matches: []gatewayv1.HTTPRouteMatch{pathMatch(gatewayv1.PathMatchRegularExpression, "/a.*")}}}
+
+	kubeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	b := NewDefaultAlbConfigManagerBuilder(kubeClient, fakecloud.NewFakeCloud(nil), nil, logr.Discard())
+	gwCtx := newTestGatewayStackContext(older, newer, unsupported)
+	stack, lb, routeErrs, err := b.BuildGateway(context.TODO(), gwCtx)
+	assert.NoError(t, err)
+	assert.NotNil(t, lb)
+	assert.Equal(t, "alb-test", lb.Spec.LoadBalancerId)
+	assert.True(t, lb.Spec.DeletionProtectionConfig.Enabled)
+
+	// the unsupported route is left out of the stack
+	assert.Len(t, routeErrs, 1)
+	assert.Error(t, routeErrs[types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "unsupported"}])
+
+	var listeners []*alb.Listener
+	assert.NoError(t, stack.ListResources(&listeners))
+	assert.Len(t, listeners, 1)
+	assert.Equal(t, 80, listeners[0].Spec.ListenerPort)
+	assert.Equal(t, 30, listeners[0].Spec.IdleTimeout)
+
+	rules := listGatewayRules(t, stack)
+	assert.Len(t, rules, 5)
+	// exact paths go first, then the longer prefixes, the matches with a method, and the older routes
+	assert.Equal(t, []string{"/api"}, pathValues(rules[1]))
+	assert.Equal(t, []string{"/empty", "/empty/*"}, pathValues(rules[2]))
+	assert.Equal(t, []string{"/api", "/api/*"}, pathValues(rules[3]))
+	assert.Equal(t, util.RuleConditionFieldMethod, rules[3].Spec.RuleConditions[1].Type)
+	assert.Equal(t, []string{"/api", "/api/*"}, pathValues(rules[4]))
+	assert.Equal(t, []string{"/*"}, pathValues(rules[5]))
+	for priority, r := range rules {
+		assert.Equal(t, fmt.Sprintf("%v-80-%v", ListenerRuleNamePrefix, priority), r.Spec.RuleName)
+	}
+
+	// the host condition of the older route only
+	assert.Equal(t, util.RuleConditionFieldHost, rules[4].Spec.RuleConditions[0].Type)
+	assert.Equal(t, []string{"foo.example.com"}, rules[4].Spec.RuleConditions[0].HostConfig.Values)
+	assert.Equal(t, util.RuleConditionFieldPath, rules[1].Spec.RuleConditions[0].Type)
+
+	assert.Equal(t, util.RuleActionTypeRedirect, rules[4].Spec.RuleActions[0].Type)
+	assert.Equal(t, "bar.example.com", rules[4].Spec.RuleActions[0].RedirectConfig.Host)
+	assert.Equal(t, RedirectPathDefault, rules[4].Spec.RuleActions[0].RedirectConfig.Path)
+	assert.Equal(t, "302", rules[4].Spec.RuleActions[0].RedirectConfig.HttpCode)
+
+	assert.Equal(t, util.RuleActionTypeFixedResponse, rules[2].Spec.RuleActions[0].Type)
+	assert.Equal(t, "500", rules[2].Spec.RuleActions[0].FixedResponseConfig.HttpCode)
+
+	// the weights are scaled into the range of alb, a non-zero weight is kept above zero
+	forward := rules[5].Spec.RuleActions[0]
+	assert.Equal(t, util.RuleActionTypeForward, forward.Type)
+	assert.Len(t, forward.ForwardConfig.ServerGroups, 2)
+	assert.Equal(t, GatewayBackendWeightMax, forward.ForwardConfig.ServerGroups[0].Weight)
+	assert.Equal(t, 1, forward.ForwardConfig.ServerGroups[1].Weight)
+
+	// the server groups are shared by the rules of a route, and tagged with the route name
+	var sgps []*alb.ServerGroup
+	assert.NoError(t, stack.ListResources(&sgps))
+	routeSgps := make(map[string]int)
+	for _, sgp := range sgps {
+		routeSgps[sgp.Spec.IngressName]++
+	}
+	assert.Equal(t, 2, routeSgps["older"])
+	assert.Equal(t, 1, routeSgps["newer"])
+	assert.Zero(t, routeSgps["unsupported"])
+}
+
+func TestBuildGatewayRequestFilters(t *testing.T) {
+	route := newGatewayRoute("filters")
+	mirrorPort := gatewayv1.PortNumber(8080)
+	fullPath := "/v2"
+	route.Spec.Rules = []gatewayv1.HTTPRouteRule{{
+		Filters: []gatewayv1.HTTPRouteFilter{
+			{
+				Type: gatewayv1.HTTPRouteFilterRequestMirror,
+				RequestMirror: &gatewayv1.HTTPRequestMirrorFilter{
+					BackendRef: gatewayv1.BackendObjectReference{Name: "mirror", Port: &mirrorPort},
+				},
+			},
+			{
+				Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+				RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+					Set:    []gatewayv1.HTTPHeader{{Name: "x-set", Value: "a"}},
+					Add:    []gatewayv1.HTTPHeader{{Name: "x-add", Value: "b"}},
+					Remove: []string{"x-remove"},
+				},
+			},
+			{
+				Type: gatewayv1.HTTPRouteFilterURLRewrite,
+				URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+					Path: &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: &fullPath},
+				},
+			},
+		},
+		BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("svc", nil)},
+	}}
+
+	kubeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	b := NewDefaultAlbConfigManagerBuilder(kubeClient, fakecloud.NewFakeCloud(nil), nil, logr.Discard())
+	stack, _, routeErrs, err := b.BuildGateway(context.TODO(), newTestGatewayStackContext(route))
+	assert.NoError(t, err)
+	assert.Empty(t, routeErrs)
+
+	rules := listGatewayRules(t, stack)
+	assert.Len(t, rules, 1)
+	var actionTypes []string
+	for _, a := range rules[1].Spec.RuleActions {
+		actionTypes = append(actionTypes, a.Type)
+	}
+	// the actions are ordered as the ones of the ingresses, with the forward action at last
+	assert.Equal(t, []string{
+		util.RuleActionTypeRewrite,
+		util.RuleActionTypeInsertHeader,
+		util.RuleActionTypeInsertHeader,
+		util.RuleActionTypeRemoveHeader,
+		util.RuleActionTypeTrafficMirror,
+		util.RuleActionTypeForward,
+	}, actionTypes)
+	actions := rules[1].Spec.RuleActions
+	assert.Equal(t, "/v2", actions[0].RewriteConfig.Path)
+	assert.Equal(t, RewriteHostDefault, actions[0].RewriteConfig.Host)
+	assert.True(t, actions[1].InsertHeaderConfig.CoverEnabled)
+	assert.False(t, actions[2].InsertHeaderConfig.CoverEnabled)
+	assert.Equal(t, "x-remove", actions[3].RemoveHeaderConfig.Key)
+}
+
+func TestBuildGatewayWithoutLoadBalancer(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	b := NewDefaultAlbConfigManagerBuilder(kubeClient, fakecloud.NewFakeCloud(nil), nil, logr.Discard())
+	gwCtx := newTestGatewayStackContext()
+	gwCtx.AlbConfig.Spec.LoadBalancer = nil
+	_, _, _, err := b.BuildGateway(context.TODO(), gwCtx)
+	assert.Error(t, err)
+}
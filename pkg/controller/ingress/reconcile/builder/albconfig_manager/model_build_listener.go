@@ -3,19 +3,37 @@ package albconfigmanager
 import (
 	"context"
 	"fmt"
+	"reflect"
 
+	"github.com/pkg/errors"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 )
 
-func (t *defaultModelBuildTask) buildListener(ctx context.Context, lbID core.StringToken, lsSpec *v1.ListenerSpec) (*alb.Listener, error) {
-	lsSpecDst, err := t.buildListenerSpec(ctx, lbID, lsSpec)
+// buildListener builds the listener of the spec, the listener upgrades to the QUIC listener if
+// quicLsID is not nil.
+func (t *defaultModelBuildTask) buildListener(ctx context.Context, lbID core.StringToken, lsSpec *v1.ListenerSpec, quicLsID core.StringToken) (*alb.Listener, error) {
+	defaultAction, err := t.buildAlbConfigDefaultAction(ctx, lsSpec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listener %v default actions", lsSpec.Port.String())
+	}
+	lsSpecDst, err := t.buildListenerSpec(ctx, lbID, lsSpec, defaultAction)
 	if err != nil {
 		return nil, err
 	}
+	if quicLsID != nil {
+		lsSpecDst.QuicListenerID = quicLsID
+		lsSpecDst.QuicConfig.QuicUpgradeEnabled = true
+	}
 	lsResID := fmt.Sprintf("%v", lsSpecDst.ListenerPort)
 	ls := alb.NewListener(t.stack, lsResID, lsSpecDst)
+	if defaultAction != nil && defaultAction.Type != util.RuleActionTypeForward {
+		t.buildLsDefaultRule(ctx, ls.ListenerID(), lsSpecDst.ListenerPort, *defaultAction)
+	}
 	return ls, nil
 }
 
@@ -23,10 +41,19 @@ const (
 	ListenerDescriptionPrefix = "ls"
 )
 
-func (t *defaultModelBuildTask) buildListenerSpec(ctx context.Context, lbID core.StringToken, apiLs *v1.ListenerSpec) (alb.ListenerSpec, error) {
-	defaultAction, err := t.buildLsDefaultAction(ctx, apiLs.Port.IntValue())
-	if err != nil {
-		return alb.ListenerSpec{}, err
+func (t *defaultModelBuildTask) buildListenerSpec(ctx context.Context, lbID core.StringToken, apiLs *v1.ListenerSpec,
+	albconfigDefaultAction *alb.Action) (alb.ListenerSpec, error) {
+	var defaultAction alb.Action
+	if albconfigDefaultAction != nil && albconfigDefaultAction.Type == util.RuleActionTypeForward {
+		defaultAction = *albconfigDefaultAction
+	} else {
+		// alb only forwards the unmatched requests of a listener to server groups, the other default
+		// actions are taken by the catch-all rule of the listener
+		var err error
+		defaultAction, err = t.buildLsDefaultAction(ctx, apiLs.Port.IntValue())
+		if err != nil {
+			return alb.ListenerSpec{}, err
+		}
 	}
 	modelLs := alb.ListenerSpec{
 		LoadBalancerID: lbID,
@@ -85,11 +112,13 @@ func (t *defaultModelBuildTask) buildListenerSpec(ctx context.Context, lbID core
 		modelLs.GzipEnabled = t.defaultListenerGzipEnabled
 	}
 
-	if apiLs.Protocol == string(ProtocolHTTPS) {
+	if apiLs.Protocol == string(ProtocolHTTPS) || apiLs.Protocol == string(ProtocolQUIC) {
 		modelLs.Certificates = transCertificatesFromAPIToSDK(apiLs.Certificates)
 		if len(apiLs.CaCertificates) != 0 {
 			modelLs.CaCertificates = transCertificatesFromAPIToSDK(apiLs.CaCertificates)
 		}
+	}
+	if apiLs.Protocol == string(ProtocolHTTPS) {
 		if len(modelLs.SecurityPolicyId) == 0 {
 			modelLs.SecurityPolicyId = t.defaultListenerSecurityPolicyId
 		}
@@ -113,3 +142,243 @@ func transCertificatesFromAPIToSDK(apiCerts []v1.Certificate) []alb.Certificate
 
 	return sdkCerts
 }
+
+const (
+	// ListenerDefaultRulePriority is the priority of the catch-all rule taking the default action of a
+	// listener, which is the lowest priority of alb
+	ListenerDefaultRulePriority = 10000
+	ListenerDefaultRulePath     = "/*"
+
+	defaultFixedResponseContentType = "text/plain"
+)
+
+// buildAlbConfigDefaultAction builds the default action of the listener from the default actions
+// of the AlbConfig, nil is returned if the AlbConfig does not set any.
+func (t *defaultModelBuildTask) buildAlbConfigDefaultAction(ctx context.Context, apiLs *v1.ListenerSpec) (*alb.Action, error) {
+	if len(apiLs.DefaultActions) == 0 {
+		return nil, nil
+	}
+	if len(apiLs.DefaultActions) > 1 {
+		return nil, errors.Errorf("only one default action is supported, got %d", len(apiLs.DefaultActions))
+	}
+	apiAction := apiLs.DefaultActions[0]
+
+	var action alb.Action
+	switch apiAction.Type {
+	case util.RuleActionTypeFixedResponse:
+		if apiAction.FixedResponseConfig == nil {
+			return nil, errors.New("missing fixedResponseConfig")
+		}
+		action = alb.Action{
+			Type: util.RuleActionTypeFixedResponse,
+			FixedResponseConfig: &alb.FixedResponseConfig{
+				Content:     apiAction.FixedResponseConfig.MessageBody,
+				ContentType: apiAction.FixedResponseConfig.ContentType,
+				HttpCode:    apiAction.FixedResponseConfig.StatusCode,
+			},
+		}
+		if action.FixedResponseConfig.ContentType == "" {
+			action.FixedResponseConfig.ContentType = defaultFixedResponseContentType
+		}
+	case util.RuleActionTypeRedirect:
+		if apiAction.RedirectConfig == nil {
+			return nil, errors.New("missing redirectConfig")
+		}
+		action = alb.Action{
+			Type: util.RuleActionTypeRedirect,
+			RedirectConfig: &alb.RedirectConfig{
+				Host:     defaultIfEmpty(apiAction.RedirectConfig.Host, "${host}"),
+				Path:     defaultIfEmpty(apiAction.RedirectConfig.Path, "${path}"),
+				Port:     defaultIfEmpty(apiAction.RedirectConfig.Port, "${port}"),
+				Protocol: defaultIfEmpty(apiAction.RedirectConfig.Protocol, "${protocol}"),
+				Query:    defaultIfEmpty(apiAction.RedirectConfig.Query, "${query}"),
+				HttpCode: apiAction.RedirectConfig.StatusCode,
+			},
+		}
+	case util.RuleActionTypeForward:
+		if apiAction.ForwardConfig == nil || len(apiAction.ForwardConfig.TargetGroups) == 0 {
+			return nil, errors.New("missing forwardConfig")
+		}
+		action = alb.Action{
+			Type:          util.RuleActionTypeForward,
+			ForwardConfig: &alb.ForwardActionConfig{},
+		}
+		for _, tg := range apiAction.ForwardConfig.TargetGroups {
+			if tg.ServiceName == "" || tg.ServicePort.Type != intstr.Int {
+				return nil, errors.Errorf("forward target must be a service with a port number: %s:%s",
+					tg.ServiceName, tg.ServicePort.String())
+			}
+			weight := tg.Weight
+			if weight == 0 {
+				weight = 100
+			}
+			action.ForwardConfig.ServerGroups = append(action.ForwardConfig.ServerGroups, alb.ServerGroupTuple{
+				ServiceName: tg.ServiceName,
+				ServicePort: tg.ServicePort.IntValue(),
+				Weight:      weight,
+			})
+		}
+	default:
+		return nil, errors.Errorf("unsupported default action type: %s", apiAction.Type)
+	}
+
+	// the server groups of the services are owned by the AlbConfig, which are synced with the
+	// endpoints of the services as the ones of the ingresses
+	owner := new(networking.Ingress)
+	owner.Namespace = t.albconfig.Namespace
+	owner.Name = AlbConfigDefaultActionOwner(t.albconfig.Name)
+	action, err := t.buildAction(ctx, *owner, action)
+	if err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// buildLsDefaultRule builds the catch-all rule of the listener, which takes the default action
+// that can not be set on the listener.
+func (t *defaultModelBuildTask) buildLsDefaultRule(ctx context.Context, lsID core.StringToken, port int, action alb.Action) {
+	lrs := alb.ListenerRuleSpec{
+		ListenerID: lsID,
+	}
+	lrs.Priority = ListenerDefaultRulePriority
+	lrs.RuleConditions = []alb.Condition{t.buildPathPatternCondition(ctx, []string{ListenerDefaultRulePath})}
+	lrs.RuleActions = []alb.Action{action}
+	lrs.RuleName = fmt.Sprintf("%v-%v-default", ListenerRuleNamePrefix, port)
+	_ = alb.NewListenerRule(t.stack, fmt.Sprintf("%v:%v", port, ListenerDefaultRulePriority), lrs)
+}
+
+// buildIngressDefaultBackends sets the default action of the listeners to the defaultBackend of the
+// ingresses, if the AlbConfig does not set the default actions.
+func (t *defaultModelBuildTask) buildIngressDefaultBackends(ctx context.Context, lss map[int32]*alb.Listener) error {
+	defaultBackends, _ := ComputeListenerDefaultBackends(t.albconfig, t.ingGroup.Members)
+	for port, ing := range defaultBackends {
+		ls, ok := lss[port]
+		if !ok {
+			continue
+		}
+		backend := ing.Spec.DefaultBackend.Service
+		action, err := t.buildAction(ctx, *ing,
+			buildActionViaServiceAndServicePort(ctx, backend.Name, int(backend.Port.Number), 100))
+		if err != nil {
+			return errors.Wrapf(err, "ingress: %v", util.NamespacedName(ing))
+		}
+		ls.Spec.DefaultActions = []alb.Action{action}
+	}
+	return nil
+}
+
+// AlbConfigDefaultActionOwner returns the owner name of the server groups forwarded by the default
+// actions of the AlbConfig.
+func AlbConfigDefaultActionOwner(albconfigName string) string {
+	return albconfigName + util.AlbConfigDefaultActionFlag
+}
+
+// ComputeListenerSpecs returns the listeners of the albconfig and the port of the QUIC listener
+// which each HTTPS listener upgrades to. The HTTPS listeners without a quicListenerId upgrade to
+// the QUIC listener of the albconfig if there is exactly one, and the QUIC listener without
+// default actions takes the ones of the first HTTPS listener upgrading to it.
+func ComputeListenerSpecs(albconfig *v1.AlbConfig) ([]*v1.ListenerSpec, map[int32]int32) {
+	var quicPorts []int32
+	for _, ls := range albconfig.Spec.Listeners {
+		if ls != nil && ls.Protocol == string(ProtocolQUIC) {
+			quicPorts = append(quicPorts, int32(ls.Port.IntValue()))
+		}
+	}
+	quicUpgrades := make(map[int32]int32)
+	var upgradedLs *v1.ListenerSpec
+	if len(quicPorts) == 1 {
+		for _, ls := range albconfig.Spec.Listeners {
+			if ls != nil && ls.Protocol == string(ProtocolHTTPS) && ls.QuicConfig.QuicListenerId == "" {
+				quicUpgrades[int32(ls.Port.IntValue())] = quicPorts[0]
+				if upgradedLs == nil {
+					upgradedLs = ls
+				}
+			}
+		}
+	}
+
+	lsSpecs := make([]*v1.ListenerSpec, 0, len(albconfig.Spec.Listeners))
+	for _, ls := range albconfig.Spec.Listeners {
+		if ls == nil {
+			continue
+		}
+		if ls.Protocol == string(ProtocolQUIC) && len(ls.DefaultActions) == 0 && upgradedLs != nil {
+			lsCopy := *ls
+			lsCopy.DefaultActions = upgradedLs.DefaultActions
+			ls = &lsCopy
+		}
+		lsSpecs = append(lsSpecs, ls)
+	}
+	return lsSpecs, quicUpgrades
+}
+
+// DefaultBackendConflict is an ingress whose defaultBackend is ignored by a listener
+type DefaultBackendConflict struct {
+	Ingress *networking.Ingress
+	Port    int32
+	Message string
+}
+
+// ComputeListenerDefaultBackends returns the ingress whose defaultBackend is the default action of
+// the listener on each port, and the ingresses whose defaultBackends are ignored. The default
+// actions of the AlbConfig take precedence over all the defaultBackends, otherwise the first
+// ingress in the group order wins.
+func ComputeListenerDefaultBackends(albconfig *v1.AlbConfig, members []*networking.Ingress) (map[int32]*networking.Ingress, []DefaultBackendConflict) {
+	lsSpecs, quicUpgrades := ComputeListenerSpecs(albconfig)
+	albconfigDefaults := make(map[int32]bool)
+	for _, ls := range lsSpecs {
+		albconfigDefaults[int32(ls.Port.IntValue())] = len(ls.DefaultActions) != 0
+	}
+
+	defaultBackends := make(map[int32]*networking.Ingress)
+	var conflicts []DefaultBackendConflict
+	for _, ing := range members {
+		if ing.Spec.DefaultBackend == nil {
+			continue
+		}
+		ports, err := ComputeIngressListenPorts(ing)
+		if err != nil {
+			// the error is reported when building the rules of the ingress
+			continue
+		}
+		listenPorts := make(map[int32]Protocol, len(ports))
+		for port, protocol := range ports {
+			listenPorts[port] = protocol
+		}
+		mirrorQuicListenPorts(ports, quicUpgrades)
+		for port := range ports {
+			hasAlbConfigDefault, ok := albconfigDefaults[port]
+			if !ok {
+				continue
+			}
+			conflict := DefaultBackendConflict{Ingress: ing, Port: port}
+			switch {
+			case ing.Spec.DefaultBackend.Service == nil:
+				conflict.Message = fmt.Sprintf("defaultBackend is ignored on listener %d: only service backends are supported", port)
+			case hasAlbConfigDefault:
+				conflict.Message = fmt.Sprintf("defaultBackend is ignored on listener %d: the default actions are set by albconfig %s",
+					port, util.NamespacedName(albconfig))
+			case defaultBackends[port] == nil:
+				defaultBackends[port] = ing
+				continue
+			case reflect.DeepEqual(defaultBackends[port].Spec.DefaultBackend, ing.Spec.DefaultBackend):
+				continue
+			default:
+				conflict.Message = fmt.Sprintf("defaultBackend is ignored on listener %d: the defaultBackend of ingress %s takes precedence by the group order",
+					port, util.NamespacedName(defaultBackends[port]))
+			}
+			// the conflicts are reported on the listen ports of the ingress but not the mirrored ones
+			if _, ok := listenPorts[port]; ok {
+				conflicts = append(conflicts, conflict)
+			}
+		}
+	}
+	return defaultBackends, conflicts
+}
+
+func defaultIfEmpty(v, defaultValue string) string {
+	if v == "" {
+		return defaultValue
+	}
+	return v
+}
@@ -2,7 +2,11 @@ package albconfigmanager
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -10,6 +14,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
@@ -23,10 +29,17 @@ const (
 	HTTPRedirectCode       = "308"
 	CookieAlways           = "always"
 	HTTPS443               = "443"
+
+	RewriteHostDefault  = "${host}"
+	RewriteQueryDefault = "${query}"
+	QpsLimitMin         = 1
+	QpsLimitMax         = 100000
 )
 
-func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID core.StringToken, port int32, ingList []networking.Ingress) error {
+func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID core.StringToken, port int32, protocol Protocol, ingList []networking.Ingress) error {
 	var rules []alb.ListenerRule
+	// the response rules follow the request rules, so that each rule has a unique priority
+	var responseRules []alb.ListenerRule
 	carryWeight := make(map[string][]alb.ServerGroupTuple)
 	for _, ing := range ingList {
 		if v := annotations.GetStringAnnotationMutil(annotations.NginxCanary, annotations.AlbCanary, &ing); v == "true" {
@@ -74,7 +87,7 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 
 			for _, path := range rule.HTTP.Paths {
 				var action alb.Action
-				if v := annotations.GetStringAnnotationMutil(annotations.NginxSslRedirect, annotations.AlbSslRedirect, &ing); v == "true" && port != 443 {
+				if isSslRedirected(&ing, port, protocol) {
 					action = buildActionViaHostAndPath(ctx, rule.Host, path.Path)
 				} else {
 					action = buildActionViaServiceAndServicePort(ctx, path.Backend.Service.Name, int(path.Backend.Service.Port.Number), 100)
@@ -92,28 +105,44 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 					}
 				}
 
-				conditions, err := t.buildRuleConditions(ctx, rule, path, ing)
+				conditions, responseConditions, err := t.buildRuleConditions(ctx, rule, path, ing)
 				if err != nil {
 					return errors.Wrapf(err, "ingress: %v", util.NamespacedName(&ing))
 				}
-				action2, err := t.buildAction(ctx, ing, action)
+				actions, err := t.buildRuleActions(ctx, ing, action)
 				if err != nil {
 					return errors.Wrapf(err, "ingress: %v", util.NamespacedName(&ing))
 				}
 				lrs := alb.ListenerRuleSpec{
 					ListenerID: lsID,
 				}
-				lrs.RuleActions = []alb.Action{action2}
+				lrs.RuleActions = actions
 				lrs.RuleConditions = conditions
 				rules = append(rules, alb.ListenerRule{
 					Spec: lrs,
 				})
+
+				if isSslRedirected(&ing, port, protocol) {
+					continue
+				}
+				responseRule, err := t.buildResponseRule(ctx, lsID, ing, conditions, responseConditions)
+				if err != nil {
+					return errors.Wrapf(err, "ingress: %v", util.NamespacedName(&ing))
+				}
+				if responseRule != nil {
+					responseRules = append(responseRules, *responseRule)
+				}
 			}
 		}
 	}
 
 	priority := 1
-	for _, rule := range rules {
+	for _, rule := range append(rules, responseRules...) {
+		if priority >= ListenerDefaultRulePriority {
+			// the lowest priority is reserved for the catch-all rule of the default action
+			return errors.Errorf("too many rules on listener %d, at most %d rules are supported",
+				port, ListenerDefaultRulePriority-1)
+		}
 		ruleResID := fmt.Sprintf("%v:%v", port, priority)
 		klog.Infof("ruleResID: %s", ruleResID)
 		lrs := alb.ListenerRuleSpec{
@@ -122,6 +151,7 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 		lrs.Priority = priority
 		lrs.RuleConditions = rule.Spec.RuleConditions
 		lrs.RuleActions = rule.Spec.RuleActions
+		lrs.Direction = rule.Spec.Direction
 		lrs.RuleName = fmt.Sprintf("%v-%v-%v", ListenerRuleNamePrefix, port, priority)
 		_ = alb.NewListenerRule(t.stack, ruleResID, lrs)
 		priority += 1
@@ -241,8 +271,10 @@ func (t *defaultModelBuildTask) buildPathPatternCondition(_ context.Context, pat
 	}
 }
 
+// buildRuleConditions returns the conditions of the request rule of a path, and the custom response
+// conditions of the path, which are only evaluated by the response rule.
 func (t *defaultModelBuildTask) buildRuleConditions(ctx context.Context, rule networking.IngressRule,
-	path networking.HTTPIngressPath, ing networking.Ingress) ([]alb.Condition, error) {
+	path networking.HTTPIngressPath, ing networking.Ingress) ([]alb.Condition, []alb.Condition, error) {
 	var hosts []string
 	if rule.Host != "" {
 		hosts = append(hosts, rule.Host)
@@ -251,7 +283,7 @@ func (t *defaultModelBuildTask) buildRuleConditions(ctx context.Context, rule ne
 	if path.Path != "" {
 		pathPatterns, err := t.buildPathPatterns(path.Path, path.PathType)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		paths = append(paths, pathPatterns...)
 	}
@@ -275,10 +307,156 @@ func (t *defaultModelBuildTask) buildRuleConditions(ctx context.Context, rule ne
 		}
 
 	}
+	var responseConditions []alb.Condition
+	if path.Backend.Service != nil {
+		customConditions, err := t.buildCustomRuleConditions(ctx, path.Backend.Service.Name, ing)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range customConditions {
+			if isResponseRuleCondition(c) {
+				responseConditions = append(responseConditions, c)
+			} else {
+				conditions = append(conditions, c)
+			}
+		}
+	}
 
+	return conditions, responseConditions, nil
+}
+
+func isResponseRuleCondition(condition alb.Condition) bool {
+	return condition.Type == util.RuleConditionFieldResponseHeader || condition.Type == util.RuleConditionFieldResponseStatusCode
+}
+
+// buildResponseRule builds the Response direction rule of a path, which rewrites the headers of the
+// responses matching the host and path of the request rule and the response conditions. It returns
+// nil if no response header annotation is set.
+func (t *defaultModelBuildTask) buildResponseRule(ctx context.Context, lsID core.StringToken, ing networking.Ingress,
+	conditions, responseConditions []alb.Condition) (*alb.ListenerRule, error) {
+	insertHeaderActions, err := t.buildInsertHeaderActions(ctx, ing, annotations.AlbResponseInsertHeader)
+	if err != nil {
+		return nil, err
+	}
+	actions := append(insertHeaderActions, t.buildRemoveHeaderActions(ctx, ing, annotations.AlbResponseRemoveHeader)...)
+	if len(actions) == 0 {
+		if len(responseConditions) != 0 {
+			return nil, errors.Errorf("response conditions require annotation %s or %s",
+				annotations.AlbResponseInsertHeader, annotations.AlbResponseRemoveHeader)
+		}
+		return nil, nil
+	}
+
+	// response rules only match the host and path of the request
+	var ruleConditions []alb.Condition
+	for _, c := range conditions {
+		if c.Type == util.RuleConditionFieldHost || c.Type == util.RuleConditionFieldPath {
+			ruleConditions = append(ruleConditions, c)
+		}
+	}
+	lrs := alb.ListenerRuleSpec{
+		ListenerID: lsID,
+	}
+	lrs.Direction = util.RuleDirectionResponse
+	lrs.RuleConditions = append(ruleConditions, responseConditions...)
+	lrs.RuleActions = actions
+	return &alb.ListenerRule{Spec: lrs}, nil
+}
+
+var supportedRuleConditionMethods = sets.NewString("GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS")
+
+// buildCustomRuleConditions parses the extra conditions configured for the backend service of a path.
+func (t *defaultModelBuildTask) buildCustomRuleConditions(_ context.Context, svcName string, ing networking.Ingress) ([]alb.Condition, error) {
+	key := annotations.AlbConditionsPrefix + svcName
+	raw, ok := ing.Annotations[key]
+	if !ok {
+		return nil, nil
+	}
+	var conditions []alb.Condition
+	if err := json.Unmarshal([]byte(raw), &conditions); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse json annotation, %s: %s", key, raw)
+	}
+	for i := range conditions {
+		if err := validateCustomRuleCondition(&conditions[i]); err != nil {
+			return nil, errors.Wrapf(err, "invalid annotation %s", key)
+		}
+	}
 	return conditions, nil
 }
 
+func validateCustomRuleCondition(condition *alb.Condition) error {
+	switch condition.Type {
+	case util.RuleConditionFieldHost:
+		if len(condition.HostConfig.Values) == 0 {
+			return errors.New("host condition must have values")
+		}
+	case util.RuleConditionFieldPath:
+		if len(condition.PathConfig.Values) == 0 {
+			return errors.New("path condition must have values")
+		}
+	case util.RuleConditionFieldMethod:
+		if len(condition.MethodConfig.Values) == 0 {
+			return errors.New("method condition must have values")
+		}
+		for i, m := range condition.MethodConfig.Values {
+			m = strings.ToUpper(m)
+			if !supportedRuleConditionMethods.Has(m) {
+				return errors.Errorf("method must be within %v: %s", supportedRuleConditionMethods.List(), m)
+			}
+			condition.MethodConfig.Values[i] = m
+		}
+	case util.RuleConditionFieldQueryString:
+		if err := validateRuleConditionValues(condition.Type, condition.QueryStringConfig.Values); err != nil {
+			return err
+		}
+	case util.RuleConditionFieldCookie:
+		if err := validateRuleConditionValues(condition.Type, condition.CookieConfig.Values); err != nil {
+			return err
+		}
+	case util.RuleConditionFieldHeader:
+		if len(condition.HeaderConfig.Key) == 0 || len(condition.HeaderConfig.Values) == 0 {
+			return errors.New("header condition must have key and values")
+		}
+	case util.RuleConditionFieldSourceIp:
+		if len(condition.SourceIpConfig.Values) == 0 {
+			return errors.New("sourceIp condition must have values")
+		}
+		for _, v := range condition.SourceIpConfig.Values {
+			if _, _, err := net.ParseCIDR(v); err != nil && net.ParseIP(v) == nil {
+				return errors.Errorf("sourceIp must be an ip or cidr: %s", v)
+			}
+		}
+	case util.RuleConditionFieldResponseHeader:
+		if len(condition.ResponseHeaderConfig.Key) == 0 || len(condition.ResponseHeaderConfig.Values) == 0 {
+			return errors.New("responseHeader condition must have key and values")
+		}
+	case util.RuleConditionFieldResponseStatusCode:
+		if len(condition.ResponseStatusCodeConfig.Values) == 0 {
+			return errors.New("responseStatusCode condition must have values")
+		}
+		for _, v := range condition.ResponseStatusCodeConfig.Values {
+			if code, err := strconv.Atoi(v); err != nil || code < 100 || code > 599 {
+				return errors.Errorf("responseStatusCode must be a http status code: %s", v)
+			}
+		}
+	default:
+		return errors.Errorf("unknown condition type: %s", condition.Type)
+	}
+	return nil
+}
+
+func validateRuleConditionValues(conditionType string, values []alb.Value) error {
+	if len(values) == 0 {
+		return errors.Errorf("%s condition must have values", conditionType)
+	}
+	for _, v := range values {
+		if len(v.Key) == 0 || len(v.Value) == 0 {
+			return errors.Errorf("%s condition values must have key and value", conditionType)
+		}
+	}
+	return nil
+}
+
 func (t *defaultModelBuildTask) buildFixedResponseAction(_ context.Context, actionCfg alb.Action) (*alb.Action, error) {
 	if len(actionCfg.FixedResponseConfig.ContentType) == 0 {
 		return nil, errors.New("missing FixedResponseConfig")
@@ -357,3 +535,282 @@ func (t *defaultModelBuildTask) buildAction(ctx context.Context, ing networking.
 	}
 	return *backendAction, nil
 }
+
+// isSslRedirected returns whether the rules of the ingress on the listener redirect to https. The
+// QUIC listeners are already encrypted, whose rules are mirrored from the HTTPS listeners.
+func isSslRedirected(ing *networking.Ingress, port int32, protocol Protocol) bool {
+	v := annotations.GetStringAnnotationMutil(annotations.NginxSslRedirect, annotations.AlbSslRedirect, ing)
+	return v == "true" && port != 443 && protocol != ProtocolQUIC
+}
+
+// ruleActionAnnotations are the annotations chained in front of the forward action of a rule, or
+// building the response rule of it
+var ruleActionAnnotations = []string{
+	annotations.AlbQpsLimit,
+	annotations.NginxRewriteTarget,
+	annotations.AlbRewriteTarget,
+	annotations.AlbInsertHeader,
+	annotations.AlbRemoveHeader,
+	annotations.AlbTrafficMirrorToService,
+	annotations.AlbResponseInsertHeader,
+	annotations.AlbResponseRemoveHeader,
+}
+
+// IgnoredActionAnnotations is an ingress whose rule action annotations are ignored by the rules
+// redirecting to https on a listener
+type IgnoredActionAnnotations struct {
+	Ingress *networking.Ingress
+	Port    int32
+	Message string
+}
+
+// ComputeIgnoredActionAnnotations returns the ingresses whose rule action annotations are ignored,
+// which alb only accepts together with a ForwardGroup action, on the listeners where ssl-redirect
+// replaces the forward action by a redirect.
+func ComputeIgnoredActionAnnotations(albconfig *v1.AlbConfig, members []*networking.Ingress) []IgnoredActionAnnotations {
+	lsSpecs, quicUpgrades := ComputeListenerSpecs(albconfig)
+	lsPorts := make(map[int32]bool)
+	for _, ls := range lsSpecs {
+		lsPorts[int32(ls.Port.IntValue())] = true
+	}
+
+	var ignored []IgnoredActionAnnotations
+	for _, ing := range members {
+		var names []string
+		for _, name := range ruleActionAnnotations {
+			if _, ok := ing.Annotations[name]; ok {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		listenPorts, err := ComputeIngressListenPorts(ing)
+		if err != nil {
+			// the error is reported when building the rules of the ingress
+			continue
+		}
+		mirrorQuicListenPorts(listenPorts, quicUpgrades)
+		var ports []int32
+		for port, protocol := range listenPorts {
+			if lsPorts[port] && isSslRedirected(ing, port, protocol) {
+				ports = append(ports, port)
+			}
+		}
+		sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+		for _, port := range ports {
+			ignored = append(ignored, IgnoredActionAnnotations{
+				Ingress: ing,
+				Port:    port,
+				Message: fmt.Sprintf("annotations %s are ignored on listener %d: the rules redirect to https by ssl-redirect",
+					strings.Join(names, ", "), port),
+			})
+		}
+	}
+	return ignored
+}
+
+// buildRuleActions chains the actions configured by annotations in front of the final action.
+// ALB only accepts these extra actions together with a ForwardGroup action, and they must be
+// ordered as TrafficLimit, Rewrite, InsertHeader, RemoveHeader, TrafficMirror, ForwardGroup.
+func (t *defaultModelBuildTask) buildRuleActions(ctx context.Context, ing networking.Ingress, action alb.Action) ([]alb.Action, error) {
+	finalAction, err := t.buildAction(ctx, ing, action)
+	if err != nil {
+		return nil, err
+	}
+	if finalAction.Type != util.RuleActionTypeForward {
+		return []alb.Action{finalAction}, nil
+	}
+
+	var actions []alb.Action
+	trafficLimitAction, err := t.buildTrafficLimitAction(ctx, ing)
+	if err != nil {
+		return nil, err
+	}
+	if trafficLimitAction != nil {
+		actions = append(actions, *trafficLimitAction)
+	}
+	if rewriteAction := t.buildRewriteAction(ctx, ing); rewriteAction != nil {
+		actions = append(actions, *rewriteAction)
+	}
+	insertHeaderActions, err := t.buildInsertHeaderActions(ctx, ing, annotations.AlbInsertHeader)
+	if err != nil {
+		return nil, err
+	}
+	actions = append(actions, insertHeaderActions...)
+	actions = append(actions, t.buildRemoveHeaderActions(ctx, ing, annotations.AlbRemoveHeader)...)
+	trafficMirrorAction, err := t.buildTrafficMirrorAction(ctx, ing)
+	if err != nil {
+		return nil, err
+	}
+	if trafficMirrorAction != nil {
+		actions = append(actions, *trafficMirrorAction)
+	}
+
+	return append(actions, finalAction), nil
+}
+
+func (t *defaultModelBuildTask) buildTrafficLimitAction(_ context.Context, ing networking.Ingress) (*alb.Action, error) {
+	raw, err := annotations.GetStringAnnotation(annotations.AlbQpsLimit, &ing)
+	if err != nil {
+		return nil, nil
+	}
+	qps, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse qps-limit annotation: %s", raw)
+	}
+	if qps < QpsLimitMin || qps > QpsLimitMax {
+		return nil, errors.Errorf("qps-limit must be within [%d, %d]: %d", QpsLimitMin, QpsLimitMax, qps)
+	}
+	return &alb.Action{
+		Type: util.RuleActionTypeTrafficLimit,
+		TrafficLimitConfig: &alb.TrafficLimitConfig{
+			QPS: qps,
+		},
+	}, nil
+}
+
+// nginxCaptureGroup matches nginx style capture group references such as $1
+var nginxCaptureGroup = regexp.MustCompile(`\$([1-3])`)
+
+func (t *defaultModelBuildTask) buildRewriteAction(_ context.Context, ing networking.Ingress) *alb.Action {
+	target := annotations.GetStringAnnotationMutil(annotations.NginxRewriteTarget, annotations.AlbRewriteTarget, &ing)
+	if target == "" {
+		return nil
+	}
+	// alb references capture groups as ${1}, convert the nginx style $1 for compatibility
+	if !strings.Contains(target, "${") {
+		target = nginxCaptureGroup.ReplaceAllString(target, "$${$1}")
+	}
+	return &alb.Action{
+		Type: util.RuleActionTypeRewrite,
+		RewriteConfig: &alb.RewriteConfig{
+			Host:  RewriteHostDefault,
+			Path:  target,
+			Query: RewriteQueryDefault,
+		},
+	}
+}
+
+type insertHeaderValue struct {
+	Value        string `json:"value"`
+	ValueType    string `json:"valueType"`
+	CoverEnabled bool   `json:"coverEnabled"`
+}
+
+// buildInsertHeaderActions builds the InsertHeader actions of the insert-header or the
+// response-insert-header annotation.
+func (t *defaultModelBuildTask) buildInsertHeaderActions(_ context.Context, ing networking.Ingress, key string) ([]alb.Action, error) {
+	raw, err := annotations.GetStringAnnotation(key, &ing)
+	if err != nil {
+		return nil, nil
+	}
+	headers := make(map[string]insertHeaderValue)
+	if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse annotation %s: %s", key, raw)
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var actions []alb.Action
+	for _, k := range keys {
+		if k == "" {
+			return nil, errors.Errorf("annotation %s contains empty header name: %s", key, raw)
+		}
+		v := headers[k]
+		if v.ValueType == "" {
+			v.ValueType = util.InsertHeaderValueTypeUserDefined
+		}
+		switch v.ValueType {
+		case util.InsertHeaderValueTypeUserDefined, util.InsertHeaderValueTypeReferenceHeader, util.InsertHeaderValueTypeSystemDefined:
+		default:
+			return nil, errors.Errorf("insert-header valueType must be within [%s, %s, %s]: %s",
+				util.InsertHeaderValueTypeUserDefined, util.InsertHeaderValueTypeReferenceHeader, util.InsertHeaderValueTypeSystemDefined, v.ValueType)
+		}
+		actions = append(actions, alb.Action{
+			Type: util.RuleActionTypeInsertHeader,
+			InsertHeaderConfig: &alb.InsertHeaderConfig{
+				Key:          k,
+				Value:        v.Value,
+				ValueType:    v.ValueType,
+				CoverEnabled: v.CoverEnabled,
+			},
+		})
+	}
+	return actions, nil
+}
+
+// buildRemoveHeaderActions builds the RemoveHeader actions of the remove-header or the
+// response-remove-header annotation.
+func (t *defaultModelBuildTask) buildRemoveHeaderActions(_ context.Context, ing networking.Ingress, key string) []alb.Action {
+	raw, err := annotations.GetStringAnnotation(key, &ing)
+	if err != nil {
+		return nil
+	}
+	var actions []alb.Action
+	for _, k := range strings.Split(raw, ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		actions = append(actions, alb.Action{
+			Type: util.RuleActionTypeRemoveHeader,
+			RemoveHeaderConfig: &alb.RemoveHeaderConfig{
+				Key: k,
+			},
+		})
+	}
+	return actions
+}
+
+type TrafficMirrorService struct {
+	ServiceName string `json:"serviceName"`
+	ServicePort int    `json:"servicePort"`
+}
+
+// ComputeIngressTrafficMirrorService returns the service that traffic of the ingress is mirrored to,
+// or nil if traffic mirroring is not configured.
+func ComputeIngressTrafficMirrorService(ing *networking.Ingress) (*TrafficMirrorService, error) {
+	raw, err := annotations.GetStringAnnotation(annotations.AlbTrafficMirrorToService, ing)
+	if err != nil {
+		return nil, nil
+	}
+	var mirror TrafficMirrorService
+	if err := json.Unmarshal([]byte(raw), &mirror); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse traffic-mirror-to-service annotation: %s", raw)
+	}
+	if mirror.ServiceName == "" || mirror.ServicePort < 1 || mirror.ServicePort > 65535 {
+		return nil, errors.Errorf("traffic-mirror-to-service must specify serviceName and servicePort within [1, 65535]: %s", raw)
+	}
+	return &mirror, nil
+}
+
+func (t *defaultModelBuildTask) buildTrafficMirrorAction(ctx context.Context, ing networking.Ingress) (*alb.Action, error) {
+	mirror, err := ComputeIngressTrafficMirrorService(&ing)
+	if err != nil || mirror == nil {
+		return nil, err
+	}
+
+	svc := new(corev1.Service)
+	svc.Namespace = ing.Namespace
+	svc.Name = mirror.ServiceName
+	modelSgp, err := t.buildServerGroup(ctx, &ing, svc, mirror.ServicePort)
+	if err != nil {
+		return nil, err
+	}
+	return &alb.Action{
+		Type: util.RuleActionTypeTrafficMirror,
+		TrafficMirrorConfig: &alb.TrafficMirrorConfig{
+			TargetType: util.TrafficMirrorTargetTypeForwardGroupMirror,
+			MirrorGroupConfig: alb.MirrorGroupConfig{
+				ServerGroupTuples: []alb.ServerGroupTuple{{
+					ServerGroupID: modelSgp.ServerGroupID(),
+				}},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,407 @@
+package albconfigmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func TestBuildCustomRuleConditions(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation string
+		expect     []alb.Condition
+		expectErr  bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:       "method is upper cased",
+			annotation: `[{"type":"Method","methodConfig":{"values":["get","HEAD"]}}]`,
+			expect:     []alb.Condition{{Type: util.RuleConditionFieldMethod, MethodConfig: alb.MethodConfig{Values: []string{"GET", "HEAD"}}}},
+		},
+		{
+			name:       "unsupported method",
+			annotation: `[{"type":"Method","methodConfig":{"values":["TRACE"]}}]`,
+			expectErr:  true,
+		},
+		{
+			name:       "query string",
+			annotation: `[{"type":"QueryString","queryStringConfig":{"values":[{"key":"version","value":"v2"}]}}]`,
+			expect: []alb.Condition{{Type: util.RuleConditionFieldQueryString, QueryStringConfig: alb.QueryStringConfig{
+				Values: []alb.Value{{Key: "version", Value: "v2"}},
+			}}},
+		},
+		{
+			name:       "query string without value",
+			annotation: `[{"type":"QueryString","queryStringConfig":{"values":[{"key":"version"}]}}]`,
+			expectErr:  true,
+		},
+		{
+			name:       "cookie without values",
+			annotation: `[{"type":"Cookie","cookieConfig":{"values":[]}}]`,
+			expectErr:  true,
+		},
+		{
+			name:       "header",
+			annotation: `[{"type":"Header","headerConfig":{"key":"X-Env","values":["gray"]}}]`,
+			expect:     []alb.Condition{{Type: util.RuleConditionFieldHeader, HeaderConfig: alb.HeaderConfig{Key: "X-Env", Values: []string{"gray"}}}},
+		},
+		{
+			name:       "header without key",
+			annotation: `[{"type":"Header","headerConfig":{"values":["gray"]}}]`,
+			expectErr:  true,
+		},
+		{
+			name:       "source ip and cidr",
+			annotation: `[{"type":"SourceIp","sourceIpConfig":{"values":["10.0.0.1","192.168.0.0/16"]}}]`,
+			expect:     []alb.Condition{{Type: util.RuleConditionFieldSourceIp, SourceIpConfig: alb.SourceIpConfig{Values: []string{"10.0.0.1", "192.168.0.0/16"}}}},
+		},
+		{
+			name:       "invalid source ip",
+			annotation: `[{"type":"SourceIp","sourceIpConfig":{"values":["10.0.0.256"]}}]`,
+			expectErr:  true,
+		},
+		{
+			name:       "response header",
+			annotation: `[{"type":"ResponseHeader","responseHeaderConfig":{"key":"X-Env","values":["gray"]}}]`,
+			expect: []alb.Condition{{Type: util.RuleConditionFieldResponseHeader, ResponseHeaderConfig: alb.ResponseHeaderConfig{
+				Key: "X-Env", Values: []string{"gray"},
+			}}},
+		},
+		{
+			name:       "response header without key",
+			annotation: `[{"type":"ResponseHeader","responseHeaderConfig":{"values":["gray"]}}]`,
+			expectErr:  true,
+		},
+		{
+			name:       "response status code",
+			annotation: `[{"type":"ResponseStatusCode","responseStatusCodeConfig":{"values":["200","404"]}}]`,
+			expect: []alb.Condition{{Type: util.RuleConditionFieldResponseStatusCode, ResponseStatusCodeConfig: alb.ResponseStatusCodeConfig{
+				Values: []string{"200", "404"},
+			}}},
+		},
+		{
+			name:       "invalid response status code",
+			annotation: `[{"type":"ResponseStatusCode","responseStatusCodeConfig":{"values":["2xx"]}}]`,
+			expectErr:  true,
+		},
+		{
+			name:       "unknown type",
+			annotation: `[{"type":"Unknown"}]`,
+			expectErr:  true,
+		},
+		{
+			name:       "invalid json",
+			annotation: `{"type":"Method"`,
+			expectErr:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ing := networking.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: "default"}}
+			if c.annotation != "" {
+				ing.Annotations = map[string]string{annotations.AlbConditionsPrefix + "svc": c.annotation}
+			}
+			task := &defaultModelBuildTask{}
+			conditions, err := task.buildCustomRuleConditions(context.TODO(), "svc", ing)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, conditions)
+		})
+	}
+}
+
+func TestBuildRuleActions(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectTypes []string
+		expect      map[string]alb.Action
+		expectErr   bool
+	}{
+		{
+			name:        "forward only",
+			expectTypes: []string{util.RuleActionTypeForward},
+		},
+		{
+			name: "extra actions are ordered ahead of the forward action",
+			annotations: map[string]string{
+				annotations.AlbQpsLimit:               "100",
+				annotations.AlbRewriteTarget:          "/bar/${1}",
+				annotations.AlbInsertHeader:           `{"X-Source":{"value":"client_src_ip","valueType":"SystemDefined"},"X-Env":{"value":"gray","coverEnabled":true}}`,
+				annotations.AlbRemoveHeader:           "X-Debug, ,X-Trace",
+				annotations.AlbTrafficMirrorToService: `{"serviceName":"mirror-svc","servicePort":8080}`,
+			},
+			expectTypes: []string{
+				util.RuleActionTypeTrafficLimit,
+				util.RuleActionTypeRewrite,
+				util.RuleActionTypeInsertHeader,
+				util.RuleActionTypeInsertHeader,
+				util.RuleActionTypeRemoveHeader,
+				util.RuleActionTypeRemoveHeader,
+				util.RuleActionTypeTrafficMirror,
+				util.RuleActionTypeForward,
+			},
+			expect: map[string]alb.Action{
+				util.RuleActionTypeTrafficLimit: {Type: util.RuleActionTypeTrafficLimit, TrafficLimitConfig: &alb.TrafficLimitConfig{QPS: 100}},
+				util.RuleActionTypeRewrite: {Type: util.RuleActionTypeRewrite, RewriteConfig: &alb.RewriteConfig{
+					Host: RewriteHostDefault, Path: "/bar/${1}", Query: RewriteQueryDefault,
+				}},
+			},
+		},
+		{
+			name:        "nginx style capture groups of the rewrite target are converted",
+			annotations: map[string]string{annotations.NginxRewriteTarget: "/$2/$1"},
+			expectTypes: []string{util.RuleActionTypeRewrite, util.RuleActionTypeForward},
+			expect: map[string]alb.Action{
+				util.RuleActionTypeRewrite: {Type: util.RuleActionTypeRewrite, RewriteConfig: &alb.RewriteConfig{
+					Host: RewriteHostDefault, Path: "/${2}/${1}", Query: RewriteQueryDefault,
+				}},
+			},
+		},
+		{
+			name:        "qps limit out of range",
+			annotations: map[string]string{annotations.AlbQpsLimit: "0"},
+			expectErr:   true,
+		},
+		{
+			name:        "invalid qps limit",
+			annotations: map[string]string{annotations.AlbQpsLimit: "many"},
+			expectErr:   true,
+		},
+		{
+			name:        "invalid insert header value type",
+			annotations: map[string]string{annotations.AlbInsertHeader: `{"X-Env":{"value":"gray","valueType":"Unknown"}}`},
+			expectErr:   true,
+		},
+		{
+			name:        "invalid insert header json",
+			annotations: map[string]string{annotations.AlbInsertHeader: `{"X-Env":"gray"}`},
+			expectErr:   true,
+		},
+		{
+			name:        "traffic mirror without service port",
+			annotations: map[string]string{annotations.AlbTrafficMirrorToService: `{"serviceName":"mirror-svc"}`},
+			expectErr:   true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ing := newTestIngress("ing", nil, "/foo")
+			ing.Annotations = c.annotations
+			_, rules, err := buildTestStack(t, newTestAlbConfig(), ing)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, rules, 1)
+
+			actions := rules[0].Spec.RuleActions
+			var types []string
+			for _, action := range actions {
+				types = append(types, action.Type)
+				if expect, ok := c.expect[action.Type]; ok {
+					assert.Equal(t, expect, action)
+				}
+			}
+			assert.Equal(t, c.expectTypes, types)
+			if len(types) != 8 {
+				return
+			}
+
+			// the header actions are sorted by the header names to keep the rule stable
+			assert.Equal(t, "X-Env", actions[2].InsertHeaderConfig.Key)
+			assert.Equal(t, util.InsertHeaderValueTypeUserDefined, actions[2].InsertHeaderConfig.ValueType)
+			assert.True(t, actions[2].InsertHeaderConfig.CoverEnabled)
+			assert.Equal(t, "X-Source", actions[3].InsertHeaderConfig.Key)
+			assert.Equal(t, util.InsertHeaderValueTypeSystemDefined, actions[3].InsertHeaderConfig.ValueType)
+			assert.Equal(t, "X-Debug", actions[4].RemoveHeaderConfig.Key)
+			assert.Equal(t, "X-Trace", actions[5].RemoveHeaderConfig.Key)
+
+			// traffic is mirrored to the server group of the mirror service
+			mirror := actions[6].TrafficMirrorConfig
+			assert.Equal(t, util.TrafficMirrorTargetTypeForwardGroupMirror, mirror.TargetType)
+			deps := mirror.MirrorGroupConfig.ServerGroupTuples[0].ServerGroupID.Dependencies()
+			assert.Len(t, deps, 1)
+			sgp := deps[0].(*alb.ServerGroup)
+			assert.Equal(t, "mirror-svc", sgp.Spec.ServiceName)
+			assert.Equal(t, 8080, sgp.Spec.ServicePort)
+		})
+	}
+}
+
+func TestBuildListenerRulesResponseRules(t *testing.T) {
+	responseHeader := `[{"type":"ResponseHeader","responseHeaderConfig":{"key":"X-Env","values":["gray"]}}]`
+	cases := []struct {
+		name              string
+		annotations       map[string]string
+		expectDirections  []string
+		expectConditions  []string
+		expectActionTypes []string
+		expectErr         bool
+	}{
+		{
+			name:             "no response rule",
+			expectDirections: []string{""},
+		},
+		{
+			name: "response rule follows the request rules",
+			annotations: map[string]string{
+				annotations.AlbConditionsPrefix + "svc": responseHeader,
+				annotations.AlbResponseInsertHeader:     `{"X-Served-By":{"value":"alb"}}`,
+				annotations.AlbResponseRemoveHeader:     "Server",
+			},
+			expectDirections:  []string{"", util.RuleDirectionResponse},
+			expectConditions:  []string{util.RuleConditionFieldPath, util.RuleConditionFieldResponseHeader},
+			expectActionTypes: []string{util.RuleActionTypeInsertHeader, util.RuleActionTypeRemoveHeader},
+		},
+		{
+			name: "response rule without response conditions",
+			annotations: map[string]string{
+				annotations.AlbResponseRemoveHeader: "Server",
+			},
+			expectDirections:  []string{"", util.RuleDirectionResponse},
+			expectConditions:  []string{util.RuleConditionFieldPath},
+			expectActionTypes: []string{util.RuleActionTypeRemoveHeader},
+		},
+		{
+			name: "response conditions without response actions",
+			annotations: map[string]string{
+				annotations.AlbConditionsPrefix + "svc": responseHeader,
+			},
+			expectErr: true,
+		},
+		{
+			name: "ssl redirected rules have no response rule",
+			annotations: map[string]string{
+				annotations.AlbSslRedirect:          "true",
+				annotations.AlbResponseRemoveHeader: "Server",
+			},
+			expectDirections: []string{""},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ing := newTestIngress("ing", nil, "/foo")
+			ing.Annotations = c.annotations
+			_, rules, err := buildTestStack(t, newTestAlbConfig(), ing)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			var directions []string
+			for _, r := range rules {
+				directions = append(directions, r.Spec.Direction)
+			}
+			assert.Equal(t, c.expectDirections, directions)
+			last := rules[len(rules)-1]
+			if last.Spec.Direction != util.RuleDirectionResponse {
+				return
+			}
+			// the request conditions are not evaluated on responses
+			var conditionTypes, actionTypes []string
+			for _, cond := range last.Spec.RuleConditions {
+				conditionTypes = append(conditionTypes, cond.Type)
+			}
+			for _, a := range last.Spec.RuleActions {
+				actionTypes = append(actionTypes, a.Type)
+			}
+			assert.Equal(t, c.expectConditions, conditionTypes)
+			assert.Equal(t, c.expectActionTypes, actionTypes)
+			assert.Equal(t, len(rules), last.Spec.Priority)
+			for _, cond := range rules[0].Spec.RuleConditions {
+				assert.NotEqual(t, util.RuleConditionFieldResponseHeader, cond.Type)
+			}
+		})
+	}
+}
+
+func TestComputeIgnoredActionAnnotations(t *testing.T) {
+	newIngress := func(annos map[string]string) *networking.Ingress {
+		return &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ing", Annotations: annos}}
+	}
+	newAlbConfig := func(ports ...int) *v1.AlbConfig {
+		albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Namespace: ALBConfigNamespace, Name: "alb"}}
+		for _, port := range ports {
+			protocol := ProtocolHTTP
+			if port == 443 {
+				protocol = ProtocolHTTPS
+			}
+			albconfig.Spec.Listeners = append(albconfig.Spec.Listeners, &v1.ListenerSpec{Port: intstr.FromInt(port), Protocol: string(protocol)})
+		}
+		return albconfig
+	}
+	listenPorts := `[{"HTTP":80},{"HTTPS":443}]`
+
+	cases := []struct {
+		name      string
+		albconfig *v1.AlbConfig
+		annos     map[string]string
+		expect    map[int32]string
+	}{
+		{
+			name:      "action annotations without ssl-redirect",
+			albconfig: newAlbConfig(80, 443),
+			annos:     map[string]string{annotations.AlbQpsLimit: "100", annotations.ListenPorts: listenPorts},
+		},
+		{
+			name:      "ssl-redirect without action annotations",
+			albconfig: newAlbConfig(80, 443),
+			annos:     map[string]string{annotations.AlbSslRedirect: "true", annotations.ListenPorts: listenPorts},
+		},
+		{
+			name:      "action annotations are ignored by the redirect rules on the http listener",
+			albconfig: newAlbConfig(80, 443),
+			annos: map[string]string{
+				annotations.AlbSslRedirect:     "true",
+				annotations.ListenPorts:        listenPorts,
+				annotations.AlbQpsLimit:        "100",
+				annotations.NginxRewriteTarget: "/$1",
+			},
+			expect: map[int32]string{
+				80: "annotations " + annotations.AlbQpsLimit + ", " + annotations.NginxRewriteTarget +
+					" are ignored on listener 80: the rules redirect to https by ssl-redirect",
+			},
+		},
+		{
+			name:      "listener not in the albconfig",
+			albconfig: newAlbConfig(443),
+			annos: map[string]string{
+				annotations.AlbSslRedirect:  "true",
+				annotations.ListenPorts:     listenPorts,
+				annotations.AlbRemoveHeader: "X-Debug",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ignored := ComputeIgnoredActionAnnotations(c.albconfig, []*networking.Ingress{newIngress(c.annos)})
+			messages := make(map[int32]string)
+			for _, i := range ignored {
+				assert.Equal(t, "ing", i.Ingress.Name)
+				messages[i.Port] = i.Message
+			}
+			if c.expect == nil {
+				assert.Empty(t, ignored)
+				return
+			}
+			assert.Equal(t, c.expect, messages)
+		})
+	}
+}
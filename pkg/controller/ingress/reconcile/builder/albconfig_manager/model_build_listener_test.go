@@ -0,0 +1,416 @@
+package albconfigmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func newTestAlbConfig(defaultActions ...v1.Action) *v1.AlbConfig {
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Namespace: ALBConfigNamespace, Name: "alb"}}
+	deletionProtection := true
+	albconfig.Spec.LoadBalancer = &v1.LoadBalancerSpec{Id: "alb-test", DeletionProtectionEnabled: &deletionProtection}
+	albconfig.Spec.Listeners = []*v1.ListenerSpec{{
+		Port:           intstr.FromInt(80),
+		Protocol:       string(ProtocolHTTP),
+		DefaultActions: defaultActions,
+	}}
+	return albconfig
+}
+
+func newTestIngress(name string, defaultBackend *networking.IngressBackend, paths ...string) *networking.Ingress {
+	pathType := networking.PathTypePrefix
+	ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: name}}
+	ing.Spec.DefaultBackend = defaultBackend
+	rule := networking.IngressRule{IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{}}}
+	for _, p := range paths {
+		rule.HTTP.Paths = append(rule.HTTP.Paths, networking.HTTPIngressPath{
+			Path:     p,
+			PathType: &pathType,
+			Backend:  *serviceBackend("svc", 80),
+		})
+	}
+	if len(paths) != 0 {
+		ing.Spec.Rules = []networking.IngressRule{rule}
+	}
+	return ing
+}
+
+func serviceBackend(name string, port int32) *networking.IngressBackend {
+	return &networking.IngressBackend{Service: &networking.IngressServiceBackend{
+		Name: name,
+		Port: networking.ServiceBackendPort{Number: port},
+	}}
+}
+
+func buildTestStack(t *testing.T, albconfig *v1.AlbConfig, ings ...*networking.Ingress) ([]*alb.Listener, []*alb.ListenerRule, error) {
+	kubeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	b := NewDefaultAlbConfigManagerBuilder(kubeClient, fakecloud.NewFakeCloud(nil), nil, logr.Discard())
+	stack, _, err := b.Build(context.TODO(), albconfig, &Group{
+		ID:      GroupID{Namespace: albconfig.Namespace, Name: albconfig.Name},
+		Members: ings,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var listeners []*alb.Listener
+	var rules []*alb.ListenerRule
+	assert.NoError(t, stack.ListResources(&listeners))
+	assert.NoError(t, stack.ListResources(&rules))
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Spec.Priority < rules[j].Spec.Priority })
+	return listeners, rules, nil
+}
+
+func TestBuildListenerDefaultActions(t *testing.T) {
+	fixedResponse := v1.Action{
+		Type:                util.RuleActionTypeFixedResponse,
+		FixedResponseConfig: &v1.FixedResponseActionConfig{MessageBody: "not found", StatusCode: "404"},
+	}
+	forward := v1.Action{
+		Type: util.RuleActionTypeForward,
+		ForwardConfig: &v1.ForwardActionConfig{TargetGroups: []v1.TargetGroupTuple{
+			{ServiceName: "default-svc", ServicePort: intstr.FromInt(8080)},
+		}},
+	}
+
+	cases := []struct {
+		name           string
+		albconfig      *v1.AlbConfig
+		ings           []*networking.Ingress
+		expectForward  string
+		expectCatchAll string
+		expectErr      bool
+	}{
+		{
+			name:      "no default actions",
+			albconfig: newTestAlbConfig(),
+			ings:      []*networking.Ingress{newTestIngress("ing", nil, "/foo", "/bar")},
+		},
+		{
+			name:           "fixed response is taken by the catch-all rule after the ingress rules",
+			albconfig:      newTestAlbConfig(fixedResponse),
+			ings:           []*networking.Ingress{newTestIngress("ing", nil, "/foo", "/bar")},
+			expectCatchAll: util.RuleActionTypeFixedResponse,
+		},
+		{
+			name:          "forward is taken by the listener",
+			albconfig:     newTestAlbConfig(forward),
+			ings:          []*networking.Ingress{newTestIngress("ing", nil, "/foo", "/bar")},
+			expectForward: "default-svc",
+		},
+		{
+			name:          "defaultBackend of the ingress is taken by the listener",
+			albconfig:     newTestAlbConfig(),
+			ings:          []*networking.Ingress{newTestIngress("ing", serviceBackend("backend-svc", 80), "/foo", "/bar")},
+			expectForward: "backend-svc",
+		},
+		{
+			name:          "default actions of the albconfig take precedence over the defaultBackend",
+			albconfig:     newTestAlbConfig(forward),
+			ings:          []*networking.Ingress{newTestIngress("ing", serviceBackend("backend-svc", 80), "/foo", "/bar")},
+			expectForward: "default-svc",
+		},
+		{
+			name:      "only one default action is supported",
+			albconfig: newTestAlbConfig(fixedResponse, forward),
+			expectErr: true,
+		},
+		{
+			name:      "missing fixed response config",
+			albconfig: newTestAlbConfig(v1.Action{Type: util.RuleActionTypeFixedResponse}),
+			expectErr: true,
+		},
+		{
+			name: "forward to a named port",
+			albconfig: newTestAlbConfig(v1.Action{
+				Type: util.RuleActionTypeForward,
+				ForwardConfig: &v1.ForwardActionConfig{TargetGroups: []v1.TargetGroupTuple{
+					{ServiceName: "default-svc", ServicePort: intstr.FromString("http")},
+				}},
+			}),
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			listeners, rules, err := buildTestStack(t, c.albconfig, c.ings...)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, listeners, 1)
+
+			defaultActions := listeners[0].Spec.DefaultActions
+			assert.Len(t, defaultActions, 1)
+			assert.Equal(t, util.RuleActionTypeForward, defaultActions[0].Type)
+			// the server group of the default action is referenced by the token of its id
+			deps := defaultActions[0].ForwardConfig.ServerGroups[0].ServerGroupID.Dependencies()
+			assert.Len(t, deps, 1)
+			serviceName := deps[0].(*alb.ServerGroup).Spec.ServiceName
+			if c.expectForward != "" {
+				assert.Equal(t, c.expectForward, serviceName)
+			} else {
+				assert.Equal(t, fakeDefaultServiceName, serviceName)
+			}
+
+			// the rules of the ingresses keep their priorities ahead of the catch-all rule
+			var paths [][]string
+			for _, r := range rules {
+				paths = append(paths, r.Spec.RuleConditions[0].PathConfig.Values)
+			}
+			if c.expectCatchAll == "" {
+				assert.Len(t, rules, 2)
+				assert.Equal(t, [][]string{{"/foo", "/foo/*"}, {"/bar", "/bar/*"}}, paths)
+				return
+			}
+			assert.Len(t, rules, 3)
+			assert.Equal(t, [][]string{{"/foo", "/foo/*"}, {"/bar", "/bar/*"}, {ListenerDefaultRulePath}}, paths)
+			assert.Equal(t, []int{1, 2}, []int{rules[0].Spec.Priority, rules[1].Spec.Priority})
+			catchAll := rules[2]
+			assert.Equal(t, ListenerDefaultRulePriority, catchAll.Spec.Priority)
+			assert.Equal(t, fmt.Sprintf("%v-80-default", ListenerRuleNamePrefix), catchAll.Spec.RuleName)
+			assert.Equal(t, c.expectCatchAll, catchAll.Spec.RuleActions[0].Type)
+		})
+	}
+}
+
+func TestBuildListenerRulesReserveDefaultRulePriority(t *testing.T) {
+	var paths []string
+	for i := 1; i < ListenerDefaultRulePriority; i++ {
+		paths = append(paths, fmt.Sprintf("/%d", i))
+	}
+	_, rules, err := buildTestStack(t, newTestAlbConfig(), newTestIngress("ing", nil, paths...))
+	assert.NoError(t, err)
+	assert.Equal(t, ListenerDefaultRulePriority-1, rules[len(rules)-1].Spec.Priority)
+
+	// the rule would take the priority of the catch-all rule
+	_, _, err = buildTestStack(t, newTestAlbConfig(), newTestIngress("ing", nil, append(paths, "/last")...))
+	assert.Error(t, err)
+}
+
+func TestComputeListenerDefaultBackends(t *testing.T) {
+	forward := v1.Action{
+		Type: util.RuleActionTypeForward,
+		ForwardConfig: &v1.ForwardActionConfig{TargetGroups: []v1.TargetGroupTuple{
+			{ServiceName: "default-svc", ServicePort: intstr.FromInt(8080)},
+		}},
+	}
+	resourceBackend := &networking.IngressBackend{Resource: &corev1.TypedLocalObjectReference{Kind: "Bucket", Name: "b"}}
+
+	cases := []struct {
+		name            string
+		albconfig       *v1.AlbConfig
+		ings            []*networking.Ingress
+		expectIngress   string
+		expectConflicts []string
+	}{
+		{
+			name:          "first ingress wins",
+			albconfig:     newTestAlbConfig(),
+			ings:          []*networking.Ingress{newTestIngress("a", serviceBackend("a", 80)), newTestIngress("b", serviceBackend("b", 80))},
+			expectIngress: "a",
+			expectConflicts: []string{
+				"defaultBackend is ignored on listener 80: the defaultBackend of ingress default/a takes precedence by the group order",
+			},
+		},
+		{
+			name:          "same defaultBackend is not a conflict",
+			albconfig:     newTestAlbConfig(),
+			ings:          []*networking.Ingress{newTestIngress("a", serviceBackend("a", 80)), newTestIngress("b", serviceBackend("a", 80))},
+			expectIngress: "a",
+		},
+		{
+			name:            "default actions of the albconfig take precedence",
+			albconfig:       newTestAlbConfig(forward),
+			ings:            []*networking.Ingress{newTestIngress("a", serviceBackend("a", 80))},
+			expectConflicts: []string{"defaultBackend is ignored on listener 80: the default actions are set by albconfig kube-system/alb"},
+		},
+		{
+			name:            "resource backend is not supported",
+			albconfig:       newTestAlbConfig(),
+			ings:            []*networking.Ingress{newTestIngress("a", resourceBackend)},
+			expectConflicts: []string{"defaultBackend is ignored on listener 80: only service backends are supported"},
+		},
+		{
+			name:      "ingress without defaultBackend",
+			albconfig: newTestAlbConfig(),
+			ings:      []*networking.Ingress{newTestIngress("a", nil, "/foo")},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defaultBackends, conflicts := ComputeListenerDefaultBackends(c.albconfig, c.ings)
+			if c.expectIngress == "" {
+				assert.Empty(t, defaultBackends)
+			} else {
+				assert.Len(t, defaultBackends, 1)
+				assert.Equal(t, c.expectIngress, defaultBackends[80].Name)
+			}
+			var messages []string
+			for _, conflict := range conflicts {
+				assert.Equal(t, int32(80), conflict.Port)
+				messages = append(messages, conflict.Message)
+			}
+			assert.Equal(t, c.expectConflicts, messages)
+		})
+	}
+}
+
+func TestComputeListenerSpecs(t *testing.T) {
+	fixedResponse := func(body string) []v1.Action {
+		return []v1.Action{{
+			Type:                util.RuleActionTypeFixedResponse,
+			FixedResponseConfig: &v1.FixedResponseActionConfig{MessageBody: body, StatusCode: "404"},
+		}}
+	}
+	newLs := func(port int, protocol Protocol, defaultActions []v1.Action) *v1.ListenerSpec {
+		return &v1.ListenerSpec{Port: intstr.FromInt(port), Protocol: string(protocol), DefaultActions: defaultActions}
+	}
+	explicitUpgrade := newLs(4443, ProtocolHTTPS, nil)
+	explicitUpgrade.QuicConfig.QuicListenerId = "lsn-quic"
+
+	cases := []struct {
+		name                 string
+		listeners            []*v1.ListenerSpec
+		expectUpgrades       map[int32]int32
+		expectDefaultActions map[int]string
+	}{
+		{
+			name:           "no quic listener",
+			listeners:      []*v1.ListenerSpec{newLs(80, ProtocolHTTP, nil), newLs(443, ProtocolHTTPS, nil)},
+			expectUpgrades: map[int32]int32{},
+		},
+		{
+			name: "https listeners upgrade to the quic listener which takes the default actions of the first one",
+			listeners: []*v1.ListenerSpec{
+				newLs(80, ProtocolHTTP, fixedResponse("http")),
+				newLs(443, ProtocolHTTPS, fixedResponse("https")),
+				newLs(8443, ProtocolHTTPS, fixedResponse("https-8443")),
+				newLs(443, ProtocolQUIC, nil),
+			},
+			expectUpgrades:       map[int32]int32{443: 443, 8443: 443},
+			expectDefaultActions: map[int]string{80: "http", 443: "https", 8443: "https-8443"},
+		},
+		{
+			name: "quic listener keeps its own default actions",
+			listeners: []*v1.ListenerSpec{
+				newLs(443, ProtocolHTTPS, fixedResponse("https")),
+				newLs(8443, ProtocolQUIC, fixedResponse("quic")),
+			},
+			expectUpgrades:       map[int32]int32{443: 8443},
+			expectDefaultActions: map[int]string{443: "https", 8443: "quic"},
+		},
+		{
+			name:           "https listener with a quicListenerId is not upgraded",
+			listeners:      []*v1.ListenerSpec{explicitUpgrade, newLs(8443, ProtocolQUIC, nil)},
+			expectUpgrades: map[int32]int32{},
+		},
+		{
+			name: "more than one quic listener",
+			listeners: []*v1.ListenerSpec{
+				newLs(443, ProtocolHTTPS, nil),
+				newLs(8443, ProtocolQUIC, nil),
+				newLs(9443, ProtocolQUIC, nil),
+			},
+			expectUpgrades: map[int32]int32{},
+		},
+		{
+			name:           "nil listener is skipped",
+			listeners:      []*v1.ListenerSpec{nil, newLs(80, ProtocolHTTP, nil)},
+			expectUpgrades: map[int32]int32{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			albconfig := &v1.AlbConfig{}
+			albconfig.Spec.Listeners = c.listeners
+			lsSpecs, upgrades := ComputeListenerSpecs(albconfig)
+			assert.Equal(t, c.expectUpgrades, upgrades)
+
+			var expectLen int
+			for _, ls := range c.listeners {
+				if ls != nil {
+					expectLen++
+				}
+			}
+			assert.Len(t, lsSpecs, expectLen)
+			for _, ls := range lsSpecs {
+				body, ok := c.expectDefaultActions[ls.Port.IntValue()]
+				if !ok {
+					continue
+				}
+				assert.Len(t, ls.DefaultActions, 1)
+				assert.Equal(t, body, ls.DefaultActions[0].FixedResponseConfig.MessageBody)
+			}
+		})
+	}
+	// the albconfig is not modified
+	albconfig := &v1.AlbConfig{}
+	albconfig.Spec.Listeners = []*v1.ListenerSpec{newLs(443, ProtocolHTTPS, fixedResponse("https")), newLs(8443, ProtocolQUIC, nil)}
+	ComputeListenerSpecs(albconfig)
+	assert.Empty(t, albconfig.Spec.Listeners[1].DefaultActions)
+}
+
+func TestBuildQuicListeners(t *testing.T) {
+	certs := []v1.Certificate{{IsDefault: true, CertificateId: "cert-1"}}
+	newAlbConfig := func(quicPort int) *v1.AlbConfig {
+		albconfig := newTestAlbConfig()
+		albconfig.Spec.Listeners = []*v1.ListenerSpec{
+			{Port: intstr.FromInt(443), Protocol: string(ProtocolHTTPS), Certificates: certs},
+			{Port: intstr.FromInt(quicPort), Protocol: string(ProtocolQUIC), Certificates: certs},
+		}
+		return albconfig
+	}
+	ing := newTestIngress("ing", nil, "/foo")
+	ing.Annotations = map[string]string{
+		annotations.ListenPorts:    `[{"HTTPS":443}]`,
+		annotations.AlbSslRedirect: "true",
+	}
+
+	listeners, rules, err := buildTestStack(t, newAlbConfig(8443), ing)
+	assert.NoError(t, err)
+	assert.Len(t, listeners, 2)
+	lss := make(map[string]*alb.Listener)
+	for _, ls := range listeners {
+		lss[ls.Spec.ListenerProtocol] = ls
+	}
+	quicLs, httpsLs := lss[util.ListenerProtocolQUIC], lss[util.ListenerProtocolHTTPS]
+	assert.Equal(t, 8443, quicLs.Spec.ListenerPort)
+	assert.Equal(t, []alb.Certificate{{IsDefault: true, CertificateId: "cert-1"}}, quicLs.Spec.Certificates)
+	assert.Empty(t, quicLs.Spec.SecurityPolicyId)
+	assert.Nil(t, quicLs.Spec.QuicListenerID)
+	// the https listener upgrades to the quic listener, which is built first
+	assert.True(t, httpsLs.Spec.QuicConfig.QuicUpgradeEnabled)
+	assert.Equal(t, []core.Resource{quicLs}, httpsLs.Spec.QuicListenerID.Dependencies())
+
+	// the rules of the https listener are mirrored onto the quic listener without ssl-redirect
+	rulePorts := make(map[int]string)
+	for _, rule := range rules {
+		ls := rule.Spec.ListenerID.Dependencies()[0].(*alb.Listener)
+		assert.Len(t, rule.Spec.RuleActions, 1)
+		rulePorts[ls.Spec.ListenerPort] = rule.Spec.RuleActions[0].Type
+	}
+	assert.Equal(t, map[int]string{443: util.RuleActionTypeForward, 8443: util.RuleActionTypeForward}, rulePorts)
+
+	// the quic listener can not share the port with another listener
+	_, _, err = buildTestStack(t, newAlbConfig(443), ing)
+	assert.EqualError(t, err, "quic listener port 443 is used by another listener")
+}
@@ -6,9 +6,11 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
@@ -47,7 +49,7 @@ func (t *defaultModelBuildTask) buildServerGroupName(ing *networking.Ingress, sv
 	return fmt.Sprintf("%s-%s-%s", svc.Namespace, svc.Name, fmt.Sprintf("%v", port))
 }
 
-func (t *defaultModelBuildTask) buildServerGroupSpec(_ context.Context,
+func (t *defaultModelBuildTask) buildServerGroupSpec(ctx context.Context,
 	ing *networking.Ingress, svc *corev1.Service, port int) (alb.ServerGroupSpec, error) {
 
 	// preCheck tag value
@@ -84,43 +86,162 @@ func (t *defaultModelBuildTask) buildServerGroupSpec(_ context.Context,
 		ServicePort: port,
 	}
 
+	backendSvc, err := t.loadBackendService(ctx, svc)
+	if err != nil {
+		return alb.ServerGroupSpec{}, err
+	}
+	sgpAnnotations := buildServerGroupAnnotations(ing, backendSvc)
+
 	var sgpSpec alb.ServerGroupSpec
 	sgpSpec.ServerGroupNamedKey = sgpNameKey
 	sgpSpec.Tags = tags
-	sgpSpec.HealthCheckConfig = buildServerGroupHealthCheckConfig(ing)
+	sgpSpec.HealthCheckConfig, err = buildServerGroupHealthCheckConfig(sgpAnnotations)
+	if err != nil {
+		return alb.ServerGroupSpec{}, err
+	}
 	sgpSpec.ServerGroupName = t.buildServerGroupName(ing, svc, port)
-	sgpSpec.Scheduler = t.defaultServerGroupScheduler
-	sgpSpec.Protocol = t.defaultServerGroupProtocol
-	sgpSpec.StickySessionConfig = buildServerGroupStickySessionConfig(ing)
+	sgpSpec.Scheduler, sgpSpec.UchConfig, err = t.buildServerGroupScheduler(sgpAnnotations)
+	if err != nil {
+		return alb.ServerGroupSpec{}, err
+	}
+	sgpSpec.Protocol, err = t.buildServerGroupProtocol(sgpAnnotations)
+	if err != nil {
+		return alb.ServerGroupSpec{}, err
+	}
+	sgpSpec.StickySessionConfig, err = buildServerGroupStickySessionConfig(sgpAnnotations)
+	if err != nil {
+		return alb.ServerGroupSpec{}, err
+	}
+	sgpSpec.ConnectionDrainConfig, err = buildServerGroupConnectionDrainConfig(sgpAnnotations)
+	if err != nil {
+		return alb.ServerGroupSpec{}, err
+	}
+	sgpSpec.SlowStartConfig, err = buildServerGroupSlowStartConfig(sgpAnnotations)
+	if err != nil {
+		return alb.ServerGroupSpec{}, err
+	}
 	sgpSpec.ServerGroupType = t.defaultServerGroupType
 	sgpSpec.VpcId = t.vpcID
 	return sgpSpec, nil
 }
 
-func buildServerGroupHealthCheckConfig(ing *networking.Ingress) alb.HealthCheckConfig {
+// loadBackendService returns the Service with its annotations, the services passed to buildServerGroup
+// only carry the namespace and name. Missing services are returned as is.
+func (t *defaultModelBuildTask) loadBackendService(ctx context.Context, svc *corev1.Service) (*corev1.Service, error) {
+	svcKey := util.NamespacedName(svc)
+	if backendSvc, ok := t.backendServices[svcKey]; ok {
+		return backendSvc, nil
+	}
+	backendSvc := &corev1.Service{}
+	if err := t.kubeClient.Get(ctx, svcKey, backendSvc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		backendSvc = svc
+	}
+	t.backendServices[svcKey] = backendSvc
+	return backendSvc, nil
+}
+
+// buildServerGroupAnnotations returns the annotations of the Ingress, overridden by the
+// alb annotations of the Service, so that a Service can tune its own server groups.
+func buildServerGroupAnnotations(ing *networking.Ingress, svc *corev1.Service) map[string]string {
+	sgpAnnotations := make(map[string]string, len(ing.Annotations))
+	for k, v := range ing.Annotations {
+		sgpAnnotations[k] = v
+	}
+	for k, v := range svc.Annotations {
+		if strings.HasPrefix(k, annotations.AnnotationLoadBalancerPrefix) {
+			sgpAnnotations[k] = v
+		}
+	}
+	return sgpAnnotations
+}
+
+func (t *defaultModelBuildTask) buildServerGroupScheduler(sgpAnnotations map[string]string) (string, alb.UchConfig, error) {
+	v, ok := sgpAnnotations[annotations.BackendScheduler]
+	if !ok {
+		return t.defaultServerGroupScheduler, alb.UchConfig{}, nil
+	}
+	var scheduler string
+	switch strings.ToLower(v) {
+	case "wrr":
+		scheduler = util.ServerGroupSchedulerWrr
+	case "wlc":
+		scheduler = util.ServerGroupSchedulerWlc
+	case "sch":
+		scheduler = util.ServerGroupSchedulerSch
+	case "uch":
+		uchValue := sgpAnnotations[annotations.BackendSchedulerUch]
+		if uchValue == "" {
+			return "", alb.UchConfig{}, fmt.Errorf("annotation %s is required by the uch scheduler", annotations.BackendSchedulerUch)
+		}
+		return util.ServerGroupSchedulerUch, alb.UchConfig{
+			Type:  util.ServerGroupUchConfigTypeQueryString,
+			Value: uchValue,
+		}, nil
+	default:
+		return "", alb.UchConfig{}, fmt.Errorf("invalid annotation %s: %s, expected wrr, wlc, sch or uch", annotations.BackendScheduler, v)
+	}
+	return scheduler, alb.UchConfig{}, nil
+}
+
+func (t *defaultModelBuildTask) buildServerGroupProtocol(sgpAnnotations map[string]string) (string, error) {
+	v, ok := sgpAnnotations[annotations.BackendProtocol]
+	if !ok {
+		return t.defaultServerGroupProtocol, nil
+	}
+	switch strings.ToLower(v) {
+	case "http":
+		return util.ServerGroupProtocolHTTP, nil
+	case "https":
+		return util.ServerGroupProtocolHTTPS, nil
+	case "grpc":
+		return util.ServerGroupProtocolGRPC, nil
+	}
+	return "", fmt.Errorf("invalid annotation %s: %s, expected http, https or grpc", annotations.BackendProtocol, v)
+}
+
+func buildServerGroupHealthCheckConfig(sgpAnnotations map[string]string) (alb.HealthCheckConfig, error) {
 	healthCheckEnabled := util.DefaultServerGroupHealthCheckEnabled
-	if v, ok := ing.Annotations[annotations.HealthCheckEnabled]; ok && v == "true" {
+	if v, ok := sgpAnnotations[annotations.HealthCheckEnabled]; ok && v == "true" {
 		healthCheckEnabled = true
 	}
 
 	healthcheckPath := util.DefaultServerGroupHealthCheckPath
-	if v, ok := ing.Annotations[annotations.HealthCheckPath]; ok {
+	if v, ok := sgpAnnotations[annotations.HealthCheckPath]; ok {
 		healthcheckPath = v
 	}
 	healthcheckMethod := util.DefaultServerGroupHealthCheckMethod
-	if v, ok := ing.Annotations[annotations.HealthCheckMethod]; ok {
+	if v, ok := sgpAnnotations[annotations.HealthCheckMethod]; ok {
 		healthcheckMethod = v
 	}
 	healthcheckProtocol := util.DefaultServerGroupHealthCheckProtocol
-	if v, ok := ing.Annotations[annotations.HealthCheckProtocol]; ok {
+	if v, ok := sgpAnnotations[annotations.HealthCheckProtocol]; ok {
 		healthcheckProtocol = v
 	}
 	healthcheckCode := util.DefaultServerGroupHealthCheckHTTPCodes
-	if v, ok := ing.Annotations[annotations.HealthCheckHTTPCode]; ok {
+	if v, ok := sgpAnnotations[annotations.HealthCheckHTTPCode]; ok {
 		healthcheckCode = v
 	}
+	healthcheckHost := util.DefaultServerGroupHealthCheckHost
+	if v, ok := sgpAnnotations[annotations.HealthCheckHost]; ok {
+		healthcheckHost = v
+	}
+	healthcheckHttpVersion := util.DefaultServerGroupHealthCheckHttpVersion
+	if v, ok := sgpAnnotations[annotations.HealthCheckVersion]; ok {
+		healthcheckHttpVersion = v
+	}
+	healthcheckConnectPort := util.DefaultServerGroupHealthCheckConnectPort
+	if v, ok := sgpAnnotations[annotations.HealthCheckPort]; ok {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return alb.HealthCheckConfig{}, fmt.Errorf("invalid annotation %s: %s", annotations.HealthCheckPort, v)
+		}
+		healthcheckConnectPort = val
+	}
 	healthcheckTimeout := util.DefaultServerGroupHealthCheckTimeout
-	if v, ok := ing.Annotations[annotations.HealthCheckTimeout]; ok {
+	if v, ok := sgpAnnotations[annotations.HealthCheckTimeout]; ok {
 		if val, err := strconv.Atoi(v); err != nil {
 			klog.Error(err.Error())
 		} else {
@@ -128,7 +249,7 @@ func buildServerGroupHealthCheckConfig(ing *networking.Ingress) alb.HealthCheckC
 		}
 	}
 	healthCheckInterval := util.DefaultServerGroupHealthCheckInterval
-	if v, ok := ing.Annotations[annotations.HealthCheckInterval]; ok {
+	if v, ok := sgpAnnotations[annotations.HealthCheckInterval]; ok {
 		if val, err := strconv.Atoi(v); err != nil {
 			klog.Error(err.Error())
 		} else {
@@ -136,7 +257,7 @@ func buildServerGroupHealthCheckConfig(ing *networking.Ingress) alb.HealthCheckC
 		}
 	}
 	healthyThreshold := util.DefaultServerGroupHealthyThreshold
-	if v, ok := ing.Annotations[annotations.HealthThreshold]; ok {
+	if v, ok := sgpAnnotations[annotations.HealthThreshold]; ok {
 		if val, err := strconv.Atoi(v); err != nil {
 			klog.Error(err.Error())
 		} else {
@@ -144,7 +265,7 @@ func buildServerGroupHealthCheckConfig(ing *networking.Ingress) alb.HealthCheckC
 		}
 	}
 	unhealthyThreshold := util.DefaultServerGroupUnhealthyThreshold
-	if v, ok := ing.Annotations[annotations.UnHealthThreshold]; ok {
+	if v, ok := sgpAnnotations[annotations.UnHealthThreshold]; ok {
 		if val, err := strconv.Atoi(v); err != nil {
 			klog.Error(err.Error())
 		} else {
@@ -152,10 +273,10 @@ func buildServerGroupHealthCheckConfig(ing *networking.Ingress) alb.HealthCheckC
 		}
 	}
 	return alb.HealthCheckConfig{
-		HealthCheckConnectPort:         util.DefaultServerGroupHealthCheckConnectPort,
+		HealthCheckConnectPort:         healthcheckConnectPort,
 		HealthCheckEnabled:             healthCheckEnabled,
-		HealthCheckHost:                util.DefaultServerGroupHealthCheckHost,
-		HealthCheckHttpVersion:         util.DefaultServerGroupHealthCheckHttpVersion,
+		HealthCheckHost:                healthcheckHost,
+		HealthCheckHttpVersion:         healthcheckHttpVersion,
 		HealthCheckInterval:            healthCheckInterval,
 		HealthCheckMethod:              healthcheckMethod,
 		HealthCheckPath:                healthcheckPath,
@@ -170,14 +291,98 @@ func buildServerGroupHealthCheckConfig(ing *networking.Ingress) alb.HealthCheckC
 		HealthCheckCodes: []string{
 			util.DefaultServerGroupHealthCheckCodes,
 		},
-	}
+	}, nil
 }
 
-func buildServerGroupStickySessionConfig(ing *networking.Ingress) alb.StickySessionConfig {
-	return alb.StickySessionConfig{
+func buildServerGroupStickySessionConfig(sgpAnnotations map[string]string) (alb.StickySessionConfig, error) {
+	conf := alb.StickySessionConfig{
 		Cookie:               "",
 		CookieTimeout:        util.DefaultServerGroupStickySessionCookieTimeout,
 		StickySessionEnabled: util.DefaultServerGroupStickySessionEnabled,
 		StickySessionType:    util.DefaultServerGroupStickySessionType,
 	}
+	var err error
+	if conf.StickySessionEnabled, err = parseBoolAnnotation(sgpAnnotations, annotations.StickySession, conf.StickySessionEnabled); err != nil {
+		return alb.StickySessionConfig{}, err
+	}
+	if !conf.StickySessionEnabled {
+		return conf, nil
+	}
+
+	if v, ok := sgpAnnotations[annotations.StickySessionType]; ok {
+		switch strings.ToLower(v) {
+		case "insert":
+			conf.StickySessionType = util.ServerGroupStickySessionTypeInsert
+		case "server":
+			conf.StickySessionType = util.ServerGroupStickySessionTypeServer
+		default:
+			return alb.StickySessionConfig{}, fmt.Errorf("invalid annotation %s: %s, expected Insert or Server", annotations.StickySessionType, v)
+		}
+	}
+	if conf.StickySessionType == util.ServerGroupStickySessionTypeServer {
+		conf.Cookie = sgpAnnotations[annotations.Cookie]
+		return conf, nil
+	}
+	if conf.CookieTimeout, err = parseIntAnnotation(sgpAnnotations, annotations.CookieTimeout, conf.CookieTimeout); err != nil {
+		return alb.StickySessionConfig{}, err
+	}
+	return conf, nil
+}
+
+func buildServerGroupConnectionDrainConfig(sgpAnnotations map[string]string) (alb.ConnectionDrainConfig, error) {
+	var (
+		conf alb.ConnectionDrainConfig
+		err  error
+	)
+	if conf.ConnectionDrainEnabled, err = parseBoolAnnotation(sgpAnnotations, annotations.ConnectionDrainEnabled, false); err != nil {
+		return alb.ConnectionDrainConfig{}, err
+	}
+	if !conf.ConnectionDrainEnabled {
+		return conf, nil
+	}
+	if conf.ConnectionDrainTimeout, err = parseIntAnnotation(sgpAnnotations, annotations.ConnectionDrainTimeout, util.DefaultServerGroupConnectionDrainTimeout); err != nil {
+		return alb.ConnectionDrainConfig{}, err
+	}
+	return conf, nil
+}
+
+func buildServerGroupSlowStartConfig(sgpAnnotations map[string]string) (alb.SlowStartConfig, error) {
+	var (
+		conf alb.SlowStartConfig
+		err  error
+	)
+	if conf.SlowStartEnabled, err = parseBoolAnnotation(sgpAnnotations, annotations.SlowStartEnabled, false); err != nil {
+		return alb.SlowStartConfig{}, err
+	}
+	if !conf.SlowStartEnabled {
+		return conf, nil
+	}
+	if conf.SlowStartDuration, err = parseIntAnnotation(sgpAnnotations, annotations.SlowStartDuration, util.DefaultServerGroupSlowStartDuration); err != nil {
+		return alb.SlowStartConfig{}, err
+	}
+	return conf, nil
+}
+
+func parseBoolAnnotation(sgpAnnotations map[string]string, key string, defaultValue bool) (bool, error) {
+	v, ok := sgpAnnotations[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	val, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid annotation %s: %s", key, v)
+	}
+	return val, nil
+}
+
+func parseIntAnnotation(sgpAnnotations map[string]string, key string, defaultValue int) (int, error) {
+	v, ok := sgpAnnotations[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	val, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid annotation %s: %s", key, v)
+	}
+	return val, nil
 }
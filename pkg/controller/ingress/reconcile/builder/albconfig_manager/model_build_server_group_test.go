@@ -0,0 +1,214 @@
+package albconfigmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func TestBuildServerGroupSpec(t *testing.T) {
+	cases := []struct {
+		name        string
+		ingAnnos    map[string]string
+		svcAnnos    map[string]string
+		noService   bool
+		expectErr   bool
+		checkResult func(t *testing.T, spec alb.ServerGroupSpec)
+	}{
+		{
+			name: "defaults",
+			checkResult: func(t *testing.T, spec alb.ServerGroupSpec) {
+				assert.Equal(t, util.DefaultServerGroupScheduler, spec.Scheduler)
+				assert.Equal(t, util.DefaultServerGroupProtocol, spec.Protocol)
+				assert.Equal(t, alb.UchConfig{}, spec.UchConfig)
+				assert.False(t, spec.StickySessionConfig.StickySessionEnabled)
+				assert.Equal(t, alb.ConnectionDrainConfig{}, spec.ConnectionDrainConfig)
+				assert.Equal(t, alb.SlowStartConfig{}, spec.SlowStartConfig)
+				assert.Equal(t, util.DefaultServerGroupHealthCheckConnectPort, spec.HealthCheckConfig.HealthCheckConnectPort)
+				assert.Equal(t, "default-vpc", spec.VpcId)
+				assert.Equal(t, "svc", spec.ServiceName)
+				assert.Equal(t, 80, spec.ServicePort)
+			},
+		},
+		{
+			name: "uch scheduler and grpc protocol",
+			ingAnnos: map[string]string{
+				annotations.BackendScheduler:    "uch",
+				annotations.BackendSchedulerUch: "uid",
+				annotations.BackendProtocol:     "grpc",
+			},
+			checkResult: func(t *testing.T, spec alb.ServerGroupSpec) {
+				assert.Equal(t, util.ServerGroupSchedulerUch, spec.Scheduler)
+				assert.Equal(t, alb.UchConfig{Type: util.ServerGroupUchConfigTypeQueryString, Value: "uid"}, spec.UchConfig)
+				assert.Equal(t, util.ServerGroupProtocolGRPC, spec.Protocol)
+			},
+		},
+		{
+			name:      "uch scheduler without value",
+			ingAnnos:  map[string]string{annotations.BackendScheduler: "uch"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid scheduler",
+			ingAnnos:  map[string]string{annotations.BackendScheduler: "rr"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid protocol",
+			ingAnnos:  map[string]string{annotations.BackendProtocol: "tcp"},
+			expectErr: true,
+		},
+		{
+			name: "annotations of the service override the ones of the ingress",
+			ingAnnos: map[string]string{
+				annotations.BackendScheduler: "wlc",
+				annotations.BackendProtocol:  "http",
+			},
+			svcAnnos: map[string]string{
+				annotations.BackendProtocol: "https",
+				// only the alb annotations of the service are taken
+				"example.com/backend-protocol": "grpc",
+			},
+			checkResult: func(t *testing.T, spec alb.ServerGroupSpec) {
+				assert.Equal(t, util.ServerGroupSchedulerWlc, spec.Scheduler)
+				assert.Equal(t, util.ServerGroupProtocolHTTPS, spec.Protocol)
+			},
+		},
+		{
+			name:      "missing service takes the annotations of the ingress",
+			ingAnnos:  map[string]string{annotations.BackendProtocol: "https"},
+			noService: true,
+			checkResult: func(t *testing.T, spec alb.ServerGroupSpec) {
+				assert.Equal(t, util.ServerGroupProtocolHTTPS, spec.Protocol)
+			},
+		},
+		{
+			name: "insert sticky session",
+			ingAnnos: map[string]string{
+				annotations.StickySession:     "true",
+				annotations.StickySessionType: "insert",
+				annotations.CookieTimeout:     "600",
+				annotations.Cookie:            "ignored",
+			},
+			checkResult: func(t *testing.T, spec alb.ServerGroupSpec) {
+				assert.Equal(t, alb.StickySessionConfig{
+					StickySessionEnabled: true,
+					StickySessionType:    util.ServerGroupStickySessionTypeInsert,
+					CookieTimeout:        600,
+				}, spec.StickySessionConfig)
+			},
+		},
+		{
+			name: "server sticky session",
+			ingAnnos: map[string]string{
+				annotations.StickySession:     "true",
+				annotations.StickySessionType: "Server",
+				annotations.Cookie:            "session",
+			},
+			checkResult: func(t *testing.T, spec alb.ServerGroupSpec) {
+				assert.True(t, spec.StickySessionConfig.StickySessionEnabled)
+				assert.Equal(t, util.ServerGroupStickySessionTypeServer, spec.StickySessionConfig.StickySessionType)
+				assert.Equal(t, "session", spec.StickySessionConfig.Cookie)
+			},
+		},
+		{
+			name: "sticky session type is ignored when disabled",
+			ingAnnos: map[string]string{
+				annotations.StickySession:     "false",
+				annotations.StickySessionType: "unknown",
+			},
+			checkResult: func(t *testing.T, spec alb.ServerGroupSpec) {
+				assert.False(t, spec.StickySessionConfig.StickySessionEnabled)
+			},
+		},
+		{
+			name: "invalid sticky session type",
+			ingAnnos: map[string]string{
+				annotations.StickySession:     "true",
+				annotations.StickySessionType: "unknown",
+			},
+			expectErr: true,
+		},
+		{
+			name:      "invalid sticky session",
+			ingAnnos:  map[string]string{annotations.StickySession: "yes please"},
+			expectErr: true,
+		},
+		{
+			name: "connection drain and slow start",
+			ingAnnos: map[string]string{
+				annotations.ConnectionDrainEnabled: "true",
+				annotations.ConnectionDrainTimeout: "60",
+				annotations.SlowStartEnabled:       "true",
+			},
+			checkResult: func(t *testing.T, spec alb.ServerGroupSpec) {
+				assert.Equal(t, alb.ConnectionDrainConfig{ConnectionDrainEnabled: true, ConnectionDrainTimeout: 60}, spec.ConnectionDrainConfig)
+				assert.Equal(t, alb.SlowStartConfig{SlowStartEnabled: true, SlowStartDuration: util.DefaultServerGroupSlowStartDuration}, spec.SlowStartConfig)
+			},
+		},
+		{
+			name: "invalid connection drain timeout",
+			ingAnnos: map[string]string{
+				annotations.ConnectionDrainEnabled: "true",
+				annotations.ConnectionDrainTimeout: "1m",
+			},
+			expectErr: true,
+		},
+		{
+			name: "health check host, port and version",
+			ingAnnos: map[string]string{
+				annotations.HealthCheckHost:    "health.example.com",
+				annotations.HealthCheckPort:    "8080",
+				annotations.HealthCheckVersion: "HTTP1.0",
+			},
+			checkResult: func(t *testing.T, spec alb.ServerGroupSpec) {
+				assert.Equal(t, "health.example.com", spec.HealthCheckConfig.HealthCheckHost)
+				assert.Equal(t, 8080, spec.HealthCheckConfig.HealthCheckConnectPort)
+				assert.Equal(t, "HTTP1.0", spec.HealthCheckConfig.HealthCheckHttpVersion)
+			},
+		},
+		{
+			name:      "invalid health check port",
+			ingAnnos:  map[string]string{annotations.HealthCheckPort: "http"},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ing", Annotations: c.ingAnnos}}
+			var objs []client.Object
+			if !c.noService {
+				objs = append(objs, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "svc", Annotations: c.svcAnnos}})
+			}
+			task := &defaultModelBuildTask{
+				kubeClient:                  fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objs...).Build(),
+				backendServices:             make(map[types.NamespacedName]*corev1.Service),
+				vpcID:                       "default-vpc",
+				defaultServerGroupScheduler: util.DefaultServerGroupScheduler,
+				defaultServerGroupProtocol:  util.DefaultServerGroupProtocol,
+				defaultServerGroupType:      util.DefaultServerGroupType,
+			}
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "svc"}}
+
+			spec, err := task.buildServerGroupSpec(context.TODO(), ing, svc, 80)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			c.checkResult(t, spec)
+		})
+	}
+}
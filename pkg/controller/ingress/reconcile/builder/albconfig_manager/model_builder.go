@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 
@@ -32,31 +33,44 @@ type Builder interface {
 var _ Builder = &defaultAlbConfigManagerBuilder{}
 
 type defaultAlbConfigManagerBuilder struct {
-	kubeClient client.Client
-	cloud      prvd.Provider
-	logger     logr.Logger
+	kubeClient        client.Client
+	cloud             prvd.Provider
+	secretCertManager SecretCertManager
+	logger            logr.Logger
 }
 
-func NewDefaultAlbConfigManagerBuilder(kubeClient client.Client, cloud prvd.Provider, logger logr.Logger) *defaultAlbConfigManagerBuilder {
+func NewDefaultAlbConfigManagerBuilder(kubeClient client.Client, cloud prvd.Provider, secretCertManager SecretCertManager, logger logr.Logger) *defaultAlbConfigManagerBuilder {
 	return &defaultAlbConfigManagerBuilder{
-		kubeClient: kubeClient,
-		cloud:      cloud,
-		logger:     logger,
+		kubeClient:        kubeClient,
+		cloud:             cloud,
+		secretCertManager: secretCertManager,
+		logger:            logger,
 	}
 }
 
 func (b defaultAlbConfigManagerBuilder) Build(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *Group) (core.Manager, *alb.AlbLoadBalancer, error) {
-	stack := core.NewDefaultManager(core.StackID(ingGroup.ID))
+	task, err := b.newModelBuildTask(albconfig, ingGroup)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := task.run(ctx); err != nil {
+		return nil, nil, err
+	}
 
+	return task.stack, task.loadBalancer, nil
+}
+
+func (b defaultAlbConfigManagerBuilder) newModelBuildTask(albconfig *v1.AlbConfig, ingGroup *Group) (*defaultModelBuildTask, error) {
 	vpcID, err := b.cloud.VpcID()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	task := &defaultModelBuildTask{
-		stack:     stack,
-		albconfig: albconfig,
-		ingGroup:  ingGroup,
+	return &defaultModelBuildTask{
+		stack:      core.NewDefaultManager(core.StackID(ingGroup.ID)),
+		albconfig:  albconfig,
+		ingGroup:   ingGroup,
+		kubeClient: b.kubeClient,
 
 		clusterID: b.cloud.ClusterID(),
 		vpcID:     vpcID,
@@ -64,9 +78,10 @@ func (b defaultAlbConfigManagerBuilder) Build(ctx context.Context, albconfig *v1
 		sgpByResID:      make(map[string]*alb.ServerGroup),
 		backendServices: make(map[types.NamespacedName]*corev1.Service),
 
-		annotationParser: annotations.NewSuffixAnnotationParser(annotations.DefaultAnnotationsPrefix),
-		certDiscovery:    NewCASCertDiscovery(b.cloud, b.logger),
-		vSwitchResolver:  NewDefaultVSwitchResolver(b.cloud, vpcID, b.logger),
+		annotationParser:  annotations.NewSuffixAnnotationParser(annotations.DefaultAnnotationsPrefix),
+		certDiscovery:     NewCASCertDiscovery(b.cloud, b.logger),
+		secretCertManager: b.secretCertManager,
+		vSwitchResolver:   NewDefaultVSwitchResolver(b.cloud, vpcID, b.logger),
 
 		defaultServerGroupScheduler:     util.DefaultServerGroupScheduler,
 		defaultServerGroupProtocol:      util.DefaultServerGroupProtocol,
@@ -78,12 +93,7 @@ func (b defaultAlbConfigManagerBuilder) Build(ctx context.Context, albconfig *v1
 		defaultListenerGzipEnabled:      util.DefaultListenerGzipEnabled,
 		defaultListenerHttp2Enabled:     util.DefaultListenerHttp2Enabled,
 		defaultListenerSecurityPolicyId: util.DefaultListenerSecurityPolicyId,
-	}
-	if err := task.run(ctx); err != nil {
-		return nil, nil, err
-	}
-
-	return task.stack, task.loadBalancer, nil
+	}, nil
 }
 
 type defaultModelBuildTask struct {
@@ -91,15 +101,17 @@ type defaultModelBuildTask struct {
 	loadBalancer *alb.AlbLoadBalancer
 	albconfig    *v1.AlbConfig
 	ingGroup     *Group
+	kubeClient   client.Client
 
 	clusterID string
 	vpcID     string
 
 	sgpByResID map[string]*alb.ServerGroup
 
-	annotationParser annotations.Parser
-	certDiscovery    CertDiscovery
-	vSwitchResolver  VSwitchResolver
+	annotationParser  annotations.Parser
+	certDiscovery     CertDiscovery
+	secretCertManager SecretCertManager
+	vSwitchResolver   VSwitchResolver
 
 	backendServices map[types.NamespacedName]*corev1.Service
 
@@ -161,14 +173,30 @@ func (t *defaultModelBuildTask) run(ctx context.Context) error {
 		return err
 	}
 
+	lsSpecs, quicUpgrades := ComputeListenerSpecs(t.albconfig)
+	// the QUIC listeners are built first, which are referred by the HTTPS listeners upgrading to them
+	sort.SliceStable(lsSpecs, func(i, j int) bool {
+		return lsSpecs[i].Protocol == string(ProtocolQUIC) && lsSpecs[j].Protocol != string(ProtocolQUIC)
+	})
 	var lss = make(map[int32]*alb.Listener)
-	for _, ls := range t.albconfig.Spec.Listeners {
-		modelLs, err := t.buildListener(ctx, lb.LoadBalancerID(), ls)
+	for _, ls := range lsSpecs {
+		if dup, ok := lss[int32(ls.Port.IntValue())]; ok &&
+			(ls.Protocol == string(ProtocolQUIC) || dup.Spec.ListenerProtocol == string(ProtocolQUIC)) {
+			return fmt.Errorf("quic listener port %d is used by another listener", ls.Port.IntValue())
+		}
+		var quicLsID core.StringToken
+		if quicPort, ok := quicUpgrades[int32(ls.Port.IntValue())]; ok && ls.Protocol == string(ProtocolHTTPS) {
+			quicLsID = lss[quicPort].ListenerID()
+		}
+		modelLs, err := t.buildListener(ctx, lb.LoadBalancerID(), ls, quicLsID)
 		if err != nil {
 			return err
 		}
 		lss[int32(ls.Port.IntValue())] = modelLs
 	}
+	if err := t.buildIngressDefaultBackends(ctx, lss); err != nil {
+		return err
+	}
 
 	ingListByPort := make(map[portProtocol][]networking.Ingress)
 
@@ -177,6 +205,7 @@ func (t *defaultModelBuildTask) run(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		mirrorQuicListenPorts(listenPorts, quicUpgrades)
 		for k, v := range listenPorts {
 			pp := portProtocol{
 				port:     k,
@@ -190,10 +219,16 @@ func (t *defaultModelBuildTask) run(ctx context.Context) error {
 		if !ok {
 			continue
 		}
-		if pp.protocol == ProtocolHTTPS {
+		if pp.protocol == ProtocolHTTPS || pp.protocol == ProtocolQUIC {
 			if len(ls.Spec.Certificates) == 0 {
 				var certIDs []string
 				for _, ing := range ingList {
+					secretCerts, err := t.computeIngressSecretCertIDs(ctx, &ing)
+					if err != nil {
+						klog.Errorf("computeIngressSecretCertIDs error: %s", err.Error())
+						return err
+					}
+					certIDs = append(certIDs, secretCerts...)
 					cert, err := t.computeIngressInferredTLSCertIDs(ctx, &ing)
 					if err != nil {
 						klog.Errorf("computeIngressInferredTLSCertARNs error: %s", err.Error())
@@ -217,17 +252,17 @@ func (t *defaultModelBuildTask) run(ctx context.Context) error {
 					}
 					cs = append(cs, cert)
 				}
-				lss[pp.port].Spec.ListenerProtocol = string(ProtocolHTTPS)
+				lss[pp.port].Spec.ListenerProtocol = string(pp.protocol)
 				lss[pp.port].Spec.Certificates = cs
 			}
 		}
-		if err := t.buildListenerRules(ctx, ls.ListenerID(), pp.port, ingList); err != nil {
+		if err := t.buildListenerRules(ctx, ls.ListenerID(), pp.port, pp.protocol, ingList); err != nil {
 			return err
 		}
 	}
 
 	for _, ls := range lss {
-		if ls.Spec.ListenerProtocol == string(ProtocolHTTPS) {
+		if ls.Spec.ListenerProtocol == string(ProtocolHTTPS) || ls.Spec.ListenerProtocol == string(ProtocolQUIC) {
 			var isDefaultCertExist bool
 			for _, c := range ls.Spec.Certificates {
 				if c.IsDefault {
@@ -236,7 +271,7 @@ func (t *defaultModelBuildTask) run(ctx context.Context) error {
 				}
 			}
 			if !isDefaultCertExist {
-				return fmt.Errorf("https listener: %d must provider one default cert", ls.Spec.ListenerPort)
+				return fmt.Errorf("%s listener: %d must provider one default cert", strings.ToLower(ls.Spec.ListenerProtocol), ls.Spec.ListenerPort)
 			}
 		}
 	}
@@ -254,9 +289,34 @@ func (t *defaultModelBuildTask) computeIngressInferredTLSCertIDs(ctx context.Con
 	for _, t := range ing.Spec.TLS {
 		hosts.Insert(t.Hosts...)
 	}
+	// hosts served by certificates from tls secrets don't need to be discovered in CAS
+	for _, t := range ing.Spec.TLS {
+		if t.SecretName == "" {
+			continue
+		}
+		if len(t.Hosts) == 0 {
+			return nil, nil
+		}
+		hosts.Delete(t.Hosts...)
+	}
+	if hosts.Len() == 0 {
+		return nil, nil
+	}
 	return t.certDiscovery.Discover(ctx, hosts.List())
 }
 
+func (t *defaultModelBuildTask) computeIngressSecretCertIDs(ctx context.Context, ing *networking.Ingress) ([]string, error) {
+	var certIDs []string
+	for _, secretKey := range ComputeIngressTLSSecrets(ing) {
+		certID, err := t.secretCertManager.Ensure(ctx, secretKey)
+		if err != nil {
+			return nil, err
+		}
+		certIDs = append(certIDs, certID)
+	}
+	return certIDs, nil
+}
+
 func ComputeIngressListenPorts(ing *networking.Ingress) (map[int32]Protocol, error) {
 	rawListenPorts := ""
 	portAndProtocols := make(map[int32]Protocol)
@@ -295,8 +355,10 @@ func ComputeIngressListenPorts(ing *networking.Ingress) (map[int32]Protocol, err
 				portAndProtocols[port] = util.ListenerProtocolHTTP
 			case string(ProtocolHTTPS):
 				portAndProtocols[port] = util.ListenerProtocolHTTPS
+			case string(ProtocolQUIC):
+				portAndProtocols[port] = util.ListenerProtocolQUIC
 			default:
-				return nil, errors.Errorf("listen protocol must be within [%v, %v]: %v", ProtocolHTTP, ProtocolHTTPS, protocol)
+				return nil, errors.Errorf("listen protocol must be within [%v, %v, %v]: %v", ProtocolHTTP, ProtocolHTTPS, ProtocolQUIC, protocol)
 			}
 		}
 	}
@@ -308,4 +370,21 @@ type Protocol string
 const (
 	ProtocolHTTP  Protocol = util.ListenerProtocolHTTP
 	ProtocolHTTPS Protocol = util.ListenerProtocolHTTPS
+	ProtocolQUIC  Protocol = util.ListenerProtocolQUIC
 )
+
+// mirrorQuicListenPorts adds the QUIC listeners which the HTTPS listen ports upgrade to, so that
+// the rules on the HTTPS listeners are mirrored onto the QUIC listeners.
+func mirrorQuicListenPorts(listenPorts map[int32]Protocol, quicUpgrades map[int32]int32) {
+	var quicPorts []int32
+	for port, protocol := range listenPorts {
+		if quicPort, ok := quicUpgrades[port]; ok && protocol == ProtocolHTTPS {
+			quicPorts = append(quicPorts, quicPort)
+		}
+	}
+	for _, port := range quicPorts {
+		if _, ok := listenPorts[port]; !ok {
+			listenPorts[port] = ProtocolQUIC
+		}
+	}
+}
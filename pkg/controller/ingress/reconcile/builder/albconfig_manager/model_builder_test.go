@@ -0,0 +1,117 @@
+package albconfigmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+)
+
+func TestComputeIngressListenPorts(t *testing.T) {
+	cases := []struct {
+		name      string
+		annos     map[string]string
+		tlsHosts  []string
+		expect    map[int32]Protocol
+		expectErr bool
+	}{
+		{
+			name:   "http by default",
+			expect: map[int32]Protocol{80: ProtocolHTTP},
+		},
+		{
+			name:     "https for tls hosts",
+			tlsHosts: []string{"example.com"},
+			expect:   map[int32]Protocol{443: ProtocolHTTPS},
+		},
+		{
+			name:     "ssl-redirect adds the http port",
+			annos:    map[string]string{annotations.AlbSslRedirect: "true"},
+			tlsHosts: []string{"example.com"},
+			expect:   map[int32]Protocol{80: ProtocolHTTP, 443: ProtocolHTTPS},
+		},
+		{
+			name:   "listen ports with quic",
+			annos:  map[string]string{annotations.ListenPorts: `[{"HTTP":80},{"HTTPS":443},{"QUIC":8443}]`},
+			expect: map[int32]Protocol{80: ProtocolHTTP, 443: ProtocolHTTPS, 8443: ProtocolQUIC},
+		},
+		{
+			name:      "unknown protocol",
+			annos:     map[string]string{annotations.ListenPorts: `[{"TCP":80}]`},
+			expectErr: true,
+		},
+		{
+			name:      "port out of range",
+			annos:     map[string]string{annotations.ListenPorts: `[{"QUIC":65536}]`},
+			expectErr: true,
+		},
+		{
+			name:      "empty listen ports",
+			annos:     map[string]string{annotations.ListenPorts: `[]`},
+			expectErr: true,
+		},
+		{
+			name:      "invalid listen ports",
+			annos:     map[string]string{annotations.ListenPorts: `{"HTTP":80}`},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ing", Annotations: c.annos}}
+			if len(c.tlsHosts) != 0 {
+				ing.Spec.TLS = []networking.IngressTLS{{Hosts: c.tlsHosts}}
+			}
+			ports, err := ComputeIngressListenPorts(ing)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, ports)
+		})
+	}
+}
+
+func TestMirrorQuicListenPorts(t *testing.T) {
+	cases := []struct {
+		name         string
+		listenPorts  map[int32]Protocol
+		quicUpgrades map[int32]int32
+		expect       map[int32]Protocol
+	}{
+		{
+			name:         "https port is mirrored onto the quic port",
+			listenPorts:  map[int32]Protocol{80: ProtocolHTTP, 443: ProtocolHTTPS},
+			quicUpgrades: map[int32]int32{443: 8443},
+			expect:       map[int32]Protocol{80: ProtocolHTTP, 443: ProtocolHTTPS, 8443: ProtocolQUIC},
+		},
+		{
+			name:         "https port without upgrade",
+			listenPorts:  map[int32]Protocol{443: ProtocolHTTPS},
+			quicUpgrades: map[int32]int32{4443: 8443},
+			expect:       map[int32]Protocol{443: ProtocolHTTPS},
+		},
+		{
+			name:         "http port on an upgraded port",
+			listenPorts:  map[int32]Protocol{443: ProtocolHTTP},
+			quicUpgrades: map[int32]int32{443: 8443},
+			expect:       map[int32]Protocol{443: ProtocolHTTP},
+		},
+		{
+			name:         "listen port taken by the ingress is kept",
+			listenPorts:  map[int32]Protocol{443: ProtocolHTTPS, 8443: ProtocolHTTP},
+			quicUpgrades: map[int32]int32{443: 8443},
+			expect:       map[int32]Protocol{443: ProtocolHTTPS, 8443: ProtocolHTTP},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mirrorQuicListenPorts(c.listenPorts, c.quicUpgrades)
+			assert.Equal(t, c.expect, c.listenPorts)
+		})
+	}
+}
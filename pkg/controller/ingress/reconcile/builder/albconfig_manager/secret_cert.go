@@ -0,0 +1,231 @@
+package albconfigmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+// secretCertNameHashLength is the number of hex characters of the secret data
+// hash kept in the CAS certificate name, the name must stay within 64 characters.
+const secretCertNameHashLength = 16
+
+// SecretCertManager uploads the certificates stored in kubernetes TLS secrets to CAS.
+// Uploaded certificates are named after the cluster id and a hash of the secret data,
+// so an update of the secret results in a new certificate, and the outdated one is
+// removed by GC once it is no longer referenced.
+type SecretCertManager interface {
+	// Ensure uploads the secret to CAS if needed and returns the CAS certificate identifier.
+	Ensure(ctx context.Context, secretKey types.NamespacedName) (string, error)
+	// GC deletes the certificates uploaded by this cluster which are not referenced by any ingress of the cluster.
+	GC(ctx context.Context) error
+}
+
+func NewCASSecretCertManager(kubeClient client.Client, cloud prvd.Provider, logger logr.Logger) *casSecretCertManager {
+	return &casSecretCertManager{
+		kubeClient: kubeClient,
+		cloud:      cloud,
+		logger:     logger,
+		clusterID:  cloud.ClusterID(),
+	}
+}
+
+var _ SecretCertManager = &casSecretCertManager{}
+
+type casSecretCertManager struct {
+	kubeClient client.Client
+	cloud      prvd.Provider
+	logger     logr.Logger
+	clusterID  string
+
+	// uploadMutex avoids uploading the same secret twice from concurrent reconciles
+	uploadMutex sync.Mutex
+}
+
+func (m *casSecretCertManager) Ensure(ctx context.Context, secretKey types.NamespacedName) (string, error) {
+	secret := &corev1.Secret{}
+	if err := m.kubeClient.Get(ctx, secretKey, secret); err != nil {
+		return "", errors.Wrapf(err, "failed to get tls secret %s", secretKey.String())
+	}
+	cert, key, err := tlsSecretData(secret)
+	if err != nil {
+		return "", err
+	}
+	certName := m.certName(secret, cert, key)
+
+	m.uploadMutex.Lock()
+	defer m.uploadMutex.Unlock()
+
+	certID, err := m.findCertIdentifier(ctx, certName)
+	if err != nil {
+		return "", err
+	}
+	if certID != "" {
+		return certID, nil
+	}
+
+	id, err := m.cloud.UploadUserCertificate(ctx, certName, cert, key, map[string]string{
+		util.ClusterTagKey: m.clusterID,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to upload tls secret %s", secretKey.String())
+	}
+	m.logger.Info("uploaded tls secret to cas", "secret", secretKey.String(), "certName", certName, "certID", id)
+
+	certID, err = m.findCertIdentifier(ctx, certName)
+	if err != nil {
+		return "", err
+	}
+	if certID == "" {
+		return "", fmt.Errorf("certificate %s uploaded from secret %s is not listed yet", certName, secretKey.String())
+	}
+	return certID, nil
+}
+
+func (m *casSecretCertManager) GC(ctx context.Context) error {
+	if m.clusterID == "" {
+		m.logger.Info("cluster id is empty, skip gc of certificates uploaded from secrets")
+		return nil
+	}
+
+	// the certificates are shared by all the albconfigs of the cluster, so the ingresses of
+	// every albconfig, including the ones being deleted, keep their certificates
+	ings := &networking.IngressList{}
+	if err := m.kubeClient.List(ctx, ings); err != nil {
+		return errors.Wrap(err, "failed to list ingresses")
+	}
+	inUse := sets.New[string]()
+	for i := range ings.Items {
+		for _, secretKey := range ComputeIngressTLSSecrets(&ings.Items[i]) {
+			secret := &corev1.Secret{}
+			if err := m.kubeClient.Get(ctx, secretKey, secret); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				// keep everything uploaded while the secret state is unknown
+				return err
+			}
+			cert, key, err := tlsSecretData(secret)
+			if err != nil {
+				continue
+			}
+			inUse.Insert(m.certName(secret, cert, key))
+		}
+	}
+
+	m.uploadMutex.Lock()
+	defer m.uploadMutex.Unlock()
+
+	certs, err := m.cloud.DescribeSSLCertificateList(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, c := range certs {
+		if !strings.HasPrefix(c.CertName, m.certNamePrefix()) || inUse.Has(c.CertName) {
+			continue
+		}
+		id, err := parseCertID(c.CertIdentifier)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// the name prefix may be taken by a certificate uploaded by hand, only the certificates
+		// tagged with the cluster id on upload are owned by the cluster
+		tags, err := m.cloud.ListUserCertificateTags(ctx, id)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to list tags of certificate %s", c.CertName))
+			continue
+		}
+		if tags[util.ClusterTagKey] != m.clusterID {
+			continue
+		}
+		// deleting a certificate still attached to a listener fails, it is retried on the next gc
+		if err := m.cloud.DeleteUserCertificate(ctx, id); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to delete certificate %s", c.CertName))
+			continue
+		}
+		m.logger.Info("deleted stale certificate uploaded from secret", "certName", c.CertName, "certID", c.CertIdentifier)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("gc certificates uploaded from secrets: %v", errs)
+	}
+	return nil
+}
+
+func (m *casSecretCertManager) findCertIdentifier(ctx context.Context, certName string) (string, error) {
+	certs, err := m.cloud.DescribeSSLCertificateList(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range certs {
+		if c.CertName == certName {
+			return c.CertIdentifier, nil
+		}
+	}
+	return "", nil
+}
+
+func (m *casSecretCertManager) certNamePrefix() string {
+	return m.clusterID + "-"
+}
+
+func (m *casSecretCertManager) certName(secret *corev1.Secret, cert, key string) string {
+	h := sha256.New()
+	h.Write([]byte(secret.Namespace + "/" + secret.Name))
+	h.Write([]byte(cert))
+	h.Write([]byte(key))
+	return m.certNamePrefix() + hex.EncodeToString(h.Sum(nil))[:secretCertNameHashLength]
+}
+
+func tlsSecretData(secret *corev1.Secret) (string, string, error) {
+	cert, ok := secret.Data[corev1.TLSCertKey]
+	if !ok || len(cert) == 0 {
+		return "", "", fmt.Errorf("secret %s/%s has no %s", secret.Namespace, secret.Name, corev1.TLSCertKey)
+	}
+	key, ok := secret.Data[corev1.TLSPrivateKeyKey]
+	if !ok || len(key) == 0 {
+		return "", "", fmt.Errorf("secret %s/%s has no %s", secret.Namespace, secret.Name, corev1.TLSPrivateKeyKey)
+	}
+	return string(cert), string(key), nil
+}
+
+// parseCertID extracts the numeric CAS certificate id from an identifier like 123456-cn-hangzhou.
+func parseCertID(certIdentifier string) (int64, error) {
+	id := strings.SplitN(certIdentifier, "-", 2)[0]
+	certID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid certificate identifier %s", certIdentifier)
+	}
+	return certID, nil
+}
+
+// ComputeIngressTLSSecrets returns the secrets referenced by spec.tls of the ingress.
+func ComputeIngressTLSSecrets(ing *networking.Ingress) []types.NamespacedName {
+	var secrets []types.NamespacedName
+	seen := sets.New[string]()
+	for _, tls := range ing.Spec.TLS {
+		if tls.SecretName == "" || seen.Has(tls.SecretName) {
+			continue
+		}
+		seen.Insert(tls.SecretName)
+		secrets = append(secrets, types.NamespacedName{Namespace: ing.Namespace, Name: tls.SecretName})
+	}
+	return secrets
+}
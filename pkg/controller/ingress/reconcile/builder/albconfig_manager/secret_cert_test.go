@@ -0,0 +1,154 @@
+package albconfigmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func newTLSSecret(name, cert string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: name},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte(cert),
+			corev1.TLSPrivateKeyKey: []byte("key-" + name),
+		},
+	}
+}
+
+func newTLSIngress(name, className string, secrets ...string) *networking.Ingress {
+	ing := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: name},
+		Spec:       networking.IngressSpec{IngressClassName: &className},
+	}
+	for _, s := range secrets {
+		ing.Spec.TLS = append(ing.Spec.TLS, networking.IngressTLS{Hosts: []string{name + ".example.com"}, SecretName: s})
+	}
+	return ing
+}
+
+func certNames(t *testing.T, cloud *fakecloud.FakeCloud) []string {
+	certs, err := cloud.DescribeSSLCertificateList(context.TODO())
+	assert.NoError(t, err)
+	var names []string
+	for _, c := range certs {
+		names = append(names, c.CertName)
+	}
+	return names
+}
+
+func TestSecretCertManagerEnsure(t *testing.T) {
+	secret := newTLSSecret("tls", "cert-v1")
+	kubeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(secret).Build()
+	cloud := fakecloud.NewFakeCloud(nil)
+	m := NewCASSecretCertManager(kubeClient, cloud, logr.Discard())
+	ctx := context.TODO()
+
+	certID, err := m.Ensure(ctx, types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "tls"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, certID)
+	assert.Len(t, certNames(t, cloud), 1)
+	assert.Equal(t, 1, cloud.CallCount("UploadUserCertificate"))
+
+	// the secret is uploaded once
+	again, err := m.Ensure(ctx, types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "tls"})
+	assert.NoError(t, err)
+	assert.Equal(t, certID, again)
+	assert.Equal(t, 1, cloud.CallCount("UploadUserCertificate"))
+
+	// an update of the secret results in a new certificate
+	secret.Data[corev1.TLSCertKey] = []byte("cert-v2")
+	assert.NoError(t, kubeClient.Update(ctx, secret))
+	updated, err := m.Ensure(ctx, types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "tls"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, certID, updated)
+	assert.Len(t, certNames(t, cloud), 2)
+
+	// a secret without the key is rejected
+	invalid := newTLSSecret("invalid", "cert")
+	delete(invalid.Data, corev1.TLSPrivateKeyKey)
+	assert.NoError(t, kubeClient.Create(ctx, invalid))
+	_, err = m.Ensure(ctx, types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "invalid"})
+	assert.Error(t, err)
+	_, err = m.Ensure(ctx, types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "not-found"})
+	assert.Error(t, err)
+}
+
+func TestSecretCertManagerGC(t *testing.T) {
+	objs := []client.Object{
+		newTLSSecret("tls-a", "cert-a"),
+		newTLSSecret("tls-b", "cert-b"),
+		newTLSSecret("tls-c", "cert-c"),
+		// the ingresses belong to different albconfigs
+		newTLSIngress("ing-a", "alb-a", "tls-a"),
+		newTLSIngress("ing-b", "alb-b", "tls-b"),
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objs...).Build()
+	cloud := fakecloud.NewFakeCloud(nil)
+	m := NewCASSecretCertManager(kubeClient, cloud, logr.Discard())
+	ctx := context.TODO()
+
+	ids := map[string]string{}
+	for _, name := range []string{"tls-a", "tls-b", "tls-c"} {
+		id, err := m.Ensure(ctx, types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: name})
+		assert.NoError(t, err)
+		ids[name] = id
+	}
+	// certificates not uploaded by the cluster are never deleted
+	_, err := cloud.UploadUserCertificate(ctx, "user-cert", "cert", "key", nil)
+	assert.NoError(t, err)
+	// neither are the certificates named like the ones of the cluster without the cluster tag
+	manual := m.certNamePrefix() + "manual"
+	_, err = cloud.UploadUserCertificate(ctx, manual, "cert", "key", map[string]string{util.ClusterTagKey: "other"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, m.GC(ctx))
+	var remaining []string
+	certs, err := cloud.DescribeSSLCertificateList(ctx)
+	assert.NoError(t, err)
+	for _, c := range certs {
+		remaining = append(remaining, c.CertIdentifier)
+	}
+	assert.Len(t, remaining, 4)
+	assert.Contains(t, remaining, ids["tls-a"])
+	assert.Contains(t, remaining, ids["tls-b"])
+	assert.NotContains(t, remaining, ids["tls-c"])
+	assert.Contains(t, certNames(t, cloud), "user-cert")
+	assert.Contains(t, certNames(t, cloud), manual)
+
+	// the certificate is kept while its tags are unknown, and deleted once no ingress uses the secret
+	assert.NoError(t, kubeClient.Delete(ctx, newTLSIngress("ing-b", "alb-b")))
+	cloud.InjectError("ListUserCertificateTags", assert.AnError, -1)
+	assert.Error(t, m.GC(ctx))
+	assert.Contains(t, certNames(t, cloud), certNameOf(t, cloud, ids["tls-b"]))
+	cloud.ClearFaults()
+	assert.NoError(t, m.GC(ctx))
+	assert.ElementsMatch(t, []string{certNameOf(t, cloud, ids["tls-a"]), "user-cert", manual}, certNames(t, cloud))
+
+	// nothing is deleted if the certificates fail to be listed
+	cloud.InjectError("DescribeSSLCertificateList", assert.AnError, 1)
+	assert.Error(t, m.GC(ctx))
+	assert.Len(t, certNames(t, cloud), 3)
+}
+
+func certNameOf(t *testing.T, cloud *fakecloud.FakeCloud, certID string) string {
+	cert, err := cloud.DescribeSSLCertificatePublicKeyDetail(context.TODO(), certID)
+	assert.NoError(t, err)
+	if cert == nil {
+		return ""
+	}
+	return cert.CertName
+}
@@ -57,7 +57,7 @@ func (v *defaultVSwitchesResolver) discoveryALBZones(ctx context.Context) ([]alb
 		"traceID", traceID,
 		"startTime", startTime,
 		"action", DescribeALBZones)
-	resp, err := v.cloud.DescribeALBZones(req)
+	resp, err := v.cloud.DescribeALBZones(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	apiv1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -76,21 +77,50 @@ func (cml *ConfigMapLister) ByKey(key string) (*apiv1.ConfigMap, error) {
 	return s.(*apiv1.ConfigMap), nil
 }
 
-// EndpointLister makes a Store that lists Endpoints.
-type EndpointLister struct {
-	cache.Store
+// EndpointSliceLister makes a Store that lists EndpointSlices.
+type EndpointSliceLister struct {
+	cache.Indexer
 }
 
-// ByKey returns the Endpoints of the Service matching key in the local Endpoint Store.
-func (s *EndpointLister) ByKey(key string) (*apiv1.Endpoints, error) {
-	eps, exists, err := s.GetByKey(key)
+// ByService returns the EndpointSlices of the Service matching key in the local EndpointSlice Store.
+func (s *EndpointSliceLister) ByService(key string) ([]*discovery.EndpointSlice, error) {
+	objs, err := s.ByIndex(EndpointSliceServiceIndex, key)
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
+	if len(objs) == 0 {
 		return nil, NotExistsError(key)
 	}
-	return eps.(*apiv1.Endpoints), nil
+	slices := make([]*discovery.EndpointSlice, 0, len(objs))
+	for _, obj := range objs {
+		slices = append(slices, obj.(*discovery.EndpointSlice))
+	}
+	return slices, nil
+}
+
+// EndpointSliceServiceIndex indexes EndpointSlices by the namespace/name key of the Service they belong to.
+const EndpointSliceServiceIndex = "service"
+
+// EndpointSliceServiceKey returns the namespace/name key of the Service the EndpointSlice belongs to,
+// or an empty string if the EndpointSlice is not managed for a Service.
+func EndpointSliceServiceKey(es *discovery.EndpointSlice) string {
+	svcName, ok := es.Labels[discovery.LabelServiceName]
+	if !ok || svcName == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", es.Namespace, svcName)
+}
+
+func endpointSliceServiceIndexFunc(obj interface{}) ([]string, error) {
+	es, ok := obj.(*discovery.EndpointSlice)
+	if !ok {
+		return nil, nil
+	}
+	key := EndpointSliceServiceKey(es)
+	if key == "" {
+		return nil, nil
+	}
+	return []string{key}, nil
 }
 
 // IngressLister makes a Store that lists Ingress.
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/eapache/channels"
 
 	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -55,8 +57,8 @@ type Storer interface {
 	// GetService returns the Service matching key.
 	GetService(key string) (*corev1.Service, error)
 
-	// GetServiceEndpoints returns the Endpoints of a Service matching key.
-	GetServiceEndpoints(key string) (*corev1.Endpoints, error)
+	// GetServiceEndpointSlices returns the EndpointSlices of a Service matching key.
+	GetServiceEndpointSlices(key string) ([]*discovery.EndpointSlice, error)
 
 	GetPod(key string) (*corev1.Pod, error)
 
@@ -69,20 +71,22 @@ type Storer interface {
 
 // Informer defines the required SharedIndexInformers that interact with the API server.
 type Informer struct {
-	Ingress  cache.SharedIndexInformer
-	Endpoint cache.SharedIndexInformer
-	Service  cache.SharedIndexInformer
-	Node     cache.SharedIndexInformer
-	Pod      cache.SharedIndexInformer
+	Ingress       cache.SharedIndexInformer
+	EndpointSlice cache.SharedIndexInformer
+	Service       cache.SharedIndexInformer
+	Node          cache.SharedIndexInformer
+	Pod           cache.SharedIndexInformer
+	Secret        cache.SharedIndexInformer
 }
 
 // Lister contains object listers (stores).
 type Lister struct {
 	Ingress               IngressLister
 	Service               ServiceLister
-	Endpoint              EndpointLister
+	EndpointSlice         EndpointSliceLister
 	Pod                   PodLister
 	Node                  NodeLister
+	Secret                SecretLister
 	IngressWithAnnotation IngressWithAnnotationsLister
 }
 
@@ -97,16 +101,18 @@ func (e NotExistsError) Error() string {
 // Run initiates the synchronization of the informers against the API server.
 func (i *Informer) Run(stopCh chan struct{}) {
 	go i.Pod.Run(stopCh)
-	go i.Endpoint.Run(stopCh)
+	go i.EndpointSlice.Run(stopCh)
 	go i.Service.Run(stopCh)
 	go i.Node.Run(stopCh)
+	go i.Secret.Run(stopCh)
 	// wait for all involved caches to be synced before processing items
 	// from the queue
 	if !cache.WaitForCacheSync(stopCh,
 		i.Pod.HasSynced,
-		i.Endpoint.HasSynced,
+		i.EndpointSlice.HasSynced,
 		i.Service.HasSynced,
 		i.Node.HasSynced,
+		i.Secret.HasSynced,
 	) {
 		utilruntime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
 	}
@@ -184,8 +190,11 @@ func New(
 	store.informers.Ingress = infFactory.Networking().V1().Ingresses().Informer()
 	store.listers.Ingress.Store = store.informers.Ingress.GetStore()
 
-	store.informers.Endpoint = infFactory.Core().V1().Endpoints().Informer()
-	store.listers.Endpoint.Store = store.informers.Endpoint.GetStore()
+	store.informers.EndpointSlice = infFactory.Discovery().V1().EndpointSlices().Informer()
+	utilruntime.Must(store.informers.EndpointSlice.AddIndexers(cache.Indexers{
+		EndpointSliceServiceIndex: endpointSliceServiceIndexFunc,
+	}))
+	store.listers.EndpointSlice.Indexer = store.informers.EndpointSlice.GetIndexer()
 
 	store.informers.Service = infFactory.Core().V1().Services().Informer()
 	store.listers.Service.Store = store.informers.Service.GetStore()
@@ -196,6 +205,9 @@ func New(
 	store.informers.Pod = infFactory.Core().V1().Pods().Informer()
 	store.listers.Pod.Store = store.informers.Pod.GetStore()
 
+	store.informers.Secret = infFactory.Core().V1().Secrets().Informer()
+	store.listers.Secret.Store = store.informers.Secret.GetStore()
+
 	ingDeleteHandler := func(obj interface{}) {
 		ing, ok := toIngress(obj)
 		if !ok {
@@ -304,72 +316,49 @@ func New(
 		},
 	}
 
+	enqueueEndpointSliceService := func(es *discovery.EndpointSlice, event string) {
+		key := EndpointSliceServiceKey(es)
+		if key == "" {
+			return
+		}
+		svc, exist, err := store.listers.Service.GetByKey(key)
+		if err != nil {
+			klog.Error(err, "get service GetByKey by endpointslice failed", "endpointslice", util.NamespacedName(es))
+			return
+		}
+		if !exist {
+			klog.Warningf("epEventHandler %s: service %s not found", event, key)
+			return
+		}
+
+		klog.Infof("controller: endpointslice %s event %s", event, util.NamespacedName(es).String())
+		updateCh.In() <- helper.Event{
+			Type: helper.EndPointEvent,
+			Obj:  svc.(*corev1.Service),
+		}
+	}
 	epEventHandler := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			ep1 := obj.(*corev1.Endpoints)
-			key := MetaNamespaceKey(ep1)
-			svc, exist, err := store.listers.Service.GetByKey(key)
-			if err != nil {
-				klog.Error(err, "get service GetByKey by endpoint failed", "endpoint", ep1)
-				return
-			}
-			if !exist {
-				klog.Warningf("epEventHandler %s", key)
-				return
-			}
-			s := svc.(*corev1.Service)
-
-			klog.Info("controller: endpoint add event",
-				util.NamespacedName(ep1).String())
-			updateCh.In() <- helper.Event{
-				Type: helper.EndPointEvent,
-				Obj:  s,
-			}
+			enqueueEndpointSliceService(obj.(*discovery.EndpointSlice), "add")
 		},
 		DeleteFunc: func(obj interface{}) {
-			ep1 := obj.(*corev1.Endpoints)
-			key := MetaNamespaceKey(ep1)
-			svc, exist, err := store.listers.Service.GetByKey(key)
-			if err != nil {
-				klog.Error(err, "DeleteFunc get service GetByKey by endpoint failed", "endpoint", ep1)
-				return
-			}
-			if !exist {
-				klog.Warningf("DeleteFunc epEventHandler %s", key)
-				return
-			}
-
-			s := svc.(*corev1.Service)
-
-			klog.Info("controller: endpoint delete event",
-				util.NamespacedName(ep1).String())
-			updateCh.In() <- helper.Event{
-				Type: helper.EndPointEvent,
-				Obj:  s,
-			}
-		},
-		UpdateFunc: func(old, cur interface{}) {
-			ep1 := old.(*corev1.Endpoints)
-			ep2 := cur.(*corev1.Endpoints)
-			if !reflect.DeepEqual(ep1.Subsets, ep2.Subsets) {
-				key := MetaNamespaceKey(ep1)
-				svc, exist, err := store.listers.Service.GetByKey(key)
-				if err != nil {
-					klog.Error(err, "UpdateFunc get service GetByKey by endpoint failed", "endpoint", ep1)
+			es, ok := obj.(*discovery.EndpointSlice)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
 					return
 				}
-				if !exist {
-					klog.Warningf("UpdateFunc epEventHandler %s", key)
+				if es, ok = tombstone.Obj.(*discovery.EndpointSlice); !ok {
 					return
 				}
-				s := svc.(*corev1.Service)
-
-				klog.Info("controller: endpoint update event",
-					util.NamespacedName(ep1).String())
-				updateCh.In() <- helper.Event{
-					Type: helper.EndPointEvent,
-					Obj:  s,
-				}
+			}
+			enqueueEndpointSliceService(es, "delete")
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			es1 := old.(*discovery.EndpointSlice)
+			es2 := cur.(*discovery.EndpointSlice)
+			if !reflect.DeepEqual(es1.Endpoints, es2.Endpoints) || !reflect.DeepEqual(es1.Ports, es2.Ports) {
+				enqueueEndpointSliceService(es2, "update")
 			}
 		},
 	}
@@ -444,6 +433,11 @@ func New(
 				return
 			}
 
+			// the alb annotations of the service tune its server groups
+			if !reflect.DeepEqual(albAnnotations(oldSvc.Annotations), albAnnotations(curSvc.Annotations)) {
+				store.enqueueImpactedIngresses(updateCh, curSvc)
+			}
+
 			updateCh.In() <- helper.Event{
 				Type: helper.ServiceEvent,
 				Obj:  cur,
@@ -456,14 +450,63 @@ func New(
 		},
 	}
 
+	secretEventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			sec := obj.(*corev1.Secret)
+			store.enqueueSecretReferencedIngresses(updateCh, sec)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			oldSec := old.(*corev1.Secret)
+			curSec := cur.(*corev1.Secret)
+			if reflect.DeepEqual(oldSec.Data, curSec.Data) {
+				return
+			}
+			store.enqueueSecretReferencedIngresses(updateCh, curSec)
+		},
+		DeleteFunc: func(obj interface{}) {
+			sec, ok := obj.(*corev1.Secret)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				if sec, ok = tombstone.Obj.(*corev1.Secret); !ok {
+					return
+				}
+			}
+			store.enqueueSecretReferencedIngresses(updateCh, sec)
+		},
+	}
+
 	_, _ = store.informers.Ingress.AddEventHandler(ingEventHandler)
-	_, _ = store.informers.Endpoint.AddEventHandler(epEventHandler)
+	_, _ = store.informers.EndpointSlice.AddEventHandler(epEventHandler)
 	_, _ = store.informers.Node.AddEventHandler(podEventHandler)
 	_, _ = store.informers.Service.AddEventHandler(serviceHandler)
 	_, _ = store.informers.Node.AddEventHandler(nodeEventHandler)
+	_, _ = store.informers.Secret.AddEventHandler(secretEventHandler)
 	return store
 }
 
+// enqueueSecretReferencedIngresses enqueues the ingresses using the secret in spec.tls,
+// so the certificate uploaded from the secret is refreshed.
+func (s *k8sStore) enqueueSecretReferencedIngresses(updateCh *channels.RingChannel, sec *corev1.Secret) {
+	key := MetaNamespaceKey(sec)
+	if !s.secretIngressMap.Has(key) {
+		return
+	}
+	for _, ingKey := range s.secretIngressMap.Reference(key) {
+		obj, exists, err := s.listers.Ingress.GetByKey(ingKey)
+		if err != nil || !exists {
+			continue
+		}
+		klog.InfoS("secret change: enqueue ingress", "secret", key, "ingress", ingKey)
+		updateCh.In() <- helper.Event{
+			Type: helper.IngressEvent,
+			Obj:  obj,
+		}
+	}
+}
+
 func (s *k8sStore) enqueueImpactedIngresses(updateCh *channels.RingChannel, svc *corev1.Service) {
 	ingList := s.listers.Ingress.List()
 
@@ -481,6 +524,16 @@ func (s *k8sStore) enqueueImpactedIngresses(updateCh *channels.RingChannel, svc
 	}
 }
 
+func albAnnotations(annos map[string]string) map[string]string {
+	ret := make(map[string]string)
+	for k, v := range annos {
+		if strings.HasPrefix(k, annotations.AnnotationLoadBalancerPrefix) {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
 func IsIngressAlbClass(ing networking.Ingress) bool {
 	if ingClassAnnotation, exists := ing.Annotations[util.IngressClass]; exists {
 		if ingClassAnnotation == IngressClassName {
@@ -523,6 +576,13 @@ func (s *k8sStore) syncIngress(ing *networking.Ingress) {
 
 	SetDefaultALBPathType(copyIng)
 
+	s.secretIngressMap.Delete(key)
+	for _, tls := range copyIng.Spec.TLS {
+		if tls.SecretName != "" {
+			s.secretIngressMap.Insert(key, fmt.Sprintf("%s/%s", copyIng.Namespace, tls.SecretName))
+		}
+	}
+
 	err := s.listers.IngressWithAnnotation.Update(&Ingress{
 		Ingress: *copyIng,
 	})
@@ -568,9 +628,9 @@ func (s *k8sStore) ListIngresses() []*Ingress {
 	return ingresses
 }
 
-// GetServiceEndpoints returns the Endpoints of a Service matching key.
-func (s *k8sStore) GetServiceEndpoints(key string) (*corev1.Endpoints, error) {
-	return s.listers.Endpoint.ByKey(key)
+// GetServiceEndpointSlices returns the EndpointSlices of a Service matching key.
+func (s *k8sStore) GetServiceEndpointSlices(key string) ([]*discovery.EndpointSlice, error) {
+	return s.listers.EndpointSlice.ByService(key)
 }
 
 func (s *k8sStore) GetPod(key string) (*corev1.Pod, error) {
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/annotations"
+)
+
+func TestAlbAnnotations(t *testing.T) {
+	annos := map[string]string{
+		annotations.BackendProtocol:          "https",
+		annotations.StickySession:            "true",
+		"kubectl.kubernetes.io/last-applied": "{}",
+		annotations.BackendType:              "eni",
+	}
+	// only the changes of the alb annotations of a service re-enqueue its ingresses
+	assert.Equal(t, map[string]string{
+		annotations.BackendProtocol: "https",
+		annotations.StickySession:   "true",
+	}, albAnnotations(annos))
+	assert.Empty(t, albAnnotations(nil))
+}
@@ -3,6 +3,7 @@ package ingress
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	"k8s.io/cloud-provider-alibaba-cloud/cmd/health"
 	v1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
@@ -49,10 +50,18 @@ func RegisterCRD(cfg *rest.Config) error {
 			return fmt.Errorf("initialize crd: %s, %s", reflect.TypeOf(crd), err.Error())
 		}
 	}
-	health.CRDReady = true
+	health.RegisterReadiness("albconfig-crd", health.NewCachedChecker(health.CheckerFunc(func() error {
+		return client.IsPresent(AlbConfigCRDName)
+	}), crdCheckPeriod))
 	return nil
 }
 
+const (
+	// AlbConfigCRDName is the name of the AlbConfig crd
+	AlbConfigCRDName = "albconfigs.alibabacloud.com"
+	crdCheckPeriod   = time.Minute
+)
+
 type CRD interface {
 	Initialize() error
 	GetObject() runtime.Object
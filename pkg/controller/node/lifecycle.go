@@ -0,0 +1,138 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider/api"
+)
+
+const (
+	// NodeInstanceTerminating is true when the spot instance of the node is about to be reclaimed
+	// or the subscription of the instance is about to expire, so that the node can be drained ahead of time.
+	NodeInstanceTerminating v1.NodeConditionType = "InstanceTerminating"
+
+	ReasonSpotInstanceRecycling = "SpotInstanceRecycling"
+	ReasonSubscriptionExpiring  = "SubscriptionExpiring"
+	ReasonInstanceAvailable     = "InstanceAvailable"
+)
+
+// instanceTermination returns the reason and the message if the instance is about to be reclaimed or to expire.
+func instanceTermination(ins *prvd.NodeAttribute, now time.Time) (string, string, bool) {
+	for _, reason := range ins.LockReasons {
+		if reason == prvd.InstanceLockRecycling {
+			return ReasonSpotInstanceRecycling, "Spot instance is about to be reclaimed", true
+		}
+	}
+	window := ctrlCfg.ControllerCFG.NodeExpirationWindow
+	if window > 0 && !ins.ExpiredTime.IsZero() && ins.ExpiredTime.Sub(now) < window {
+		return ReasonSubscriptionExpiring,
+			fmt.Sprintf("Subscription of the instance expires at %s", ins.ExpiredTime.Format(time.RFC3339)), true
+	}
+	return "", "", false
+}
+
+func isNodeTerminating(node *v1.Node) bool {
+	condition, ok := helper.FindCondition(node.Status.Conditions, NodeInstanceTerminating)
+	return ok && condition.Status == v1.ConditionTrue
+}
+
+// setInstanceTerminatingCondition sets the InstanceTerminating condition of the node. The condition is only
+// added once the instance is terminating, and is set to false afterwards, e.g. the subscription is renewed.
+func setInstanceTerminatingCondition(node *v1.Node, ins *prvd.NodeAttribute, now time.Time) {
+	reason, message, terminating := instanceTermination(ins, now)
+	existing, found := helper.FindCondition(node.Status.Conditions, NodeInstanceTerminating)
+	if !terminating && !found {
+		return
+	}
+
+	condition := v1.NodeCondition{
+		Type:    NodeInstanceTerminating,
+		Status:  v1.ConditionFalse,
+		Reason:  ReasonInstanceAvailable,
+		Message: "Instance is not about to be reclaimed or to expire",
+	}
+	if terminating {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = message
+	}
+	if found && existing.Status == condition.Status &&
+		existing.Reason == condition.Reason && existing.Message == condition.Message {
+		return
+	}
+	condition.LastHeartbeatTime = metav1.NewTime(now)
+	condition.LastTransitionTime = metav1.NewTime(now)
+	if found && existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	}
+
+	var conditions []v1.NodeCondition
+	for _, c := range node.Status.Conditions {
+		if c.Type != NodeInstanceTerminating {
+			conditions = append(conditions, c)
+		}
+	}
+	node.Status.Conditions = append(conditions, condition)
+}
+
+// setLifecycleTaints adds the shutdown taint to the node of a stopped instance, and the termination taint
+// to the node of an instance which is about to be reclaimed or to expire. The taints are removed once the
+// instance recovers.
+func setLifecycleTaints(node *v1.Node, ins *prvd.NodeAttribute, now time.Time) {
+	switch ins.Status {
+	case prvd.InstanceStatusStopped, prvd.InstanceStatusStopping:
+		node.Spec.Taints = addTaint(node.Spec.Taints, v1.Taint{
+			Key:    api.TaintNodeShutdown,
+			Effect: v1.TaintEffectNoSchedule,
+		})
+	case "":
+		// status is unknown, leave the shutdown taint as it is
+	default:
+		node.Spec.Taints = removeTaintsByKey(node.Spec.Taints, api.TaintNodeShutdown)
+	}
+
+	key := ctrlCfg.ControllerCFG.NodeTerminationTaintKey
+	if key == "" {
+		return
+	}
+	if _, _, terminating := instanceTermination(ins, now); !terminating {
+		node.Spec.Taints = removeTaintsByKey(node.Spec.Taints, key)
+		return
+	}
+	taint := v1.Taint{Key: key, Effect: v1.TaintEffect(ctrlCfg.ControllerCFG.NodeTerminationTaintEffect)}
+	if taint.Effect == "" {
+		taint.Effect = v1.TaintEffectNoSchedule
+	}
+	if taint.Effect == v1.TaintEffectNoExecute {
+		added := metav1.NewTime(now)
+		taint.TimeAdded = &added
+	}
+	node.Spec.Taints = addTaint(node.Spec.Taints, taint)
+}
+
+// addTaint adds the taint if no taint with the same key and effect exists, taints with the same key and
+// other effects are replaced.
+func addTaint(taints []v1.Taint, taint v1.Taint) []v1.Taint {
+	for i := range taints {
+		if taints[i].MatchTaint(&taint) {
+			return taints
+		}
+	}
+	return append(removeTaintsByKey(taints, taint.Key), taint)
+}
+
+func removeTaintsByKey(taints []v1.Taint, key string) []v1.Taint {
+	var ret []v1.Taint
+	for _, taint := range taints {
+		if taint.Key != key {
+			ret = append(ret, taint)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,109 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider/api"
+)
+
+const testTerminationTaintKey = "node.alibabacloud.com/instance-terminating"
+
+func TestSetLifecycleTaints(t *testing.T) {
+	ctrlCfg.ControllerCFG.NodeTerminationTaintKey = testTerminationTaintKey
+	ctrlCfg.ControllerCFG.NodeTerminationTaintEffect = string(v1.TaintEffectNoSchedule)
+	ctrlCfg.ControllerCFG.NodeExpirationWindow = 72 * time.Hour
+	defer func() {
+		ctrlCfg.ControllerCFG.NodeTerminationTaintKey = ""
+		ctrlCfg.ControllerCFG.NodeTerminationTaintEffect = ""
+		ctrlCfg.ControllerCFG.NodeExpirationWindow = 0
+	}()
+
+	now := time.Now()
+	shutdown := v1.Taint{Key: api.TaintNodeShutdown, Effect: v1.TaintEffectNoSchedule}
+	terminating := v1.Taint{Key: testTerminationTaintKey, Effect: v1.TaintEffectNoSchedule}
+	cases := []struct {
+		name   string
+		ins    prvd.NodeAttribute
+		taints []v1.Taint
+		expect []string
+	}{
+		{
+			name:   "stopped instance",
+			ins:    prvd.NodeAttribute{Status: prvd.InstanceStatusStopped},
+			expect: []string{api.TaintNodeShutdown},
+		},
+		{
+			name:   "started instance",
+			ins:    prvd.NodeAttribute{Status: prvd.InstanceStatusRunning},
+			taints: []v1.Taint{shutdown},
+		},
+		{
+			name:   "unknown status",
+			ins:    prvd.NodeAttribute{},
+			taints: []v1.Taint{shutdown},
+			expect: []string{api.TaintNodeShutdown},
+		},
+		{
+			name: "spot instance recycling",
+			ins: prvd.NodeAttribute{
+				Status:      prvd.InstanceStatusRunning,
+				LockReasons: []string{prvd.InstanceLockRecycling},
+			},
+			expect: []string{testTerminationTaintKey},
+		},
+		{
+			name: "subscription expiring",
+			ins: prvd.NodeAttribute{
+				Status:      prvd.InstanceStatusRunning,
+				ExpiredTime: now.Add(24 * time.Hour),
+			},
+			expect: []string{testTerminationTaintKey},
+		},
+		{
+			name: "subscription renewed",
+			ins: prvd.NodeAttribute{
+				Status:      prvd.InstanceStatusRunning,
+				ExpiredTime: now.Add(30 * 24 * time.Hour),
+			},
+			taints: []v1.Taint{terminating},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			node := &v1.Node{Spec: v1.NodeSpec{Taints: c.taints}}
+			setLifecycleTaints(node, &c.ins, now)
+			var keys []string
+			for _, taint := range node.Spec.Taints {
+				keys = append(keys, taint.Key)
+			}
+			assert.Equal(t, c.expect, keys)
+		})
+	}
+}
+
+func TestSetInstanceTerminatingCondition(t *testing.T) {
+	ctrlCfg.ControllerCFG.NodeExpirationWindow = 72 * time.Hour
+	defer func() {
+		ctrlCfg.ControllerCFG.NodeExpirationWindow = 0
+	}()
+
+	now := time.Now()
+	node := &v1.Node{}
+	setInstanceTerminatingCondition(node, &prvd.NodeAttribute{ExpiredTime: now.Add(30 * 24 * time.Hour)}, now)
+	assert.Empty(t, node.Status.Conditions)
+
+	setInstanceTerminatingCondition(node, &prvd.NodeAttribute{ExpiredTime: now.Add(time.Hour)}, now)
+	assert.True(t, isNodeTerminating(node))
+	assert.Equal(t, ReasonSubscriptionExpiring, node.Status.Conditions[0].Reason)
+
+	setInstanceTerminatingCondition(node, &prvd.NodeAttribute{ExpiredTime: now.Add(30 * 24 * time.Hour)}, now)
+	assert.False(t, isNodeTerminating(node))
+	assert.Len(t, node.Status.Conditions, 1)
+	assert.Equal(t, ReasonInstanceAvailable, node.Status.Conditions[0].Reason)
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -19,6 +21,7 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -181,7 +184,13 @@ outer:
 		}
 
 		log.Info("batch reconcile nodes", "length", len(nodes), "names", names, "worker", idx)
-		if err := m.syncNode(nodes, m.configCloudRoute); err != nil {
+		spanCtx, span := tracing.Start(ctx, "Reconcile Node", trace.WithAttributes(
+			attribute.String("k8s.kind", "Node"),
+			attribute.StringSlice("k8s.names", names),
+		))
+		err := m.syncNode(tracing.Background(spanCtx), nodes, m.configCloudRoute)
+		tracing.End(span, err)
+		if err != nil {
 			log.Error(err, "sync node error", "names", names)
 			continue
 		}
@@ -241,7 +250,7 @@ func (m *ReconcileNode) doAddCloudNode(node *corev1.Node) error {
 		return fmt.Errorf("failed to get specified nodeIP in cloud provider")
 	}
 
-	initializer := func(_ context.Context) (done bool, err error) {
+	initializer := func(ctx context.Context) (done bool, err error) {
 		log.Info("try remove cloud taints", "node", node.Name)
 
 		diff := func(copy runtime.Object) (client.Object, error) {
@@ -258,14 +267,14 @@ func (m *ReconcileNode) doAddCloudNode(node *corev1.Node) error {
 		log.Info("finished remove uninitialized cloud taints", "node", node.Name)
 		// After adding, call UpdateNodeAddress to set the CloudProvider provided IPAddresses
 		// So that users do not see any significant delay in IP addresses being filled into the node
-		_ = m.syncNode([]corev1.Node{*node}, false)
+		_ = m.syncNode(ctx, []corev1.Node{*node}, false)
 		return true, nil
 	}
 	return wait.PollUntilContextTimeout(context.TODO(), 5*time.Second, 20*time.Second, true, initializer)
 }
 
 // syncNode sync the nodeAddress & cloud node existence
-func (m *ReconcileNode) syncNode(nodes []corev1.Node, configCloudRoute bool) error {
+func (m *ReconcileNode) syncNode(ctx context.Context, nodes []corev1.Node, configCloudRoute bool) error {
 	start := time.Now()
 	defer func() {
 		metric.NodeLatency.WithLabelValues("reconcile").Observe(metric.MsSince(start))
@@ -285,7 +294,7 @@ func (m *ReconcileNode) syncNode(nodes []corev1.Node, configCloudRoute bool) err
 		switch t {
 		case NodeTypeECS:
 			tasks = append(tasks, func() error {
-				if err := m.syncECSNodes(nodes, configCloudRoute); err != nil {
+				if err := m.syncECSNodes(ctx, nodes, configCloudRoute); err != nil {
 					log.Error(err, "sync ecs nodes error", "nodeLen", len(nodes))
 					return err
 				}
@@ -293,7 +302,7 @@ func (m *ReconcileNode) syncNode(nodes []corev1.Node, configCloudRoute bool) err
 			})
 		case NodeTypeLingJun:
 			tasks = append(tasks, func() error {
-				if err := m.syncLingJunNodes(nodes, configCloudRoute); err != nil {
+				if err := m.syncLingJunNodes(ctx, nodes, configCloudRoute); err != nil {
 					log.Error(err, "sync lingjun nodes error", "nodeLen", len(nodes))
 					return err
 				}
@@ -313,8 +322,8 @@ func (m *ReconcileNode) syncNode(nodes []corev1.Node, configCloudRoute bool) err
 	return err
 }
 
-func (m *ReconcileNode) syncECSNodes(nodes []corev1.Node, configCloudRoute bool) error {
-	instances, err := m.cloud.ListInstances(context.TODO(), nodeids(nodes))
+func (m *ReconcileNode) syncECSNodes(ctx context.Context, nodes []corev1.Node, configCloudRoute bool) error {
+	instances, err := m.cloud.ListInstances(ctx, nodeids(nodes))
 	if err != nil {
 		return fmt.Errorf("[NodeAddress] list ecs instances from api: %s", err.Error())
 	}
@@ -360,7 +369,7 @@ func (m *ReconcileNode) syncECSNodes(nodes []corev1.Node, configCloudRoute bool)
 
 	var failedENIs []eniInfo
 	if !ctrlCfg.ControllerCFG.SkipDisableSourceDestCheck && len(toDisableSourceDestCheckIDs) != 0 {
-		failedENIs, err = m.disableNetworkInterfaceSourceDestCheck(toDisableSourceDestCheckIDs)
+		failedENIs, err = m.disableNetworkInterfaceSourceDestCheck(ctx, toDisableSourceDestCheckIDs)
 		if err != nil {
 			return fmt.Errorf("failed to disable source dest check: %w", err)
 		}
@@ -400,8 +409,14 @@ func (m *ReconcileNode) syncECSNodes(nodes []corev1.Node, configCloudRoute bool)
 			cloudNode.Addresses = []corev1.NodeAddress{*nodeIP}
 		}
 
+		now := time.Now()
+		if reason, message, terminating := instanceTermination(cloudNode, now); terminating && !isNodeTerminating(node) {
+			log.Info("cloud instance is terminating", "node", node.Name, "reason", reason, "message", message)
+			m.record.Event(nodeRef, corev1.EventTypeWarning, reason, message)
+		}
 		statusDiff := func(copy *corev1.Node) (*corev1.Node, error) {
 			copy.Status.Addresses = cloudNode.Addresses
+			setInstanceTerminatingCondition(copy, cloudNode, now)
 			return copy, nil
 		}
 
@@ -421,6 +436,7 @@ func (m *ReconcileNode) syncECSNodes(nodes []corev1.Node, configCloudRoute bool)
 		diff := func(copy runtime.Object) (client.Object, error) {
 			nins := copy.(*corev1.Node)
 			setFields(nins, cloudNode, configCloudRoute, removeTaints)
+			setLifecycleTaints(nins, cloudNode, now)
 			return nins, nil
 		}
 
@@ -436,7 +452,7 @@ func (m *ReconcileNode) syncECSNodes(nodes []corev1.Node, configCloudRoute bool)
 	return nil
 }
 
-func (m *ReconcileNode) syncLingJunNodes(nodes []corev1.Node, configCloudRoute bool) error {
+func (m *ReconcileNode) syncLingJunNodes(ctx context.Context, nodes []corev1.Node, configCloudRoute bool) error {
 	for i := range nodes {
 		node := &nodes[i]
 		nodeRef := &corev1.ObjectReference{
@@ -453,7 +469,7 @@ func (m *ReconcileNode) syncLingJunNodes(nodes []corev1.Node, configCloudRoute b
 
 		condition := nodeConditionReady(m.client, node)
 		if condition != nil && condition.Status == corev1.ConditionUnknown {
-			n, err := m.cloud.DescribeLingJunNode(context.TODO(), node.Spec.ProviderID)
+			n, err := m.cloud.DescribeLingJunNode(ctx, node.Spec.ProviderID)
 			if err != nil {
 				log.Error(err, "describe lingjun node failed", "node", node.Name, "id", node.Spec.ProviderID)
 				continue
@@ -491,7 +507,7 @@ type eniInfo struct {
 	NodeRef *corev1.ObjectReference
 }
 
-func (m *ReconcileNode) disableNetworkInterfaceSourceDestCheck(enis []eniInfo) ([]eniInfo, error) {
+func (m *ReconcileNode) disableNetworkInterfaceSourceDestCheck(ctx context.Context, enis []eniInfo) ([]eniInfo, error) {
 	var eniIDs []string
 	eniMap := map[string]eniInfo{}
 	for _, e := range enis {
@@ -499,7 +515,7 @@ func (m *ReconcileNode) disableNetworkInterfaceSourceDestCheck(enis []eniInfo) (
 		eniMap[e.ENI] = e
 	}
 
-	ifs, err := m.cloud.DescribeNetworkInterfacesByIDs(eniIDs)
+	ifs, err := m.cloud.DescribeNetworkInterfacesByIDs(ctx, eniIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -518,7 +534,7 @@ func (m *ReconcileNode) disableNetworkInterfaceSourceDestCheck(enis []eniInfo) (
 	var failed []eniInfo
 	for _, d := range toDisable {
 		err := helper.RetryOnErrorContains(errorOperationConflict, func() error {
-			return m.cloud.ModifyNetworkInterfaceSourceDestCheck(d.ENI, false)
+			return m.cloud.ModifyNetworkInterfaceSourceDestCheck(ctx, d.ENI, false)
 		})
 		if err != nil {
 			log.Error(err, "disable sourceDestCheck for network interface error",
@@ -541,11 +557,17 @@ func (m *ReconcileNode) PeriodicalSync() {
 			return
 		}
 
+		ctx, span := tracing.Start(context.Background(), "Sync Node", trace.WithAttributes(
+			attribute.String("k8s.kind", "Node"),
+		))
 		// ignore return value, retry on error
 		err = batchOperate(
 			nodes.Items,
-			m.syncNode,
+			func(nodes []corev1.Node, configCloudRoute bool) error {
+				return m.syncNode(ctx, nodes, configCloudRoute)
+			},
 		)
+		tracing.End(span, err)
 		if err != nil {
 			log.Error(err, "periodically sync node error")
 		}
@@ -242,7 +242,7 @@ func TestSyncLingJunNodes(t *testing.T) {
 
 	recon := getReconcileNode()
 	recon.client = client
-	err = recon.syncLingJunNodes(list.Items, false)
+	err = recon.syncLingJunNodes(context.TODO(), list.Items, false)
 	assert.NoError(t, err)
 
 	// wait for nodes to be deleted
@@ -10,9 +10,11 @@ import (
 	cmap "github.com/orcaman/concurrent-map"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	util_errors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
@@ -23,18 +25,41 @@ type Actuator struct {
 	client   client.Client
 	provider prvd.Provider
 	cacheMap cmap.ConcurrentMap
+	// claimedMap caches the rrs whose ownership records are written, by the keys of cacheMap
+	claimedMap cmap.ConcurrentMap
 }
 
 func NewActuator(c client.Client, p prvd.Provider) *Actuator {
 	a := &Actuator{
-		client:   c,
-		provider: p,
-		cacheMap: cmap.New(),
+		client:     c,
+		provider:   p,
+		cacheMap:   cmap.New(),
+		claimedMap: cmap.New(),
 	}
 	return a
 }
 
 func (a *Actuator) UpdateService(svc *corev1.Service) error {
+	eps, err := a.desiredService(svc)
+	errs := make([]error, 0)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	a.cacheMap.Set(serviceRr(svc), eps)
+	if err := a.claim(newOwner(ownerKindService, types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}), serviceRr(svc), eps); err != nil {
+		errs = append(errs, err)
+	}
+	for _, ep := range eps {
+		err := a.provider.UpdatePVTZ(context.TODO(), ep)
+		if err != nil {
+			klog.Errorf("update pvtz error %s", err.Error())
+			errs = append(errs, err)
+		}
+	}
+	return errors.Wrap(util_errors.NewAggregate(errs), "UpdateService error")
+}
+
+func (a *Actuator) desiredService(svc *corev1.Service) ([]*model.PvtzEndpoint, error) {
 	eps := make([]*model.PvtzEndpoint, 0)
 	desiredFuncs := []func(svc *corev1.Service) ([]*model.PvtzEndpoint, error){
 		a.desiredAandAAAA,
@@ -50,44 +75,12 @@ func (a *Actuator) UpdateService(svc *corev1.Service) error {
 		}
 		eps = append(eps, ps...)
 	}
-	a.cacheMap.Set(serviceRr(svc), eps)
-	for _, ep := range eps {
-		err := a.provider.UpdatePVTZ(context.TODO(), ep)
-		if err != nil {
-			klog.Errorf("update pvtz error %s", err.Error())
-			errs = append(errs, err)
-		}
-	}
-	return errors.Wrap(util_errors.NewAggregate(errs), "UpdateService error")
+	return eps, util_errors.NewAggregate(errs)
 }
 
 func (a *Actuator) DeleteService(svcName types.NamespacedName) error {
-	if eps, exist := a.cacheMap.Get(serviceRrByName(svcName)); exist {
-		errs := make([]error, 0)
-		remains := make([]*model.PvtzEndpoint, 0)
-		for _, ep := range eps.([]*model.PvtzEndpoint) {
-			err := a.provider.DeletePVTZ(context.TODO(), &model.PvtzEndpoint{
-				Rr: ep.Rr,
-			})
-			if err != nil {
-				klog.Errorf("Delete pvtz error %s", err.Error())
-				errs = append(errs, err)
-			} else {
-				remains = append(remains, ep)
-			}
-		}
-		if len(remains) > 0 {
-			a.cacheMap.Set(serviceRrByName(svcName), remains)
-		} else {
-			a.cacheMap.Remove(serviceRrByName(svcName))
-		}
-		return errors.Wrap(util_errors.NewAggregate(errs), "DeleteService error")
-	} else {
-		ep := &model.PvtzEndpoint{
-			Rr: serviceRrByName(svcName),
-		}
-		return a.provider.DeletePVTZ(context.TODO(), ep)
-	}
+	err := a.deleteOwned(newOwner(ownerKindService, svcName), serviceRrByName(svcName))
+	return errors.Wrap(err, "DeleteService error")
 }
 
 func (a *Actuator) getEndpoints(epName types.NamespacedName) (*corev1.Endpoints, error) {
@@ -307,11 +300,171 @@ func (a *Actuator) desiredCNAME(svc *corev1.Service) ([]*model.PvtzEndpoint, err
 }
 
 func (a *Actuator) UpdatePod(pod *corev1.Pod) error {
-	// TODO
-	return nil
+	eps, err := a.desiredPod(pod)
+	if err != nil {
+		return errors.Wrap(err, "UpdatePod error")
+	}
+	o := newOwner(ownerKindPod, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})
+	desiredRrs := endpointRrs(eps)
+	errs := make([]error, 0)
+	remains := make([]*model.PvtzEndpoint, 0)
+	// remove the records which are no longer desired, e.g. the pod becomes unready
+	if cached, exist := a.cacheMap.Get(podKey(pod)); exist {
+		for _, old := range cached.([]*model.PvtzEndpoint) {
+			if containsEndpoint(eps, old) {
+				continue
+			}
+			var err error
+			if desiredRrs.Has(old.Rr) {
+				err = a.provider.DeletePVTZ(context.TODO(), &model.PvtzEndpoint{Rr: old.Rr, Type: old.Type})
+			} else {
+				err = a.release(o, old.Rr)
+			}
+			if err != nil {
+				klog.Errorf("delete pvtz error %s", err.Error())
+				errs = append(errs, err)
+				remains = append(remains, old)
+			}
+		}
+	}
+	if err := a.claim(o, podKey(pod), eps); err != nil {
+		errs = append(errs, err)
+	}
+	for _, ep := range eps {
+		err := a.provider.UpdatePVTZ(context.TODO(), ep)
+		if err != nil {
+			klog.Errorf("update pvtz error %s", err.Error())
+			errs = append(errs, err)
+		}
+	}
+	// keep the records failed to delete in cache to retry
+	eps = append(eps, remains...)
+	if len(eps) > 0 {
+		a.cacheMap.Set(podKey(pod), eps)
+	} else {
+		a.cacheMap.Remove(podKey(pod))
+	}
+	return errors.Wrap(util_errors.NewAggregate(errs), "UpdatePod error")
 }
 
+// DeletePod removes the records created for the pod, the records are found by the cache and the ownership records.
 func (a *Actuator) DeletePod(podName types.NamespacedName) error {
-	// TODO
-	return nil
+	err := a.deleteOwned(newOwner(ownerKindPod, podName), podKeyByName(podName))
+	return errors.Wrap(err, "DeletePod error")
+}
+
+// desiredPod returns the A/AAAA and PTR records of the pod which has hostname and subdomain set,
+// the subdomain must be a headless service in the same namespace as the Kubernetes DNS Spec says.
+// The PTR records already desired by the service are skipped.
+// No record is desired if the pod is not ready, unless the service publishes not ready addresses.
+// https://github.com/kubernetes/dns/blob/master/docs/specification.md
+func (a *Actuator) desiredPod(pod *corev1.Pod) ([]*model.PvtzEndpoint, error) {
+	eps := make([]*model.PvtzEndpoint, 0)
+	if pod.Spec.Hostname == "" || pod.Spec.Subdomain == "" || pod.DeletionTimestamp != nil {
+		return eps, nil
+	}
+	svc := &corev1.Service{}
+	err := a.client.Get(context.TODO(), types.NamespacedName{Namespace: pod.Namespace, Name: pod.Spec.Subdomain}, svc)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return eps, nil
+		}
+		return nil, fmt.Errorf("getting service %s error: %s", pod.Spec.Subdomain, err)
+	}
+	if svc.Spec.ClusterIP != corev1.ClusterIPNone {
+		return eps, nil
+	}
+	if !svc.Spec.PublishNotReadyAddresses && !isPodReady(pod) {
+		return eps, nil
+	}
+
+	var ipsV4 []string
+	var ipsV6 []string
+	ips := []string{pod.Status.PodIP}
+	for _, podIP := range pod.Status.PodIPs {
+		ips = append(ips, podIP.IP)
+	}
+	for _, ip := range ips {
+		if ip == "" {
+			continue
+		}
+		if IsIPv4(ip) {
+			ipsV4 = append(ipsV4, ip)
+		} else if IsIPv6(ip) {
+			ipsV6 = append(ipsV6, ip)
+		} else {
+			return nil, fmt.Errorf("pod ip %s is invalid", ip)
+		}
+	}
+
+	rr := podRr(pod)
+	epTemplate := model.NewPvtzEndpointBuilder()
+	epTemplate.WithRr(rr)
+	epTemplate.WithTtl(ctrlCfg.CloudCFG.Global.PrivateZoneRecordTTL)
+	if len(ipsV4) != 0 {
+		epb := epTemplate.DeepCopy()
+		epb.WithType(model.RecordTypeA)
+		for _, ip := range ipsV4 {
+			epb.WithValueData(ip)
+		}
+		eps = append(eps, epb.Build())
+	}
+	if len(ipsV6) != 0 {
+		epb := epTemplate.DeepCopy()
+		epb.WithType(model.RecordTypeAAAA)
+		for _, ip := range ipsV6 {
+			epb.WithValueData(ip)
+		}
+		eps = append(eps, epb.Build())
+	}
+
+	reversed := make(map[string]bool)
+	for _, ip := range ipsV4 {
+		reversed[reverseIPv4(ip)] = true
+	}
+	for _, ip := range ipsV6 {
+		reversed[reverseIPv6(ip)] = true
+	}
+	// the PTR records of the addresses covered by the headless service point to the service,
+	// they are owned by the service and must not be overwritten or deleted with the pod
+	covered, err := a.servicePTRRrs(svc)
+	if err != nil {
+		return nil, err
+	}
+	for reverseRr := range reversed {
+		if covered.Has(reverseRr) {
+			continue
+		}
+		epb := model.NewPvtzEndpointBuilder()
+		epb.WithTtl(ctrlCfg.CloudCFG.Global.PrivateZoneRecordTTL)
+		epb.WithType(model.RecordTypePTR)
+		epb.WithRr(reverseRr)
+		epb.WithValueData(rr)
+		eps = append(eps, epb.Build())
+	}
+	return eps, nil
+}
+
+// servicePTRRrs returns the rrs of the PTR records desired by the headless service
+func (a *Actuator) servicePTRRrs(svc *corev1.Service) (sets.Set[string], error) {
+	if _, err := a.getEndpoints(types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return sets.New[string](), nil
+		}
+		return nil, fmt.Errorf("getting endpoints error: %s", err)
+	}
+	eps, err := a.desiredPTR(svc)
+	if err != nil {
+		return nil, err
+	}
+	return endpointRrs(eps), nil
+}
+
+func containsEndpoint(eps []*model.PvtzEndpoint, ep *model.PvtzEndpoint) bool {
+	for _, e := range eps {
+		if e.Rr == ep.Rr && e.Type == ep.Type {
+			return true
+		}
+	}
+	return false
 }
@@ -1,11 +1,18 @@
 package pvtz
 
 import (
+	"context"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 const (
@@ -176,3 +183,249 @@ func testDesiredEndpoints(t *testing.T, cases map[*corev1.Service][]*model.PvtzE
 		i++
 	}
 }
+
+func TestUpdateAndDeletePod(t *testing.T) {
+	headless := &corev1.Service{
+		ObjectMeta: testCommonObjectMeta,
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: corev1.ClusterIPNone,
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: testServiceNamespace},
+		Spec:       corev1.PodSpec{Hostname: "web-0", Subdomain: testServiceName},
+		Status: corev1.PodStatus{
+			PodIP:      IP1,
+			PodIPs:     []corev1.PodIP{{IP: IP1}, {IP: IPv61}},
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	cloud := fake.NewFakeCloud(nil)
+	a := NewActuator(fakeclient.NewClientBuilder().WithObjects(headless).Build(), cloud)
+
+	assert.NoError(t, a.UpdatePod(pod))
+	eps, err := cloud.ListPVTZ(context.TODO())
+	assert.NoError(t, err)
+	records := make(map[string]string)
+	for _, ep := range eps {
+		records[ep.Type+" "+ep.Rr] = ep.ValueString()
+	}
+	podRr := "web-0.test-svc.default.svc"
+	podOwner := newOwner(ownerKindPod, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}).String()
+	assert.Equal(t, map[string]string{
+		model.RecordTypeA + " " + podRr:                         IP1,
+		model.RecordTypeAAAA + " " + podRr:                      IPv61,
+		model.RecordTypePTR + " " + reverseIPv4(IP1):            podRr,
+		model.RecordTypePTR + " " + reverseIPv6(IPv61):          podRr,
+		model.RecordTypeTXT + " " + ownerRr(podRr):              podOwner,
+		model.RecordTypeTXT + " " + ownerRr(reverseIPv4(IP1)):   podOwner,
+		model.RecordTypeTXT + " " + ownerRr(reverseIPv6(IPv61)): podOwner,
+	}, records)
+
+	// records are removed when the pod becomes unready
+	pod.Status.Conditions[0].Status = corev1.ConditionFalse
+	assert.NoError(t, a.UpdatePod(pod))
+	eps, err = cloud.ListPVTZ(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, eps, 0)
+
+	pod.Status.Conditions[0].Status = corev1.ConditionTrue
+	assert.NoError(t, a.UpdatePod(pod))
+	assert.NoError(t, a.DeletePod(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}))
+	eps, err = cloud.ListPVTZ(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, eps, 0)
+}
+
+func TestPodPTRCoveredByService(t *testing.T) {
+	headless := &corev1.Service{
+		ObjectMeta: testCommonObjectMeta,
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: corev1.ClusterIPNone,
+		},
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: testCommonObjectMeta,
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: IP1}}}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: testServiceNamespace},
+		Spec:       corev1.PodSpec{Hostname: "web-0", Subdomain: testServiceName},
+		Status: corev1.PodStatus{
+			PodIP:      IP1,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	cloud := fake.NewFakeCloud(nil)
+	a := NewActuator(fakeclient.NewClientBuilder().WithObjects(headless, endpoints).Build(), cloud)
+	servicePTR := func() []*model.PvtzEndpoint {
+		eps, err := cloud.SearchPVTZ(context.TODO(), &model.PvtzEndpoint{Rr: reverseIPv4(IP1), Type: model.RecordTypePTR}, true)
+		assert.NoError(t, err)
+		return eps
+	}
+
+	assert.NoError(t, a.UpdateService(headless))
+	assert.NoError(t, a.UpdatePod(pod))
+	eps := servicePTR()
+	assert.Len(t, eps, 1)
+	assert.Equal(t, testServiceRr, eps[0].ValueString())
+
+	// the PTR record of the service is kept when the pod is deleted
+	assert.NoError(t, a.DeletePod(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}))
+	eps = servicePTR()
+	assert.Len(t, eps, 1)
+	assert.Equal(t, testServiceRr, eps[0].ValueString())
+}
+
+func TestDesiredPodWithoutHeadlessService(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: testCommonObjectMeta,
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: IP2,
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: testServiceNamespace},
+		Spec:       corev1.PodSpec{Hostname: "web-0", Subdomain: testServiceName},
+		Status: corev1.PodStatus{
+			PodIP:      IP1,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	a := NewActuator(fakeclient.NewClientBuilder().WithObjects(svc).Build(), nil)
+	eps, err := a.desiredPod(pod)
+	assert.NoError(t, err)
+	assert.Len(t, eps, 0)
+
+	pod.Spec.Subdomain = "not-exist"
+	eps, err = a.desiredPod(pod)
+	assert.NoError(t, err)
+	assert.Len(t, eps, 0)
+}
+
+func TestDeleteWithoutCacheAndGC(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: testCommonObjectMeta,
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: IP1,
+			Ports:     []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(80)}},
+		},
+	}
+	gone := svc.DeepCopy()
+	gone.Name = "gone"
+	cloud := fake.NewFakeCloud(nil)
+	c := fakeclient.NewClientBuilder().WithObjects(svc, gone, &corev1.Endpoints{ObjectMeta: testCommonObjectMeta},
+		&corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "gone", Namespace: testServiceNamespace}}).Build()
+	cloud.AddUnmanagedPvtzRecord(testServiceRr, model.RecordTypeA, IP2)
+
+	assert.NoError(t, NewActuator(c, cloud).UpdateService(svc))
+	assert.NoError(t, NewActuator(c, cloud).UpdateService(gone))
+	countRecords := func(rr string) int {
+		eps, err := cloud.SearchPVTZ(context.TODO(), &model.PvtzEndpoint{Rr: rr}, false)
+		assert.NoError(t, err)
+		return len(eps)
+	}
+	assert.NotZero(t, countRecords("gone.default.svc"))
+
+	// a restarted actuator deletes the records of the service rr by the ownership records without listing
+	// the zone, and leaves the others to gc
+	a := NewActuator(c, cloud)
+	assert.NoError(t, c.Delete(context.TODO(), gone))
+	assert.NoError(t, a.DeleteService(types.NamespacedName{Namespace: testServiceNamespace, Name: "gone"}))
+	eps, err := cloud.SearchPVTZ(context.TODO(), &model.PvtzEndpoint{Rr: "gone.default.svc"}, true)
+	assert.NoError(t, err)
+	assert.Len(t, eps, 0)
+	assert.NotZero(t, countRecords("_http._tcp.gone.default.svc"))
+	assert.NotZero(t, countRecords(testServiceRr))
+	assert.Zero(t, cloud.CallCount("ListPVTZ"))
+
+	// records of the services deleted while the controller is down are removed by gc
+	assert.NoError(t, c.Delete(context.TODO(), svc))
+	assert.NoError(t, a.GC())
+	eps, err = cloud.ListPVTZ(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, eps, 0)
+}
+
+func TestParseOwner(t *testing.T) {
+	o := owner{cluster: "c1", kind: ownerKindService, name: types.NamespacedName{Namespace: "ns", Name: "svc"}}
+	parsed, ok := parseOwner(o.String())
+	assert.True(t, ok)
+	assert.Equal(t, o, parsed)
+
+	_, ok = parseOwner("heritage=external-dns,external-dns/owner=default")
+	assert.False(t, ok)
+}
+
+func TestClaimOnce(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: testCommonObjectMeta,
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: IP1,
+			Ports:     []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(80)}},
+		},
+	}
+	cloud := fake.NewFakeCloud(nil)
+	c := fakeclient.NewClientBuilder().WithObjects(svc, &corev1.Endpoints{ObjectMeta: testCommonObjectMeta}).Build()
+	a := NewActuator(c, cloud)
+
+	assert.NoError(t, a.UpdateService(svc))
+	searches := cloud.CallCount("SearchPVTZ")
+	assert.NotZero(t, searches)
+
+	// the ownership records are not searched again if the rrs are not changed
+	assert.NoError(t, a.UpdateService(svc))
+	assert.Equal(t, searches, cloud.CallCount("SearchPVTZ"))
+
+	// the ownership record of a new rr is written
+	svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443, TargetPort: intstr.FromInt(443)})
+	assert.NoError(t, a.UpdateService(svc))
+	assert.Equal(t, searches+1, cloud.CallCount("SearchPVTZ"))
+	owner, err := a.ownerRecord("_https._tcp.test-svc.default.svc")
+	assert.NoError(t, err)
+	assert.Len(t, owner.Values, 1)
+}
+
+func TestClaimAfterGC(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: testCommonObjectMeta,
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: IP1,
+		},
+	}
+	cloud := fake.NewFakeCloud(nil)
+	c := fakeclient.NewClientBuilder().WithObjects(svc, &corev1.Endpoints{ObjectMeta: testCommonObjectMeta}).Build()
+	a := NewActuator(c, cloud)
+	assert.NoError(t, a.UpdateService(svc))
+
+	// the records released by gc are claimed again when the service comes back
+	assert.NoError(t, c.Delete(context.TODO(), svc))
+	assert.NoError(t, a.GC())
+	svc.ResourceVersion = ""
+	assert.NoError(t, c.Create(context.TODO(), svc))
+	assert.NoError(t, a.UpdateService(svc))
+	owner, err := a.ownerRecord(testServiceRr)
+	assert.NoError(t, err)
+	assert.Len(t, owner.Values, 1)
+}
+
+func TestPodPredicate(t *testing.T) {
+	pp := &PodPredicate{}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: testServiceNamespace},
+		Spec:       corev1.PodSpec{Hostname: "web-0", Subdomain: testServiceName},
+	}
+	assert.True(t, pp.Create(event.CreateEvent{Object: pod}))
+	assert.True(t, pp.Delete(event.DeleteEvent{Object: pod}))
+
+	other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: testServiceNamespace}}
+	assert.False(t, pp.Create(event.CreateEvent{Object: other}))
+	assert.False(t, pp.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: other.DeepCopy()}))
+	assert.False(t, pp.Delete(event.DeleteEvent{Object: other}))
+}
@@ -3,12 +3,17 @@ package pvtz
 import (
 	"context"
 	"fmt"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/context/shared"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -19,24 +24,49 @@ import (
 
 func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
 	var err error
-	err = addServiceReconciler(mgr, ctx)
+	// the reconcilers and the gc runner share the caches of the actuator
+	actuator := NewActuator(mgr.GetClient(), ctx.Provider())
+	err = addServiceReconciler(mgr, ctx, actuator)
 	if err != nil {
 		return err
 	}
 	// TODO should turn off by default
-	err = addPodReconciler(mgr, ctx)
+	err = addPodReconciler(mgr, ctx, actuator)
 	if err != nil {
 		return err
 	}
+	return mgr.Add(&gcRunner{
+		actuator: actuator,
+		period:   time.Duration(ctrlCfg.CloudCFG.Global.PrivateZoneGCPeriod) * time.Second,
+	})
+}
+
+// gcRunner sweeps the zone periodically to remove the orphan records owned by the cluster
+type gcRunner struct {
+	actuator *Actuator
+	period   time.Duration
+}
+
+// Start function will not be called until the resource lock is acquired
+func (r *gcRunner) Start(ctx context.Context) error {
+	if r.period <= 0 {
+		klog.Infof("pvtz gc is disabled, period: %s", r.period)
+		return nil
+	}
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.actuator.GC(); err != nil {
+			klog.Errorf("pvtz gc error: %s", err.Error())
+		}
+	}, r.period)
 	return nil
 }
 
-func addServiceReconciler(mgr manager.Manager, ctx *shared.SharedContext) error {
+func addServiceReconciler(mgr manager.Manager, ctx *shared.SharedContext, actuator *Actuator) error {
 	r := &ServiceReconciler{
 		cloud:    ctx.Provider(),
 		client:   mgr.GetClient(),
 		scheme:   mgr.GetScheme(),
-		actuator: NewActuator(mgr.GetClient(), ctx.Provider()),
+		actuator: actuator,
 		record:   mgr.GetEventRecorderFor("Pvtz"),
 	}
 
@@ -103,12 +133,12 @@ func (m *ServiceReconciler) Reconcile(ctx context.Context, request reconcile.Req
 	return reconcile.Result{}, err
 }
 
-func addPodReconciler(mgr manager.Manager, ctx *shared.SharedContext) error {
+func addPodReconciler(mgr manager.Manager, ctx *shared.SharedContext, actuator *Actuator) error {
 	r := &PodReconciler{
 		cloud:    ctx.Provider(),
 		client:   mgr.GetClient(),
 		scheme:   mgr.GetScheme(),
-		actuator: NewActuator(mgr.GetClient(), ctx.Provider()),
+		actuator: actuator,
 		record:   mgr.GetEventRecorderFor("Pvtz"),
 	}
 	c, err := controller.New(
@@ -122,7 +152,7 @@ func addPodReconciler(mgr manager.Manager, ctx *shared.SharedContext) error {
 		return err
 	}
 	eventHandler := NewEventHandlerWithClient()
-	err = c.Watch(source.Kind(mgr.GetCache(), &corev1.Pod{}), eventHandler)
+	err = c.Watch(source.Kind(mgr.GetCache(), &corev1.Pod{}), eventHandler, &PodPredicate{})
 	if err != nil {
 		return fmt.Errorf("watch resource: pod, %s", err.Error())
 	}
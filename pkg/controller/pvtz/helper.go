@@ -14,3 +14,16 @@ func serviceRr(svc *corev1.Service) string {
 func serviceRrByName(svcName types.NamespacedName) string {
 	return fmt.Sprintf("%s.%s.svc", svcName.Name, svcName.Namespace)
 }
+
+func podRr(pod *corev1.Pod) string {
+	return fmt.Sprintf("%s.%s.%s.svc", pod.Spec.Hostname, pod.Spec.Subdomain, pod.Namespace)
+}
+
+// podKey is the cache key of the records of a pod, the rr of the records can not be got from the name
+func podKey(pod *corev1.Pod) string {
+	return fmt.Sprintf("%s.%s.pod", pod.Name, pod.Namespace)
+}
+
+func podKeyByName(podName types.NamespacedName) string {
+	return fmt.Sprintf("%s.%s.pod", podName.Name, podName.Namespace)
+}
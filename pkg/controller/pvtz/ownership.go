@@ -0,0 +1,324 @@
+package pvtz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	util_errors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/klog/v2"
+)
+
+// The ownership of records is stored in the zone as TXT records like external-dns does, so that it
+// survives restarts of the controller. For a record with rr "svc.ns.svc", the ownership record is
+// "_ccm-owner.svc.ns.svc" and each value of it is an owner, e.g.
+// "heritage=alibaba-cloud-ccm,ccm/owner=<cluster id>,ccm/resource=service/ns/svc".
+// A prefix is used because CNAME records can not coexist with other records of the same rr.
+const (
+	ownerRecordPrefix = "_ccm-owner."
+	ownerHeritage     = "heritage=alibaba-cloud-ccm"
+	ownerLabelCluster = "ccm/owner"
+	ownerLabelRes     = "ccm/resource"
+
+	ownerKindService = "service"
+	ownerKindPod     = "pod"
+)
+
+type owner struct {
+	cluster string
+	kind    string
+	name    types.NamespacedName
+}
+
+func newOwner(kind string, name types.NamespacedName) owner {
+	return owner{cluster: clusterOwnerID(), kind: kind, name: name}
+}
+
+func clusterOwnerID() string {
+	if ctrlCfg.CloudCFG.Global.ClusterID != "" {
+		return ctrlCfg.CloudCFG.Global.ClusterID
+	}
+	return ctrlCfg.ControllerCFG.ClusterName
+}
+
+func (o owner) String() string {
+	return fmt.Sprintf("%s,%s=%s,%s=%s/%s/%s", ownerHeritage, ownerLabelCluster, o.cluster,
+		ownerLabelRes, o.kind, o.name.Namespace, o.name.Name)
+}
+
+// cacheKey returns the key of the cache of the records owned by o
+func (o owner) cacheKey() string {
+	if o.kind == ownerKindPod {
+		return podKeyByName(o.name)
+	}
+	return serviceRrByName(o.name)
+}
+
+// parseOwner parses the value of an ownership record, false is returned if it is not written by ccm
+func parseOwner(value string) (owner, bool) {
+	o := owner{}
+	parts := strings.Split(strings.Trim(value, "\""), ",")
+	if len(parts) != 3 || parts[0] != ownerHeritage {
+		return o, false
+	}
+	for _, p := range parts[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			return o, false
+		}
+		switch kv[0] {
+		case ownerLabelCluster:
+			o.cluster = kv[1]
+		case ownerLabelRes:
+			res := strings.Split(kv[1], "/")
+			if len(res) != 3 {
+				return o, false
+			}
+			o.kind = res[0]
+			o.name = types.NamespacedName{Namespace: res[1], Name: res[2]}
+		default:
+			return o, false
+		}
+	}
+	if o.kind == "" {
+		return o, false
+	}
+	return o, true
+}
+
+func ownerRr(rr string) string {
+	return ownerRecordPrefix + rr
+}
+
+// ownerRecord returns the ownership record of rr, the values of it are empty if it does not exist
+func (a *Actuator) ownerRecord(rr string) (*model.PvtzEndpoint, error) {
+	eps, err := a.provider.SearchPVTZ(context.TODO(), &model.PvtzEndpoint{Rr: ownerRr(rr)}, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, ep := range eps {
+		if ep.Rr == ownerRr(rr) && ep.Type == model.RecordTypeTXT {
+			return ep, nil
+		}
+	}
+	return &model.PvtzEndpoint{Rr: ownerRr(rr), Type: model.RecordTypeTXT, Values: []model.PvtzValue{}}, nil
+}
+
+// claim records o as an owner of the rr of each endpoint, the rrs claimed by the last call with the
+// same key are skipped to avoid searching the ownership records on each update
+func (a *Actuator) claim(o owner, key string, eps []*model.PvtzEndpoint) error {
+	known := sets.New[string]()
+	if cached, exist := a.claimedMap.Get(key); exist {
+		known = cached.(sets.Set[string])
+	}
+	claimed := sets.New[string]()
+	errs := make([]error, 0)
+	for rr := range endpointRrs(eps) {
+		if known.Has(rr) {
+			claimed.Insert(rr)
+			continue
+		}
+		ep, err := a.ownerRecord(rr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		value := model.PvtzValue{Data: o.String()}
+		if !value.InVals(ep.Values) {
+			ep.Values = append(ep.Values, value)
+			ep.Ttl = ctrlCfg.CloudCFG.Global.PrivateZoneRecordTTL
+			if err := a.provider.UpdatePVTZ(context.TODO(), ep); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		claimed.Insert(rr)
+	}
+	a.claimedMap.Set(key, claimed)
+	return errors.Wrap(util_errors.NewAggregate(errs), "claim ownership error")
+}
+
+// release removes o from the owners of rr, and deletes the records of rr if there is no owner left.
+// Records without ownership record are created by old versions and owned by o.
+func (a *Actuator) release(o owner, rr string) error {
+	// the rr is no longer claimed by o, the ownership records are searched again on the next claim
+	a.claimedMap.Remove(o.cacheKey())
+	ep, err := a.ownerRecord(rr)
+	if err != nil {
+		return err
+	}
+	remains := make([]model.PvtzValue, 0)
+	for _, v := range ep.Values {
+		if v.Data != o.String() {
+			remains = append(remains, v)
+		}
+	}
+	if len(remains) != 0 {
+		if len(remains) != len(ep.Values) {
+			ep.Values = remains
+			return a.provider.UpdatePVTZ(context.TODO(), ep)
+		}
+		// owned by others only
+		return nil
+	}
+	if err := a.deleteRr(rr); err != nil {
+		return err
+	}
+	if len(ep.Values) != 0 {
+		return a.provider.DeletePVTZ(context.TODO(), &model.PvtzEndpoint{Rr: ep.Rr, Type: model.RecordTypeTXT})
+	}
+	return nil
+}
+
+// deleteRr deletes the records of all types of rr
+func (a *Actuator) deleteRr(rr string) error {
+	eps, err := a.provider.SearchPVTZ(context.TODO(), &model.PvtzEndpoint{Rr: rr}, true)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, 0)
+	for _, ep := range eps {
+		if ep.Rr != rr {
+			continue
+		}
+		if err := a.provider.DeletePVTZ(context.TODO(), &model.PvtzEndpoint{Rr: ep.Rr, Type: ep.Type}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return util_errors.NewAggregate(errs)
+}
+
+// ownedRrs lists the zone and returns the rrs owned by each owner of the cluster, it is used by GC only
+// as listing the zone is expensive
+func (a *Actuator) ownedRrs() (map[owner]sets.Set[string], error) {
+	eps, err := a.provider.ListPVTZ(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[owner]sets.Set[string])
+	for _, ep := range eps {
+		if ep.Type != model.RecordTypeTXT || !strings.HasPrefix(ep.Rr, ownerRecordPrefix) {
+			continue
+		}
+		for _, v := range ep.Values {
+			o, ok := parseOwner(v.Data)
+			if !ok || o.cluster != clusterOwnerID() {
+				continue
+			}
+			if owned[o] == nil {
+				owned[o] = sets.New[string]()
+			}
+			owned[o].Insert(strings.TrimPrefix(ep.Rr, ownerRecordPrefix))
+		}
+	}
+	return owned, nil
+}
+
+// deleteOwned releases the rrs owned by o. The rrs are found in cache and by the exact search of the
+// rr of the service, the others, e.g. the records of a pod deleted after a restart, are removed by GC.
+func (a *Actuator) deleteOwned(o owner, key string) error {
+	rrs := sets.New[string]()
+	if cached, exist := a.cacheMap.Get(key); exist {
+		rrs = endpointRrs(cached.([]*model.PvtzEndpoint))
+	}
+	if o.kind == ownerKindService {
+		rr := serviceRrByName(o.name)
+		ep, err := a.ownerRecord(rr)
+		if err != nil {
+			return err
+		}
+		value := model.PvtzValue{Data: o.String()}
+		// records without ownership record are created by old versions, best effort to delete them
+		if value.InVals(ep.Values) || (len(ep.Values) == 0 && rrs.Len() == 0) {
+			rrs.Insert(rr)
+		}
+	}
+	errs := make([]error, 0)
+	for rr := range rrs {
+		if err := a.release(o, rr); err != nil {
+			klog.Errorf("release pvtz record %s error %s", rr, err.Error())
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		a.cacheMap.Remove(key)
+		a.claimedMap.Remove(key)
+	}
+	return util_errors.NewAggregate(errs)
+}
+
+// GC removes the records owned by the cluster but no longer desired, e.g. the records of the services
+// deleted while the controller is down.
+func (a *Actuator) GC() error {
+	owned, err := a.ownedRrs()
+	if err != nil {
+		return errors.Wrap(err, "list pvtz error")
+	}
+	errs := make([]error, 0)
+	for o, rrs := range owned {
+		desired, err := a.desiredRrs(o)
+		if err != nil {
+			klog.Errorf("pvtz gc: get desired records of %s error %s", o.String(), err.Error())
+			continue
+		}
+		for rr := range rrs.Difference(desired) {
+			klog.Infof("pvtz gc: removing record %s of %s/%s", rr, o.kind, o.name)
+			if err := a.release(o, rr); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Wrap(util_errors.NewAggregate(errs), "pvtz gc error")
+}
+
+func (a *Actuator) desiredRrs(o owner) (sets.Set[string], error) {
+	var eps []*model.PvtzEndpoint
+	switch o.kind {
+	case ownerKindService:
+		svc := &corev1.Service{}
+		err := a.client.Get(context.TODO(), o.name, svc)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return sets.New[string](), nil
+			}
+			return nil, err
+		}
+		eps, err = a.desiredService(svc)
+		if err != nil {
+			return nil, err
+		}
+	case ownerKindPod:
+		pod := &corev1.Pod{}
+		err := a.client.Get(context.TODO(), o.name, pod)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return sets.New[string](), nil
+			}
+			return nil, err
+		}
+		eps, err = a.desiredPod(pod)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown owner kind %s", o.kind)
+	}
+	return endpointRrs(eps), nil
+}
+
+func endpointRrs(eps []*model.PvtzEndpoint) sets.Set[string] {
+	rrs := sets.New[string]()
+	for _, ep := range eps {
+		if ep != nil {
+			rrs.Insert(ep.Rr)
+		}
+	}
+	return rrs
+}
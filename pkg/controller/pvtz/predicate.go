@@ -3,6 +3,7 @@ package pvtz
 import (
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -31,3 +32,29 @@ func (sp *ServicePredicate) Create(e event.CreateEvent) bool {
 func (sp *ServicePredicate) Update(e event.UpdateEvent) bool {
 	return sp.filterLeaseEvents(e.ObjectOld)
 }
+
+// PodPredicate filters the pods without hostname or subdomain, no record is desired for them
+type PodPredicate struct {
+	predicate.Funcs
+}
+
+func (pp *PodPredicate) hasRecords(obj client.Object) bool {
+	pod, ok := obj.(*corev1.Pod)
+	return ok && pod.Spec.Hostname != "" && pod.Spec.Subdomain != ""
+}
+
+func (pp *PodPredicate) Create(e event.CreateEvent) bool {
+	return pp.hasRecords(e.Object)
+}
+
+func (pp *PodPredicate) Update(e event.UpdateEvent) bool {
+	return pp.hasRecords(e.ObjectOld) || pp.hasRecords(e.ObjectNew)
+}
+
+func (pp *PodPredicate) Delete(e event.DeleteEvent) bool {
+	return pp.hasRecords(e.Object)
+}
+
+func (pp *PodPredicate) Generic(e event.GenericEvent) bool {
+	return pp.hasRecords(e.Object)
+}
@@ -37,6 +37,15 @@ func reverseIPv6(ip string) string {
 	return strings.Join(ipS, ".")
 }
 
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
 type NamedPortMap map[string]map[string]int32
 
 func NewNamedPortMap(ep *corev1.Endpoints) NamedPortMap {
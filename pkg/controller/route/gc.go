@@ -0,0 +1,187 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+	"k8s.io/klog/v2"
+)
+
+// maxDescribeInstances is the max number of instance ids in a DescribeInstances request
+const maxDescribeInstances = 100
+
+// gcStaleRoutes deletes the routes in the cluster cidr whose next hop instances are not nodes of the cluster,
+// e.g. the routes left by the deleted nodes. A route is deleted after it has been stale for the grace period,
+// the routes whose next hop instances belong to other clusters in the same vpc are kept.
+func (r *ReconcileRoute) gcStaleRoutes(ctx context.Context, table string, clusterCIDRs []*net.IPNet, routes []*model.Route) {
+	gracePeriod := ctrlCfg.ControllerCFG.RouteGCGracePeriod
+	if gracePeriod <= 0 || len(clusterCIDRs) == 0 {
+		// the routes of the vpc can not be told apart without the cluster cidr
+		return
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(ctx, nodes); err != nil {
+		klog.Errorf("route gc: error listing nodes: %s", err.Error())
+		return
+	}
+	candidates := findStaleRoutes(routes, clusterCIDRs, nodes)
+
+	var ids []string
+	for _, route := range candidates {
+		ids = append(ids, route.ProviderId)
+	}
+	instances, err := r.listInstances(ctx, ids)
+	if err != nil {
+		klog.Errorf("route gc: error listing next hop instances of routes in table %s: %s", table, err.Error())
+		return
+	}
+
+	if r.staleRoutes == nil {
+		r.staleRoutes = map[string]time.Time{}
+	}
+	now := time.Now()
+	stale := map[string]bool{}
+	for _, route := range candidates {
+		if ins := instances[route.ProviderId]; ins != nil && ownedByOtherCluster(ins) {
+			klog.V(4).Infof("route gc: skip route %s in table %s, next hop instance belongs to another cluster", route.Name, table)
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s", table, route.Name)
+		stale[key] = true
+		ref := routeEventReference(table, route, nodes)
+		firstSeen, ok := r.staleRoutes[key]
+		if !ok {
+			r.staleRoutes[key] = now
+			klog.Infof("route gc: found stale route %s %s -> %s in table %s, next hop instance is not a node of the cluster, "+
+				"it will be deleted after %s", route.Name, route.DestinationCIDR, route.ProviderId, table, gracePeriod)
+			r.record.Eventf(ref, corev1.EventTypeNormal, helper.StaleRoute,
+				"Found stale route %s -> %s in %s, it will be deleted after %s", route.DestinationCIDR, route.ProviderId, table, gracePeriod)
+			metric.RouteGC.WithLabelValues(metric.ResultStale).Inc()
+			continue
+		}
+		if now.Sub(firstSeen) < gracePeriod {
+			continue
+		}
+
+		if ctrlCfg.ControllerCFG.RouteGCDryRun {
+			klog.Infof("route gc: dry run, skip deleting stale route %s %s -> %s in table %s",
+				route.Name, route.DestinationCIDR, route.ProviderId, table)
+			r.record.Eventf(ref, corev1.EventTypeNormal, helper.StaleRoute,
+				"Stale route %s -> %s in %s is not deleted in dry run mode", route.DestinationCIDR, route.ProviderId, table)
+			metric.RouteGC.WithLabelValues(metric.ResultDryRun).Inc()
+			continue
+		}
+		if err := deleteRouteForInstance(ctx, table, route.ProviderId, route.DestinationCIDR, r.cloud); err != nil {
+			klog.Errorf("route gc: error deleting stale route %s %s -> %s in table %s: %s",
+				route.Name, route.DestinationCIDR, route.ProviderId, table, err.Error())
+			r.record.Eventf(ref, corev1.EventTypeWarning, helper.FailedDeleteRoute,
+				"Error deleting stale route %s -> %s in %s: %s", route.DestinationCIDR, route.ProviderId, table, helper.GetLogMessage(err))
+			metric.RouteGC.WithLabelValues(metric.ResultFail).Inc()
+			continue
+		}
+		klog.Infof("route gc: deleted stale route %s %s -> %s in table %s", route.Name, route.DestinationCIDR, route.ProviderId, table)
+		r.record.Eventf(ref, corev1.EventTypeNormal, helper.SucceedDeleteRoute,
+			"Deleted stale route %s -> %s in %s", route.DestinationCIDR, route.ProviderId, table)
+		metric.RouteGC.WithLabelValues(metric.ResultSuccess).Inc()
+		delete(stale, key)
+	}
+
+	// forget the routes which are deleted or not stale anymore
+	for key := range r.staleRoutes {
+		if strings.HasPrefix(key, table+"/") && !stale[key] {
+			delete(r.staleRoutes, key)
+		}
+	}
+}
+
+// findStaleRoutes returns the routes in the cluster cidrs whose next hop instances are not nodes of the cluster.
+func findStaleRoutes(routes []*model.Route, clusterCIDRs []*net.IPNet, nodes *corev1.NodeList) []*model.Route {
+	instances := map[string]bool{}
+	for _, node := range nodes.Items {
+		if _, ins, err := util.NodeFromProviderID(node.Spec.ProviderID); err == nil {
+			instances[ins] = true
+		}
+	}
+
+	var stale []*model.Route
+	for _, route := range routes {
+		contains, err := containsRouteInCluster(clusterCIDRs, route.DestinationCIDR)
+		if err != nil || !contains {
+			continue
+		}
+		_, ins, err := util.NodeFromProviderID(route.ProviderId)
+		if err != nil || instances[ins] {
+			continue
+		}
+		stale = append(stale, route)
+	}
+	return stale
+}
+
+// routeEventReference returns the node whose pod cidr is the destination of the route if the node still exists,
+// e.g. the node is registered again with a new instance, otherwise the route table in kube-system as the events
+// of the routes are cluster-wide.
+func routeEventReference(table string, route *model.Route, nodes *corev1.NodeList) *corev1.ObjectReference {
+	for _, node := range nodes.Items {
+		cidrs := node.Spec.PodCIDRs
+		if len(cidrs) == 0 && node.Spec.PodCIDR != "" {
+			cidrs = []string{node.Spec.PodCIDR}
+		}
+		for _, cidr := range cidrs {
+			if cidr == route.DestinationCIDR {
+				return &corev1.ObjectReference{
+					Kind:      "Node",
+					Name:      node.Name,
+					UID:       node.UID,
+					Namespace: "",
+				}
+			}
+		}
+	}
+	return &corev1.ObjectReference{
+		Kind:      "RouteTable",
+		Name:      table,
+		Namespace: metav1.NamespaceSystem,
+	}
+}
+
+// ownedByOtherCluster returns true if the instance is tagged with the id of another cluster.
+func ownedByOtherCluster(ins *prvd.NodeAttribute) bool {
+	clusterID, ok := ins.Tags[ctrlCfg.CloudCFG.GetKubernetesClusterTag()]
+	if !ok || clusterID == "" {
+		return false
+	}
+	return clusterID != ctrlCfg.CloudCFG.Global.ClusterID
+}
+
+func (r *ReconcileRoute) listInstances(ctx context.Context, ids []string) (map[string]*prvd.NodeAttribute, error) {
+	instances := map[string]*prvd.NodeAttribute{}
+	for len(ids) > 0 {
+		batch := ids
+		if len(batch) > maxDescribeInstances {
+			batch = ids[:maxDescribeInstances]
+		}
+		ids = ids[len(batch):]
+		ins, err := r.cloud.ListInstances(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for id, attr := range ins {
+			instances[id] = attr
+		}
+	}
+	return instances, nil
+}
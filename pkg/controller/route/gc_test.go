@@ -0,0 +1,178 @@
+package route
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+)
+
+func TestFindStaleRoutes(t *testing.T) {
+	_, clusterCIDR, _ := net.ParseCIDR("172.16.0.0/16")
+	nodes := &v1.NodeList{Items: []v1.Node{
+		{Spec: v1.NodeSpec{ProviderID: "alicloud://cn-hangzhou.i-a", PodCIDR: "172.16.0.0/24"}},
+	}}
+	routes := []*model.Route{
+		{Name: "a", DestinationCIDR: "172.16.0.0/24", ProviderId: "cn-hangzhou.i-a"},
+		{Name: "b", DestinationCIDR: "172.16.1.0/24", ProviderId: "cn-hangzhou.i-b"},
+		{Name: "c", DestinationCIDR: "192.168.0.0/24", ProviderId: "cn-hangzhou.i-c"},
+	}
+
+	stale := findStaleRoutes(routes, []*net.IPNet{clusterCIDR}, nodes)
+	assert.Len(t, stale, 1)
+	assert.Equal(t, "b", stale[0].Name)
+}
+
+func TestOwnedByOtherCluster(t *testing.T) {
+	ctrlCfg.CloudCFG.Global.ClusterID = "c-1"
+	defer func() {
+		ctrlCfg.CloudCFG.Global.ClusterID = ""
+	}()
+	tagKey := ctrlCfg.CloudCFG.GetKubernetesClusterTag()
+
+	assert.False(t, ownedByOtherCluster(&prvd.NodeAttribute{}))
+	assert.False(t, ownedByOtherCluster(&prvd.NodeAttribute{Tags: map[string]string{tagKey: "c-1"}}))
+	assert.True(t, ownedByOtherCluster(&prvd.NodeAttribute{Tags: map[string]string{tagKey: "c-2"}}))
+}
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	assert.NoError(t, c.Write(m))
+	return m.GetCounter().GetValue()
+}
+
+func TestGCStaleRoutes(t *testing.T) {
+	gracePeriod, dryRun := ctrlCfg.ControllerCFG.RouteGCGracePeriod, ctrlCfg.ControllerCFG.RouteGCDryRun
+	ctrlCfg.CloudCFG.Global.ClusterID = "c-1"
+	defer func() {
+		ctrlCfg.ControllerCFG.RouteGCGracePeriod, ctrlCfg.ControllerCFG.RouteGCDryRun = gracePeriod, dryRun
+		ctrlCfg.CloudCFG.Global.ClusterID = ""
+	}()
+	ctrlCfg.ControllerCFG.RouteGCGracePeriod = time.Hour
+	ctrlCfg.ControllerCFG.RouteGCDryRun = false
+
+	cloud := fakecloud.NewFakeCloud(nil)
+	cloud.AddInstance(&prvd.NodeAttribute{InstanceID: "i-other",
+		Tags: map[string]string{ctrlCfg.CloudCFG.GetKubernetesClusterTag(): "c-2"}})
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileRoute{
+		cloud:  cloud,
+		client: getFakeKubeClient(),
+		record: recorder,
+	}
+	ctx := context.TODO()
+	table := fakecloud.DefaultRouteTableID
+	_, clusterCIDR, _ := net.ParseCIDR("10.96.0.0/16")
+	for provider, cidr := range map[string]string{
+		// the route of a node of the cluster
+		"cn-hangzhou.ecs-id": "10.96.0.0/26",
+		// the route of a deleted node
+		"cn-hangzhou.i-stale": "10.96.1.0/26",
+		// the route of a node of another cluster in the same vpc
+		"cn-hangzhou.i-other": "10.96.2.0/26",
+		// the route out of the cluster cidr
+		"cn-hangzhou.i-out": "192.168.0.0/26",
+	} {
+		_, err := cloud.CreateRoute(ctx, table, provider, cidr)
+		assert.NoError(t, err)
+	}
+	gc := func() []string {
+		routes, err := cloud.ListRoute(ctx, table)
+		assert.NoError(t, err)
+		r.gcStaleRoutes(ctx, table, []*net.IPNet{clusterCIDR}, routes)
+		routes, err = cloud.ListRoute(ctx, table)
+		assert.NoError(t, err)
+		var cidrs []string
+		for _, route := range routes {
+			cidrs = append(cidrs, route.DestinationCIDR)
+		}
+		return cidrs
+	}
+	all := []string{"10.96.0.0/26", "10.96.1.0/26", "10.96.2.0/26", "192.168.0.0/26"}
+	stale := metric.RouteGC.WithLabelValues(metric.ResultStale)
+	dryRunKept := metric.RouteGC.WithLabelValues(metric.ResultDryRun)
+	deleted := metric.RouteGC.WithLabelValues(metric.ResultSuccess)
+	staleFound, dryRunFound, deletedFound := counterValue(t, stale), counterValue(t, dryRunKept), counterValue(t, deleted)
+
+	// only the route of the deleted node is stale, it is kept in the grace period
+	assert.ElementsMatch(t, all, gc())
+	assert.Len(t, r.staleRoutes, 1)
+	assert.Equal(t, staleFound+1, counterValue(t, stale))
+	assert.Contains(t, <-recorder.Events, helper.StaleRoute)
+	assert.ElementsMatch(t, all, gc())
+	assert.Equal(t, staleFound+1, counterValue(t, stale))
+	assert.Len(t, recorder.Events, 0)
+
+	// the route is kept after the grace period in dry run mode
+	for key := range r.staleRoutes {
+		r.staleRoutes[key] = time.Now().Add(-2 * time.Hour)
+	}
+	ctrlCfg.ControllerCFG.RouteGCDryRun = true
+	assert.ElementsMatch(t, all, gc())
+	assert.Equal(t, dryRunFound+1, counterValue(t, dryRunKept))
+	assert.Contains(t, <-recorder.Events, "dry run")
+	assert.Len(t, r.staleRoutes, 1)
+
+	// the route is deleted after the grace period and forgotten
+	ctrlCfg.ControllerCFG.RouteGCDryRun = false
+	assert.ElementsMatch(t, []string{"10.96.0.0/26", "10.96.2.0/26", "192.168.0.0/26"}, gc())
+	assert.Equal(t, deletedFound+1, counterValue(t, deleted))
+	assert.Contains(t, <-recorder.Events, helper.SucceedDeleteRoute)
+	assert.Empty(t, r.staleRoutes)
+
+	// the gc is disabled without the grace period
+	ctrlCfg.ControllerCFG.RouteGCGracePeriod = 0
+	_, err := cloud.CreateRoute(ctx, table, "cn-hangzhou.i-stale", "10.96.1.0/26")
+	assert.NoError(t, err)
+	listInstances := cloud.CallCount("ListInstances")
+	assert.Len(t, gc(), 4)
+	assert.Equal(t, listInstances, cloud.CallCount("ListInstances"))
+	assert.Empty(t, r.staleRoutes)
+}
+
+func TestRouteEventReference(t *testing.T) {
+	nodes := &v1.NodeList{Items: []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "uid-1"}, Spec: v1.NodeSpec{PodCIDR: "10.96.0.0/26"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2", UID: "uid-2"}, Spec: v1.NodeSpec{PodCIDRs: []string{"10.96.1.0/26", "fd00::/120"}}},
+	}}
+	cases := []struct {
+		name      string
+		cidr      string
+		expectRef v1.ObjectReference
+	}{
+		{
+			name:      "pod cidr of a node",
+			cidr:      "10.96.0.0/26",
+			expectRef: v1.ObjectReference{Kind: "Node", Name: "node-1", UID: "uid-1"},
+		},
+		{
+			name:      "one of the pod cidrs of a node",
+			cidr:      "fd00::/120",
+			expectRef: v1.ObjectReference{Kind: "Node", Name: "node-2", UID: "uid-2"},
+		},
+		{
+			name:      "the node is deleted",
+			cidr:      "10.96.2.0/26",
+			expectRef: v1.ObjectReference{Kind: "RouteTable", Name: "vtb-1", Namespace: metav1.NamespaceSystem},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ref := routeEventReference("vtb-1", &model.Route{DestinationCIDR: c.cidr}, nodes)
+			assert.Equal(t, c.expectRef, *ref)
+		})
+	}
+}
@@ -98,18 +98,16 @@ func (r *ReconcileRoute) syncTableRoutes(ctx context.Context, table string, node
 		return fmt.Errorf("error listing routes: %v", err)
 	}
 
-	var clusterCIDR *net.IPNet
-	if ctrlCfg.ControllerCFG.ClusterCIDR != "" {
-		_, clusterCIDR, err = net.ParseCIDR(ctrlCfg.ControllerCFG.ClusterCIDR)
-		if err != nil {
-			return fmt.Errorf("error parse cluster cidr %s: %s", ctrlCfg.ControllerCFG.ClusterCIDR, err)
-		}
+	clusterCIDRs, err := parseClusterCIDRs(ctrlCfg.ControllerCFG.ClusterCIDR)
+	if err != nil {
+		return err
 	}
 
+	var remains []*model.Route
 	for _, route := range routes {
-		contains, _, err := containsRoute(clusterCIDR, route.DestinationCIDR)
+		contains, err := containsRouteInCluster(clusterCIDRs, route.DestinationCIDR)
 		if err != nil {
-			klog.Errorf("error contains route %v <- %v, error %v ", clusterCIDR, route.DestinationCIDR, err)
+			klog.Errorf("error contains route %v <- %v, error %v ", clusterCIDRs, route.DestinationCIDR, err)
 			continue
 		}
 		if !contains {
@@ -121,9 +119,13 @@ func (r *ReconcileRoute) syncTableRoutes(ctx context.Context, table string, node
 				continue
 			}
 			klog.Infof("Delete conflict route %s, %s from table %s SUCCESS.", route.Name, route.DestinationCIDR, table)
+			continue
 		}
+		remains = append(remains, route)
 	}
 
+	r.gcStaleRoutes(ctx, table, clusterCIDRs, remains)
+
 	for _, node := range nodes.Items {
 		if !needSyncRoute(&node) {
 			continue
@@ -134,13 +136,20 @@ func (r *ReconcileRoute) syncTableRoutes(ctx context.Context, table string, node
 			continue
 		}
 
-		_, ipv4RouteCidr, err := getIPv4RouteForNode(&node)
-		if err != nil || ipv4RouteCidr == "" {
+		podCIDRs, err := getRoutesForNode(&node)
+		if err != nil || len(podCIDRs) == 0 {
 			continue
 		}
 
-		err = r.addRouteForNode(ctx, table, ipv4RouteCidr, prvdId, &node, routes)
-		if err != nil {
+		// the node is available only if the routes of all ip families are created
+		var routeErr error
+		for _, podCIDR := range podCIDRs {
+			if err = r.addRouteForNode(ctx, table, podCIDR.String(), prvdId, &node, routes); err != nil {
+				klog.Errorf("add %s route for node %s error: %s", ipFamilyOf(podCIDR), node.Name, err.Error())
+				routeErr = err
+			}
+		}
+		if routeErr != nil {
 			continue
 		}
 
@@ -153,24 +162,22 @@ func (r *ReconcileRoute) syncTableRoutes(ctx context.Context, table string, node
 
 func conflictWithNodes(route *model.Route, nodes *v1.NodeList) bool {
 	for _, node := range nodes.Items {
-		ipv4Cidr, _, err := getIPv4RouteForNode(&node)
+		podCIDRs, err := getRoutesForNode(&node)
 		if err != nil {
-			klog.Errorf("error get ipv4 cidr from node: %v", node.Name)
+			klog.Errorf("error get pod cidrs from node: %v", node.Name)
 			continue
 		}
-		if ipv4Cidr == nil {
-			continue
-		}
-		equal, contains, err := containsRoute(ipv4Cidr, route.DestinationCIDR)
-		if err != nil {
-			klog.Errorf("error get conflict state from node: %v and route: %v", node.Name, route)
-			continue
-		}
-		if contains || (equal && route.ProviderId != node.Spec.ProviderID) {
-			klog.Warningf("conflict route with node %v(%v) found, route: %+v", node.Name, ipv4Cidr, route)
-			return true
+		for _, podCIDR := range podCIDRs {
+			equal, contains, err := containsRoute(podCIDR, route.DestinationCIDR)
+			if err != nil {
+				klog.Errorf("error get conflict state from node: %v and route: %v", node.Name, route)
+				continue
+			}
+			if contains || (equal && route.ProviderId != node.Spec.ProviderID) {
+				klog.Warningf("conflict route with node %v(%v) found, route: %+v", node.Name, podCIDR, route)
+				return true
+			}
 		}
-
 	}
 	return false
 }
@@ -270,7 +277,10 @@ func (r *ReconcileRoute) batchAddRoutes(ctx context.Context, reconcileID string,
 		return err
 	}
 
-	for _, s := range statuses {
+	// a dual-stack node has a route for each ip family, it is available only if none of its routes failed
+	failedNodes := map[string]bool{}
+	for i := range statuses {
+		s := &statuses[i]
 		if s.FailedCode == "VPC_ROUTE_ENTRY_CIDR_BLOCK_DUPLICATE" {
 			log.Info("route already exists, ignore create error", "route", s.Route.Name, "reconcileID", reconcileID)
 			s.Failed = false
@@ -283,22 +293,11 @@ func (r *ReconcileRoute) batchAddRoutes(ctx context.Context, reconcileID string,
 		}
 
 		if !s.Failed {
-			err = r.updateNetworkingCondition(ctx, s.Route.NodeReference, true)
-			if err != nil {
-				log.Error(err, "update node network condition error",
-					"node", s.Route.NodeReference.Name, "reconcileID", reconcileID)
-				continue
-			}
-
-			r.nodeCache.SetIfAbsent(s.Route.NodeReference.Name, s.Route)
-			r.rateLimiter.Forget(reconcile.Request{
-				NamespacedName: types.NamespacedName{
-					Name: s.Route.NodeReference.Name,
-				},
-			})
+			r.cacheRoute(s.Route.NodeReference.Name, s.Route)
 			continue
 		}
 
+		failedNodes[s.Route.NodeReference.Name] = true
 		log.Info("error creating route entry, requeue",
 			"node", s.Route.NodeReference.Name, "route", s.Route.DestinationCIDR, "table", table,
 			"message", s.FailedMessage, "code", s.FailedCode, "reconcileID", reconcileID)
@@ -307,6 +306,26 @@ func (r *ReconcileRoute) batchAddRoutes(ctx context.Context, reconcileID string,
 		r.requeueNode(s.Route.NodeReference)
 	}
 
+	updatedNodes := map[string]bool{}
+	for _, s := range statuses {
+		name := s.Route.NodeReference.Name
+		if failedNodes[name] || updatedNodes[name] {
+			continue
+		}
+		updatedNodes[name] = true
+		err = r.updateNetworkingCondition(ctx, s.Route.NodeReference, true)
+		if err != nil {
+			log.Error(err, "update node network condition error",
+				"node", name, "reconcileID", reconcileID)
+			continue
+		}
+		r.rateLimiter.Forget(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: name,
+			},
+		})
+	}
+
 	return nil
 }
 
@@ -328,7 +347,7 @@ func (r *ReconcileRoute) batchDeleteRoutes(ctx context.Context, reconcileID stri
 		}
 
 		if !s.Failed {
-			r.nodeCache.Remove(s.Route.NodeReference.Name)
+			r.uncacheRoute(s.Route.NodeReference.Name, s.Route)
 			r.rateLimiter.Forget(reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name: s.Route.NodeReference.Name,
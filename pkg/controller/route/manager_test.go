@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	globalCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
@@ -139,3 +141,27 @@ func TestFindRouteCached(t *testing.T) {
 		assert.Equal(t, testcase.err, err != nil)
 	}
 }
+
+func TestConflictWithNodes(t *testing.T) {
+	nodes := &v1.NodeList{Items: []v1.Node{{
+		Spec: v1.NodeSpec{
+			ProviderID: "cn-hangzhou.i-a",
+			PodCIDR:    "172.16.0.0/24",
+			PodCIDRs:   []string{"172.16.0.0/24", "fd00::/80"},
+		},
+	}}}
+
+	assert.True(t, conflictWithNodes(&model.Route{DestinationCIDR: "172.16.0.0/24", ProviderId: "cn-hangzhou.i-b"}, nodes))
+	assert.False(t, conflictWithNodes(&model.Route{DestinationCIDR: "172.16.0.0/24", ProviderId: "cn-hangzhou.i-a"}, nodes))
+	// ipv6 routes are only checked if IPv6DualStack is enabled
+	assert.False(t, conflictWithNodes(&model.Route{DestinationCIDR: "fd00::/80", ProviderId: "cn-hangzhou.i-b"}, nodes))
+
+	_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(globalCtx.IPv6DualStack): true})
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(globalCtx.IPv6DualStack): false})
+	}()
+	assert.True(t, conflictWithNodes(&model.Route{DestinationCIDR: "fd00::/80", ProviderId: "cn-hangzhou.i-b"}, nodes))
+	assert.True(t, conflictWithNodes(&model.Route{DestinationCIDR: "fd00::/96", ProviderId: "cn-hangzhou.i-a"}, nodes))
+	assert.False(t, conflictWithNodes(&model.Route{DestinationCIDR: "fd00::/80", ProviderId: "cn-hangzhou.i-a"}, nodes))
+	assert.False(t, conflictWithNodes(&model.Route{DestinationCIDR: "fd00::1:0:0:0/80", ProviderId: "cn-hangzhou.i-b"}, nodes))
+}
@@ -92,6 +92,7 @@ func newReconciler(mgr manager.Manager, ctx *shared.SharedContext, requeue chan<
 		scheme:          mgr.GetScheme(),
 		record:          mgr.GetEventRecorderFor("route-controller"),
 		nodeCache:       cmap.New(),
+		staleRoutes:     map[string]time.Time{},
 		configRoutes:    ctrlCfg.ControllerCFG.ConfigureCloudRoutes,
 		reconcilePeriod: ctrlCfg.ControllerCFG.RouteReconciliationPeriod.Duration,
 		requeueChan:     requeue,
@@ -131,6 +132,9 @@ type ReconcileRoute struct {
 	configRoutes    bool
 
 	nodeCache cmap.ConcurrentMap
+	// staleRoutes records when the routes whose next hop instances are not nodes of the cluster were found,
+	// keyed by table and route name. It is only accessed by the periodical sync.
+	staleRoutes map[string]time.Time
 
 	//record event recorder
 	record record.EventRecorder
@@ -226,8 +230,8 @@ func (r *ReconcileRoute) syncCloudRoute(ctx context.Context, node *corev1.Node)
 		return nil
 	}
 
-	_, ipv4RouteCidr, err := getIPv4RouteForNode(node)
-	if err != nil || ipv4RouteCidr == "" {
+	podCIDRs, err := getRoutesForNode(node)
+	if err != nil || len(podCIDRs) == 0 {
 		klog.Warningf("node %s parse podCIDR %s error, skip creating route", node.Name, node.Spec.PodCIDR)
 		if err1 := r.updateNetworkingCondition(ctx, node, false); err1 != nil {
 			klog.Errorf("route, update network condition error: %v", err1)
@@ -241,7 +245,10 @@ func (r *ReconcileRoute) syncCloudRoute(ctx context.Context, node *corev1.Node)
 	}
 	var tablesErr []error
 	for _, table := range tables {
-		tablesErr = append(tablesErr, r.addRouteForNode(ctx, table, ipv4RouteCidr, prvdId, node, nil))
+		// the node is available only if the routes of all ip families are created
+		for _, podCIDR := range podCIDRs {
+			tablesErr = append(tablesErr, r.addRouteForNode(ctx, table, podCIDR.String(), prvdId, node, nil))
+		}
 	}
 	if utilerrors.NewAggregate(tablesErr) != nil {
 		err := r.updateNetworkingCondition(ctx, node, false)
@@ -255,7 +262,7 @@ func (r *ReconcileRoute) syncCloudRoute(ctx context.Context, node *corev1.Node)
 }
 
 func (r *ReconcileRoute) addRouteForNode(
-	ctx context.Context, table, ipv4Cidr, prvdId string, node *corev1.Node, cachedRouteEntry []*model.Route,
+	ctx context.Context, table, podCidr, prvdId string, node *corev1.Node, cachedRouteEntry []*model.Route,
 ) error {
 	var err error
 	nodeRef := &corev1.ObjectReference{
@@ -265,7 +272,7 @@ func (r *ReconcileRoute) addRouteForNode(
 		Namespace: "",
 	}
 
-	route, findErr := findRoute(ctx, table, prvdId, ipv4Cidr, cachedRouteEntry, r.cloud)
+	route, findErr := findRoute(ctx, table, prvdId, podCidr, cachedRouteEntry, r.cloud)
 	if findErr != nil {
 		klog.Errorf("error found exist route for instance: %v, %v", prvdId, findErr)
 		r.record.Event(
@@ -278,10 +285,10 @@ func (r *ReconcileRoute) addRouteForNode(
 	}
 
 	// route not found, try to create route
-	if route == nil || route.DestinationCIDR != ipv4Cidr {
-		klog.Infof("create routes for node %s: %v - %v", node.Name, prvdId, ipv4Cidr)
+	if route == nil || route.DestinationCIDR != podCidr {
+		klog.Infof("create routes for node %s: %v - %v", node.Name, prvdId, podCidr)
 		start := time.Now()
-		route, err = createRouteForInstance(ctx, table, prvdId, ipv4Cidr, r.cloud)
+		route, err = createRouteForInstance(ctx, table, prvdId, podCidr, r.cloud)
 		if err != nil {
 			klog.Errorf("error create route for node %v : instance id [%v], route [%v], err: %s", node.Name, prvdId, table, err.Error())
 			r.record.Event(
@@ -291,22 +298,64 @@ func (r *ReconcileRoute) addRouteForNode(
 				fmt.Sprintf("Error creating route entry in %s: %s", table, helper.GetLogMessage(err)),
 			)
 		} else {
-			klog.Infof("Created route for %s with %s - %s successfully", table, node.Name, ipv4Cidr)
+			klog.Infof("Created route for %s with %s - %s successfully", table, node.Name, podCidr)
 			r.record.Event(
 				nodeRef,
 				corev1.EventTypeNormal,
 				helper.SucceedCreateRoute,
-				fmt.Sprintf("Created route for %s with %s -> %s successfully", table, node.Name, ipv4Cidr),
+				fmt.Sprintf("Created route for %s with %s -> %s successfully", table, node.Name, podCidr),
 			)
 		}
 		metric.RouteLatency.WithLabelValues("create").Observe(metric.MsSince(start))
 	}
 	if route != nil {
-		r.nodeCache.SetIfAbsent(node.Name, route)
+		r.cacheRoute(node.Name, route)
 	}
 	return err
 }
 
+// cacheRoute caches the route of the node, a dual-stack node has a route for each ip family.
+func (r *ReconcileRoute) cacheRoute(nodeName string, route *model.Route) {
+	r.nodeCache.Upsert(nodeName, route, func(exist bool, valueInMap interface{}, newValue interface{}) interface{} {
+		newRoute := newValue.(*model.Route)
+		routes, _ := valueInMap.([]*model.Route)
+		for _, cached := range routes {
+			if cached.DestinationCIDR == newRoute.DestinationCIDR {
+				return routes
+			}
+		}
+		return append(append([]*model.Route(nil), routes...), newRoute)
+	})
+}
+
+// uncacheRoute removes the route of the node from the cache, the node is removed once it has no routes.
+func (r *ReconcileRoute) uncacheRoute(nodeName string, route *model.Route) {
+	r.nodeCache.Upsert(nodeName, nil, func(exist bool, valueInMap interface{}, newValue interface{}) interface{} {
+		routes, _ := valueInMap.([]*model.Route)
+		var remains []*model.Route
+		for _, cached := range routes {
+			if cached.DestinationCIDR != route.DestinationCIDR {
+				remains = append(remains, cached)
+			}
+		}
+		return remains
+	})
+	r.nodeCache.RemoveCb(nodeName, func(key string, v interface{}, exists bool) bool {
+		routes, _ := v.([]*model.Route)
+		return exists && len(routes) == 0
+	})
+}
+
+// cachedRoutes returns the cached routes of the node.
+func (r *ReconcileRoute) cachedRoutes(nodeName string) []*model.Route {
+	o, ok := r.nodeCache.Get(nodeName)
+	if !ok {
+		return nil
+	}
+	routes, _ := o.([]*model.Route)
+	return routes
+}
+
 func (r *ReconcileRoute) updateNetworkingCondition(ctx context.Context, node *corev1.Node, routeCreated bool) error {
 	networkCondition, ok := helper.FindCondition(node.Status.Conditions, corev1.NodeNetworkUnavailable)
 	if routeCreated && ok && networkCondition.Status == corev1.ConditionFalse {
@@ -424,19 +473,17 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 		if err != nil {
 			// todo: check deletion timestamp
 			if errors.IsNotFound(err) {
-				if o, ok := r.nodeCache.Get(request.Name); ok {
-					if route, ok := o.(*model.Route); ok {
-						toDelete = append(toDelete, &model.Route{
-							Name:            route.Name,
-							DestinationCIDR: route.DestinationCIDR,
-							ProviderId:      route.ProviderId,
-							NodeReference: &corev1.Node{
-								ObjectMeta: metav1.ObjectMeta{
-									Name: request.Name,
-								},
+				for _, route := range r.cachedRoutes(request.Name) {
+					toDelete = append(toDelete, &model.Route{
+						Name:            route.Name,
+						DestinationCIDR: route.DestinationCIDR,
+						ProviderId:      route.ProviderId,
+						NodeReference: &corev1.Node{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: request.Name,
 							},
-						})
-					}
+						},
+					})
 				}
 				// node not found, ignore
 				continue
@@ -463,8 +510,8 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 
 		}
 
-		_, ipv4RouteCidr, err := getIPv4RouteForNode(n)
-		if err != nil || ipv4RouteCidr == "" {
+		podCIDRs, err := getRoutesForNode(n)
+		if err != nil || len(podCIDRs) == 0 {
 			log.Error(err, "node parse podCIDR error, skip creating route",
 				"node", n.Name, "cidr", n.Spec.PodCIDR, "reconcileID", reconcileID)
 			if err1 := r.updateNetworkingCondition(ctx, n, false); err1 != nil {
@@ -498,35 +545,41 @@ func (r *ReconcileRoute) batchSyncCloudRoutes(ctx context.Context, reconcileID s
 	}
 
 	for _, n := range toAddedNodes {
+		podCIDRs, _ := getRoutesForNode(n)
 		for _, table := range tables {
-			_, ipv4RouteCidr, _ := getIPv4RouteForNode(n)
-
-			route, err := findRoute(ctx, table, n.Spec.ProviderID, ipv4RouteCidr, cachedRoutes[table], r.cloud)
-			if err != nil {
-				log.Error(err, "error find route existence for instance", "providerID", n.Spec.ProviderID, "reconcileID", reconcileID)
-				nodeRef := &corev1.ObjectReference{
-					Kind: "Node",
-					Name: n.Name,
-					UID:  n.UID,
+			found := true
+			for _, podCIDR := range podCIDRs {
+				podRouteCidr := podCIDR.String()
+				route, err := findRoute(ctx, table, n.Spec.ProviderID, podRouteCidr, cachedRoutes[table], r.cloud)
+				if err != nil {
+					log.Error(err, "error find route existence for instance", "providerID", n.Spec.ProviderID, "reconcileID", reconcileID)
+					nodeRef := &corev1.ObjectReference{
+						Kind: "Node",
+						Name: n.Name,
+						UID:  n.UID,
+					}
+					r.record.Event(
+						nodeRef,
+						corev1.EventTypeWarning,
+						"DescriberRouteFailed",
+						fmt.Sprintf("Describe Route Failed for %s reason: %s", table, helper.GetLogMessage(err)),
+					)
+					r.requeueNode(n)
+					found = false
+					continue
 				}
-				r.record.Event(
-					nodeRef,
-					corev1.EventTypeWarning,
-					"DescriberRouteFailed",
-					fmt.Sprintf("Describe Route Failed for %s reason: %s", table, helper.GetLogMessage(err)),
-				)
-				r.requeueNode(n)
-				continue
-			}
 
-			if route == nil {
-				toAdd[table] = append(toAdd[table], &model.Route{
-					Name:            fmt.Sprintf("%s-%s", n.Spec.ProviderID, ipv4RouteCidr),
-					DestinationCIDR: ipv4RouteCidr,
-					ProviderId:      n.Spec.ProviderID,
-					NodeReference:   n,
-				})
-			} else {
+				if route == nil {
+					found = false
+					toAdd[table] = append(toAdd[table], &model.Route{
+						Name:            fmt.Sprintf("%s-%s", n.Spec.ProviderID, podRouteCidr),
+						DestinationCIDR: podRouteCidr,
+						ProviderId:      n.Spec.ProviderID,
+						NodeReference:   n,
+					})
+				}
+			}
+			if found {
 				r.rateLimiter.Forget(reconcile.Request{
 					NamespacedName: types.NamespacedName{
 						Name: n.Name,
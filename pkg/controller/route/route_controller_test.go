@@ -3,16 +3,22 @@ package route
 import (
 	"context"
 	cmap "github.com/orcaman/concurrent-map"
+	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"testing"
 )
 
@@ -126,3 +132,56 @@ func getFakeKubeClient() client.Client {
 	objs := []runtime.Object{nodeList}
 	return fake.NewClientBuilder().WithRuntimeObjects(objs...).Build()
 }
+
+func TestBatchSyncCloudRoutesLifecycle(t *testing.T) {
+	cloud := fakecloud.NewFakeCloud(nil)
+	kubeClient := getFakeKubeClient()
+	r := &ReconcileRoute{
+		cloud:        cloud,
+		client:       kubeClient,
+		record:       record.NewFakeRecorder(100),
+		nodeCache:    cmap.New(),
+		configRoutes: true,
+		requeueChan:  make(chan event.GenericEvent, 10),
+		rateLimiter:  workqueue.DefaultControllerRateLimiter(),
+	}
+	ctx := context.TODO()
+	requests := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: NodeName}},
+		{NamespacedName: types.NamespacedName{Name: "cn-hangzhou.192.0.168.69"}},
+	}
+
+	// the nodes are requeued if the routes can not be created
+	cloud.InjectThrottling("CreateRoutes", 1)
+	assert.Error(t, r.batchSyncCloudRoutes(ctx, "1", requests))
+	assert.Zero(t, cloud.RouteCount(fakecloud.DefaultRouteTableID))
+	assert.Len(t, r.requeueChan, 2)
+
+	// the routes are created on retry and the nodes become available
+	assert.NoError(t, r.batchSyncCloudRoutes(ctx, "2", requests))
+	routes, err := cloud.ListRoute(ctx, fakecloud.DefaultRouteTableID)
+	assert.NoError(t, err)
+	var cidrs []string
+	for _, route := range routes {
+		cidrs = append(cidrs, route.DestinationCIDR)
+	}
+	assert.ElementsMatch(t, []string{"10.96.0.64/26", "10.96.0.128/26"}, cidrs)
+	node := &v1.Node{}
+	assert.NoError(t, kubeClient.Get(ctx, types.NamespacedName{Name: NodeName}, node))
+	condition, ok := helper.FindCondition(node.Status.Conditions, v1.NodeNetworkUnavailable)
+	assert.True(t, ok)
+	assert.Equal(t, v1.ConditionFalse, condition.Status)
+
+	// the existing route is not created again
+	assert.NoError(t, r.batchSyncCloudRoutes(ctx, "3", requests[:1]))
+	assert.Equal(t, 2, cloud.CallCount("CreateRoutes"))
+
+	// the route of a deleted node is removed
+	assert.NoError(t, kubeClient.Delete(ctx, node))
+	assert.NoError(t, r.batchSyncCloudRoutes(ctx, "4", requests[:1]))
+	routes, err = cloud.ListRoute(ctx, fakecloud.DefaultRouteTableID)
+	assert.NoError(t, err)
+	assert.Len(t, routes, 1)
+	assert.Equal(t, "10.96.0.128/26", routes[0].DestinationCIDR)
+	assert.Empty(t, r.cachedRoutes(NodeName))
+}
@@ -2,8 +2,13 @@ package route
 
 import (
 	"fmt"
-	v1 "k8s.io/api/core/v1"
 	"net"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 )
 
 func getIPv4RouteForNode(node *v1.Node) (*net.IPNet, string, error) {
@@ -27,3 +32,101 @@ func getIPv4RouteForNode(node *v1.Node) (*net.IPNet, string, error) {
 	}
 	return ipv4CIDR, ipv4CIDRStr, nil
 }
+
+// getIPv6RouteForNode returns the first ipv6 pod cidr of the node, nil if the node has no ipv6 pod cidr.
+func getIPv6RouteForNode(node *v1.Node) (*net.IPNet, string, error) {
+	for _, podCidr := range append(node.Spec.PodCIDRs, node.Spec.PodCIDR) {
+		if podCidr == "" {
+			continue
+		}
+		_, ipv6CIDR, err := net.ParseCIDR(podCidr)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid pod cidr on node spec: %v", podCidr)
+		}
+		if len(ipv6CIDR.Mask) == net.IPv6len {
+			return ipv6CIDR, ipv6CIDR.String(), nil
+		}
+	}
+	return nil, "", nil
+}
+
+// getRoutesForNode returns the pod cidrs of the node which need routes. The ipv6 pod cidr is only
+// returned if IPv6DualStack is enabled.
+func getRoutesForNode(node *v1.Node) ([]*net.IPNet, error) {
+	var cidrs []*net.IPNet
+	ipv4CIDR, _, err := getIPv4RouteForNode(node)
+	if err != nil {
+		return nil, err
+	}
+	if ipv4CIDR != nil && len(ipv4CIDR.Mask) == net.IPv4len {
+		cidrs = append(cidrs, ipv4CIDR)
+	}
+	if !utilfeature.DefaultFeatureGate.Enabled(ctrlCfg.IPv6DualStack) {
+		// keep the previous behavior for the nodes with a single ipv6 pod cidr
+		if len(cidrs) == 0 && ipv4CIDR != nil {
+			cidrs = append(cidrs, ipv4CIDR)
+		}
+		return cidrs, nil
+	}
+	ipv6CIDR, _, err := getIPv6RouteForNode(node)
+	if err != nil {
+		return nil, err
+	}
+	if ipv6CIDR != nil {
+		cidrs = append(cidrs, ipv6CIDR)
+	}
+	return cidrs, nil
+}
+
+// parseClusterCIDRs parses the comma-separated cluster cidrs, at most one cidr for each ip family is allowed.
+// The ipv6 cidr of a dual-stack cluster is ignored if IPv6DualStack is disabled.
+func parseClusterCIDRs(clusterCIDR string) ([]*net.IPNet, error) {
+	if clusterCIDR == "" {
+		return nil, nil
+	}
+	parts := strings.Split(clusterCIDR, ",")
+	var cidrs []*net.IPNet
+	families := map[int]bool{}
+	for _, c := range parts {
+		_, cidr, err := net.ParseCIDR(strings.TrimSpace(c))
+		if err != nil {
+			return nil, fmt.Errorf("error parse cluster cidr %s: %s", c, err)
+		}
+		if families[len(cidr.Mask)] {
+			return nil, fmt.Errorf("error parse cluster cidr %s: multiple cidrs of the same ip family", clusterCIDR)
+		}
+		families[len(cidr.Mask)] = true
+		if len(parts) > 1 && len(cidr.Mask) == net.IPv6len &&
+			!utilfeature.DefaultFeatureGate.Enabled(ctrlCfg.IPv6DualStack) {
+			continue
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
+
+// containsRouteInCluster returns true if the route is in any of the cluster cidrs, an empty cluster cidr
+// contains all routes.
+func containsRouteInCluster(clusterCIDRs []*net.IPNet, route string) (bool, error) {
+	if len(clusterCIDRs) == 0 {
+		return true, nil
+	}
+	for _, clusterCIDR := range clusterCIDRs {
+		contains, _, err := containsRoute(clusterCIDR, route)
+		if err != nil {
+			return false, err
+		}
+		if contains {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ipFamilyOf returns the ip family of the pod cidr, which is used in the logs and events.
+func ipFamilyOf(cidr *net.IPNet) model.AddressIPVersionType {
+	if len(cidr.Mask) == net.IPv6len {
+		return model.IPv6
+	}
+	return model.IPv4
+}
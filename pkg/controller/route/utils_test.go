@@ -3,6 +3,8 @@ package route
 import (
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"testing"
 )
 
@@ -47,3 +49,64 @@ func TestGetIPv4RouteForNode(t *testing.T) {
 		assert.Equal(t, testcase.err, err != nil)
 	}
 }
+
+func TestGetIPv6RouteForNode(t *testing.T) {
+	node := v1.Node{Spec: v1.NodeSpec{PodCIDR: "192.168.0.0/24", PodCIDRs: []string{"192.168.0.0/24", "fd00::/80"}}}
+	ipnet, cidr, err := getIPv6RouteForNode(&node)
+	assert.NoError(t, err)
+	assert.NotNil(t, ipnet)
+	assert.Equal(t, "fd00::/80", cidr)
+
+	node = v1.Node{Spec: v1.NodeSpec{PodCIDR: "192.168.0.0/24"}}
+	ipnet, cidr, err = getIPv6RouteForNode(&node)
+	assert.NoError(t, err)
+	assert.Nil(t, ipnet)
+	assert.Equal(t, "", cidr)
+}
+
+func TestGetRoutesForNode(t *testing.T) {
+	node := v1.Node{Spec: v1.NodeSpec{PodCIDR: "192.168.0.0/24", PodCIDRs: []string{"192.168.0.0/24", "fd00::/80"}}}
+
+	cidrs, err := getRoutesForNode(&node)
+	assert.NoError(t, err)
+	assert.Len(t, cidrs, 1)
+	assert.Equal(t, "192.168.0.0/24", cidrs[0].String())
+
+	_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.IPv6DualStack): true})
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.IPv6DualStack): false})
+	}()
+	cidrs, err = getRoutesForNode(&node)
+	assert.NoError(t, err)
+	assert.Len(t, cidrs, 2)
+	assert.Equal(t, "192.168.0.0/24", cidrs[0].String())
+	assert.Equal(t, "fd00::/80", cidrs[1].String())
+}
+
+func TestParseClusterCIDRs(t *testing.T) {
+	cidrs, err := parseClusterCIDRs("")
+	assert.NoError(t, err)
+	assert.Empty(t, cidrs)
+
+	cidrs, err = parseClusterCIDRs("172.16.0.0/16,fd00::/64")
+	assert.NoError(t, err)
+	assert.Len(t, cidrs, 1)
+
+	_, err = parseClusterCIDRs("172.16.0.0/16,172.17.0.0/16")
+	assert.Error(t, err)
+
+	_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.IPv6DualStack): true})
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(ctrlCfg.IPv6DualStack): false})
+	}()
+	cidrs, err = parseClusterCIDRs("172.16.0.0/16, fd00::/64")
+	assert.NoError(t, err)
+	assert.Len(t, cidrs, 2)
+
+	contains, err := containsRouteInCluster(cidrs, "fd00::1:0:0:0/80")
+	assert.NoError(t, err)
+	assert.True(t, contains)
+	contains, err = containsRouteInCluster(cidrs, "fd01::/80")
+	assert.NoError(t, err)
+	assert.False(t, contains)
+}
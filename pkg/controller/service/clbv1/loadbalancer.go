@@ -2,11 +2,13 @@ package clbv1
 
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 
 	"github.com/aliyun/credentials-go/credentials/utils"
 	cmap "github.com/orcaman/concurrent-map"
+	v1 "k8s.io/api/core/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/rand"
 	ctrlcfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
@@ -279,6 +281,18 @@ func (mgr *LoadBalancerManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, m
 			mdl.LoadBalancerAttribute.VSwitchId = ""
 		}
 	}
+
+	if len(reqCtx.Service.Spec.LoadBalancerSourceRanges) != 0 {
+		if mdl.LoadBalancerAttribute.IsUserManaged {
+			reqCtx.Recorder.Eventf(reqCtx.Service, v1.EventTypeWarning, helper.FeatureNotSupported,
+				"LoadBalancerSourceRanges is not supported for user-managed LB.")
+		} else if reqCtx.Anno.Get(annotation.AclID) != "" || reqCtx.Anno.Get(annotation.AclStatus) != "" {
+			reqCtx.Recorder.Eventf(reqCtx.Service, v1.EventTypeWarning, helper.FeatureNotSupported,
+				"LoadBalancerSourceRanges is ignored because the acl of listeners is specified by annotations.")
+		} else {
+			mdl.LoadBalancerAttribute.SourceRanges = reqCtx.Service.Spec.LoadBalancerSourceRanges
+		}
+	}
 	return nil
 }
 
@@ -366,6 +380,176 @@ func (mgr *LoadBalancerManager) CleanupLoadBalancerTags(reqCtx *svcCtx.RequestCo
 	return mgr.cloud.UntagResources(reqCtx.Ctx, remote.LoadBalancerAttribute.LoadBalancerId, &removedTags)
 }
 
+// FindAssociatedAcl finds the acl created for spec.loadBalancerSourceRanges of the service
+func (mgr *LoadBalancerManager) FindAssociatedAcl(reqCtx *svcCtx.RequestContext) (*model.AccessControlList, error) {
+	acls, err := mgr.cloud.DescribeAccessControlLists(reqCtx.Ctx, []tag.Tag{
+		{
+			Key:   helper.TAGKEY,
+			Value: reqCtx.Anno.GetDefaultLoadBalancerName(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find associated acl error: %s", err.Error())
+	}
+	if len(acls) == 0 {
+		return nil, nil
+	}
+	if len(acls) > 1 {
+		var ids []string
+		for _, a := range acls {
+			ids = append(ids, a.AclId)
+		}
+		return nil, fmt.Errorf("find multiple acls by tag [%s:%s]: %v",
+			helper.TAGKEY, reqCtx.Anno.GetDefaultLoadBalancerName(), ids)
+	}
+	return mgr.cloud.DescribeAccessControlListAttribute(reqCtx.Ctx, acls[0].AclId)
+}
+
+func (mgr *LoadBalancerManager) CreateAssociatedAcl(reqCtx *svcCtx.RequestContext, remote *model.LoadBalancer) (string, error) {
+	rgId := ctrlcfg.CloudCFG.Global.ResourceGroupID
+	if remote.LoadBalancerAttribute.ResourceGroupId != "" {
+		rgId = remote.LoadBalancerAttribute.ResourceGroupId
+	}
+	acl := &model.AccessControlList{
+		AclName:          getAclName(reqCtx),
+		AddressIPVersion: remote.LoadBalancerAttribute.AddressIPVersion,
+		ResourceGroupId:  rgId,
+		Tags: []tag.Tag{
+			{
+				Key:   helper.TAGKEY,
+				Value: reqCtx.Anno.GetDefaultLoadBalancerName(),
+			},
+			{
+				Key:   util.ClusterTagKey,
+				Value: ctrlcfg.CloudCFG.Global.ClusterID,
+			},
+		},
+	}
+	aclId, err := mgr.cloud.CreateAccessControlList(reqCtx.Ctx, acl)
+	if err != nil {
+		return "", fmt.Errorf("error to create acl for slb, svc [%s], err: %s", util.Key(reqCtx.Service), err.Error())
+	}
+	return aclId, nil
+}
+
+// UpdateAssociatedAclEntries keeps the entries of the associated acl in sync with spec.loadBalancerSourceRanges
+func (mgr *LoadBalancerManager) UpdateAssociatedAclEntries(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) error {
+	if local.LoadBalancerAttribute.SourceRangesAclId == "" || remote.AssociatedAcl == nil {
+		return nil
+	}
+
+	localEntries := buildAclEntriesFromSourceRanges(reqCtx, local.LoadBalancerAttribute.SourceRanges,
+		remote.AssociatedAcl.AddressIPVersion)
+	var toAdd, toRemove []model.AclEntry
+	for _, l := range localEntries {
+		found := false
+		for _, r := range remote.AssociatedAcl.Entries {
+			if l.Entry == r.Entry {
+				found = true
+				break
+			}
+		}
+		if !found {
+			toAdd = append(toAdd, l)
+		}
+	}
+	for _, r := range remote.AssociatedAcl.Entries {
+		found := false
+		for _, l := range localEntries {
+			if l.Entry == r.Entry {
+				found = true
+				break
+			}
+		}
+		if !found {
+			toRemove = append(toRemove, r)
+		}
+	}
+
+	aclId := remote.AssociatedAcl.AclId
+	if len(toRemove) != 0 {
+		reqCtx.Log.Info("remove acl entries", "aclId", aclId, "entries", toRemove)
+		if err := mgr.cloud.RemoveAccessControlListEntry(reqCtx.Ctx, aclId, toRemove); err != nil {
+			return fmt.Errorf("error to remove entries of acl [%s], svc [%s], err: %s",
+				aclId, util.Key(reqCtx.Service), err.Error())
+		}
+	}
+	if len(toAdd) != 0 {
+		reqCtx.Log.Info("add acl entries", "aclId", aclId, "entries", toAdd)
+		if err := mgr.cloud.AddAccessControlListEntry(reqCtx.Ctx, aclId, toAdd); err != nil {
+			return fmt.Errorf("error to add entries to acl [%s], svc [%s], err: %s",
+				aclId, util.Key(reqCtx.Service), err.Error())
+		}
+	}
+	return nil
+}
+
+func (mgr *LoadBalancerManager) DeleteAssociatedAcl(reqCtx *svcCtx.RequestContext, remote *model.LoadBalancer) error {
+	if remote.AssociatedAcl == nil || remote.AssociatedAcl.AclId == "" {
+		return nil
+	}
+	if err := mgr.cloud.DeleteAccessControlList(reqCtx.Ctx, remote.AssociatedAcl.AclId); err != nil {
+		return fmt.Errorf("error to delete associated acl, aclId: %s, svc: %s, err: %s",
+			remote.AssociatedAcl.AclId, remote.NamespacedName, err.Error())
+	}
+	return nil
+}
+
+func getAclName(reqCtx *svcCtx.RequestContext) string {
+	return fmt.Sprintf("k8s-clb-%s", reqCtx.Anno.GetDefaultLoadBalancerName())
+}
+
+// buildAclEntriesFromSourceRanges converts source ranges to acl entries, ranges of the other ip family
+// are skipped as an acl only accepts the entries of its own ip version. The skipped ranges are reported
+// by a warning event on the service.
+func buildAclEntriesFromSourceRanges(reqCtx *svcCtx.RequestContext, sourceRanges []string, ipVersion model.AddressIPVersionType) []model.AclEntry {
+	var entries []model.AclEntry
+	var invalid, otherFamily []string
+	defer func() {
+		if len(invalid) != 0 {
+			reqCtx.Log.Info("skip invalid source ranges", "sourceRanges", invalid)
+			reqCtx.Recorder.Eventf(reqCtx.Service, v1.EventTypeWarning, helper.SkippedSourceRanges,
+				"Invalid source ranges %v are skipped.", invalid)
+		}
+		if len(otherFamily) != 0 {
+			reqCtx.Log.Info("skip source ranges of different ip version", "sourceRanges", otherFamily, "ipVersion", ipVersion)
+			reqCtx.Recorder.Eventf(reqCtx.Service, v1.EventTypeWarning, helper.SkippedSourceRanges,
+				"Source ranges %v are skipped, the acl only accepts the entries of ip version %s.", otherFamily,
+				addressIPVersionOrDefault(ipVersion))
+		}
+	}()
+	seen := make(map[string]bool)
+	for _, r := range sourceRanges {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(r))
+		if err != nil {
+			invalid = append(invalid, r)
+			continue
+		}
+		isIPv4 := ipNet.IP.To4() != nil
+		if isIPv4 == equalsAddressIPVersion(ipVersion, model.IPv6) {
+			otherFamily = append(otherFamily, r)
+			continue
+		}
+		entry := ipNet.String()
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		entries = append(entries, model.AclEntry{
+			Entry:   entry,
+			Comment: "source-range",
+		})
+	}
+	return entries
+}
+
+func addressIPVersionOrDefault(ipVersion model.AddressIPVersionType) model.AddressIPVersionType {
+	if ipVersion == "" {
+		return model.IPv4
+	}
+	return ipVersion
+}
+
 func equalsAddressIPVersion(local, remote model.AddressIPVersionType) bool {
 	if local == "" {
 		local = model.IPv4
@@ -100,3 +100,63 @@ func TestSetAvailableVswitchID(t *testing.T) {
 	assert.Error(t, err2)
 	assert.Contains(t, err2.Error(), "no available vsw found")
 }
+
+type aclMockProvider struct {
+	vmock.MockCloud
+	added   []model.AclEntry
+	removed []model.AclEntry
+}
+
+func (m *aclMockProvider) AddAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	m.added = append(m.added, entries...)
+	return nil
+}
+
+func (m *aclMockProvider) RemoveAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	m.removed = append(m.removed, entries...)
+	return nil
+}
+
+func TestBuildAclEntriesFromSourceRanges(t *testing.T) {
+	reqCtx := getReqCtx(getDefaultService())
+	sourceRanges := []string{"10.0.0.0/8", "192.168.1.1/32", "192.168.0.5/16", "10.0.0.0/8", "invalid", "2001:db8::/32"}
+
+	entries := buildAclEntriesFromSourceRanges(reqCtx, sourceRanges, "")
+	var ips []string
+	for _, e := range entries {
+		ips = append(ips, e.Entry)
+	}
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1/32", "192.168.0.0/16"}, ips)
+
+	entries = buildAclEntriesFromSourceRanges(reqCtx, sourceRanges, model.IPv6)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "2001:db8::/32", entries[0].Entry)
+}
+
+func TestUpdateAssociatedAclEntries(t *testing.T) {
+	cloud := &aclMockProvider{}
+	mgr := NewLoadBalancerManager(cloud)
+	reqCtx := getReqCtx(getDefaultService())
+
+	local := &model.LoadBalancer{
+		LoadBalancerAttribute: model.LoadBalancerAttribute{
+			SourceRanges:      []string{"10.0.0.0/8", "172.16.0.0/12"},
+			SourceRangesAclId: "acl-id",
+		},
+	}
+	remote := &model.LoadBalancer{
+		AssociatedAcl: &model.AccessControlList{
+			AclId: "acl-id",
+			Entries: []model.AclEntry{
+				{Entry: "10.0.0.0/8"},
+				{Entry: "192.168.0.0/16"},
+			},
+		},
+	}
+	err := mgr.UpdateAssociatedAclEntries(reqCtx, local, remote)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(cloud.added))
+	assert.Equal(t, "172.16.0.0/12", cloud.added[0].Entry)
+	assert.Equal(t, 1, len(cloud.removed))
+	assert.Equal(t, "192.168.0.0/16", cloud.removed[0].Entry)
+}
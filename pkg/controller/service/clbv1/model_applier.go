@@ -65,6 +65,14 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *model.LoadBal
 	var tasks []func() error
 
 	if hashChangedOrDryRun {
+		if needFindAssociatedAcl(reqCtx.Service, local, remote) {
+			acl, err := m.slbMgr.FindAssociatedAcl(reqCtx)
+			if err != nil {
+				return remote, err
+			}
+			remote.AssociatedAcl = acl
+		}
+
 		err := m.applyLoadBalancer(reqCtx, local, remote)
 		if err != nil {
 			return remote, fmt.Errorf("update lb attribute error: %s", err.Error())
@@ -80,6 +88,13 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *model.LoadBal
 
 	if remote.LoadBalancerAttribute.LoadBalancerId == "" {
 		if helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+			// listeners are deleted together with the slb, so the associated acl can be deleted now
+			if hashChangedOrDryRun && remote.AssociatedAcl != nil {
+				if err := m.slbMgr.DeleteAssociatedAcl(reqCtx, remote); err != nil {
+					return remote, fmt.Errorf("delete associated acl error: %s", err.Error())
+				}
+				remote.AssociatedAcl = nil
+			}
 			return remote, nil
 		}
 		return remote, fmt.Errorf("alicloud: can not find loadbalancer by tag [%s:%s]",
@@ -100,6 +115,10 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *model.LoadBal
 		if local.LoadBalancerAttribute.IsUserManaged && !reqCtx.Anno.IsForceOverride() {
 			reqCtx.Log.Info("listener override is false, skip reconcile listeners")
 		} else {
+			if err := m.applyAssociatedAcl(reqCtx, local, remote); err != nil {
+				return remote, fmt.Errorf("update source ranges error: %s", err.Error())
+			}
+
 			createActions, updateActions, deleteActions, err := buildActionsForListeners(reqCtx, local, remote)
 			if err != nil {
 				return remote, fmt.Errorf("merge listener: %s", err.Error())
@@ -244,6 +263,48 @@ func (m *ModelApplier) deleteLoadBalancer(reqCtx *svcCtx.RequestContext, local,
 	return nil
 }
 
+// applyAssociatedAcl creates the acl for spec.loadBalancerSourceRanges and syncs its entries,
+// then binds it to all listeners as a whitelist. When the source ranges are removed, the acl
+// is unbound from the listeners here and deleted in cleanup.
+func (m *ModelApplier) applyAssociatedAcl(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) error {
+	if len(local.LoadBalancerAttribute.SourceRanges) == 0 {
+		if remote.AssociatedAcl != nil && reqCtx.Anno.Get(annotation.AclStatus) == "" {
+			for i := range local.Listeners {
+				local.Listeners[i].AclStatus = model.OffFlag
+			}
+		}
+		return nil
+	}
+
+	if remote.AssociatedAcl == nil {
+		aclId, err := m.slbMgr.CreateAssociatedAcl(reqCtx, remote)
+		if err != nil {
+			return err
+		}
+		reqCtx.Log.Info(fmt.Sprintf("successfully create acl %s", aclId))
+		remote.AssociatedAcl, err = m.slbMgr.FindAssociatedAcl(reqCtx)
+		if err != nil {
+			return err
+		}
+		if remote.AssociatedAcl == nil {
+			return fmt.Errorf("can not find acl %s by tag [%s:%s]", aclId,
+				helper.TAGKEY, reqCtx.Anno.GetDefaultLoadBalancerName())
+		}
+	}
+	local.LoadBalancerAttribute.SourceRangesAclId = remote.AssociatedAcl.AclId
+
+	if err := m.slbMgr.UpdateAssociatedAclEntries(reqCtx, local, remote); err != nil {
+		return err
+	}
+
+	for i := range local.Listeners {
+		local.Listeners[i].AclStatus = model.OnFlag
+		local.Listeners[i].AclType = "white"
+		local.Listeners[i].AclId = local.LoadBalancerAttribute.SourceRangesAclId
+	}
+	return nil
+}
+
 func (m *ModelApplier) applyVGroups(reqCtx *svcCtx.RequestContext, actions []vGroupAction, serverGroupChannel chan vGroupApplyResult) error {
 	if serverGroupChannel != nil {
 		defer close(serverGroupChannel)
@@ -491,9 +552,41 @@ func (m *ModelApplier) cleanup(reqCtx *svcCtx.RequestContext, local *model.LoadB
 	}
 
 	errs := m.vGroupMgr.ParallelUpdateVServerGroup(reqCtx, actions, nil)
+
+	// delete associated acl, it has been unbound from listeners in applyAssociatedAcl. When the slb
+	// is preserved on delete, the listeners of the service are deleted with the acl bound to them,
+	// so the acl is deleted as well.
+	if len(local.LoadBalancerAttribute.SourceRanges) == 0 && remote.AssociatedAcl != nil &&
+		reqCtx.Anno.Get(annotation.AclStatus) == "" {
+		reqCtx.Log.Info(fmt.Sprintf("delete associated acl [%s]", remote.AssociatedAcl.AclId))
+		if err := m.slbMgr.DeleteAssociatedAcl(reqCtx, remote); err != nil {
+			errs = append(errs, err)
+		} else {
+			remote.AssociatedAcl = nil
+		}
+	}
 	return utilerrors.NewAggregate(errs)
 }
 
+func needFindAssociatedAcl(service *v1.Service, local, remote *model.LoadBalancer) bool {
+	if local.LoadBalancerAttribute.IsUserManaged {
+		return false
+	}
+	if len(local.LoadBalancerAttribute.SourceRanges) != 0 {
+		return true
+	}
+	// the service is deleting, or the source ranges may have been removed
+	if helper.NeedDeleteLoadBalancer(service) {
+		return true
+	}
+	for _, l := range remote.Listeners {
+		if l.AclStatus == model.OnFlag {
+			return true
+		}
+	}
+	return false
+}
+
 func isLoadBalancerReusable(reqCtx *svcCtx.RequestContext, tags []tag.Tag, lbIp string) (bool, string) {
 	for _, tag := range tags {
 		// the tag of the apiserver slb is "ack.aliyun.com": "${clusterid}",
@@ -0,0 +1,258 @@
+package clbv1
+
+import (
+	"fmt"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// fields of the local model which are not applied to the loadbalancer or are only for the local model
+var ignoredLoadBalancerFields = []string{"IsUserManaged", "PreserveOnDelete", "Tags", "SourceRanges", "SourceRangesAclId",
+	"RegionId", "LoadBalancerId", "LoadBalancerStatus", "VpcId"}
+
+var ignoredListenerFields = []string{"IsUserManaged", "NamedKey", "Status", "VGroupName", "VGroupId"}
+
+// buildPlan builds the local and remote models of the service and returns the changes a reconcile
+// would make to the loadbalancer.
+func (m *ReconcileService) buildPlan(reqCtx *svcCtx.RequestContext) *dryrun.Plan {
+	plan := dryrun.NewPlan("Service", util.NamespacedName(reqCtx.Service))
+
+	local, err := m.builder.BuildModel(reqCtx, LocalModel)
+	if err != nil {
+		plan.SetError(fmt.Errorf("build lb local model error: %s", err.Error()))
+		return plan
+	}
+	remote, err := m.builder.BuildModel(reqCtx, RemoteModel)
+	if err != nil {
+		plan.SetError(fmt.Errorf("build lb remote model error: %s", err.Error()))
+		return plan
+	}
+	if lbId := remote.LoadBalancerAttribute.LoadBalancerId; lbId != "" {
+		plan.LoadBalancerID = lbId
+		tags, err := m.cloud.ListCLBTagResources(reqCtx.Ctx, lbId)
+		if err != nil {
+			plan.SetError(fmt.Errorf("DescribeTags: %s", err.Error()))
+			return plan
+		}
+		remote.LoadBalancerAttribute.Tags = tags
+	}
+
+	plan.Add(buildChanges(reqCtx, local, remote)...)
+	return plan
+}
+
+func buildChanges(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) []dryrun.Change {
+	var changes []dryrun.Change
+	lbId := remote.LoadBalancerAttribute.LoadBalancerId
+
+	if helper.NeedDeleteLoadBalancer(reqCtx.Service) || !helper.NeedCLB(reqCtx.Service) {
+		if lbId == "" {
+			return nil
+		}
+		if !local.LoadBalancerAttribute.IsUserManaged && !local.LoadBalancerAttribute.PreserveOnDelete {
+			return append(changes, dryrun.Change{
+				Action:   dryrun.ActionDelete,
+				Resource: dryrun.ResourceLoadBalancer,
+				ID:       lbId,
+				Name:     remote.LoadBalancerAttribute.LoadBalancerName,
+				Before:   dryrun.Attributes(remote.LoadBalancerAttribute, ignoredLoadBalancerFields...),
+			})
+		}
+		// the listeners and vgroups of the service are removed from the reused loadbalancer
+		local.Listeners, local.VServerGroups = nil, nil
+	} else if lbId == "" {
+		changes = append(changes, dryrun.Change{
+			Action:   dryrun.ActionCreate,
+			Resource: dryrun.ResourceLoadBalancer,
+			Name:     local.LoadBalancerAttribute.LoadBalancerName,
+			After:    dryrun.Attributes(local.LoadBalancerAttribute, ignoredLoadBalancerFields...),
+		})
+		if len(local.LoadBalancerAttribute.Tags) != 0 {
+			changes = append(changes, *dryrun.TagChange("", nil, local.LoadBalancerAttribute.Tags))
+		}
+	} else {
+		before, after := dryrun.Diff(remote.LoadBalancerAttribute, local.LoadBalancerAttribute, ignoredLoadBalancerFields...)
+		if len(after) != 0 {
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionUpdate,
+				Resource: dryrun.ResourceLoadBalancer,
+				ID:       lbId,
+				Name:     remote.LoadBalancerAttribute.LoadBalancerName,
+				Before:   before,
+				After:    after,
+			})
+		}
+		if c := dryrun.TagChange(lbId, remote.LoadBalancerAttribute.Tags, local.LoadBalancerAttribute.Tags); c != nil {
+			changes = append(changes, *c)
+		}
+	}
+
+	changes = append(changes, buildVGroupChanges(reqCtx, local, remote)...)
+	if !local.LoadBalancerAttribute.IsUserManaged || reqCtx.Anno.IsForceOverride() {
+		changes = append(changes, buildListenerChanges(local, remote)...)
+	}
+	return changes
+}
+
+func buildVGroupChanges(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) []dryrun.Change {
+	var changes []dryrun.Change
+	for _, l := range local.VServerGroups {
+		var r *model.VServerGroup
+		for i := range remote.VServerGroups {
+			if (l.VGroupId != "" && l.VGroupId == remote.VServerGroups[i].VGroupId) ||
+				(l.VGroupId == "" && l.VGroupName == remote.VServerGroups[i].VGroupName) {
+				r = &remote.VServerGroups[i]
+				break
+			}
+		}
+		if r == nil {
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionCreate,
+				Resource: dryrun.ResourceServerGroup,
+				Name:     l.VGroupName,
+				After:    map[string]interface{}{"VGroupName": l.VGroupName, "Backends": len(l.Backends)},
+			})
+			changes = append(changes, backendChanges(dryrun.ActionCreate, l.VGroupName, l.Backends)...)
+			continue
+		}
+
+		add, del, update := diff(*r, l)
+		changes = append(changes, backendChanges(dryrun.ActionCreate, r.VGroupId, add)...)
+		changes = append(changes, backendChanges(dryrun.ActionDelete, r.VGroupId, del)...)
+		for _, u := range update {
+			for _, b := range r.Backends {
+				if b.ServerId == u.ServerId && (u.Type != "eni" || b.ServerIp == u.ServerIp) {
+					before, after := dryrun.Diff(b, u, "IsUserManaged", "NodeName", "TargetRef", "Invalid")
+					changes = append(changes, dryrun.Change{
+						Action:   dryrun.ActionUpdate,
+						Resource: dryrun.ResourceBackend,
+						ID:       backendID(u),
+						Parent:   r.VGroupId,
+						Before:   before,
+						After:    after,
+					})
+					break
+				}
+			}
+		}
+	}
+
+	for _, r := range remote.VServerGroups {
+		found := false
+		for _, l := range local.VServerGroups {
+			if (l.VGroupId != "" && l.VGroupId == r.VGroupId) || (l.VGroupId == "" && l.VGroupName == r.VGroupName) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if isVGroupManagedByMyService(r, reqCtx.Service) {
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionDelete,
+				Resource: dryrun.ResourceServerGroup,
+				ID:       r.VGroupId,
+				Name:     r.VGroupName,
+			})
+			continue
+		}
+		// the backends of the service are removed from the user managed vgroup
+		var del []model.BackendAttribute
+		for _, b := range r.Backends {
+			if !b.IsUserManaged {
+				del = append(del, b)
+			}
+		}
+		changes = append(changes, backendChanges(dryrun.ActionDelete, r.VGroupId, del)...)
+	}
+	return changes
+}
+
+func buildListenerChanges(local, remote *model.LoadBalancer) []dryrun.Change {
+	var changes []dryrun.Change
+	lbId := remote.LoadBalancerAttribute.LoadBalancerId
+	for _, r := range remote.Listeners {
+		found := false
+		for _, l := range local.Listeners {
+			if r.ListenerPort != l.ListenerPort || r.Protocol != l.Protocol {
+				continue
+			}
+			found = true
+			before, after := dryrun.Diff(r, l, ignoredListenerFields...)
+			if len(after) != 0 {
+				changes = append(changes, dryrun.Change{
+					Action:   dryrun.ActionUpdate,
+					Resource: dryrun.ResourceListener,
+					Name:     listenerName(l),
+					Parent:   lbId,
+					Before:   before,
+					After:    after,
+				})
+			}
+		}
+		if !found && isPortManagedByMyService(local, r) {
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionDelete,
+				Resource: dryrun.ResourceListener,
+				Name:     listenerName(r),
+				Parent:   lbId,
+			})
+		}
+	}
+
+	for _, l := range local.Listeners {
+		found := false
+		for _, r := range remote.Listeners {
+			if l.ListenerPort == r.ListenerPort && l.Protocol == r.Protocol {
+				found = true
+				break
+			}
+		}
+		if !found {
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionCreate,
+				Resource: dryrun.ResourceListener,
+				Name:     listenerName(l),
+				Parent:   lbId,
+				After:    dryrun.Attributes(l, ignoredListenerFields...),
+			})
+		}
+	}
+	return changes
+}
+
+func backendChanges(action dryrun.Action, vGroup string, backends []model.BackendAttribute) []dryrun.Change {
+	var changes []dryrun.Change
+	for _, b := range backends {
+		c := dryrun.Change{
+			Action:   action,
+			Resource: dryrun.ResourceBackend,
+			ID:       backendID(b),
+			Parent:   vGroup,
+		}
+		attrs := dryrun.Attributes(b, "IsUserManaged", "NodeName", "TargetRef", "Invalid")
+		if action == dryrun.ActionDelete {
+			c.Before = attrs
+		} else {
+			c.After = attrs
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+func backendID(b model.BackendAttribute) string {
+	if b.Type == "eni" {
+		return fmt.Sprintf("%s/%s:%d", b.ServerId, b.ServerIp, b.Port)
+	}
+	return fmt.Sprintf("%s:%d", b.ServerId, b.Port)
+}
+
+func listenerName(l model.ListenerAttribute) string {
+	return fmt.Sprintf("%s:%d", l.Protocol, l.ListenerPort)
+}
@@ -0,0 +1,113 @@
+package clbv1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+)
+
+func TestBuildChanges_CreateLoadBalancer(t *testing.T) {
+	svc := getDefaultService()
+	reqCtx := getReqCtx(svc)
+	local := &model.LoadBalancer{
+		LoadBalancerAttribute: model.LoadBalancerAttribute{
+			LoadBalancerName: "lb-test",
+			AddressType:      model.InternetAddressType,
+			Tags:             []tag.Tag{{Key: "k", Value: "v"}},
+		},
+		Listeners: []model.ListenerAttribute{{ListenerPort: 80, Protocol: model.TCP}},
+		VServerGroups: []model.VServerGroup{{
+			VGroupName: "k8s/80/test/default/clusterid",
+			Backends:   []model.BackendAttribute{{ServerId: "ecs-1", Port: 30080, Weight: 100, Type: model.ECSBackendType}},
+		}},
+	}
+	remote := &model.LoadBalancer{}
+
+	changes := buildChanges(reqCtx, local, remote)
+	assert.Len(t, changes, 5)
+	assert.Equal(t, dryrun.Change{
+		Action:   dryrun.ActionCreate,
+		Resource: dryrun.ResourceLoadBalancer,
+		Name:     "lb-test",
+		After:    map[string]interface{}{"LoadBalancerName": "lb-test", "AddressType": "internet"},
+	}, changes[0])
+	assert.Equal(t, dryrun.ResourceTag, changes[1].Resource)
+	assert.Equal(t, dryrun.ResourceServerGroup, changes[2].Resource)
+	assert.Equal(t, dryrun.ResourceBackend, changes[3].Resource)
+	assert.Equal(t, "ecs-1:30080", changes[3].ID)
+	assert.Equal(t, dryrun.ActionCreate, changes[4].Action)
+	assert.Equal(t, "tcp:80", changes[4].Name)
+}
+
+func TestBuildChanges_UpdateLoadBalancer(t *testing.T) {
+	svc := getDefaultService()
+	reqCtx := getReqCtx(svc)
+	local := &model.LoadBalancer{
+		LoadBalancerAttribute: model.LoadBalancerAttribute{
+			LoadBalancerName: "lb-new",
+			Tags:             []tag.Tag{{Key: "k", Value: "v"}},
+		},
+		Listeners: []model.ListenerAttribute{{ListenerPort: 80, Protocol: model.TCP, Scheduler: "wrr"}},
+		VServerGroups: []model.VServerGroup{{
+			VGroupId: "rsp-1",
+			Backends: []model.BackendAttribute{
+				{ServerId: "ecs-1", Port: 30080, Weight: 50, Type: model.ECSBackendType},
+				{ServerId: "ecs-3", Port: 30080, Weight: 100, Type: model.ECSBackendType},
+			},
+		}},
+	}
+	remote := &model.LoadBalancer{
+		LoadBalancerAttribute: model.LoadBalancerAttribute{
+			LoadBalancerId:   "lb-id",
+			LoadBalancerName: "lb-old",
+			Tags:             []tag.Tag{{Key: "k", Value: "v"}},
+		},
+		Listeners: []model.ListenerAttribute{
+			{ListenerPort: 80, Protocol: model.TCP, Scheduler: "rr"},
+			{ListenerPort: 443, Protocol: model.TCP, IsUserManaged: true},
+		},
+		VServerGroups: []model.VServerGroup{{
+			VGroupId: "rsp-1",
+			Backends: []model.BackendAttribute{
+				{ServerId: "ecs-1", Port: 30080, Weight: 100, Type: model.ECSBackendType},
+				{ServerId: "ecs-2", Port: 30080, Weight: 100, Type: model.ECSBackendType},
+			},
+		}},
+	}
+
+	changes := buildChanges(reqCtx, local, remote)
+	var actions []string
+	for _, c := range changes {
+		actions = append(actions, string(c.Action)+" "+c.Resource+" "+c.ID+c.Name)
+	}
+	assert.Equal(t, []string{
+		"Update LoadBalancer lb-idlb-old",
+		"Create Backend ecs-3:30080",
+		"Delete Backend ecs-2:30080",
+		"Update Backend ecs-1:30080",
+		"Update Listener tcp:80",
+	}, actions)
+	assert.Equal(t, map[string]interface{}{"LoadBalancerName": "lb-old"}, changes[0].Before)
+	assert.Equal(t, map[string]interface{}{"weight": float64(100)}, changes[3].Before)
+	assert.Equal(t, map[string]interface{}{"weight": float64(50)}, changes[3].After)
+	assert.Equal(t, map[string]interface{}{"Scheduler": "wrr"}, changes[4].After)
+}
+
+func TestBuildChanges_DeleteLoadBalancer(t *testing.T) {
+	svc := getDefaultService()
+	svc.Spec.Type = "ClusterIP"
+	reqCtx := getReqCtx(svc)
+	remote := &model.LoadBalancer{
+		LoadBalancerAttribute: model.LoadBalancerAttribute{LoadBalancerId: "lb-id", LoadBalancerName: "lb"},
+	}
+
+	changes := buildChanges(reqCtx, &model.LoadBalancer{}, remote)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, dryrun.ActionDelete, changes[0].Action)
+	assert.Equal(t, "lb-id", changes[0].ID)
+
+	assert.Empty(t, buildChanges(reqCtx, &model.LoadBalancer{}, &model.LoadBalancer{}))
+}
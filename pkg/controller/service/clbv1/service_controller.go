@@ -21,8 +21,11 @@ import (
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/drift"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/lbconfig"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -33,6 +36,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -63,6 +67,8 @@ func newReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*ReconcileSe
 		logger:           ctrl.Log.WithName("controller").WithName("service-controller"),
 		record:           mgr.GetEventRecorderFor("service-controller"),
 		finalizerManager: helper.NewDefaultFinalizerManager(mgr.GetClient()),
+		readinessChecker: helper.NewReadinessChecker("service-readiness-checker",
+			ctrlCfg.CloudCFG.Global.ServiceMaxConcurrentReconciles, helper.DefaultReadinessCheckPeriod),
 	}
 
 	slbManager := NewLoadBalancerManager(recon.cloud)
@@ -134,6 +140,27 @@ func add(mgr manager.Manager, r *ReconcileService) error {
 		NewEnqueueRequestForNodeEvent(mgr.GetClient(), mgr.GetEventRecorderFor("service-controller"))); err != nil {
 		return fmt.Errorf("watch resource node error: %s", err.Error())
 	}
+
+	// the services failed for a missing configuration are enqueued too, they may not have the finalizer yet
+	if err := lbconfig.Watch(c, mgr, needAdd); err != nil {
+		return fmt.Errorf("watch resource LoadBalancerConfiguration error: %s", err.Error())
+	}
+
+	detector := drift.NewDetector(metric.CLBType, mgr.GetClient(), mgr.GetEventRecorderFor("service-controller"),
+		func(svc *v1.Service) bool {
+			return needAdd(svc) && helper.HasFinalizer(svc, helper.ServiceFinalizer)
+		},
+		newInspector(r).Plan,
+	)
+	if err := c.Watch(detector.Source(), &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("watch drift detection error: %s", err.Error())
+	}
+	if err := mgr.Add(detector); err != nil {
+		return fmt.Errorf("add drift detector error: %s", err.Error())
+	}
+	if err := mgr.Add(r.readinessChecker); err != nil {
+		return fmt.Errorf("add readiness checker error: %s", err.Error())
+	}
 	return mgr.Add(&serviceController{c: c, recon: r})
 }
 
@@ -155,10 +182,15 @@ type ReconcileService struct {
 	//record event recorder
 	record           record.EventRecorder
 	finalizerManager helper.FinalizerManager
+	// readinessChecker rechecks the readiness gates of the pods waiting for the health check
+	readinessChecker *helper.ReadinessChecker
 }
 
 func (m *ReconcileService) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
-	return util.HandleReconcileResult(request, m.reconcile(ctx, request))
+	ctx, span := tracing.StartReconcile(ctx, "Service", request.NamespacedName)
+	err := m.reconcile(ctx, request)
+	tracing.End(span, err)
+	return util.HandleReconcileResult(request, err)
 }
 
 func (m *ReconcileService) reconcile(c context.Context, request reconcile.Request) (err error) {
@@ -195,12 +227,23 @@ func (m *ReconcileService) reconcile(c context.Context, request reconcile.Reques
 			// Owned objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			m.readinessChecker.Remove(request.NamespacedName)
 			return nil
 		}
 		util.ServiceLog.Error(err, "reconcile: get service failed", "service", request.NamespacedName)
 		return err
 	}
-	anno := &annotation.AnnotationRequest{Service: svc}
+	anno, err := lbconfig.NewAnnotationRequest(c, m.kubeClient, svc)
+	if err != nil {
+		if !helper.NeedDeleteLoadBalancer(svc) {
+			m.record.Event(svc, v1.EventTypeWarning, helper.FailedSyncLB,
+				fmt.Sprintf("Error syncing load balancer: %s", helper.GetLogMessage(err)))
+			return err
+		}
+		// the loadbalancer is cleaned up by the annotations, e.g. the configuration is deleted with the namespace
+		svcLog.Info("ignore the LoadBalancerConfiguration of the deleting service", "error", err.Error())
+		anno = annotation.NewAnnotationRequest(svc)
+	}
 
 	// disable public address
 	if anno.Get(annotation.AddressType) == "" ||
@@ -212,8 +255,8 @@ func (m *ReconcileService) reconcile(c context.Context, request reconcile.Reques
 		}
 	}
 
-	// new context for each request
-	ctx := context.Background()
+	// new context for each request, which carries the span of the reconcile
+	ctx := tracing.Background(c)
 	ctx = context.WithValue(ctx, dryrun.ContextService, svc)
 
 	reqContext := &svcCtx.RequestContext{
@@ -228,6 +271,9 @@ func (m *ReconcileService) reconcile(c context.Context, request reconcile.Reques
 	klog.Infof("%s: ensure loadbalancer with service details, reconcileID: %s\n%+v\n", util.Key(svc), reconcileID, util.PrettyJson(svc))
 
 	if ctrlCfg.ControllerCFG.DryRun {
+		if err := dryrun.AddPlan(m.kubeClient, m.buildPlan(reqContext)); err != nil {
+			reqContext.Log.Error(err, "DryRun: write plan failed")
+		}
 		if lb, _, err := m.buildAndApplyModel(reqContext); err != nil {
 			reqContext.Log.Error(err, "DryRun: reconcile loadbalancer failed")
 			m.record.Event(reqContext.Service, v1.EventTypeWarning, helper.FailedSyncLB,
@@ -296,6 +342,7 @@ func (m *ReconcileService) reconcile(c context.Context, request reconcile.Reques
 
 func (m *ReconcileService) cleanupLoadBalancerResources(reqCtx *svcCtx.RequestContext) error {
 	reqCtx.Log.Info("service do not need lb any more, try to delete it")
+	m.readinessChecker.Remove(util.NamespacedName(reqCtx.Service))
 	if helper.HasFinalizer(reqCtx.Service, helper.ServiceFinalizer) {
 		lb, _, err := m.buildAndApplyModel(reqCtx)
 		if err != nil && !strings.Contains(err.Error(), "LoadBalancerId does not exist") {
@@ -345,12 +392,13 @@ func (m *ReconcileService) reconcileLoadBalancerResources(req *svcCtx.RequestCon
 		return err
 	}
 
-	err = m.updateReadinessCondition(req, vservers)
+	waitingHealthCheck, err := m.updateReadinessCondition(req, lb.GetLoadBalancerId(), vservers)
 	if err != nil {
 		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedUpdateReadinessGate,
 			fmt.Sprintf("Error updating pod readiness gates for service [%s]: %s", util.Key(req.Service), err.Error()))
 		return err
 	}
+	m.scheduleReadinessCheck(req, lb.GetLoadBalancerId(), vservers, waitingHealthCheck)
 
 	if err := m.addServiceLabels(req.Service, lb.GetLoadBalancerId()); err != nil {
 		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedAddHash,
@@ -597,10 +645,26 @@ func (m *ReconcileService) setEIPAsExternalIP(ctx context.Context, lbId string)
 	return ingress, nil
 }
 
-func (m *ReconcileService) updateReadinessCondition(reqCtx *svcCtx.RequestContext, vgroups []model.VServerGroup) error {
+// updateReadinessCondition updates the readiness gates of the backend pods by the health check results of
+// the load balancer, true is returned if there are pods waiting for passing the health check.
+func (m *ReconcileService) updateReadinessCondition(reqCtx *svcCtx.RequestContext, lbId string, vgroups []model.VServerGroup) (bool, error) {
 	var errs []error
 	cond := helper.BuildReadinessGatePodConditionTypeWithPrefix(helper.TargetHealthPodConditionServiceTypePrefix, reqCtx.Service.Name)
 	updated := map[string]bool{}
+	waiting := false
+
+	// the health status is loaded only if there are pods with readiness gates
+	var health backendHealth
+	var healthErr error
+	isHealthy := func(b model.BackendAttribute) (bool, error) {
+		if health == nil && healthErr == nil {
+			health, healthErr = m.describeBackendHealth(reqCtx, lbId)
+		}
+		if healthErr != nil {
+			return false, healthErr
+		}
+		return health.isHealthy(b), nil
+	}
 
 	for _, vg := range vgroups {
 		for _, b := range vg.InvalidBackends {
@@ -638,18 +702,99 @@ func (m *ReconcileService) updateReadinessCondition(reqCtx *svcCtx.RequestContex
 				continue
 			}
 
-			err := m.updateReadinessConditionForPodKey(reqCtx, key, cond, v1.ConditionTrue,
-				helper.ConditionReasonServerRegistered, helper.ConditionMessageServerRegistered)
+			pending, err := m.updateHealthConditionForPodKey(reqCtx, key, cond, func() (bool, error) {
+				return isHealthy(b)
+			})
 			if err != nil {
 				errs = append(errs, err)
 				continue
 			}
+			waiting = waiting || pending
 
 			updated[key.String()] = true
 		}
 
 	}
-	return utilerrors.NewAggregate(errs)
+	return waiting, utilerrors.NewAggregate(errs)
+}
+
+// scheduleReadinessCheck rechecks the readiness gates of the pods waiting for the health check later
+// instead of requeueing the service, the pending check is canceled if no pod is waiting.
+func (m *ReconcileService) scheduleReadinessCheck(reqCtx *svcCtx.RequestContext, lbId string, vgroups []model.VServerGroup, waiting bool) {
+	key := util.NamespacedName(reqCtx.Service)
+	if !waiting {
+		m.readinessChecker.Remove(key)
+		return
+	}
+	reqCtx.Log.Info("has backends waiting for health check, recheck readiness gates later")
+	m.readinessChecker.Add(key, func(ctx context.Context) (bool, error) {
+		checkCtx := *reqCtx
+		checkCtx.Ctx = ctx
+		return m.updateReadinessCondition(&checkCtx, lbId, vgroups)
+	})
+}
+
+func (m *ReconcileService) updateHealthConditionForPodKey(reqCtx *svcCtx.RequestContext, key types.NamespacedName,
+	cond v1.PodConditionType, isHealthy func() (bool, error)) (bool, error) {
+	pod := &v1.Pod{}
+	err := m.kubeClient.Get(reqCtx.Ctx, key, pod)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			reqCtx.Log.Info("pod not found while updating readiness condition, skip", "pod", key.String())
+			return false, nil
+		}
+		return false, err
+	}
+	if !helper.IsPodHasReadinessGate(pod, string(cond)) {
+		return false, nil
+	}
+
+	healthy, err := isHealthy()
+	if err != nil {
+		return false, err
+	}
+	pending, err := helper.UpdateHealthConditionForPod(reqCtx.Ctx, m.kubeClient, pod, cond, healthy,
+		ctrlCfg.ControllerCFG.HealthCheckTimeout)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			reqCtx.Log.Info("pod not found while updating readiness condition, skip", "pod", key.String())
+			return false, nil
+		}
+		return false, err
+	}
+	return pending, nil
+}
+
+// backendHealth indexes the health status of the backends by server id and port
+type backendHealth map[string][]model.BackendHealthStatus
+
+func (m *ReconcileService) describeBackendHealth(reqCtx *svcCtx.RequestContext, lbId string) (backendHealth, error) {
+	status, err := m.cloud.DescribeHealthStatus(reqCtx.Ctx, lbId)
+	if err != nil {
+		return nil, fmt.Errorf("describe health status of %s error: %s", lbId, err.Error())
+	}
+	health := backendHealth{}
+	for _, s := range status {
+		key := fmt.Sprintf("%s/%d", s.ServerId, s.Port)
+		health[key] = append(health[key], s)
+	}
+	return health, nil
+}
+
+// isHealthy returns whether the backend has passed the health check of all the listeners.
+// A backend without health status is not healthy, e.g. it is just added to the vserver group.
+func (h backendHealth) isHealthy(b model.BackendAttribute) bool {
+	found := false
+	for _, s := range h[fmt.Sprintf("%s/%d", b.ServerId, b.Port)] {
+		if b.ServerIp != "" && s.ServerIp != "" && b.ServerIp != s.ServerIp {
+			continue
+		}
+		if s.Status == model.HealthStatusAbnormal {
+			return false
+		}
+		found = true
+	}
+	return found
 }
 
 func (m *ReconcileService) updateReadinessConditionForPodKey(reqCtx *svcCtx.RequestContext, key types.NamespacedName,
@@ -2,8 +2,10 @@ package clbv1
 
 import (
 	"context"
+	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -13,12 +15,15 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"strings"
 	"testing"
 	"time"
 )
@@ -268,6 +273,7 @@ func getReconcileService() *ReconcileService {
 		kubeClient:       getFakeKubeClient(),
 		record:           eventRecord,
 		finalizerManager: helper.NewDefaultFinalizerManager(getFakeKubeClient()),
+		readinessChecker: helper.NewReadinessChecker("test", 1, helper.DefaultReadinessCheckPeriod),
 	}
 
 	slbManager := NewLoadBalancerManager(recon.cloud)
@@ -351,6 +357,7 @@ func TestReconcileNotFoundService(t *testing.T) {
 
 func TestDryRun(t *testing.T) {
 	ctrlCfg.ControllerCFG.DryRun = true
+	defer func() { ctrlCfg.ControllerCFG.DryRun = false }()
 	initMap(getFakeKubeClient())
 
 	recon := getReconcileService()
@@ -365,3 +372,182 @@ func TestDryRun(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestBackendHealth(t *testing.T) {
+	health := backendHealth{
+		"eni-1/80": {
+			{ListenerPort: 80, ServerId: "eni-1", ServerIp: "10.0.0.1", Port: 80, Status: model.HealthStatusNormal},
+			{ListenerPort: 80, ServerId: "eni-1", ServerIp: "10.0.0.2", Port: 80, Status: model.HealthStatusAbnormal},
+			{ListenerPort: 443, ServerId: "eni-1", ServerIp: "10.0.0.1", Port: 80, Status: model.HealthStatusUnavailable},
+		},
+	}
+	cases := []struct {
+		backend model.BackendAttribute
+		healthy bool
+	}{
+		{backend: model.BackendAttribute{ServerId: "eni-1", ServerIp: "10.0.0.1", Port: 80}, healthy: true},
+		{backend: model.BackendAttribute{ServerId: "eni-1", ServerIp: "10.0.0.2", Port: 80}, healthy: false},
+		// not reported yet
+		{backend: model.BackendAttribute{ServerId: "eni-1", ServerIp: "10.0.0.3", Port: 80}, healthy: false},
+		{backend: model.BackendAttribute{ServerId: "eni-2", ServerIp: "10.0.0.4", Port: 80}, healthy: false},
+	}
+	for _, c := range cases {
+		if health.isHealthy(c.backend) != c.healthy {
+			t.Errorf("expect backend %s/%s healthy %t", c.backend.ServerId, c.backend.ServerIp, c.healthy)
+		}
+	}
+}
+
+func getFakeReconcileService(cloud prvd.Provider, kubeClient client.Client) *ReconcileService {
+	recon := &ReconcileService{
+		cloud:            cloud,
+		kubeClient:       kubeClient,
+		record:           record.NewFakeRecorder(100),
+		finalizerManager: helper.NewDefaultFinalizerManager(kubeClient),
+		readinessChecker: helper.NewReadinessChecker("test", 1, helper.DefaultReadinessCheckPeriod),
+	}
+	slbManager := NewLoadBalancerManager(recon.cloud)
+	listenerManager := NewListenerManager(recon.cloud)
+	vGroupManager, _ := NewVGroupManager(recon.kubeClient, recon.cloud)
+	recon.builder = NewModelBuilder(slbManager, listenerManager, vGroupManager)
+	recon.applier = NewModelApplier(slbManager, listenerManager, vGroupManager)
+	return recon
+}
+
+func TestReconcileServiceLifecycle(t *testing.T) {
+	cloud := fakecloud.NewFakeCloud(nil)
+	kubeClient := getFakeKubeClient()
+	recon := getFakeReconcileService(cloud, kubeClient)
+	ctx := context.TODO()
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: NS, Name: SvcName}}
+	getService := func() *v1.Service {
+		svc := &v1.Service{}
+		assert.NoError(t, kubeClient.Get(ctx, req.NamespacedName, svc))
+		return svc
+	}
+	listenerPorts := func(lbId string) []int {
+		var ports []int
+		for _, l := range cloud.GetLoadBalancer(lbId).Listeners {
+			ports = append(ports, l.ListenerPort)
+		}
+		return ports
+	}
+
+	// the load balancer is created with the listener of the service port
+	_, err := recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	lbIds := cloud.LoadBalancerIds()
+	assert.Len(t, lbIds, 1)
+	lb := cloud.GetLoadBalancer(lbIds[0])
+	assert.Equal(t, []int{80}, listenerPorts(lbIds[0]))
+	assert.Len(t, lb.VServerGroups, 1)
+	svc := getService()
+	assert.True(t, helper.HasFinalizer(svc, helper.ServiceFinalizer))
+	assert.Equal(t, []v1.LoadBalancerIngress{{IP: lb.LoadBalancerAttribute.Address}}, svc.Status.LoadBalancer.Ingress)
+
+	// a reconcile without changes only reads the load balancer
+	creates := cloud.CallCount("CreateLoadBalancer")
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, creates, cloud.CallCount("CreateLoadBalancer"))
+	assert.Zero(t, cloud.CallCount("DeleteLoadBalancerListener"))
+
+	// the listener is replaced once the throttled request is retried
+	svc = getService()
+	svc.Spec.Ports[0].Port = 8080
+	assert.NoError(t, kubeClient.Update(ctx, svc))
+	cloud.InjectThrottling("CreateLoadBalancerTCPListener", 1)
+	_, err = recon.Reconcile(ctx, req)
+	assert.Error(t, err)
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{8080}, listenerPorts(lbIds[0]))
+	assert.Equal(t, []string{lbIds[0]}, cloud.LoadBalancerIds())
+
+	// the load balancer is deleted with the service
+	assert.NoError(t, kubeClient.Delete(ctx, getService()))
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Empty(t, cloud.LoadBalancerIds())
+	assert.True(t, apierrors.IsNotFound(kubeClient.Get(ctx, req.NamespacedName, &v1.Service{})))
+}
+
+func TestReconcileServiceSourceRangesPreserveOnDelete(t *testing.T) {
+	cloud := fakecloud.NewFakeCloud(nil)
+	kubeClient := getFakeKubeClient()
+	recon := getFakeReconcileService(cloud, kubeClient)
+	recorder := recon.record.(*record.FakeRecorder)
+	ctx := context.TODO()
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: NS, Name: SvcName}}
+	svc := &v1.Service{}
+	assert.NoError(t, kubeClient.Get(ctx, req.NamespacedName, svc))
+	svc.Annotations = map[string]string{annotation.Annotation(annotation.PreserveLBOnDelete): "true"}
+	svc.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8", "2001:db8::/32"}
+	assert.NoError(t, kubeClient.Update(ctx, svc))
+
+	// the acl only has the entries of the ip version of the load balancer, the others are reported
+	_, err := recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	acls, err := cloud.DescribeAccessControlLists(ctx, nil)
+	assert.NoError(t, err)
+	assert.Len(t, acls, 1)
+	acl, err := cloud.DescribeAccessControlListAttribute(ctx, acls[0].AclId)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8"}, aclEntries(acl))
+	skipped := false
+	for len(recorder.Events) != 0 {
+		if e := <-recorder.Events; strings.Contains(e, helper.SkippedSourceRanges) && strings.Contains(e, "2001:db8::/32") {
+			skipped = true
+		}
+	}
+	assert.True(t, skipped)
+
+	// the preserved load balancer does not keep the acl of the deleted service
+	assert.NoError(t, kubeClient.Get(ctx, req.NamespacedName, svc))
+	assert.NoError(t, kubeClient.Delete(ctx, svc))
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	lbIds := cloud.LoadBalancerIds()
+	assert.Len(t, lbIds, 1)
+	assert.Empty(t, cloud.GetLoadBalancer(lbIds[0]).Listeners)
+	acls, err = cloud.DescribeAccessControlLists(ctx, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, acls)
+}
+
+func aclEntries(acl *model.AccessControlList) []string {
+	var entries []string
+	for _, e := range acl.Entries {
+		entries = append(entries, e.Entry)
+	}
+	return entries
+}
+
+func TestScheduleReadinessCheck(t *testing.T) {
+	recon := getFakeReconcileService(fakecloud.NewFakeCloud(nil), getFakeKubeClient())
+	recon.readinessChecker = helper.NewReadinessChecker("test", 1, 10*time.Millisecond)
+	reqCtx := getReqCtx(getDefaultService())
+	key := util.NamespacedName(reqCtx.Service)
+
+	// nothing is scheduled if no backend is waiting for the health check
+	recon.scheduleReadinessCheck(reqCtx, "lb-1", nil, false)
+	assert.False(t, recon.readinessChecker.Has(key))
+
+	// the readiness gates are rechecked without reconciling the service, the check is done once
+	// no backend is waiting any more
+	recon.scheduleReadinessCheck(reqCtx, "lb-1", nil, true)
+	assert.True(t, recon.readinessChecker.Has(key))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go func() {
+		_ = recon.readinessChecker.Start(ctx)
+	}()
+	assert.Eventually(t, func() bool { return !recon.readinessChecker.Has(key) }, 5*time.Second, 10*time.Millisecond)
+
+	// the pending check is canceled by a reconcile without waiting backends
+	cancel()
+	recon.scheduleReadinessCheck(reqCtx, "lb-1", nil, true)
+	assert.True(t, recon.readinessChecker.Has(key))
+	recon.scheduleReadinessCheck(reqCtx, "lb-1", nil, false)
+	assert.False(t, recon.readinessChecker.Has(key))
+}
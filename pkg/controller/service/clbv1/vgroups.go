@@ -110,7 +110,7 @@ func (mgr *VGroupManager) updateVServerGroupENIBackendID(reqCtx *svcCtx.RequestC
 	if len(ips) == 0 {
 		return nil
 	}
-	result, err := mgr.cloud.DescribeNetworkInterfaces(mgr.vpcId, ips, ipVersion)
+	result, err := mgr.cloud.DescribeNetworkInterfaces(reqCtx.Ctx, mgr.vpcId, ips, ipVersion)
 	if err != nil {
 		return fmt.Errorf("call DescribeNetworkInterfaces: %s", err.Error())
 	}
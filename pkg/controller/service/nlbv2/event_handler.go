@@ -15,6 +15,7 @@ import (
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/lbconfig"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"k8s.io/klog/v2"
 	"reflect"
@@ -338,10 +339,13 @@ func (h *enqueueRequestForNodeEvent) checkServiceAffected(node *v1.Node, svc *v1
 		return true
 	}
 
-	r := annotation.NewAnnotationRequest(svc)
+	r, err := lbconfig.NewAnnotationRequest(context.TODO(), h.client, svc)
+	if err != nil {
+		r = annotation.NewAnnotationRequest(svc)
+	}
 	// service with annotation `service.beta.kubernetes.io/alibaba-cloud-loadbalancer-backend-label`
-	// or `service.beta.kubernetes.io/alibaba-cloud-loadbalancer-remove-unscheduled-backend`
-	// should be considered as affected.
+	// or `service.beta.kubernetes.io/alibaba-cloud-loadbalancer-remove-unscheduled-backend`,
+	// or with the backend settings of its LoadBalancerConfiguration, should be considered as affected.
 	if r.Get(annotation.BackendLabel) != "" ||
 		r.Get(annotation.RemoveUnscheduled) != "" {
 		util.NLBLog.Info("service is affected by node change because of annotations",
@@ -0,0 +1,79 @@
+package nlbv2
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/lbconfig"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// Inspector builds the models and plans of services without changing the nlbs or the
+// services, it is used by ccmctl and the drift detection.
+type Inspector struct {
+	recon *ReconcileNLB
+}
+
+func NewInspector(kubeClient client.Client, cloud prvd.Provider) (*Inspector, error) {
+	recon := &ReconcileNLB{
+		cloud:      cloud,
+		kubeClient: kubeClient,
+		logger:     ctrl.Log.WithName("inspector").WithName("nlb"),
+	}
+	serverGroupManager, err := NewServerGroupManager(kubeClient, cloud)
+	if err != nil {
+		return nil, err
+	}
+	recon.builder = NewModelBuilder(NewNLBManager(cloud), NewListenerManager(cloud), serverGroupManager)
+	return newInspector(recon), nil
+}
+
+// newInspector returns an inspector sharing the model builder of the reconciler
+func newInspector(recon *ReconcileNLB) *Inspector {
+	r := *recon
+	// the events are dropped
+	r.record = &record.FakeRecorder{}
+	return &Inspector{recon: &r}
+}
+
+func (i *Inspector) requestContext(ctx context.Context, svc *v1.Service) (*svcCtx.RequestContext, error) {
+	anno, err := lbconfig.NewAnnotationRequest(ctx, i.recon.kubeClient, svc)
+	if err != nil {
+		return nil, err
+	}
+	return &svcCtx.RequestContext{
+		Ctx:      ctx,
+		Service:  svc,
+		Anno:     anno,
+		Log:      util.NLBLog.WithValues("service", util.Key(svc)),
+		Recorder: i.recon.record,
+	}, nil
+}
+
+// RemoteModel returns the nlb of the service with all its listeners and server groups.
+func (i *Inspector) RemoteModel(ctx context.Context, svc *v1.Service) (*nlbmodel.NetworkLoadBalancer, error) {
+	reqCtx, err := i.requestContext(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	return i.recon.builder.BuildModel(reqCtx, RemoteModel)
+}
+
+// Plan returns the changes a reconcile of the service would make to the nlb.
+func (i *Inspector) Plan(ctx context.Context, svc *v1.Service) *dryrun.Plan {
+	reqCtx, err := i.requestContext(ctx, svc)
+	if err != nil {
+		plan := dryrun.NewPlan("Service", util.NamespacedName(svc))
+		plan.SetError(err)
+		return plan
+	}
+	return i.recon.buildPlan(reqCtx)
+}
@@ -25,16 +25,20 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/drift"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/lbconfig"
 	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	"k8s.io/klog/v2"
 	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -56,6 +60,8 @@ func newReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*ReconcileNL
 		logger:           ctrl.Log.WithName("controller").WithName("nlb-controller"),
 		record:           mgr.GetEventRecorderFor("nlb-controller"),
 		finalizerManager: helper.NewDefaultFinalizerManager(mgr.GetClient()),
+		readinessChecker: helper.NewReadinessChecker("nlb-readiness-checker",
+			ctrlCfg.CloudCFG.Global.ServiceMaxConcurrentReconciles, helper.DefaultReadinessCheckPeriod),
 	}
 
 	nlbManager := NewNLBManager(recon.cloud)
@@ -123,6 +129,27 @@ func add(mgr manager.Manager, r *ReconcileNLB) error {
 		return fmt.Errorf("watch resource node error: %s", err.Error())
 	}
 
+	// the services failed for a missing configuration are enqueued too, they may not have the finalizer yet
+	if err := lbconfig.Watch(c, mgr, needAdd); err != nil {
+		return fmt.Errorf("watch resource LoadBalancerConfiguration error: %s", err.Error())
+	}
+
+	detector := drift.NewDetector(metric.NLBType, mgr.GetClient(), mgr.GetEventRecorderFor("nlb-controller"),
+		func(svc *v1.Service) bool {
+			return needAdd(svc) && helper.HasFinalizer(svc, helper.NLBFinalizer)
+		},
+		newInspector(r).Plan,
+	)
+	if err := c.Watch(detector.Source(), &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("watch drift detection error: %s", err.Error())
+	}
+	if err := mgr.Add(detector); err != nil {
+		return fmt.Errorf("add drift detector error: %s", err.Error())
+	}
+	if err := mgr.Add(r.readinessChecker); err != nil {
+		return fmt.Errorf("add readiness checker error: %s", err.Error())
+	}
+
 	return mgr.Add(&nlbController{c: c, recon: r})
 }
 
@@ -142,10 +169,15 @@ type ReconcileNLB struct {
 	//record event recorder
 	record           record.EventRecorder
 	finalizerManager helper.FinalizerManager
+	// readinessChecker rechecks the readiness gates of the pods waiting for the health check
+	readinessChecker *helper.ReadinessChecker
 }
 
 func (m *ReconcileNLB) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
-	return util.HandleReconcileResult(request, m.reconcile(ctx, request))
+	ctx, span := tracing.StartReconcile(ctx, "Service", request.NamespacedName)
+	err := m.reconcile(ctx, request)
+	tracing.End(span, err)
+	return util.HandleReconcileResult(request, err)
 }
 
 func (m *ReconcileNLB) reconcile(c context.Context, request reconcile.Request) error {
@@ -160,14 +192,25 @@ func (m *ReconcileNLB) reconcile(c context.Context, request reconcile.Request) e
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			m.logger.Info("service not found, skip", "service", request.NamespacedName)
+			m.readinessChecker.Remove(request.NamespacedName)
 			return nil
 		}
 		m.logger.Error(err, "reconcile: get service failed", "service", request.NamespacedName)
 	}
 
-	anno := &annotation.AnnotationRequest{Service: svc}
-	// new context for each request
-	ctx := context.Background()
+	anno, err := lbconfig.NewAnnotationRequest(c, m.kubeClient, svc)
+	if err != nil {
+		if !helper.NeedDeleteLoadBalancer(svc) {
+			m.record.Event(svc, v1.EventTypeWarning, helper.FailedSyncLB,
+				fmt.Sprintf("Error syncing load balancer: %s", helper.GetLogMessage(err)))
+			return err
+		}
+		// the nlb is cleaned up by the annotations, e.g. the configuration is deleted with the namespace
+		nlbLog.Info("ignore the LoadBalancerConfiguration of the deleting service", "error", err.Error())
+		anno = annotation.NewAnnotationRequest(svc)
+	}
+	// new context for each request, which carries the span of the reconcile
+	ctx := tracing.Background(c)
 	ctx = context.WithValue(ctx, dryrun.ContextService, svc)
 	reqCtx := &svcCtx.RequestContext{
 		Ctx:         ctx,
@@ -180,6 +223,14 @@ func (m *ReconcileNLB) reconcile(c context.Context, request reconcile.Request) e
 
 	klog.Infof("%s: ensure loadbalancer with service details, reconcileID: %s\n%+v\n", util.Key(svc), reconcileID, util.PrettyJson(svc))
 
+	if ctrlCfg.ControllerCFG.DryRun {
+		// only write the plan, neither the nlb nor the service is changed in dry run mode
+		if err := dryrun.AddPlan(m.kubeClient, m.buildPlan(reqCtx)); err != nil {
+			reqCtx.Log.Error(err, "DryRun: write plan failed")
+		}
+		return nil
+	}
+
 	if helper.NeedDeleteLoadBalancer(svc) || !helper.NeedNLB(svc) {
 		err = m.cleanupLoadBalancerResources(reqCtx)
 	} else {
@@ -204,6 +255,7 @@ func (m *ReconcileNLB) reconcile(c context.Context, request reconcile.Request) e
 }
 
 func (m *ReconcileNLB) cleanupLoadBalancerResources(reqCtx *svcCtx.RequestContext) error {
+	m.readinessChecker.Remove(util.NamespacedName(reqCtx.Service))
 	reqCtx.Log.Info("service do not need lb any more, try to delete it")
 	if helper.HasFinalizer(reqCtx.Service, helper.NLBFinalizer) {
 		lb, _, err := m.buildAndApplyModel(reqCtx)
@@ -254,11 +306,13 @@ func (m *ReconcileNLB) reconcileLoadBalancerResources(req *svcCtx.RequestContext
 		return err
 	}
 
-	if err := m.updateReadinessCondition(req, sgs); err != nil {
+	waitingHealthCheck, err := m.updateReadinessCondition(req, lb.GetLoadBalancerId(), sgs)
+	if err != nil {
 		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedUpdateReadinessGate,
 			fmt.Sprintf("Error updating pod readiness gates for service [%s]: %s", util.Key(req.Service), err.Error()))
 		return err
 	}
+	m.scheduleReadinessCheck(req, lb.GetLoadBalancerId(), sgs, waitingHealthCheck)
 
 	if err := m.updateServiceLabels(req.Service, lb); err != nil {
 		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedAddHash,
@@ -488,10 +542,26 @@ func (m *ReconcileNLB) removeServiceLabels(svc *v1.Service) error {
 	return nil
 }
 
-func (m *ReconcileNLB) updateReadinessCondition(reqCtx *svcCtx.RequestContext, sgs []*nlbmodel.ServerGroup) error {
+// updateReadinessCondition updates the readiness gates of the backend pods by the health check results of
+// the listeners, true is returned if there are pods waiting for passing the health check.
+func (m *ReconcileNLB) updateReadinessCondition(reqCtx *svcCtx.RequestContext, lbId string, sgs []*nlbmodel.ServerGroup) (bool, error) {
 	var errs []error
 	cond := helper.BuildReadinessGatePodConditionTypeWithPrefix(helper.TargetHealthPodConditionServiceTypePrefix, reqCtx.Service.Name)
 	a := map[string]bool{}
+	waiting := false
+
+	// the health status is loaded only if there are pods with readiness gates
+	var health serverGroupHealth
+	var healthErr error
+	isHealthy := func(sgId string, s nlbmodel.ServerGroupServer) (bool, error) {
+		if health == nil && healthErr == nil {
+			health, healthErr = m.getServerGroupHealth(reqCtx, lbId)
+		}
+		if healthErr != nil {
+			return false, healthErr
+		}
+		return health.isHealthy(sgId, s), nil
+	}
 	for _, sg := range sgs {
 		for _, s := range sg.InvalidServers {
 			if s.TargetRef == nil {
@@ -527,17 +597,112 @@ func (m *ReconcileNLB) updateReadinessCondition(reqCtx *svcCtx.RequestContext, s
 				continue
 			}
 
-			err := m.updateReadinessConditionForPodKey(reqCtx, key, cond, v1.ConditionTrue,
-				helper.ConditionReasonServerRegistered, helper.ConditionMessageServerRegistered)
+			pending, err := m.updateHealthConditionForPodKey(reqCtx, key, cond, func() (bool, error) {
+				return isHealthy(sg.ServerGroupId, s)
+			})
 			if err != nil {
 				errs = append(errs, err)
 				continue
 			}
+			waiting = waiting || pending
 
 			a[key.String()] = true
 		}
 	}
-	return utilerrors.NewAggregate(errs)
+	return waiting, utilerrors.NewAggregate(errs)
+}
+
+// scheduleReadinessCheck rechecks the readiness gates of the pods waiting for the health check later
+// instead of requeueing the service, the pending check is canceled if no pod is waiting.
+func (m *ReconcileNLB) scheduleReadinessCheck(reqCtx *svcCtx.RequestContext, lbId string, sgs []*nlbmodel.ServerGroup, waiting bool) {
+	key := util.NamespacedName(reqCtx.Service)
+	if !waiting {
+		m.readinessChecker.Remove(key)
+		return
+	}
+	reqCtx.Log.Info("has backends waiting for health check, recheck readiness gates later")
+	m.readinessChecker.Add(key, func(ctx context.Context) (bool, error) {
+		checkCtx := *reqCtx
+		checkCtx.Ctx = ctx
+		return m.updateReadinessCondition(&checkCtx, lbId, sgs)
+	})
+}
+
+func (m *ReconcileNLB) updateHealthConditionForPodKey(reqCtx *svcCtx.RequestContext, key types.NamespacedName,
+	cond v1.PodConditionType, isHealthy func() (bool, error)) (bool, error) {
+	pod := &v1.Pod{}
+	err := m.kubeClient.Get(reqCtx.Ctx, key, pod)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			reqCtx.Log.Info("pod not found while updating readiness condition, skip", "pod", key.String())
+			return false, nil
+		}
+		return false, err
+	}
+	if !helper.IsPodHasReadinessGate(pod, string(cond)) {
+		return false, nil
+	}
+
+	healthy, err := isHealthy()
+	if err != nil {
+		return false, err
+	}
+	pending, err := helper.UpdateHealthConditionForPod(reqCtx.Ctx, m.kubeClient, pod, cond, healthy,
+		ctrlCfg.ControllerCFG.HealthCheckTimeout)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			reqCtx.Log.Info("pod not found while updating readiness condition, skip", "pod", key.String())
+			return false, nil
+		}
+		return false, err
+	}
+	return pending, nil
+}
+
+// serverGroupHealth indexes the health status of the server groups by server group id
+type serverGroupHealth map[string]*nlbmodel.ServerGroupHealthStatus
+
+func (m *ReconcileNLB) getServerGroupHealth(reqCtx *svcCtx.RequestContext, lbId string) (serverGroupHealth, error) {
+	listeners, err := m.cloud.ListNLBListeners(reqCtx.Ctx, lbId)
+	if err != nil {
+		return nil, fmt.Errorf("list listeners of %s error: %s", lbId, err.Error())
+	}
+	health := serverGroupHealth{}
+	for _, lis := range listeners {
+		status, err := m.cloud.GetNLBListenerHealthStatus(reqCtx.Ctx, lis.ListenerId)
+		if err != nil {
+			return nil, fmt.Errorf("get health status of listener %s error: %s", lis.ListenerId, err.Error())
+		}
+		for i := range status {
+			s := &status[i]
+			if exist, ok := health[s.ServerGroupId]; ok {
+				exist.HealthCheckEnabled = exist.HealthCheckEnabled || s.HealthCheckEnabled
+				exist.NonNormalServers = append(exist.NonNormalServers, s.NonNormalServers...)
+				continue
+			}
+			health[s.ServerGroupId] = s
+		}
+	}
+	return health, nil
+}
+
+// isHealthy returns whether the server has passed the health check. Only the servers which are not healthy
+// are reported, so a server in a server group without health status is not healthy.
+func (h serverGroupHealth) isHealthy(sgId string, s nlbmodel.ServerGroupServer) bool {
+	sg, ok := h[sgId]
+	if !ok {
+		return false
+	}
+	if !sg.HealthCheckEnabled {
+		return true
+	}
+	for _, n := range sg.NonNormalServers {
+		if n.ServerId == s.ServerId && n.Port == s.Port &&
+			(s.ServerIp == "" || n.ServerIp == "" || n.ServerIp == s.ServerIp) {
+			return false
+		}
+	}
+	return true
 }
 
 func (m *ReconcileNLB) updateReadinessConditionForPodKey(reqCtx *svcCtx.RequestContext, key types.NamespacedName,
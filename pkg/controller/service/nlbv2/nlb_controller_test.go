@@ -8,16 +8,21 @@ import (
 	"golang.org/x/time/rate"
 	v1 "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
 	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	fakecloud "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/fake"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -36,6 +41,7 @@ func getReconcileNLB() (*ReconcileNLB, error) {
 		logger:           ctrl.Log.WithName("controller").WithName("nlb-controller"),
 		record:           eventRecord,
 		finalizerManager: helper.NewDefaultFinalizerManager(getFakeKubeClient()),
+		readinessChecker: helper.NewReadinessChecker("test", 1, helper.DefaultReadinessCheckPeriod),
 	}
 
 	nlbManager := NewNLBManager(recon.cloud)
@@ -96,6 +102,10 @@ var (
 )
 
 func getFakeKubeClient() client.Client {
+	return fake.NewClientBuilder().WithRuntimeObjects(getFakeKubeObjects()...).Build()
+}
+
+func getFakeKubeObjects() []runtime.Object {
 	// Node
 	nodeList := &v1.NodeList{
 		Items: []v1.Node{
@@ -308,8 +318,7 @@ func getFakeKubeClient() client.Client {
 		AddressType: discovery.AddressTypeIPv4,
 	}
 
-	objs := []runtime.Object{nodeList, eps, es, svcList}
-	return fake.NewClientBuilder().WithRuntimeObjects(objs...).Build()
+	return []runtime.Object{nodeList, eps, es, svcList}
 }
 
 func getMockCloudProvider() prvd.Provider {
@@ -342,3 +351,189 @@ func TestRateLimiter(t *testing.T) {
 		t.Logf("ep duration %f", d.Seconds())
 	}
 }
+
+func TestServerGroupHealth(t *testing.T) {
+	health := serverGroupHealth{
+		"sgp-1": {
+			ServerGroupId:      "sgp-1",
+			HealthCheckEnabled: true,
+			NonNormalServers: []nlbmodel.ServerHealthStatus{
+				{ServerId: "eni-1", ServerIp: "10.0.0.2", Port: 80, Status: "Unhealthy"},
+			},
+		},
+		"sgp-2": {
+			ServerGroupId: "sgp-2",
+		},
+	}
+	assert.True(t, health.isHealthy("sgp-1", nlbmodel.ServerGroupServer{ServerId: "eni-1", ServerIp: "10.0.0.1", Port: 80}))
+	assert.False(t, health.isHealthy("sgp-1", nlbmodel.ServerGroupServer{ServerId: "eni-1", ServerIp: "10.0.0.2", Port: 80}))
+	// health check is disabled
+	assert.True(t, health.isHealthy("sgp-2", nlbmodel.ServerGroupServer{ServerId: "eni-1", ServerIp: "10.0.0.2", Port: 80}))
+	// not reported yet
+	assert.False(t, health.isHealthy("sgp-3", nlbmodel.ServerGroupServer{ServerId: "eni-1", ServerIp: "10.0.0.2", Port: 80}))
+}
+
+func TestReconcileNLBLifecycle(t *testing.T) {
+	cloud := fakecloud.NewFakeCloud(nil)
+	kubeClient := getFakeKubeClient()
+	recon := &ReconcileNLB{
+		cloud:            cloud,
+		kubeClient:       kubeClient,
+		logger:           ctrl.Log.WithName("controller").WithName("nlb-controller"),
+		record:           record.NewFakeRecorder(100),
+		finalizerManager: helper.NewDefaultFinalizerManager(kubeClient),
+		readinessChecker: helper.NewReadinessChecker("test", 1, helper.DefaultReadinessCheckPeriod),
+	}
+	serverGroupManager, err := NewServerGroupManager(kubeClient, cloud)
+	assert.NoError(t, err)
+	recon.builder = NewModelBuilder(NewNLBManager(cloud), NewListenerManager(cloud), serverGroupManager)
+	recon.applier = NewModelApplier(NewNLBManager(cloud), NewListenerManager(cloud), serverGroupManager)
+
+	ctx := context.TODO()
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v1.NamespaceDefault, Name: ServiceName}}
+	getService := func() *v1.Service {
+		svc := &v1.Service{}
+		assert.NoError(t, kubeClient.Get(ctx, req.NamespacedName, svc))
+		return svc
+	}
+	listNLBs := func() []*nlbmodel.NetworkLoadBalancer {
+		lbs, err := cloud.ListNLBs(ctx, nil)
+		assert.NoError(t, err)
+		return lbs
+	}
+	listenerPorts := func(lbId string) []int32 {
+		var ports []int32
+		for _, l := range cloud.GetNLB(lbId).Listeners {
+			ports = append(ports, l.ListenerPort)
+		}
+		return ports
+	}
+
+	// the nlb is created with the listener of the service port
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	lbs := listNLBs()
+	assert.Len(t, lbs, 1)
+	lbId := lbs[0].LoadBalancerAttribute.LoadBalancerId
+	assert.Equal(t, []int32{80}, listenerPorts(lbId))
+	svc := getService()
+	assert.True(t, helper.HasFinalizer(svc, helper.NLBFinalizer))
+	assert.Equal(t, []v1.LoadBalancerIngress{{Hostname: cloud.GetNLB(lbId).LoadBalancerAttribute.DNSName}}, svc.Status.LoadBalancer.Ingress)
+
+	// a reconcile without changes does not create anything
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cloud.CallCount("CreateNLB"))
+	assert.Equal(t, 1, cloud.CallCount("CreateNLBListenerAsync"))
+
+	// the listener is replaced once the throttled request is retried
+	svc = getService()
+	svc.Spec.Ports[0].Port = 8080
+	assert.NoError(t, kubeClient.Update(ctx, svc))
+	cloud.InjectThrottling("CreateNLBListenerAsync", 1)
+	_, err = recon.Reconcile(ctx, req)
+	assert.Error(t, err)
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{8080}, listenerPorts(lbId))
+	assert.Len(t, listNLBs(), 1)
+
+	// the nlb is deleted with the service
+	assert.NoError(t, kubeClient.Delete(ctx, getService()))
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Empty(t, listNLBs())
+	assert.True(t, apierrors.IsNotFound(kubeClient.Get(ctx, req.NamespacedName, &v1.Service{})))
+}
+
+func TestReconcileNLBWaitingHealthCheck(t *testing.T) {
+	timeout := ctrlCfg.ControllerCFG.HealthCheckTimeout
+	ctrlCfg.ControllerCFG.HealthCheckTimeout = time.Minute
+	defer func() { ctrlCfg.ControllerCFG.HealthCheckTimeout = timeout }()
+
+	cond := helper.BuildReadinessGatePodConditionTypeWithPrefix(helper.TargetHealthPodConditionServiceTypePrefix, ServiceName)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: v1.NamespaceDefault, UID: "nginx-uid"},
+		Spec:       v1.PodSpec{ReadinessGates: []v1.PodReadinessGate{{ConditionType: cond}}},
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{{
+				Type: v1.ContainersReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.Now(),
+			}},
+		},
+	}
+	targetRef := &v1.ObjectReference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name, UID: pod.UID}
+	objs := getFakeKubeObjects()
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *v1.Endpoints:
+			o.Subsets[0].Addresses[0].TargetRef = targetRef
+		case *discovery.EndpointSlice:
+			o.Endpoints[0].TargetRef = targetRef
+		case *v1.ServiceList:
+			o.Items[0].Annotations[helper.BackendType] = model.ENIBackendType
+		}
+	}
+	kubeClient := fake.NewClientBuilder().WithRuntimeObjects(append(objs, pod)...).
+		WithStatusSubresource(&v1.Pod{}).Build()
+	cloud := fakecloud.NewFakeCloud(nil)
+	cloud.AddENI(prvd.EniAttribute{NetworkInterfaceID: "eni-1", PrivateIPAddress: "10.96.0.15", Status: "InUse"},
+		fakecloud.DefaultVpcID)
+	recon := &ReconcileNLB{
+		cloud:            cloud,
+		kubeClient:       kubeClient,
+		logger:           ctrl.Log.WithName("controller").WithName("nlb-controller"),
+		record:           record.NewFakeRecorder(100),
+		finalizerManager: helper.NewDefaultFinalizerManager(kubeClient),
+		readinessChecker: helper.NewReadinessChecker("test", 1, 10*time.Millisecond),
+	}
+	serverGroupManager, err := NewServerGroupManager(kubeClient, cloud)
+	assert.NoError(t, err)
+	recon.builder = NewModelBuilder(NewNLBManager(cloud), NewListenerManager(cloud), serverGroupManager)
+	recon.applier = NewModelApplier(NewNLBManager(cloud), NewListenerManager(cloud), serverGroupManager)
+
+	ctx := context.TODO()
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v1.NamespaceDefault, Name: ServiceName}}
+	podCondition := func() v1.ConditionStatus {
+		p := &v1.Pod{}
+		assert.NoError(t, kubeClient.Get(ctx, util.NamespacedName(pod), p))
+		if c := helper.GetPodCondition(p, cond); c != nil {
+			return c.Status
+		}
+		return v1.ConditionUnknown
+	}
+
+	// the service is not requeued for the backend waiting for the health check
+	cloud.SetBackendHealth("eni-1", "10.96.0.15", 80, false)
+	res, err := recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, res)
+	assert.True(t, recon.readinessChecker.Has(req.NamespacedName))
+	assert.Equal(t, v1.ConditionFalse, podCondition())
+
+	// only the readiness gate is rechecked, the nlb is not synced again
+	listServerGroups := cloud.CallCount("ListNLBServerGroups")
+	checkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		_ = recon.readinessChecker.Start(checkCtx)
+	}()
+	cloud.SetBackendHealth("eni-1", "10.96.0.15", 80, true)
+	assert.Eventually(t, func() bool {
+		return !recon.readinessChecker.Has(req.NamespacedName)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, v1.ConditionTrue, podCondition())
+	assert.Equal(t, listServerGroups, cloud.CallCount("ListNLBServerGroups"))
+
+	// the pending check is canceled with the service
+	cloud.SetBackendHealth("eni-1", "10.96.0.15", 80, false)
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	cancel()
+	assert.True(t, recon.readinessChecker.Has(req.NamespacedName))
+	svc := &v1.Service{}
+	assert.NoError(t, kubeClient.Get(ctx, req.NamespacedName, svc))
+	assert.NoError(t, kubeClient.Delete(ctx, svc))
+	_, err = recon.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.False(t, recon.readinessChecker.Has(req.NamespacedName))
+}
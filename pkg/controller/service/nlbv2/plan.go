@@ -0,0 +1,298 @@
+package nlbv2
+
+import (
+	"fmt"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	svcCtx "k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/context"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// fields of the local model which are not applied to the nlb or are only for the local model
+var ignoredLoadBalancerFields = []string{"IsUserManaged", "PreserveOnDelete", "Tags", "SourceRanges",
+	"SourceRangesSecurityGroupId", "LoadBalancerId", "LoadBalancerStatus", "LoadBalancerBusinessStatus", "DNSName"}
+
+var ignoredListenerFields = []string{"IsUserManaged", "NamedKey", "ServerGroupName", "ServicePort", "ServerGroupId",
+	"LoadBalancerId", "ListenerId", "ListenerStatus"}
+
+var ignoredServerGroupFields = []string{"IsUserManaged", "NamedKey", "ServicePort", "Weight", "DefaultWeight", "VPCId",
+	"Servers", "InvalidServers", "InitialServers", "Tags", "IgnoreWeightUpdate", "ServerGroupId"}
+
+var ignoredServerFields = []string{"IsUserManaged", "NodeName", "ServerGroupId", "Status", "TargetRef", "Invalid"}
+
+// buildPlan builds the local and remote models of the service and returns the changes a reconcile
+// would make to the nlb.
+func (m *ReconcileNLB) buildPlan(reqCtx *svcCtx.RequestContext) *dryrun.Plan {
+	plan := dryrun.NewPlan("Service", util.NamespacedName(reqCtx.Service))
+
+	local, err := m.builder.BuildModel(reqCtx, LocalModel)
+	if err != nil {
+		plan.SetError(fmt.Errorf("build nlb local model error: %s", err.Error()))
+		return plan
+	}
+	remote, err := m.builder.BuildModel(reqCtx, RemoteModel)
+	if err != nil {
+		plan.SetError(fmt.Errorf("build nlb remote model error: %s", err.Error()))
+		return plan
+	}
+	if lbId := remote.LoadBalancerAttribute.LoadBalancerId; lbId != "" {
+		plan.LoadBalancerID = lbId
+		tags, err := m.cloud.ListNLBTagResources(reqCtx.Ctx, lbId)
+		if err != nil {
+			plan.SetError(fmt.Errorf("ListNLBTagResources: %s", err.Error()))
+			return plan
+		}
+		remote.LoadBalancerAttribute.Tags = tags
+	}
+
+	plan.Add(buildChanges(reqCtx, local, remote)...)
+	return plan
+}
+
+func buildChanges(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.NetworkLoadBalancer) []dryrun.Change {
+	var changes []dryrun.Change
+	lbId := remote.LoadBalancerAttribute.LoadBalancerId
+
+	if helper.NeedDeleteLoadBalancer(reqCtx.Service) || !helper.NeedNLB(reqCtx.Service) {
+		if lbId == "" {
+			return nil
+		}
+		if !local.LoadBalancerAttribute.IsUserManaged && !local.LoadBalancerAttribute.PreserveOnDelete {
+			return append(changes, dryrun.Change{
+				Action:   dryrun.ActionDelete,
+				Resource: dryrun.ResourceLoadBalancer,
+				ID:       lbId,
+				Name:     remote.LoadBalancerAttribute.Name,
+				Before:   dryrun.Attributes(remote.LoadBalancerAttribute, ignoredLoadBalancerFields...),
+			})
+		}
+		// the listeners and server groups of the service are removed from the reused nlb
+		local.Listeners, local.ServerGroups = nil, nil
+	} else if lbId == "" {
+		changes = append(changes, dryrun.Change{
+			Action:   dryrun.ActionCreate,
+			Resource: dryrun.ResourceLoadBalancer,
+			Name:     local.LoadBalancerAttribute.Name,
+			After:    dryrun.Attributes(local.LoadBalancerAttribute, ignoredLoadBalancerFields...),
+		})
+		if len(local.LoadBalancerAttribute.Tags) != 0 {
+			changes = append(changes, *dryrun.TagChange("", nil, local.LoadBalancerAttribute.Tags))
+		}
+	} else {
+		before, after := dryrun.Diff(remote.LoadBalancerAttribute, local.LoadBalancerAttribute, ignoredLoadBalancerFields...)
+		if len(after) != 0 {
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionUpdate,
+				Resource: dryrun.ResourceLoadBalancer,
+				ID:       lbId,
+				Name:     remote.LoadBalancerAttribute.Name,
+				Before:   before,
+				After:    after,
+			})
+		}
+		if c := dryrun.TagChange(lbId, remote.LoadBalancerAttribute.Tags, local.LoadBalancerAttribute.Tags); c != nil {
+			changes = append(changes, *c)
+		}
+	}
+
+	changes = append(changes, buildServerGroupChanges(reqCtx, local, remote)...)
+	if !local.LoadBalancerAttribute.IsUserManaged || reqCtx.Anno.IsForceOverride() {
+		changes = append(changes, buildListenerChanges(reqCtx, local, remote)...)
+	}
+	return changes
+}
+
+func findRemoteServerGroup(l *nlbmodel.ServerGroup, remote []*nlbmodel.ServerGroup) *nlbmodel.ServerGroup {
+	for _, r := range remote {
+		if (l.ServerGroupId != "" && l.ServerGroupId == r.ServerGroupId) ||
+			(l.ServerGroupId == "" && l.ServerGroupName == r.ServerGroupName) {
+			return r
+		}
+	}
+	return nil
+}
+
+func buildServerGroupChanges(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.NetworkLoadBalancer) []dryrun.Change {
+	var changes []dryrun.Change
+	matched := map[string]bool{}
+	for _, l := range local.ServerGroups {
+		r := findRemoteServerGroup(l, remote.ServerGroups)
+		if r != nil && l.ServerGroupType != "" && l.ServerGroupType != r.ServerGroupType && !l.IsUserManaged {
+			// the server group is recreated if its type changed
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionDelete,
+				Resource: dryrun.ResourceServerGroup,
+				ID:       r.ServerGroupId,
+				Name:     r.ServerGroupName,
+			})
+			matched[r.ServerGroupId] = true
+			r = nil
+		}
+		if r == nil {
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionCreate,
+				Resource: dryrun.ResourceServerGroup,
+				Name:     l.ServerGroupName,
+				After:    dryrun.Attributes(l, ignoredServerGroupFields...),
+			})
+			changes = append(changes, serverChanges(dryrun.ActionCreate, l.ServerGroupName, l.Servers)...)
+			continue
+		}
+		matched[r.ServerGroupId] = true
+
+		if !l.IsUserManaged {
+			before, after := dryrun.Diff(r, l, ignoredServerGroupFields...)
+			if len(after) != 0 {
+				changes = append(changes, dryrun.Change{
+					Action:   dryrun.ActionUpdate,
+					Resource: dryrun.ResourceServerGroup,
+					ID:       r.ServerGroupId,
+					Name:     r.ServerGroupName,
+					Before:   before,
+					After:    after,
+				})
+			}
+		}
+		add, del, update := diff(r, l)
+		changes = append(changes, serverChanges(dryrun.ActionCreate, r.ServerGroupId, add)...)
+		changes = append(changes, serverChanges(dryrun.ActionDelete, r.ServerGroupId, del)...)
+		for _, u := range update {
+			for _, s := range r.Servers {
+				if isServerEqual(u, s) {
+					before, after := dryrun.Diff(s, u, ignoredServerFields...)
+					changes = append(changes, dryrun.Change{
+						Action:   dryrun.ActionUpdate,
+						Resource: dryrun.ResourceBackend,
+						ID:       serverID(u),
+						Parent:   r.ServerGroupId,
+						Before:   before,
+						After:    after,
+					})
+					break
+				}
+			}
+		}
+	}
+
+	for _, r := range remote.ServerGroups {
+		if matched[r.ServerGroupId] {
+			continue
+		}
+		if local.LoadBalancerAttribute.PreserveOnDelete {
+			// the tags of the server group are removed instead
+			continue
+		}
+		if r.NamedKey != nil && !r.IsUserManaged && r.NamedKey.IsManagedByService(reqCtx.Service, base.CLUSTER_ID) {
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionDelete,
+				Resource: dryrun.ResourceServerGroup,
+				ID:       r.ServerGroupId,
+				Name:     r.ServerGroupName,
+			})
+			continue
+		}
+		// the servers of the service are removed from the user managed server group
+		var del []nlbmodel.ServerGroupServer
+		for _, s := range r.Servers {
+			if !s.IsUserManaged {
+				del = append(del, s)
+			}
+		}
+		changes = append(changes, serverChanges(dryrun.ActionDelete, r.ServerGroupId, del)...)
+	}
+	return changes
+}
+
+func buildListenerChanges(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.NetworkLoadBalancer) []dryrun.Change {
+	var changes []dryrun.Change
+	lbId := remote.LoadBalancerAttribute.LoadBalancerId
+	for _, r := range remote.Listeners {
+		found := false
+		for _, l := range local.Listeners {
+			if !isListenerPortMatch(l, r) || r.ListenerProtocol != l.ListenerProtocol {
+				continue
+			}
+			found = true
+			before, after := dryrun.Diff(r, l, ignoredListenerFields...)
+			if len(after) != 0 {
+				changes = append(changes, dryrun.Change{
+					Action:   dryrun.ActionUpdate,
+					Resource: dryrun.ResourceListener,
+					ID:       r.ListenerId,
+					Name:     listenerName(l),
+					Parent:   lbId,
+					Before:   before,
+					After:    after,
+				})
+			}
+		}
+		if found {
+			continue
+		}
+		if local.LoadBalancerAttribute.IsUserManaged &&
+			(r.NamedKey == nil || !r.NamedKey.IsManagedByService(reqCtx.Service, base.CLUSTER_ID)) {
+			continue
+		}
+		changes = append(changes, dryrun.Change{
+			Action:   dryrun.ActionDelete,
+			Resource: dryrun.ResourceListener,
+			ID:       r.ListenerId,
+			Name:     listenerName(r),
+			Parent:   lbId,
+		})
+	}
+
+	for _, l := range local.Listeners {
+		found := false
+		for _, r := range remote.Listeners {
+			if isListenerPortMatch(l, r) && r.ListenerProtocol == l.ListenerProtocol {
+				found = true
+				break
+			}
+		}
+		if !found {
+			changes = append(changes, dryrun.Change{
+				Action:   dryrun.ActionCreate,
+				Resource: dryrun.ResourceListener,
+				Name:     listenerName(l),
+				Parent:   lbId,
+				After:    dryrun.Attributes(l, ignoredListenerFields...),
+			})
+		}
+	}
+	return changes
+}
+
+func serverChanges(action dryrun.Action, sg string, servers []nlbmodel.ServerGroupServer) []dryrun.Change {
+	var changes []dryrun.Change
+	for _, s := range servers {
+		c := dryrun.Change{
+			Action:   action,
+			Resource: dryrun.ResourceBackend,
+			ID:       serverID(s),
+			Parent:   sg,
+		}
+		attrs := dryrun.Attributes(s, ignoredServerFields...)
+		if action == dryrun.ActionDelete {
+			c.Before = attrs
+		} else {
+			c.After = attrs
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+func serverID(s nlbmodel.ServerGroupServer) string {
+	if s.ServerType == nlbmodel.EcsServerType {
+		return fmt.Sprintf("%s:%d", s.ServerId, s.Port)
+	}
+	return fmt.Sprintf("%s/%s:%d", s.ServerId, s.ServerIp, s.Port)
+}
+
+func listenerName(l *nlbmodel.ListenerAttribute) string {
+	return fmt.Sprintf("%s:%s", l.ListenerProtocol, l.PortString())
+}
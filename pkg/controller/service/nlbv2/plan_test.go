@@ -0,0 +1,93 @@
+package nlbv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+	"k8s.io/utils/pointer"
+)
+
+func getPlanTestService() *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ServiceName,
+			Namespace:   v1.NamespaceDefault,
+			Annotations: map[string]string{},
+		},
+		Spec: v1.ServiceSpec{
+			Type:              v1.ServiceTypeLoadBalancer,
+			LoadBalancerClass: pointer.String(helper.NLBClass),
+		},
+	}
+}
+
+func TestBuildChanges_UpdateNLB(t *testing.T) {
+	reqCtx := getReqCtx(getPlanTestService())
+	local := &nlbmodel.NetworkLoadBalancer{
+		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{Name: "nlb-new"},
+		Listeners: []*nlbmodel.ListenerAttribute{
+			{ListenerProtocol: nlbmodel.TCP, ListenerPort: 80, IdleTimeout: 60},
+			{ListenerProtocol: nlbmodel.TCP, ListenerPort: 443},
+		},
+		ServerGroups: []*nlbmodel.ServerGroup{{
+			ServerGroupName: "sg-80",
+			Scheduler:       "Wrr",
+			Servers: []nlbmodel.ServerGroupServer{
+				{ServerId: "ecs-1", ServerType: nlbmodel.EcsServerType, Port: 30080, Weight: 100},
+			},
+		}},
+	}
+	remote := &nlbmodel.NetworkLoadBalancer{
+		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{LoadBalancerId: "nlb-id", Name: "nlb-old"},
+		Listeners: []*nlbmodel.ListenerAttribute{
+			{ListenerProtocol: nlbmodel.TCP, ListenerPort: 80, IdleTimeout: 900, ListenerId: "lsn-80"},
+		},
+		ServerGroups: []*nlbmodel.ServerGroup{{
+			ServerGroupId:   "sgp-1",
+			ServerGroupName: "sg-80",
+			Scheduler:       "Wrr",
+			Servers: []nlbmodel.ServerGroupServer{
+				{ServerId: "ecs-2", ServerType: nlbmodel.EcsServerType, Port: 30080, Weight: 100},
+			},
+		}},
+	}
+
+	changes := buildChanges(reqCtx, local, remote)
+	var actions []string
+	for _, c := range changes {
+		actions = append(actions, string(c.Action)+" "+c.Resource+" "+c.ID)
+	}
+	assert.Equal(t, []string{
+		"Update LoadBalancer nlb-id",
+		"Create Backend ecs-1:30080",
+		"Delete Backend ecs-2:30080",
+		"Update Listener lsn-80",
+		"Create Listener ",
+	}, actions)
+	assert.Equal(t, map[string]interface{}{"IdleTimeout": float64(900)}, changes[3].Before)
+	assert.Equal(t, map[string]interface{}{"IdleTimeout": float64(60)}, changes[3].After)
+	assert.Equal(t, "TCP:443", changes[4].Name)
+}
+
+func TestBuildChanges_DeleteNLB(t *testing.T) {
+	svc := getPlanTestService()
+	svc.Spec.Type = v1.ServiceTypeClusterIP
+	reqCtx := getReqCtx(svc)
+	local := &nlbmodel.NetworkLoadBalancer{LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{}}
+	remote := &nlbmodel.NetworkLoadBalancer{
+		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{LoadBalancerId: "nlb-id"},
+	}
+
+	changes := buildChanges(reqCtx, local, remote)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, dryrun.ActionDelete, changes[0].Action)
+	assert.Equal(t, dryrun.ResourceLoadBalancer, changes[0].Resource)
+
+	local.LoadBalancerAttribute.PreserveOnDelete = true
+	assert.Empty(t, buildChanges(reqCtx, local, remote))
+}
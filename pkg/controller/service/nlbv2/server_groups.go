@@ -155,7 +155,7 @@ func (mgr *ServerGroupManager) updateServerGroupENIBackendID(reqCtx *svcCtx.Requ
 		if len(ipSet.set) == 0 {
 			continue
 		}
-		r, err := mgr.cloud.DescribeNetworkInterfaces(mgr.vpcId, ipSet.set.UnsortedList(), ipSet.ver)
+		r, err := mgr.cloud.DescribeNetworkInterfaces(reqCtx.Ctx, mgr.vpcId, ipSet.set.UnsortedList(), ipSet.ver)
 		if err != nil {
 			return fmt.Errorf("call DescribeNetworkInterfaces: %w", err)
 		}
@@ -46,6 +46,11 @@ const (
 	IgnoreWeightUpdate     = AnnotationLoadBalancerPrefix + "ignore-weight-update"
 
 	PreserveLBOnDelete = AnnotationLoadBalancerPrefix + "preserve-lb-on-delete"
+
+	DriftPolicy       = AnnotationLoadBalancerPrefix + "drift-policy"        // DriftPolicy report, enforce or disabled, overrides --drift-policy
+	DriftIgnoreFields = AnnotationLoadBalancerPrefix + "drift-ignore-fields" // DriftIgnoreFields For example: "Backend,Listener.AclId", added to --drift-ignore-fields
+
+	LoadBalancerConfiguration = AnnotationLoadBalancerPrefix + "configuration" // LoadBalancerConfiguration the name of the LoadBalancerConfiguration in the namespace of the service
 )
 
 // classic load balancer
@@ -141,10 +146,15 @@ var DefaultValue = map[string]string{
 	composite(AnnotationPrefix, ModificationProtection): string(model.ConsoleProtection),
 }
 
-type AnnotationRequest struct{ Service *v1.Service }
+type AnnotationRequest struct {
+	Service *v1.Service
+	// Config are the values of the LoadBalancerConfiguration of the service by annotation keys without
+	// prefix, they are used when the service does not have the annotations
+	Config map[string]string
+}
 
 func NewAnnotationRequest(svc *v1.Service) *AnnotationRequest {
-	return &AnnotationRequest{svc}
+	return &AnnotationRequest{Service: svc}
 }
 
 func (n *AnnotationRequest) Get(k string) string {
@@ -152,23 +162,21 @@ func (n *AnnotationRequest) Get(k string) string {
 		return ""
 	}
 
-	if n.Service.Annotations == nil {
-		return ""
-	}
-
-	key := composite(AnnotationPrefix, k)
-	v, ok := n.Service.Annotations[key]
-	if ok {
-		return v
-	}
+	if n.Service.Annotations != nil {
+		key := composite(AnnotationPrefix, k)
+		v, ok := n.Service.Annotations[key]
+		if ok {
+			return v
+		}
 
-	lkey := composite(AnnotationLegacyPrefix, k)
-	v, ok = n.Service.Annotations[lkey]
-	if ok {
-		return v
+		lkey := composite(AnnotationLegacyPrefix, k)
+		v, ok = n.Service.Annotations[lkey]
+		if ok {
+			return v
+		}
 	}
 
-	return ""
+	return n.Config[k]
 }
 
 func (n *AnnotationRequest) Has(k string) bool {
@@ -176,21 +184,20 @@ func (n *AnnotationRequest) Has(k string) bool {
 		return false
 	}
 
-	if n.Service.Annotations == nil {
-		return false
-	}
-
-	key := composite(AnnotationPrefix, k)
-	if _, ok := n.Service.Annotations[key]; ok {
-		return true
-	}
+	if n.Service.Annotations != nil {
+		key := composite(AnnotationPrefix, k)
+		if _, ok := n.Service.Annotations[key]; ok {
+			return true
+		}
 
-	key = composite(AnnotationLegacyPrefix, k)
-	if _, ok := n.Service.Annotations[key]; ok {
-		return true
+		key = composite(AnnotationLegacyPrefix, k)
+		if _, ok := n.Service.Annotations[key]; ok {
+			return true
+		}
 	}
 
-	return false
+	_, ok := n.Config[k]
+	return ok
 }
 
 func (n *AnnotationRequest) GetDefaultValue(k string) string {
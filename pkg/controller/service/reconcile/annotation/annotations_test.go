@@ -22,6 +22,21 @@ func TestGet(t *testing.T) {
 	assert.Equal(t, anno.Get(OverrideListener), "false")
 }
 
+func TestGet_Config(t *testing.T) {
+	svc := getDefaultService()
+	anno := &AnnotationRequest{Service: svc, Config: map[string]string{Scheduler: "wrr", AclStatus: "on"}}
+	assert.Equal(t, anno.Get(Scheduler), "wrr")
+	assert.True(t, anno.Has(AclStatus))
+	assert.False(t, anno.Has(AclID))
+
+	// the annotations take precedence over the configuration
+	svc.Annotations["service.beta.kubernetes.io/alicloud-loadbalancer-scheduler"] = "rr"
+	assert.Equal(t, anno.Get(Scheduler), "rr")
+
+	svc.Annotations = nil
+	assert.Equal(t, anno.Get(Scheduler), "wrr")
+}
+
 func TestGetLoadBalancerAdditionalTags(t *testing.T) {
 	svc := getDefaultService()
 	anno := NewAnnotationRequest(svc)
@@ -0,0 +1,282 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+)
+
+const (
+	// maxReportedChanges is the max number of changes in the message of a drift event
+	maxReportedChanges = 5
+	// enqueueBufferSize is the size of the channel the drifted services are enqueued by
+	enqueueBufferSize = 100
+)
+
+// Policy decides what to do with the drift of a service
+type Policy struct {
+	// Mode is one of report, enforce and disabled
+	Mode string
+	// IgnoreFields are the resources, e.g. Backend, or the fields of the resources, e.g. Listener.AclId,
+	// whose changes are not drift
+	IgnoreFields []string
+}
+
+// PolicyOf returns the policy of the service, the annotations of the service override the global policy
+// and add the ignored fields to the global ones. An unknown mode is regarded as report.
+func PolicyOf(svc *v1.Service) Policy {
+	anno := annotation.NewAnnotationRequest(svc)
+	p := Policy{
+		Mode:         ctrlCfg.ControllerCFG.DriftPolicy,
+		IgnoreFields: append([]string{}, ctrlCfg.ControllerCFG.DriftIgnoreFields...),
+	}
+	if mode := anno.Get(annotation.DriftPolicy); mode != "" {
+		p.Mode = mode
+	}
+	switch p.Mode {
+	case ctrlCfg.DriftPolicyEnforce, ctrlCfg.DriftPolicyDisabled:
+	default:
+		p.Mode = ctrlCfg.DriftPolicyReport
+	}
+	for _, f := range strings.Split(anno.Get(annotation.DriftIgnoreFields), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			p.IgnoreFields = append(p.IgnoreFields, f)
+		}
+	}
+	return p
+}
+
+// Filter removes the changes of the ignored resources and fields. A field is ignored by <Resource>.<Field>,
+// and all the changes of a resource are ignored by <Resource>, case-insensitively.
+func Filter(changes []dryrun.Change, ignoreFields []string) []dryrun.Change {
+	var ret []dryrun.Change
+	for _, c := range changes {
+		if isIgnored(ignoreFields, c.Resource, "") {
+			continue
+		}
+		after, ok := c.After.(map[string]interface{})
+		if c.Action == dryrun.ActionUpdate && ok {
+			before, _ := c.Before.(map[string]interface{})
+			b, a := map[string]interface{}{}, map[string]interface{}{}
+			for k, v := range after {
+				if isIgnored(ignoreFields, c.Resource, k) {
+					continue
+				}
+				a[k] = v
+				if bv, ok := before[k]; ok {
+					b[k] = bv
+				}
+			}
+			if len(a) == 0 {
+				continue
+			}
+			c.Before, c.After = b, a
+		}
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+func isIgnored(ignoreFields []string, resource, field string) bool {
+	for _, f := range ignoreFields {
+		r, fd := f, ""
+		if i := strings.Index(f, "."); i >= 0 {
+			r, fd = f[:i], f[i+1:]
+		}
+		if !strings.EqualFold(r, resource) {
+			continue
+		}
+		if fd == "" || strings.EqualFold(fd, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// Detector periodically compares the loadbalancers of the services with their local models to find the
+// changes made out of the controller, e.g. in the console. The drift is reported by the ccm_drift_detected
+// metric and the events of the services, and the services with the enforce policy are reconciled to
+// correct it.
+type Detector struct {
+	lbType     string
+	kubeClient client.Client
+	record     record.EventRecorder
+	period     time.Duration
+	// need returns true if the loadbalancer of the service is managed by the controller
+	need func(svc *v1.Service) bool
+	// plan returns the changes a reconcile of the service would make
+	plan    func(ctx context.Context, svc *v1.Service) *dryrun.Plan
+	enqueue chan event.GenericEvent
+
+	// drifted are the services whose drift metrics are set
+	drifted map[types.NamespacedName]bool
+}
+
+func NewDetector(
+	lbType string,
+	kubeClient client.Client,
+	record record.EventRecorder,
+	need func(svc *v1.Service) bool,
+	plan func(ctx context.Context, svc *v1.Service) *dryrun.Plan,
+) *Detector {
+	return &Detector{
+		lbType:     lbType,
+		kubeClient: kubeClient,
+		record:     record,
+		period:     ctrlCfg.ControllerCFG.DriftDetectionPeriod,
+		need:       need,
+		plan:       plan,
+		enqueue:    make(chan event.GenericEvent, enqueueBufferSize),
+		drifted:    map[types.NamespacedName]bool{},
+	}
+}
+
+// Source is watched by the controller to reconcile the services whose drift is enforced
+func (d *Detector) Source() source.Source {
+	return &source.Channel{Source: d.enqueue}
+}
+
+// Start function will not be called until the resource lock is acquired
+func (d *Detector) Start(ctx context.Context) error {
+	if d.period <= 0 || ctrlCfg.ControllerCFG.DryRun {
+		klog.Infof("%s drift detection is disabled, period: %s", d.lbType, d.period)
+		return nil
+	}
+	// the services are reconciled when the controller starts, wait for a period before the first detection
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-time.After(d.period):
+	}
+	for {
+		d.Detect(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(d.period):
+		}
+	}
+}
+
+// Detect compares the loadbalancers of all the services with their local models once
+func (d *Detector) Detect(ctx context.Context) {
+	svcs := &v1.ServiceList{}
+	if err := d.kubeClient.List(ctx, svcs); err != nil {
+		klog.Errorf("%s drift detection: list services error: %s", d.lbType, err.Error())
+		return
+	}
+
+	drifted := map[types.NamespacedName]bool{}
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		key := util.NamespacedName(svc)
+		// the changes of the services not reconciled yet are not drift
+		if !d.need(svc) || svc.DeletionTimestamp != nil || helper.IsServiceHashChanged(svc) {
+			continue
+		}
+		policy := PolicyOf(svc)
+		if policy.Mode == ctrlCfg.DriftPolicyDisabled {
+			continue
+		}
+
+		plan := d.plan(ctx, svc)
+		if plan.Error != "" {
+			klog.Warningf("%s drift detection: skip service %s, error: %s", d.lbType, key, plan.Error)
+			// keep the metric until the next successful detection
+			drifted[key] = d.drifted[key]
+			continue
+		}
+		changes := Filter(plan.Changes, policy.IgnoreFields)
+		if len(changes) == 0 {
+			continue
+		}
+		drifted[key] = true
+		metric.DriftDetected.WithLabelValues(d.lbType, svc.Namespace, svc.Name).Set(float64(len(changes)))
+		d.report(ctx, svc, policy, plan.LoadBalancerID, changes)
+	}
+
+	for key := range d.drifted {
+		if !drifted[key] {
+			metric.DriftDetected.DeleteLabelValues(d.lbType, key.Namespace, key.Name)
+		}
+	}
+	d.drifted = drifted
+}
+
+func (d *Detector) report(ctx context.Context, svc *v1.Service, policy Policy, lbId string, changes []dryrun.Change) {
+	summary := Summary(changes)
+	if policy.Mode != ctrlCfg.DriftPolicyEnforce {
+		klog.Infof("%s drift detection: service %s drifted: %s", d.lbType, util.Key(svc), summary)
+		d.record.Eventf(svc, v1.EventTypeWarning, helper.DriftDetected,
+			"Loadbalancer %s drifted from the service: %s", lbId, summary)
+		return
+	}
+
+	klog.Infof("%s drift detection: service %s drifted, correcting: %s", d.lbType, util.Key(svc), summary)
+	d.record.Eventf(svc, v1.EventTypeWarning, helper.DriftDetected,
+		"Loadbalancer %s drifted from the service, correcting: %s", lbId, summary)
+	// the attributes of the loadbalancer and listeners are applied only if the hash of the service changed
+	if err := d.removeServiceHash(ctx, svc); err != nil {
+		klog.Errorf("%s drift detection: %s", d.lbType, err.Error())
+		return
+	}
+	select {
+	case d.enqueue <- event.GenericEvent{Object: svc}:
+	case <-ctx.Done():
+	}
+}
+
+func (d *Detector) removeServiceHash(ctx context.Context, svc *v1.Service) error {
+	updated := svc.DeepCopy()
+	delete(updated.Labels, helper.LabelServiceHash)
+	if err := d.kubeClient.Patch(ctx, updated, client.MergeFrom(svc)); err != nil {
+		return fmt.Errorf("%s failed to remove service hash, error: %s", util.Key(svc), err.Error())
+	}
+	return nil
+}
+
+// Summary describes the changes in a line, e.g. "Update Listener tcp:80 (AclId, AclStatus)"
+func Summary(changes []dryrun.Change) string {
+	var ret []string
+	for i, c := range changes {
+		if i == maxReportedChanges {
+			ret = append(ret, fmt.Sprintf("and %d more", len(changes)-maxReportedChanges))
+			break
+		}
+		s := fmt.Sprintf("%s %s", c.Action, c.Resource)
+		name := c.Name
+		if name == "" {
+			name = c.ID
+		}
+		if name != "" {
+			s += " " + name
+		}
+		if after, ok := c.After.(map[string]interface{}); ok && c.Action == dryrun.ActionUpdate {
+			var fields []string
+			for k := range after {
+				fields = append(fields, k)
+			}
+			sort.Strings(fields)
+			s += fmt.Sprintf(" (%s)", strings.Join(fields, ", "))
+		}
+		ret = append(ret, s)
+	}
+	return strings.Join(ret, ", ")
+}
@@ -0,0 +1,169 @@
+package drift
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/dryrun"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+)
+
+func newService(name string, annotations map[string]string) *v1.Service {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   v1.NamespaceDefault,
+			Name:        name,
+			Annotations: annotations,
+			Finalizers:  []string{helper.ServiceFinalizer},
+		},
+		Spec: v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	// the service has been reconciled
+	svc.Labels = map[string]string{helper.LabelServiceHash: helper.GetServiceHash(svc)}
+	return svc
+}
+
+func listenerChange() dryrun.Change {
+	return dryrun.Change{
+		Action:   dryrun.ActionUpdate,
+		Resource: dryrun.ResourceListener,
+		Name:     "tcp:80",
+		Parent:   "lb-1",
+		Before:   map[string]interface{}{"AclId": "acl-2", "Scheduler": "rr"},
+		After:    map[string]interface{}{"AclId": "acl-1", "Scheduler": "wrr"},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	backend := dryrun.Change{Action: dryrun.ActionCreate, Resource: dryrun.ResourceBackend, ID: "ecs-1:30080", Parent: "rsp-1"}
+	changes := []dryrun.Change{listenerChange(), backend}
+
+	assert.Equal(t, changes, Filter(changes, nil))
+	assert.Equal(t, []dryrun.Change{listenerChange()}, Filter(changes, []string{"backend"}))
+
+	filtered := Filter(changes, []string{"Listener.aclid"})
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, map[string]interface{}{"Scheduler": "rr"}, filtered[0].Before)
+	assert.Equal(t, map[string]interface{}{"Scheduler": "wrr"}, filtered[0].After)
+	// the changes are not modified
+	assert.Equal(t, listenerChange(), changes[0])
+
+	assert.Empty(t, Filter(changes, []string{"Listener.AclId", "Listener.Scheduler", "Backend"}))
+}
+
+func TestPolicyOf(t *testing.T) {
+	ctrlCfg.ControllerCFG.DriftPolicy = ctrlCfg.DriftPolicyReport
+	ctrlCfg.ControllerCFG.DriftIgnoreFields = []string{"Backend"}
+	defer func() { ctrlCfg.ControllerCFG.DriftIgnoreFields = nil }()
+
+	p := PolicyOf(newService("test", nil))
+	assert.Equal(t, ctrlCfg.DriftPolicyReport, p.Mode)
+	assert.Equal(t, []string{"Backend"}, p.IgnoreFields)
+
+	p = PolicyOf(newService("test", map[string]string{
+		annotation.Annotation(annotation.DriftPolicy):       ctrlCfg.DriftPolicyEnforce,
+		annotation.Annotation(annotation.DriftIgnoreFields): "Listener.AclId, Tag",
+	}))
+	assert.Equal(t, ctrlCfg.DriftPolicyEnforce, p.Mode)
+	assert.Equal(t, []string{"Backend", "Listener.AclId", "Tag"}, p.IgnoreFields)
+
+	p = PolicyOf(newService("test", map[string]string{annotation.Annotation(annotation.DriftPolicy): "unknown"}))
+	assert.Equal(t, ctrlCfg.DriftPolicyReport, p.Mode)
+}
+
+func TestSummary(t *testing.T) {
+	assert.Equal(t, "Update Listener tcp:80 (AclId, Scheduler), Delete ServerGroup rsp-1",
+		Summary([]dryrun.Change{listenerChange(), {Action: dryrun.ActionDelete, Resource: dryrun.ResourceServerGroup, ID: "rsp-1"}}))
+
+	var changes []dryrun.Change
+	for i := 0; i < maxReportedChanges+2; i++ {
+		changes = append(changes, dryrun.Change{Action: dryrun.ActionCreate, Resource: dryrun.ResourceBackend})
+	}
+	assert.Contains(t, Summary(changes), "and 2 more")
+}
+
+func gaugeValue(t *testing.T, g prometheus.Gauge) float64 {
+	m := &dto.Metric{}
+	assert.NoError(t, g.Write(m))
+	return m.GetGauge().GetValue()
+}
+
+func countMetrics(c prometheus.Collector) int {
+	ch := make(chan prometheus.Metric, 10)
+	c.Collect(ch)
+	close(ch)
+	return len(ch)
+}
+
+func newDetector(kubeClient client.Client, recorder record.EventRecorder, changes map[string][]dryrun.Change) *Detector {
+	return NewDetector(metric.CLBType, kubeClient, recorder,
+		func(svc *v1.Service) bool { return helper.HasFinalizer(svc, helper.ServiceFinalizer) },
+		func(ctx context.Context, svc *v1.Service) *dryrun.Plan {
+			plan := dryrun.NewPlan("Service", util.NamespacedName(svc))
+			plan.LoadBalancerID = "lb-1"
+			plan.Add(changes[svc.Name]...)
+			return plan
+		},
+	)
+}
+
+func TestDetect_Report(t *testing.T) {
+	ctrlCfg.ControllerCFG.DriftPolicy = ctrlCfg.DriftPolicyReport
+	drifted := newService("drifted", nil)
+	synced := newService("synced", nil)
+	// the changes of a service not reconciled yet are not drift
+	pending := newService("pending", nil)
+	pending.Labels = nil
+	disabled := newService("disabled", map[string]string{annotation.Annotation(annotation.DriftPolicy): ctrlCfg.DriftPolicyDisabled})
+	kubeClient := fake.NewClientBuilder().WithObjects(drifted, synced, pending, disabled).Build()
+	recorder := record.NewFakeRecorder(10)
+	changes := map[string][]dryrun.Change{
+		"drifted":  {listenerChange()},
+		"pending":  {listenerChange()},
+		"disabled": {listenerChange()},
+	}
+	d := newDetector(kubeClient, recorder, changes)
+
+	d.Detect(context.TODO())
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Loadbalancer lb-1 drifted from the service: Update Listener tcp:80 (AclId, Scheduler)")
+	assert.Equal(t, float64(1), gaugeValue(t, metric.DriftDetected.WithLabelValues(metric.CLBType, "default", "drifted")))
+	assert.Empty(t, d.enqueue)
+
+	// the metric is removed after the drift is corrected
+	delete(changes, "drifted")
+	d.Detect(context.TODO())
+	assert.Equal(t, 0, countMetrics(metric.DriftDetected))
+}
+
+func TestDetect_Enforce(t *testing.T) {
+	ctrlCfg.ControllerCFG.DriftPolicy = ctrlCfg.DriftPolicyEnforce
+	defer func() { ctrlCfg.ControllerCFG.DriftPolicy = ctrlCfg.DriftPolicyReport }()
+	svc := newService("drifted", nil)
+	kubeClient := fake.NewClientBuilder().WithObjects(svc).Build()
+	recorder := record.NewFakeRecorder(10)
+	d := newDetector(kubeClient, recorder, map[string][]dryrun.Change{"drifted": {listenerChange()}})
+
+	d.Detect(context.TODO())
+	assert.Contains(t, <-recorder.Events, "correcting")
+	e := <-d.enqueue
+	assert.Equal(t, "drifted", e.Object.GetName())
+	// the hash is removed to apply the loadbalancer and listeners
+	got := &v1.Service{}
+	assert.NoError(t, kubeClient.Get(context.TODO(), util.NamespacedName(svc), got))
+	assert.NotContains(t, got.Labels, helper.LabelServiceHash)
+	metric.DriftDetected.Reset()
+}
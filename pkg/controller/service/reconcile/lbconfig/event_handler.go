@@ -0,0 +1,114 @@
+package lbconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+// Watch makes the controller reconcile the services using a LoadBalancerConfiguration when it changes.
+// The watch is skipped if the crd is not installed, and the controller needs a restart to watch the
+// configurations after the crd is installed.
+func Watch(c controller.Controller, mgr manager.Manager, need func(svc *v1.Service) bool) error {
+	gvk := albv1.SchemeGroupVersion.WithKind("LoadBalancerConfiguration")
+	if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			klog.Warningf("crd of %s is not installed, skip watching it", gvk.Kind)
+			return nil
+		}
+		return fmt.Errorf("get rest mapping of %s error: %s", gvk.Kind, err.Error())
+	}
+	return c.Watch(source.Kind(mgr.GetCache(), &albv1.LoadBalancerConfiguration{}),
+		NewEnqueueRequestForConfigurationEvent(mgr.GetClient(), need))
+}
+
+// NewEnqueueRequestForConfigurationEvent, event handler for LoadBalancerConfiguration event. The services
+// using the configuration are enqueued, need returns true if the service is managed by the controller.
+func NewEnqueueRequestForConfigurationEvent(kubeClient client.Client, need func(svc *v1.Service) bool) *enqueueRequestForConfigurationEvent {
+	return &enqueueRequestForConfigurationEvent{kubeClient: kubeClient, need: need}
+}
+
+type enqueueRequestForConfigurationEvent struct {
+	kubeClient client.Client
+	need       func(svc *v1.Service) bool
+}
+
+var _ handler.EventHandler = (*enqueueRequestForConfigurationEvent)(nil)
+
+func (h *enqueueRequestForConfigurationEvent) Create(ctx context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueReferencingServices(ctx, queue, util.NamespacedName(e.Object))
+}
+
+func (h *enqueueRequestForConfigurationEvent) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldCfg, ok1 := e.ObjectOld.(*albv1.LoadBalancerConfiguration)
+	newCfg, ok2 := e.ObjectNew.(*albv1.LoadBalancerConfiguration)
+	if ok1 && ok2 && !reflect.DeepEqual(oldCfg.Spec, newCfg.Spec) {
+		h.enqueueReferencingServices(ctx, queue, util.NamespacedName(newCfg))
+	}
+}
+
+func (h *enqueueRequestForConfigurationEvent) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	h.enqueueReferencingServices(ctx, queue, util.NamespacedName(e.Object))
+}
+
+func (h *enqueueRequestForConfigurationEvent) Generic(_ context.Context, _ event.GenericEvent, _ workqueue.RateLimitingInterface) {
+	// unknown event, ignore
+}
+
+func (h *enqueueRequestForConfigurationEvent) enqueueReferencingServices(
+	ctx context.Context, queue workqueue.RateLimitingInterface, key types.NamespacedName,
+) {
+	opts := []client.ListOption{}
+	if d, ok := DefaultConfiguration(); !ok || d != key {
+		// a configuration other than the default one is used by the services in its namespace only
+		opts = append(opts, client.InNamespace(key.Namespace))
+	}
+	svcs := &v1.ServiceList{}
+	if err := h.kubeClient.List(ctx, svcs, opts...); err != nil {
+		util.ServiceLog.Error(err, "fail to list services for LoadBalancerConfiguration", "configuration", key)
+		return
+	}
+
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		if !h.need(svc) || svc.DeletionTimestamp != nil || !IsReferenced(svc, key) {
+			continue
+		}
+		// the attributes of the loadbalancer and listeners are applied only if the hash of the service changed
+		if err := h.removeServiceHash(ctx, svc); err != nil {
+			util.ServiceLog.Error(err, "LoadBalancerConfiguration changed", "configuration", key)
+		}
+		queue.Add(reconcile.Request{NamespacedName: util.NamespacedName(svc)})
+		util.ServiceLog.Info(fmt.Sprintf("LoadBalancerConfiguration change: enqueue service %s", util.Key(svc)),
+			"configuration", key, "queueLen", queue.Len())
+	}
+}
+
+func (h *enqueueRequestForConfigurationEvent) removeServiceHash(ctx context.Context, svc *v1.Service) error {
+	if _, ok := svc.Labels[helper.LabelServiceHash]; !ok {
+		return nil
+	}
+	updated := svc.DeepCopy()
+	delete(updated.Labels, helper.LabelServiceHash)
+	if err := h.kubeClient.Patch(ctx, updated, client.MergeFrom(svc)); err != nil {
+		return fmt.Errorf("%s failed to remove service hash, error: %s", util.Key(svc), err.Error())
+	}
+	return nil
+}
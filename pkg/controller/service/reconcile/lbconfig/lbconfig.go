@@ -0,0 +1,211 @@
+package lbconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+)
+
+// NewAnnotationRequest returns the annotation request of the service with the values of its
+// LoadBalancerConfiguration, the annotations of the service take precedence over the values.
+func NewAnnotationRequest(ctx context.Context, kubeClient client.Client, svc *v1.Service) (*annotation.AnnotationRequest, error) {
+	config, err := Resolve(ctx, kubeClient, svc)
+	if err != nil {
+		return nil, err
+	}
+	return &annotation.AnnotationRequest{Service: svc, Config: config}, nil
+}
+
+// Resolve returns the values of the LoadBalancerConfiguration referenced by the service merged over the
+// cluster default configuration. A missing default configuration is ignored, while a missing referenced
+// configuration is an error so that a typo in the reference does not silently fall back to the defaults.
+func Resolve(ctx context.Context, kubeClient client.Client, svc *v1.Service) (map[string]string, error) {
+	values := map[string]string{}
+	if key, ok := DefaultConfiguration(); ok {
+		cfg := &albv1.LoadBalancerConfiguration{}
+		err := kubeClient.Get(ctx, key, cfg)
+		switch {
+		case err == nil:
+			for k, v := range Values(&cfg.Spec) {
+				values[k] = v
+			}
+		case apierrors.IsNotFound(err) || meta.IsNoMatchError(err):
+		default:
+			return nil, fmt.Errorf("get default LoadBalancerConfiguration %s error: %s", key, err.Error())
+		}
+	}
+
+	key, ok := Reference(svc)
+	if !ok {
+		return values, nil
+	}
+	cfg := &albv1.LoadBalancerConfiguration{}
+	if err := kubeClient.Get(ctx, key, cfg); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, fmt.Errorf("LoadBalancerConfiguration %s is referenced, but the crd is not installed", key)
+		}
+		return nil, fmt.Errorf("get LoadBalancerConfiguration %s error: %s", key, err.Error())
+	}
+	for k, v := range Values(&cfg.Spec) {
+		values[k] = v
+	}
+	return values, nil
+}
+
+// Reference returns the LoadBalancerConfiguration referenced by the annotation of the service
+func Reference(svc *v1.Service) (types.NamespacedName, bool) {
+	name := strings.TrimSpace(annotation.NewAnnotationRequest(svc).Get(annotation.LoadBalancerConfiguration))
+	if name == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: svc.Namespace, Name: name}, true
+}
+
+// DefaultConfiguration returns the cluster default LoadBalancerConfiguration set by
+// --default-loadbalancer-configuration
+func DefaultConfiguration() (types.NamespacedName, bool) {
+	parts := strings.Split(ctrlCfg.ControllerCFG.DefaultLBConfiguration, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, true
+}
+
+// IsReferenced returns true if the values of the LoadBalancerConfiguration are used by the service
+func IsReferenced(svc *v1.Service, key types.NamespacedName) bool {
+	if d, ok := DefaultConfiguration(); ok && d == key {
+		return true
+	}
+	ref, ok := Reference(svc)
+	return ok && ref == key
+}
+
+// Values converts the spec to the values of the annotations by annotation keys without prefix
+func Values(spec *albv1.LoadBalancerConfigurationSpec) map[string]string {
+	values := map[string]string{}
+	setString := func(k, v string) {
+		if v != "" {
+			values[k] = v
+		}
+	}
+	setInt := func(k string, v *int) {
+		if v != nil {
+			values[k] = strconv.Itoa(*v)
+		}
+	}
+	setFlag := func(k string, v *bool) {
+		if v == nil {
+			return
+		}
+		if *v {
+			values[k] = string(model.OnFlag)
+		} else {
+			values[k] = string(model.OffFlag)
+		}
+	}
+	setList := func(k string, v []string) {
+		if len(v) != 0 {
+			values[k] = strings.Join(v, ",")
+		}
+	}
+
+	if lb := spec.LoadBalancer; lb != nil {
+		setString(annotation.AddressType, lb.AddressType)
+		setString(annotation.IPVersion, lb.IPVersion)
+		setString(annotation.Spec, lb.Spec)
+		setString(annotation.InstanceChargeType, lb.InstanceChargeType)
+		setString(annotation.ChargeType, lb.ChargeType)
+		setInt(annotation.Bandwidth, lb.Bandwidth)
+		setString(annotation.VswitchId, lb.VSwitchId)
+		setString(annotation.ResourceGroupId, lb.ResourceGroupId)
+		setString(annotation.MasterZoneID, lb.MasterZoneId)
+		setString(annotation.SlaveZoneID, lb.SlaveZoneId)
+		var zoneMaps []string
+		for _, z := range lb.ZoneMappings {
+			zoneMaps = append(zoneMaps, fmt.Sprintf("%s:%s", z.ZoneId, z.VSwitchId))
+		}
+		setList(annotation.ZoneMaps, zoneMaps)
+		setList(annotation.SecurityGroupIds, lb.SecurityGroupIds)
+		setFlag(annotation.CrossZoneEnabled, lb.CrossZoneEnabled)
+		setFlag(annotation.DeleteProtection, lb.DeletionProtection)
+		setString(annotation.ModificationProtection, lb.ModificationProtection)
+		setList(annotation.AdditionalTags, keyValues(lb.AdditionalTags))
+	}
+
+	if l := spec.Listener; l != nil {
+		setString(annotation.Scheduler, l.Scheduler)
+		setFlag(annotation.AclStatus, l.AclStatus)
+		setString(annotation.AclID, l.AclId)
+		setString(annotation.AclType, l.AclType)
+		var protocolPorts []string
+		for _, pp := range l.ProtocolPorts {
+			protocolPorts = append(protocolPorts, fmt.Sprintf("%s:%d", pp.Protocol, pp.Port))
+		}
+		setList(annotation.ProtocolPort, protocolPorts)
+		setString(annotation.CertID, l.CertId)
+		setString(annotation.TLSCipherPolicy, l.TLSCipherPolicy)
+		setFlag(annotation.EnableHttp2, l.Http2Enabled)
+		setFlag(annotation.ProxyProtocol, l.ProxyProtocol)
+		setInt(annotation.IdleTimeout, l.IdleTimeout)
+		setInt(annotation.RequestTimeout, l.RequestTimeout)
+		setInt(annotation.EstablishedTimeout, l.EstablishedTimeout)
+		setInt(annotation.PersistenceTimeout, l.PersistenceTimeout)
+		setFlag(annotation.ConnectionDrain, l.ConnectionDrain)
+		setInt(annotation.ConnectionDrainTimeout, l.ConnectionDrainTimeout)
+		setFlag(annotation.SessionStick, l.StickySession)
+		setString(annotation.SessionStickType, l.StickySessionType)
+		setString(annotation.Cookie, l.Cookie)
+		setInt(annotation.CookieTimeout, l.CookieTimeout)
+		setFlag(annotation.XForwardedForProto, l.XForwardedForProto)
+	}
+
+	if hc := spec.HealthCheck; hc != nil {
+		// the switch is used by the tcp and udp listeners, and the flag by the http and https listeners
+		// and the server groups of a nlb
+		setFlag(annotation.HealthCheckSwitch, hc.Enabled)
+		setFlag(annotation.HealthCheckFlag, hc.Enabled)
+		setString(annotation.HealthCheckType, hc.Type)
+		setString(annotation.HealthCheckURI, hc.URI)
+		setString(annotation.HealthCheckDomain, hc.Domain)
+		setString(annotation.HealthCheckMethod, hc.Method)
+		setList(annotation.HealthCheckHTTPCode, hc.HTTPCodes)
+		setInt(annotation.HealthCheckConnectPort, hc.ConnectPort)
+		setInt(annotation.HealthCheckConnectTimeout, hc.ConnectTimeout)
+		setInt(annotation.HealthCheckTimeout, hc.Timeout)
+		setInt(annotation.HealthCheckInterval, hc.Interval)
+		setInt(annotation.HealthyThreshold, hc.HealthyThreshold)
+		setInt(annotation.UnhealthyThreshold, hc.UnhealthyThreshold)
+	}
+
+	if b := spec.Backend; b != nil {
+		setList(annotation.BackendLabel, keyValues(b.NodeSelector))
+		setFlag(annotation.RemoveUnscheduled, b.RemoveUnscheduled)
+		setInt(annotation.VGroupWeight, b.Weight)
+		setFlag(annotation.IgnoreWeightUpdate, b.IgnoreWeightUpdate)
+		setFlag(annotation.PreserveClientIp, b.PreserveClientIp)
+	}
+	return values
+}
+
+// keyValues returns the sorted k=v pairs of the map
+func keyValues(m map[string]string) []string {
+	var ret []string
+	for k, v := range m {
+		ret = append(ret, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,171 @@
+package lbconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	albv1 "k8s.io/cloud-provider-alibaba-cloud/pkg/apis/alibabacloud/v1"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/helper"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/service/reconcile/annotation"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func newService(name string, annotations map[string]string) *v1.Service {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   v1.NamespaceDefault,
+			Name:        name,
+			Annotations: annotations,
+		},
+		Spec: v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	svc.Labels = map[string]string{helper.LabelServiceHash: helper.GetServiceHash(svc)}
+	return svc
+}
+
+func newConfiguration(namespace, name string, spec albv1.LoadBalancerConfigurationSpec) *albv1.LoadBalancerConfiguration {
+	return &albv1.LoadBalancerConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       spec,
+	}
+}
+
+func newClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, albv1.SchemeBuilder.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestValues(t *testing.T) {
+	values := Values(&albv1.LoadBalancerConfigurationSpec{
+		LoadBalancer: &albv1.ServiceLoadBalancerSpec{
+			AddressType:        "intranet",
+			Bandwidth:          pointer.Int(10),
+			ZoneMappings:       []albv1.ZoneMapping{{ZoneId: "cn-hangzhou-a", VSwitchId: "vsw-1"}, {ZoneId: "cn-hangzhou-b", VSwitchId: "vsw-2"}},
+			DeletionProtection: pointer.Bool(false),
+			AdditionalTags:     map[string]string{"k2": "v2", "k1": "v1"},
+		},
+		Listener: &albv1.ServiceListenerSpec{
+			ProtocolPorts: []albv1.ProtocolPort{{Protocol: "https", Port: 443}, {Protocol: "http", Port: 80}},
+			AclStatus:     pointer.Bool(true),
+		},
+		HealthCheck: &albv1.ServiceHealthCheckSpec{
+			Enabled:   pointer.Bool(true),
+			HTTPCodes: []string{"http_2xx", "http_3xx"},
+		},
+		Backend: &albv1.ServiceBackendSpec{
+			NodeSelector: map[string]string{"pool": "lb"},
+		},
+	})
+
+	assert.Equal(t, map[string]string{
+		annotation.AddressType:         "intranet",
+		annotation.Bandwidth:           "10",
+		annotation.ZoneMaps:            "cn-hangzhou-a:vsw-1,cn-hangzhou-b:vsw-2",
+		annotation.DeleteProtection:    "off",
+		annotation.AdditionalTags:      "k1=v1,k2=v2",
+		annotation.ProtocolPort:        "https:443,http:80",
+		annotation.AclStatus:           "on",
+		annotation.HealthCheckSwitch:   "on",
+		annotation.HealthCheckFlag:     "on",
+		annotation.HealthCheckHTTPCode: "http_2xx,http_3xx",
+		annotation.BackendLabel:        "pool=lb",
+	}, values)
+}
+
+func TestResolve(t *testing.T) {
+	ctrlCfg.ControllerCFG.DefaultLBConfiguration = "kube-system/default"
+	defer func() { ctrlCfg.ControllerCFG.DefaultLBConfiguration = "" }()
+
+	def := newConfiguration("kube-system", "default", albv1.LoadBalancerConfigurationSpec{
+		LoadBalancer: &albv1.ServiceLoadBalancerSpec{AddressType: "intranet"},
+		Listener:     &albv1.ServiceListenerSpec{Scheduler: "rr"},
+	})
+	cfg := newConfiguration(v1.NamespaceDefault, "wrr", albv1.LoadBalancerConfigurationSpec{
+		Listener: &albv1.ServiceListenerSpec{Scheduler: "wrr"},
+	})
+	kubeClient := newClient(t, def, cfg)
+
+	// the default configuration is used by all the services
+	anno, err := NewAnnotationRequest(context.TODO(), kubeClient, newService("test", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "intranet", anno.Get(annotation.AddressType))
+	assert.Equal(t, "rr", anno.Get(annotation.Scheduler))
+
+	// the referenced configuration overrides the default one, and the annotations override both
+	svc := newService("test", map[string]string{
+		annotation.Annotation(annotation.LoadBalancerConfiguration): "wrr",
+		annotation.Annotation(annotation.AddressType):               "internet",
+	})
+	anno, err = NewAnnotationRequest(context.TODO(), kubeClient, svc)
+	assert.NoError(t, err)
+	assert.Equal(t, "internet", anno.Get(annotation.AddressType))
+	assert.Equal(t, "wrr", anno.Get(annotation.Scheduler))
+
+	// a missing referenced configuration is an error
+	svc = newService("test", map[string]string{annotation.Annotation(annotation.LoadBalancerConfiguration): "not-found"})
+	_, err = NewAnnotationRequest(context.TODO(), kubeClient, svc)
+	assert.Error(t, err)
+
+	// a missing default configuration is ignored
+	ctrlCfg.ControllerCFG.DefaultLBConfiguration = "kube-system/not-found"
+	values, err := Resolve(context.TODO(), kubeClient, newService("test", nil))
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestIsReferenced(t *testing.T) {
+	ctrlCfg.ControllerCFG.DefaultLBConfiguration = "kube-system/default"
+	defer func() { ctrlCfg.ControllerCFG.DefaultLBConfiguration = "" }()
+
+	svc := newService("test", map[string]string{annotation.Annotation(annotation.LoadBalancerConfiguration): "wrr"})
+	assert.True(t, IsReferenced(svc, util.NamespacedName(newConfiguration(v1.NamespaceDefault, "wrr", albv1.LoadBalancerConfigurationSpec{}))))
+	assert.True(t, IsReferenced(svc, util.NamespacedName(newConfiguration("kube-system", "default", albv1.LoadBalancerConfigurationSpec{}))))
+	// the configurations in other namespaces are not referenced
+	assert.False(t, IsReferenced(svc, util.NamespacedName(newConfiguration("other", "wrr", albv1.LoadBalancerConfigurationSpec{}))))
+}
+
+func TestEnqueueRequestForConfigurationEvent(t *testing.T) {
+	cfg := newConfiguration(v1.NamespaceDefault, "wrr", albv1.LoadBalancerConfigurationSpec{
+		Listener: &albv1.ServiceListenerSpec{Scheduler: "wrr"},
+	})
+	referencing := newService("referencing", map[string]string{annotation.Annotation(annotation.LoadBalancerConfiguration): "wrr"})
+	other := newService("other", nil)
+	kubeClient := newClient(t, cfg, referencing, other)
+	h := NewEnqueueRequestForConfigurationEvent(kubeClient, func(svc *v1.Service) bool { return true })
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+
+	// the services are not enqueued if the spec is not changed
+	updated := cfg.DeepCopy()
+	updated.Labels = map[string]string{"app": "test"}
+	h.Update(context.TODO(), event.UpdateEvent{ObjectOld: cfg, ObjectNew: updated}, queue)
+	assert.Equal(t, 0, queue.Len())
+
+	updated.Spec.Listener.Scheduler = "rr"
+	h.Update(context.TODO(), event.UpdateEvent{ObjectOld: cfg, ObjectNew: updated}, queue)
+	assert.Equal(t, 1, queue.Len())
+	item, _ := queue.Get()
+	assert.Equal(t, reconcile.Request{NamespacedName: util.NamespacedName(referencing)}, item)
+
+	// the hash is removed to apply the loadbalancer and listeners
+	got := &v1.Service{}
+	assert.NoError(t, kubeClient.Get(context.TODO(), util.NamespacedName(referencing), got))
+	assert.NotContains(t, got.Labels, helper.LabelServiceHash)
+	assert.NoError(t, kubeClient.Get(context.TODO(), util.NamespacedName(other), got))
+	assert.Contains(t, got.Labels, helper.LabelServiceHash)
+}
@@ -48,20 +48,24 @@ type ALBListenerRuleSpec struct {
 	RuleStatus     string      `json:"RuleStatus" xml:"RuleStatus"`
 	RuleActions    []Action    `json:"RuleActions" xml:"RuleActions"`
 	RuleConditions []Condition `json:"RuleConditions" xml:"RuleConditions"`
+	Direction      string      `json:"Direction" xml:"Direction"`
 }
 
 type ALBServerGroupSpec struct {
-	Protocol            string              `json:"Protocol" xml:"Protocol"`
-	ResourceGroupId     string              `json:"ResourceGroupId" xml:"ResourceGroupId"`
-	Scheduler           string              `json:"Scheduler" xml:"Scheduler"`
-	ServerGroupId       string              `json:"ServerGroupId" xml:"ServerGroupId"`
-	ServerGroupName     string              `json:"ServerGroupName" xml:"ServerGroupName"`
-	ServerGroupStatus   string              `json:"ServerGroupStatus" xml:"ServerGroupStatus"`
-	ServerGroupType     string              `json:"ServerGroupType" xml:"ServerGroupType"`
-	VpcId               string              `json:"VpcId" xml:"VpcId"`
-	HealthCheckConfig   HealthCheckConfig   `json:"HealthCheckConfig" xml:"HealthCheckConfig"`
-	StickySessionConfig StickySessionConfig `json:"StickySessionConfig" xml:"StickySessionConfig"`
-	Tags                []ALBTag            `json:"Tags" xml:"Tags"`
+	Protocol              string                `json:"Protocol" xml:"Protocol"`
+	ResourceGroupId       string                `json:"ResourceGroupId" xml:"ResourceGroupId"`
+	Scheduler             string                `json:"Scheduler" xml:"Scheduler"`
+	ServerGroupId         string                `json:"ServerGroupId" xml:"ServerGroupId"`
+	ServerGroupName       string                `json:"ServerGroupName" xml:"ServerGroupName"`
+	ServerGroupStatus     string                `json:"ServerGroupStatus" xml:"ServerGroupStatus"`
+	ServerGroupType       string                `json:"ServerGroupType" xml:"ServerGroupType"`
+	VpcId                 string                `json:"VpcId" xml:"VpcId"`
+	HealthCheckConfig     HealthCheckConfig     `json:"HealthCheckConfig" xml:"HealthCheckConfig"`
+	StickySessionConfig   StickySessionConfig   `json:"StickySessionConfig" xml:"StickySessionConfig"`
+	UchConfig             UchConfig             `json:"UchConfig" xml:"UchConfig"`
+	ConnectionDrainConfig ConnectionDrainConfig `json:"ConnectionDrainConfig" xml:"ConnectionDrainConfig"`
+	SlowStartConfig       SlowStartConfig       `json:"SlowStartConfig" xml:"SlowStartConfig"`
+	Tags                  []ALBTag              `json:"Tags" xml:"Tags"`
 }
 
 type AccessLogConfig struct {
@@ -123,6 +127,19 @@ type StickySessionConfig struct {
 	StickySessionType    string `json:"StickySessionType" xml:"StickySessionType"`
 }
 
+type UchConfig struct {
+	Type  string `json:"Type" xml:"Type"`
+	Value string `json:"Value" xml:"Value"`
+}
+type ConnectionDrainConfig struct {
+	ConnectionDrainEnabled bool `json:"ConnectionDrainEnabled" xml:"ConnectionDrainEnabled"`
+	ConnectionDrainTimeout int  `json:"ConnectionDrainTimeout" xml:"ConnectionDrainTimeout"`
+}
+type SlowStartConfig struct {
+	SlowStartEnabled  bool `json:"SlowStartEnabled" xml:"SlowStartEnabled"`
+	SlowStartDuration int  `json:"SlowStartDuration" xml:"SlowStartDuration"`
+}
+
 type Certificate struct {
 	IsDefault     bool   `json:"IsDefault" xml:"IsDefault"`
 	CertificateId string `json:"CertificateId" xml:"CertificateId"`
@@ -48,10 +48,18 @@ func (ls *Listener) registerDependencies(stack core.Manager) {
 	for _, dep := range ls.Spec.LoadBalancerID.Dependencies() {
 		_ = stack.AddDependency(dep, ls)
 	}
+	if ls.Spec.QuicListenerID != nil {
+		for _, dep := range ls.Spec.QuicListenerID.Dependencies() {
+			_ = stack.AddDependency(dep, ls)
+		}
+	}
 }
 
 type ListenerSpec struct {
 	LoadBalancerID core.StringToken `json:"loadBalancerID"`
+	// QuicListenerID is the QUIC listener managed in the same stack which the listener upgrades to,
+	// it takes precedence over QuicConfig.QuicListenerId
+	QuicListenerID core.StringToken `json:"quicListenerID,omitempty"`
 	ALBListenerSpec
 }
 type ListenerStatus struct {
@@ -3,6 +3,7 @@ package alb
 import (
 	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
 )
 
 var _ core.Resource = &ListenerRule{}
@@ -49,3 +50,11 @@ type ResAndSDKListenerRulePair struct {
 	ResLR *ListenerRule
 	SdkLR *albsdk.Rule
 }
+
+// RuleDirection returns the direction of a rule, the rules without a direction are Request rules.
+func RuleDirection(direction string) string {
+	if direction == "" {
+		return util.RuleDirectionRequest
+	}
+	return direction
+}
@@ -94,6 +94,8 @@ type LoadBalancer struct {
 	Listeners                       []ListenerAttribute
 	VServerGroups                   []VServerGroup
 	ContainsPotentialReadyEndpoints bool
+	// AssociatedAcl is only for remote model
+	AssociatedAcl *AccessControlList
 }
 
 func (l *LoadBalancer) GetLoadBalancerId() string {
@@ -125,6 +127,9 @@ type LoadBalancerAttribute struct {
 	Tags                         []tag.Tag
 	Address                      string
 	PreserveOnDelete             bool
+	// SourceRanges and SourceRangesAclId are only for local model
+	SourceRanges      []string
+	SourceRangesAclId string
 
 	// parameters are immutable
 	RegionId                     string
@@ -135,6 +140,21 @@ type LoadBalancerAttribute struct {
 	ModificationProtectionReason string
 }
 
+// AccessControlList represents a CLB access control list.
+type AccessControlList struct {
+	AclId            string
+	AclName          string
+	AddressIPVersion AddressIPVersionType
+	ResourceGroupId  string
+	Entries          []AclEntry
+	Tags             []tag.Tag
+}
+
+type AclEntry struct {
+	Entry   string `json:"entry"`
+	Comment string `json:"comment,omitempty"`
+}
+
 type DomainExtension struct {
 	DomainExtensionId   string
 	Domain              string
@@ -246,6 +266,25 @@ type BackendAttribute struct {
 	Invalid bool
 }
 
+type HealthStatus string
+
+const (
+	HealthStatusNormal   = HealthStatus("normal")
+	HealthStatusAbnormal = HealthStatus("abnormal")
+	// HealthStatusUnavailable means the health check of the listener is off
+	HealthStatusUnavailable = HealthStatus("unavailable")
+)
+
+// BackendHealthStatus is the health check status of a backend reported by a listener
+type BackendHealthStatus struct {
+	ListenerPort     int
+	ListenerProtocol string
+	ServerId         string
+	ServerIp         string
+	Port             int
+	Status           HealthStatus
+}
+
 type CertAttribute struct {
 	CreateTimeStamp     int64
 	ExpireTimeStamp     int64
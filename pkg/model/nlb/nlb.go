@@ -268,6 +268,23 @@ type ServerGroupServer struct {
 	Invalid   bool
 }
 
+// ServerGroupHealthStatus is the health status of a server group attached to a listener,
+// only the servers which are not healthy are reported in NonNormalServers.
+type ServerGroupHealthStatus struct {
+	ServerGroupId      string
+	HealthCheckEnabled bool
+	NonNormalServers   []ServerHealthStatus
+}
+
+type ServerHealthStatus struct {
+	ServerId string
+	ServerIp string
+	Port     int32
+	// Status is one of Initial, Unhealthy, Unused and Unavailable
+	Status     string
+	ReasonCode string
+}
+
 type ZoneMapping struct {
 	VSwitchId    string
 	ZoneId       string
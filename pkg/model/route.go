@@ -6,6 +6,10 @@ const (
 	RouteMaxQueryRouteEntry  = 500
 	RouteNextHopTypeInstance = "Instance"
 	RouteEntryTypeCustom     = "Custom"
+
+	// RouteIPVersionIPv4 and RouteIPVersionIPv6 are the ip versions of the route entries in vpc route tables
+	RouteIPVersionIPv4 = "IPv4"
+	RouteIPVersionIPv6 = "IPv6"
 )
 
 // Route external route for node
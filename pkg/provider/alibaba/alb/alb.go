@@ -19,6 +19,7 @@ import (
 	"github.com/go-logr/logr"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -71,6 +72,7 @@ func (m *ALBProvider) CreateALB(ctx context.Context, resLB *alb.AlbLoadBalancer,
 		"traceID", traceID,
 		"startTime", startTime,
 		util.Action, util.CreateALBLoadBalancer)
+	tracing.Inject(ctx, createLbReq.GetHeaders())
 	createLbResp, err := m.auth.ALB.CreateLoadBalancer(createLbReq)
 	if err != nil {
 		return alb.LoadBalancerStatus{}, err
@@ -174,6 +176,7 @@ func (m *ALBProvider) Tag(ctx context.Context, resLB *alb.AlbLoadBalancer, lbID
 		"loadBalancerID", lbID,
 		"startTime", startTime,
 		util.Action, util.TagALBResource)
+	tracing.Inject(ctx, tagReq.GetHeaders())
 	tagResp, err := m.auth.ALB.TagResources(tagReq)
 	if err != nil {
 		return err
@@ -281,6 +284,7 @@ var getALBLoadBalancerAttributeFunc = func(ctx context.Context, lbID string, aut
 		"loadBalancerID", lbID,
 		"startTime", startTime,
 		util.Action, util.GetALBLoadBalancerAttribute)
+	tracing.Inject(ctx, getLbReq.GetHeaders())
 	getLbResp, err := auth.ALB.GetLoadBalancerAttribute(getLbReq)
 	if err != nil {
 		return nil, err
@@ -306,6 +310,7 @@ var disableALBDeletionProtectionFunc = func(ctx context.Context, lbID string, au
 		"loadBalancerID", lbID,
 		"startTime", startTime,
 		util.Action, util.DisableALBDeletionProtection)
+	tracing.Inject(ctx, updateLbReq.GetHeaders())
 	updateLbResp, err := auth.ALB.DisableDeletionProtection(updateLbReq)
 	if err != nil {
 		return nil, err
@@ -331,6 +336,7 @@ var enableALBDeletionProtectionFunc = func(ctx context.Context, lbID string, aut
 		"loadBalancerID", lbID,
 		"startTime", startTime,
 		util.Action, util.EnableALBDeletionProtection)
+	tracing.Inject(ctx, updateLbReq.GetHeaders())
 	updateLbResp, err := auth.ALB.EnableDeletionProtection(updateLbReq)
 	if err != nil {
 		return nil, err
@@ -357,6 +363,7 @@ var deleteALBLoadBalancerFunc = func(ctx context.Context, m *ALBProvider, lbID s
 		"traceID", traceID,
 		"startTime", startTime,
 		util.Action, util.DeleteALBLoadBalancer)
+	tracing.Inject(ctx, lbReq.GetHeaders())
 	lsResp, err := m.auth.ALB.DeleteLoadBalancer(lbReq)
 	if err != nil {
 		return nil, err
@@ -521,6 +528,7 @@ func (m *ALBProvider) updateAlbLoadBalancerAttribute(ctx context.Context, resLB
 		"loadBalancerID", sdkLB.LoadBalancerId,
 		"startTime", startTime,
 		util.Action, util.UpdateALBLoadBalancerAttribute)
+	tracing.Inject(ctx, updateLbReq.GetHeaders())
 	updateLbResp, err := m.auth.ALB.UpdateLoadBalancerAttribute(updateLbReq)
 	if err != nil {
 		return err
@@ -592,6 +600,7 @@ func (m *ALBProvider) DissociateAccessLogFromALB(ctx context.Context, lbID strin
 		"loadBalancerID", lbID,
 		"startTime", startTime,
 		util.Action, util.DisableALBLoadBalancerAccessLog)
+	tracing.Inject(ctx, updateLbReq.GetHeaders())
 	updateLbResp, err := m.auth.ALB.DisableLoadBalancerAccessLog(updateLbReq)
 	if err != nil {
 		return err
@@ -638,6 +647,7 @@ func (m *ALBProvider) AnalyzeAndAssociateAccessLogToALB(ctx context.Context, lbI
 		"loadBalancerID", lbID,
 		"startTime", startTime,
 		util.Action, util.AnalyzeProductLog)
+	tracing.Inject(ctx, logReq.GetHeaders())
 	logResp, err := m.auth.SLS.AnalyzeProductLog(logReq)
 	if err != nil {
 		m.logger.V(util.MgrLogLevel).Info("analyzing product log",
@@ -671,6 +681,7 @@ func (m *ALBProvider) AnalyzeAndAssociateAccessLogToALB(ctx context.Context, lbI
 		"loadBalancerID", lbID,
 		"startTime", startTime,
 		util.Action, util.EnableALBLoadBalancerAccessLog)
+	tracing.Inject(ctx, lbReq.GetHeaders())
 	lbResp, err := m.auth.ALB.EnableLoadBalancerAccessLog(lbReq)
 	if err != nil {
 		return err
@@ -755,6 +766,7 @@ func (m *ALBProvider) updateAlbLoadBalancerEdition(ctx context.Context, resLB *a
 		"traceID", traceID,
 		"loadBalancerID", sdkLB.LoadBalancerId,
 		util.Action, util.UpdateALBLoadBalancerEdition)
+	tracing.Inject(ctx, updateLbReq.GetHeaders())
 	updateLbResp, err := m.auth.ALB.UpdateLoadBalancerEdition(updateLbReq)
 	if err != nil {
 		return err
@@ -828,10 +840,12 @@ func isLoadBalancerAddressAllocatedModeValid(addressAllocatedMode string) bool {
 	return false
 }
 
-func (p ALBProvider) TagALBResources(request *albsdk.TagResourcesRequest) (response *albsdk.TagResourcesResponse, err error) {
+func (p ALBProvider) TagALBResources(ctx context.Context, request *albsdk.TagResourcesRequest) (response *albsdk.TagResourcesResponse, err error) {
+	tracing.Inject(ctx, request.GetHeaders())
 	return p.auth.ALB.TagResources(request)
 }
-func (p ALBProvider) DescribeALBZones(request *albsdk.DescribeZonesRequest) (response *albsdk.DescribeZonesResponse, err error) {
+func (p ALBProvider) DescribeALBZones(ctx context.Context, request *albsdk.DescribeZonesRequest) (response *albsdk.DescribeZonesResponse, err error) {
+	tracing.Inject(ctx, request.GetHeaders())
 	return p.auth.ALB.DescribeZones(request)
 }
 
@@ -19,6 +19,7 @@ import (
 	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 )
 
 func (m *ALBProvider) CreateALBListener(ctx context.Context, resLS *albmodel.Listener) (albmodel.ListenerStatus, error) {
@@ -40,6 +41,7 @@ func (m *ALBProvider) CreateALBListener(ctx context.Context, resLS *albmodel.Lis
 			"listenerProtocol", resLS.Spec.ListenerProtocol,
 			"startTime", startTime,
 			util.Action, util.CreateALBListener)
+		tracing.Inject(ctx, createLsReq.GetHeaders())
 		createLsResp, err = m.auth.ALB.CreateListener(createLsReq)
 		if err != nil {
 			m.logger.V(util.MgrLogLevel).Info("creating listener",
@@ -95,7 +97,7 @@ func (m *ALBProvider) CreateALBListener(ctx context.Context, resLS *albmodel.Lis
 		"elapsedTime", time.Since(asynchronousStartTime).Milliseconds(),
 		util.Action, util.CreateALBListenerAsynchronous)
 
-	if isHTTPSListenerProtocol(resLS.Spec.ListenerProtocol) {
+	if isCertificateListenerProtocol(resLS.Spec.ListenerProtocol) {
 		if err := util.RetryImmediateOnError(m.waitLSExistencePollInterval, m.waitLSExistenceTimeout, isIncorrectStatusListenerError, func() error {
 			if err := m.updateListenerExtraCertificates(ctx, createLsResp.ListenerId, resLS); err != nil {
 				return err
@@ -123,6 +125,7 @@ var getALBListenerAttributeFunc = func(ctx context.Context, lsID string, auth *b
 		"listenerID", lsID,
 		"startTime", startTime,
 		util.Action, util.GetALBListenerAttribute)
+	tracing.Inject(ctx, getLsReq.GetHeaders())
 	getLsResp, err := auth.ALB.GetListenerAttribute(getLsReq)
 	if err != nil {
 		return nil, err
@@ -155,6 +158,7 @@ func (m *ALBProvider) listListenerCerts(ctx context.Context, lsID string) ([]alb
 			"listenerID", lsID,
 			"startTime", startTime,
 			util.Action, util.ListALBListenerCertificates)
+		tracing.Inject(ctx, listLsCertificateReq.GetHeaders())
 		listLsCertificateResp, err := m.auth.ALB.ListListenerCertificates(listLsCertificateReq)
 		if err != nil {
 			return nil, err
@@ -180,7 +184,7 @@ func (m *ALBProvider) listListenerCerts(ctx context.Context, lsID string) ([]alb
 }
 
 func (m *ALBProvider) UpdateALBListener(ctx context.Context, resLS *albmodel.Listener, sdkLS *albsdk.Listener) (albmodel.ListenerStatus, error) {
-	if isHTTPSListenerProtocol(sdkLS.ListenerProtocol) {
+	if isCertificateListenerProtocol(sdkLS.ListenerProtocol) {
 		certs, err := m.listListenerCerts(ctx, sdkLS.ListenerId)
 		if err != nil {
 			return albmodel.ListenerStatus{}, err
@@ -192,7 +196,7 @@ func (m *ALBProvider) UpdateALBListener(ctx context.Context, resLS *albmodel.Lis
 		return albmodel.ListenerStatus{}, err
 	}
 
-	if isHTTPSListenerProtocol(sdkLS.ListenerProtocol) {
+	if isCertificateListenerProtocol(sdkLS.ListenerProtocol) {
 		if err := m.updateListenerExtraCertificates(ctx, sdkLS.ListenerId, resLS); err != nil {
 			return albmodel.ListenerStatus{}, err
 		}
@@ -213,6 +217,7 @@ func (m *ALBProvider) DeleteALBListener(ctx context.Context, sdkLSId string) err
 		"traceID", traceID,
 		"startTime", startTime,
 		util.Action, util.DeleteALBListener)
+	tracing.Inject(ctx, deleteLsReq.GetHeaders())
 	deleteLsResp, err := m.auth.ALB.DeleteListener(deleteLsReq)
 	if err != nil {
 		return err
@@ -250,6 +255,7 @@ func (m *ALBProvider) ListALBListeners(ctx context.Context, lbID string) ([]albs
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.ListALBListeners)
+		tracing.Inject(ctx, listLsReq.GetHeaders())
 		listLsResp, err := m.auth.ALB.ListListeners(listLsReq)
 		if err != nil {
 			return nil, err
@@ -284,10 +290,11 @@ func transSDKCertificateToAssociate(certs []albsdk.Certificate) *[]albsdk.Associ
 	return &associateCerts
 }
 
-func (m *ALBProvider) AssociateALBAdditionalCertificatesWithListener(lsID string, certs []albsdk.Certificate) (*albsdk.AssociateAdditionalCertificatesWithListenerResponse, error) {
+func (m *ALBProvider) AssociateALBAdditionalCertificatesWithListener(ctx context.Context, lsID string, certs []albsdk.Certificate) (*albsdk.AssociateAdditionalCertificatesWithListenerResponse, error) {
 	lsReq := albsdk.CreateAssociateAdditionalCertificatesWithListenerRequest()
 	lsReq.ListenerId = lsID
 	lsReq.Certificates = transSDKCertificateToAssociate(certs)
+	tracing.Inject(ctx, lsReq.GetHeaders())
 	resp, err := m.auth.ALB.AssociateAdditionalCertificatesWithListener(lsReq)
 	if err != nil {
 		return nil, err
@@ -305,10 +312,11 @@ func transSDKCertificateToDissociate(certs []albsdk.Certificate) *[]albsdk.Disso
 	return &dissociateCerts
 }
 
-func (m *ALBProvider) DissociateALBAdditionalCertificatesFromListener(lsID string, certs []albsdk.Certificate) (*albsdk.DissociateAdditionalCertificatesFromListenerResponse, error) {
+func (m *ALBProvider) DissociateALBAdditionalCertificatesFromListener(ctx context.Context, lsID string, certs []albsdk.Certificate) (*albsdk.DissociateAdditionalCertificatesFromListenerResponse, error) {
 	lsReq := albsdk.CreateDissociateAdditionalCertificatesFromListenerRequest()
 	lsReq.ListenerId = lsID
 	lsReq.Certificates = transSDKCertificateToDissociate(certs)
+	tracing.Inject(ctx, lsReq.GetHeaders())
 	lsResp, err := m.auth.ALB.DissociateAdditionalCertificatesFromListener(lsReq)
 	if err != nil {
 		return nil, err
@@ -371,7 +379,10 @@ func buildSDKCreateListenerRequest(lsSpec albmodel.ListenerSpec) (*albsdk.Create
 			return nil, fmt.Errorf("invalid https listener SecurityPolicyId: %s", lsSpec.SecurityPolicyId)
 		}
 		createLsReq.SecurityPolicyId = lsSpec.SecurityPolicyId
+		createLsReq.Http2Enabled = requests.NewBoolean(lsSpec.Http2Enabled)
+	}
 
+	if isCertificateListenerProtocol(lsSpec.ListenerProtocol) {
 		createLsReq.CaCertificates = transSDKCaCertificatesToCreateLs(lsSpec.CaCertificates)
 
 		if len(lsSpec.Certificates) == 0 {
@@ -382,8 +393,6 @@ func buildSDKCreateListenerRequest(lsSpec albmodel.ListenerSpec) (*albsdk.Create
 			return nil, fmt.Errorf("empty https listener default certs")
 		}
 		createLsReq.Certificates = transSDKCertificatesToCreateLs(defaultCerts)
-
-		createLsReq.Http2Enabled = requests.NewBoolean(lsSpec.Http2Enabled)
 	}
 
 	return createLsReq, nil
@@ -421,7 +430,7 @@ func (m *ALBProvider) updateListenerExtraCertificates(ctx context.Context, lsID
 			"certificates", certs,
 			"startTime", startTime,
 			util.Action, util.AssociateALBAdditionalCertificatesWithListener)
-		resp, err := m.AssociateALBAdditionalCertificatesWithListener(lsID, certs)
+		resp, err := m.AssociateALBAdditionalCertificatesWithListener(ctx, lsID, certs)
 		if err != nil {
 			m.logger.V(util.MgrLogLevel).Info("associating additional certificates to listener",
 				"stackID", resLs.Stack().StackID(),
@@ -460,7 +469,7 @@ func (m *ALBProvider) updateListenerExtraCertificates(ctx context.Context, lsID
 			"certificates", certs,
 			"startTime", startTime,
 			util.Action, util.DissociateALBAdditionalCertificatesFromListener)
-		resp, err := m.DissociateALBAdditionalCertificatesFromListener(lsID, certs)
+		resp, err := m.DissociateALBAdditionalCertificatesFromListener(ctx, lsID, certs)
 		if err != nil {
 			return err
 		}
@@ -513,7 +522,7 @@ func (m *ALBProvider) updateListenerAttribute(ctx context.Context, resLS *albmod
 	if len(resLS.Spec.DefaultActions) == 0 {
 		return fmt.Errorf("empty listener default action: %v", resLS.Spec.DefaultActions)
 	}
-	resAction, err := transModelActionsToSDKLs(resLS.Spec.DefaultActions)
+	resAction, err := TransModelActionsToSDKLs(resLS.Spec.DefaultActions)
 	if err != nil {
 		return err
 	}
@@ -583,6 +592,18 @@ func (m *ALBProvider) updateListenerAttribute(ctx context.Context, resLS *albmod
 			isSecurityPolicyIdNeedUpdate = true
 		}
 
+		if resLS.Spec.Http2Enabled != sdkLs.Http2Enabled {
+			m.logger.V(util.MgrLogLevel).Info("Http2Enabled update",
+				"res", resLS.Spec.Http2Enabled,
+				"sdk", sdkLs.Http2Enabled,
+				"listenerID", sdkLs.ListenerId,
+				"traceID", traceID)
+			isHttp2EnabledNeedUpdate = true
+		}
+	}
+
+	if isCertificateListenerProtocol(sdkLs.ListenerProtocol) {
+
 		desiredDefaultCerts, _ := buildSDKCertificates(resLS.Spec.Certificates)
 		if len(desiredDefaultCerts) != 1 {
 			return fmt.Errorf("invalid res https listener default certs len: %d", len(desiredDefaultCerts))
@@ -599,15 +620,6 @@ func (m *ALBProvider) updateListenerAttribute(ctx context.Context, resLS *albmod
 				"traceID", traceID)
 			isCertificatesNeedUpdate = true
 		}
-
-		if resLS.Spec.Http2Enabled != sdkLs.Http2Enabled {
-			m.logger.V(util.MgrLogLevel).Info("Http2Enabled update",
-				"res", resLS.Spec.Http2Enabled,
-				"sdk", sdkLs.Http2Enabled,
-				"listenerID", sdkLs.ListenerId,
-				"traceID", traceID)
-			isHttp2EnabledNeedUpdate = true
-		}
 	}
 
 	if !isGzipEnabledNeedUpdate && !isQuicConfigUpdate && !isHttp2EnabledNeedUpdate &&
@@ -663,6 +675,7 @@ func (m *ALBProvider) updateListenerAttribute(ctx context.Context, resLS *albmod
 		"listenerID", sdkLs.ListenerId,
 		"startTime", startTime,
 		util.Action, util.UpdateALBListenerAttribute)
+	tracing.Inject(ctx, updateLsReq.GetHeaders())
 	updateLsResp, err := m.auth.ALB.UpdateListenerAttribute(updateLsReq)
 	if err != nil {
 		return err
@@ -733,7 +746,8 @@ func transModelServerGroupTuplesToSDKLs(ms []albmodel.ServerGroupTuple) (*[]albs
 	return &updateRuleServerGroupTuples, nil
 }
 
-func transModelActionsToSDKLs(actions []albmodel.Action) (*[]albsdk.DefaultAction, error) {
+// TransModelActionsToSDKLs converts the default actions of the model to the form returned by ListListeners
+func TransModelActionsToSDKLs(actions []albmodel.Action) (*[]albsdk.DefaultAction, error) {
 	lsActions := make([]albsdk.DefaultAction, 0)
 
 	for _, action := range actions {
@@ -941,6 +955,12 @@ func isHTTPSListenerProtocol(protocol string) bool {
 	return strings.EqualFold(protocol, util.ListenerProtocolHTTPS)
 }
 
+// isCertificateListenerProtocol returns whether the listeners of the protocol terminate TLS with
+// the certificates, both HTTPS and QUIC listeners do.
+func isCertificateListenerProtocol(protocol string) bool {
+	return isHTTPSListenerProtocol(protocol) || strings.EqualFold(protocol, util.ListenerProtocolQUIC)
+}
+
 func isListenerProtocolValid(protocol string) bool {
 	if strings.EqualFold(protocol, util.ListenerProtocolHTTP) ||
 		strings.EqualFold(protocol, util.ListenerProtocolHTTPS) ||
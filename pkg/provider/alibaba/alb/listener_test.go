@@ -0,0 +1,123 @@
+package alb
+
+import (
+	"testing"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func TestBuildSDKCreateListenerRequest(t *testing.T) {
+	newSpec := func(mutate func(spec *alb.ListenerSpec)) alb.ListenerSpec {
+		spec := alb.ListenerSpec{
+			LoadBalancerID: core.LiteralStringToken("alb-1"),
+			ALBListenerSpec: alb.ALBListenerSpec{
+				ListenerPort:     80,
+				ListenerProtocol: util.ListenerProtocolHTTP,
+				RequestTimeout:   60,
+				IdleTimeout:      15,
+				DefaultActions: []alb.Action{{
+					Type: util.RuleActionTypeForward,
+					ForwardConfig: &alb.ForwardActionConfig{ServerGroups: []alb.ServerGroupTuple{
+						{ServerGroupID: core.LiteralStringToken("sgp-1")},
+					}},
+				}},
+			},
+		}
+		mutate(&spec)
+		return spec
+	}
+	certs := []alb.Certificate{{IsDefault: true, CertificateId: "cert-1"}, {CertificateId: "cert-2"}}
+
+	cases := []struct {
+		name      string
+		spec      alb.ListenerSpec
+		expectErr bool
+		check     func(t *testing.T, req *albsdk.CreateListenerRequest)
+	}{
+		{
+			name: "http listener has no certificates",
+			spec: newSpec(func(spec *alb.ListenerSpec) { spec.Certificates = certs }),
+			check: func(t *testing.T, req *albsdk.CreateListenerRequest) {
+				assert.Equal(t, "alb-1", req.LoadBalancerId)
+				assert.Nil(t, req.Certificates)
+				assert.Empty(t, req.SecurityPolicyId)
+				assert.Empty(t, string(req.Http2Enabled))
+			},
+		},
+		{
+			name: "https listener upgrading to quic",
+			spec: newSpec(func(spec *alb.ListenerSpec) {
+				spec.ListenerPort = 443
+				spec.ListenerProtocol = util.ListenerProtocolHTTPS
+				spec.Certificates = certs
+				spec.SecurityPolicyId = "tls_cipher_policy_1_0"
+				spec.Http2Enabled = true
+				spec.QuicConfig = alb.QuicConfig{QuicUpgradeEnabled: true, QuicListenerId: "lsn-quic"}
+			}),
+			check: func(t *testing.T, req *albsdk.CreateListenerRequest) {
+				assert.Equal(t, &[]albsdk.CreateListenerCertificates{{CertificateId: "cert-1"}}, req.Certificates)
+				assert.Equal(t, "tls_cipher_policy_1_0", req.SecurityPolicyId)
+				assert.Equal(t, "true", string(req.Http2Enabled))
+				assert.Equal(t, albsdk.CreateListenerQuicConfig{QuicUpgradeEnabled: "true", QuicListenerId: "lsn-quic"}, req.QuicConfig)
+			},
+		},
+		{
+			name: "https listener without security policy",
+			spec: newSpec(func(spec *alb.ListenerSpec) {
+				spec.ListenerProtocol = util.ListenerProtocolHTTPS
+				spec.Certificates = certs
+			}),
+			expectErr: true,
+		},
+		{
+			name: "quic listener has certificates without security policy and http2",
+			spec: newSpec(func(spec *alb.ListenerSpec) {
+				spec.ListenerPort = 8443
+				spec.ListenerProtocol = util.ListenerProtocolQUIC
+				spec.Certificates = certs
+				spec.SecurityPolicyId = "tls_cipher_policy_1_0"
+				spec.Http2Enabled = true
+			}),
+			check: func(t *testing.T, req *albsdk.CreateListenerRequest) {
+				assert.Equal(t, util.ListenerProtocolQUIC, req.ListenerProtocol)
+				assert.Equal(t, &[]albsdk.CreateListenerCertificates{{CertificateId: "cert-1"}}, req.Certificates)
+				assert.Empty(t, req.SecurityPolicyId)
+				assert.Empty(t, string(req.Http2Enabled))
+				assert.Equal(t, "false", req.QuicConfig.QuicUpgradeEnabled)
+			},
+		},
+		{
+			name: "quic listener without certificates",
+			spec: newSpec(func(spec *alb.ListenerSpec) {
+				spec.ListenerProtocol = util.ListenerProtocolQUIC
+			}),
+			expectErr: true,
+		},
+		{
+			name:      "invalid protocol",
+			spec:      newSpec(func(spec *alb.ListenerSpec) { spec.ListenerProtocol = "TCP" }),
+			expectErr: true,
+		},
+		{
+			name:      "empty default actions",
+			spec:      newSpec(func(spec *alb.ListenerSpec) { spec.DefaultActions = nil }),
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := buildSDKCreateListenerRequest(c.spec)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			c.check(t, req)
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
 	"github.com/pkg/errors"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 )
 
 func (m *ALBProvider) CreateALBListenerRule(ctx context.Context, resLR *alb.ListenerRule) (alb.ListenerRuleStatus, error) {
@@ -35,6 +36,7 @@ func (m *ALBProvider) CreateALBListenerRule(ctx context.Context, resLR *alb.List
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.CreateALBRule)
+		tracing.Inject(ctx, createRuleReq.GetHeaders())
 		createRuleResp, err = m.auth.ALB.CreateRule(createRuleReq)
 		if err != nil {
 			m.logger.V(util.MgrLogLevel).Info("creating rule",
@@ -93,6 +95,7 @@ func (m *ALBProvider) DeleteALBListenerRule(ctx context.Context, sdkLRId string)
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.DeleteALBRule)
+		tracing.Inject(ctx, deleteRuleReq.GetHeaders())
 		deleteRuleResp, err := m.auth.ALB.DeleteRule(deleteRuleReq)
 		if err != nil {
 			m.logger.V(util.MgrLogLevel).Info("deleting rule",
@@ -123,39 +126,47 @@ func (m *ALBProvider) ListALBListenerRules(ctx context.Context, lsID string) ([]
 		return nil, fmt.Errorf("invalid listener id: %s for listing rules", lsID)
 	}
 
-	var (
-		nextToken string
-		rules     []albsdk.Rule
-	)
+	var rules []albsdk.Rule
+	// ListRules returns the Request rules only if no direction is set
+	for _, direction := range []string{util.RuleDirectionRequest, util.RuleDirectionResponse} {
+		var nextToken string
+		listRuleReq := albsdk.CreateListRulesRequest()
+		listRuleReq.ListenerId = lsID
+		listRuleReq.Direction = direction
 
-	listRuleReq := albsdk.CreateListRulesRequest()
-	listRuleReq.ListenerId = lsID
+		for {
+			listRuleReq.NextToken = nextToken
 
-	for {
-		listRuleReq.NextToken = nextToken
-
-		startTime := time.Now()
-		m.logger.V(util.MgrLogLevel).Info("listing rules",
-			"listenerID", lsID,
-			"traceID", traceID,
-			"startTime", startTime,
-			util.Action, util.ListALBRules)
-		listRuleResp, err := m.auth.ALB.ListRules(listRuleReq)
-		if err != nil {
-			return nil, err
-		}
-		m.logger.V(util.MgrLogLevel).Info("listed rules",
-			"listenerID", lsID,
-			"traceID", traceID,
-			"requestID", listRuleResp.RequestId,
-			"elapsedTime", time.Since(startTime).Milliseconds(),
-			util.Action, util.ListALBRules)
-
-		rules = append(rules, listRuleResp.Rules...)
+			startTime := time.Now()
+			m.logger.V(util.MgrLogLevel).Info("listing rules",
+				"listenerID", lsID,
+				"direction", direction,
+				"traceID", traceID,
+				"startTime", startTime,
+				util.Action, util.ListALBRules)
+			tracing.Inject(ctx, listRuleReq.GetHeaders())
+			listRuleResp, err := m.auth.ALB.ListRules(listRuleReq)
+			if err != nil {
+				return nil, err
+			}
+			m.logger.V(util.MgrLogLevel).Info("listed rules",
+				"listenerID", lsID,
+				"direction", direction,
+				"traceID", traceID,
+				"requestID", listRuleResp.RequestId,
+				"elapsedTime", time.Since(startTime).Milliseconds(),
+				util.Action, util.ListALBRules)
+
+			for _, rule := range listRuleResp.Rules {
+				if rule.Direction == "" {
+					rule.Direction = direction
+				}
+				rules = append(rules, rule)
+			}
 
-		if listRuleResp.NextToken == "" {
-			break
-		} else {
+			if listRuleResp.NextToken == "" {
+				break
+			}
 			nextToken = listRuleResp.NextToken
 		}
 	}
@@ -217,6 +228,7 @@ func (m *ALBProvider) updateALBRuleAttribute(ctx context.Context, resLR *alb.Lis
 			"startTime", startTime,
 			util.Action, util.UpdateALBRuleAttribute)
 		var err error
+		tracing.Inject(ctx, ruleReq.GetHeaders())
 		updateRuleResp, err = m.auth.ALB.UpdateRuleAttribute(ruleReq)
 		if err != nil {
 			m.logger.V(util.MgrLogLevel).Info("updating rule attribute",
@@ -480,6 +492,196 @@ func transModelForwardActionConfigToSDKRule(forwardActionConf alb.ForwardActionC
 	return r, nil
 }
 
+func transSDKRewriteConfigToCreateRules(rewriteConf alb.RewriteConfig) albsdk.CreateRulesRulesRuleActionsItemRewriteConfig {
+	return albsdk.CreateRulesRulesRuleActionsItemRewriteConfig{
+		Host:  rewriteConf.Host,
+		Path:  rewriteConf.Path,
+		Query: rewriteConf.Query,
+	}
+}
+func transSDKRewriteConfigToUpdateRules(rewriteConf alb.RewriteConfig) albsdk.UpdateRulesAttributeRulesRuleActionsItemRewriteConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleActionsItemRewriteConfig{
+		Host:  rewriteConf.Host,
+		Path:  rewriteConf.Path,
+		Query: rewriteConf.Query,
+	}
+}
+func transSDKRewriteConfigToCreateRule(rewriteConf alb.RewriteConfig) albsdk.CreateRuleRuleActionsRewriteConfig {
+	return albsdk.CreateRuleRuleActionsRewriteConfig{
+		Host:  rewriteConf.Host,
+		Path:  rewriteConf.Path,
+		Query: rewriteConf.Query,
+	}
+}
+func transSDKRewriteConfigToUpdateRule(rewriteConf alb.RewriteConfig) albsdk.UpdateRuleAttributeRuleActionsRewriteConfig {
+	return albsdk.UpdateRuleAttributeRuleActionsRewriteConfig{
+		Host:  rewriteConf.Host,
+		Path:  rewriteConf.Path,
+		Query: rewriteConf.Query,
+	}
+}
+func transSDKInsertHeaderConfigToCreateRules(insertHeaderConf alb.InsertHeaderConfig) albsdk.CreateRulesRulesRuleActionsItemInsertHeaderConfig {
+	return albsdk.CreateRulesRulesRuleActionsItemInsertHeaderConfig{
+		Key:          insertHeaderConf.Key,
+		Value:        insertHeaderConf.Value,
+		ValueType:    insertHeaderConf.ValueType,
+		CoverEnabled: strconv.FormatBool(insertHeaderConf.CoverEnabled),
+	}
+}
+func transSDKInsertHeaderConfigToUpdateRules(insertHeaderConf alb.InsertHeaderConfig) albsdk.UpdateRulesAttributeRulesRuleActionsItemInsertHeaderConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleActionsItemInsertHeaderConfig{
+		Key:          insertHeaderConf.Key,
+		Value:        insertHeaderConf.Value,
+		ValueType:    insertHeaderConf.ValueType,
+		CoverEnabled: strconv.FormatBool(insertHeaderConf.CoverEnabled),
+	}
+}
+func transSDKInsertHeaderConfigToCreateRule(insertHeaderConf alb.InsertHeaderConfig) albsdk.CreateRuleRuleActionsInsertHeaderConfig {
+	return albsdk.CreateRuleRuleActionsInsertHeaderConfig{
+		Key:          insertHeaderConf.Key,
+		Value:        insertHeaderConf.Value,
+		ValueType:    insertHeaderConf.ValueType,
+		CoverEnabled: strconv.FormatBool(insertHeaderConf.CoverEnabled),
+	}
+}
+func transSDKInsertHeaderConfigToUpdateRule(insertHeaderConf alb.InsertHeaderConfig) albsdk.UpdateRuleAttributeRuleActionsInsertHeaderConfig {
+	return albsdk.UpdateRuleAttributeRuleActionsInsertHeaderConfig{
+		Key:          insertHeaderConf.Key,
+		Value:        insertHeaderConf.Value,
+		ValueType:    insertHeaderConf.ValueType,
+		CoverEnabled: strconv.FormatBool(insertHeaderConf.CoverEnabled),
+	}
+}
+func transSDKRemoveHeaderConfigToCreateRules(removeHeaderConf alb.RemoveHeaderConfig) albsdk.CreateRulesRulesRuleActionsItemRemoveHeaderConfig {
+	return albsdk.CreateRulesRulesRuleActionsItemRemoveHeaderConfig{
+		Key: removeHeaderConf.Key,
+	}
+}
+func transSDKRemoveHeaderConfigToUpdateRules(removeHeaderConf alb.RemoveHeaderConfig) albsdk.UpdateRulesAttributeRulesRuleActionsItemRemoveHeaderConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleActionsItemRemoveHeaderConfig{
+		Key: removeHeaderConf.Key,
+	}
+}
+func transSDKRemoveHeaderConfigToCreateRule(removeHeaderConf alb.RemoveHeaderConfig) albsdk.CreateRuleRuleActionsRemoveHeaderConfig {
+	return albsdk.CreateRuleRuleActionsRemoveHeaderConfig{
+		Key: removeHeaderConf.Key,
+	}
+}
+func transSDKRemoveHeaderConfigToUpdateRule(removeHeaderConf alb.RemoveHeaderConfig) albsdk.UpdateRuleAttributeRuleActionsRemoveHeaderConfig {
+	return albsdk.UpdateRuleAttributeRuleActionsRemoveHeaderConfig{
+		Key: removeHeaderConf.Key,
+	}
+}
+func transSDKTrafficLimitConfigToCreateRules(trafficLimitConf alb.TrafficLimitConfig) albsdk.CreateRulesRulesRuleActionsItemTrafficLimitConfig {
+	return albsdk.CreateRulesRulesRuleActionsItemTrafficLimitConfig{
+		QPS: strconv.Itoa(trafficLimitConf.QPS),
+	}
+}
+func transSDKTrafficLimitConfigToUpdateRules(trafficLimitConf alb.TrafficLimitConfig) albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficLimitConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficLimitConfig{
+		QPS: strconv.Itoa(trafficLimitConf.QPS),
+	}
+}
+func transSDKTrafficLimitConfigToCreateRule(trafficLimitConf alb.TrafficLimitConfig) albsdk.CreateRuleRuleActionsTrafficLimitConfig {
+	return albsdk.CreateRuleRuleActionsTrafficLimitConfig{
+		QPS: strconv.Itoa(trafficLimitConf.QPS),
+	}
+}
+func transSDKTrafficLimitConfigToUpdateRule(trafficLimitConf alb.TrafficLimitConfig) albsdk.UpdateRuleAttributeRuleActionsTrafficLimitConfig {
+	return albsdk.UpdateRuleAttributeRuleActionsTrafficLimitConfig{
+		QPS: strconv.Itoa(trafficLimitConf.QPS),
+	}
+}
+func transModelTrafficMirrorConfigToSDKCreateRules(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfig, error) {
+	sdkSGPs := make([]albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem, 0)
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfig{
+			ServerGroupTuples: &sdkSGPs,
+		},
+	}, nil
+}
+func transModelTrafficMirrorConfigToSDKUpdateRules(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfig, error) {
+	sdkSGPs := make([]albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem, 0)
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.UpdateRulesAttributeRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfig{
+			ServerGroupTuples: &sdkSGPs,
+		},
+	}, nil
+}
+func transModelTrafficMirrorConfigToSDKCreateRule(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.CreateRuleRuleActionsTrafficMirrorConfig, error) {
+	sdkSGPs := make([]albsdk.CreateRuleRuleActionsTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem, 0)
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.CreateRuleRuleActionsTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.CreateRuleRuleActionsTrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.CreateRuleRuleActionsTrafficMirrorConfigMirrorGroupConfig{
+			ServerGroupTuples: &sdkSGPs,
+		},
+	}, nil
+}
+func transModelTrafficMirrorConfigToSDKUpdateRule(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfig, error) {
+	sdkSGPs := make([]albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem, 0)
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.UpdateRuleAttributeRuleActionsTrafficMirrorConfigMirrorGroupConfig{
+			ServerGroupTuples: &sdkSGPs,
+		},
+	}, nil
+}
+func transModelTrafficMirrorConfigToSDKRule(trafficMirrorConf alb.TrafficMirrorConfig) (*albsdk.TrafficMirrorConfig, error) {
+	sdkSGPs := make([]albsdk.ServerGroupTuple, 0)
+	for _, m := range trafficMirrorConf.MirrorGroupConfig.ServerGroupTuples {
+		serverGroupID, err := m.ServerGroupID.Resolve(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		sdkSGPs = append(sdkSGPs, albsdk.ServerGroupTuple{
+			ServerGroupId: serverGroupID,
+		})
+	}
+	return &albsdk.TrafficMirrorConfig{
+		TargetType: trafficMirrorConf.TargetType,
+		MirrorGroupConfig: albsdk.MirrorGroupConfig{
+			ServerGroupTuples: sdkSGPs,
+		},
+	}, nil
+}
 func transModelActionToSDKCreateRules(action alb.Action) (*albsdk.CreateRulesRulesRuleActionsItem, error) {
 	sdkObj := &albsdk.CreateRulesRulesRuleActionsItem{}
 	sdkObj.Type = action.Type
@@ -495,6 +697,20 @@ func transModelActionToSDKCreateRules(action alb.Action) (*albsdk.CreateRulesRul
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = transSDKRewriteConfigToCreateRules(*action.RewriteConfig)
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = transSDKInsertHeaderConfigToCreateRules(*action.InsertHeaderConfig)
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = transSDKRemoveHeaderConfigToCreateRules(*action.RemoveHeaderConfig)
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = transSDKTrafficLimitConfigToCreateRules(*action.TrafficLimitConfig)
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKCreateRules(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
@@ -513,6 +729,20 @@ func transModelActionToSDKUpdateRules(action alb.Action) (*albsdk.UpdateRulesAtt
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = transSDKRewriteConfigToUpdateRules(*action.RewriteConfig)
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = transSDKInsertHeaderConfigToUpdateRules(*action.InsertHeaderConfig)
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = transSDKRemoveHeaderConfigToUpdateRules(*action.RemoveHeaderConfig)
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = transSDKTrafficLimitConfigToUpdateRules(*action.TrafficLimitConfig)
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKUpdateRules(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
@@ -531,6 +761,20 @@ func transModelActionToSDKCreateRule(action alb.Action) (*albsdk.CreateRuleRuleA
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = transSDKRewriteConfigToCreateRule(*action.RewriteConfig)
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = transSDKInsertHeaderConfigToCreateRule(*action.InsertHeaderConfig)
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = transSDKRemoveHeaderConfigToCreateRule(*action.RemoveHeaderConfig)
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = transSDKTrafficLimitConfigToCreateRule(*action.TrafficLimitConfig)
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKCreateRule(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
@@ -549,6 +793,20 @@ func transModelActionToSDKUpdateRule(action alb.Action) (*albsdk.UpdateRuleAttri
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = transSDKRewriteConfigToUpdateRule(*action.RewriteConfig)
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = transSDKInsertHeaderConfigToUpdateRule(*action.InsertHeaderConfig)
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = transSDKRemoveHeaderConfigToUpdateRule(*action.RemoveHeaderConfig)
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = transSDKTrafficLimitConfigToUpdateRule(*action.TrafficLimitConfig)
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKUpdateRule(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
@@ -639,10 +897,39 @@ func transModelActionToSDK(action alb.Action) (*albsdk.Action, error) {
 			return nil, err
 		}
 		sdkObj.ForwardGroupConfig = *forwardActionConfig
+	case util.RuleActionTypeRewrite:
+		sdkObj.RewriteConfig = albsdk.RewriteConfig{
+			Host:  action.RewriteConfig.Host,
+			Path:  action.RewriteConfig.Path,
+			Query: action.RewriteConfig.Query,
+		}
+	case util.RuleActionTypeInsertHeader:
+		sdkObj.InsertHeaderConfig = albsdk.InsertHeaderConfig{
+			Key:          action.InsertHeaderConfig.Key,
+			Value:        action.InsertHeaderConfig.Value,
+			ValueType:    action.InsertHeaderConfig.ValueType,
+			CoverEnabled: action.InsertHeaderConfig.CoverEnabled,
+		}
+	case util.RuleActionTypeRemoveHeader:
+		sdkObj.RemoveHeaderConfig = albsdk.RemoveHeaderConfig{
+			Key: action.RemoveHeaderConfig.Key,
+		}
+	case util.RuleActionTypeTrafficLimit:
+		sdkObj.TrafficLimitConfig = albsdk.TrafficLimitConfig{
+			QPS: action.TrafficLimitConfig.QPS,
+		}
+	case util.RuleActionTypeTrafficMirror:
+		trafficMirrorConfig, err := transModelTrafficMirrorConfigToSDKRule(*action.TrafficMirrorConfig)
+		if err != nil {
+			return nil, err
+		}
+		sdkObj.TrafficMirrorConfig = *trafficMirrorConfig
 	}
 	return sdkObj, nil
 }
-func transModelActionsToSDK(actions []alb.Action) (*[]albsdk.Action, error) {
+
+// TransModelActionsToSDK converts the rule actions of the model to the form returned by ListRules
+func TransModelActionsToSDK(actions []alb.Action) (*[]albsdk.Action, error) {
 	var sdkActions []albsdk.Action
 	if len(actions) != 0 {
 		sdkActions = make([]albsdk.Action, 0)
@@ -892,6 +1179,72 @@ func transSDKPathConfigToUpdateRule(pathConfig alb.PathConfig) albsdk.UpdateRule
 		Values: &pathConfig.Values,
 	}
 }
+func transSDKSourceIpConfigToCreateRules(sourceIpConfig alb.SourceIpConfig) albsdk.CreateRulesRulesRuleConditionsItemSourceIpConfig {
+	return albsdk.CreateRulesRulesRuleConditionsItemSourceIpConfig{
+		Values: &sourceIpConfig.Values,
+	}
+}
+func transSDKSourceIpConfigToUpdateRules(sourceIpConfig alb.SourceIpConfig) albsdk.UpdateRulesAttributeRulesRuleConditionsItemSourceIpConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleConditionsItemSourceIpConfig{
+		Values: &sourceIpConfig.Values,
+	}
+}
+func transSDKSourceIpConfigToCreateRule(sourceIpConfig alb.SourceIpConfig) albsdk.CreateRuleRuleConditionsSourceIpConfig {
+	return albsdk.CreateRuleRuleConditionsSourceIpConfig{
+		Values: &sourceIpConfig.Values,
+	}
+}
+func transSDKSourceIpConfigToUpdateRule(sourceIpConfig alb.SourceIpConfig) albsdk.UpdateRuleAttributeRuleConditionsSourceIpConfig {
+	return albsdk.UpdateRuleAttributeRuleConditionsSourceIpConfig{
+		Values: &sourceIpConfig.Values,
+	}
+}
+
+func transSDKResponseHeaderConfigToCreateRules(responseHeaderConfig alb.ResponseHeaderConfig) albsdk.CreateRulesRulesRuleConditionsItemResponseHeaderConfig {
+	return albsdk.CreateRulesRulesRuleConditionsItemResponseHeaderConfig{
+		Values: &responseHeaderConfig.Values,
+		Key:    responseHeaderConfig.Key,
+	}
+}
+func transSDKResponseHeaderConfigToUpdateRules(responseHeaderConfig alb.ResponseHeaderConfig) albsdk.UpdateRulesAttributeRulesRuleConditionsItemResponseHeaderConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleConditionsItemResponseHeaderConfig{
+		Values: &responseHeaderConfig.Values,
+		Key:    responseHeaderConfig.Key,
+	}
+}
+func transSDKResponseHeaderConfigToCreateRule(responseHeaderConfig alb.ResponseHeaderConfig) albsdk.CreateRuleRuleConditionsResponseHeaderConfig {
+	return albsdk.CreateRuleRuleConditionsResponseHeaderConfig{
+		Values: &responseHeaderConfig.Values,
+		Key:    responseHeaderConfig.Key,
+	}
+}
+func transSDKResponseHeaderConfigToUpdateRule(responseHeaderConfig alb.ResponseHeaderConfig) albsdk.UpdateRuleAttributeRuleConditionsResponseHeaderConfig {
+	return albsdk.UpdateRuleAttributeRuleConditionsResponseHeaderConfig{
+		Values: &responseHeaderConfig.Values,
+		Key:    responseHeaderConfig.Key,
+	}
+}
+
+func transSDKResponseStatusCodeConfigToCreateRules(responseStatusCodeConfig alb.ResponseStatusCodeConfig) albsdk.CreateRulesRulesRuleConditionsItemResponseStatusCodeConfig {
+	return albsdk.CreateRulesRulesRuleConditionsItemResponseStatusCodeConfig{
+		Values: &responseStatusCodeConfig.Values,
+	}
+}
+func transSDKResponseStatusCodeConfigToUpdateRules(responseStatusCodeConfig alb.ResponseStatusCodeConfig) albsdk.UpdateRulesAttributeRulesRuleConditionsItemResponseStatusCodeConfig {
+	return albsdk.UpdateRulesAttributeRulesRuleConditionsItemResponseStatusCodeConfig{
+		Values: &responseStatusCodeConfig.Values,
+	}
+}
+func transSDKResponseStatusCodeConfigToCreateRule(responseStatusCodeConfig alb.ResponseStatusCodeConfig) albsdk.CreateRuleRuleConditionsResponseStatusCodeConfig {
+	return albsdk.CreateRuleRuleConditionsResponseStatusCodeConfig{
+		Values: &responseStatusCodeConfig.Values,
+	}
+}
+func transSDKResponseStatusCodeConfigToUpdateRule(responseStatusCodeConfig alb.ResponseStatusCodeConfig) albsdk.UpdateRuleAttributeRuleConditionsResponseStatusCodeConfig {
+	return albsdk.UpdateRuleAttributeRuleConditionsResponseStatusCodeConfig{
+		Values: &responseStatusCodeConfig.Values,
+	}
+}
 func transSDKConditionToCreateRules(condition alb.Condition) *albsdk.CreateRulesRulesRuleConditionsItem {
 	resCondition := &albsdk.CreateRulesRulesRuleConditionsItem{}
 	resCondition.Type = condition.Type
@@ -909,6 +1262,12 @@ func transSDKConditionToCreateRules(condition alb.Condition) *albsdk.CreateRules
 		resCondition.QueryStringConfig = transSDKQueryStringConfigToCreateRules(condition.QueryStringConfig)
 	case util.RuleConditionFieldCookie:
 		resCondition.CookieConfig = transSDKCookieConfigToCreateRules(condition.CookieConfig)
+	case util.RuleConditionFieldSourceIp:
+		resCondition.SourceIpConfig = transSDKSourceIpConfigToCreateRules(condition.SourceIpConfig)
+	case util.RuleConditionFieldResponseHeader:
+		resCondition.ResponseHeaderConfig = transSDKResponseHeaderConfigToCreateRules(condition.ResponseHeaderConfig)
+	case util.RuleConditionFieldResponseStatusCode:
+		resCondition.ResponseStatusCodeConfig = transSDKResponseStatusCodeConfigToCreateRules(condition.ResponseStatusCodeConfig)
 	}
 
 	return resCondition
@@ -930,6 +1289,12 @@ func transSDKConditionToUpdateRules(condition alb.Condition) *albsdk.UpdateRules
 		resCondition.QueryStringConfig = transSDKQueryStringConfigToUpdateRules(condition.QueryStringConfig)
 	case util.RuleConditionFieldCookie:
 		resCondition.CookieConfig = transSDKCookieConfigToUpdateRules(condition.CookieConfig)
+	case util.RuleConditionFieldSourceIp:
+		resCondition.SourceIpConfig = transSDKSourceIpConfigToUpdateRules(condition.SourceIpConfig)
+	case util.RuleConditionFieldResponseHeader:
+		resCondition.ResponseHeaderConfig = transSDKResponseHeaderConfigToUpdateRules(condition.ResponseHeaderConfig)
+	case util.RuleConditionFieldResponseStatusCode:
+		resCondition.ResponseStatusCodeConfig = transSDKResponseStatusCodeConfigToUpdateRules(condition.ResponseStatusCodeConfig)
 	}
 
 	return resCondition
@@ -952,6 +1317,12 @@ func transSDKConditionToCreateRule(condition alb.Condition) *albsdk.CreateRuleRu
 		resCondition.QueryStringConfig = transSDKQueryStringConfigToCreateRule(condition.QueryStringConfig)
 	case util.RuleConditionFieldCookie:
 		resCondition.CookieConfig = transSDKCookieConfigToCreateRule(condition.CookieConfig)
+	case util.RuleConditionFieldSourceIp:
+		resCondition.SourceIpConfig = transSDKSourceIpConfigToCreateRule(condition.SourceIpConfig)
+	case util.RuleConditionFieldResponseHeader:
+		resCondition.ResponseHeaderConfig = transSDKResponseHeaderConfigToCreateRule(condition.ResponseHeaderConfig)
+	case util.RuleConditionFieldResponseStatusCode:
+		resCondition.ResponseStatusCodeConfig = transSDKResponseStatusCodeConfigToCreateRule(condition.ResponseStatusCodeConfig)
 	}
 
 	return resCondition
@@ -973,6 +1344,12 @@ func transSDKConditionToUpdateRule(condition alb.Condition) *albsdk.UpdateRuleAt
 		resCondition.QueryStringConfig = transSDKQueryStringConfigToUpdateRule(condition.QueryStringConfig)
 	case util.RuleConditionFieldCookie:
 		resCondition.CookieConfig = transSDKCookieConfigToUpdateRule(condition.CookieConfig)
+	case util.RuleConditionFieldSourceIp:
+		resCondition.SourceIpConfig = transSDKSourceIpConfigToUpdateRule(condition.SourceIpConfig)
+	case util.RuleConditionFieldResponseHeader:
+		resCondition.ResponseHeaderConfig = transSDKResponseHeaderConfigToUpdateRule(condition.ResponseHeaderConfig)
+	case util.RuleConditionFieldResponseStatusCode:
+		resCondition.ResponseStatusCodeConfig = transSDKResponseStatusCodeConfigToUpdateRule(condition.ResponseStatusCodeConfig)
 	}
 
 	return resCondition
@@ -1024,6 +1401,57 @@ func transSDKConditionsToUpdateRule(conditions []alb.Condition) *[]albsdk.Update
 	return &updateRuleConditions
 }
 
+func transModelValuesToSDK(values []alb.Value) []albsdk.Value {
+	var sdkValues []albsdk.Value
+	if len(values) != 0 {
+		sdkValues = make([]albsdk.Value, 0)
+		for _, v := range values {
+			sdkValues = append(sdkValues, albsdk.Value{
+				Key:   v.Key,
+				Value: v.Value,
+			})
+		}
+	}
+	return sdkValues
+}
+func transModelConditionToSDK(condition alb.Condition) albsdk.Condition {
+	sdkObj := albsdk.Condition{}
+	sdkObj.Type = condition.Type
+	switch condition.Type {
+	case util.RuleConditionFieldHost:
+		sdkObj.HostConfig = albsdk.HostConfig(condition.HostConfig)
+	case util.RuleConditionFieldHeader:
+		sdkObj.HeaderConfig = albsdk.HeaderConfig(condition.HeaderConfig)
+	case util.RuleConditionFieldMethod:
+		sdkObj.MethodConfig = albsdk.MethodConfig(condition.MethodConfig)
+	case util.RuleConditionFieldPath:
+		sdkObj.PathConfig = albsdk.PathConfig(condition.PathConfig)
+	case util.RuleConditionFieldQueryString:
+		sdkObj.QueryStringConfig = albsdk.QueryStringConfig{Values: transModelValuesToSDK(condition.QueryStringConfig.Values)}
+	case util.RuleConditionFieldCookie:
+		sdkObj.CookieConfig = albsdk.CookieConfig{Values: transModelValuesToSDK(condition.CookieConfig.Values)}
+	case util.RuleConditionFieldSourceIp:
+		sdkObj.SourceIpConfig = albsdk.SourceIpConfig(condition.SourceIpConfig)
+	case util.RuleConditionFieldResponseHeader:
+		sdkObj.ResponseHeaderConfig = albsdk.ResponseHeaderConfig(condition.ResponseHeaderConfig)
+	case util.RuleConditionFieldResponseStatusCode:
+		sdkObj.ResponseStatusCodeConfig = albsdk.ResponseStatusCodeConfig(condition.ResponseStatusCodeConfig)
+	}
+	return sdkObj
+}
+
+// TransModelConditionsToSDK converts the rule conditions of the model to the form returned by ListRules
+func TransModelConditionsToSDK(conditions []alb.Condition) []albsdk.Condition {
+	var sdkConditions []albsdk.Condition
+	if len(conditions) != 0 {
+		sdkConditions = make([]albsdk.Condition, 0)
+		for _, condition := range conditions {
+			sdkConditions = append(sdkConditions, transModelConditionToSDK(condition))
+		}
+	}
+	return sdkConditions
+}
+
 func buildSDKCreateListenerRuleRequest(lrSpec alb.ListenerRuleSpec) (*albsdk.CreateRuleRequest, error) {
 	lsID, err := lrSpec.ListenerID.Resolve(context.Background())
 	if err != nil {
@@ -1040,6 +1468,7 @@ func buildSDKCreateListenerRuleRequest(lrSpec alb.ListenerRuleSpec) (*albsdk.Cre
 	}
 	ruleReq.RuleActions = actions
 	ruleReq.Priority = requests.NewInteger(lrSpec.Priority)
+	ruleReq.Direction = alb.RuleDirection(lrSpec.Direction)
 
 	return ruleReq, nil
 }
@@ -1086,7 +1515,7 @@ func (r *ListenerRuleUpdateAnalyzer) analysis(_ context.Context, resLR *alb.List
 		r.priorityNeedUpdate = true
 	}
 
-	resActions, err := transModelActionsToSDK(resLR.Spec.RuleActions)
+	resActions, err := TransModelActionsToSDK(resLR.Spec.RuleActions)
 	if err != nil {
 		return err
 	}
@@ -1094,7 +1523,7 @@ func (r *ListenerRuleUpdateAnalyzer) analysis(_ context.Context, resLR *alb.List
 		r.ruleActionsNeedUpdate = true
 	}
 
-	if !reflect.DeepEqual(resLR.Spec.RuleConditions, sdkLR.RuleConditions) {
+	if !reflect.DeepEqual(TransModelConditionsToSDK(resLR.Spec.RuleConditions), sdkLR.RuleConditions) {
 		r.ruleConditionsNeedUpdate = true
 	}
 
@@ -1124,6 +1553,7 @@ var createRulesFunc = func(ctx context.Context, ruleMgr *ALBProvider, lsID strin
 			"startTime", startTime,
 			util.Action, util.CreateALBRules)
 		var err error
+		tracing.Inject(ctx, createRulesReq.GetHeaders())
 		createRuleResp, err = ruleMgr.auth.ALB.CreateRules(createRulesReq)
 		if err != nil {
 			ruleMgr.logger.V(util.MgrLogLevel).Info("creating rules",
@@ -1190,6 +1620,7 @@ func transModelRulesToSDKCreateRules(resLRs []*alb.ListenerRule) ([]albsdk.Creat
 			RuleName:       resLR.Spec.RuleName,
 			Priority:       strconv.Itoa(resLR.Spec.Priority),
 			RuleActions:    actions,
+			Direction:      alb.RuleDirection(resLR.Spec.Direction),
 		})
 	}
 	return creatRules, nil
@@ -1241,6 +1672,7 @@ var updateRulesFunc = func(ctx context.Context, ruleMgr *ALBProvider, rules []al
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.UpdateALBRulesAttribute)
+		tracing.Inject(ctx, updateRulesReq.GetHeaders())
 		updateRulesResp, err := ruleMgr.auth.ALB.UpdateRulesAttribute(updateRulesReq)
 		if err != nil {
 			ruleMgr.logger.V(util.MgrLogLevel).Info("updated rules attribute",
@@ -1386,6 +1818,7 @@ var deleteRulesFunc = func(ctx context.Context, ruleMgr *ALBProvider, ruleIDs []
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.DeleteALBRules)
+		tracing.Inject(ctx, deleteRulesReq.GetHeaders())
 		deleteRulesResp, err := ruleMgr.auth.ALB.DeleteRules(deleteRulesReq)
 		if err != nil {
 			ruleMgr.logger.V(util.MgrLogLevel).Info("deleting rules",
@@ -0,0 +1,400 @@
+package alb
+
+import (
+	"context"
+	"testing"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb/core"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func TestTransSDKConditionToCreateRules(t *testing.T) {
+	hosts := []string{"foo.example.com"}
+	paths := []string{"/foo"}
+	methods := []string{"GET", "HEAD"}
+	headers := []string{"gray"}
+	sourceIps := []string{"192.168.0.0/16"}
+	statusCodes := []string{"200"}
+
+	cases := []struct {
+		name      string
+		condition alb.Condition
+		expect    albsdk.CreateRulesRulesRuleConditionsItem
+	}{
+		{
+			name:      "host",
+			condition: alb.Condition{Type: util.RuleConditionFieldHost, HostConfig: alb.HostConfig{Values: hosts}},
+			expect: albsdk.CreateRulesRulesRuleConditionsItem{
+				Type:       util.RuleConditionFieldHost,
+				HostConfig: albsdk.CreateRulesRulesRuleConditionsItemHostConfig{Values: &hosts},
+			},
+		},
+		{
+			name:      "path",
+			condition: alb.Condition{Type: util.RuleConditionFieldPath, PathConfig: alb.PathConfig{Values: paths}},
+			expect: albsdk.CreateRulesRulesRuleConditionsItem{
+				Type:       util.RuleConditionFieldPath,
+				PathConfig: albsdk.CreateRulesRulesRuleConditionsItemPathConfig{Values: &paths},
+			},
+		},
+		{
+			name:      "method",
+			condition: alb.Condition{Type: util.RuleConditionFieldMethod, MethodConfig: alb.MethodConfig{Values: methods}},
+			expect: albsdk.CreateRulesRulesRuleConditionsItem{
+				Type:         util.RuleConditionFieldMethod,
+				MethodConfig: albsdk.CreateRulesRulesRuleConditionsItemMethodConfig{Values: &methods},
+			},
+		},
+		{
+			name:      "header",
+			condition: alb.Condition{Type: util.RuleConditionFieldHeader, HeaderConfig: alb.HeaderConfig{Key: "X-Env", Values: headers}},
+			expect: albsdk.CreateRulesRulesRuleConditionsItem{
+				Type:         util.RuleConditionFieldHeader,
+				HeaderConfig: albsdk.CreateRulesRulesRuleConditionsItemHeaderConfig{Key: "X-Env", Values: &headers},
+			},
+		},
+		{
+			name: "query string",
+			condition: alb.Condition{Type: util.RuleConditionFieldQueryString, QueryStringConfig: alb.QueryStringConfig{
+				Values: []alb.Value{{Key: "version", Value: "v2"}},
+			}},
+			expect: albsdk.CreateRulesRulesRuleConditionsItem{
+				Type: util.RuleConditionFieldQueryString,
+				QueryStringConfig: albsdk.CreateRulesRulesRuleConditionsItemQueryStringConfig{
+					Values: &[]albsdk.CreateRulesRulesRuleConditionsItemQueryStringConfigValuesItem{{Key: "version", Value: "v2"}},
+				},
+			},
+		},
+		{
+			name: "cookie",
+			condition: alb.Condition{Type: util.RuleConditionFieldCookie, CookieConfig: alb.CookieConfig{
+				Values: []alb.Value{{Key: "user", Value: "beta"}},
+			}},
+			expect: albsdk.CreateRulesRulesRuleConditionsItem{
+				Type: util.RuleConditionFieldCookie,
+				CookieConfig: albsdk.CreateRulesRulesRuleConditionsItemCookieConfig{
+					Values: &[]albsdk.CreateRulesRulesRuleConditionsItemCookieConfigValuesItem{{Key: "user", Value: "beta"}},
+				},
+			},
+		},
+		{
+			name:      "source ip",
+			condition: alb.Condition{Type: util.RuleConditionFieldSourceIp, SourceIpConfig: alb.SourceIpConfig{Values: sourceIps}},
+			expect: albsdk.CreateRulesRulesRuleConditionsItem{
+				Type:           util.RuleConditionFieldSourceIp,
+				SourceIpConfig: albsdk.CreateRulesRulesRuleConditionsItemSourceIpConfig{Values: &sourceIps},
+			},
+		},
+		{
+			name: "response header",
+			condition: alb.Condition{Type: util.RuleConditionFieldResponseHeader, ResponseHeaderConfig: alb.ResponseHeaderConfig{
+				Key: "X-Env", Values: headers,
+			}},
+			expect: albsdk.CreateRulesRulesRuleConditionsItem{
+				Type:                 util.RuleConditionFieldResponseHeader,
+				ResponseHeaderConfig: albsdk.CreateRulesRulesRuleConditionsItemResponseHeaderConfig{Key: "X-Env", Values: &headers},
+			},
+		},
+		{
+			name: "response status code",
+			condition: alb.Condition{Type: util.RuleConditionFieldResponseStatusCode, ResponseStatusCodeConfig: alb.ResponseStatusCodeConfig{
+				Values: statusCodes,
+			}},
+			expect: albsdk.CreateRulesRulesRuleConditionsItem{
+				Type:                     util.RuleConditionFieldResponseStatusCode,
+				ResponseStatusCodeConfig: albsdk.CreateRulesRulesRuleConditionsItemResponseStatusCodeConfig{Values: &statusCodes},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, &c.expect, transSDKConditionToCreateRules(c.condition))
+			// the other requests carry the same condition type
+			assert.Equal(t, c.condition.Type, transSDKConditionToUpdateRules(c.condition).Type)
+			assert.Equal(t, c.condition.Type, transSDKConditionToCreateRule(c.condition).Type)
+			assert.Equal(t, c.condition.Type, transSDKConditionToUpdateRule(c.condition).Type)
+		})
+	}
+}
+
+func TestTransModelConditionsToSDK(t *testing.T) {
+	cases := []struct {
+		name       string
+		conditions []alb.Condition
+		expect     []albsdk.Condition
+	}{
+		{
+			name: "no conditions",
+		},
+		{
+			name: "only the config of the type is kept",
+			conditions: []alb.Condition{{
+				Type:       util.RuleConditionFieldPath,
+				PathConfig: alb.PathConfig{Values: []string{"/foo"}},
+				HostConfig: alb.HostConfig{Values: []string{"ignored.example.com"}},
+			}},
+			expect: []albsdk.Condition{{Type: util.RuleConditionFieldPath, PathConfig: albsdk.PathConfig{Values: []string{"/foo"}}}},
+		},
+		{
+			name: "key value conditions",
+			conditions: []alb.Condition{
+				{Type: util.RuleConditionFieldQueryString, QueryStringConfig: alb.QueryStringConfig{Values: []alb.Value{{Key: "version", Value: "v2"}}}},
+				{Type: util.RuleConditionFieldCookie, CookieConfig: alb.CookieConfig{Values: []alb.Value{{Key: "user", Value: "beta"}}}},
+			},
+			expect: []albsdk.Condition{
+				{Type: util.RuleConditionFieldQueryString, QueryStringConfig: albsdk.QueryStringConfig{Values: []albsdk.Value{{Key: "version", Value: "v2"}}}},
+				{Type: util.RuleConditionFieldCookie, CookieConfig: albsdk.CookieConfig{Values: []albsdk.Value{{Key: "user", Value: "beta"}}}},
+			},
+		},
+		{
+			name: "request conditions",
+			conditions: []alb.Condition{
+				{Type: util.RuleConditionFieldMethod, MethodConfig: alb.MethodConfig{Values: []string{"GET"}}},
+				{Type: util.RuleConditionFieldHeader, HeaderConfig: alb.HeaderConfig{Key: "X-Env", Values: []string{"gray"}}},
+				{Type: util.RuleConditionFieldSourceIp, SourceIpConfig: alb.SourceIpConfig{Values: []string{"10.0.0.1"}}},
+			},
+			expect: []albsdk.Condition{
+				{Type: util.RuleConditionFieldMethod, MethodConfig: albsdk.MethodConfig{Values: []string{"GET"}}},
+				{Type: util.RuleConditionFieldHeader, HeaderConfig: albsdk.HeaderConfig{Key: "X-Env", Values: []string{"gray"}}},
+				{Type: util.RuleConditionFieldSourceIp, SourceIpConfig: albsdk.SourceIpConfig{Values: []string{"10.0.0.1"}}},
+			},
+		},
+		{
+			name: "response conditions",
+			conditions: []alb.Condition{
+				{Type: util.RuleConditionFieldResponseHeader, ResponseHeaderConfig: alb.ResponseHeaderConfig{Key: "X-Env", Values: []string{"gray"}}},
+				{Type: util.RuleConditionFieldResponseStatusCode, ResponseStatusCodeConfig: alb.ResponseStatusCodeConfig{Values: []string{"200"}}},
+			},
+			expect: []albsdk.Condition{
+				{Type: util.RuleConditionFieldResponseHeader, ResponseHeaderConfig: albsdk.ResponseHeaderConfig{Key: "X-Env", Values: []string{"gray"}}},
+				{Type: util.RuleConditionFieldResponseStatusCode, ResponseStatusCodeConfig: albsdk.ResponseStatusCodeConfig{Values: []string{"200"}}},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expect, TransModelConditionsToSDK(c.conditions))
+		})
+	}
+}
+
+func TestTransModelActionsToSDK(t *testing.T) {
+	cases := []struct {
+		name    string
+		actions []alb.Action
+		expect  []albsdk.Action
+	}{
+		{
+			name:    "forward",
+			actions: []alb.Action{{Type: util.RuleActionTypeForward, ForwardConfig: &alb.ForwardActionConfig{ServerGroups: []alb.ServerGroupTuple{{ServerGroupID: core.LiteralStringToken("sgp-1"), Weight: 100}}}}},
+			expect: []albsdk.Action{{
+				Order:              1,
+				Type:               util.RuleActionTypeForward,
+				ForwardGroupConfig: albsdk.ForwardGroupConfigInListRules{ServerGroupTuples: []albsdk.ServerGroupTuple{{ServerGroupId: "sgp-1", Weight: 100}}},
+			}},
+		},
+		{
+			name:    "fixed response",
+			actions: []alb.Action{{Type: util.RuleActionTypeFixedResponse, FixedResponseConfig: &alb.FixedResponseConfig{HttpCode: "503", Content: "busy", ContentType: "text/plain"}}},
+			expect: []albsdk.Action{{
+				Order:               1,
+				Type:                util.RuleActionTypeFixedResponse,
+				FixedResponseConfig: albsdk.FixedResponseConfig{HttpCode: "503", Content: "busy", ContentType: "text/plain"},
+			}},
+		},
+		{
+			name:    "redirect",
+			actions: []alb.Action{{Type: util.RuleActionTypeRedirect, RedirectConfig: &alb.RedirectConfig{HttpCode: "301", Protocol: "HTTPS", Port: "443"}}},
+			expect: []albsdk.Action{{
+				Order:          1,
+				Type:           util.RuleActionTypeRedirect,
+				RedirectConfig: albsdk.RedirectConfig{HttpCode: "301", Protocol: "HTTPS", Port: "443"},
+			}},
+		},
+		{
+			name: "the actions are ordered by position",
+			actions: []alb.Action{
+				{Type: util.RuleActionTypeRewrite, RewriteConfig: &alb.RewriteConfig{Path: "/bar"}},
+				{Type: util.RuleActionTypeInsertHeader, InsertHeaderConfig: &alb.InsertHeaderConfig{Key: "X-Env", Value: "gray", ValueType: "UserDefined"}},
+				{Type: util.RuleActionTypeRemoveHeader, RemoveHeaderConfig: &alb.RemoveHeaderConfig{Key: "X-Debug"}},
+				{Type: util.RuleActionTypeTrafficLimit, TrafficLimitConfig: &alb.TrafficLimitConfig{QPS: 100}},
+			},
+			expect: []albsdk.Action{
+				{Order: 1, Type: util.RuleActionTypeRewrite, RewriteConfig: albsdk.RewriteConfig{Path: "/bar"}},
+				{Order: 2, Type: util.RuleActionTypeInsertHeader, InsertHeaderConfig: albsdk.InsertHeaderConfig{Key: "X-Env", Value: "gray", ValueType: "UserDefined"}},
+				{Order: 3, Type: util.RuleActionTypeRemoveHeader, RemoveHeaderConfig: albsdk.RemoveHeaderConfig{Key: "X-Debug"}},
+				{Order: 4, Type: util.RuleActionTypeTrafficLimit, TrafficLimitConfig: albsdk.TrafficLimitConfig{QPS: 100}},
+			},
+		},
+		{
+			name: "traffic mirror",
+			actions: []alb.Action{{Type: util.RuleActionTypeTrafficMirror, TrafficMirrorConfig: &alb.TrafficMirrorConfig{
+				TargetType:        util.TrafficMirrorTargetTypeForwardGroupMirror,
+				MirrorGroupConfig: alb.MirrorGroupConfig{ServerGroupTuples: []alb.ServerGroupTuple{{ServerGroupID: core.LiteralStringToken("sgp-mirror")}}},
+			}}},
+			expect: []albsdk.Action{{
+				Order: 1,
+				Type:  util.RuleActionTypeTrafficMirror,
+				TrafficMirrorConfig: albsdk.TrafficMirrorConfig{
+					TargetType:        util.TrafficMirrorTargetTypeForwardGroupMirror,
+					MirrorGroupConfig: albsdk.MirrorGroupConfig{ServerGroupTuples: []albsdk.ServerGroupTuple{{ServerGroupId: "sgp-mirror"}}},
+				},
+			}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actions, err := TransModelActionsToSDK(c.actions)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, *actions)
+		})
+	}
+}
+
+func TestListenerRuleUpdateAnalyzer(t *testing.T) {
+	newRule := func(conditions ...alb.Condition) *alb.ListenerRule {
+		return &alb.ListenerRule{Spec: alb.ListenerRuleSpec{ALBListenerRuleSpec: alb.ALBListenerRuleSpec{
+			Priority:       1,
+			RuleName:       "rule",
+			RuleActions:    []alb.Action{{Type: util.RuleActionTypeFixedResponse, FixedResponseConfig: &alb.FixedResponseConfig{HttpCode: "200"}}},
+			RuleConditions: conditions,
+		}}}
+	}
+	query := alb.Condition{Type: util.RuleConditionFieldQueryString, QueryStringConfig: alb.QueryStringConfig{Values: []alb.Value{{Key: "version", Value: "v2"}}}}
+	sdkRule := &albsdk.Rule{
+		Priority:       1,
+		RuleName:       "rule",
+		RuleActions:    []albsdk.Action{{Order: 1, Type: util.RuleActionTypeFixedResponse, FixedResponseConfig: albsdk.FixedResponseConfig{HttpCode: "200"}}},
+		RuleConditions: TransModelConditionsToSDK([]alb.Condition{query}),
+	}
+
+	cases := []struct {
+		name             string
+		resLR            *alb.ListenerRule
+		conditionsUpdate bool
+	}{
+		{name: "same conditions", resLR: newRule(query)},
+		{
+			name: "changed value",
+			resLR: newRule(alb.Condition{Type: util.RuleConditionFieldQueryString, QueryStringConfig: alb.QueryStringConfig{
+				Values: []alb.Value{{Key: "version", Value: "v3"}},
+			}}),
+			conditionsUpdate: true,
+		},
+		{
+			name:             "added condition",
+			resLR:            newRule(query, alb.Condition{Type: util.RuleConditionFieldMethod, MethodConfig: alb.MethodConfig{Values: []string{"GET"}}}),
+			conditionsUpdate: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			analyzer := &ListenerRuleUpdateAnalyzer{}
+			assert.NoError(t, analyzer.analysis(context.TODO(), c.resLR, sdkRule))
+			assert.False(t, analyzer.ruleActionsNeedUpdate)
+			assert.Equal(t, c.conditionsUpdate, analyzer.ruleConditionsNeedUpdate)
+			assert.Equal(t, c.conditionsUpdate, analyzer.needUpdate)
+		})
+	}
+}
+
+func TestTransModelActionsToSDKCreateRules(t *testing.T) {
+	actions := []alb.Action{
+		{Type: util.RuleActionTypeTrafficLimit, TrafficLimitConfig: &alb.TrafficLimitConfig{QPS: 100}},
+		{Type: util.RuleActionTypeRewrite, RewriteConfig: &alb.RewriteConfig{Host: "${host}", Path: "/bar", Query: "${query}"}},
+		{Type: util.RuleActionTypeInsertHeader, InsertHeaderConfig: &alb.InsertHeaderConfig{Key: "X-Env", Value: "gray", ValueType: "UserDefined", CoverEnabled: true}},
+		{Type: util.RuleActionTypeRemoveHeader, RemoveHeaderConfig: &alb.RemoveHeaderConfig{Key: "X-Debug"}},
+		{Type: util.RuleActionTypeTrafficMirror, TrafficMirrorConfig: &alb.TrafficMirrorConfig{
+			TargetType:        util.TrafficMirrorTargetTypeForwardGroupMirror,
+			MirrorGroupConfig: alb.MirrorGroupConfig{ServerGroupTuples: []alb.ServerGroupTuple{{ServerGroupID: core.LiteralStringToken("sgp-mirror")}}},
+		}},
+		{Type: util.RuleActionTypeForward, ForwardConfig: &alb.ForwardActionConfig{ServerGroups: []alb.ServerGroupTuple{{ServerGroupID: core.LiteralStringToken("sgp-1"), Weight: 100}}}},
+	}
+	mirrorTuples := []albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfigServerGroupTuplesItem{{ServerGroupId: "sgp-mirror"}}
+	forwardTuples := []albsdk.CreateRulesRulesRuleActionsItemForwardGroupConfigServerGroupTuplesItem{{ServerGroupId: "sgp-1", Weight: "100"}}
+
+	sdkActions, err := transModelActionsToSDKCreateRules(actions)
+	assert.NoError(t, err)
+	assert.Equal(t, []albsdk.CreateRulesRulesRuleActionsItem{
+		{Order: "1", Type: util.RuleActionTypeTrafficLimit, TrafficLimitConfig: albsdk.CreateRulesRulesRuleActionsItemTrafficLimitConfig{QPS: "100"}},
+		{Order: "2", Type: util.RuleActionTypeRewrite, RewriteConfig: albsdk.CreateRulesRulesRuleActionsItemRewriteConfig{Host: "${host}", Path: "/bar", Query: "${query}"}},
+		{Order: "3", Type: util.RuleActionTypeInsertHeader, InsertHeaderConfig: albsdk.CreateRulesRulesRuleActionsItemInsertHeaderConfig{
+			Key: "X-Env", Value: "gray", ValueType: "UserDefined", CoverEnabled: "true",
+		}},
+		{Order: "4", Type: util.RuleActionTypeRemoveHeader, RemoveHeaderConfig: albsdk.CreateRulesRulesRuleActionsItemRemoveHeaderConfig{Key: "X-Debug"}},
+		{Order: "5", Type: util.RuleActionTypeTrafficMirror, TrafficMirrorConfig: albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfig{
+			TargetType:        util.TrafficMirrorTargetTypeForwardGroupMirror,
+			MirrorGroupConfig: albsdk.CreateRulesRulesRuleActionsItemTrafficMirrorConfigMirrorGroupConfig{ServerGroupTuples: &mirrorTuples},
+		}},
+		{Order: "6", Type: util.RuleActionTypeForward, ForwardGroupConfig: albsdk.CreateRulesRulesRuleActionsItemForwardGroupConfig{ServerGroupTuples: &forwardTuples}},
+	}, *sdkActions)
+}
+
+func TestTransModelRulesToSDKCreateRulesDirection(t *testing.T) {
+	stack := core.NewDefaultManager(core.StackID{Name: "test"})
+	newRule := func(id string, priority int, direction string) *alb.ListenerRule {
+		lrs := alb.ListenerRuleSpec{ListenerID: core.LiteralStringToken("lsn-1")}
+		lrs.Priority = priority
+		lrs.Direction = direction
+		lrs.RuleActions = []alb.Action{{Type: util.RuleActionTypeRemoveHeader, RemoveHeaderConfig: &alb.RemoveHeaderConfig{Key: "Server"}}}
+		return alb.NewListenerRule(stack, id, lrs)
+	}
+	rules, err := transModelRulesToSDKCreateRules([]*alb.ListenerRule{
+		newRule("1", 1, ""),
+		newRule("2", 2, util.RuleDirectionResponse),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, util.RuleDirectionRequest, rules[0].Direction)
+	assert.Equal(t, util.RuleDirectionResponse, rules[1].Direction)
+
+	req, err := buildSDKCreateListenerRuleRequest(newRule("3", 3, util.RuleDirectionResponse).Spec)
+	assert.NoError(t, err)
+	assert.Equal(t, util.RuleDirectionResponse, req.Direction)
+}
+
+func TestListenerRuleUpdateAnalyzerActions(t *testing.T) {
+	forward := alb.Action{Type: util.RuleActionTypeForward, ForwardConfig: &alb.ForwardActionConfig{ServerGroups: []alb.ServerGroupTuple{{ServerGroupID: core.LiteralStringToken("sgp-1"), Weight: 100}}}}
+	qpsLimit := func(qps int) alb.Action {
+		return alb.Action{Type: util.RuleActionTypeTrafficLimit, TrafficLimitConfig: &alb.TrafficLimitConfig{QPS: qps}}
+	}
+	removeHeader := alb.Action{Type: util.RuleActionTypeRemoveHeader, RemoveHeaderConfig: &alb.RemoveHeaderConfig{Key: "X-Debug"}}
+	newRule := func(actions ...alb.Action) *alb.ListenerRule {
+		return &alb.ListenerRule{Spec: alb.ListenerRuleSpec{ALBListenerRuleSpec: alb.ALBListenerRuleSpec{
+			Priority:    1,
+			RuleName:    "rule",
+			RuleActions: actions,
+		}}}
+	}
+	sdkActions, err := TransModelActionsToSDK([]alb.Action{qpsLimit(100), removeHeader, forward})
+	assert.NoError(t, err)
+	sdkRule := &albsdk.Rule{
+		Priority:       1,
+		RuleName:       "rule",
+		RuleActions:    *sdkActions,
+		RuleConditions: TransModelConditionsToSDK(nil),
+	}
+
+	cases := []struct {
+		name          string
+		resLR         *alb.ListenerRule
+		actionsUpdate bool
+	}{
+		{name: "same actions", resLR: newRule(qpsLimit(100), removeHeader, forward)},
+		{name: "changed qps", resLR: newRule(qpsLimit(200), removeHeader, forward), actionsUpdate: true},
+		{name: "removed action", resLR: newRule(qpsLimit(100), forward), actionsUpdate: true},
+		{name: "reordered actions", resLR: newRule(removeHeader, qpsLimit(100), forward), actionsUpdate: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			analyzer := &ListenerRuleUpdateAnalyzer{}
+			assert.NoError(t, analyzer.analysis(context.TODO(), c.resLR, sdkRule))
+			assert.False(t, analyzer.ruleConditionsNeedUpdate)
+			assert.Equal(t, c.actionsUpdate, analyzer.ruleActionsNeedUpdate)
+			assert.Equal(t, c.actionsUpdate, analyzer.needUpdate)
+		})
+	}
+}
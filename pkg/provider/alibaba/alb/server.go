@@ -11,6 +11,7 @@ import (
 
 	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 )
 
 var registerServersFunc = func(ctx context.Context, serverMgr *ALBProvider, sgpID string, servers []albsdk.AddServersToServerGroupServers) error {
@@ -31,6 +32,7 @@ var registerServersFunc = func(ctx context.Context, serverMgr *ALBProvider, sgpI
 		"traceID", traceID,
 		"startTime", startTime,
 		util.Action, util.AddALBServersToServerGroup)
+	tracing.Inject(ctx, addServerToSgpReq.GetHeaders())
 	addServerToSgpResp, err := serverMgr.auth.ALB.AddServersToServerGroup(addServerToSgpReq)
 	if err != nil {
 		return err
@@ -132,6 +134,7 @@ var deregisterServersFunc = func(ctx context.Context, serverMgr *ALBProvider, sg
 		"servers", servers,
 		"startTime", startTime,
 		util.Action, util.RemoveALBServersFromServerGroup)
+	tracing.Inject(ctx, removeServerFromSgpReq.GetHeaders())
 	removeServerFromSgpResp, err := serverMgr.auth.ALB.RemoveServersFromServerGroup(removeServerFromSgpReq)
 	if err != nil {
 		return err
@@ -268,6 +271,7 @@ func (m *ALBProvider) ReplaceALBServers(ctx context.Context, serverGroupID strin
 		"removedServers", removedServers,
 		"startTime", startTime,
 		util.Action, util.ReplaceALBServersInServerGroup)
+	tracing.Inject(ctx, replaceServerFromSgpReq.GetHeaders())
 	replaceServerFromSgpResp, err := m.auth.ALB.ReplaceServersInServerGroup(replaceServerFromSgpReq)
 	if err != nil {
 		return err
@@ -315,6 +319,60 @@ func (m *ALBProvider) ReplaceALBServers(ctx context.Context, serverGroupID strin
 	return nil
 }
 
+func (m *ALBProvider) UpdateALBServers(ctx context.Context, serverGroupID string, resServers []alb.BackendItem) error {
+	if len(serverGroupID) == 0 {
+		return fmt.Errorf("empty server group id when update servers error")
+	}
+
+	if len(resServers) == 0 {
+		return nil
+	}
+
+	serversToUpdate := make([]albsdk.UpdateServerGroupServersAttributeServers, 0)
+	for _, resServer := range resServers {
+		serverToUpdate, err := transModelBackendToSDKUpdateServerGroupServersAttributeServer(resServer)
+		if err != nil {
+			return err
+		}
+		serversToUpdate = append(serversToUpdate, *serverToUpdate)
+	}
+
+	traceID := ctx.Value(util.TraceID)
+	for len(serversToUpdate) > 0 {
+		cnt := util.BatchRegisterServersDefaultNum
+		if len(serversToUpdate) < cnt {
+			cnt = len(serversToUpdate)
+		}
+		batch := serversToUpdate[0:cnt]
+		serversToUpdate = serversToUpdate[cnt:]
+
+		updateServersReq := albsdk.CreateUpdateServerGroupServersAttributeRequest()
+		updateServersReq.ServerGroupId = serverGroupID
+		updateServersReq.Servers = &batch
+
+		startTime := time.Now()
+		m.logger.V(util.MgrLogLevel).Info("updating servers attribute",
+			"serverGroupID", serverGroupID,
+			"servers", batch,
+			"traceID", traceID,
+			"startTime", startTime,
+			util.Action, util.UpdateALBServersAttribute)
+		tracing.Inject(ctx, updateServersReq.GetHeaders())
+		updateServersResp, err := m.auth.ALB.UpdateServerGroupServersAttribute(updateServersReq)
+		if err != nil {
+			return err
+		}
+		m.logger.V(util.MgrLogLevel).Info("updated servers attribute",
+			"serverGroupID", serverGroupID,
+			"traceID", traceID,
+			"requestID", updateServersResp.RequestId,
+			"elapsedTime", time.Since(startTime).Milliseconds(),
+			util.Action, util.UpdateALBServersAttribute)
+	}
+
+	return nil
+}
+
 func (m *ALBProvider) ListALBServers(ctx context.Context, serverGroupID string) ([]albsdk.BackendServer, error) {
 	if len(serverGroupID) == 0 {
 		return nil, fmt.Errorf("empty server group id when list servers error")
@@ -339,6 +397,7 @@ func (m *ALBProvider) ListALBServers(ctx context.Context, serverGroupID string)
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.ListALBServerGroupServers)
+		tracing.Inject(ctx, listSgpServersReq.GetHeaders())
 		listSgpServersResp, err := m.auth.ALB.ListServerGroupServers(listSgpServersReq)
 		if err != nil {
 			return nil, err
@@ -467,6 +526,34 @@ func transModelBackendToSDKReplaceServersInServerGroupAddedServer(server alb.Bac
 	return serverToAdd, nil
 }
 
+func transModelBackendToSDKUpdateServerGroupServersAttributeServer(server alb.BackendItem) (*albsdk.UpdateServerGroupServersAttributeServers, error) {
+	serverToUpdate := new(albsdk.UpdateServerGroupServersAttributeServers)
+
+	serverToUpdate.ServerIp = server.ServerIp
+
+	if len(server.ServerId) == 0 {
+		return nil, fmt.Errorf("invalid server id for server: %v", server)
+	}
+	serverToUpdate.ServerId = server.ServerId
+
+	if !isServerPortValid(server.Port) {
+		return nil, fmt.Errorf("invalid server port for server: %v", server)
+	}
+	serverToUpdate.Port = strconv.Itoa(server.Port)
+
+	if !isServerTypeValid(server.Type) {
+		return nil, fmt.Errorf("invalid server type for server: %v", server)
+	}
+	serverToUpdate.ServerType = server.Type
+
+	if !isServerWeightValid(server.Weight) {
+		return nil, fmt.Errorf("invalid server weight for server: %v", server)
+	}
+	serverToUpdate.Weight = strconv.Itoa(server.Weight)
+
+	return serverToUpdate, nil
+}
+
 func transModelBackendsToSDKAddServersToServerGroupServers(servers []alb.BackendItem) ([]albsdk.AddServersToServerGroupServers, error) {
 	serversToAdd := make([]albsdk.AddServersToServerGroupServers, 0)
 	for _, resServer := range servers {
@@ -15,6 +15,7 @@ import (
 	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
 	"github.com/pkg/errors"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 )
 
 func (m *ALBProvider) CreateALBServerGroup(ctx context.Context, resSGP *alb.ServerGroup, trackingProvider tracking.TrackingProvider) (alb.ServerGroupStatus, error) {
@@ -32,6 +33,7 @@ func (m *ALBProvider) CreateALBServerGroup(ctx context.Context, resSGP *alb.Serv
 		"traceID", traceID,
 		"startTime", startTime,
 		util.Action, util.CreateALBServerGroup)
+	tracing.Inject(ctx, createSgpReq.GetHeaders())
 	createSgpResp, err := m.auth.ALB.CreateServerGroup(createSgpReq)
 	if err != nil {
 		return alb.ServerGroupStatus{}, err
@@ -61,6 +63,7 @@ func (m *ALBProvider) CreateALBServerGroup(ctx context.Context, resSGP *alb.Serv
 		"serverGroupID", createSgpResp.ServerGroupId,
 		"startTime", startTime,
 		util.Action, util.TagALBResource)
+	tracing.Inject(ctx, tagReq.GetHeaders())
 	tagResp, err := m.auth.ALB.TagResources(tagReq)
 	if err != nil {
 		if errTmp := m.DeleteALBServerGroup(ctx, createSgpResp.ServerGroupId); errTmp != nil {
@@ -106,6 +109,7 @@ func (m *ALBProvider) DeleteALBServerGroup(ctx context.Context, serverGroupID st
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.DeleteALBServerGroup)
+		tracing.Inject(ctx, deleteSgpReq.GetHeaders())
 		deleteSgpResp, err := m.auth.ALB.DeleteServerGroup(deleteSgpReq)
 		if err != nil {
 			m.logger.V(util.MgrLogLevel).Info("deleting server group",
@@ -136,7 +140,10 @@ func (m *ALBProvider) updateServerGroupAttribute(ctx context.Context, resSGP *al
 		isHealthCheckConfigNeedUpdate,
 		isStickySessionConfigNeedUpdate,
 		isServerGroupNameNeedUpdate,
-		isSchedulerNeedUpdate bool
+		isSchedulerNeedUpdate,
+		isUchConfigNeedUpdate,
+		isConnectionDrainConfigNeedUpdate,
+		isSlowStartConfigNeedUpdate bool
 	)
 	if resSGP.Spec.ServerGroupName != sdkSGP.ServerGroupName {
 		m.logger.V(util.MgrLogLevel).Info("ServerGroupName update:",
@@ -157,12 +164,21 @@ func (m *ALBProvider) updateServerGroupAttribute(ctx context.Context, resSGP *al
 			"traceID", traceID)
 		isSchedulerNeedUpdate = true
 	}
+	if strings.EqualFold(resSGP.Spec.Scheduler, util.ServerGroupSchedulerUch) &&
+		albsdk.UchConfig(resSGP.Spec.UchConfig) != sdkSGP.UchConfig {
+		m.logger.V(util.MgrLogLevel).Info("UchConfig update:",
+			"res", resSGP.Spec.UchConfig,
+			"sdk", sdkSGP.UchConfig,
+			"serverGroupID", sdkSGP.ServerGroupId,
+			"traceID", traceID)
+		isUchConfigNeedUpdate = true
+	}
 
 	if err := checkHealthCheckConfigValid(resSGP.Spec.HealthCheckConfig); err != nil {
 		return nil, err
 	}
 	if resSGP.Spec.HealthCheckConfig.HealthCheckEnabled {
-		if !reflect.DeepEqual(resSGP.Spec.HealthCheckConfig, sdkSGP.HealthCheckConfig) {
+		if !reflect.DeepEqual(albsdk.HealthCheckConfig(resSGP.Spec.HealthCheckConfig), sdkSGP.HealthCheckConfig) {
 			m.logger.V(util.MgrLogLevel).Info("HealthCheckConfig update:",
 				"res", resSGP.Spec.HealthCheckConfig,
 				"sdk", sdkSGP.HealthCheckConfig,
@@ -183,7 +199,7 @@ func (m *ALBProvider) updateServerGroupAttribute(ctx context.Context, resSGP *al
 		return nil, err
 	}
 	if resSGP.Spec.StickySessionConfig.StickySessionEnabled {
-		if !reflect.DeepEqual(resSGP.Spec.StickySessionConfig, sdkSGP.StickySessionConfig) {
+		if !isStickySessionConfigEqual(resSGP.Spec.StickySessionConfig, sdkSGP.StickySessionConfig) {
 			m.logger.V(util.MgrLogLevel).Info("StickySessionConfig update:",
 				"res", resSGP.Spec.StickySessionConfig,
 				"sdk", sdkSGP.StickySessionConfig,
@@ -200,8 +216,33 @@ func (m *ALBProvider) updateServerGroupAttribute(ctx context.Context, resSGP *al
 		isStickySessionConfigNeedUpdate = true
 	}
 
-	if !isServerGroupNameNeedUpdate && !isSchedulerNeedUpdate &&
-		!isHealthCheckConfigNeedUpdate && !isStickySessionConfigNeedUpdate {
+	if err := checkConnectionDrainConfigValid(resSGP.Spec.ConnectionDrainConfig); err != nil {
+		return nil, err
+	}
+	if !isConnectionDrainConfigEqual(resSGP.Spec.ConnectionDrainConfig, sdkSGP.ConnectionDrainConfig) {
+		m.logger.V(util.MgrLogLevel).Info("ConnectionDrainConfig update:",
+			"res", resSGP.Spec.ConnectionDrainConfig,
+			"sdk", sdkSGP.ConnectionDrainConfig,
+			"serverGroupID", sdkSGP.ServerGroupId,
+			"traceID", traceID)
+		isConnectionDrainConfigNeedUpdate = true
+	}
+
+	if err := checkSlowStartConfigValid(resSGP.Spec.SlowStartConfig); err != nil {
+		return nil, err
+	}
+	if !isSlowStartConfigEqual(resSGP.Spec.SlowStartConfig, sdkSGP.SlowStartConfig) {
+		m.logger.V(util.MgrLogLevel).Info("SlowStartConfig update:",
+			"res", resSGP.Spec.SlowStartConfig,
+			"sdk", sdkSGP.SlowStartConfig,
+			"serverGroupID", sdkSGP.ServerGroupId,
+			"traceID", traceID)
+		isSlowStartConfigNeedUpdate = true
+	}
+
+	if !isServerGroupNameNeedUpdate && !isSchedulerNeedUpdate && !isUchConfigNeedUpdate &&
+		!isHealthCheckConfigNeedUpdate && !isStickySessionConfigNeedUpdate &&
+		!isConnectionDrainConfigNeedUpdate && !isSlowStartConfigNeedUpdate {
 		return nil, nil
 	}
 
@@ -214,12 +255,24 @@ func (m *ALBProvider) updateServerGroupAttribute(ctx context.Context, resSGP *al
 	if isSchedulerNeedUpdate {
 		updateSgpReq.Scheduler = resSGP.Spec.Scheduler
 	}
+	if isUchConfigNeedUpdate {
+		updateSgpReq.UchConfig = albsdk.UpdateServerGroupAttributeUchConfig{
+			Type:  resSGP.Spec.UchConfig.Type,
+			Value: resSGP.Spec.UchConfig.Value,
+		}
+	}
 	if isHealthCheckConfigNeedUpdate {
 		updateSgpReq.HealthCheckConfig = *transSDKHealthCheckConfigToUpdateSGP(resSGP.Spec.HealthCheckConfig)
 	}
 	if isStickySessionConfigNeedUpdate {
 		updateSgpReq.StickySessionConfig = *transSDKStickySessionConfigToUpdateSGP(resSGP.Spec.StickySessionConfig)
 	}
+	if isConnectionDrainConfigNeedUpdate {
+		updateSgpReq.ConnectionDrainConfig = *transSDKConnectionDrainConfigToUpdateSGP(resSGP.Spec.ConnectionDrainConfig)
+	}
+	if isSlowStartConfigNeedUpdate {
+		updateSgpReq.SlowStartConfig = *transSDKSlowStartConfigToUpdateSGP(resSGP.Spec.SlowStartConfig)
+	}
 
 	startTime := time.Now()
 	m.logger.V(util.MgrLogLevel).Info("updating server group attribute",
@@ -229,6 +282,7 @@ func (m *ALBProvider) updateServerGroupAttribute(ctx context.Context, resSGP *al
 		"serverGroupID", sdkSGP.ServerGroupId,
 		"startTime", startTime,
 		util.Action, util.UpdateALBServerGroupAttribute)
+	tracing.Inject(ctx, updateSgpReq.GetHeaders())
 	updateSgpResp, err := m.auth.ALB.UpdateServerGroupAttribute(updateSgpReq)
 	if err != nil {
 		return nil, err
@@ -259,6 +313,15 @@ func buildSDKServerGroupCreateRequest(sgpSpec alb.ServerGroupSpec) (*albsdk.Crea
 		return nil, fmt.Errorf("invalid server group scheduler: %s", sgpSpec.Scheduler)
 	}
 	sgpReq.Scheduler = sgpSpec.Scheduler
+	if strings.EqualFold(sgpSpec.Scheduler, util.ServerGroupSchedulerUch) {
+		if len(sgpSpec.UchConfig.Value) == 0 {
+			return nil, fmt.Errorf("invalid server group UchConfig: %v", sgpSpec.UchConfig)
+		}
+		sgpReq.UchConfig = albsdk.CreateServerGroupUchConfig{
+			Type:  sgpSpec.UchConfig.Type,
+			Value: sgpSpec.UchConfig.Value,
+		}
+	}
 
 	if !isServerGroupProtocolValid(sgpSpec.Protocol) {
 		return nil, fmt.Errorf("invalid server group protocol: %s", sgpSpec.Protocol)
@@ -274,6 +337,25 @@ func buildSDKServerGroupCreateRequest(sgpSpec alb.ServerGroupSpec) (*albsdk.Crea
 		return nil, err
 	}
 	sgpReq.StickySessionConfig = *transSDKStickySessionConfigToCreateSGP(sgpSpec.StickySessionConfig)
+	if err := checkConnectionDrainConfigValid(sgpSpec.ConnectionDrainConfig); err != nil {
+		return nil, err
+	}
+	// connection drain and slow start are not supported by the basic edition, only set them when enabled
+	if sgpSpec.ConnectionDrainConfig.ConnectionDrainEnabled {
+		sgpReq.ConnectionDrainConfig = albsdk.CreateServerGroupConnectionDrainConfig{
+			ConnectionDrainEnabled: strconv.FormatBool(true),
+			ConnectionDrainTimeout: strconv.Itoa(sgpSpec.ConnectionDrainConfig.ConnectionDrainTimeout),
+		}
+	}
+	if err := checkSlowStartConfigValid(sgpSpec.SlowStartConfig); err != nil {
+		return nil, err
+	}
+	if sgpSpec.SlowStartConfig.SlowStartEnabled {
+		sgpReq.SlowStartConfig = albsdk.CreateServerGroupSlowStartConfig{
+			SlowStartEnabled:  strconv.FormatBool(true),
+			SlowStartDuration: strconv.Itoa(sgpSpec.SlowStartConfig.SlowStartDuration),
+		}
+	}
 	sgpReq.ServerGroupType = sgpSpec.ServerGroupType
 
 	return sgpReq, nil
@@ -299,7 +381,8 @@ func checkHealthCheckConfigValid(conf alb.HealthCheckConfig) error {
 		return fmt.Errorf("invalid server group HealthCheckMethod: %v", conf.HealthCheckMethod)
 	}
 	if !strings.EqualFold(conf.HealthCheckProtocol, util.ServerGroupHealthCheckProtocolHTTP) &&
-		!strings.EqualFold(conf.HealthCheckProtocol, util.ServerGroupHealthCheckProtocolHTTPS) {
+		!strings.EqualFold(conf.HealthCheckProtocol, util.ServerGroupHealthCheckProtocolHTTPS) &&
+		!strings.EqualFold(conf.HealthCheckProtocol, util.ServerGroupHealthCheckProtocolGRPC) {
 		return fmt.Errorf("invalid server group HealthCheckProtocol: %v", conf.HealthCheckProtocol)
 	}
 	if conf.HealthCheckTimeout < 1 || conf.HealthCheckTimeout > 300 {
@@ -340,6 +423,56 @@ func checkStickySessionConfigValid(conf alb.StickySessionConfig) error {
 	return nil
 }
 
+func isStickySessionConfigEqual(res alb.StickySessionConfig, sdk albsdk.StickySessionConfig) bool {
+	if res.StickySessionEnabled != sdk.StickySessionEnabled {
+		return false
+	}
+	if !res.StickySessionEnabled {
+		return true
+	}
+	if !strings.EqualFold(res.StickySessionType, sdk.StickySessionType) {
+		return false
+	}
+	if strings.EqualFold(res.StickySessionType, util.ServerGroupStickySessionTypeServer) {
+		return res.Cookie == sdk.Cookie
+	}
+	return res.CookieTimeout == sdk.CookieTimeout
+}
+
+func checkConnectionDrainConfigValid(conf alb.ConnectionDrainConfig) error {
+	if !conf.ConnectionDrainEnabled {
+		return nil
+	}
+	if conf.ConnectionDrainTimeout < 0 || conf.ConnectionDrainTimeout > 900 {
+		return fmt.Errorf("invalid server group ConnectionDrainTimeout: %v", conf.ConnectionDrainTimeout)
+	}
+	return nil
+}
+
+func isConnectionDrainConfigEqual(res alb.ConnectionDrainConfig, sdk albsdk.ConnectionDrainConfig) bool {
+	if res.ConnectionDrainEnabled != sdk.ConnectionDrainEnabled {
+		return false
+	}
+	return !res.ConnectionDrainEnabled || res.ConnectionDrainTimeout == sdk.ConnectionDrainTimeout
+}
+
+func checkSlowStartConfigValid(conf alb.SlowStartConfig) error {
+	if !conf.SlowStartEnabled {
+		return nil
+	}
+	if conf.SlowStartDuration < 30 || conf.SlowStartDuration > 900 {
+		return fmt.Errorf("invalid server group SlowStartDuration: %v", conf.SlowStartDuration)
+	}
+	return nil
+}
+
+func isSlowStartConfigEqual(res alb.SlowStartConfig, sdk albsdk.SlowStartConfig) bool {
+	if res.SlowStartEnabled != sdk.SlowStartEnabled {
+		return false
+	}
+	return !res.SlowStartEnabled || res.SlowStartDuration == sdk.SlowStartDuration
+}
+
 func isServerGroupResourceInUseError(err error) bool {
 	if strings.Contains(err.Error(), "ResourceInUse.ServerGroup") ||
 		strings.Contains(err.Error(), "IncorrectStatus.ServerGroup") {
@@ -351,14 +484,16 @@ func isServerGroupResourceInUseError(err error) bool {
 func isServerGroupSchedulerValid(scheduler string) bool {
 	if strings.EqualFold(scheduler, util.ServerGroupSchedulerWrr) ||
 		strings.EqualFold(scheduler, util.ServerGroupSchedulerWlc) ||
-		strings.EqualFold(scheduler, util.ServerGroupSchedulerSch) {
+		strings.EqualFold(scheduler, util.ServerGroupSchedulerSch) ||
+		strings.EqualFold(scheduler, util.ServerGroupSchedulerUch) {
 		return true
 	}
 	return false
 }
 func isServerGroupProtocolValid(protocol string) bool {
 	if strings.EqualFold(protocol, util.ServerGroupProtocolHTTP) ||
-		strings.EqualFold(protocol, util.ServerGroupProtocolHTTPS) {
+		strings.EqualFold(protocol, util.ServerGroupProtocolHTTPS) ||
+		strings.EqualFold(protocol, util.ServerGroupProtocolGRPC) {
 		return true
 	}
 	return false
@@ -449,3 +584,29 @@ func transSDKStickySessionConfigToUpdateSGP(conf alb.StickySessionConfig) *albsd
 		StickySessionType:    conf.StickySessionType,
 	}
 }
+
+func transSDKConnectionDrainConfigToUpdateSGP(conf alb.ConnectionDrainConfig) *albsdk.UpdateServerGroupAttributeConnectionDrainConfig {
+	if !conf.ConnectionDrainEnabled {
+		return &albsdk.UpdateServerGroupAttributeConnectionDrainConfig{
+			ConnectionDrainEnabled: strconv.FormatBool(conf.ConnectionDrainEnabled),
+		}
+	}
+
+	return &albsdk.UpdateServerGroupAttributeConnectionDrainConfig{
+		ConnectionDrainEnabled: strconv.FormatBool(conf.ConnectionDrainEnabled),
+		ConnectionDrainTimeout: strconv.Itoa(conf.ConnectionDrainTimeout),
+	}
+}
+
+func transSDKSlowStartConfigToUpdateSGP(conf alb.SlowStartConfig) *albsdk.UpdateServerGroupAttributeSlowStartConfig {
+	if !conf.SlowStartEnabled {
+		return &albsdk.UpdateServerGroupAttributeSlowStartConfig{
+			SlowStartEnabled: strconv.FormatBool(conf.SlowStartEnabled),
+		}
+	}
+
+	return &albsdk.UpdateServerGroupAttributeSlowStartConfig{
+		SlowStartEnabled:  strconv.FormatBool(conf.SlowStartEnabled),
+		SlowStartDuration: strconv.Itoa(conf.SlowStartDuration),
+	}
+}
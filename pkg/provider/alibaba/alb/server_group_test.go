@@ -0,0 +1,176 @@
+package alb
+
+import (
+	"testing"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+func TestBuildSDKServerGroupCreateRequest(t *testing.T) {
+	newSpec := func(mutate func(spec *alb.ServerGroupSpec)) alb.ServerGroupSpec {
+		spec := alb.ServerGroupSpec{ALBServerGroupSpec: alb.ALBServerGroupSpec{
+			ServerGroupName: "sgp",
+			VpcId:           "vpc-1",
+			Scheduler:       util.ServerGroupSchedulerWrr,
+			Protocol:        util.ServerGroupProtocolHTTP,
+		}}
+		mutate(&spec)
+		return spec
+	}
+
+	cases := []struct {
+		name      string
+		spec      alb.ServerGroupSpec
+		expectErr bool
+		check     func(t *testing.T, req *albsdk.CreateServerGroupRequest)
+	}{
+		{
+			name: "defaults leave the optional configs unset",
+			spec: newSpec(func(spec *alb.ServerGroupSpec) {}),
+			check: func(t *testing.T, req *albsdk.CreateServerGroupRequest) {
+				assert.Equal(t, util.ServerGroupSchedulerWrr, req.Scheduler)
+				assert.Equal(t, albsdk.CreateServerGroupUchConfig{}, req.UchConfig)
+				assert.Equal(t, albsdk.CreateServerGroupConnectionDrainConfig{}, req.ConnectionDrainConfig)
+				assert.Equal(t, albsdk.CreateServerGroupSlowStartConfig{}, req.SlowStartConfig)
+			},
+		},
+		{
+			name: "uch scheduler",
+			spec: newSpec(func(spec *alb.ServerGroupSpec) {
+				spec.Scheduler = util.ServerGroupSchedulerUch
+				spec.UchConfig = alb.UchConfig{Type: util.ServerGroupUchConfigTypeQueryString, Value: "uid"}
+			}),
+			check: func(t *testing.T, req *albsdk.CreateServerGroupRequest) {
+				assert.Equal(t, albsdk.CreateServerGroupUchConfig{Type: util.ServerGroupUchConfigTypeQueryString, Value: "uid"}, req.UchConfig)
+			},
+		},
+		{
+			name:      "uch scheduler without value",
+			spec:      newSpec(func(spec *alb.ServerGroupSpec) { spec.Scheduler = util.ServerGroupSchedulerUch }),
+			expectErr: true,
+		},
+		{
+			name: "grpc protocol",
+			spec: newSpec(func(spec *alb.ServerGroupSpec) { spec.Protocol = util.ServerGroupProtocolGRPC }),
+			check: func(t *testing.T, req *albsdk.CreateServerGroupRequest) {
+				assert.Equal(t, util.ServerGroupProtocolGRPC, req.Protocol)
+			},
+		},
+		{
+			name:      "invalid protocol",
+			spec:      newSpec(func(spec *alb.ServerGroupSpec) { spec.Protocol = "TCP" }),
+			expectErr: true,
+		},
+		{
+			name: "connection drain and slow start",
+			spec: newSpec(func(spec *alb.ServerGroupSpec) {
+				spec.ConnectionDrainConfig = alb.ConnectionDrainConfig{ConnectionDrainEnabled: true, ConnectionDrainTimeout: 60}
+				spec.SlowStartConfig = alb.SlowStartConfig{SlowStartEnabled: true, SlowStartDuration: 30}
+			}),
+			check: func(t *testing.T, req *albsdk.CreateServerGroupRequest) {
+				assert.Equal(t, albsdk.CreateServerGroupConnectionDrainConfig{ConnectionDrainEnabled: "true", ConnectionDrainTimeout: "60"}, req.ConnectionDrainConfig)
+				assert.Equal(t, albsdk.CreateServerGroupSlowStartConfig{SlowStartEnabled: "true", SlowStartDuration: "30"}, req.SlowStartConfig)
+			},
+		},
+		{
+			name: "connection drain timeout out of range",
+			spec: newSpec(func(spec *alb.ServerGroupSpec) {
+				spec.ConnectionDrainConfig = alb.ConnectionDrainConfig{ConnectionDrainEnabled: true, ConnectionDrainTimeout: 901}
+			}),
+			expectErr: true,
+		},
+		{
+			name: "slow start duration out of range",
+			spec: newSpec(func(spec *alb.ServerGroupSpec) {
+				spec.SlowStartConfig = alb.SlowStartConfig{SlowStartEnabled: true, SlowStartDuration: 10}
+			}),
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := buildSDKServerGroupCreateRequest(c.spec)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			c.check(t, req)
+		})
+	}
+}
+
+func TestServerGroupConfigEqual(t *testing.T) {
+	insert := alb.StickySessionConfig{StickySessionEnabled: true, StickySessionType: util.ServerGroupStickySessionTypeInsert, CookieTimeout: 600}
+	server := alb.StickySessionConfig{StickySessionEnabled: true, StickySessionType: util.ServerGroupStickySessionTypeServer, Cookie: "session"}
+
+	stickySessionCases := []struct {
+		name   string
+		res    alb.StickySessionConfig
+		sdk    albsdk.StickySessionConfig
+		expect bool
+	}{
+		{
+			name:   "both disabled with different details",
+			res:    alb.StickySessionConfig{CookieTimeout: 1000},
+			sdk:    albsdk.StickySessionConfig{StickySessionType: "Insert", CookieTimeout: 600},
+			expect: true,
+		},
+		{
+			name: "enabled",
+			res:  insert,
+			sdk:  albsdk.StickySessionConfig{StickySessionEnabled: false},
+		},
+		{
+			name:   "insert ignores the cookie",
+			res:    insert,
+			sdk:    albsdk.StickySessionConfig{StickySessionEnabled: true, StickySessionType: "insert", CookieTimeout: 600, Cookie: "old"},
+			expect: true,
+		},
+		{
+			name: "insert with another timeout",
+			res:  insert,
+			sdk:  albsdk.StickySessionConfig{StickySessionEnabled: true, StickySessionType: "Insert", CookieTimeout: 1000},
+		},
+		{
+			name:   "server ignores the timeout",
+			res:    server,
+			sdk:    albsdk.StickySessionConfig{StickySessionEnabled: true, StickySessionType: "Server", Cookie: "session", CookieTimeout: 1000},
+			expect: true,
+		},
+		{
+			name: "server with another cookie",
+			res:  server,
+			sdk:  albsdk.StickySessionConfig{StickySessionEnabled: true, StickySessionType: "Server", Cookie: "sid"},
+		},
+		{
+			name: "changed type",
+			res:  server,
+			sdk:  albsdk.StickySessionConfig{StickySessionEnabled: true, StickySessionType: "Insert", Cookie: "session"},
+		},
+	}
+	for _, c := range stickySessionCases {
+		t.Run("sticky session "+c.name, func(t *testing.T) {
+			assert.Equal(t, c.expect, isStickySessionConfigEqual(c.res, c.sdk))
+		})
+	}
+
+	// the timeout and duration are ignored when disabled
+	assert.True(t, isConnectionDrainConfigEqual(alb.ConnectionDrainConfig{}, albsdk.ConnectionDrainConfig{ConnectionDrainTimeout: 300}))
+	assert.True(t, isConnectionDrainConfigEqual(alb.ConnectionDrainConfig{ConnectionDrainEnabled: true, ConnectionDrainTimeout: 60},
+		albsdk.ConnectionDrainConfig{ConnectionDrainEnabled: true, ConnectionDrainTimeout: 60}))
+	assert.False(t, isConnectionDrainConfigEqual(alb.ConnectionDrainConfig{ConnectionDrainEnabled: true, ConnectionDrainTimeout: 60},
+		albsdk.ConnectionDrainConfig{ConnectionDrainEnabled: true, ConnectionDrainTimeout: 300}))
+	assert.False(t, isConnectionDrainConfigEqual(alb.ConnectionDrainConfig{}, albsdk.ConnectionDrainConfig{ConnectionDrainEnabled: true}))
+
+	assert.True(t, isSlowStartConfigEqual(alb.SlowStartConfig{}, albsdk.SlowStartConfig{SlowStartDuration: 30}))
+	assert.True(t, isSlowStartConfigEqual(alb.SlowStartConfig{SlowStartEnabled: true, SlowStartDuration: 30},
+		albsdk.SlowStartConfig{SlowStartEnabled: true, SlowStartDuration: 30}))
+	assert.False(t, isSlowStartConfigEqual(alb.SlowStartConfig{SlowStartEnabled: true, SlowStartDuration: 30},
+		albsdk.SlowStartConfig{SlowStartEnabled: true, SlowStartDuration: 60}))
+	assert.False(t, isSlowStartConfigEqual(alb.SlowStartConfig{SlowStartEnabled: true, SlowStartDuration: 30}, albsdk.SlowStartConfig{}))
+}
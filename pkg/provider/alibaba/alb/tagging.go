@@ -10,6 +10,7 @@ import (
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
 
 	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 )
 
 func transTagFilterToListServerGroupTags(tagFilters map[string]string) []albsdk.ListServerGroupsTag {
@@ -59,6 +60,7 @@ func (m *ALBProvider) ListALBServerGroupsByTag(ctx context.Context, tagFilters m
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.ListALBServerGroups)
+		tracing.Inject(ctx, sgpReq.GetHeaders())
 		sgpResp, err := m.auth.ALB.ListServerGroups(sgpReq)
 		if err != nil {
 			return nil, err
@@ -107,6 +109,7 @@ func (m *ALBProvider) ListAlbLoadBalancersByTag(ctx context.Context, tagFilters
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.ListALBLoadBalancers)
+		tracing.Inject(ctx, lbReq.GetHeaders())
 		lbResp, err := m.auth.ALB.ListLoadBalancers(lbReq)
 		if err != nil {
 			return nil, err
@@ -166,6 +169,7 @@ func (m *ALBProvider) ListALBsWithTags(ctx context.Context, tagFilters map[strin
 			"traceID", traceID,
 			"startTime", startTime,
 			util.Action, util.GetALBLoadBalancerAttribute)
+		tracing.Inject(ctx, getLbReq.GetHeaders())
 		getLbResp, err := m.auth.ALB.GetLoadBalancerAttribute(getLbReq)
 		if err != nil {
 			return nil, err
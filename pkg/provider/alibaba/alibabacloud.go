@@ -3,6 +3,7 @@ package alibaba
 import (
 	"fmt"
 
+	"k8s.io/cloud-provider-alibaba-cloud/cmd/health"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/alb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
@@ -31,6 +32,9 @@ func NewAlibabaCloud() prvd.Provider {
 		klog.Warningf("refresh token: %s", err.Error())
 	}
 
+	health.RegisterReadiness("cloud-token", health.CheckerFunc(mgr.CheckToken))
+	health.RegisterReadiness("cloud-api", health.NewCachedChecker(health.CheckerFunc(mgr.ProbeCloud), base.CloudProbePeriod))
+
 	metric.RegisterPrometheus()
 
 	return AlibabaCloud{
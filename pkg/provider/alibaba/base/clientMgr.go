@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
@@ -52,6 +53,10 @@ const (
 	KubernetesCloudControllerManager = "ack.ccm"
 	AgentClusterId                   = "ClusterId"
 	TokenSyncPeriod                  = 10 * time.Minute
+	// TokenStalePeriod is how long the token is considered stale since the last successful refresh
+	TokenStalePeriod = 3 * TokenSyncPeriod
+	// CloudProbePeriod is how long the result of the cloud api probe is cached
+	CloudProbePeriod = time.Minute
 
 	AccessKeyID     = "ACCESS_KEY_ID"
 	AccessKeySecret = "ACCESS_KEY_SECRET"
@@ -64,6 +69,9 @@ type ClientMgr struct {
 	stop   <-chan struct{}
 	Region string
 
+	tokenLock        sync.Mutex
+	tokenRefreshTime time.Time
+
 	Meta prvd.IMetaData
 	ECS  *ecs.Client
 	VPC  *vpc.Client
@@ -75,6 +83,9 @@ type ClientMgr struct {
 	CAS  *cas.Client
 	ESS  *ess.Client
 	EFLO *efloController.Client
+
+	// RateLimiter is shared by all the clients, nil if the rate limit is disabled
+	RateLimiter *RateLimiter
 }
 
 // NewClientMgr return a new client manager
@@ -90,62 +101,67 @@ func NewClientMgr() (*ClientMgr, error) {
 		return nil, fmt.Errorf("can not determin region: %s", err.Error())
 	}
 
+	limiter, err := NewRateLimiter(ctrlCfg.CloudCFG.Global.RateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("initialize rate limiter: %s", err.Error())
+	}
+
 	credential := &credentials.StsTokenCredential{
 		AccessKeyId:       "key",
 		AccessKeySecret:   "secret",
 		AccessKeyStsToken: "",
 	}
 
-	ecli, err := ecs.NewClientWithOptions(region, clientCfg(), credential)
+	ecli, err := ecs.NewClientWithOptions(region, clientCfg(limiter, "ecs"), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba ecs client: %s", err.Error())
 	}
 	ecli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	ecli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	vpcli, err := vpc.NewClientWithOptions(region, clientCfg(), credential)
+	vpcli, err := vpc.NewClientWithOptions(region, clientCfg(limiter, "vpc"), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba vpc client: %s", err.Error())
 	}
 	vpcli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	vpcli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	slbcli, err := slb.NewClientWithOptions(region, clientCfg(), credential)
+	slbcli, err := slb.NewClientWithOptions(region, clientCfg(limiter, "slb"), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba slb client: %s", err.Error())
 	}
 	slbcli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	slbcli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	albcli, err := alb.NewClientWithOptions(region, clientCfg(), credential)
+	albcli, err := alb.NewClientWithOptions(region, clientCfg(limiter, "alb"), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba alb client: %s", err.Error())
 	}
 	albcli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	albcli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	slscli, err := sls.NewClientWithOptions(region, clientCfg(), credential)
+	slscli, err := sls.NewClientWithOptions(region, clientCfg(limiter, "sls"), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba sls client: %s", err.Error())
 	}
 	slscli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	slscli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	cascli, err := cas.NewClientWithOptions(region, clientCfg(), credential)
+	cascli, err := cas.NewClientWithOptions(region, clientCfg(limiter, "cas"), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba cas client: %s", err.Error())
 	}
 	cascli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	cascli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	pvtzcli, err := pvtz.NewClientWithOptions(region, clientCfg(), credential)
+	pvtzcli, err := pvtz.NewClientWithOptions(region, clientCfg(limiter, "pvtz"), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba pvtz client: %s", err.Error())
 	}
 	pvtzcli.AppendUserAgent(KubernetesCloudControllerManager, version.Version)
 	pvtzcli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
-	esscli, err := ess.NewClientWithOptions(region, clientCfg(), credential)
+	esscli, err := ess.NewClientWithOptions(region, clientCfg(limiter, "ess"), credential)
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba pvtz client: %s", err.Error())
 	}
@@ -153,12 +169,12 @@ func NewClientMgr() (*ClientMgr, error) {
 	esscli.AppendUserAgent(AgentClusterId, CLUSTER_ID)
 
 	// new sdk
-	nlbcli, err := nlb.NewClient(openapiCfg(region, credential, ctrlCfg.ControllerCFG.NetWork))
+	nlbcli, err := nlb.NewClient(openapiCfg(region, credential, ctrlCfg.ControllerCFG.NetWork, limiter, "nlb"))
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba nlb client: %s", err.Error())
 	}
 
-	eflocli, err := efloController.NewClient(openapiCfg(region, credential, ctrlCfg.ControllerCFG.NetWork))
+	eflocli, err := efloController.NewClient(openapiCfg(region, credential, ctrlCfg.ControllerCFG.NetWork, limiter, "eflo"))
 	if err != nil {
 		return nil, fmt.Errorf("initialize alibaba eflo client: %s", err.Error())
 	}
@@ -177,6 +193,8 @@ func NewClientMgr() (*ClientMgr, error) {
 		EFLO:   eflocli,
 		Region: region,
 		stop:   make(<-chan struct{}, 1),
+
+		RateLimiter: limiter,
 	}
 	return auth, nil
 }
@@ -191,12 +209,16 @@ func (mgr *ClientMgr) Start(
 		token, err := tokenAuth.NextToken()
 		if err != nil {
 			log.Error(err, "fail to get next token")
+			return
 		}
 		err = settoken(mgr, token)
 		if err != nil {
 			log.Error(err, "fail to set token")
 			return
 		}
+		mgr.tokenLock.Lock()
+		mgr.tokenRefreshTime = time.Now()
+		mgr.tokenLock.Unlock()
 		initialized = true
 	}
 
@@ -220,8 +242,42 @@ func (mgr *ClientMgr) Start(
 	)
 }
 
+// CheckToken fails if the token has not been refreshed within TokenStalePeriod, e.g. the credentials
+// are revoked or the metadata server is unreachable.
+func (mgr *ClientMgr) CheckToken() error {
+	mgr.tokenLock.Lock()
+	defer mgr.tokenLock.Unlock()
+	if mgr.tokenRefreshTime.IsZero() {
+		return fmt.Errorf("token is not initialized")
+	}
+	if since := time.Since(mgr.tokenRefreshTime); since > TokenStalePeriod {
+		return fmt.Errorf("token is stale, last refreshed %s ago", since.Truncate(time.Second))
+	}
+	return nil
+}
+
+// ProbeCloud checks that the region can be read from the metadata and the cloud apis are accessible
+// with the token.
+func (mgr *ClientMgr) ProbeCloud() error {
+	region, err := mgr.Meta.Region()
+	if err != nil {
+		return fmt.Errorf("read region from metadata: %s", err.Error())
+	}
+	req := ecs.CreateDescribeRegionsRequest()
+	req.RegionId = region
+	if _, err := mgr.ECS.DescribeRegions(req); err != nil {
+		return fmt.Errorf("probe cloud api: %s", err.Error())
+	}
+	return nil
+}
+
 func (mgr *ClientMgr) GetTokenAuth() TokenAuth {
-	// priority: AddonToken > ServiceToken > AKMode > RamRoleToken
+	// priority: OIDCToken > AddonToken > ServiceToken > AKMode > RamRoleToken
+	if token := NewOIDCTokenFromEnv(mgr.Region, ctrlCfg.ControllerCFG.NetWork, mgr.RateLimiter); token != nil {
+		log.Info("use rrsa oidc mode to get token", "roleARN", token.RoleARN, "endpoint", token.Endpoint)
+		return token
+	}
+
 	if _, err := os.Stat(AddonTokenFilePath); err == nil {
 		log.Info("use addon token mode to get token")
 		return &AddonToken{Region: mgr.Region}
@@ -254,47 +310,47 @@ func RefreshToken(mgr *ClientMgr, token *DefaultToken) error {
 		AccessKeyStsToken: token.SecurityToken,
 	}
 
-	err := mgr.ECS.InitWithOptions(token.Region, clientCfg(), credential)
+	err := mgr.ECS.InitWithOptions(token.Region, clientCfg(mgr.RateLimiter, "ecs"), credential)
 	if err != nil {
 		return fmt.Errorf("init ecs sts token config: %s", err.Error())
 	}
 
-	err = mgr.VPC.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.VPC.InitWithOptions(token.Region, clientCfg(mgr.RateLimiter, "vpc"), credential)
 	if err != nil {
 		return fmt.Errorf("init vpc sts token config: %s", err.Error())
 	}
 
-	err = mgr.SLB.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.SLB.InitWithOptions(token.Region, clientCfg(mgr.RateLimiter, "slb"), credential)
 	if err != nil {
 		return fmt.Errorf("init slb sts token config: %s", err.Error())
 	}
 
-	err = mgr.ALB.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.ALB.InitWithOptions(token.Region, clientCfg(mgr.RateLimiter, "alb"), credential)
 	if err != nil {
 		return fmt.Errorf("init alb sts token config: %s", err.Error())
 	}
 
-	err = mgr.SLS.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.SLS.InitWithOptions(token.Region, clientCfg(mgr.RateLimiter, "sls"), credential)
 	if err != nil {
 		return fmt.Errorf("init sls sts token config: %s", err.Error())
 	}
 
-	err = mgr.CAS.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.CAS.InitWithOptions(token.Region, clientCfg(mgr.RateLimiter, "cas"), credential)
 	if err != nil {
 		return fmt.Errorf("init cas sts token config: %s", err.Error())
 	}
 
-	err = mgr.PVTZ.InitWithOptions(token.Region, clientCfg(), credential)
+	err = mgr.PVTZ.InitWithOptions(token.Region, clientCfg(mgr.RateLimiter, "pvtz"), credential)
 	if err != nil {
 		return fmt.Errorf("init pvtz sts token config: %s", err.Error())
 	}
 
-	err = mgr.NLB.Init(openapiCfg(token.Region, credential, ctrlCfg.ControllerCFG.NetWork))
+	err = mgr.NLB.Init(openapiCfg(token.Region, credential, ctrlCfg.ControllerCFG.NetWork, mgr.RateLimiter, "nlb"))
 	if err != nil {
 		return fmt.Errorf("init nlb sts token config: %s", err.Error())
 	}
 
-	err = mgr.EFLO.Init(openapiCfg(token.Region, credential, ctrlCfg.ControllerCFG.NetWork))
+	err = mgr.EFLO.Init(openapiCfg(token.Region, credential, ctrlCfg.ControllerCFG.NetWork, mgr.RateLimiter, "eflo"))
 	if err != nil {
 		return fmt.Errorf("init eflo controller sts token config: %s", err.Error())
 	}
@@ -358,19 +414,20 @@ func parseURL(str string) (string, error) {
 	return u.Host, nil
 }
 
-func clientCfg() *sdk.Config {
+func clientCfg(limiter *RateLimiter, product string) *sdk.Config {
 	scheme := "HTTPS"
 	if os.Getenv("ALICLOUD_CLIENT_SCHEME") == "HTTP" {
 		scheme = "HTTP"
 	}
 	return &sdk.Config{
 		Timeout:   20 * time.Second,
-		Transport: http.DefaultTransport,
+		Transport: instrumentTransport(product, limiter.Transport(product, http.DefaultTransport)),
 		Scheme:    scheme,
 	}
 }
 
-func openapiCfg(region string, credential *credentials.StsTokenCredential, network string) *openapi.Config {
+func openapiCfg(region string, credential *credentials.StsTokenCredential, network string,
+	limiter *RateLimiter, product string) *openapi.Config {
 	scheme := "HTTPS"
 	if os.Getenv("ALICLOUD_CLIENT_SCHEME") == "HTTP" {
 		scheme = "HTTP"
@@ -385,6 +442,7 @@ func openapiCfg(region string, credential *credentials.StsTokenCredential, netwo
 		AccessKeyId:     tea.String(credential.AccessKeyId),
 		AccessKeySecret: tea.String(credential.AccessKeySecret),
 		SecurityToken:   tea.String(credential.AccessKeyStsToken),
+		HttpClient:      instrumentHTTPClient(product, limiter.HTTPClient(product, 20*time.Second), 20*time.Second),
 	}
 }
 
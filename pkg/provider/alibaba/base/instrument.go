@@ -0,0 +1,129 @@
+package base
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alibabacloud-go/tea/dara"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
+)
+
+const (
+	// the error code of the calls failed without a response, e.g. timeout
+	ErrorCodeRequestFailed = "RequestFailed"
+
+	requestIDHeader = "x-acs-request-id"
+)
+
+// instrumentTransport wraps the transport of the clients of the legacy sdk, which carry the api name in
+// the Action query parameter.
+func instrumentTransport(product string, next http.RoundTripper) http.RoundTripper {
+	return &instrumentedTransport{product: product, next: next}
+}
+
+type instrumentedTransport struct {
+	product string
+	next    http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return roundTrip(req, t.product, req.URL.Query().Get("Action"), t.next.RoundTrip)
+}
+
+// instrumentHTTPClient wraps the http client of the clients of the darabonba sdk, which carry the api
+// name in the x-acs-action header. next is nil if the rate limit is disabled, a client without limiter
+// is used instead to keep the transport of the first call, as the sdk creates a transport for each call.
+func instrumentHTTPClient(product string, next dara.HttpClient, timeout time.Duration) dara.HttpClient {
+	if next == nil {
+		next = &limitedHTTPClient{product: product, client: &http.Client{Timeout: timeout}}
+	}
+	return &instrumentedHTTPClient{product: product, next: next}
+}
+
+type instrumentedHTTPClient struct {
+	product string
+	next    dara.HttpClient
+}
+
+func (c *instrumentedHTTPClient) Call(req *http.Request, transport *http.Transport) (*http.Response, error) {
+	return roundTrip(req, c.product, req.Header.Get("x-acs-action"), func(req *http.Request) (*http.Response, error) {
+		return c.next.Call(req, transport)
+	})
+}
+
+// roundTrip sends the request and records the metrics and the span of the call. The span is a child of
+// the span in the context or the headers of the request, and covers the time waiting for the rate limiter.
+func roundTrip(req *http.Request, product, api string,
+	do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx, span := tracing.Start(tracing.Extract(req.Context(), req.Header), product+"."+api,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("alibabacloud"),
+			semconv.RPCService(product),
+			semconv.RPCMethod(api),
+		))
+	defer span.End()
+
+	start := time.Now()
+	metric.OpenAPIRequests.WithLabelValues(product, api).Inc()
+	resp, err := do(req.WithContext(ctx))
+	metric.OpenAPILatency.WithLabelValues(product, api).Observe(metric.MsSince(start))
+	if err != nil {
+		metric.OpenAPIErrors.WithLabelValues(product, api, ErrorCodeRequestFailed).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	requestID, code := parseResponse(resp)
+	span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode), attribute.String("alibabacloud.request_id", requestID))
+	if code != "" {
+		metric.OpenAPIErrors.WithLabelValues(product, api, code).Inc()
+		span.SetAttributes(attribute.String("alibabacloud.error_code", code))
+		span.SetStatus(codes.Error, code)
+	}
+	return resp, err
+}
+
+// parseResponse returns the request id of the response, and the error code if the call failed. The
+// body of a failed response is restored for the sdk to parse the error.
+func parseResponse(resp *http.Response) (requestID, code string) {
+	requestID = resp.Header.Get(requestIDHeader)
+	if resp.StatusCode < http.StatusBadRequest {
+		return requestID, ""
+	}
+
+	code = strconv.Itoa(resp.StatusCode)
+	if resp.Body == nil {
+		return requestID, code
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return requestID, code
+	}
+
+	var e struct {
+		Code      string
+		RequestId string
+	}
+	if err := json.Unmarshal(body, &e); err == nil {
+		if e.Code != "" {
+			code = e.Code
+		}
+		if requestID == "" {
+			requestID = e.RequestId
+		}
+	}
+	return requestID, code
+}
@@ -0,0 +1,120 @@
+package base
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
+)
+
+type fakeTransport struct {
+	status int
+	body   string
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: f.status,
+		Header:     http.Header{"X-Acs-Request-Id": []string{"req-header"}},
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+	}, nil
+}
+
+func TestParseResponse(t *testing.T) {
+	body := `{"Code":"Throttling.User","Message":"Request was denied due to user flow control.","RequestId":"req-body"}`
+	resp := &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader(body))}
+	requestID, code := parseResponse(resp)
+	assert.Equal(t, "req-body", requestID)
+	assert.Equal(t, "Throttling.User", code)
+	// the body is restored for the sdk to parse the error
+	restored, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, body, string(restored))
+
+	resp = &http.Response{StatusCode: http.StatusBadGateway, Body: io.NopCloser(strings.NewReader("bad gateway"))}
+	_, code = parseResponse(resp)
+	assert.Equal(t, "502", code)
+
+	resp = &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Acs-Request-Id": []string{"req-ok"}},
+		Body: io.NopCloser(strings.NewReader(`{"RequestId":"req-ok"}`))}
+	requestID, code = parseResponse(resp)
+	assert.Equal(t, "req-ok", requestID)
+	assert.Equal(t, "", code)
+}
+
+func TestInstrumentedTransport(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	limiter, err := NewRateLimiter(ctrlCfg.RateLimitConfig{Default: ctrlCfg.RateLimit{QPS: 10, Burst: 10}})
+	assert.Equal(t, nil, err)
+	transport := instrumentTransport("slb", limiter.Transport("slb", &fakeTransport{
+		status: http.StatusBadRequest,
+		body:   `{"Code":"Throttling.User","RequestId":"req-body"}`,
+	}))
+
+	ctx, parent := tracing.Start(context.TODO(), "Reconcile Service")
+	// the legacy sdk copies the headers of the request to the http request
+	headers := map[string]string{}
+	tracing.Inject(ctx, headers)
+	req, _ := http.NewRequest(http.MethodPost, "https://slb.aliyuncs.com/?Action=DescribeLoadBalancers", nil)
+	for k, v := range headers {
+		req.Header[k] = []string{v}
+	}
+
+	resp, err := transport.RoundTrip(req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "slb.DescribeLoadBalancers", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("alibabacloud.request_id", "req-header"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("alibabacloud.error_code", "Throttling.User"))
+
+	// the throttled call slows down the bucket of the product
+	assert.Equal(t, 5.0, float64(limiter.getBuckets("slb", "DescribeLoadBalancers")[0].limiter.Limit()))
+}
+
+type fakeHTTPClient struct {
+	transport http.RoundTripper
+}
+
+func (f *fakeHTTPClient) Call(req *http.Request, _ *http.Transport) (*http.Response, error) {
+	return f.transport.RoundTrip(req)
+}
+
+func TestInstrumentedHTTPClient(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	client := instrumentHTTPClient("nlb", &fakeHTTPClient{transport: &fakeTransport{
+		status: http.StatusBadRequest,
+		body:   `{"Code":"Throttling.User","RequestId":"req-body"}`,
+	}}, time.Second)
+	req, _ := http.NewRequest(http.MethodPost, "https://nlb.aliyuncs.com/", nil)
+	req.Header.Set("x-acs-action", "ListLoadBalancers")
+	resp, err := client.Call(req, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "nlb.ListLoadBalancers", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("alibabacloud.error_code", "Throttling.User"))
+
+	// a client without limiter is used if the rate limit is disabled
+	client = instrumentHTTPClient("nlb", nil, time.Second)
+	assert.IsType(t, &limitedHTTPClient{}, client.(*instrumentedHTTPClient).next)
+}
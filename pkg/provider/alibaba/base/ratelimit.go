@@ -0,0 +1,390 @@
+package base
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alibabacloud-go/tea/dara"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/metric"
+)
+
+// Priority is the priority class of an openapi call. The calls of a lower priority yield to the
+// calls of higher priorities waiting for the same bucket.
+type Priority int
+
+const (
+	PriorityHigh Priority = iota
+	PriorityNormal
+	PriorityLow
+
+	numPriorities = 3
+)
+
+var priorityNames = []string{"high", "normal", "low"}
+
+func (p Priority) String() string {
+	return priorityNames[p]
+}
+
+func ParsePriority(s string) (Priority, error) {
+	for i, n := range priorityNames {
+		if strings.EqualFold(s, n) {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNormal, fmt.Errorf("unknown priority %q, expect one of %v", s, priorityNames)
+}
+
+// bulk backend syncs, which wait while the other calls of the product are waiting
+var lowPriorityAPIs = sets.NewString(
+	// clb
+	"AddBackendServers", "RemoveBackendServers", "SetBackendServers",
+	"AddVServerGroupBackendServers", "RemoveVServerGroupBackendServers", "ModifyVServerGroupBackendServers",
+	"SetVServerGroupAttribute",
+	// nlb and alb
+	"AddServersToServerGroup", "RemoveServersFromServerGroup", "ReplaceServersInServerGroup",
+	"UpdateServerGroupServersAttribute",
+)
+
+// health updates of the backends, which are used by the readiness gates of the pods
+var highPriorityAPIs = sets.NewString("DescribeHealthStatus", "GetListenerHealthStatus")
+
+// deletions go first, so that the removed resources are released as soon as possible
+var highPriorityPrefixes = []string{"Delete", "Release"}
+
+const (
+	// a throttled bucket is slowed down to no less than minRateFactor of its configured qps
+	minRateFactor = 0.1
+	// a successful call recovers recoverFactor of the configured qps of the bucket
+	recoverFactor = 0.02
+	// the rate of a bucket is halved at most once in decreaseInterval, the throttled responses
+	// of the calls sent before the decrease should not slow it down again
+	decreaseInterval = time.Second
+	// the interval for the waiters to check whether they can go
+	yieldInterval = 10 * time.Millisecond
+)
+
+// bucket is a token bucket which adapts its rate to the throttling errors: the rate is halved when
+// a call is throttled and recovers additively on successful calls, up to the configured qps.
+type bucket struct {
+	name    string
+	qps     float64
+	limiter *rate.Limiter
+
+	lock        sync.Mutex
+	waiting     [numPriorities]int
+	decreasedAt time.Time
+}
+
+func newBucket(name string, cfg ctrlCfg.RateLimit) *bucket {
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	b := &bucket{
+		name:    name,
+		qps:     cfg.QPS,
+		limiter: rate.NewLimiter(rate.Limit(cfg.QPS), burst),
+	}
+	metric.OpenAPIRateLimitQPS.WithLabelValues(name).Set(cfg.QPS)
+	return b
+}
+
+func (b *bucket) higherWaiting(p Priority) bool {
+	for i := PriorityHigh; i < p; i++ {
+		if b.waiting[i] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bucket) wait(ctx context.Context, p Priority) error {
+	b.lock.Lock()
+	b.waiting[p]++
+	b.lock.Unlock()
+	defer func() {
+		b.lock.Lock()
+		b.waiting[p]--
+		b.lock.Unlock()
+	}()
+
+	for {
+		// the token is not reserved ahead, so that the waiters of higher priorities coming later
+		// still take the tokens first
+		b.lock.Lock()
+		if !b.higherWaiting(p) && b.limiter.Allow() {
+			b.lock.Unlock()
+			return nil
+		}
+		b.lock.Unlock()
+		select {
+		case <-time.After(yieldInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *bucket) throttled() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if time.Since(b.decreasedAt) < decreaseInterval {
+		return
+	}
+	b.decreasedAt = time.Now()
+	limit := float64(b.limiter.Limit()) / 2
+	if limit < b.qps*minRateFactor {
+		limit = b.qps * minRateFactor
+	}
+	b.setLimit(limit)
+}
+
+func (b *bucket) succeeded() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	limit := float64(b.limiter.Limit())
+	if limit >= b.qps {
+		return
+	}
+	limit += b.qps * recoverFactor
+	if limit > b.qps {
+		limit = b.qps
+	}
+	b.setLimit(limit)
+}
+
+func (b *bucket) setLimit(limit float64) {
+	b.limiter.SetLimit(rate.Limit(limit))
+	metric.OpenAPIRateLimitQPS.WithLabelValues(b.name).Set(limit)
+}
+
+// RateLimiter is the client side rate limiter shared by all the openapi clients. Every call takes a
+// token from the bucket of its product, and from the bucket of its api if the api is configured.
+type RateLimiter struct {
+	cfg        ctrlCfg.RateLimitConfig
+	priorities map[string]Priority
+
+	lock        sync.Mutex
+	buckets     map[string]*bucket
+	httpClients map[string]*limitedHTTPClient
+}
+
+// NewRateLimiter returns a rate limiter with the buckets in cfg, a nil limiter is returned if the
+// rate limit is disabled. All the methods of a nil limiter are no-op.
+func NewRateLimiter(cfg ctrlCfg.RateLimitConfig) (*RateLimiter, error) {
+	if cfg.Disabled {
+		return nil, nil
+	}
+	if cfg.Default.QPS <= 0 {
+		return nil, fmt.Errorf("rate limit qps of the default bucket must be positive")
+	}
+	for k, v := range cfg.Products {
+		if v.QPS <= 0 {
+			return nil, fmt.Errorf("rate limit qps of product %s must be positive", k)
+		}
+	}
+	for k, v := range cfg.APIs {
+		if len(strings.Split(k, "/")) != 2 {
+			return nil, fmt.Errorf("rate limit api %s should be in format product/Action", k)
+		}
+		if v.QPS <= 0 {
+			return nil, fmt.Errorf("rate limit qps of api %s must be positive", k)
+		}
+	}
+	priorities := make(map[string]Priority, len(cfg.Priorities))
+	for k, v := range cfg.Priorities {
+		p, err := ParsePriority(v)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit priority of api %s: %s", k, err.Error())
+		}
+		priorities[k] = p
+	}
+	return &RateLimiter{
+		cfg:         cfg,
+		priorities:  priorities,
+		buckets:     make(map[string]*bucket),
+		httpClients: make(map[string]*limitedHTTPClient),
+	}, nil
+}
+
+// Priority returns the priority class of the api of the product
+func (l *RateLimiter) Priority(product, api string) Priority {
+	if l != nil {
+		if p, ok := l.priorities[product+"/"+api]; ok {
+			return p
+		}
+	}
+	if lowPriorityAPIs.Has(api) {
+		return PriorityLow
+	}
+	if highPriorityAPIs.Has(api) {
+		return PriorityHigh
+	}
+	for _, prefix := range highPriorityPrefixes {
+		if strings.HasPrefix(api, prefix) {
+			return PriorityHigh
+		}
+	}
+	return PriorityNormal
+}
+
+func (l *RateLimiter) getBuckets(product, api string) []*bucket {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var buckets []*bucket
+	key := product + "/" + api
+	if cfg, ok := l.cfg.APIs[key]; ok {
+		b, ok := l.buckets[key]
+		if !ok {
+			b = newBucket(key, cfg)
+			l.buckets[key] = b
+		}
+		buckets = append(buckets, b)
+	}
+
+	b, ok := l.buckets[product]
+	if !ok {
+		cfg, ok := l.cfg.Products[product]
+		if !ok {
+			cfg = l.cfg.Default
+		}
+		b = newBucket(product, cfg)
+		l.buckets[product] = b
+	}
+	return append(buckets, b)
+}
+
+// Wait blocks until the api of the product is allowed to be called or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, product, api string) error {
+	if l == nil {
+		return nil
+	}
+	p := l.Priority(product, api)
+	start := time.Now()
+	defer func() {
+		metric.OpenAPIRateLimitWait.WithLabelValues(product, p.String()).Observe(metric.MsSince(start))
+	}()
+	for _, b := range l.getBuckets(product, api) {
+		if err := b.wait(ctx, p); err != nil {
+			return fmt.Errorf("wait rate limiter of %s/%s: %s", product, api, err.Error())
+		}
+	}
+	return nil
+}
+
+// Observe adapts the rate of the buckets of the api to the result of a call
+func (l *RateLimiter) Observe(product, api string, throttled bool) {
+	if throttled {
+		metric.OpenAPIThrottled.WithLabelValues(product, api).Inc()
+	}
+	if l == nil {
+		return
+	}
+	for _, b := range l.getBuckets(product, api) {
+		if throttled {
+			b.throttled()
+		} else {
+			b.succeeded()
+		}
+	}
+}
+
+// Transport wraps the transport of the clients of the legacy sdk, which carry the api name in the
+// Action query parameter.
+func (l *RateLimiter) Transport(product string, next http.RoundTripper) http.RoundTripper {
+	if l == nil {
+		return next
+	}
+	return &limitedTransport{product: product, limiter: l, next: next}
+}
+
+// HTTPClient returns the http client for the clients of the darabonba sdk, which carry the api name
+// in the x-acs-action header.
+func (l *RateLimiter) HTTPClient(product string, timeout time.Duration) dara.HttpClient {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	// the http client is reused when the token is refreshed to keep the idle connections
+	c, ok := l.httpClients[product]
+	if !ok {
+		c = &limitedHTTPClient{product: product, limiter: l, client: &http.Client{Timeout: timeout}}
+		l.httpClients[product] = c
+	}
+	return c
+}
+
+type limitedTransport struct {
+	product string
+	limiter *RateLimiter
+	next    http.RoundTripper
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	api := req.URL.Query().Get("Action")
+	if err := t.limiter.Wait(req.Context(), t.product, api); err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.limiter.Observe(t.product, api, isThrottledResponse(resp))
+	}
+	return resp, err
+}
+
+type limitedHTTPClient struct {
+	product string
+	limiter *RateLimiter
+
+	lock   sync.Mutex
+	client *http.Client
+}
+
+func (c *limitedHTTPClient) Call(req *http.Request, transport *http.Transport) (*http.Response, error) {
+	api := req.Header.Get("x-acs-action")
+	if err := c.limiter.Wait(req.Context(), c.product, api); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	if c.client.Transport == nil {
+		c.client.Transport = transport
+	}
+	client := c.client
+	c.lock.Unlock()
+
+	resp, err := client.Do(req)
+	if err == nil {
+		c.limiter.Observe(c.product, api, isThrottledResponse(resp))
+	}
+	return resp, err
+}
+
+// isThrottledResponse checks the error code in the body of a failed response, the body is restored
+// for the sdk to parse the error.
+func isThrottledResponse(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode < http.StatusBadRequest || resp.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(body, []byte("Throttling"))
+}
@@ -0,0 +1,138 @@
+package base
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	l, err := NewRateLimiter(ctrlCfg.RateLimitConfig{Disabled: true})
+	assert.Equal(t, nil, err)
+	assert.Nil(t, l)
+	assert.Equal(t, nil, l.Wait(context.TODO(), "slb", "DescribeLoadBalancers"))
+
+	_, err = NewRateLimiter(ctrlCfg.RateLimitConfig{
+		Default: ctrlCfg.RateLimit{QPS: 10},
+		APIs:    map[string]ctrlCfg.RateLimit{"DescribeLoadBalancers": {QPS: 1}},
+	})
+	assert.Error(t, err)
+
+	_, err = NewRateLimiter(ctrlCfg.RateLimitConfig{
+		Default:    ctrlCfg.RateLimit{QPS: 10},
+		Priorities: map[string]string{"slb/DescribeLoadBalancers": "urgent"},
+	})
+	assert.Error(t, err)
+}
+
+func TestRateLimiterPriority(t *testing.T) {
+	l, err := NewRateLimiter(ctrlCfg.RateLimitConfig{
+		Default:    ctrlCfg.RateLimit{QPS: 10},
+		Priorities: map[string]string{"nlb/ListServerGroupServers": "low"},
+	})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, PriorityHigh, l.Priority("slb", "DeleteLoadBalancer"))
+	assert.Equal(t, PriorityHigh, l.Priority("nlb", "GetListenerHealthStatus"))
+	assert.Equal(t, PriorityLow, l.Priority("slb", "RemoveVServerGroupBackendServers"))
+	assert.Equal(t, PriorityLow, l.Priority("nlb", "AddServersToServerGroup"))
+	assert.Equal(t, PriorityNormal, l.Priority("slb", "DescribeLoadBalancers"))
+	assert.Equal(t, PriorityLow, l.Priority("nlb", "ListServerGroupServers"))
+}
+
+func TestRateLimiterBuckets(t *testing.T) {
+	l, err := NewRateLimiter(ctrlCfg.RateLimitConfig{
+		Default:  ctrlCfg.RateLimit{QPS: 10, Burst: 10},
+		Products: map[string]ctrlCfg.RateLimit{"slb": {QPS: 20, Burst: 20}},
+		APIs:     map[string]ctrlCfg.RateLimit{"slb/DescribeLoadBalancers": {QPS: 1, Burst: 1}},
+	})
+	assert.Equal(t, nil, err)
+
+	buckets := l.getBuckets("slb", "DescribeLoadBalancers")
+	assert.Len(t, buckets, 2)
+	assert.Equal(t, 1.0, buckets[0].qps)
+	assert.Equal(t, 20.0, buckets[1].qps)
+
+	buckets = l.getBuckets("ecs", "DescribeInstances")
+	assert.Len(t, buckets, 1)
+	assert.Equal(t, 10.0, buckets[0].qps)
+}
+
+func TestBucketAdaptive(t *testing.T) {
+	b := newBucket("test", ctrlCfg.RateLimit{QPS: 100, Burst: 1})
+
+	b.throttled()
+	assert.Equal(t, 50.0, float64(b.limiter.Limit()))
+	// throttled responses of the calls sent before the decrease are ignored
+	b.throttled()
+	assert.Equal(t, 50.0, float64(b.limiter.Limit()))
+
+	b.succeeded()
+	assert.Equal(t, 52.0, float64(b.limiter.Limit()))
+
+	for i := 0; i < 10; i++ {
+		b.decreasedAt = time.Time{}
+		b.throttled()
+	}
+	assert.Equal(t, 10.0, float64(b.limiter.Limit()))
+
+	for i := 0; i < 100; i++ {
+		b.succeeded()
+	}
+	assert.Equal(t, 100.0, float64(b.limiter.Limit()))
+}
+
+func TestBucketPriority(t *testing.T) {
+	b := newBucket("test", ctrlCfg.RateLimit{QPS: 20, Burst: 1})
+	// drain the burst
+	assert.Equal(t, nil, b.wait(context.TODO(), PriorityNormal))
+
+	var lock sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	run := func(p Priority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, nil, b.wait(context.TODO(), p))
+			lock.Lock()
+			order = append(order, p)
+			lock.Unlock()
+		}()
+	}
+
+	run(PriorityLow)
+	time.Sleep(time.Millisecond)
+	run(PriorityHigh)
+	run(PriorityHigh)
+	wg.Wait()
+	// the low priority waiter was the first to come, but it goes after the high ones
+	assert.Equal(t, []Priority{PriorityHigh, PriorityHigh, PriorityLow}, order)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	assert.Error(t, b.wait(ctx, PriorityLow))
+}
+
+func TestIsThrottledResponse(t *testing.T) {
+	body := `{"Code":"Throttling.User","Message":"Request was denied due to user flow control."}`
+	resp := &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader(body))}
+	assert.True(t, isThrottledResponse(resp))
+	// the body is restored for the sdk to parse the error
+	restored, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, body, string(restored))
+
+	resp = &http.Response{StatusCode: http.StatusBadRequest,
+		Body: io.NopCloser(strings.NewReader(`{"Code":"InvalidParameter"}`))}
+	assert.False(t, isThrottledResponse(resp))
+
+	resp = &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}
+	assert.False(t, isThrottledResponse(resp))
+}
@@ -6,17 +6,38 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"github.com/go-cmd/cmd"
-	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
-	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/go-cmd/cmd"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 )
 
 const (
 	AddonTokenFilePath = "/var/addon/token-config"
+
+	// the environment variables of RRSA (RAM Roles for Service Accounts), which are injected by the
+	// ack-pod-identity-webhook together with the projected service account token
+	RoleARNEnv         = "ALIBABA_CLOUD_ROLE_ARN"
+	OIDCProviderARNEnv = "ALIBABA_CLOUD_OIDC_PROVIDER_ARN"
+	OIDCTokenFileEnv   = "ALIBABA_CLOUD_OIDC_TOKEN_FILE"
+	RoleSessionNameEnv = "ALIBABA_CLOUD_ROLE_SESSION_NAME"
+	// STSEndpointEnv overrides the endpoint of sts, e.g. http://127.0.0.1:8080 for a local stand-in
+	STSEndpointEnv = "STS_ENDPOINT"
+
+	DefaultRoleSessionName = "ack-ccm"
+	OIDCTokenDuration      = time.Hour
+	// OIDCTokenRefreshBefore is how long before the expiration the token is refreshed, which is
+	// longer than the TokenSyncPeriod to refresh the token before it expires
+	OIDCTokenRefreshBefore = 2 * TokenSyncPeriod
 )
 
 type DefaultToken struct {
@@ -169,6 +190,165 @@ func (f *AddonToken) NextToken() (*DefaultToken, error) {
 	}, nil
 }
 
+// OIDCToken is an implementation of RRSA auth, which assumes the ram role with the projected
+// service account token by AssumeRoleWithOIDC
+type OIDCToken struct {
+	Region          string
+	RoleARN         string
+	OIDCProviderARN string
+	OIDCTokenFile   string
+	RoleSessionName string
+	// Endpoint is the url of sts
+	Endpoint string
+	Client   *http.Client
+
+	lock       sync.Mutex
+	token      *DefaultToken
+	expiration time.Time
+}
+
+// NewOIDCTokenFromEnv returns the OIDCToken configured by the RRSA environment variables, nil is
+// returned if RRSA is not enabled.
+func NewOIDCTokenFromEnv(region, network string, limiter *RateLimiter) *OIDCToken {
+	roleARN, providerARN, tokenFile := os.Getenv(RoleARNEnv), os.Getenv(OIDCProviderARNEnv), os.Getenv(OIDCTokenFileEnv)
+	if roleARN == "" || providerARN == "" || tokenFile == "" {
+		return nil
+	}
+	sessionName := os.Getenv(RoleSessionNameEnv)
+	if sessionName == "" {
+		sessionName = DefaultRoleSessionName
+	}
+	return &OIDCToken{
+		Region:          region,
+		RoleARN:         roleARN,
+		OIDCProviderARN: providerARN,
+		OIDCTokenFile:   tokenFile,
+		RoleSessionName: sessionName,
+		Endpoint:        stsEndpoint(region, network),
+		Client: &http.Client{
+			Timeout:   20 * time.Second,
+			Transport: instrumentTransport("sts", limiter.Transport("sts", http.DefaultTransport)),
+		},
+	}
+}
+
+// stsEndpoint returns the url of sts, the scheme of an endpoint overridden without one follows
+// ALICLOUD_CLIENT_SCHEME.
+func stsEndpoint(region, network string) string {
+	scheme := "https"
+	if os.Getenv("ALICLOUD_CLIENT_SCHEME") == "HTTP" {
+		scheme = "http"
+	}
+	if ep := os.Getenv(STSEndpointEnv); ep != "" {
+		if strings.Contains(ep, "://") {
+			return ep
+		}
+		return scheme + "://" + ep
+	}
+	if network == "vpc" {
+		return fmt.Sprintf("%s://sts-vpc.%s.aliyuncs.com", scheme, region)
+	}
+	return fmt.Sprintf("%s://sts.%s.aliyuncs.com", scheme, region)
+}
+
+// NextToken returns the cached token, which is refreshed if it expires within OIDCTokenRefreshBefore.
+func (f *OIDCToken) NextToken() (*DefaultToken, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.token != nil && time.Until(f.expiration) > OIDCTokenRefreshBefore {
+		token := *f.token
+		return &token, nil
+	}
+	token, expiration, err := f.assumeRoleWithOIDC()
+	if err != nil {
+		if f.token != nil && time.Now().Before(f.expiration) {
+			// keep using the token until it expires
+			log.Error(err, "fail to refresh oidc token, use the cached one", "expiration", f.expiration)
+			token := *f.token
+			return &token, nil
+		}
+		return nil, err
+	}
+	f.token, f.expiration = token, expiration
+	log.Info("refreshed oidc token", "roleARN", f.RoleARN, "expiration", expiration)
+	tokenCopy := *token
+	return &tokenCopy, nil
+}
+
+func (f *OIDCToken) assumeRoleWithOIDC() (*DefaultToken, time.Time, error) {
+	oidcToken, err := os.ReadFile(f.OIDCTokenFile)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("read oidc token file %s error: %s", f.OIDCTokenFile, err.Error())
+	}
+
+	query := url.Values{}
+	query.Set("Action", "AssumeRoleWithOIDC")
+	query.Set("Version", "2015-04-01")
+	query.Set("Format", "JSON")
+	query.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	form := url.Values{}
+	form.Set("RoleArn", f.RoleARN)
+	form.Set("OIDCProviderArn", f.OIDCProviderARN)
+	form.Set("OIDCToken", strings.TrimSpace(string(oidcToken)))
+	form.Set("RoleSessionName", f.RoleSessionName)
+	form.Set("DurationSeconds", strconv.Itoa(int(OIDCTokenDuration.Seconds())))
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(f.Endpoint, "/")+"/?"+query.Encode(),
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("assume role with oidc: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("assume role with oidc: read response: %s", err.Error())
+	}
+
+	result := struct {
+		RequestId   string
+		Code        string
+		Message     string
+		Credentials struct {
+			AccessKeyId     string
+			AccessKeySecret string
+			SecurityToken   string
+			Expiration      string
+		}
+	}{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, time.Time{}, fmt.Errorf("assume role with oidc: unmarshal response [%s] error: %s", string(body), err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Time{}, fmt.Errorf("assume role with oidc: status %d, code %s, message %s, requestID %s",
+			resp.StatusCode, result.Code, result.Message, result.RequestId)
+	}
+	creds := result.Credentials
+	if creds.AccessKeyId == "" || creds.AccessKeySecret == "" || creds.SecurityToken == "" {
+		return nil, time.Time{}, fmt.Errorf("assume role with oidc: empty credentials, requestID %s", result.RequestId)
+	}
+	expiration, err := time.Parse(time.RFC3339, creds.Expiration)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("assume role with oidc: parse expiration %s error: %s", creds.Expiration, err.Error())
+	}
+
+	return &DefaultToken{
+		Region:          f.Region,
+		AccessKeyId:     creds.AccessKeyId,
+		AccessKeySecret: creds.AccessKeySecret,
+		SecurityToken:   creds.SecurityToken,
+	}, expiration, nil
+}
+
 func LoadAK() (string, string, error) {
 	var keyId, keySecret string
 	log.V(5).Info(fmt.Sprintf("load cfg from file: %s", ctrlCfg.ControllerCFG.CloudConfigPath))
@@ -0,0 +1,136 @@
+package base
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSTS struct {
+	calls      int
+	status     int
+	expiration time.Time
+	forms      []map[string]string
+}
+
+func (s *fakeSTS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.calls++
+	_ = r.ParseForm()
+	s.forms = append(s.forms, map[string]string{
+		"Action":          r.URL.Query().Get("Action"),
+		"RoleArn":         r.PostForm.Get("RoleArn"),
+		"OIDCProviderArn": r.PostForm.Get("OIDCProviderArn"),
+		"OIDCToken":       r.PostForm.Get("OIDCToken"),
+		"RoleSessionName": r.PostForm.Get("RoleSessionName"),
+	})
+	if s.status != http.StatusOK {
+		w.WriteHeader(s.status)
+		_, _ = fmt.Fprint(w, `{"RequestId":"req-1","Code":"AuthenticationFail.OIDCToken.Expired","Message":"expired"}`)
+		return
+	}
+	_, _ = fmt.Fprintf(w, `{"RequestId":"req-1","Credentials":{"AccessKeyId":"STS.key-%d","AccessKeySecret":"secret","SecurityToken":"token","Expiration":"%s"}}`,
+		s.calls, s.expiration.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+func newOIDCTokenForTest(t *testing.T, sts *fakeSTS) *OIDCToken {
+	server := httptest.NewServer(sts)
+	t.Cleanup(server.Close)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.Equal(t, nil, os.WriteFile(tokenFile, []byte("oidc-token\n"), 0600))
+
+	t.Setenv(RoleARNEnv, "acs:ram::123:role/ccm")
+	t.Setenv(OIDCProviderARNEnv, "acs:ram::123:oidc-provider/ack-rrsa-c1")
+	t.Setenv(OIDCTokenFileEnv, tokenFile)
+	t.Setenv(STSEndpointEnv, server.URL)
+	token := NewOIDCTokenFromEnv("cn-hangzhou", "vpc", nil)
+	assert.NotNil(t, token)
+	return token
+}
+
+func TestOIDCToken(t *testing.T) {
+	sts := &fakeSTS{status: http.StatusOK, expiration: time.Now().Add(time.Hour)}
+	token := newOIDCTokenForTest(t, sts)
+
+	got, err := token.NextToken()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "cn-hangzhou", got.Region)
+	assert.Equal(t, "STS.key-1", got.AccessKeyId)
+	assert.Equal(t, "token", got.SecurityToken)
+	assert.Equal(t, map[string]string{
+		"Action":          "AssumeRoleWithOIDC",
+		"RoleArn":         "acs:ram::123:role/ccm",
+		"OIDCProviderArn": "acs:ram::123:oidc-provider/ack-rrsa-c1",
+		"OIDCToken":       "oidc-token",
+		"RoleSessionName": DefaultRoleSessionName,
+	}, sts.forms[0])
+
+	// the cached token is used until it is about to expire
+	got, err = token.NextToken()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "STS.key-1", got.AccessKeyId)
+	assert.Equal(t, 1, sts.calls)
+
+	token.expiration = time.Now().Add(OIDCTokenRefreshBefore - time.Minute)
+	got, err = token.NextToken()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "STS.key-2", got.AccessKeyId)
+	assert.Equal(t, 2, sts.calls)
+}
+
+func TestOIDCTokenRefreshError(t *testing.T) {
+	sts := &fakeSTS{status: http.StatusBadRequest}
+	token := newOIDCTokenForTest(t, sts)
+
+	_, err := token.NextToken()
+	assert.ErrorContains(t, err, "AuthenticationFail.OIDCToken.Expired")
+
+	// the cached token is still used if it does not expire yet
+	token.token = &DefaultToken{AccessKeyId: "STS.cached"}
+	token.expiration = time.Now().Add(time.Minute)
+	got, err := token.NextToken()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "STS.cached", got.AccessKeyId)
+
+	token.expiration = time.Now().Add(-time.Minute)
+	_, err = token.NextToken()
+	assert.NotEqual(t, nil, err)
+}
+
+func TestGetTokenAuthOIDC(t *testing.T) {
+	token := newOIDCTokenForTest(t, &fakeSTS{status: http.StatusOK})
+	mgr := &ClientMgr{Region: "cn-hangzhou"}
+	auth, ok := mgr.GetTokenAuth().(*OIDCToken)
+	assert.True(t, ok)
+	assert.Equal(t, token.Endpoint, auth.Endpoint)
+
+	t.Setenv(OIDCTokenFileEnv, "")
+	_, ok = mgr.GetTokenAuth().(*OIDCToken)
+	assert.False(t, ok)
+}
+
+func TestSTSEndpoint(t *testing.T) {
+	t.Setenv(STSEndpointEnv, "")
+	assert.Equal(t, "https://sts-vpc.cn-beijing.aliyuncs.com", stsEndpoint("cn-beijing", "vpc"))
+	assert.Equal(t, "https://sts.cn-beijing.aliyuncs.com", stsEndpoint("cn-beijing", "public"))
+
+	t.Setenv(STSEndpointEnv, "127.0.0.1:8080")
+	t.Setenv("ALICLOUD_CLIENT_SCHEME", "HTTP")
+	assert.Equal(t, "http://127.0.0.1:8080", stsEndpoint("cn-beijing", "vpc"))
+}
+
+func TestCheckToken(t *testing.T) {
+	mgr := &ClientMgr{}
+	assert.ErrorContains(t, mgr.CheckToken(), "not initialized")
+
+	mgr.tokenRefreshTime = time.Now().Add(-TokenSyncPeriod)
+	assert.Equal(t, nil, mgr.CheckToken())
+
+	mgr.tokenRefreshTime = time.Now().Add(-TokenStalePeriod - time.Minute)
+	assert.ErrorContains(t, mgr.CheckToken(), "stale")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/pkg/errors"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -12,6 +13,7 @@ import (
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/responses"
@@ -52,11 +54,17 @@ const (
 	certsCacheKey                         = "CertificateInfo"
 	DescribeSSLCertificateList            = "DescribeSSLCertificateList"
 	DescribeSSLCertificatePublicKeyDetail = "DescribeSSLCertificatePublicKeyDetail"
-	DefaultSSLCertificatePollInterval     = 30 * time.Second
-	DefaultSSLCertificateTimeout          = 60 * time.Second
+	UploadUserCertificate                 = "UploadUserCertificate"
+	DeleteUserCertificate                 = "DeleteUserCertificate"
+	ListTagResources                      = "ListTagResources"
+	// uploadCertificateResourceType is the tag resource type of the uploaded certificates
+	uploadCertificateResourceType     = "UPLOAD"
+	DefaultSSLCertificatePollInterval = 30 * time.Second
+	DefaultSSLCertificateTimeout      = 60 * time.Second
 )
 
-func (c CASProvider) casDoAction(request requests.AcsRequest, response responses.AcsResponse) (err error) {
+func (c CASProvider) casDoAction(ctx context.Context, request requests.AcsRequest, response responses.AcsResponse) (err error) {
+	tracing.Inject(ctx, request.GetHeaders())
 	return c.auth.CAS.Client.DoAction(request, response)
 }
 
@@ -84,7 +92,7 @@ func (c CASProvider) DescribeSSLCertificatePublicKeyDetail(ctx context.Context,
 			"traceID", traceID,
 			"startTime", startTime,
 			"action", DescribeSSLCertificatePublicKeyDetail)
-		retErr = c.casDoAction(rpcRequest, response)
+		retErr = c.casDoAction(ctx, rpcRequest, response)
 		if retErr != nil {
 			return retErr
 		}
@@ -144,7 +152,7 @@ func (c CASProvider) DescribeSSLCertificateList(ctx context.Context) ([]model.Ce
 			"traceID", traceID,
 			"startTime", startTime,
 			"action", DescribeSSLCertificateList)
-		err := c.casDoAction(rpcRequest, response)
+		err := c.casDoAction(ctx, rpcRequest, response)
 		if err != nil {
 			c.logger.Error(err, "DescribeUserCertificateList error")
 			return nil, err
@@ -177,3 +185,150 @@ func (c CASProvider) DescribeSSLCertificateList(ctx context.Context) ([]model.Ce
 	c.certsCache.Set(certsCacheKey, certificateInfos, c.certsCacheTTL)
 	return certificateInfos, nil
 }
+
+func (c CASProvider) UploadUserCertificate(ctx context.Context, name, cert, key string, tags map[string]string) (int64, error) {
+	traceID := ctx.Value(util.TraceID)
+
+	rpcRequest := &requests.RpcRequest{}
+	rpcRequest.InitWithApiInfo("cas", "2020-04-07", UploadUserCertificate, "cas", "openAPI")
+	rpcRequest.Method = requests.POST
+	rpcRequest.Domain = CASDomain
+	rpcRequest.QueryParams = map[string]string{
+		"Name": name,
+	}
+	rpcRequest.FormParams = map[string]string{
+		"Cert": cert,
+		"Key":  key,
+	}
+	tagKeys := make([]string, 0, len(tags))
+	for k := range tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	for i, k := range tagKeys {
+		rpcRequest.QueryParams["Tags."+strconv.Itoa(i+1)+".Key"] = k
+		rpcRequest.QueryParams["Tags."+strconv.Itoa(i+1)+".Value"] = tags[k]
+	}
+
+	response := responses.NewCommonResponse()
+
+	startTime := time.Now()
+	c.logger.Info("uploading user certificate",
+		"traceID", traceID,
+		"certName", name,
+		"startTime", startTime,
+		"action", UploadUserCertificate)
+	if err := c.casDoAction(ctx, rpcRequest, response); err != nil {
+		c.logger.Error(err, "UploadUserCertificate error", "certName", name)
+		return 0, errors.Wrap(err, "failed to uploadUserCertificate")
+	}
+
+	resp := struct {
+		CertId int64 `json:"CertId"`
+	}{}
+	if err := json.Unmarshal(response.GetHttpContentBytes(), &resp); err != nil {
+		return 0, err
+	}
+	c.logger.Info("uploaded user certificate",
+		"traceID", traceID,
+		"certName", name,
+		"certID", resp.CertId,
+		"elapsedTime", time.Since(startTime).Milliseconds(),
+		"action", UploadUserCertificate)
+
+	// the certificate list is cached, drop it so the new certificate can be found immediately
+	c.certsCache.Delete(certsCacheKey)
+	return resp.CertId, nil
+}
+
+func (c CASProvider) DeleteUserCertificate(ctx context.Context, certId int64) error {
+	traceID := ctx.Value(util.TraceID)
+
+	rpcRequest := &requests.RpcRequest{}
+	rpcRequest.InitWithApiInfo("cas", "2020-04-07", DeleteUserCertificate, "cas", "openAPI")
+	rpcRequest.Method = requests.POST
+	rpcRequest.Domain = CASDomain
+	rpcRequest.QueryParams = map[string]string{
+		"CertId": strconv.FormatInt(certId, 10),
+	}
+
+	response := responses.NewCommonResponse()
+
+	startTime := time.Now()
+	c.logger.Info("deleting user certificate",
+		"traceID", traceID,
+		"certID", certId,
+		"startTime", startTime,
+		"action", DeleteUserCertificate)
+	if err := c.casDoAction(ctx, rpcRequest, response); err != nil {
+		c.logger.Error(err, "DeleteUserCertificate error", "certID", certId)
+		return errors.Wrap(err, "failed to deleteUserCertificate")
+	}
+	c.logger.Info("deleted user certificate",
+		"traceID", traceID,
+		"certID", certId,
+		"elapsedTime", time.Since(startTime).Milliseconds(),
+		"action", DeleteUserCertificate)
+
+	c.certsCache.Delete(certsCacheKey)
+	return nil
+}
+
+func (c CASProvider) ListUserCertificateTags(ctx context.Context, certId int64) (map[string]string, error) {
+	traceID := ctx.Value(util.TraceID)
+
+	rpcRequest := &requests.RpcRequest{}
+	rpcRequest.InitWithApiInfo("cas", "2020-04-07", ListTagResources, "cas", "openAPI")
+	rpcRequest.Method = requests.POST
+	rpcRequest.Domain = CASDomain
+
+	response := responses.NewCommonResponse()
+
+	tags := make(map[string]string)
+	nextToken := ""
+	for {
+		rpcRequest.QueryParams = map[string]string{
+			"ResourceType": uploadCertificateResourceType,
+			"ResourceId.1": strconv.FormatInt(certId, 10),
+			"RegionId":     c.auth.Region,
+		}
+		if nextToken != "" {
+			rpcRequest.QueryParams["NextToken"] = nextToken
+		}
+
+		startTime := time.Now()
+		c.logger.Info("listing user certificate tags",
+			"traceID", traceID,
+			"certID", certId,
+			"startTime", startTime,
+			"action", ListTagResources)
+		if err := c.casDoAction(ctx, rpcRequest, response); err != nil {
+			c.logger.Error(err, "ListTagResources error", "certID", certId)
+			return nil, errors.Wrap(err, "failed to listTagResources")
+		}
+		c.logger.Info("listed user certificate tags",
+			"traceID", traceID,
+			"certID", certId,
+			"elapsedTime", time.Since(startTime).Milliseconds(),
+			"action", ListTagResources)
+
+		resp := struct {
+			NextToken    string `json:"NextToken"`
+			TagResources []struct {
+				TagKey   string `json:"TagKey"`
+				TagValue string `json:"TagValue"`
+			} `json:"TagResources"`
+		}{}
+		if err := json.Unmarshal(response.GetHttpContentBytes(), &resp); err != nil {
+			return nil, err
+		}
+		for _, t := range resp.TagResources {
+			tags[t.TagKey] = t.TagValue
+		}
+		if resp.NextToken == "" {
+			break
+		}
+		nextToken = resp.NextToken
+	}
+	return tags, nil
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 
 	"strings"
+	"time"
 
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	ecsmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/ecs"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	"k8s.io/klog/v2"
 
 	v1 "k8s.io/api/core/v1"
@@ -68,6 +70,7 @@ func (e *ECSProvider) AuthorizeSecurityGroup(ctx context.Context, sgId string, p
 	}
 	req.Permissions = &ecstPerms
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := e.auth.ECS.AuthorizeSecurityGroup(req)
 	if err != nil {
 		klog.Errorf("calling AuthorizeSecurityGroup: securityGroupId=%s, message=[%s].", req.SecurityGroupId, err.Error())
@@ -81,6 +84,7 @@ func (e *ECSProvider) DescribeSecurityGroupAttribute(ctx context.Context, sgId s
 	req := ecs.CreateDescribeSecurityGroupAttributeRequest()
 	req.SecurityGroupId = sgId
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := e.auth.ECS.DescribeSecurityGroupAttribute(req)
 	if err != nil {
 		klog.Errorf("calling DescribeSecurityGroupAttribute: securityGroupId=%s, message=[%s].", sgId, err.Error())
@@ -131,6 +135,7 @@ func (e *ECSProvider) DeleteSecurityGroup(ctx context.Context, sgId string) erro
 	req := ecs.CreateDeleteSecurityGroupRequest()
 	req.SecurityGroupId = sgId
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := e.auth.ECS.DeleteSecurityGroup(req)
 	if err != nil {
 		klog.Errorf("calling DeleteSecurityGroup: securityGroupId=%s, message=[%s].", sgId, err.Error())
@@ -156,6 +161,7 @@ func (e *ECSProvider) DescribeSecurityGroups(ctx context.Context, tags []tag.Tag
 
 	var result []ecsmodel.SecurityGroup
 	for {
+		tracing.Inject(ctx, req.GetHeaders())
 		resp, err := e.auth.ECS.DescribeSecurityGroups(req)
 		if err != nil {
 			klog.Errorf("calling DescribeSecurityGroups: message=[%s].", err.Error())
@@ -211,6 +217,7 @@ func (e *ECSProvider) CreateSecurityGroup(ctx context.Context, sg ecsmodel.Secur
 		req.Tag = &ecstags
 	}
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := e.auth.ECS.CreateSecurityGroup(req)
 	if err != nil {
 		klog.Errorf("calling CreateSecurityGroup: securityGroupName=%s, message=[%s].", sg.Name, err.Error())
@@ -249,6 +256,7 @@ func (e *ECSProvider) RevokeSecurityGroup(ctx context.Context, sgId string, perm
 	}
 	req.Permissions = &ecstPerms
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := e.auth.ECS.RevokeSecurityGroup(req)
 	if err != nil {
 		klog.Errorf("calling RevokeSecurityGroup: securityGroupId=%s, message=[%s].", req.SecurityGroupId, err.Error())
@@ -264,6 +272,7 @@ func (e *ECSProvider) ModifySecurityGroupAttribute(ctx context.Context, sgId str
 	req.SecurityGroupName = sg.Name
 	req.Description = sg.Description
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := e.auth.ECS.ModifySecurityGroupAttribute(req)
 	if err != nil {
 		klog.Errorf("calling ModifySecurityGroupAttribute: securityGroupId=%s, message=[%s].", sgId, err.Error())
@@ -292,6 +301,7 @@ func (e *ECSProvider) ModifySecurityGroupRule(ctx context.Context, sgId string,
 	req.NicType = permission.NicType
 	req.SourcePrefixListId = permission.SourcePrefixListId
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := e.auth.ECS.ModifySecurityGroupRule(req)
 	if err != nil {
 		klog.Errorf("calling ModifySecurityGroupRule: securityGroupId=%s, message=[%s].", sgId, err.Error())
@@ -313,7 +323,7 @@ func (e *ECSProvider) ListInstances(ctx context.Context, ids []string) (map[stri
 
 	var insList []ecs.Instance
 	for region, nodes := range nodeRegionMap {
-		ins, err := e.getInstances(nodes, region)
+		ins, err := e.getInstances(ctx, nodes, region)
 		if err != nil {
 			return nil, err
 		}
@@ -347,6 +357,9 @@ func (e *ECSProvider) ListInstances(ctx context.Context, ids []string) (map[stri
 					SpotStrategy:              n.SpotStrategy,
 					PrimaryNetworkInterfaceID: primaryNetworkInterface,
 					Tags:                      tags,
+					Status:                    n.Status,
+					ExpiredTime:               findExpiredTime(&n),
+					LockReasons:               findLockReasons(&n),
 				}
 				break
 			}
@@ -372,6 +385,7 @@ func (e *ECSProvider) GetInstancesByIP(ctx context.Context, ips []string) (*prvd
 			Key: ctrlCfg.CloudCFG.GetKubernetesClusterTag(),
 		},
 	}
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := e.auth.ECS.DescribeInstances(req)
 	if err != nil {
 		klog.V(5).Infof("RequestId: %s, API: %s, ips: %s", resp.RequestId, "DescribeInstances", req.PrivateIpAddresses)
@@ -399,10 +413,13 @@ func (e *ECSProvider) GetInstancesByIP(ctx context.Context, ips []string) (*prvd
 		InstanceChargeType: ins.InstanceChargeType,
 		SpotStrategy:       ins.SpotStrategy,
 		Tags:               tags,
+		Status:             ins.Status,
+		ExpiredTime:        findExpiredTime(&ins),
+		LockReasons:        findLockReasons(&ins),
 	}, nil
 }
 
-func (e *ECSProvider) getInstances(ids []string, region string) ([]ecs.Instance, error) {
+func (e *ECSProvider) getInstances(ctx context.Context, ids []string, region string) ([]ecs.Instance, error) {
 	bids, err := json.Marshal(ids)
 	if err != nil {
 		return nil, fmt.Errorf("get instances error: %s", err.Error())
@@ -416,6 +433,7 @@ func (e *ECSProvider) getInstances(ids []string, region string) ([]ecs.Instance,
 
 	var ecsInstances []ecs.Instance
 	for {
+		tracing.Inject(ctx, req.GetHeaders())
 		resp, err := e.auth.ECS.DescribeInstances(req)
 		if err != nil {
 			klog.Errorf("calling DescribeInstances: region=%s, "+
@@ -434,7 +452,7 @@ func (e *ECSProvider) getInstances(ids []string, region string) ([]ecs.Instance,
 	return ecsInstances, nil
 }
 
-func (e *ECSProvider) DescribeNetworkInterfaces(vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error) {
+func (e *ECSProvider) DescribeNetworkInterfaces(ctx context.Context, vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error) {
 	result := make(map[string]string)
 
 	for begin := 0; begin < len(ips); begin += MaxNetworkInterfaceNum {
@@ -455,6 +473,7 @@ func (e *ECSProvider) DescribeNetworkInterfaces(vpcId string, ips []string, ipVe
 		req.MaxResults = requests.NewInteger(MaxResult)
 
 		for {
+			tracing.Inject(ctx, req.GetHeaders())
 			resp, err := e.auth.ECS.DescribeNetworkInterfaces(req)
 			if err != nil {
 				return result, err
@@ -484,7 +503,7 @@ func (e *ECSProvider) DescribeNetworkInterfaces(vpcId string, ips []string, ipVe
 	return result, nil
 }
 
-func (e *ECSProvider) DescribeNetworkInterfacesByIDs(ids []string) ([]*prvd.EniAttribute, error) {
+func (e *ECSProvider) DescribeNetworkInterfacesByIDs(ctx context.Context, ids []string) ([]*prvd.EniAttribute, error) {
 	var result []*prvd.EniAttribute
 	for begin := 0; begin < len(ids); begin += MaxNetworkInterfaceNum {
 		last := len(ids)
@@ -499,6 +518,7 @@ func (e *ECSProvider) DescribeNetworkInterfacesByIDs(ids []string) ([]*prvd.EniA
 		nextToken := ""
 		for {
 			req.NextToken = nextToken
+			tracing.Inject(ctx, req.GetHeaders())
 			resp, err := e.auth.ECS.DescribeNetworkInterfaces(req)
 			if err != nil {
 				return result, err
@@ -525,11 +545,12 @@ func (e *ECSProvider) DescribeNetworkInterfacesByIDs(ids []string) ([]*prvd.EniA
 	return result, nil
 }
 
-func (e *ECSProvider) ModifyNetworkInterfaceSourceDestCheck(id string, enabled bool) error {
+func (e *ECSProvider) ModifyNetworkInterfaceSourceDestCheck(ctx context.Context, id string, enabled bool) error {
 	req := ecs.CreateModifyNetworkInterfaceAttributeRequest()
 	req.NetworkInterfaceId = id
 	req.SourceDestCheck = requests.NewBoolean(enabled)
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := e.auth.ECS.ModifyNetworkInterfaceAttribute(req)
 	if err != nil {
 		return err
@@ -541,6 +562,29 @@ func (e *ECSProvider) ModifyNetworkInterfaceSourceDestCheck(id string, enabled b
 	return nil
 }
 
+// findExpiredTime parses the expired time of a subscription instance, the expired time of a
+// pay-as-you-go instance is meaningless and ignored.
+func findExpiredTime(instance *ecs.Instance) time.Time {
+	if instance.InstanceChargeType != prvd.InstanceChargeTypePrePaid || instance.ExpiredTime == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{"2006-01-02T15:04Z", time.RFC3339} {
+		if t, err := time.Parse(layout, instance.ExpiredTime); err == nil {
+			return t
+		}
+	}
+	klog.Warningf("instance %s has invalid expired time %s", instance.InstanceId, instance.ExpiredTime)
+	return time.Time{}
+}
+
+func findLockReasons(instance *ecs.Instance) []string {
+	var reasons []string
+	for _, l := range instance.OperationLocks.LockReason {
+		reasons = append(reasons, l.LockReason)
+	}
+	return reasons
+}
+
 func findAddress(instance *ecs.Instance) []v1.NodeAddress {
 	var addrs []v1.NodeAddress
 
@@ -590,6 +634,7 @@ func findAddress(instance *ecs.Instance) []v1.NodeAddress {
 func (e *ECSProvider) DeleteInstance(ctx context.Context, id string) error {
 	req := ecs.CreateDeleteInstanceRequest()
 	req.InstanceId = id
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := e.auth.ECS.DeleteInstance(req)
 	if err != nil {
 		klog.Errorf("calling DeleteInstance: region=%s, instanceID=%s, message=[%s].", req.RegionId, id, err.Error())
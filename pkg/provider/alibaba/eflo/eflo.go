@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	efloController "github.com/alibabacloud-go/eflo-controller-20221215/v2/client"
+	"github.com/alibabacloud-go/tea/dara"
 	"github.com/alibabacloud-go/tea/tea"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
@@ -27,7 +28,7 @@ func (e *EFLOProvider) DescribeLingJunNode(ctx context.Context, id string) (*prv
 	req := &efloController.DescribeNodeRequest{}
 	req.NodeId = tea.String(id)
 
-	resp, err := e.auth.EFLO.DescribeNode(req)
+	resp, err := e.auth.EFLO.DescribeNodeWithContext(ctx, req, &dara.RuntimeOptions{})
 	if err != nil {
 		if strings.Contains(err.Error(), "RESOURCE_NOT_FOUND") {
 			return nil, nil
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strconv"
 
+	openapiutil "github.com/alibabacloud-go/darabonba-openapi/v2/utils"
 	nlb "github.com/alibabacloud-go/nlb-20220430/v4/client"
+	"github.com/alibabacloud-go/tea/dara"
 	"github.com/alibabacloud-go/tea/tea"
 	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
@@ -24,7 +26,7 @@ func (p *NLBProvider) ListNLBListeners(ctx context.Context, lbId string) ([]*nlb
 		var resp *nlb.ListListenersResponse
 		err := retryOnThrottling("ListListeners", func() error {
 			var err error
-			resp, err = p.auth.NLB.ListListeners(req)
+			resp, err = p.auth.NLB.ListListenersWithContext(ctx, req, &dara.RuntimeOptions{})
 			return err
 		})
 		if err != nil {
@@ -116,7 +118,7 @@ func (p *NLBProvider) CreateNLBListener(ctx context.Context, lbId string, lis *n
 	if err != nil {
 		return err
 	}
-	return p.WaitJobFinish("CreateListener", jobId)
+	return p.WaitJobFinish(ctx, "CreateListener", jobId)
 }
 
 func (p *NLBProvider) UpdateNLBListener(ctx context.Context, lis *nlbmodel.ListenerAttribute) error {
@@ -124,7 +126,7 @@ func (p *NLBProvider) UpdateNLBListener(ctx context.Context, lis *nlbmodel.Liste
 	if err != nil {
 		return err
 	}
-	return p.WaitJobFinish("UpdateListener", jobId)
+	return p.WaitJobFinish(ctx, "UpdateListener", jobId)
 }
 
 func (p *NLBProvider) DeleteNLBListener(ctx context.Context, listenerId string) error {
@@ -132,7 +134,7 @@ func (p *NLBProvider) DeleteNLBListener(ctx context.Context, listenerId string)
 	if err != nil {
 		return err
 	}
-	return p.WaitJobFinish("DeleteListener", jobId)
+	return p.WaitJobFinish(ctx, "DeleteListener", jobId)
 }
 
 func (p *NLBProvider) StartNLBListener(ctx context.Context, listenerId string) error {
@@ -142,7 +144,7 @@ func (p *NLBProvider) StartNLBListener(ctx context.Context, listenerId string) e
 	var resp *nlb.StartListenerResponse
 	err := retryOnThrottling("StartListener", func() error {
 		var err error
-		resp, err = p.auth.NLB.StartListener(req)
+		resp, err = p.auth.NLB.StartListenerWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -202,7 +204,7 @@ func (p *NLBProvider) CreateNLBListenerAsync(ctx context.Context, lbId string, l
 	var resp *nlb.CreateListenerResponse
 	err := retryOnThrottling("CreateListener", func() error {
 		var err error
-		resp, err = p.auth.NLB.CreateListener(req)
+		resp, err = p.auth.NLB.CreateListenerWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -253,7 +255,7 @@ func (p *NLBProvider) UpdateNLBListenerAsync(ctx context.Context, lis *nlbmodel.
 	var resp *nlb.UpdateListenerAttributeResponse
 	err := retryOnThrottling("UpdateListenerAttribute", func() error {
 		var err error
-		resp, err = p.auth.NLB.UpdateListenerAttribute(req)
+		resp, err = p.auth.NLB.UpdateListenerAttributeWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -273,7 +275,7 @@ func (p *NLBProvider) DeleteNLBListenerAsync(ctx context.Context, listenerId str
 	var resp *nlb.DeleteListenerResponse
 	err := retryOnThrottling("DeleteListener", func() error {
 		var err error
-		resp, err = p.auth.NLB.DeleteListener(req)
+		resp, err = p.auth.NLB.DeleteListenerWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -285,3 +287,98 @@ func (p *NLBProvider) DeleteNLBListenerAsync(ctx context.Context, listenerId str
 	klog.V(5).Infof("RequestId: %s, API: %s", tea.StringValue(resp.Body.RequestId), "DeleteNLBListener")
 	return tea.StringValue(resp.Body.JobId), nil
 }
+
+func (p *NLBProvider) GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error) {
+	var respStatus []*nlb.GetListenerHealthStatusResponseBodyListenerHealthStatus
+	nextToken := ""
+	for {
+		var resp *nlb.GetListenerHealthStatusResponse
+		err := retryOnThrottling("GetListenerHealthStatus", func() error {
+			var err error
+			resp, err = p.getListenerHealthStatus(ctx, listenerId, nextToken)
+			return err
+		})
+		if err != nil {
+			return nil, util.SDKError("GetListenerHealthStatus", err)
+		}
+		if resp == nil || resp.Body == nil {
+			return nil, fmt.Errorf("OpenAPI GetListenerHealthStatus resp is nil")
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s, listenerId: %s", tea.StringValue(resp.Body.RequestId), "GetListenerHealthStatus", listenerId)
+
+		respStatus = append(respStatus, resp.Body.ListenerHealthStatus...)
+
+		nextToken = tea.StringValue(resp.Body.NextToken)
+		if nextToken == "" {
+			break
+		}
+	}
+
+	var ret []nlbmodel.ServerGroupHealthStatus
+	for _, ls := range respStatus {
+		if ls == nil {
+			continue
+		}
+		for _, sg := range ls.ServerGroupInfos {
+			if sg == nil {
+				continue
+			}
+			status := nlbmodel.ServerGroupHealthStatus{
+				ServerGroupId:      tea.StringValue(sg.ServerGroupId),
+				HealthCheckEnabled: tea.BoolValue(sg.HeathCheckEnabled),
+			}
+			for _, s := range sg.NonNormalServers {
+				if s == nil {
+					continue
+				}
+				server := nlbmodel.ServerHealthStatus{
+					ServerId: tea.StringValue(s.ServerId),
+					ServerIp: tea.StringValue(s.ServerIp),
+					Port:     tea.Int32Value(s.Port),
+					Status:   tea.StringValue(s.Status),
+				}
+				if s.Reason != nil {
+					server.ReasonCode = tea.StringValue(s.Reason.ReasonCode)
+				}
+				status.NonNormalServers = append(status.NonNormalServers, server)
+			}
+			ret = append(ret, status)
+		}
+	}
+	return ret, nil
+}
+
+// getListenerHealthStatus calls GetListenerHealthStatus with the paging parameters, which are
+// missing from the request of the sdk.
+func (p *NLBProvider) getListenerHealthStatus(ctx context.Context, listenerId, nextToken string) (*nlb.GetListenerHealthStatusResponse, error) {
+	query := map[string]interface{}{
+		"ListenerId": tea.String(listenerId),
+		"MaxResults": tea.Int32(100),
+	}
+	if nextToken != "" {
+		query["NextToken"] = tea.String(nextToken)
+	}
+	req := &openapiutil.OpenApiRequest{
+		Query: openapiutil.Query(query),
+	}
+	params := &openapiutil.Params{
+		Action:      tea.String("GetListenerHealthStatus"),
+		Version:     tea.String("2022-04-30"),
+		Protocol:    tea.String("HTTPS"),
+		Pathname:    tea.String("/"),
+		Method:      tea.String("POST"),
+		AuthType:    tea.String("AK"),
+		Style:       tea.String("RPC"),
+		ReqBodyType: tea.String("formData"),
+		BodyType:    tea.String("json"),
+	}
+	body, err := p.auth.NLB.CallApiWithCtx(ctx, params, req, &dara.RuntimeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	resp := &nlb.GetListenerHealthStatusResponse{}
+	if err := dara.Convert(body, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
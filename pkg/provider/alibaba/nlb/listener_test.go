@@ -0,0 +1,85 @@
+package nlb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	nlb "github.com/alibabacloud-go/nlb-20220430/v4/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/stretchr/testify/assert"
+
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+)
+
+func TestGetNLBListenerHealthStatusPaging(t *testing.T) {
+	// each page reports one server group, the last page has no next token
+	pages := map[string]map[string]interface{}{
+		"": {
+			"RequestId": "req-1",
+			"NextToken": "token-1",
+			"ListenerHealthStatus": []map[string]interface{}{{
+				"ListenerId": "lsn-1",
+				"ServerGroupInfos": []map[string]interface{}{{
+					"ServerGroupId":     "sgp-1",
+					"HeathCheckEnabled": true,
+					"NonNormalServers": []map[string]interface{}{{
+						"ServerId": "eni-1", "ServerIp": "10.0.0.1", "Port": 80, "Status": "Unhealthy",
+						"Reason": map[string]interface{}{"ReasonCode": "CONNECT_TIMEOUT"},
+					}},
+				}},
+			}},
+		},
+		"token-1": {
+			"RequestId": "req-2",
+			"ListenerHealthStatus": []map[string]interface{}{{
+				"ListenerId": "lsn-1",
+				"ServerGroupInfos": []map[string]interface{}{{
+					"ServerGroupId":     "sgp-2",
+					"HeathCheckEnabled": false,
+				}},
+			}},
+		},
+	}
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "GetListenerHealthStatus", r.Header.Get("x-acs-action"))
+		assert.Equal(t, "lsn-1", r.Form.Get("ListenerId"))
+		assert.Equal(t, "100", r.Form.Get("MaxResults"))
+		token := r.Form.Get("NextToken")
+		tokens = append(tokens, token)
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(pages[token]))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := nlb.NewClient(&openapi.Config{
+		RegionId:        tea.String("cn-hangzhou"),
+		AccessKeyId:     tea.String("ak"),
+		AccessKeySecret: tea.String("sk"),
+		Protocol:        tea.String("http"),
+		Endpoint:        tea.String(strings.TrimPrefix(server.URL, "http://")),
+	})
+	assert.NoError(t, err)
+	p := NewNLBProvider(&base.ClientMgr{NLB: client})
+
+	status, err := p.GetNLBListenerHealthStatus(context.TODO(), "lsn-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "token-1"}, tokens)
+	assert.Equal(t, []nlbmodel.ServerGroupHealthStatus{
+		{
+			ServerGroupId:      "sgp-1",
+			HealthCheckEnabled: true,
+			NonNormalServers: []nlbmodel.ServerHealthStatus{{
+				ServerId: "eni-1", ServerIp: "10.0.0.1", Port: 80, Status: "Unhealthy", ReasonCode: "CONNECT_TIMEOUT",
+			}},
+		},
+		{ServerGroupId: "sgp-2"},
+	}, status)
+}
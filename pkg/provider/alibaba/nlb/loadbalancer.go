@@ -7,6 +7,7 @@ import (
 	"time"
 
 	nlb "github.com/alibabacloud-go/nlb-20220430/v4/client"
+	"github.com/alibabacloud-go/tea/dara"
 	"github.com/alibabacloud-go/tea/tea"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -108,7 +109,7 @@ func (p *NLBProvider) FindNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBala
 	}
 
 	// 2. find by tags
-	err := p.findNLBByTag(mdl)
+	err := p.findNLBByTag(ctx, mdl)
 	if err != nil {
 		return err
 	}
@@ -119,7 +120,7 @@ func (p *NLBProvider) FindNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBala
 	}
 
 	// 3. find by name
-	err = p.findNLBByName(mdl)
+	err = p.findNLBByName(ctx, mdl)
 	if err != nil {
 		return err
 	}
@@ -134,7 +135,7 @@ func (p *NLBProvider) FindNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBala
 }
 
 func (p *NLBProvider) DescribeNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
-	resp, err := p.waitNLBActive(mdl.LoadBalancerAttribute.LoadBalancerId)
+	resp, err := p.waitNLBActive(ctx, mdl.LoadBalancerAttribute.LoadBalancerId)
 	if err != nil {
 		return err
 	}
@@ -201,7 +202,7 @@ func (p *NLBProvider) CreateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBa
 	var resp *nlb.CreateLoadBalancerResponse
 	err := retryOnThrottling("CreateLoadBalancer", func() error {
 		var err error
-		resp, err = p.auth.NLB.CreateLoadBalancer(req)
+		resp, err = p.auth.NLB.CreateLoadBalancerWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -222,7 +223,7 @@ func (p *NLBProvider) DeleteNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBa
 	var resp *nlb.DeleteLoadBalancerResponse
 	err := retryOnThrottling("DeleteLoadBalancer", func() error {
 		var err error
-		resp, err = p.auth.NLB.DeleteLoadBalancer(req)
+		resp, err = p.auth.NLB.DeleteLoadBalancerWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -233,7 +234,7 @@ func (p *NLBProvider) DeleteNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBa
 	}
 	klog.V(5).Infof("RequestId: %s, API: %s", tea.StringValue(resp.Body.RequestId), "DeleteLoadBalancer")
 
-	return p.WaitJobFinish("DeleteLoadBalancer", tea.StringValue(resp.Body.JobId), 20*time.Second, 3*time.Minute)
+	return p.WaitJobFinish(ctx, "DeleteLoadBalancer", tea.StringValue(resp.Body.JobId), 20*time.Second, 3*time.Minute)
 }
 
 func (p *NLBProvider) UpdateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
@@ -248,7 +249,7 @@ func (p *NLBProvider) UpdateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBa
 	var resp *nlb.UpdateLoadBalancerAttributeResponse
 	err := retryOnThrottling("UpdateLoadBalancerAttribute", func() error {
 		var err error
-		resp, err = p.auth.NLB.UpdateLoadBalancerAttribute(req)
+		resp, err = p.auth.NLB.UpdateLoadBalancerAttributeWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -270,7 +271,7 @@ func (p *NLBProvider) UpdateNLBAddressType(ctx context.Context, mdl *nlbmodel.Ne
 	var resp *nlb.UpdateLoadBalancerAddressTypeConfigResponse
 	err := retryOnThrottling("UpdateLoadBalancerAddressTypeConfig", func() error {
 		var err error
-		resp, err = p.auth.NLB.UpdateLoadBalancerAddressTypeConfig(req)
+		resp, err = p.auth.NLB.UpdateLoadBalancerAddressTypeConfigWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -302,7 +303,7 @@ func (p *NLBProvider) enableIPv6Internet(ctx context.Context, mdl *nlbmodel.Netw
 	var resp *nlb.EnableLoadBalancerIpv6InternetResponse
 	err := retryOnThrottling("EnableLoadBalancerIpv6Internet", func() error {
 		var err error
-		resp, err = p.auth.NLB.EnableLoadBalancerIpv6Internet(req)
+		resp, err = p.auth.NLB.EnableLoadBalancerIpv6InternetWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -322,7 +323,7 @@ func (p *NLBProvider) disableIPv6Internet(ctx context.Context, mdl *nlbmodel.Net
 	var resp *nlb.DisableLoadBalancerIpv6InternetResponse
 	err := retryOnThrottling("DisableLoadBalancerIpv6Internet", func() error {
 		var err error
-		resp, err = p.auth.NLB.DisableLoadBalancerIpv6Internet(req)
+		resp, err = p.auth.NLB.DisableLoadBalancerIpv6InternetWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -356,7 +357,7 @@ func (p *NLBProvider) UpdateNLBZones(ctx context.Context, mdl *nlbmodel.NetworkL
 	var resp *nlb.UpdateLoadBalancerZonesResponse
 	err := retryOnThrottling("UpdateLoadBalancerZones", func() error {
 		var err error
-		resp, err = p.auth.NLB.UpdateLoadBalancerZones(req)
+		resp, err = p.auth.NLB.UpdateLoadBalancerZonesWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -377,7 +378,7 @@ func (p *NLBProvider) NLBJoinSecurityGroup(ctx context.Context, lbId string, sgI
 	var resp *nlb.LoadBalancerJoinSecurityGroupResponse
 	err := retryOnThrottling("LoadBalancerJoinSecurityGroup", func() error {
 		var err error
-		resp, err = p.auth.NLB.LoadBalancerJoinSecurityGroup(req)
+		resp, err = p.auth.NLB.LoadBalancerJoinSecurityGroupWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -388,7 +389,7 @@ func (p *NLBProvider) NLBJoinSecurityGroup(ctx context.Context, lbId string, sgI
 	}
 	klog.V(5).Infof("RequestId: %s, API: %s", tea.StringValue(resp.Body.RequestId), "LoadBalancerJoinSecurityGroup")
 
-	err = p.WaitJobFinish("LoadBalancerJoinSecurityGroup", tea.StringValue(resp.Body.JobId))
+	err = p.WaitJobFinish(ctx, "LoadBalancerJoinSecurityGroup", tea.StringValue(resp.Body.JobId))
 	if err != nil {
 		return err
 	}
@@ -402,7 +403,7 @@ func (p *NLBProvider) NLBLeaveSecurityGroup(ctx context.Context, lbId string, sg
 	var resp *nlb.LoadBalancerLeaveSecurityGroupResponse
 	err := retryOnThrottling("LoadBalancerLeaveSecurityGroup", func() error {
 		var err error
-		resp, err = p.auth.NLB.LoadBalancerLeaveSecurityGroup(req)
+		resp, err = p.auth.NLB.LoadBalancerLeaveSecurityGroupWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -413,7 +414,7 @@ func (p *NLBProvider) NLBLeaveSecurityGroup(ctx context.Context, lbId string, sg
 	}
 	klog.V(5).Infof("RequestId: %s, API: %s", tea.StringValue(resp.Body.RequestId), "LoadBalancerLeaveSecurityGroup")
 
-	err = p.WaitJobFinish("LoadBalancerLeaveSecurityGroup", tea.StringValue(resp.Body.JobId))
+	err = p.WaitJobFinish(ctx, "LoadBalancerLeaveSecurityGroup", tea.StringValue(resp.Body.JobId))
 	if err != nil {
 		return err
 	}
@@ -430,7 +431,7 @@ func (p *NLBProvider) UpdateNLBSecurityGroupIds(ctx context.Context, mdl *nlbmod
 		var resp *nlb.LoadBalancerJoinSecurityGroupResponse
 		err := retryOnThrottling("LoadBalancerJoinSecurityGroup", func() error {
 			var err error
-			resp, err = p.auth.NLB.LoadBalancerJoinSecurityGroup(req)
+			resp, err = p.auth.NLB.LoadBalancerJoinSecurityGroupWithContext(ctx, req, &dara.RuntimeOptions{})
 			return err
 		})
 		if err != nil {
@@ -441,7 +442,7 @@ func (p *NLBProvider) UpdateNLBSecurityGroupIds(ctx context.Context, mdl *nlbmod
 		}
 		klog.V(5).Infof("RequestId: %s, API: %s", tea.StringValue(resp.Body.RequestId), "LoadBalancerJoinSecurityGroup")
 
-		err = p.WaitJobFinish("LoadBalancerJoinSecurityGroup", tea.StringValue(resp.Body.JobId))
+		err = p.WaitJobFinish(ctx, "LoadBalancerJoinSecurityGroup", tea.StringValue(resp.Body.JobId))
 		if err != nil {
 			return err
 		}
@@ -454,7 +455,7 @@ func (p *NLBProvider) UpdateNLBSecurityGroupIds(ctx context.Context, mdl *nlbmod
 		var resp *nlb.LoadBalancerLeaveSecurityGroupResponse
 		err := retryOnThrottling("LoadBalancerLeaveSecurityGroup", func() error {
 			var err error
-			resp, err = p.auth.NLB.LoadBalancerLeaveSecurityGroup(req)
+			resp, err = p.auth.NLB.LoadBalancerLeaveSecurityGroupWithContext(ctx, req, &dara.RuntimeOptions{})
 			return err
 		})
 		if err != nil {
@@ -465,7 +466,7 @@ func (p *NLBProvider) UpdateNLBSecurityGroupIds(ctx context.Context, mdl *nlbmod
 		}
 		klog.V(5).Infof("RequestId: %s, API: %s", tea.StringValue(resp.Body.RequestId), "LoadBalancerLeaveSecurityGroup")
 
-		err = p.WaitJobFinish("LoadBalancerLeaveSecurityGroup", tea.StringValue(resp.Body.JobId))
+		err = p.WaitJobFinish(ctx, "LoadBalancerLeaveSecurityGroup", tea.StringValue(resp.Body.JobId))
 		if err != nil {
 			return err
 		}
@@ -517,7 +518,7 @@ func (p *NLBProvider) UpdateLoadBalancerProtection(ctx context.Context, lbId str
 	var resp *nlb.UpdateLoadBalancerProtectionResponse
 	err := retryOnThrottling("UpdateLoadBalancerProtection", func() error {
 		var err error
-		resp, err = p.auth.NLB.UpdateLoadBalancerProtection(req)
+		resp, err = p.auth.NLB.UpdateLoadBalancerProtectionWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -539,7 +540,7 @@ func (p *NLBProvider) AttachCommonBandwidthPackageToLoadBalancer(ctx context.Con
 	var resp *nlb.AttachCommonBandwidthPackageToLoadBalancerResponse
 	err := retryOnThrottling("AttachCommonBandwidthPackageToLoadBalancer", func() error {
 		var err error
-		resp, err = p.auth.NLB.AttachCommonBandwidthPackageToLoadBalancer(req)
+		resp, err = p.auth.NLB.AttachCommonBandwidthPackageToLoadBalancerWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -561,7 +562,7 @@ func (p *NLBProvider) DetachCommonBandwidthPackageFromLoadBalancer(ctx context.C
 	var resp *nlb.DetachCommonBandwidthPackageFromLoadBalancerResponse
 	err := retryOnThrottling("DetachCommonBandwidthPackageFromLoadBalancer", func() error {
 		var err error
-		resp, err = p.auth.NLB.DetachCommonBandwidthPackageFromLoadBalancer(req)
+		resp, err = p.auth.NLB.DetachCommonBandwidthPackageFromLoadBalancerWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -572,7 +573,7 @@ func (p *NLBProvider) DetachCommonBandwidthPackageFromLoadBalancer(ctx context.C
 	}
 
 	klog.V(5).Infof("RequestId: %s, API: %s", tea.StringValue(resp.Body.RequestId), "DetachCommonBandwidthPackageFromLoadBalancer")
-	err = p.WaitJobFinish("DetachCommonBandwidthPackageFromLoadBalancer", tea.StringValue(resp.Body.JobId))
+	err = p.WaitJobFinish(ctx, "DetachCommonBandwidthPackageFromLoadBalancer", tea.StringValue(resp.Body.JobId))
 	if err != nil {
 		return err
 	}
@@ -595,7 +596,7 @@ func (p *NLBProvider) TagNLBResource(ctx context.Context, resourceId string, res
 	var resp *nlb.TagResourcesResponse
 	err := retryOnThrottling("TagResources", func() error {
 		var err error
-		resp, err = p.auth.NLB.TagResources(req)
+		resp, err = p.auth.NLB.TagResourcesWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -616,7 +617,7 @@ func (p *NLBProvider) ListNLBTagResources(ctx context.Context, lbId string) ([]t
 	var resp *nlb.ListTagResourcesResponse
 	err := retryOnThrottling("ListTagResources", func() error {
 		var err error
-		resp, err = p.auth.NLB.ListTagResources(req)
+		resp, err = p.auth.NLB.ListTagResourcesWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -639,7 +640,7 @@ func (p *NLBProvider) ListNLBTagResources(ctx context.Context, lbId string) ([]t
 	return ret, nil
 }
 
-func (p *NLBProvider) findNLBByTag(mdl *nlbmodel.NetworkLoadBalancer) error {
+func (p *NLBProvider) findNLBByTag(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
 	klog.Infof("[%s] try to find nlb by tag %+v", mdl.NamespacedName, mdl.LoadBalancerAttribute.Tags)
 	req := &nlb.ListLoadBalancersRequest{}
 	for _, v := range mdl.LoadBalancerAttribute.Tags {
@@ -653,7 +654,7 @@ func (p *NLBProvider) findNLBByTag(mdl *nlbmodel.NetworkLoadBalancer) error {
 	resp := &nlb.ListLoadBalancersResponse{}
 	err := retryOnThrottling("ListLoadBalancers", func() error {
 		var err error
-		resp, err = p.auth.NLB.ListLoadBalancers(req)
+		resp, err = p.auth.NLB.ListLoadBalancersWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -689,18 +690,64 @@ func (p *NLBProvider) findNLBByTag(mdl *nlbmodel.NetworkLoadBalancer) error {
 		return loadResponse(resp.Body.LoadBalancers[0], mdl)
 	}
 
-	getResp, err := p.waitNLBActive(tea.StringValue(resp.Body.LoadBalancers[0].LoadBalancerId))
+	getResp, err := p.waitNLBActive(ctx, tea.StringValue(resp.Body.LoadBalancers[0].LoadBalancerId))
 	if err != nil {
 		return err
 	}
 	return loadResponse(getResp.Body, mdl)
 }
 
+func (p *NLBProvider) ListNLBs(ctx context.Context, tags []tag.Tag) ([]*nlbmodel.NetworkLoadBalancer, error) {
+	var ret []*nlbmodel.NetworkLoadBalancer
+	var nextToken = ""
+	for {
+		req := &nlb.ListLoadBalancersRequest{}
+		req.MaxResults = tea.Int32(100)
+		req.NextToken = tea.String(nextToken)
+		for _, t := range tags {
+			req.Tag = append(req.Tag, &nlb.ListLoadBalancersRequestTag{
+				Key:   tea.String(t.Key),
+				Value: tea.String(t.Value),
+			})
+		}
+		var resp *nlb.ListLoadBalancersResponse
+		err := retryOnThrottling("ListLoadBalancers", func() error {
+			var err error
+			resp, err = p.auth.NLB.ListLoadBalancersWithContext(ctx, req, &dara.RuntimeOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, util.SDKError("ListLoadBalancers", err)
+		}
+		if resp == nil || resp.Body == nil {
+			return nil, fmt.Errorf("OpenAPI ListLoadBalancers resp is nil")
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s", tea.StringValue(resp.Body.RequestId), "ListLoadBalancers")
+
+		for _, lb := range resp.Body.LoadBalancers {
+			if lb == nil {
+				continue
+			}
+			mdl := &nlbmodel.NetworkLoadBalancer{LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{}}
+			if err := loadResponse(lb, mdl); err != nil {
+				return nil, err
+			}
+			ret = append(ret, mdl)
+		}
+
+		nextToken = tea.StringValue(resp.Body.NextToken)
+		if nextToken == "" {
+			break
+		}
+	}
+	return ret, nil
+}
+
 func (p *NLBProvider) FindNLBByName(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
-	return p.findNLBByName(mdl)
+	return p.findNLBByName(ctx, mdl)
 }
 
-func (p *NLBProvider) findNLBByName(mdl *nlbmodel.NetworkLoadBalancer) error {
+func (p *NLBProvider) findNLBByName(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
 	if mdl.LoadBalancerAttribute.Name == "" {
 		klog.Warningf("[%s] find nlb by name error: nlb name is empty.", mdl.NamespacedName.String())
 		return nil
@@ -712,7 +759,7 @@ func (p *NLBProvider) findNLBByName(mdl *nlbmodel.NetworkLoadBalancer) error {
 	var resp *nlb.ListLoadBalancersResponse
 	err := retryOnThrottling("ListLoadBalancers", func() error {
 		var err error
-		resp, err = p.auth.NLB.ListLoadBalancers(req)
+		resp, err = p.auth.NLB.ListLoadBalancersWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -743,7 +790,7 @@ func (p *NLBProvider) findNLBByName(mdl *nlbmodel.NetworkLoadBalancer) error {
 		return loadResponse(resp.Body.LoadBalancers[0], mdl)
 	}
 
-	getResp, err := p.waitNLBActive(tea.StringValue(resp.Body.LoadBalancers[0].LoadBalancerId))
+	getResp, err := p.waitNLBActive(ctx, tea.StringValue(resp.Body.LoadBalancers[0].LoadBalancerId))
 	if err != nil {
 		return err
 	}
@@ -834,7 +881,7 @@ const (
 	DefaultRetryTimeout  = 30 * time.Second
 )
 
-func (p *NLBProvider) WaitJobFinish(api, jobId string, args ...time.Duration) error {
+func (p *NLBProvider) WaitJobFinish(ctx context.Context, api, jobId string, args ...time.Duration) error {
 	var interval, timeout time.Duration
 	if len(args) < 2 {
 		interval = DefaultRetryInterval
@@ -852,7 +899,7 @@ func (p *NLBProvider) WaitJobFinish(api, jobId string, args ...time.Duration) er
 		req.JobId = tea.String(jobId)
 		retErr = retryOnThrottling("GetJobStatus", func() error {
 			var err error
-			resp, err = p.auth.NLB.GetJobStatus(req)
+			resp, err = p.auth.NLB.GetJobStatusWithContext(ctx, req, &dara.RuntimeOptions{})
 			return err
 		})
 		if retErr != nil {
@@ -949,7 +996,7 @@ func (p *NLBProvider) listAsynJobs(ctx context.Context, jobIds []string) ([]*nlb
 		req.JobIds = tea.StringSlice(pieces[i])
 		err := retryOnThrottling("ListAsynJobs", func() error {
 			var err error
-			resp, err = p.auth.NLB.ListAsynJobs(req)
+			resp, err = p.auth.NLB.ListAsynJobsWithContext(ctx, req, &dara.RuntimeOptions{})
 			return err
 		})
 		if err != nil {
@@ -973,7 +1020,7 @@ func (p *NLBProvider) listAsynJobs(ctx context.Context, jobIds []string) ([]*nlb
 	return ret, nil
 }
 
-func (p *NLBProvider) waitNLBActive(lbId string) (*nlb.GetLoadBalancerAttributeResponse, error) {
+func (p *NLBProvider) waitNLBActive(ctx context.Context, lbId string) (*nlb.GetLoadBalancerAttributeResponse, error) {
 	var (
 		retErr error
 		resp   *nlb.GetLoadBalancerAttributeResponse
@@ -984,7 +1031,7 @@ func (p *NLBProvider) waitNLBActive(lbId string) (*nlb.GetLoadBalancerAttributeR
 
 		retErr = retryOnThrottling("GetLoadBalancerAttribute", func() error {
 			var err error
-			resp, err = p.auth.NLB.GetLoadBalancerAttribute(req)
+			resp, err = p.auth.NLB.GetLoadBalancerAttributeWithContext(ctx, req, &dara.RuntimeOptions{})
 			return err
 		})
 		if retErr != nil {
@@ -1066,7 +1113,7 @@ func (p *NLBProvider) UntagNLBResources(ctx context.Context, resourceId string,
 	var resp *nlb.UntagResourcesResponse
 	err := retryOnThrottling("UntagResources", func() error {
 		var err error
-		resp, err = p.auth.NLB.UntagResources(req)
+		resp, err = p.auth.NLB.UntagResourcesWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
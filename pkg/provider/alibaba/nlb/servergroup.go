@@ -6,6 +6,7 @@ import (
 	"time"
 
 	nlb "github.com/alibabacloud-go/nlb-20220430/v4/client"
+	"github.com/alibabacloud-go/tea/dara"
 	"github.com/alibabacloud-go/tea/tea"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
@@ -85,7 +86,7 @@ func (p *NLBProvider) ListNLBServerGroups(ctx context.Context, tags []tag.Tag) (
 		var resp *nlb.ListServerGroupsResponse
 		err := retryOnThrottling("ListServerGroups", func() error {
 			var err error
-			resp, err = p.auth.NLB.ListServerGroups(req)
+			resp, err = p.auth.NLB.ListServerGroupsWithContext(ctx, req, &dara.RuntimeOptions{})
 			return err
 		})
 		if err != nil {
@@ -153,7 +154,7 @@ func (p *NLBProvider) GetNLBServerGroup(ctx context.Context, sgId string) (*nlbm
 	var resp *nlb.ListServerGroupsResponse
 	err := retryOnThrottling("ListServerGroups", func() error {
 		var err error
-		resp, err = p.auth.NLB.ListServerGroups(req)
+		resp, err = p.auth.NLB.ListServerGroupsWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -180,7 +181,7 @@ func (p *NLBProvider) CreateNLBServerGroup(ctx context.Context, sg *nlbmodel.Ser
 	if err != nil {
 		return err
 	}
-	return p.WaitJobFinish("CreateServerGroup", jobId, 1200*time.Millisecond, DefaultRetryTimeout)
+	return p.WaitJobFinish(ctx, "CreateServerGroup", jobId, 1200*time.Millisecond, DefaultRetryTimeout)
 }
 
 func (p *NLBProvider) DeleteNLBServerGroup(ctx context.Context, sgId string) error {
@@ -201,7 +202,7 @@ func (p *NLBProvider) AddNLBServers(ctx context.Context, sgId string, backends [
 	if err != nil {
 		return err
 	}
-	return p.WaitJobFinish("AddServersToServerGroup", jobId, 1200*time.Millisecond, DefaultRetryTimeout)
+	return p.WaitJobFinish(ctx, "AddServersToServerGroup", jobId, 1200*time.Millisecond, DefaultRetryTimeout)
 }
 
 func (p *NLBProvider) RemoveNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer,
@@ -210,7 +211,7 @@ func (p *NLBProvider) RemoveNLBServers(ctx context.Context, sgId string, backend
 	if err != nil {
 		return err
 	}
-	return p.WaitJobFinish("RemoveServersFromServerGroup", jobId, 1200*time.Millisecond, DefaultRetryTimeout)
+	return p.WaitJobFinish(ctx, "RemoveServersFromServerGroup", jobId, 1200*time.Millisecond, DefaultRetryTimeout)
 }
 
 func (p *NLBProvider) UpdateNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer,
@@ -219,7 +220,7 @@ func (p *NLBProvider) UpdateNLBServers(ctx context.Context, sgId string, backend
 	if err != nil {
 		return err
 	}
-	return p.WaitJobFinish("UpdateServerGroupServersAttribute", jobId, 1200*time.Millisecond, DefaultRetryTimeout)
+	return p.WaitJobFinish(ctx, "UpdateServerGroupServersAttribute", jobId, 1200*time.Millisecond, DefaultRetryTimeout)
 }
 
 func (p *NLBProvider) ListNLBServers(ctx context.Context, sgId string) ([]nlbmodel.ServerGroupServer, error) {
@@ -234,7 +235,7 @@ func (p *NLBProvider) ListNLBServers(ctx context.Context, sgId string) ([]nlbmod
 		var resp *nlb.ListServerGroupServersResponse
 		err := retryOnThrottling("ListServerGroupServers", func() error {
 			var err error
-			resp, err = p.auth.NLB.ListServerGroupServers(req)
+			resp, err = p.auth.NLB.ListServerGroupServersWithContext(ctx, req, &dara.RuntimeOptions{})
 			return err
 		})
 		if err != nil {
@@ -346,7 +347,7 @@ func (p *NLBProvider) CreateNLBServerGroupAsync(ctx context.Context, sg *nlbmode
 	var resp *nlb.CreateServerGroupResponse
 	err := retryOnThrottling("CreateServerGroup", func() error {
 		var err error
-		resp, err = p.auth.NLB.CreateServerGroup(req)
+		resp, err = p.auth.NLB.CreateServerGroupWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -367,7 +368,7 @@ func (p *NLBProvider) DeleteNLBServerGroupAsync(ctx context.Context, sgId string
 	var resp *nlb.DeleteServerGroupResponse
 	err := retryOnThrottling("DeleteServerGroup", func() error {
 		var err error
-		resp, err = p.auth.NLB.DeleteServerGroup(req)
+		resp, err = p.auth.NLB.DeleteServerGroupWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -441,7 +442,7 @@ func (p *NLBProvider) UpdateNLBServerGroupAsync(ctx context.Context, sg *nlbmode
 	var resp *nlb.UpdateServerGroupAttributeResponse
 	err := retryOnThrottling("UpdateServerGroupAttribute", func() error {
 		var err error
-		resp, err = p.auth.NLB.UpdateServerGroupAttribute(req)
+		resp, err = p.auth.NLB.UpdateServerGroupAttributeWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -474,7 +475,7 @@ func (p *NLBProvider) AddNLBServersAsync(ctx context.Context, sgId string, backe
 	var resp *nlb.AddServersToServerGroupResponse
 	err := retryOnThrottling("AddServersToServerGroup", func() error {
 		var err error
-		resp, err = p.auth.NLB.AddServersToServerGroup(req)
+		resp, err = p.auth.NLB.AddServersToServerGroupWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -506,7 +507,7 @@ func (p *NLBProvider) RemoveNLBServersAsync(ctx context.Context, sgId string, ba
 	var resp *nlb.RemoveServersFromServerGroupResponse
 	err := retryOnThrottling("RemoveServersFromServerGroup", func() error {
 		var err error
-		resp, err = p.auth.NLB.RemoveServersFromServerGroup(req)
+		resp, err = p.auth.NLB.RemoveServersFromServerGroupWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -541,7 +542,7 @@ func (p *NLBProvider) UpdateNLBServersAsync(ctx context.Context, sgId string, ba
 	var resp *nlb.UpdateServerGroupServersAttributeResponse
 	err := retryOnThrottling("UpdateServerGroupServersAttribute", func() error {
 		var err error
-		resp, err = p.auth.NLB.UpdateServerGroupServersAttribute(req)
+		resp, err = p.auth.NLB.UpdateServerGroupServersAttributeWithContext(ctx, req, &dara.RuntimeOptions{})
 		return err
 	})
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/pvtz"
@@ -53,6 +54,7 @@ func (p *PVTZProvider) SearchPVTZ(ctx context.Context, ep *model.PvtzEndpoint, e
 	pageNumber := 1
 	for {
 		req.PageNumber = requests.NewInteger(pageNumber)
+		tracing.Inject(ctx, req.GetHeaders())
 		resp, err := p.client.DescribeZoneRecords(req)
 		if err != nil {
 			return nil, err
@@ -130,13 +132,13 @@ func (p *PVTZProvider) UpdatePVTZ(ctx context.Context, ep *model.PvtzEndpoint) e
 	}
 	errs := make([]error, 0)
 	for _, val := range valueToAdd {
-		_, err = p.create(ep.Type, ep.Rr, val, int(ep.Ttl))
+		_, err = p.create(ctx, ep.Type, ep.Rr, val, int(ep.Ttl))
 		if err != nil {
 			errs = append(errs, err)
 		}
 	}
 	for _, id := range recordIdToDelete {
-		err = p.delete(id)
+		err = p.delete(ctx, id)
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -152,7 +154,7 @@ func (p *PVTZProvider) DeletePVTZ(ctx context.Context, ep *model.PvtzEndpoint) e
 	oldValues := ep.Values
 	errs := make([]error, 0)
 	for _, val := range oldValues {
-		err = p.delete(val.RecordId)
+		err = p.delete(ctx, val.RecordId)
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -166,7 +168,7 @@ func (p *PVTZProvider) filterUnmanagedDNSRecord(record pvtz.Record) bool {
 
 func (p *PVTZProvider) filterUnsupportedDNSRecordTypes(record pvtz.Record) bool {
 	switch record.Type {
-	case model.RecordTypeA, model.RecordTypeCNAME, model.RecordTypePTR, model.RecordTypeSRV, model.RecordTypeTXT:
+	case model.RecordTypeA, model.RecordTypeAAAA, model.RecordTypeCNAME, model.RecordTypePTR, model.RecordTypeSRV, model.RecordTypeTXT:
 		return false
 	default:
 		return true
@@ -194,7 +196,7 @@ func (p *PVTZProvider) record(ctx context.Context, ep *model.PvtzEndpoint) error
 	return nil
 }
 
-func (p *PVTZProvider) create(recordType, rr, value string, ttl int) (*pvtz.AddZoneRecordResponse, error) {
+func (p *PVTZProvider) create(ctx context.Context, recordType, rr, value string, ttl int) (*pvtz.AddZoneRecordResponse, error) {
 	req := pvtz.CreateAddZoneRecordRequest()
 	req.ZoneId = p.zoneId
 	req.Type = recordType
@@ -202,13 +204,15 @@ func (p *PVTZProvider) create(recordType, rr, value string, ttl int) (*pvtz.AddZ
 	req.Ttl = requests.NewInteger(ttl)
 	req.Remark = ZoneRecordRemark
 	req.Value = value
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.client.AddZoneRecord(req)
 	return resp, err
 }
 
-func (p *PVTZProvider) delete(recordId int64) error {
+func (p *PVTZProvider) delete(ctx context.Context, recordId int64) error {
 	req := pvtz.CreateDeleteZoneRecordRequest()
 	req.RecordId = requests.NewInteger(int(recordId))
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.client.DeleteZoneRecord(req)
 	return err
 }
@@ -0,0 +1,179 @@
+package slb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// MaxAclEntryNumPerRequest is the max number of entries can be added or removed in one request
+	MaxAclEntryNumPerRequest = 50
+	describeAclPageSize      = 50
+)
+
+func (p SLBProvider) CreateAccessControlList(ctx context.Context, acl *model.AccessControlList) (string, error) {
+	req := slb.CreateCreateAccessControlListRequest()
+	req.AclName = acl.AclName
+	req.AddressIPVersion = string(acl.AddressIPVersion)
+	req.ResourceGroupId = acl.ResourceGroupId
+	if len(acl.Tags) != 0 {
+		var tags []slb.CreateAccessControlListTag
+		for _, t := range acl.Tags {
+			tags = append(tags, slb.CreateAccessControlListTag{
+				Key:   t.Key,
+				Value: t.Value,
+			})
+		}
+		req.Tag = &tags
+	}
+	tracing.Inject(ctx, req.GetHeaders())
+	resp, err := p.auth.SLB.CreateAccessControlList(req)
+	if err != nil {
+		return "", util.SDKError("CreateAccessControlList", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, aclId: %s", resp.RequestId, "CreateAccessControlList", resp.AclId)
+	return resp.AclId, nil
+}
+
+func (p SLBProvider) DescribeAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error) {
+	req := slb.CreateDescribeAccessControlListsRequest()
+	var reqTags []slb.DescribeAccessControlListsTag
+	for _, t := range tags {
+		reqTags = append(reqTags, slb.DescribeAccessControlListsTag{
+			Key:   t.Key,
+			Value: t.Value,
+		})
+	}
+	req.Tag = &reqTags
+	req.PageSize = requests.NewInteger(describeAclPageSize)
+
+	var ret []model.AccessControlList
+	for pageNumber := 1; ; pageNumber++ {
+		req.PageNumber = requests.NewInteger(pageNumber)
+		tracing.Inject(ctx, req.GetHeaders())
+		resp, err := p.auth.SLB.DescribeAccessControlLists(req)
+		if err != nil {
+			return nil, util.SDKError("DescribeAccessControlLists", err)
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s, page: %d", resp.RequestId, "DescribeAccessControlLists", pageNumber)
+		for _, a := range resp.Acls.Acl {
+			acl := model.AccessControlList{
+				AclId:            a.AclId,
+				AclName:          a.AclName,
+				AddressIPVersion: model.AddressIPVersionType(a.AddressIPVersion),
+				ResourceGroupId:  a.ResourceGroupId,
+			}
+			for _, t := range a.Tags.Tag {
+				acl.Tags = append(acl.Tags, tag.Tag{Key: t.TagKey, Value: t.TagValue})
+			}
+			ret = append(ret, acl)
+		}
+		if pageNumber*describeAclPageSize >= resp.TotalCount || len(resp.Acls.Acl) == 0 {
+			break
+		}
+	}
+	return ret, nil
+}
+
+func (p SLBProvider) DescribeAccessControlListAttribute(ctx context.Context, aclId string) (*model.AccessControlList, error) {
+	req := slb.CreateDescribeAccessControlListAttributeRequest()
+	req.AclId = aclId
+	tracing.Inject(ctx, req.GetHeaders())
+	resp, err := p.auth.SLB.DescribeAccessControlListAttribute(req)
+	if err != nil {
+		return nil, util.SDKError("DescribeAccessControlListAttribute", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s", resp.RequestId, "DescribeAccessControlListAttribute")
+	acl := &model.AccessControlList{
+		AclId:            resp.AclId,
+		AclName:          resp.AclName,
+		AddressIPVersion: model.AddressIPVersionType(resp.AddressIPVersion),
+		ResourceGroupId:  resp.ResourceGroupId,
+	}
+	for _, e := range resp.AclEntrys.AclEntry {
+		acl.Entries = append(acl.Entries, model.AclEntry{
+			Entry:   e.AclEntryIP,
+			Comment: e.AclEntryComment,
+		})
+	}
+	for _, t := range resp.Tags.Tag {
+		acl.Tags = append(acl.Tags, tag.Tag{Key: t.TagKey, Value: t.TagValue})
+	}
+	return acl, nil
+}
+
+func (p SLBProvider) AddAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	batches, err := batchAclEntries(entries)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		req := slb.CreateAddAccessControlListEntryRequest()
+		req.AclId = aclId
+		req.AclEntrys = batch
+		tracing.Inject(ctx, req.GetHeaders())
+		resp, err := p.auth.SLB.AddAccessControlListEntry(req)
+		if err != nil {
+			return util.SDKError("AddAccessControlListEntry", err)
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s", resp.RequestId, "AddAccessControlListEntry")
+	}
+	return nil
+}
+
+func (p SLBProvider) RemoveAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	batches, err := batchAclEntries(entries)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		req := slb.CreateRemoveAccessControlListEntryRequest()
+		req.AclId = aclId
+		req.AclEntrys = batch
+		tracing.Inject(ctx, req.GetHeaders())
+		resp, err := p.auth.SLB.RemoveAccessControlListEntry(req)
+		if err != nil {
+			return util.SDKError("RemoveAccessControlListEntry", err)
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s", resp.RequestId, "RemoveAccessControlListEntry")
+	}
+	return nil
+}
+
+func (p SLBProvider) DeleteAccessControlList(ctx context.Context, aclId string) error {
+	req := slb.CreateDeleteAccessControlListRequest()
+	req.AclId = aclId
+	tracing.Inject(ctx, req.GetHeaders())
+	resp, err := p.auth.SLB.DeleteAccessControlList(req)
+	if err != nil {
+		return util.SDKError("DeleteAccessControlList", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s", resp.RequestId, "DeleteAccessControlList")
+	return nil
+}
+
+// batchAclEntries splits entries into json strings of at most MaxAclEntryNumPerRequest entries
+func batchAclEntries(entries []model.AclEntry) ([]string, error) {
+	var ret []string
+	for i := 0; i < len(entries); i += MaxAclEntryNumPerRequest {
+		end := i + MaxAclEntryNumPerRequest
+		if end > len(entries) {
+			end = len(entries)
+		}
+		b, err := json.Marshal(entries[i:end])
+		if err != nil {
+			return nil, fmt.Errorf("marshal acl entries error: %s", err.Error())
+		}
+		ret = append(ret, string(b))
+	}
+	return ret, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	"k8s.io/klog/v2"
 )
 
@@ -15,6 +16,7 @@ func (p SLBProvider) DescribeServerCertificateById(ctx context.Context, serverCe
 	req := slb.CreateDescribeServerCertificatesRequest()
 	req.ServerCertificateId = serverCertificateId
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeServerCertificates(req)
 	if err != nil {
 		return nil, util.SDKError("DescribeServerCertificates", err)
@@ -41,6 +43,7 @@ func (p SLBProvider) DescribeDomainExtensions(ctx context.Context, lbId string,
 	req := slb.CreateDescribeDomainExtensionsRequest()
 	req.LoadBalancerId = lbId
 	req.ListenerPort = requests.NewInteger(port)
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeDomainExtensions(req)
 	if err != nil {
 		return nil, util.SDKError("DescribeDomainExtensions", err)
@@ -63,6 +66,7 @@ func (p SLBProvider) CreateDomainExtension(ctx context.Context, lbId string, por
 	req.ListenerPort = requests.NewInteger(port)
 	req.Domain = domain
 	req.ServerCertificateId = certId
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.CreateDomainExtension(req)
 	if err != nil {
 		return util.SDKError("CreateDomainExtension", err)
@@ -74,6 +78,7 @@ func (p SLBProvider) CreateDomainExtension(ctx context.Context, lbId string, por
 func (p SLBProvider) DeleteDomainExtension(ctx context.Context, id string) error {
 	req := slb.CreateDeleteDomainExtensionRequest()
 	req.DomainExtensionId = id
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DeleteDomainExtension(req)
 	if err != nil {
 		return util.SDKError("DeleteDomainExtension", err)
@@ -86,6 +91,7 @@ func (p SLBProvider) SetDomainExtensionAttribute(ctx context.Context, id string,
 	req := slb.CreateSetDomainExtensionAttributeRequest()
 	req.DomainExtensionId = id
 	req.ServerCertificateId = certId
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.SetDomainExtensionAttribute(req)
 	if err != nil {
 		return util.SDKError("SetDomainExtensionAttribute", err)
@@ -97,6 +103,7 @@ func (p SLBProvider) SetDomainExtensionAttribute(ctx context.Context, id string,
 // DescribeServerCertificates used for e2etest
 func (p SLBProvider) DescribeServerCertificates(ctx context.Context) ([]string, error) {
 	req := slb.CreateDescribeServerCertificatesRequest()
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeServerCertificates(req)
 	if err != nil {
 		return nil, err
@@ -111,6 +118,7 @@ func (p SLBProvider) DescribeServerCertificates(ctx context.Context) ([]string,
 // DescribeCACertificates used for e2etest
 func (p SLBProvider) DescribeCACertificates(ctx context.Context) ([]string, error) {
 	req := slb.CreateDescribeCACertificatesRequest()
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeCACertificates(req)
 	if err != nil {
 		return nil, err
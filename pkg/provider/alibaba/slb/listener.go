@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/alibabacloud-go/tea/tea"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	"k8s.io/klog/v2"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
@@ -22,6 +23,7 @@ func (p SLBProvider) DescribeLoadBalancerListeners(ctx context.Context, lbId str
 
 	var respListeners []slb.ListenerInDescribeLoadBalancerListeners
 	for {
+		tracing.Inject(ctx, req.GetHeaders())
 		resp, err := p.auth.SLB.DescribeLoadBalancerListeners(req)
 		if err != nil {
 			return nil, util.SDKError("DescribeLoadBalancerListeners", err)
@@ -80,6 +82,7 @@ func (p SLBProvider) StartLoadBalancerListener(ctx context.Context, lbId string,
 	req.LoadBalancerId = lbId
 	req.ListenerPort = requests.NewInteger(port)
 	req.ListenerProtocol = proto
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.StartLoadBalancerListener(req)
 	return util.SDKError("StartLoadBalancerListener", err)
 }
@@ -89,6 +92,7 @@ func (p SLBProvider) StopLoadBalancerListener(ctx context.Context, lbId string,
 	req.LoadBalancerId = lbId
 	req.ListenerPort = requests.NewInteger(port)
 	req.ListenerProtocol = proto
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.StopLoadBalancerListener(req)
 	return util.SDKError("StopLoadBalancerListener", err)
 }
@@ -99,6 +103,7 @@ func (p SLBProvider) DeleteLoadBalancerListener(ctx context.Context, lbId string
 	req.ListenerPort = requests.NewInteger(port)
 	req.ListenerProtocol = proto
 
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.DeleteLoadBalancerListener(req)
 	return util.SDKError("DeleteLoadBalancerListener", err)
 
@@ -110,6 +115,7 @@ func (p SLBProvider) CreateLoadBalancerTCPListener(
 	req.LoadBalancerId = lbId
 	setGenericListenerValue(req, &listener)
 	setTCPListenerValue(req, &listener)
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.CreateLoadBalancerTCPListener(req)
 	return util.SDKError("CreateLoadBalancerTCPListener", err)
 }
@@ -121,6 +127,7 @@ func (p SLBProvider) SetLoadBalancerTCPListenerAttribute(
 	req.VServerGroup = string(model.OnFlag)
 	setGenericListenerValue(req, &listener)
 	setTCPListenerValue(req, &listener)
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.SetLoadBalancerTCPListenerAttribute(req)
 	return util.SDKError("SetLoadBalancerTCPListenerAttribute", err)
 }
@@ -131,6 +138,7 @@ func (p SLBProvider) CreateLoadBalancerUDPListener(
 	req.LoadBalancerId = lbId
 	setGenericListenerValue(req, &listener)
 	setUDPListenerValue(req, &listener)
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.CreateLoadBalancerUDPListener(req)
 	return util.SDKError("CreateLoadBalancerUDPListener", err)
 }
@@ -142,6 +150,7 @@ func (p SLBProvider) SetLoadBalancerUDPListenerAttribute(
 	req.VServerGroup = string(model.OnFlag)
 	setGenericListenerValue(req, &listener)
 	setUDPListenerValue(req, &listener)
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.SetLoadBalancerUDPListenerAttribute(req)
 	return util.SDKError("SetLoadBalancerUDPListenerAttribute", err)
 }
@@ -159,6 +168,7 @@ func (p SLBProvider) CreateLoadBalancerHTTPListener(
 	if listener.ForwardPort != 0 {
 		req.ForwardPort = requests.NewInteger(listener.ForwardPort)
 	}
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.CreateLoadBalancerHTTPListener(req)
 	return util.SDKError("CreateLoadBalancerHTTPListener", err)
 }
@@ -170,6 +180,7 @@ func (p SLBProvider) SetLoadBalancerHTTPListenerAttribute(
 	req.VServerGroup = string(model.OnFlag)
 	setGenericListenerValue(req, &listener)
 	setHTTPListenerValue(req, &listener)
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.SetLoadBalancerHTTPListenerAttribute(req)
 	return util.SDKError("SetLoadBalancerHTTPListenerAttribute", err)
 }
@@ -180,6 +191,7 @@ func (p SLBProvider) CreateLoadBalancerHTTPSListener(
 	req.LoadBalancerId = lbId
 	setGenericListenerValue(req, &listener)
 	setHTTPSListenerValue(req, &listener)
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.CreateLoadBalancerHTTPSListener(req)
 	return util.SDKError("CreateLoadBalancerHTTPSListener", err)
 }
@@ -191,6 +203,7 @@ func (p SLBProvider) SetLoadBalancerHTTPSListenerAttribute(
 	req.VServerGroup = string(model.OnFlag)
 	setGenericListenerValue(req, &listener)
 	setHTTPSListenerValue(req, &listener)
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.SetLoadBalancerHTTPSListenerAttribute(req)
 	return util.SDKError("SetLoadBalancerHTTPSListenerAttribute", err)
 }
@@ -13,12 +13,15 @@ import (
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	"k8s.io/klog/v2"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
 )
 
+const describeLoadBalancersPageSize = 100
+
 func NewLBProvider(
 	auth *base.ClientMgr,
 ) *SLBProvider {
@@ -51,7 +54,7 @@ func (p SLBProvider) FindLoadBalancer(ctx context.Context, mdl *model.LoadBalanc
 	}
 
 	// 2. find by tags
-	err := p.findLoadBalancerByTag(mdl)
+	err := p.findLoadBalancerByTag(ctx, mdl)
 	if err != nil {
 		return err
 	}
@@ -62,7 +65,7 @@ func (p SLBProvider) FindLoadBalancer(ctx context.Context, mdl *model.LoadBalanc
 	}
 
 	// 3. find by loadbalancer name
-	err = p.FindLoadBalancerByName(mdl)
+	err = p.findLoadBalancerByName(ctx, mdl)
 	if err != nil {
 		return err
 	}
@@ -76,7 +79,7 @@ func (p SLBProvider) FindLoadBalancer(ctx context.Context, mdl *model.LoadBalanc
 	return nil
 }
 
-func (p SLBProvider) findLoadBalancerByTag(mdl *model.LoadBalancer) error {
+func (p SLBProvider) findLoadBalancerByTag(ctx context.Context, mdl *model.LoadBalancer) error {
 	var tags []slb.Tag
 	for _, t := range mdl.LoadBalancerAttribute.Tags {
 		tags = append(tags, slb.Tag{
@@ -92,6 +95,7 @@ func (p SLBProvider) findLoadBalancerByTag(mdl *model.LoadBalancer) error {
 	klog.Infof("[%s] try to find loadbalancer by tag %s", mdl.NamespacedName, string(items))
 	req := slb.CreateDescribeLoadBalancersRequest()
 	req.Tags = string(items)
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeLoadBalancers(req)
 	if err != nil {
 		return fmt.Errorf("[%s] find loadbalancer by tag error: %s", mdl.NamespacedName,
@@ -118,7 +122,48 @@ func (p SLBProvider) findLoadBalancerByTag(mdl *model.LoadBalancer) error {
 	return nil
 }
 
+func (p SLBProvider) ListLoadBalancers(ctx context.Context, tags []tag.Tag) ([]*model.LoadBalancer, error) {
+	var reqTags []slb.Tag
+	for _, t := range tags {
+		reqTags = append(reqTags, slb.Tag{
+			TagKey:   t.Key,
+			TagValue: t.Value,
+		})
+	}
+	items, err := json.Marshal(reqTags)
+	if err != nil {
+		return nil, fmt.Errorf("tags marshal error: %s", err.Error())
+	}
+	req := slb.CreateDescribeLoadBalancersRequest()
+	req.Tags = string(items)
+	req.PageSize = requests.NewInteger(describeLoadBalancersPageSize)
+
+	var ret []*model.LoadBalancer
+	for pageNumber := 1; ; pageNumber++ {
+		req.PageNumber = requests.NewInteger(pageNumber)
+		tracing.Inject(ctx, req.GetHeaders())
+		resp, err := p.auth.SLB.DescribeLoadBalancers(req)
+		if err != nil {
+			return nil, util.SDKError("DescribeLoadBalancers", err)
+		}
+		klog.V(5).Infof("RequestId: %s, API: %s, page: %d", resp.RequestId, "DescribeLoadBalancers", pageNumber)
+		for _, lb := range resp.LoadBalancers.LoadBalancer {
+			mdl := &model.LoadBalancer{}
+			loadResponse(lb, mdl)
+			ret = append(ret, mdl)
+		}
+		if pageNumber*describeLoadBalancersPageSize >= resp.TotalCount || len(resp.LoadBalancers.LoadBalancer) == 0 {
+			break
+		}
+	}
+	return ret, nil
+}
+
 func (p SLBProvider) FindLoadBalancerByName(mdl *model.LoadBalancer) error {
+	return p.findLoadBalancerByName(context.TODO(), mdl)
+}
+
+func (p SLBProvider) findLoadBalancerByName(ctx context.Context, mdl *model.LoadBalancer) error {
 	if mdl.LoadBalancerAttribute.LoadBalancerName == "" {
 		klog.Warningf("[%s] find loadbalancer by name error: loadbalancer name is empty.", mdl.NamespacedName.String())
 		return nil
@@ -127,6 +172,7 @@ func (p SLBProvider) FindLoadBalancerByName(mdl *model.LoadBalancer) error {
 		mdl.NamespacedName, mdl.LoadBalancerAttribute.LoadBalancerName)
 	req := slb.CreateDescribeLoadBalancersRequest()
 	req.LoadBalancerName = mdl.LoadBalancerAttribute.LoadBalancerName
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeLoadBalancers(req)
 	if err != nil {
 		return fmt.Errorf("[%s] find loadbalancer by name %s error: %s", mdl.NamespacedName,
@@ -160,6 +206,7 @@ func (p SLBProvider) CreateLoadBalancer(ctx context.Context, mdl *model.LoadBala
 	if ascmContext := os.Getenv("X-ACSPROXY-ASCM-CONTEXT"); ascmContext != "" {
 		req.GetHeaders()["x-acsproxy-ascm-context"] = ascmContext
 	}
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.CreateLoadBalancer(req)
 	if err != nil {
 		return util.SDKError("CreateLoadBalancer", err)
@@ -174,6 +221,7 @@ func (p SLBProvider) CreateLoadBalancer(ctx context.Context, mdl *model.LoadBala
 func (p SLBProvider) DescribeLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error {
 	req := slb.CreateDescribeLoadBalancerAttributeRequest()
 	req.LoadBalancerId = mdl.LoadBalancerAttribute.LoadBalancerId
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeLoadBalancerAttribute(req)
 	if err != nil {
 		return util.SDKError("DescribeLoadBalancerAttribute", err)
@@ -191,6 +239,7 @@ func (p SLBProvider) DescribeLoadBalancer(ctx context.Context, mdl *model.LoadBa
 func (p SLBProvider) DeleteLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error {
 	req := slb.CreateDeleteLoadBalancerRequest()
 	req.LoadBalancerId = mdl.LoadBalancerAttribute.LoadBalancerId
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.DeleteLoadBalancer(req)
 	return util.SDKError("DeleteLoadBalancer", err)
 }
@@ -199,6 +248,7 @@ func (p SLBProvider) SetLoadBalancerDeleteProtection(ctx context.Context, lbId s
 	req := slb.CreateSetLoadBalancerDeleteProtectionRequest()
 	req.LoadBalancerId = lbId
 	req.DeleteProtection = flag
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.SetLoadBalancerDeleteProtection(req)
 	return util.SDKError("SetLoadBalancerDeleteProtection", err)
 }
@@ -207,6 +257,7 @@ func (p SLBProvider) ModifyLoadBalancerInstanceSpec(ctx context.Context, lbId st
 	req := slb.CreateModifyLoadBalancerInstanceSpecRequest()
 	req.LoadBalancerId = lbId
 	req.LoadBalancerSpec = spec
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.ModifyLoadBalancerInstanceSpec(req)
 	return util.SDKError("ModifyLoadBalancerInstanceSpec", err)
 }
@@ -215,6 +266,7 @@ func (p SLBProvider) SetLoadBalancerName(ctx context.Context, lbId string, name
 	req := slb.CreateSetLoadBalancerNameRequest()
 	req.LoadBalancerId = lbId
 	req.LoadBalancerName = name
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.SetLoadBalancerName(req)
 	return util.SDKError("SetLoadBalancerName", err)
 }
@@ -224,6 +276,7 @@ func (p SLBProvider) ModifyLoadBalancerInternetSpec(ctx context.Context, lbId st
 	req.LoadBalancerId = lbId
 	req.InternetChargeType = chargeType
 	req.Bandwidth = requests.NewInteger(bandwidth)
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.ModifyLoadBalancerInternetSpec(req)
 	return util.SDKError("ModifyLoadBalancerInternetSpec", err)
 }
@@ -235,6 +288,7 @@ func (p SLBProvider) SetLoadBalancerModificationProtection(ctx context.Context,
 	if flag == string(model.OnFlag) {
 		req.ModificationProtectionReason = model.ModificationProtectionReason
 	}
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.SetLoadBalancerModificationProtection(req)
 	return util.SDKError("SetLoadBalancerModificationProtection", err)
 }
@@ -246,6 +300,7 @@ func (p SLBProvider) ModifyLoadBalancerInstanceChargeType(ctx context.Context, l
 	if spec != "" {
 		req.LoadBalancerSpec = spec
 	}
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.ModifyLoadBalancerInstanceChargeType(req)
 	return util.SDKError("ModifyLoadBalancerInstanceChargeType", err)
 }
@@ -263,6 +318,7 @@ func (p SLBProvider) TagCLBResource(ctx context.Context, resourceId string, tags
 	}
 	req.Tag = &reqTags
 
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.TagResources(req)
 	return util.SDKError("TagResources", err)
 }
@@ -272,6 +328,7 @@ func (p SLBProvider) ListCLBTagResources(ctx context.Context, lbId string) ([]ta
 	req.ResourceId = &[]string{lbId}
 	req.ResourceType = "instance"
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.ListTagResources(req)
 	if err != nil {
 		return nil, util.SDKError("ListTagResources", err)
@@ -291,6 +348,7 @@ func (p SLBProvider) UntagResources(ctx context.Context, lbId string, tagKey *[]
 	req.ResourceId = &[]string{lbId}
 	req.ResourceType = "instance"
 	req.TagKey = tagKey
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.UntagResources(req)
 	return err
 }
@@ -300,6 +358,7 @@ func (p SLBProvider) DescribeAvailableResource(ctx context.Context, addressType,
 	req := slb.CreateDescribeAvailableResourceRequest()
 	req.AddressType = addressType
 	req.AddressIPVersion = AddressIPVersion
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeAvailableResource(req)
 	if err != nil {
 		return nil, err
@@ -307,21 +366,11 @@ func (p SLBProvider) DescribeAvailableResource(ctx context.Context, addressType,
 	return resp.AvailableResources.AvailableResource, nil
 }
 
-// CreateAccessControlList used for e2etest
-func (p SLBProvider) CreateAccessControlList(ctx context.Context, aclName string) (string, error) {
-	req := slb.CreateCreateAccessControlListRequest()
-	req.AclName = aclName
-	resp, err := p.auth.SLB.CreateAccessControlList(req)
-	if err != nil {
-		return "", err
-	}
-	return resp.AclId, nil
-}
-
 // DescribeAccessControlList used for e2etest
 func (p SLBProvider) DescribeAccessControlList(ctx context.Context, aclName string) (string, error) {
 	req := slb.CreateDescribeAccessControlListsRequest()
 	req.AclName = aclName
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeAccessControlLists(req)
 	if err != nil {
 		if strings.Contains(err.Error(), "NotFound") {
@@ -335,14 +384,6 @@ func (p SLBProvider) DescribeAccessControlList(ctx context.Context, aclName stri
 	return resp.Acls.Acl[0].AclId, nil
 }
 
-// DeleteAccessControlList used for e2etest
-func (p SLBProvider) DeleteAccessControlList(ctx context.Context, aclId string) error {
-	req := slb.CreateDeleteAccessControlListRequest()
-	req.AclId = aclId
-	_, err := p.auth.SLB.DeleteAccessControlList(req)
-	return err
-}
-
 func setRequest(request *slb.CreateLoadBalancerRequest, mdl *model.LoadBalancer) {
 	if mdl.LoadBalancerAttribute.AddressType != "" {
 		request.AddressType = string(mdl.LoadBalancerAttribute.AddressType)
@@ -2,6 +2,7 @@ package slb
 
 import (
 	"context"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	"k8s.io/klog/v2"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
@@ -13,6 +14,7 @@ import (
 func (p SLBProvider) DescribeVServerGroups(ctx context.Context, lbId string) ([]model.VServerGroup, error) {
 	req := slb.CreateDescribeVServerGroupsRequest()
 	req.LoadBalancerId = lbId
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeVServerGroups(req)
 	if err != nil {
 		return nil, util.SDKError("DescribeVServerGroups", err)
@@ -40,6 +42,7 @@ func (p SLBProvider) CreateVServerGroup(ctx context.Context, vg *model.VServerGr
 	req.LoadBalancerId = lbId
 	req.VServerGroupName = vg.VGroupName
 	// create vserver group with empty backends to avoid reach the limit of backends per action
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.CreateVServerGroup(req)
 	if err != nil {
 		return util.SDKError("CreateVServerGroup", err)
@@ -52,6 +55,7 @@ func (p SLBProvider) CreateVServerGroup(ctx context.Context, vg *model.VServerGr
 func (p SLBProvider) DescribeVServerGroupAttribute(ctx context.Context, vGroupId string) (model.VServerGroup, error) {
 	req := slb.CreateDescribeVServerGroupAttributeRequest()
 	req.VServerGroupId = vGroupId
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DescribeVServerGroupAttribute(req)
 	if err != nil {
 		return model.VServerGroup{}, util.SDKError("DescribeVServerGroupAttribute", err)
@@ -65,6 +69,7 @@ func (p SLBProvider) DescribeVServerGroupAttribute(ctx context.Context, vGroupId
 func (p SLBProvider) DeleteVServerGroup(ctx context.Context, vGroupId string) error {
 	req := slb.CreateDeleteVServerGroupRequest()
 	req.VServerGroupId = vGroupId
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.DeleteVServerGroup(req)
 	if err != nil {
 		return util.SDKError("DeleteVServerGroup", err)
@@ -77,6 +82,7 @@ func (p SLBProvider) AddVServerGroupBackendServers(ctx context.Context, vGroupId
 	req := slb.CreateAddVServerGroupBackendServersRequest()
 	req.VServerGroupId = vGroupId
 	req.BackendServers = backends
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.AddVServerGroupBackendServers(req)
 	if err != nil {
 		return util.SDKError("AddVServerGroupBackendServers", err)
@@ -90,6 +96,7 @@ func (p SLBProvider) RemoveVServerGroupBackendServers(ctx context.Context, vGrou
 	req := slb.CreateRemoveVServerGroupBackendServersRequest()
 	req.VServerGroupId = vGroupId
 	req.BackendServers = backends
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := p.auth.SLB.RemoveVServerGroupBackendServers(req)
 	if err != nil {
 		return util.SDKError("RemoveVServerGroupBackendServers", err)
@@ -102,6 +109,7 @@ func (p SLBProvider) SetVServerGroupAttribute(ctx context.Context, vGroupId stri
 	req := slb.CreateSetVServerGroupAttributeRequest()
 	req.VServerGroupId = vGroupId
 	req.BackendServers = backends
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.SetVServerGroupAttribute(req)
 	return util.SDKError("SetVServerGroupAttribute", err)
 }
@@ -111,10 +119,34 @@ func (p SLBProvider) ModifyVServerGroupBackendServers(ctx context.Context, vGrou
 	req.VServerGroupId = vGroupId
 	req.OldBackendServers = old
 	req.NewBackendServers = new
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := p.auth.SLB.ModifyVServerGroupBackendServers(req)
 	return util.SDKError("ModifyVServerGroupBackendServers", err)
 }
 
+func (p SLBProvider) DescribeHealthStatus(ctx context.Context, lbId string) ([]model.BackendHealthStatus, error) {
+	req := slb.CreateDescribeHealthStatusRequest()
+	req.LoadBalancerId = lbId
+	tracing.Inject(ctx, req.GetHeaders())
+	resp, err := p.auth.SLB.DescribeHealthStatus(req)
+	if err != nil {
+		return nil, util.SDKError("DescribeHealthStatus", err)
+	}
+	klog.V(5).Infof("RequestId: %s, API: %s, lbId: %s", resp.RequestId, "DescribeHealthStatus", lbId)
+	var status []model.BackendHealthStatus
+	for _, b := range resp.BackendServers.BackendServer {
+		status = append(status, model.BackendHealthStatus{
+			ListenerPort:     b.ListenerPort,
+			ListenerProtocol: b.Protocol,
+			ServerId:         b.ServerId,
+			ServerIp:         b.ServerIp,
+			Port:             b.Port,
+			Status:           model.HealthStatus(b.ServerHealthStatus),
+		})
+	}
+	return status, nil
+}
+
 func setVServerGroupFromResponse(resp *slb.DescribeVServerGroupAttributeResponse) model.VServerGroup {
 	vg := model.VServerGroup{
 		VGroupId:   resp.VServerGroupId,
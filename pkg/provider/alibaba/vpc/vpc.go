@@ -9,10 +9,13 @@ import (
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util/tracing"
 	"k8s.io/klog/v2"
 )
 
@@ -36,6 +39,7 @@ type VPCProvider struct {
 func (r *VPCProvider) ListRouteTables(ctx context.Context, vpcID string) ([]string, error) {
 	tableListRequest := vpc.CreateDescribeRouteTableListRequest()
 	tableListRequest.VpcId = vpcID
+	tracing.Inject(ctx, tableListRequest.GetHeaders())
 	resp, err := r.auth.VPC.DescribeRouteTableList(tableListRequest)
 	if err != nil {
 		return nil, fmt.Errorf("error describe vpc: %v route tables, error: %v", vpcID, err)
@@ -64,7 +68,12 @@ func (r *VPCProvider) FindRoute(ctx context.Context, table, provID, cidr string)
 
 	if cidr != "" {
 		describeRouteEntryRequest.DestinationCidrBlock = cidr
+		// only ipv4 route entries are returned if ip version is not specified
+		if isIPv6CIDR(cidr) {
+			describeRouteEntryRequest.IpVersion = model.RouteIPVersionIPv6
+		}
 	}
+	tracing.Inject(ctx, describeRouteEntryRequest.GetHeaders())
 	resp, err := r.auth.VPC.DescribeRouteEntryList(describeRouteEntryRequest)
 	if err != nil {
 		return nil, fmt.Errorf("error describe route entry list: %v", err)
@@ -109,6 +118,7 @@ func (r *VPCProvider) CreateRoute(
 		return nil, fmt.Errorf("invalid provide id: %v, err: %v", provideID, err)
 	}
 	createRouteEntryRequest.NextHopId = instance
+	tracing.Inject(ctx, createRouteEntryRequest.GetHeaders())
 	_, err = r.auth.VPC.CreateRouteEntry(createRouteEntryRequest)
 	if err != nil {
 		return nil, fmt.Errorf("error create route entry for %s, %s, error: %v", provideID, destinationCIDR, err)
@@ -143,6 +153,7 @@ func (r *VPCProvider) CreateRoutes(ctx context.Context, table string, routes []*
 	createRouteEntriesRequest := vpc.CreateCreateRouteEntriesRequest()
 	createRouteEntriesRequest.RouteEntries = &routeEntries
 
+	tracing.Inject(ctx, createRouteEntriesRequest.GetHeaders())
 	resp, err := r.auth.VPC.CreateRouteEntries(createRouteEntriesRequest)
 	if err != nil {
 		return nil, nil, err
@@ -158,7 +169,7 @@ func (r *VPCProvider) CreateRoutes(ctx context.Context, table string, routes []*
 			continue
 		}
 		for _, f := range resp.FailedRouteEntries {
-			if f.NextHop == ins {
+			if isFailedRouteEntry(f.NextHop, f.DstCidrBlock, ins, r.DestinationCIDR) {
 				foundFailed = true
 				statuses = append(statuses, prvd.RouteUpdateStatus{
 					Route:         r,
@@ -188,6 +199,7 @@ func (r *VPCProvider) DeleteRoute(ctx context.Context, table, provideID, destina
 		return fmt.Errorf("invalid provide id: %v, err: %v", provideID, err)
 	}
 	deleteRouteEntryRequest.NextHopId = instance
+	tracing.Inject(ctx, deleteRouteEntryRequest.GetHeaders())
 	_, err = r.auth.VPC.DeleteRouteEntry(deleteRouteEntryRequest)
 	if err != nil {
 		if strings.Contains(err.Error(), "InvalidRouteEntry.NotFound") {
@@ -221,6 +233,7 @@ func (r *VPCProvider) DeleteRoutes(ctx context.Context, table string, routes []*
 	deleteRouteEntriesRequest := vpc.CreateDeleteRouteEntriesRequest()
 	deleteRouteEntriesRequest.RouteEntries = &routeEntries
 
+	tracing.Inject(ctx, deleteRouteEntriesRequest.GetHeaders())
 	resp, err := r.auth.VPC.DeleteRouteEntries(deleteRouteEntriesRequest)
 	if err != nil {
 		return nil, err
@@ -237,7 +250,7 @@ func (r *VPCProvider) DeleteRoutes(ctx context.Context, table string, routes []*
 			continue
 		}
 		for _, f := range resp.FailedRouteEntries {
-			if f.NextHop == ins {
+			if isFailedRouteEntry(f.NextHop, f.DstCidrBlock, ins, r.DestinationCIDR) {
 				foundFailed = true
 				statuses = append(statuses, prvd.RouteUpdateStatus{
 					Route:         r,
@@ -259,21 +272,29 @@ func (r *VPCProvider) DeleteRoutes(ctx context.Context, table string, routes []*
 }
 
 func (r *VPCProvider) ListRoute(ctx context.Context, table string) (routes []*model.Route, err error) {
-	err = r.listRouteBatch(table, "", &routes)
-	if err != nil {
-		return nil,
-			fmt.Errorf("table %s get route entries error ,err %s", table, err.Error())
+	ipVersions := []string{model.RouteIPVersionIPv4}
+	if utilfeature.DefaultFeatureGate.Enabled(ctrlCfg.IPv6DualStack) {
+		ipVersions = append(ipVersions, model.RouteIPVersionIPv6)
+	}
+	for _, ipVersion := range ipVersions {
+		err = r.listRouteBatch(ctx, table, ipVersion, "", &routes)
+		if err != nil {
+			return nil,
+				fmt.Errorf("table %s get %s route entries error ,err %s", table, ipVersion, err.Error())
+		}
 	}
 	return routes, nil
 }
 
-func (r *VPCProvider) listRouteBatch(table, nextToken string, routes *[]*model.Route) error {
+func (r *VPCProvider) listRouteBatch(ctx context.Context, table, ipVersion, nextToken string, routes *[]*model.Route) error {
 	routeEntryListRequest := vpc.CreateDescribeRouteEntryListRequest()
 	routeEntryListRequest.NextHopType = model.RouteNextHopTypeInstance
 	routeEntryListRequest.RouteEntryType = model.RouteEntryTypeCustom
 	routeEntryListRequest.RouteTableId = table
+	routeEntryListRequest.IpVersion = ipVersion
 	routeEntryListRequest.NextToken = nextToken
 	routeEntryListRequest.MaxResult = requests.NewInteger(model.RouteMaxQueryRouteEntry)
+	tracing.Inject(ctx, routeEntryListRequest.GetHeaders())
 	routeEntryListResponse, err := r.auth.VPC.DescribeRouteEntryList(routeEntryListRequest)
 	if err != nil {
 		return fmt.Errorf("describe route entry list error, err %v", err)
@@ -281,7 +302,7 @@ func (r *VPCProvider) listRouteBatch(table, nextToken string, routes *[]*model.R
 	klog.V(5).Infof("RequestId: %s, API: %s, tableId: %s",
 		routeEntryListResponse.RequestId, "DescribeRouteEntryList", table)
 	routeEntries := routeEntryListResponse.RouteEntrys.RouteEntry
-	if len(routeEntries) <= 0 {
+	if len(routeEntries) <= 0 && ipVersion == model.RouteIPVersionIPv4 {
 		klog.Warningf("alicloud: table [%s] has 0 route entry.", table)
 	}
 	for _, e := range routeEntries {
@@ -293,7 +314,7 @@ func (r *VPCProvider) listRouteBatch(table, nextToken string, routes *[]*model.R
 			// skip none Instance route
 			strings.ToLower(e.NextHops.NextHop[0].NextHopType) != "instance" ||
 			// skip DNAT route
-			e.DestinationCidrBlock == "0.0.0.0/0" {
+			e.DestinationCidrBlock == "0.0.0.0/0" || e.DestinationCidrBlock == "::/0" {
 			continue
 		}
 		pvid, err := r.providerIDFromInstanceId(e.NextHops.NextHop[0].NextHopId)
@@ -308,11 +329,25 @@ func (r *VPCProvider) listRouteBatch(table, nextToken string, routes *[]*model.R
 		*routes = append(*routes, route)
 	}
 	if routeEntryListResponse.NextToken != "" {
-		return r.listRouteBatch(table, routeEntryListResponse.NextToken, routes)
+		return r.listRouteBatch(ctx, table, ipVersion, routeEntryListResponse.NextToken, routes)
 	}
 	return nil
 }
 
+// isFailedRouteEntry returns true if the failed entry is the route. The destination cidr is compared as well
+// since the ipv4 and ipv6 routes of a dual-stack node have the same next hop.
+func isFailedRouteEntry(failedNextHop, failedCIDR, nextHop, cidr string) bool {
+	if failedNextHop != nextHop {
+		return false
+	}
+	return failedCIDR == "" || failedCIDR == cidr
+}
+
+func isIPv6CIDR(cidr string) bool {
+	ip, _, err := net.ParseCIDR(cidr)
+	return err == nil && ip.To4() == nil
+}
+
 func (r *VPCProvider) DescribeEipAddresses(ctx context.Context, instanceType string, instanceId string) (
 	[]string, error,
 ) {
@@ -328,6 +363,7 @@ func (r *VPCProvider) DescribeEipAddresses(ctx context.Context, instanceType str
 	for {
 		req.PageSize = requests.NewInteger(next.PageSize)
 		req.PageNumber = requests.NewInteger(next.PageNumber)
+		tracing.Inject(ctx, req.GetHeaders())
 		resp, err := r.auth.VPC.DescribeEipAddresses(req)
 		if err != nil {
 			return nil, err
@@ -353,6 +389,7 @@ func (r *VPCProvider) DescribeEipAddresses(ctx context.Context, instanceType str
 func (r *VPCProvider) DescribeVpcCIDRBlock(ctx context.Context, vpcId string, ipVersion model.AddressIPVersionType) ([]*net.IPNet, error) {
 	req := vpc.CreateDescribeVpcAttributeRequest()
 	req.VpcId = vpcId
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := r.auth.VPC.DescribeVpcAttribute(req)
 	if err != nil {
 		return nil, err
@@ -402,6 +439,7 @@ func (r *VPCProvider) DescribeVswitchByID(ctx context.Context, vswId string) (vp
 	req := vpc.CreateDescribeVSwitchesRequest()
 	req.VSwitchId = vswId
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := r.auth.VPC.DescribeVSwitches(req)
 	if err != nil {
 		return vpc.VSwitch{}, err
@@ -426,6 +464,7 @@ func (r *VPCProvider) DescribeVSwitches(ctx context.Context, vpcID string) ([]vp
 	for {
 		req.PageSize = requests.NewInteger(next.PageSize)
 		req.PageNumber = requests.NewInteger(next.PageNumber)
+		tracing.Inject(ctx, req.GetHeaders())
 		resp, err := r.auth.VPC.DescribeVSwitches(req)
 		if err != nil {
 			return nil, err
@@ -451,6 +490,7 @@ func (r *VPCProvider) CreateRouteTable(ctx context.Context, vpcId string, name s
 	req := vpc.CreateCreateRouteTableRequest()
 	req.VpcId = vpcId
 	req.RouteTableName = name
+	tracing.Inject(ctx, req.GetHeaders())
 	return r.auth.VPC.CreateRouteTable(req)
 }
 
@@ -460,6 +500,7 @@ func (r *VPCProvider) DeleteRouteTable(ctx context.Context, routeTableId string)
 ) {
 	req := vpc.CreateDeleteRouteTableRequest()
 	req.RouteTableId = routeTableId
+	tracing.Inject(ctx, req.GetHeaders())
 	return r.auth.VPC.DeleteRouteTable(req)
 }
 
@@ -467,6 +508,7 @@ func (r *VPCProvider) DeleteRouteTable(ctx context.Context, routeTableId string)
 func (r *VPCProvider) DescribeRouteTableList(ctx context.Context, vpcId string) ([]string, error) {
 	req := vpc.CreateDescribeRouteTableListRequest()
 	req.VpcId = vpcId
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := r.auth.VPC.DescribeRouteTableList(req)
 	if err != nil {
 		return nil, err
@@ -483,6 +525,7 @@ func (r *VPCProvider) DescribeEipIdByName(ctx context.Context, name string) (str
 	req := vpc.CreateDescribeEipAddressesRequest()
 	req.EipName = name
 
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := r.auth.VPC.DescribeEipAddresses(req)
 	if err != nil {
 		if strings.Contains(err.Error(), "NotFound") {
@@ -502,6 +545,7 @@ func (r *VPCProvider) DescribeEipIdByName(ctx context.Context, name string) (str
 func (r *VPCProvider) AllocateEipAddress(ctx context.Context, name string) (string, error) {
 	req := vpc.CreateAllocateEipAddressRequest()
 	req.Name = name
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := r.auth.VPC.AllocateEipAddress(req)
 	if err != nil {
 		return "", err
@@ -513,6 +557,7 @@ func (r *VPCProvider) AllocateEipAddress(ctx context.Context, name string) (stri
 func (r *VPCProvider) ReleaseEipAddress(ctx context.Context, id string) error {
 	req := vpc.CreateReleaseEipAddressRequest()
 	req.AllocationId = id
+	tracing.Inject(ctx, req.GetHeaders())
 	_, err := r.auth.VPC.ReleaseEipAddress(req)
 	return err
 }
@@ -521,6 +566,7 @@ func (r *VPCProvider) ReleaseEipAddress(ctx context.Context, id string) error {
 func (r *VPCProvider) DescribeVSwitchCIDRBlock(ctx context.Context, vswId string) (string, error) {
 	req := vpc.CreateDescribeVSwitchAttributesRequest()
 	req.VSwitchId = vswId
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := r.auth.VPC.DescribeVSwitchAttributes(req)
 	if err != nil {
 		return "", err
@@ -533,6 +579,7 @@ func (r *VPCProvider) CheckCanAllocateVpcPrivateIpAddress(ctx context.Context, v
 	req := vpc.CreateCheckCanAllocateVpcPrivateIpAddressRequest()
 	req.VSwitchId = vswId
 	req.PrivateIpAddress = ipAddress
+	tracing.Inject(ctx, req.GetHeaders())
 	resp, err := r.auth.VPC.CheckCanAllocateVpcPrivateIpAddress(req)
 	if err != nil {
 		return false, err
@@ -2,6 +2,8 @@ package dryrun
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/tracking"
 
@@ -9,6 +11,7 @@ import (
 	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/alb"
 	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/base"
+	"k8s.io/klog/v2"
 
 	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
 )
@@ -26,93 +29,254 @@ type DryRunALB struct {
 	alb  *alb.ALBProvider
 }
 
-func (p DryRunALB) TagALBResources(request *albsdk.TagResourcesRequest) (response *albsdk.TagResourcesResponse, err error) {
-	return p.auth.ALB.TagResources(request)
+func (p DryRunALB) TagALBResources(ctx context.Context, request *albsdk.TagResourcesRequest) (response *albsdk.TagResourcesResponse, err error) {
+	tags := map[string]interface{}{}
+	if request.Tag != nil {
+		for _, t := range *request.Tag {
+			tags[t.Key] = t.Value
+		}
+	}
+	var ids []string
+	if request.ResourceId != nil {
+		ids = *request.ResourceId
+	}
+	// the request has no context, the tags are recorded in the log only
+	klog.Infof("dry run: tag alb resources %v with %v", ids, tags)
+	return albsdk.CreateTagResourcesResponse(), nil
 }
 
-func (p DryRunALB) DescribeALBZones(request *albsdk.DescribeZonesRequest) (response *albsdk.DescribeZonesResponse, err error) {
+func (p DryRunALB) DescribeALBZones(ctx context.Context, request *albsdk.DescribeZonesRequest) (response *albsdk.DescribeZonesResponse, err error) {
 	return nil, nil
 }
 func (p DryRunALB) CreateALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, trackingProvider tracking.TrackingProvider) (albmodel.LoadBalancerStatus, error) {
-	return albmodel.LoadBalancerStatus{}, nil
+	RecordChange(ctx, Change{
+		Action:   ActionCreate,
+		Resource: ResourceLoadBalancer,
+		Name:     resLB.Spec.LoadBalancerName,
+		After:    Attributes(resLB.Spec, ignoredALBFields...),
+	})
+	return albmodel.LoadBalancerStatus{LoadBalancerID: dryRunID("alb", resLB.ID())}, nil
 }
 func (p DryRunALB) ReuseALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, lbID string, trackingProvider tracking.TrackingProvider) (albmodel.LoadBalancerStatus, error) {
-	return albmodel.LoadBalancerStatus{}, nil
+	RecordChange(ctx, Change{
+		Action:   ActionUpdate,
+		Resource: ResourceLoadBalancer,
+		ID:       lbID,
+		Name:     resLB.Spec.LoadBalancerName,
+		After:    Attributes(resLB.Spec, ignoredALBFields...),
+	})
+	return albmodel.LoadBalancerStatus{LoadBalancerID: lbID}, nil
 }
 
 func (p DryRunALB) UpdateALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, sdkLB albsdk.LoadBalancer) (albmodel.LoadBalancerStatus, error) {
-	return albmodel.LoadBalancerStatus{}, nil
+	before, after := Diff(sdkLB, resLB.Spec, ignoredALBFields...)
+	if len(after) != 0 {
+		RecordChange(ctx, Change{
+			Action:   ActionUpdate,
+			Resource: ResourceLoadBalancer,
+			ID:       sdkLB.LoadBalancerId,
+			Name:     sdkLB.LoadBalancerName,
+			Before:   before,
+			After:    after,
+		})
+	}
+	return albmodel.LoadBalancerStatus{LoadBalancerID: sdkLB.LoadBalancerId, DNSName: sdkLB.DNSName}, nil
 }
 func (p DryRunALB) DeleteALB(ctx context.Context, lbID string) error {
+	RecordChange(ctx, Change{Action: ActionDelete, Resource: ResourceLoadBalancer, ID: lbID})
 	return nil
 }
 
 // ALB Listener
 func (p DryRunALB) CreateALBListener(ctx context.Context, resLS *albmodel.Listener) (albmodel.ListenerStatus, error) {
-	return albmodel.ListenerStatus{}, nil
+	lbID, _ := resLS.Spec.LoadBalancerID.Resolve(ctx)
+	RecordChange(ctx, Change{
+		Action:   ActionCreate,
+		Resource: ResourceListener,
+		Name:     fmt.Sprintf("%s:%d", resLS.Spec.ListenerProtocol, resLS.Spec.ListenerPort),
+		Parent:   lbID,
+		After:    Attributes(resLS.Spec, ignoredALBFields...),
+	})
+	return albmodel.ListenerStatus{ListenerID: dryRunID("listener", resLS.ID())}, nil
 }
 func (p DryRunALB) UpdateALBListener(ctx context.Context, resLS *albmodel.Listener, sdkLB *albsdk.Listener) (albmodel.ListenerStatus, error) {
-	return albmodel.ListenerStatus{}, nil
+	before, after := Diff(sdkLB, resLS.Spec, ignoredALBFields...)
+	if len(after) != 0 {
+		RecordChange(ctx, Change{
+			Action:   ActionUpdate,
+			Resource: ResourceListener,
+			ID:       sdkLB.ListenerId,
+			Name:     fmt.Sprintf("%s:%d", sdkLB.ListenerProtocol, sdkLB.ListenerPort),
+			Parent:   sdkLB.LoadBalancerId,
+			Before:   before,
+			After:    after,
+		})
+	}
+	return albmodel.ListenerStatus{ListenerID: sdkLB.ListenerId}, nil
 }
 func (p DryRunALB) DeleteALBListener(ctx context.Context, lsID string) error {
+	RecordChange(ctx, Change{Action: ActionDelete, Resource: ResourceListener, ID: lsID})
 	return nil
 }
 func (p DryRunALB) ListALBListeners(ctx context.Context, lbID string) ([]albsdk.Listener, error) {
-	return nil, nil
+	if isDryRunID(lbID) {
+		return nil, nil
+	}
+	return p.alb.ListALBListeners(ctx, lbID)
 }
 
 // ALB Listener Rule
 func (p DryRunALB) CreateALBListenerRule(ctx context.Context, resLR *albmodel.ListenerRule) (albmodel.ListenerRuleStatus, error) {
-	return albmodel.ListenerRuleStatus{}, nil
+	lsID, _ := resLR.Spec.ListenerID.Resolve(ctx)
+	RecordChange(ctx, Change{
+		Action:   ActionCreate,
+		Resource: ResourceRule,
+		Name:     resLR.Spec.RuleName,
+		Parent:   lsID,
+		After:    Attributes(resLR.Spec, ignoredALBFields...),
+	})
+	return albmodel.ListenerRuleStatus{RuleID: dryRunID("rule", resLR.ID())}, nil
 }
 func (p DryRunALB) CreateALBListenerRules(ctx context.Context, resLR []*albmodel.ListenerRule) (map[int]albmodel.ListenerRuleStatus, error) {
-	return nil, nil
+	status := make(map[int]albmodel.ListenerRuleStatus, len(resLR))
+	for i, lr := range resLR {
+		status[i], _ = p.CreateALBListenerRule(ctx, lr)
+	}
+	return status, nil
 }
 func (p DryRunALB) UpdateALBListenerRule(ctx context.Context, resLR *albmodel.ListenerRule, sdkLR *albsdk.Rule) (albmodel.ListenerRuleStatus, error) {
-	return albmodel.ListenerRuleStatus{}, nil
+	before, after := Diff(sdkLR, resLR.Spec, ignoredALBFields...)
+	if len(after) != 0 {
+		RecordChange(ctx, Change{
+			Action:   ActionUpdate,
+			Resource: ResourceRule,
+			ID:       sdkLR.RuleId,
+			Name:     sdkLR.RuleName,
+			Parent:   sdkLR.ListenerId,
+			Before:   before,
+			After:    after,
+		})
+	}
+	return albmodel.ListenerRuleStatus{RuleID: sdkLR.RuleId}, nil
 }
 func (p DryRunALB) UpdateALBListenerRules(ctx context.Context, matches []albmodel.ResAndSDKListenerRulePair) error {
+	for _, m := range matches {
+		_, _ = p.UpdateALBListenerRule(ctx, m.ResLR, m.SdkLR)
+	}
 	return nil
 }
 func (p DryRunALB) DeleteALBListenerRule(ctx context.Context, sdkLRId string) error {
+	RecordChange(ctx, Change{Action: ActionDelete, Resource: ResourceRule, ID: sdkLRId})
 	return nil
 }
 func (p DryRunALB) DeleteALBListenerRules(ctx context.Context, sdkLRIds []string) error {
+	for _, id := range sdkLRIds {
+		_ = p.DeleteALBListenerRule(ctx, id)
+	}
 	return nil
 }
 func (p DryRunALB) ListALBListenerRules(ctx context.Context, lsID string) ([]albsdk.Rule, error) {
-	return nil, nil
+	if isDryRunID(lsID) {
+		return nil, nil
+	}
+	return p.alb.ListALBListenerRules(ctx, lsID)
 }
 
 // ALB Server
 func (p DryRunALB) RegisterALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	for _, s := range resServers {
+		RecordChange(ctx, Change{
+			Action:   ActionCreate,
+			Resource: ResourceBackend,
+			ID:       fmt.Sprintf("%s/%s:%d", s.ServerId, s.ServerIp, s.Port),
+			Parent:   serverGroupID,
+			After:    Attributes(s, "Pod"),
+		})
+	}
 	return nil
 }
 func (p DryRunALB) DeregisterALBServers(ctx context.Context, serverGroupID string, sdkServers []albsdk.BackendServer) error {
+	for _, s := range sdkServers {
+		RecordChange(ctx, Change{
+			Action:   ActionDelete,
+			Resource: ResourceBackend,
+			ID:       fmt.Sprintf("%s/%s:%d", s.ServerId, s.ServerIp, s.Port),
+			Parent:   serverGroupID,
+			Before:   Attributes(s, "ServerGroupId", "Status"),
+		})
+	}
 	return nil
 }
 func (p DryRunALB) ReplaceALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem, sdkServers []albsdk.BackendServer) error {
+	_ = p.DeregisterALBServers(ctx, serverGroupID, sdkServers)
+	return p.RegisterALBServers(ctx, serverGroupID, resServers)
+}
+func (p DryRunALB) UpdateALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	for _, s := range resServers {
+		RecordChange(ctx, Change{
+			Action:   ActionUpdate,
+			Resource: ResourceBackend,
+			ID:       fmt.Sprintf("%s/%s:%d", s.ServerId, s.ServerIp, s.Port),
+			Parent:   serverGroupID,
+			After:    Attributes(s, "Pod"),
+		})
+	}
 	return nil
 }
 func (p DryRunALB) ListALBServers(ctx context.Context, serverGroupID string) ([]albsdk.BackendServer, error) {
-	return nil, nil
+	if isDryRunID(serverGroupID) {
+		return nil, nil
+	}
+	return p.alb.ListALBServers(ctx, serverGroupID)
 }
 
 // ALB ServerGroup
 func (p DryRunALB) CreateALBServerGroup(ctx context.Context, resSGP *albmodel.ServerGroup, trackingProvider tracking.TrackingProvider) (albmodel.ServerGroupStatus, error) {
-	return albmodel.ServerGroupStatus{}, nil
+	RecordChange(ctx, Change{
+		Action:   ActionCreate,
+		Resource: ResourceServerGroup,
+		Name:     resSGP.Spec.ServerGroupName,
+		After:    Attributes(resSGP.Spec.ALBServerGroupSpec, ignoredALBFields...),
+	})
+	return albmodel.ServerGroupStatus{ServerGroupID: dryRunID("sgp", resSGP.ID())}, nil
 }
 func (p DryRunALB) UpdateALBServerGroup(ctx context.Context, resSGP *albmodel.ServerGroup, sdkSGP albmodel.ServerGroupWithTags) (albmodel.ServerGroupStatus, error) {
-	return albmodel.ServerGroupStatus{}, nil
+	before, after := Diff(sdkSGP.ServerGroup, resSGP.Spec.ALBServerGroupSpec, ignoredALBFields...)
+	if len(after) != 0 {
+		RecordChange(ctx, Change{
+			Action:   ActionUpdate,
+			Resource: ResourceServerGroup,
+			ID:       sdkSGP.ServerGroupId,
+			Name:     sdkSGP.ServerGroupName,
+			Before:   before,
+			After:    after,
+		})
+	}
+	return albmodel.ServerGroupStatus{ServerGroupID: sdkSGP.ServerGroupId}, nil
 }
 func (p DryRunALB) DeleteALBServerGroup(ctx context.Context, serverGroupID string) error {
+	RecordChange(ctx, Change{Action: ActionDelete, Resource: ResourceServerGroup, ID: serverGroupID})
 	return nil
 }
 
 // ALB Tags
 func (p DryRunALB) ListALBServerGroupsWithTags(ctx context.Context, tagFilters map[string]string) ([]albmodel.ServerGroupWithTags, error) {
-	return nil, nil
+	return p.alb.ListALBServerGroupsWithTags(ctx, tagFilters)
 }
 func (p DryRunALB) ListALBsWithTags(ctx context.Context, tagFilters map[string]string) ([]albmodel.AlbLoadBalancerWithTags, error) {
-	return nil, nil
+	return p.alb.ListALBsWithTags(ctx, tagFilters)
+}
+
+// the fields which are not comparable between the res and sdk objects, e.g. the ids resolved from tokens
+var ignoredALBFields = []string{"loadBalancerID", "listenerID", "LoadBalancerId", "ListenerId", "RuleId",
+	"ServerGroupId", "DNSName", "LoadBalancerStatus", "ListenerStatus", "RuleStatus", "ServerGroupStatus", "ForceOverride"}
+
+// dryRunID is the placeholder id of the resources created in dry run mode, the appliers resolve the
+// ids of the created resources to continue with the dependent resources.
+func dryRunID(kind, resID string) string {
+	return fmt.Sprintf("dryrun-%s-%s", kind, resID)
+}
+
+func isDryRunID(id string) bool {
+	return strings.HasPrefix(id, "dryrun-")
 }
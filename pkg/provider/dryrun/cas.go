@@ -29,3 +29,15 @@ func (c DryRunCAS) DescribeSSLCertificatePublicKeyDetail(ctx context.Context, ce
 func (c DryRunCAS) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
 	return c.cas.DescribeSSLCertificateList(ctx)
 }
+
+func (c DryRunCAS) UploadUserCertificate(ctx context.Context, name, cert, key string, tags map[string]string) (int64, error) {
+	return 0, nil
+}
+
+func (c DryRunCAS) DeleteUserCertificate(ctx context.Context, certId int64) error {
+	return nil
+}
+
+func (c DryRunCAS) ListUserCertificateTags(ctx context.Context, certId int64) (map[string]string, error) {
+	return c.cas.ListUserCertificateTags(ctx, certId)
+}
@@ -37,15 +37,15 @@ func (d *DryRunECS) GetInstancesByIP(ctx context.Context, ips []string) (*prvd.N
 	return d.ecs.GetInstancesByIP(ctx, ips)
 }
 
-func (d *DryRunECS) DescribeNetworkInterfaces(vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error) {
-	return d.ecs.DescribeNetworkInterfaces(vpcId, ips, ipVersionType)
+func (d *DryRunECS) DescribeNetworkInterfaces(ctx context.Context, vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error) {
+	return d.ecs.DescribeNetworkInterfaces(ctx, vpcId, ips, ipVersionType)
 }
 
-func (d *DryRunECS) DescribeNetworkInterfacesByIDs(ids []string) ([]*prvd.EniAttribute, error) {
-	return d.ecs.DescribeNetworkInterfacesByIDs(ids)
+func (d *DryRunECS) DescribeNetworkInterfacesByIDs(ctx context.Context, ids []string) ([]*prvd.EniAttribute, error) {
+	return d.ecs.DescribeNetworkInterfacesByIDs(ctx, ids)
 }
 
-func (d *DryRunECS) ModifyNetworkInterfaceSourceDestCheck(id string, enabled bool) error {
+func (d *DryRunECS) ModifyNetworkInterfaceSourceDestCheck(ctx context.Context, id string, enabled bool) error {
 	klog.Infof("[DryRun] ModifyNetworkInterfaceSourceDestCheck: id=%s, enabled=%t", id, enabled)
 	return nil
 }
@@ -29,6 +29,10 @@ func (d DryRunNLB) DeleteNLBListenerAsync(ctx context.Context, listenerId string
 	panic("implement me")
 }
 
+func (d DryRunNLB) GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error) {
+	return d.nlb.GetNLBListenerHealthStatus(ctx, listenerId)
+}
+
 func (d DryRunNLB) UpdateNLBListenerAsync(ctx context.Context, lis *nlbmodel.ListenerAttribute) (string, error) {
 	//TODO implement me
 	panic("implement me")
@@ -69,6 +73,10 @@ func (d DryRunNLB) FindNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalance
 	panic("implement me")
 }
 
+func (d DryRunNLB) ListNLBs(ctx context.Context, tags []tag.Tag) ([]*nlbmodel.NetworkLoadBalancer, error) {
+	return d.nlb.ListNLBs(ctx, tags)
+}
+
 func (d DryRunNLB) DescribeNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
 	//TODO implement me
 	panic("implement me")
@@ -244,7 +252,7 @@ func (d DryRunNLB) DisassociateAdditionalCertificatesWithNLBListener(ctx context
 	panic("implement me")
 }
 
-func (d DryRunNLB) WaitJobFinish(api, jobId string, args ...time.Duration) error {
+func (d DryRunNLB) WaitJobFinish(ctx context.Context, api, jobId string, args ...time.Duration) error {
 	//TODO implement me
 	panic("implement me")
 }
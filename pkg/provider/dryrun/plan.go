@@ -0,0 +1,359 @@
+package dryrun
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PlanConfigMapName is the prefix of the configmaps in kube-system the dry-run plans are written to,
+	// the configmaps are labeled with PlanConfigMapLabel=ccm-dry-run-plan
+	PlanConfigMapName  = "ccm-dry-run-plan"
+	PlanConfigMapLabel = "app"
+	ContextPlan        = ContextKey("ctx.plan")
+)
+
+type Action string
+
+const (
+	ActionCreate = Action("Create")
+	ActionUpdate = Action("Update")
+	ActionDelete = Action("Delete")
+)
+
+const (
+	ResourceLoadBalancer = "LoadBalancer"
+	ResourceListener     = "Listener"
+	ResourceRule         = "Rule"
+	ResourceServerGroup  = "ServerGroup"
+	ResourceBackend      = "Backend"
+	ResourceTag          = "Tag"
+)
+
+// Change is a create, update or delete of a cloud resource. Before is the remote value and
+// After is the value of the local model.
+type Change struct {
+	Action   Action      `json:"action"`
+	Resource string      `json:"resource"`
+	ID       string      `json:"id,omitempty"`
+	Name     string      `json:"name,omitempty"`
+	Parent   string      `json:"parent,omitempty"`
+	Before   interface{} `json:"before,omitempty"`
+	After    interface{} `json:"after,omitempty"`
+}
+
+// Plan is the changes a reconcile of a Service or an AlbConfig would make to the cloud resources.
+type Plan struct {
+	Kind           string   `json:"kind"`
+	Namespace      string   `json:"namespace,omitempty"`
+	Name           string   `json:"name"`
+	LoadBalancerID string   `json:"loadBalancerId,omitempty"`
+	Changes        []Change `json:"changes"`
+	Error          string   `json:"error,omitempty"`
+
+	lock sync.Mutex
+}
+
+func NewPlan(kind string, name types.NamespacedName) *Plan {
+	return &Plan{
+		Kind:      kind,
+		Namespace: name.Namespace,
+		Name:      name.Name,
+		Changes:   []Change{},
+	}
+}
+
+func (p *Plan) Key() string {
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s.%s", strings.ToLower(p.Kind), p.Name)
+	}
+	return fmt.Sprintf("%s.%s.%s", strings.ToLower(p.Kind), p.Namespace, p.Name)
+}
+
+func (p *Plan) Add(changes ...Change) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.Changes = append(p.Changes, changes...)
+}
+
+func (p *Plan) SetError(err error) {
+	if err == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.Error = err.Error()
+}
+
+// WithPlan returns a context the dry-run providers record the changes to.
+func WithPlan(ctx context.Context, plan *Plan) context.Context {
+	return context.WithValue(ctx, ContextPlan, plan)
+}
+
+// RecordChange adds the change to the plan of the context, it does nothing if there is no plan.
+func RecordChange(ctx context.Context, change Change) {
+	plan, ok := ctx.Value(ContextPlan).(*Plan)
+	if !ok || plan == nil {
+		return
+	}
+	plan.Add(change)
+}
+
+// Diff returns the fields of before and after which are different. The fields which are empty
+// in after are not specified by the local model and are skipped.
+func Diff(before, after interface{}, ignored ...string) (map[string]interface{}, map[string]interface{}) {
+	b, a := toMap(before), toMap(after)
+	bd, ad := map[string]interface{}{}, map[string]interface{}{}
+	for k, av := range a {
+		if contains(ignored, k) || isEmpty(av) {
+			continue
+		}
+		if bv := b[k]; !reflect.DeepEqual(bv, av) {
+			bd[k] = bv
+			ad[k] = av
+		}
+	}
+	return bd, ad
+}
+
+// Attributes returns the non-empty fields of the object.
+func Attributes(obj interface{}, ignored ...string) map[string]interface{} {
+	attrs := map[string]interface{}{}
+	for k, v := range toMap(obj) {
+		if contains(ignored, k) || isEmpty(v) {
+			continue
+		}
+		attrs[k] = v
+	}
+	return attrs
+}
+
+// TagChange returns the change of the tags in local which are missing or different in remote.
+func TagChange(id string, remote, local []tag.Tag) *Change {
+	exists := map[string]string{}
+	for _, t := range remote {
+		exists[t.Key] = t.Value
+	}
+	before, after := map[string]interface{}{}, map[string]interface{}{}
+	for _, t := range local {
+		v, ok := exists[t.Key]
+		if ok && v == t.Value {
+			continue
+		}
+		if ok {
+			before[t.Key] = v
+		}
+		after[t.Key] = t.Value
+	}
+	if len(after) == 0 {
+		return nil
+	}
+	return &Change{Action: ActionUpdate, Resource: ResourceTag, ID: id, Before: before, After: after}
+}
+
+func toMap(obj interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	if obj == nil {
+		return m
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		klog.Warningf("dry run: marshal %T error: %s", obj, err.Error())
+		return m
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		klog.Warningf("dry run: unmarshal %T error: %s", obj, err.Error())
+	}
+	return m
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch val := v.(type) {
+	case string:
+		return val == ""
+	case bool:
+		return !val
+	case float64:
+		return val == 0
+	case []interface{}:
+		return len(val) == 0
+	case map[string]interface{}:
+		return len(val) == 0
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxPlanSize is the max size of a plan written to a configmap, a configmap can not exceed 1MiB.
+const MaxPlanSize = 1000 * 1024
+
+// AddPlan writes the plan of a Service or an AlbConfig. Each plan is written to its own file in the plan
+// directory or its own configmap in kube-system, so a reconcile only rewrites the plan it produced.
+func AddPlan(kubeClient client.Client, plan *Plan) error {
+	out, err := marshalPlan(plan)
+	if err != nil {
+		return fmt.Errorf("marshal plan %s error: %s", plan.Key(), err.Error())
+	}
+	klog.Infof("dry run: %s has %d changes", plan.Key(), len(plan.Changes))
+
+	if dir := ctrlCfg.ControllerCFG.DryRunPlanDir; dir != "" {
+		return writePlanFile(dir, plan.Key(), out)
+	}
+	if len(out) > MaxPlanSize {
+		out, err = marshalCompactPlan(plan)
+		if err != nil {
+			return fmt.Errorf("marshal plan %s error: %s", plan.Key(), err.Error())
+		}
+	}
+	return writePlanConfigMap(kubeClient, plan.Key(), out)
+}
+
+func marshalPlan(plan *Plan) ([]byte, error) {
+	plan.lock.Lock()
+	defer plan.lock.Unlock()
+	return marshal(plan)
+}
+
+func marshal(obj interface{}) ([]byte, error) {
+	if ctrlCfg.ControllerCFG.DryRunPlanFormat == "json" {
+		return json.MarshalIndent(obj, "", "  ")
+	}
+	return yaml.Marshal(obj)
+}
+
+// compactPlan is a plan too large for a configmap. The before and after values of the changes are left
+// out first, and the changes are counted by action and resource if the plan is still too large.
+type compactPlan struct {
+	Kind           string         `json:"kind"`
+	Namespace      string         `json:"namespace,omitempty"`
+	Name           string         `json:"name"`
+	LoadBalancerID string         `json:"loadBalancerId,omitempty"`
+	Changes        []Change       `json:"changes,omitempty"`
+	ChangeCounts   map[string]int `json:"changeCounts,omitempty"`
+	Error          string         `json:"error,omitempty"`
+	Truncated      bool           `json:"truncated"`
+}
+
+func marshalCompactPlan(plan *Plan) ([]byte, error) {
+	plan.lock.Lock()
+	defer plan.lock.Unlock()
+	compact := compactPlan{
+		Kind:           plan.Kind,
+		Namespace:      plan.Namespace,
+		Name:           plan.Name,
+		LoadBalancerID: plan.LoadBalancerID,
+		Error:          plan.Error,
+		Truncated:      true,
+	}
+	for _, c := range plan.Changes {
+		c.Before, c.After = nil, nil
+		compact.Changes = append(compact.Changes, c)
+	}
+	out, err := marshal(compact)
+	if err != nil || len(out) <= MaxPlanSize {
+		return out, err
+	}
+
+	compact.Changes = nil
+	compact.ChangeCounts = make(map[string]int)
+	for _, c := range plan.Changes {
+		compact.ChangeCounts[fmt.Sprintf("%s %s", c.Action, c.Resource)]++
+	}
+	return marshal(compact)
+}
+
+func planFileExt() string {
+	if ctrlCfg.ControllerCFG.DryRunPlanFormat == "json" {
+		return ".json"
+	}
+	return ".yaml"
+}
+
+// writePlanFile replaces the file of the plan in dir, the file is renamed into place so that readers
+// never see a partially written plan.
+func writePlanFile(dir, key string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+key+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, key+planFileExt()))
+}
+
+// planConfigMapKey returns the name of the configmap the plan with the key is written to.
+func planConfigMapKey(key string) types.NamespacedName {
+	name := fmt.Sprintf("%s-%s", PlanConfigMapName, key)
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		hash := sha256.Sum256([]byte(key))
+		suffix := hex.EncodeToString(hash[:])[:10]
+		name = strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength-len(suffix)-1], ".-") + "-" + suffix
+	}
+	return types.NamespacedName{Namespace: metav1.NamespaceSystem, Name: name}
+}
+
+func writePlanConfigMap(kubeClient client.Client, key string, data []byte) error {
+	name := planConfigMapKey(key)
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name.Name,
+			Namespace: name.Namespace,
+			Labels:    map[string]string{PlanConfigMapLabel: PlanConfigMapName},
+		},
+		Data: map[string]string{key + planFileExt(): string(data)},
+	}
+
+	exist := &v1.ConfigMap{}
+	err := kubeClient.Get(context.Background(), name, exist)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return kubeClient.Create(context.Background(), cm)
+	}
+	exist.Labels = cm.Labels
+	exist.Data = cm.Data
+	return kubeClient.Update(context.Background(), exist)
+}
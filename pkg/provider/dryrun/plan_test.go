@@ -0,0 +1,134 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ctrlCfg "k8s.io/cloud-provider-alibaba-cloud/pkg/config"
+)
+
+func setPlanConfig(t *testing.T, dir, format string) {
+	oldDir, oldFormat := ctrlCfg.ControllerCFG.DryRunPlanDir, ctrlCfg.ControllerCFG.DryRunPlanFormat
+	ctrlCfg.ControllerCFG.DryRunPlanDir = dir
+	ctrlCfg.ControllerCFG.DryRunPlanFormat = format
+	t.Cleanup(func() {
+		ctrlCfg.ControllerCFG.DryRunPlanDir = oldDir
+		ctrlCfg.ControllerCFG.DryRunPlanFormat = oldFormat
+	})
+}
+
+func newTestPlan(name string, changes ...Change) *Plan {
+	plan := NewPlan("Service", types.NamespacedName{Namespace: "default", Name: name})
+	plan.Add(changes...)
+	return plan
+}
+
+func readPlan(t *testing.T, kubeClient client.Client, key string) *compactPlan {
+	cm := &v1.ConfigMap{}
+	assert.NoError(t, kubeClient.Get(context.TODO(), planConfigMapKey(key), cm))
+	assert.Equal(t, PlanConfigMapName, cm.Labels[PlanConfigMapLabel])
+	plan := &compactPlan{}
+	assert.NoError(t, json.Unmarshal([]byte(cm.Data[key+".json"]), plan))
+	return plan
+}
+
+func TestAddPlanConfigMap(t *testing.T) {
+	setPlanConfig(t, "", "json")
+	kubeClient := fake.NewClientBuilder().Build()
+
+	a := newTestPlan("svc-a", Change{Action: ActionCreate, Resource: ResourceListener, Name: "80"})
+	b := newTestPlan("svc-b")
+	assert.NoError(t, AddPlan(kubeClient, a))
+	assert.NoError(t, AddPlan(kubeClient, b))
+
+	// each plan is written to its own configmap
+	cms := &v1.ConfigMapList{}
+	assert.NoError(t, kubeClient.List(context.TODO(), cms, client.MatchingLabels{PlanConfigMapLabel: PlanConfigMapName}))
+	assert.Len(t, cms.Items, 2)
+	assert.Len(t, readPlan(t, kubeClient, a.Key()).Changes, 1)
+	assert.Empty(t, readPlan(t, kubeClient, b.Key()).Changes)
+
+	// the plan is replaced without touching the plans of the others
+	before := &v1.ConfigMap{}
+	assert.NoError(t, kubeClient.Get(context.TODO(), planConfigMapKey(b.Key()), before))
+	a.Add(Change{Action: ActionDelete, Resource: ResourceServerGroup, ID: "sgp-1"})
+	assert.NoError(t, AddPlan(kubeClient, a))
+	assert.Len(t, readPlan(t, kubeClient, a.Key()).Changes, 2)
+	after := &v1.ConfigMap{}
+	assert.NoError(t, kubeClient.Get(context.TODO(), planConfigMapKey(b.Key()), after))
+	assert.Equal(t, before.ResourceVersion, after.ResourceVersion)
+}
+
+func TestAddPlanTruncated(t *testing.T) {
+	setPlanConfig(t, "", "json")
+	kubeClient := fake.NewClientBuilder().Build()
+
+	// the before and after values are dropped if the plan does not fit in a configmap
+	value := strings.Repeat("x", 1024)
+	plan := newTestPlan("large")
+	for i := 0; i < 1500; i++ {
+		plan.Add(Change{Action: ActionUpdate, Resource: ResourceBackend, ID: "i-1", Before: value, After: value})
+	}
+	assert.NoError(t, AddPlan(kubeClient, plan))
+	compact := readPlan(t, kubeClient, plan.Key())
+	assert.True(t, compact.Truncated)
+	assert.Len(t, compact.Changes, 1500)
+	assert.Nil(t, compact.Changes[0].Before)
+	assert.Nil(t, compact.Changes[0].After)
+
+	// the changes are counted if the plan is still too large
+	plan = newTestPlan("huge")
+	for i := 0; i < 20000; i++ {
+		plan.Add(Change{Action: ActionCreate, Resource: ResourceBackend, ID: strings.Repeat("i", 64), Parent: strings.Repeat("s", 64)})
+	}
+	plan.Add(Change{Action: ActionDelete, Resource: ResourceListener, ID: "lsn-1"})
+	assert.NoError(t, AddPlan(kubeClient, plan))
+	compact = readPlan(t, kubeClient, plan.Key())
+	assert.True(t, compact.Truncated)
+	assert.Empty(t, compact.Changes)
+	assert.Equal(t, map[string]int{"Create Backend": 20000, "Delete Listener": 1}, compact.ChangeCounts)
+}
+
+func TestPlanConfigMapKey(t *testing.T) {
+	key := planConfigMapKey("service.default.svc-a")
+	assert.Equal(t, "kube-system", key.Namespace)
+	assert.Equal(t, "ccm-dry-run-plan-service.default.svc-a", key.Name)
+
+	long := "service." + strings.Repeat("n", 63) + "." + strings.Repeat("s", 253)
+	key = planConfigMapKey(long)
+	assert.Len(t, key.Name, validation.DNS1123SubdomainMaxLength)
+	assert.Empty(t, validation.IsDNS1123Subdomain(key.Name))
+	assert.NotEqual(t, key, planConfigMapKey(long+"x"))
+}
+
+func TestAddPlanFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plans")
+	setPlanConfig(t, dir, "yaml")
+
+	a := newTestPlan("svc-a", Change{Action: ActionCreate, Resource: ResourceListener, Name: "80"})
+	assert.NoError(t, AddPlan(nil, a))
+	assert.NoError(t, AddPlan(nil, newTestPlan("svc-b")))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var files []string
+	for _, e := range entries {
+		files = append(files, e.Name())
+	}
+	assert.Equal(t, []string{"service.default.svc-a.yaml", "service.default.svc-b.yaml"}, files)
+
+	content, err := os.ReadFile(filepath.Join(dir, "service.default.svc-a.yaml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "resource: Listener")
+}
@@ -32,6 +32,10 @@ func (m *DryRunSLB) FindLoadBalancer(ctx context.Context, mdl *model.LoadBalance
 	return m.slb.FindLoadBalancer(ctx, mdl)
 }
 
+func (m *DryRunSLB) ListLoadBalancers(ctx context.Context, tags []tag.Tag) ([]*model.LoadBalancer, error) {
+	return m.slb.ListLoadBalancers(ctx, tags)
+}
+
 func (m *DryRunSLB) CreateLoadBalancer(ctx context.Context, mdl *model.LoadBalancer, clientToken string) error {
 	mtype := "CreateLoadBalancer"
 	svc := getService(ctx)
@@ -280,6 +284,10 @@ func (m *DryRunSLB) ModifyVServerGroupBackendServers(ctx context.Context, vGroup
 	return hintError(mtype, fmt.Sprintf("loadbalancer %s vgroup %s backends should be %s", lbId, vGroupId, new))
 }
 
+func (m *DryRunSLB) DescribeHealthStatus(ctx context.Context, lbId string) ([]model.BackendHealthStatus, error) {
+	return m.slb.DescribeHealthStatus(ctx, lbId)
+}
+
 func (m *DryRunSLB) DescribeServerCertificateById(ctx context.Context, serverCertificateId string) (*model.CertAttribute, error) {
 	return m.slb.DescribeServerCertificateById(ctx, serverCertificateId)
 }
@@ -312,6 +320,42 @@ func (m *DryRunSLB) SetDomainExtensionAttribute(ctx context.Context, id string,
 	return hintError(mtype, fmt.Sprintf("domain extension %s should be set", id))
 }
 
+func (m *DryRunSLB) CreateAccessControlList(ctx context.Context, acl *model.AccessControlList) (string, error) {
+	mtype := "CreateAccessControlList"
+	svc := getService(ctx)
+	AddEvent(SLB, util.Key(svc), acl.AclName, "CreateAccessControlList", ERROR, "")
+	return "", hintError(mtype, fmt.Sprintf("access control list %s should be created", acl.AclName))
+}
+
+func (m *DryRunSLB) DescribeAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error) {
+	return m.slb.DescribeAccessControlLists(ctx, tags)
+}
+
+func (m *DryRunSLB) DescribeAccessControlListAttribute(ctx context.Context, aclId string) (*model.AccessControlList, error) {
+	return m.slb.DescribeAccessControlListAttribute(ctx, aclId)
+}
+
+func (m *DryRunSLB) AddAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	mtype := "AddAccessControlListEntry"
+	svc := getService(ctx)
+	AddEvent(SLB, fmt.Sprintf("%s/%s", util.Key(svc), aclId), aclId, "AddAccessControlListEntry", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("access control list %s entries %v should be added", aclId, entries))
+}
+
+func (m *DryRunSLB) RemoveAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	mtype := "RemoveAccessControlListEntry"
+	svc := getService(ctx)
+	AddEvent(SLB, fmt.Sprintf("%s/%s", util.Key(svc), aclId), aclId, "RemoveAccessControlListEntry", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("access control list %s entries %v should be removed", aclId, entries))
+}
+
+func (m *DryRunSLB) DeleteAccessControlList(ctx context.Context, aclId string) error {
+	mtype := "DeleteAccessControlList"
+	svc := getService(ctx)
+	AddEvent(SLB, fmt.Sprintf("%s/%s", util.Key(svc), aclId), aclId, "DeleteAccessControlList", ERROR, "")
+	return hintError(mtype, fmt.Sprintf("access control list %s should be deleted", aclId))
+}
+
 func getTagString(tags []tag.Tag) string {
 	var ret []string
 	for _, t := range tags {
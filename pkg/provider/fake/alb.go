@@ -0,0 +1,980 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/controller/ingress/reconcile/tracking"
+	albmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/alb"
+	albprvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/alb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/util"
+)
+
+const (
+	albResourceTypeLoadBalancer = "loadbalancer"
+	albResourceTypeServerGroup  = "servergroup"
+
+	albStatusActive  = "Active"
+	albStatusRunning = "Running"
+)
+
+type fakeALB struct {
+	lb    albsdk.LoadBalancer
+	zones []albmodel.ZoneMapping
+}
+
+type fakeALBListener struct {
+	ls    albsdk.Listener
+	certs []albmodel.Certificate
+}
+
+type fakeALBRule struct {
+	rule albsdk.Rule
+}
+
+type fakeALBServerGroup struct {
+	sg      albsdk.ServerGroup
+	servers []albsdk.BackendServer
+}
+
+// GetALB returns a copy of the alb in the fake cloud, or nil if it does not exist.
+func (f *FakeCloud) GetALB(lbID string) *albsdk.LoadBalancer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	lb, ok := f.albs[lbID]
+	if !ok {
+		return nil
+	}
+	ret := lb.lb
+	ret.Tags = append([]albsdk.Tag(nil), lb.lb.Tags...)
+	return &ret
+}
+
+// convert copies the fields with the same json names from src to dst
+func convert(src, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+func albTagsToMap(tags []albsdk.Tag) map[string]string {
+	ret := make(map[string]string, len(tags))
+	for _, t := range tags {
+		ret[t.Key] = t.Value
+	}
+	return ret
+}
+
+func mergeALBTags(tags []albsdk.Tag, add map[string]string) []albsdk.Tag {
+	m := albTagsToMap(tags)
+	for k, v := range add {
+		m[k] = v
+	}
+	ret := make([]albsdk.Tag, 0, len(m))
+	for _, t := range mapToTags(m) {
+		ret = append(ret, albsdk.Tag{Key: t.Key, Value: t.Value})
+	}
+	return ret
+}
+
+func specTagsToMap(tags []albmodel.ALBTag) map[string]string {
+	ret := make(map[string]string, len(tags))
+	for _, t := range tags {
+		ret[t.Key] = t.Value
+	}
+	return ret
+}
+
+func matchALBTags(tags []albsdk.Tag, filters map[string]string) bool {
+	m := albTagsToMap(tags)
+	for k, v := range filters {
+		if m[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *FakeCloud) getALB(api, lbID string) (*fakeALB, error) {
+	lb, ok := f.albs[lbID]
+	if !ok {
+		return nil, notFoundError(api, "ResourceNotFound.LoadBalancer", "LoadBalancer", lbID)
+	}
+	return lb, nil
+}
+
+func (f *FakeCloud) getALBListener(api, lsID string) (*fakeALBListener, error) {
+	ls, ok := f.albLis[lsID]
+	if !ok {
+		return nil, notFoundError(api, "ResourceNotFound.Listener", "Listener", lsID)
+	}
+	return ls, nil
+}
+
+func (f *FakeCloud) getALBServerGroup(api, sgpID string) (*fakeALBServerGroup, error) {
+	sgp, ok := f.albSGs[sgpID]
+	if !ok {
+		return nil, notFoundError(api, "ResourceNotFound.ServerGroup", "ServerGroup", sgpID)
+	}
+	return sgp, nil
+}
+
+func (f *FakeCloud) DescribeALBZones(ctx context.Context, request *albsdk.DescribeZonesRequest) (*albsdk.DescribeZonesResponse, error) {
+	err := f.begin("DescribeALBZones")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	resp := albsdk.CreateDescribeZonesResponse()
+	resp.RequestId = "fake-request-id"
+	zones := map[string]bool{DefaultZoneID: true}
+	for _, vsw := range f.vswitches {
+		zones[vsw.vsw.ZoneId] = true
+	}
+	for z := range zones {
+		resp.Zones = append(resp.Zones, albsdk.Zone{ZoneId: z, LocalName: z})
+	}
+	return resp, nil
+}
+
+func (f *FakeCloud) TagALBResources(ctx context.Context, request *albsdk.TagResourcesRequest) (*albsdk.TagResourcesResponse, error) {
+	err := f.begin("TagALBResources")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	if request.Tag != nil {
+		for _, t := range *request.Tag {
+			tags[t.Key] = t.Value
+		}
+	}
+	var ids []string
+	if request.ResourceId != nil {
+		ids = *request.ResourceId
+	}
+	for _, id := range ids {
+		if err := f.tagALBResource(request.ResourceType, id, tags); err != nil {
+			return nil, err
+		}
+	}
+	resp := albsdk.CreateTagResourcesResponse()
+	resp.RequestId = "fake-request-id"
+	return resp, nil
+}
+
+func (f *FakeCloud) tagALBResource(resourceType, id string, tags map[string]string) error {
+	switch resourceType {
+	case albResourceTypeLoadBalancer:
+		lb, err := f.getALB("TagResources", id)
+		if err != nil {
+			return err
+		}
+		lb.lb.Tags = mergeALBTags(lb.lb.Tags, tags)
+	case albResourceTypeServerGroup:
+		sgp, err := f.getALBServerGroup("TagResources", id)
+		if err != nil {
+			return err
+		}
+		sgp.sg.Tags = mergeALBTags(sgp.sg.Tags, tags)
+	default:
+		return invalidParameterError("TagResources", "IllegalParam.ResourceType",
+			fmt.Sprintf("The resource type %s is invalid.", resourceType))
+	}
+	return nil
+}
+
+// ApplicationLoadBalancer
+
+func (f *FakeCloud) CreateALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, trackingProvider tracking.TrackingProvider) (albmodel.LoadBalancerStatus, error) {
+	err := f.begin("CreateALB")
+	defer f.lock.Unlock()
+	if err != nil {
+		return albmodel.LoadBalancerStatus{}, err
+	}
+	spec := resLB.Spec
+	if spec.VpcId == "" {
+		return albmodel.LoadBalancerStatus{}, fmt.Errorf("invalid load balancer vpc id: %s", spec.VpcId)
+	}
+	if len(spec.ZoneMapping) < 2 {
+		return albmodel.LoadBalancerStatus{}, invalidParameterError("CreateLoadBalancer", "IllegalParam.ZoneMappings",
+			"At least two zones are required in ZoneMappings.")
+	}
+	for _, z := range spec.ZoneMapping {
+		if vsw, ok := f.vswitches[z.VSwitchId]; ok && vsw.vsw.AvailableIpAddressCount == 0 {
+			return albmodel.LoadBalancerStatus{}, NewAPIError("CreateLoadBalancer", http.StatusBadRequest,
+				"QuotaInsufficient.VSwitchAvailableIp", fmt.Sprintf("The specified VSwitch %s has no available ip.", z.VSwitchId))
+		}
+	}
+
+	lb := albsdk.LoadBalancer{}
+	spec.Tags = nil
+	spec.ZoneMapping = nil
+	if err := convert(spec, &lb); err != nil {
+		return albmodel.LoadBalancerStatus{}, err
+	}
+	lb.LoadBalancerId = f.nextID("alb")
+	lb.LoadBalancerStatus = albStatusActive
+	lb.LoadBalancerBussinessStatus = "Normal"
+	lb.DNSName = fmt.Sprintf("%s.%s.alb.aliyuncs.com", lb.LoadBalancerId, DefaultRegion)
+	lb.Tags = mergeALBTags(nil, trackingProvider.ResourceTags(resLB.Stack(), resLB, specTagsToMap(resLB.Spec.Tags)))
+	f.albs[lb.LoadBalancerId] = &fakeALB{lb: lb, zones: append([]albmodel.ZoneMapping(nil), resLB.Spec.ZoneMapping...)}
+
+	return albmodel.LoadBalancerStatus{LoadBalancerID: lb.LoadBalancerId, DNSName: lb.DNSName}, nil
+}
+
+func (f *FakeCloud) ReuseALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, lbID string, trackingProvider tracking.TrackingProvider) (albmodel.LoadBalancerStatus, error) {
+	err := f.begin("ReuseALB")
+	if err != nil {
+		f.lock.Unlock()
+		return albmodel.LoadBalancerStatus{}, err
+	}
+	lb, err := f.getALB("GetLoadBalancerAttribute", lbID)
+	if err != nil {
+		f.lock.Unlock()
+		return albmodel.LoadBalancerStatus{}, err
+	}
+	if lb.lb.VpcId != resLB.Spec.VpcId {
+		f.lock.Unlock()
+		return albmodel.LoadBalancerStatus{}, fmt.Errorf("the vpc %s of reused alb %s is not same with cluster vpc %s",
+			lb.lb.VpcId, lbID, resLB.Spec.VpcId)
+	}
+	resTags := trackingProvider.ResourceTags(resLB.Stack(), resLB, specTagsToMap(resLB.Spec.Tags))
+	sdkTags := albTagsToMap(lb.lb.Tags)
+	for _, key := range []string{trackingProvider.ClusterNameTagKey(), trackingProvider.AlbConfigTagKey()} {
+		sdkValue, okSdk := sdkTags[key]
+		resValue, okRes := resTags[key]
+		if okSdk && okRes && sdkValue != resValue {
+			f.lock.Unlock()
+			return albmodel.LoadBalancerStatus{}, fmt.Errorf("alb %s belongs to %s, cant reuse alb to another one: %s",
+				lbID, sdkValue, resValue)
+		}
+		if key == trackingProvider.ClusterNameTagKey() && !(okSdk && okRes) {
+			break
+		}
+	}
+	lb.lb.Tags = mergeALBTags(lb.lb.Tags, resTags)
+	sdkLB := lb.lb
+	f.lock.Unlock()
+
+	if resLB.Spec.ForceOverride != nil && *resLB.Spec.ForceOverride {
+		return f.UpdateALB(ctx, resLB, sdkLB)
+	}
+	return albmodel.LoadBalancerStatus{LoadBalancerID: lbID, DNSName: sdkLB.DNSName}, nil
+}
+
+func (f *FakeCloud) UpdateALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, sdkLB albsdk.LoadBalancer) (albmodel.LoadBalancerStatus, error) {
+	err := f.begin("UpdateALB")
+	defer f.lock.Unlock()
+	if err != nil {
+		return albmodel.LoadBalancerStatus{}, err
+	}
+	lb, err := f.getALB("UpdateLoadBalancerAttribute", sdkLB.LoadBalancerId)
+	if err != nil {
+		return albmodel.LoadBalancerStatus{}, err
+	}
+	spec := resLB.Spec
+	if spec.LoadBalancerName != "" {
+		lb.lb.LoadBalancerName = spec.LoadBalancerName
+	}
+	if spec.ModificationProtectionConfig.Status != "" {
+		lb.lb.ModificationProtectionConfig = albsdk.ModificationProtectionConfig{
+			Status: spec.ModificationProtectionConfig.Status,
+			Reason: spec.ModificationProtectionConfig.Reason,
+		}
+	}
+	lb.lb.DeletionProtectionConfig.Enabled = spec.DeletionProtectionConfig.Enabled
+	lb.lb.AccessLogConfig = albsdk.AccessLogConfig{
+		LogProject: spec.AccessLogConfig.LogProject,
+		LogStore:   spec.AccessLogConfig.LogStore,
+	}
+	if spec.LoadBalancerEdition != "" {
+		lb.lb.LoadBalancerEdition = spec.LoadBalancerEdition
+	}
+	return albmodel.LoadBalancerStatus{LoadBalancerID: lb.lb.LoadBalancerId, DNSName: lb.lb.DNSName}, nil
+}
+
+func (f *FakeCloud) DeleteALB(ctx context.Context, lbID string) error {
+	err := f.begin("DeleteALB")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, err := f.getALB("DeleteLoadBalancer", lbID); err != nil {
+		return err
+	}
+	for lsID, ls := range f.albLis {
+		if ls.ls.LoadBalancerId != lbID {
+			continue
+		}
+		for ruleID, r := range f.albRules {
+			if r.rule.ListenerId == lsID {
+				delete(f.albRules, ruleID)
+			}
+		}
+		delete(f.albLis, lsID)
+	}
+	delete(f.albs, lbID)
+	return nil
+}
+
+// ALB Listener
+
+func (f *FakeCloud) checkALBActions(api string, actions []albsdk.DefaultAction) error {
+	for _, a := range actions {
+		for _, t := range a.ForwardGroupConfig.ServerGroupTuples {
+			if _, err := f.getALBServerGroup(api, t.ServerGroupId); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkALBQuicConfig checks that a listener upgrading to QUIC is an HTTPS listener and refers to a
+// QUIC listener of the same load balancer.
+func (f *FakeCloud) checkALBQuicConfig(api, lbID string, ls *albsdk.Listener) error {
+	if !ls.QuicConfig.QuicUpgradeEnabled {
+		return nil
+	}
+	if !strings.EqualFold(ls.ListenerProtocol, util.ListenerProtocolHTTPS) {
+		return invalidParameterError(api, "OperationDenied.ListenerProtocolNotSupport",
+			fmt.Sprintf("The listener protocol %s does not support QUIC upgrade.", ls.ListenerProtocol))
+	}
+	quicLs, err := f.getALBListener(api, ls.QuicConfig.QuicListenerId)
+	if err != nil {
+		return err
+	}
+	if quicLs.ls.LoadBalancerId != lbID || !strings.EqualFold(quicLs.ls.ListenerProtocol, util.ListenerProtocolQUIC) {
+		return invalidParameterError(api, "IllegalParam.QuicListenerId",
+			fmt.Sprintf("The listener %s is not a QUIC listener of load balancer %s.", ls.QuicConfig.QuicListenerId, lbID))
+	}
+	return nil
+}
+
+func (f *FakeCloud) CreateALBListener(ctx context.Context, resLS *albmodel.Listener) (albmodel.ListenerStatus, error) {
+	err := f.begin("CreateALBListener")
+	defer f.lock.Unlock()
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	lbID, err := resLS.Spec.LoadBalancerID.Resolve(ctx)
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	if _, err := f.getALB("CreateListener", lbID); err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	for _, ls := range f.albLis {
+		if ls.ls.LoadBalancerId == lbID && ls.ls.ListenerPort == resLS.Spec.ListenerPort {
+			return albmodel.ListenerStatus{}, invalidParameterError("CreateListener", "ResourceAlreadyExist.Listener",
+				fmt.Sprintf("The listener port %d is already used by listener %s.", resLS.Spec.ListenerPort, ls.ls.ListenerId))
+		}
+	}
+	ls, err := f.buildALBListener(resLS)
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	if err := f.checkALBActions("CreateListener", ls.DefaultActions); err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	if err := f.checkALBQuicConfig("CreateListener", lbID, ls); err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	ls.LoadBalancerId = lbID
+	ls.ListenerId = f.nextID("lsn")
+	ls.ListenerStatus = albStatusRunning
+	f.albLis[ls.ListenerId] = &fakeALBListener{
+		ls:    *ls,
+		certs: append([]albmodel.Certificate(nil), resLS.Spec.Certificates...),
+	}
+	return albmodel.ListenerStatus{ListenerID: ls.ListenerId}, nil
+}
+
+func (f *FakeCloud) buildALBListener(resLS *albmodel.Listener) (*albsdk.Listener, error) {
+	spec := resLS.Spec.ALBListenerSpec
+	spec.DefaultActions = nil
+	spec.Certificates = nil
+	spec.CaCertificates = nil
+	ls := &albsdk.Listener{}
+	if err := convert(spec, ls); err != nil {
+		return nil, err
+	}
+	actions, err := albprvd.TransModelActionsToSDKLs(resLS.Spec.DefaultActions)
+	if err != nil {
+		return nil, err
+	}
+	ls.DefaultActions = *actions
+	return ls, nil
+}
+
+func (f *FakeCloud) UpdateALBListener(ctx context.Context, resLS *albmodel.Listener, sdkLB *albsdk.Listener) (albmodel.ListenerStatus, error) {
+	err := f.begin("UpdateALBListener")
+	defer f.lock.Unlock()
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	remote, err := f.getALBListener("UpdateListenerAttribute", sdkLB.ListenerId)
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	ls, err := f.buildALBListener(resLS)
+	if err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	if err := f.checkALBActions("UpdateListenerAttribute", ls.DefaultActions); err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	ls.ListenerId = remote.ls.ListenerId
+	ls.LoadBalancerId = remote.ls.LoadBalancerId
+	ls.ListenerPort = remote.ls.ListenerPort
+	ls.ListenerProtocol = remote.ls.ListenerProtocol
+	if err := f.checkALBQuicConfig("UpdateListenerAttribute", remote.ls.LoadBalancerId, ls); err != nil {
+		return albmodel.ListenerStatus{}, err
+	}
+	ls.ListenerStatus = remote.ls.ListenerStatus
+	ls.Tags = remote.ls.Tags
+	remote.ls = *ls
+	remote.certs = append([]albmodel.Certificate(nil), resLS.Spec.Certificates...)
+	return albmodel.ListenerStatus{ListenerID: ls.ListenerId}, nil
+}
+
+func (f *FakeCloud) DeleteALBListener(ctx context.Context, lsID string) error {
+	err := f.begin("DeleteALBListener")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, err := f.getALBListener("DeleteListener", lsID); err != nil {
+		return err
+	}
+	for _, ls := range f.albLis {
+		if ls.ls.QuicConfig.QuicUpgradeEnabled && ls.ls.QuicConfig.QuicListenerId == lsID {
+			return invalidParameterError("DeleteListener", "ResourceInUse.Listener",
+				fmt.Sprintf("The listener %s is used by listener %s.", lsID, ls.ls.ListenerId))
+		}
+	}
+	for ruleID, r := range f.albRules {
+		if r.rule.ListenerId == lsID {
+			delete(f.albRules, ruleID)
+		}
+	}
+	delete(f.albLis, lsID)
+	return nil
+}
+
+func (f *FakeCloud) ListALBListeners(ctx context.Context, lbID string) ([]albsdk.Listener, error) {
+	err := f.begin("ListALBListeners")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(lbID) == 0 {
+		return nil, fmt.Errorf("invalid load balancer id: %s for listing listeners", lbID)
+	}
+	var ret []albsdk.Listener
+	for _, ls := range f.albLis {
+		if ls.ls.LoadBalancerId == lbID {
+			ret = append(ret, ls.ls)
+		}
+	}
+	return ret, nil
+}
+
+// ALB Listener Rule
+
+func (f *FakeCloud) buildALBRule(ctx context.Context, resLR *albmodel.ListenerRule) (*albsdk.Rule, error) {
+	actions, err := albprvd.TransModelActionsToSDK(resLR.Spec.RuleActions)
+	if err != nil {
+		return nil, err
+	}
+	rule := &albsdk.Rule{
+		Priority:       resLR.Spec.Priority,
+		RuleName:       resLR.Spec.RuleName,
+		RuleActions:    *actions,
+		RuleConditions: albprvd.TransModelConditionsToSDK(resLR.Spec.RuleConditions),
+		Direction:      albmodel.RuleDirection(resLR.Spec.Direction),
+	}
+	if err := checkALBRuleDirection(rule); err != nil {
+		return nil, err
+	}
+	for _, a := range rule.RuleActions {
+		tuples := append(a.ForwardGroupConfig.ServerGroupTuples, a.TrafficMirrorConfig.MirrorGroupConfig.ServerGroupTuples...)
+		for _, t := range tuples {
+			if _, err := f.getALBServerGroup("CreateRule", t.ServerGroupId); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rule, nil
+}
+
+// checkALBRuleDirection rejects the conditions and actions not supported in the direction of the rule.
+func checkALBRuleDirection(rule *albsdk.Rule) error {
+	response := rule.Direction == util.RuleDirectionResponse
+	for _, c := range rule.RuleConditions {
+		switch c.Type {
+		case util.RuleConditionFieldHost, util.RuleConditionFieldPath:
+		case util.RuleConditionFieldResponseHeader, util.RuleConditionFieldResponseStatusCode:
+			if !response {
+				return invalidParameterError("CreateRule", "UnsupportedCondition",
+					fmt.Sprintf("The condition %s is not supported in direction %s.", c.Type, rule.Direction))
+			}
+		default:
+			if response {
+				return invalidParameterError("CreateRule", "UnsupportedCondition",
+					fmt.Sprintf("The condition %s is not supported in direction %s.", c.Type, rule.Direction))
+			}
+		}
+	}
+	if !response {
+		return nil
+	}
+	for _, a := range rule.RuleActions {
+		if a.Type != util.RuleActionTypeInsertHeader && a.Type != util.RuleActionTypeRemoveHeader {
+			return invalidParameterError("CreateRule", "UnsupportedAction",
+				fmt.Sprintf("The action %s is not supported in direction %s.", a.Type, rule.Direction))
+		}
+	}
+	return nil
+}
+
+func (f *FakeCloud) createALBRule(ctx context.Context, resLR *albmodel.ListenerRule) (albmodel.ListenerRuleStatus, error) {
+	lsID, err := resLR.Spec.ListenerID.Resolve(ctx)
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	ls, err := f.getALBListener("CreateRule", lsID)
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	if ls.ls.ListenerStatus != albStatusRunning {
+		return albmodel.ListenerRuleStatus{}, NewAPIError("CreateRule", http.StatusBadRequest, "IncorrectStatus.Listener",
+			fmt.Sprintf("The status of listener %s is %s.", lsID, ls.ls.ListenerStatus))
+	}
+	for _, r := range f.albRules {
+		if r.rule.ListenerId == lsID && r.rule.Priority == resLR.Spec.Priority {
+			return albmodel.ListenerRuleStatus{}, invalidParameterError("CreateRule", "Conflict.Priority",
+				fmt.Sprintf("The priority %d is already used by rule %s.", resLR.Spec.Priority, r.rule.RuleId))
+		}
+	}
+	rule, err := f.buildALBRule(ctx, resLR)
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	rule.ListenerId = lsID
+	rule.LoadBalancerId = ls.ls.LoadBalancerId
+	rule.RuleId = f.nextID("rule")
+	rule.RuleStatus = "Available"
+	f.albRules[rule.RuleId] = &fakeALBRule{rule: *rule}
+	return albmodel.ListenerRuleStatus{RuleID: rule.RuleId}, nil
+}
+
+func (f *FakeCloud) CreateALBListenerRule(ctx context.Context, resLR *albmodel.ListenerRule) (albmodel.ListenerRuleStatus, error) {
+	err := f.begin("CreateALBListenerRule")
+	defer f.lock.Unlock()
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	return f.createALBRule(ctx, resLR)
+}
+
+func (f *FakeCloud) CreateALBListenerRules(ctx context.Context, resLR []*albmodel.ListenerRule) (map[int]albmodel.ListenerRuleStatus, error) {
+	err := f.begin("CreateALBListenerRules")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[int]albmodel.ListenerRuleStatus)
+	for _, lr := range resLR {
+		status, err := f.createALBRule(ctx, lr)
+		if err != nil {
+			return nil, err
+		}
+		ret[lr.Spec.Priority] = status
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) updateALBRule(ctx context.Context, resLR *albmodel.ListenerRule, sdkLR *albsdk.Rule) error {
+	remote, ok := f.albRules[sdkLR.RuleId]
+	if !ok {
+		return notFoundError("UpdateRuleAttribute", "ResourceNotFound.Rule", "Rule", sdkLR.RuleId)
+	}
+	rule, err := f.buildALBRule(ctx, resLR)
+	if err != nil {
+		return err
+	}
+	if rule.Direction != remote.rule.Direction {
+		return invalidParameterError("UpdateRuleAttribute", "UnsupportedDirection",
+			fmt.Sprintf("The direction of rule %s can not be updated.", sdkLR.RuleId))
+	}
+	remote.rule.RuleName = rule.RuleName
+	remote.rule.Priority = rule.Priority
+	remote.rule.RuleActions = rule.RuleActions
+	remote.rule.RuleConditions = rule.RuleConditions
+	return nil
+}
+
+func (f *FakeCloud) UpdateALBListenerRule(ctx context.Context, resLR *albmodel.ListenerRule, sdkLR *albsdk.Rule) (albmodel.ListenerRuleStatus, error) {
+	err := f.begin("UpdateALBListenerRule")
+	defer f.lock.Unlock()
+	if err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	if err := f.updateALBRule(ctx, resLR, sdkLR); err != nil {
+		return albmodel.ListenerRuleStatus{}, err
+	}
+	return albmodel.ListenerRuleStatus{RuleID: sdkLR.RuleId}, nil
+}
+
+func (f *FakeCloud) UpdateALBListenerRules(ctx context.Context, matches []albmodel.ResAndSDKListenerRulePair) error {
+	err := f.begin("UpdateALBListenerRules")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		if err := f.updateALBRule(ctx, m.ResLR, m.SdkLR); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FakeCloud) DeleteALBListenerRule(ctx context.Context, sdkLRId string) error {
+	return f.deleteALBListenerRules("DeleteALBListenerRule", []string{sdkLRId})
+}
+
+func (f *FakeCloud) DeleteALBListenerRules(ctx context.Context, sdkLRIds []string) error {
+	return f.deleteALBListenerRules("DeleteALBListenerRules", sdkLRIds)
+}
+
+func (f *FakeCloud) deleteALBListenerRules(api string, sdkLRIds []string) error {
+	err := f.begin(api)
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, id := range sdkLRIds {
+		if _, ok := f.albRules[id]; !ok {
+			return notFoundError("DeleteRules", "ResourceNotFound.Rule", "Rule", id)
+		}
+	}
+	for _, id := range sdkLRIds {
+		delete(f.albRules, id)
+	}
+	return nil
+}
+
+func (f *FakeCloud) ListALBListenerRules(ctx context.Context, lsID string) ([]albsdk.Rule, error) {
+	err := f.begin("ListALBListenerRules")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var ret []albsdk.Rule
+	for _, r := range f.albRules {
+		if r.rule.ListenerId == lsID {
+			ret = append(ret, r.rule)
+		}
+	}
+	return ret, nil
+}
+
+// ALB Server
+
+func albServerKey(serverId, serverIp string, port int) string {
+	return fmt.Sprintf("%s/%s/%d", serverId, serverIp, port)
+}
+
+func validateALBServer(api string, s albmodel.BackendItem) error {
+	if s.ServerId == "" || s.Port < 1 || s.Port > 65535 || s.Weight < 0 || s.Weight > 100 {
+		return invalidParameterError(api, "IllegalParam.Servers", fmt.Sprintf("invalid server: %+v", s))
+	}
+	switch strings.ToLower(s.Type) {
+	case "ecs", "eni", "eci":
+	default:
+		return invalidParameterError(api, "IllegalParam.ServerType", fmt.Sprintf("invalid server type for server: %+v", s))
+	}
+	return nil
+}
+
+func toALBServer(sgpID string, s albmodel.BackendItem) albsdk.BackendServer {
+	return albsdk.BackendServer{
+		Description:   s.Description,
+		Port:          s.Port,
+		ServerId:      s.ServerId,
+		ServerIp:      s.ServerIp,
+		ServerType:    s.Type,
+		Status:        "Available",
+		Weight:        s.Weight,
+		ServerGroupId: sgpID,
+	}
+}
+
+func (f *FakeCloud) RegisterALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	err := f.begin("RegisterALBServers")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	sgp, err := f.getALBServerGroup("AddServersToServerGroup", serverGroupID)
+	if err != nil {
+		return err
+	}
+	for _, s := range resServers {
+		if err := validateALBServer("AddServersToServerGroup", s); err != nil {
+			return err
+		}
+		for _, old := range sgp.servers {
+			if albServerKey(old.ServerId, old.ServerIp, old.Port) == albServerKey(s.ServerId, s.ServerIp, s.Port) {
+				return invalidParameterError("AddServersToServerGroup", "ResourceAlreadyAssociated.BackendServer",
+					fmt.Sprintf("The server %s is already in server group %s.", s.ServerId, serverGroupID))
+			}
+		}
+	}
+	for _, s := range resServers {
+		sgp.servers = append(sgp.servers, toALBServer(serverGroupID, s))
+	}
+	sgp.sg.ServerCount = len(sgp.servers)
+	return nil
+}
+
+func removeALBServers(servers []albsdk.BackendServer, del []albsdk.BackendServer) []albsdk.BackendServer {
+	var ret []albsdk.BackendServer
+	for _, s := range servers {
+		found := false
+		for _, d := range del {
+			if albServerKey(s.ServerId, s.ServerIp, s.Port) == albServerKey(d.ServerId, d.ServerIp, d.Port) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func (f *FakeCloud) DeregisterALBServers(ctx context.Context, serverGroupID string, sdkServers []albsdk.BackendServer) error {
+	err := f.begin("DeregisterALBServers")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	sgp, err := f.getALBServerGroup("RemoveServersFromServerGroup", serverGroupID)
+	if err != nil {
+		return err
+	}
+	sgp.servers = removeALBServers(sgp.servers, sdkServers)
+	sgp.sg.ServerCount = len(sgp.servers)
+	return nil
+}
+
+func (f *FakeCloud) ReplaceALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem, sdkServers []albsdk.BackendServer) error {
+	err := f.begin("ReplaceALBServers")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	sgp, err := f.getALBServerGroup("ReplaceServersInServerGroup", serverGroupID)
+	if err != nil {
+		return err
+	}
+	for _, s := range resServers {
+		if err := validateALBServer("ReplaceServersInServerGroup", s); err != nil {
+			return err
+		}
+	}
+	servers := removeALBServers(sgp.servers, sdkServers)
+	for _, s := range resServers {
+		servers = append(servers, toALBServer(serverGroupID, s))
+	}
+	sgp.servers = servers
+	sgp.sg.ServerCount = len(sgp.servers)
+	return nil
+}
+
+func (f *FakeCloud) UpdateALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	err := f.begin("UpdateALBServers")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	sgp, err := f.getALBServerGroup("UpdateServerGroupServersAttribute", serverGroupID)
+	if err != nil {
+		return err
+	}
+	for _, s := range resServers {
+		if err := validateALBServer("UpdateServerGroupServersAttribute", s); err != nil {
+			return err
+		}
+		found := false
+		for i := range sgp.servers {
+			if albServerKey(sgp.servers[i].ServerId, sgp.servers[i].ServerIp, sgp.servers[i].Port) == albServerKey(s.ServerId, s.ServerIp, s.Port) {
+				sgp.servers[i].Weight = s.Weight
+				sgp.servers[i].Description = s.Description
+				found = true
+				break
+			}
+		}
+		if !found {
+			return notFoundError("UpdateServerGroupServersAttribute", "ResourceNotFound.BackendServer", "BackendServer",
+				albServerKey(s.ServerId, s.ServerIp, s.Port))
+		}
+	}
+	return nil
+}
+
+func (f *FakeCloud) ListALBServers(ctx context.Context, serverGroupID string) ([]albsdk.BackendServer, error) {
+	err := f.begin("ListALBServers")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	sgp, err := f.getALBServerGroup("ListServerGroupServers", serverGroupID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]albsdk.BackendServer(nil), sgp.servers...), nil
+}
+
+// ALB ServerGroup
+
+func (f *FakeCloud) CreateALBServerGroup(ctx context.Context, resSGP *albmodel.ServerGroup, trackingProvider tracking.TrackingProvider) (albmodel.ServerGroupStatus, error) {
+	err := f.begin("CreateALBServerGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	sg := albsdk.ServerGroup{}
+	spec := resSGP.Spec.ALBServerGroupSpec
+	spec.Tags = nil
+	if err := convert(spec, &sg); err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	if sg.ServerGroupName == "" {
+		return albmodel.ServerGroupStatus{}, invalidParameterError("CreateServerGroup", "MissingParam.ServerGroupName",
+			"The parameter ServerGroupName is required.")
+	}
+	sg.ServerGroupId = f.nextID("sgp")
+	sg.ServerGroupStatus = "Available"
+	sg.Tags = mergeALBTags(nil, trackingProvider.ResourceTags(resSGP.Stack(), resSGP, specTagsToMap(resSGP.Spec.Tags)))
+	f.albSGs[sg.ServerGroupId] = &fakeALBServerGroup{sg: sg}
+	return albmodel.ServerGroupStatus{ServerGroupID: sg.ServerGroupId}, nil
+}
+
+func (f *FakeCloud) UpdateALBServerGroup(ctx context.Context, resSGP *albmodel.ServerGroup, sdkSGP albmodel.ServerGroupWithTags) (albmodel.ServerGroupStatus, error) {
+	err := f.begin("UpdateALBServerGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	remote, err := f.getALBServerGroup("UpdateServerGroupAttribute", sdkSGP.ServerGroupId)
+	if err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	remote.sg.ServerGroupName = resSGP.Spec.ServerGroupName
+	remote.sg.Scheduler = resSGP.Spec.Scheduler
+	if err := convert(resSGP.Spec.HealthCheckConfig, &remote.sg.HealthCheckConfig); err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	if err := convert(resSGP.Spec.StickySessionConfig, &remote.sg.StickySessionConfig); err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	if err := convert(resSGP.Spec.UchConfig, &remote.sg.UchConfig); err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	if err := convert(resSGP.Spec.ConnectionDrainConfig, &remote.sg.ConnectionDrainConfig); err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	if err := convert(resSGP.Spec.SlowStartConfig, &remote.sg.SlowStartConfig); err != nil {
+		return albmodel.ServerGroupStatus{}, err
+	}
+	return albmodel.ServerGroupStatus{ServerGroupID: remote.sg.ServerGroupId}, nil
+}
+
+func (f *FakeCloud) DeleteALBServerGroup(ctx context.Context, serverGroupID string) error {
+	err := f.begin("DeleteALBServerGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, err := f.getALBServerGroup("DeleteServerGroup", serverGroupID); err != nil {
+		return err
+	}
+	inUse := func(tuples []albsdk.ServerGroupTuple) bool {
+		for _, t := range tuples {
+			if t.ServerGroupId == serverGroupID {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ls := range f.albLis {
+		for _, a := range ls.ls.DefaultActions {
+			if inUse(a.ForwardGroupConfig.ServerGroupTuples) {
+				return NewAPIError("DeleteServerGroup", http.StatusBadRequest, "ResourceInUse.ServerGroup",
+					fmt.Sprintf("The server group %s is used by listener %s.", serverGroupID, ls.ls.ListenerId))
+			}
+		}
+	}
+	for _, r := range f.albRules {
+		for _, a := range r.rule.RuleActions {
+			if inUse(a.ForwardGroupConfig.ServerGroupTuples) || inUse(a.TrafficMirrorConfig.MirrorGroupConfig.ServerGroupTuples) {
+				return NewAPIError("DeleteServerGroup", http.StatusBadRequest, "ResourceInUse.ServerGroup",
+					fmt.Sprintf("The server group %s is used by rule %s.", serverGroupID, r.rule.RuleId))
+			}
+		}
+	}
+	delete(f.albSGs, serverGroupID)
+	return nil
+}
+
+// ALB Tags
+
+func (f *FakeCloud) ListALBServerGroupsWithTags(ctx context.Context, tagFilters map[string]string) ([]albmodel.ServerGroupWithTags, error) {
+	err := f.begin("ListALBServerGroupsWithTags")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]albmodel.ServerGroupWithTags, 0)
+	for _, sgp := range f.albSGs {
+		if !matchALBTags(sgp.sg.Tags, tagFilters) {
+			continue
+		}
+		ret = append(ret, albmodel.ServerGroupWithTags{
+			ServerGroup: sgp.sg,
+			Tags:        albTagsToMap(sgp.sg.Tags),
+		})
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) ListALBsWithTags(ctx context.Context, tagFilters map[string]string) ([]albmodel.AlbLoadBalancerWithTags, error) {
+	err := f.begin("ListALBsWithTags")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]albmodel.AlbLoadBalancerWithTags, 0)
+	for _, lb := range f.albs {
+		if !matchALBTags(lb.lb.Tags, tagFilters) {
+			continue
+		}
+		ret = append(ret, albmodel.AlbLoadBalancerWithTags{
+			LoadBalancer: lb.lb,
+			Tags:         albTagsToMap(lb.lb.Tags),
+		})
+	}
+	return ret, nil
+}
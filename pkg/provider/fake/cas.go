@@ -0,0 +1,116 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+)
+
+type fakeCASCertificate struct {
+	info model.CertificateInfo
+	cert string
+	key  string
+	tags map[string]string
+}
+
+func certIdentifier(certId int64) string {
+	return fmt.Sprintf("%d-%s", certId, DefaultRegion)
+}
+
+func (f *FakeCloud) DescribeSSLCertificatePublicKeyDetail(ctx context.Context, certId string) (*model.CertificateInfo, error) {
+	err := f.begin("DescribeSSLCertificatePublicKeyDetail")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(strings.SplitN(certId, "-", 2)[0], 10, 64)
+	if err != nil {
+		return nil, invalidParameterError("DescribeSSLCertificatePublicKeyDetail", "InvalidParameter.CertIdentifier",
+			fmt.Sprintf("The specified CertIdentifier %s is invalid.", certId))
+	}
+	cert, ok := f.casCerts[id]
+	if !ok {
+		return nil, notFoundError("DescribeSSLCertificatePublicKeyDetail", "NotFound.Certificate", "certificate", certId)
+	}
+	info := cert.info
+	return &info, nil
+}
+
+func (f *FakeCloud) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	err := f.begin("DescribeSSLCertificateList")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]model.CertificateInfo, 0, len(f.casCerts))
+	for _, cert := range f.casCerts {
+		ret = append(ret, cert.info)
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) UploadUserCertificate(ctx context.Context, name, cert, key string, tags map[string]string) (int64, error) {
+	err := f.begin("UploadUserCertificate")
+	defer f.lock.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if cert == "" || key == "" {
+		return 0, invalidParameterError("UploadUserCertificate", "InvalidParameter", "The certificate and key are required.")
+	}
+	for _, c := range f.casCerts {
+		if c.info.CertName == name {
+			return 0, invalidParameterError("UploadUserCertificate", "NameRepeat",
+				fmt.Sprintf("The certificate name %s already exists.", name))
+		}
+	}
+	f.seq++
+	id := int64(f.seq)
+	copied := make(map[string]string, len(tags))
+	for k, v := range tags {
+		copied[k] = v
+	}
+	f.casCerts[id] = &fakeCASCertificate{
+		info: model.CertificateInfo{
+			CertName:       name,
+			CertIdentifier: certIdentifier(id),
+		},
+		cert: cert,
+		key:  key,
+		tags: copied,
+	}
+	return id, nil
+}
+
+func (f *FakeCloud) ListUserCertificateTags(ctx context.Context, certId int64) (map[string]string, error) {
+	err := f.begin("ListUserCertificateTags")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	cert, ok := f.casCerts[certId]
+	if !ok {
+		return nil, notFoundError("ListTagResources", "NotFound.Certificate", "certificate", strconv.FormatInt(certId, 10))
+	}
+	tags := make(map[string]string, len(cert.tags))
+	for k, v := range cert.tags {
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+func (f *FakeCloud) DeleteUserCertificate(ctx context.Context, certId int64) error {
+	err := f.begin("DeleteUserCertificate")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.casCerts[certId]; !ok {
+		return notFoundError("DeleteUserCertificate", "NotFound.Certificate", "certificate", strconv.FormatInt(certId, 10))
+	}
+	delete(f.casCerts, certId)
+	return nil
+}
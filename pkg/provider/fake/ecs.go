@@ -0,0 +1,352 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	ecsmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/ecs"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+)
+
+type fakeENI struct {
+	attr  prvd.EniAttribute
+	vpcId string
+	ipv6  []string
+}
+
+type fakeSecurityGroup struct {
+	sg ecsmodel.SecurityGroup
+}
+
+// AddInstance adds an ecs instance to the fake cloud.
+func (f *FakeCloud) AddInstance(ins *prvd.NodeAttribute) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.instances[ins.InstanceID] = ins
+}
+
+// RemoveInstance removes an ecs instance from the fake cloud, e.g. the instance is released.
+func (f *FakeCloud) RemoveInstance(instanceID string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.instances, instanceID)
+}
+
+// SetInstanceStatus changes the status of an ecs instance, e.g. the instance is stopped.
+func (f *FakeCloud) SetInstanceStatus(instanceID, status string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if ins, ok := f.instances[instanceID]; ok {
+		ins.Status = status
+	}
+}
+
+// AddENI adds an InUse elastic network interface to the fake cloud.
+func (f *FakeCloud) AddENI(eni prvd.EniAttribute, vpcId string, ipv6 ...string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if eni.Status == "" {
+		eni.Status = "InUse"
+	}
+	f.enis[eni.NetworkInterfaceID] = &fakeENI{attr: eni, vpcId: vpcId, ipv6: ipv6}
+}
+
+func copyNodeAttribute(ins *prvd.NodeAttribute) *prvd.NodeAttribute {
+	ret := *ins
+	ret.Addresses = append(ret.Addresses[:0:0], ins.Addresses...)
+	ret.LockReasons = append(ret.LockReasons[:0:0], ins.LockReasons...)
+	if ins.Tags != nil {
+		ret.Tags = make(map[string]string, len(ins.Tags))
+		for k, v := range ins.Tags {
+			ret.Tags[k] = v
+		}
+	}
+	return &ret
+}
+
+func (f *FakeCloud) ListInstances(ctx context.Context, ids []string) (map[string]*prvd.NodeAttribute, error) {
+	err := f.begin("ListInstances")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	mins := make(map[string]*prvd.NodeAttribute)
+	for _, id := range ids {
+		_, nodeID, err := util.NodeFromProviderID(id)
+		if err != nil {
+			return nil, err
+		}
+		mins[id] = nil
+		if ins, ok := f.instances[nodeID]; ok {
+			mins[id] = copyNodeAttribute(ins)
+		}
+	}
+	return mins, nil
+}
+
+func (f *FakeCloud) GetInstancesByIP(ctx context.Context, ips []string) (*prvd.NodeAttribute, error) {
+	err := f.begin("GetInstancesByIP")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("describe instances by ip %s error: %s", ips, err.Error())
+	}
+	var found []*prvd.NodeAttribute
+	for _, ins := range f.instances {
+		for _, addr := range ins.Addresses {
+			if containsString(ips, addr.Address) {
+				found = append(found, ins)
+				break
+			}
+		}
+	}
+	if len(found) != 1 {
+		return nil, fmt.Errorf("find none or multiple instances by ip %s", ips)
+	}
+	return copyNodeAttribute(found[0]), nil
+}
+
+func (f *FakeCloud) DescribeNetworkInterfaces(ctx context.Context, vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error) {
+	result := make(map[string]string)
+	err := f.begin("DescribeNetworkInterfaces")
+	defer f.lock.Unlock()
+	if err != nil {
+		return result, err
+	}
+	for _, eni := range f.enis {
+		if eni.vpcId != vpcId || eni.attr.Status != "InUse" {
+			continue
+		}
+		if ipVersionType == model.IPv6 {
+			for _, ip := range eni.ipv6 {
+				if containsString(ips, ip) {
+					result[ip] = eni.attr.NetworkInterfaceID
+				}
+			}
+		} else if containsString(ips, eni.attr.PrivateIPAddress) {
+			result[eni.attr.PrivateIPAddress] = eni.attr.NetworkInterfaceID
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeCloud) DescribeNetworkInterfacesByIDs(ctx context.Context, ids []string) ([]*prvd.EniAttribute, error) {
+	err := f.begin("DescribeNetworkInterfacesByIDs")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var result []*prvd.EniAttribute
+	for _, id := range ids {
+		if eni, ok := f.enis[id]; ok {
+			attr := eni.attr
+			result = append(result, &attr)
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeCloud) ModifyNetworkInterfaceSourceDestCheck(ctx context.Context, id string, enabled bool) error {
+	err := f.begin("ModifyNetworkInterfaceSourceDestCheck")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	eni, ok := f.enis[id]
+	if !ok {
+		return notFoundError("ModifyNetworkInterfaceAttribute", "InvalidEniId.NotFound", "NetworkInterfaceId", id)
+	}
+	eni.attr.SourceDestCheck = enabled
+	return nil
+}
+
+func (f *FakeCloud) getSecurityGroup(api, sgId string) (*fakeSecurityGroup, error) {
+	sg, ok := f.sgs[sgId]
+	if !ok {
+		return nil, notFoundError(api, "InvalidSecurityGroupId.NotFound", "SecurityGroupId", sgId)
+	}
+	return sg, nil
+}
+
+// permissionKey identifies a security group rule by the fields which can not be modified
+func permissionKey(perm ecsmodel.SecurityGroupPermission) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s/%s/%s/%s/%s/%s", perm.Policy, perm.Priority, perm.IpProtocol, perm.PortRange,
+		perm.SourcePortRange, perm.SourceCidrIp, perm.Ipv6SourceCidrIp, perm.SourceGroupId, perm.SourcePrefixListId,
+		perm.NicType)
+}
+
+func (f *FakeCloud) CreateSecurityGroup(ctx context.Context, sg ecsmodel.SecurityGroup) error {
+	err := f.begin("CreateSecurityGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if sg.VpcID == "" {
+		return invalidParameterError("CreateSecurityGroup", "InvalidVpcId.Malformed", "The specified VpcId is invalid.")
+	}
+	sg.ID = f.nextID("sg")
+	sg.Tags = copyTags(sg.Tags)
+	sg.Permissions = nil
+	if sg.InnerAccessPolicy == "" {
+		sg.InnerAccessPolicy = "Accept"
+	}
+	f.sgs[sg.ID] = &fakeSecurityGroup{sg: sg}
+	return nil
+}
+
+func (f *FakeCloud) DescribeSecurityGroups(ctx context.Context, tags []tag.Tag) ([]ecsmodel.SecurityGroup, error) {
+	err := f.begin("DescribeSecurityGroups")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var result []ecsmodel.SecurityGroup
+	for _, sg := range f.sgs {
+		if !hasAllTags(sg.sg.Tags, tags) {
+			continue
+		}
+		result = append(result, ecsmodel.SecurityGroup{
+			ID:   sg.sg.ID,
+			Name: sg.sg.Name,
+			Type: sg.sg.Type,
+			Tags: copyTags(sg.sg.Tags),
+		})
+	}
+	return result, nil
+}
+
+func (f *FakeCloud) DescribeSecurityGroupAttribute(ctx context.Context, sgId string) (ecsmodel.SecurityGroup, error) {
+	err := f.begin("DescribeSecurityGroupAttribute")
+	defer f.lock.Unlock()
+	if err != nil {
+		return ecsmodel.SecurityGroup{}, err
+	}
+	sg, err := f.getSecurityGroup("DescribeSecurityGroupAttribute", sgId)
+	if err != nil {
+		return ecsmodel.SecurityGroup{}, err
+	}
+	return ecsmodel.SecurityGroup{
+		Name:              sg.sg.Name,
+		ID:                sg.sg.ID,
+		InnerAccessPolicy: sg.sg.InnerAccessPolicy,
+		Description:       sg.sg.Description,
+		Permissions:       append([]ecsmodel.SecurityGroupPermission(nil), sg.sg.Permissions...),
+	}, nil
+}
+
+func (f *FakeCloud) DeleteSecurityGroup(ctx context.Context, sgId string) error {
+	err := f.begin("DeleteSecurityGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, err := f.getSecurityGroup("DeleteSecurityGroup", sgId); err != nil {
+		return err
+	}
+	delete(f.sgs, sgId)
+	return nil
+}
+
+func (f *FakeCloud) AuthorizeSecurityGroup(ctx context.Context, sgId string, permissions []ecsmodel.SecurityGroupPermission) error {
+	err := f.begin("AuthorizeSecurityGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+	sg, err := f.getSecurityGroup("AuthorizeSecurityGroup", sgId)
+	if err != nil {
+		return err
+	}
+	for _, perm := range permissions {
+		exist := false
+		for _, old := range sg.sg.Permissions {
+			if permissionKey(old) == permissionKey(perm) {
+				exist = true
+				break
+			}
+		}
+		if exist {
+			// authorizing an existing rule succeeds without changes
+			continue
+		}
+		perm.Direction = "ingress"
+		perm.SecurityGroupRuleId = f.nextID("sgr")
+		sg.sg.Permissions = append(sg.sg.Permissions, perm)
+	}
+	return nil
+}
+
+func (f *FakeCloud) RevokeSecurityGroup(ctx context.Context, sgId string, permissions []ecsmodel.SecurityGroupPermission) error {
+	err := f.begin("RevokeSecurityGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+	sg, err := f.getSecurityGroup("RevokeSecurityGroup", sgId)
+	if err != nil {
+		return err
+	}
+	var remain []ecsmodel.SecurityGroupPermission
+	for _, old := range sg.sg.Permissions {
+		revoked := false
+		for _, perm := range permissions {
+			if permissionKey(old) == permissionKey(perm) {
+				revoked = true
+				break
+			}
+		}
+		if !revoked {
+			remain = append(remain, old)
+		}
+	}
+	sg.sg.Permissions = remain
+	return nil
+}
+
+func (f *FakeCloud) ModifySecurityGroupAttribute(ctx context.Context, sgId string, sg *ecsmodel.SecurityGroup) error {
+	err := f.begin("ModifySecurityGroupAttribute")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	remote, err := f.getSecurityGroup("ModifySecurityGroupAttribute", sgId)
+	if err != nil {
+		return err
+	}
+	if sg.Name != "" {
+		remote.sg.Name = sg.Name
+	}
+	if sg.Description != "" {
+		remote.sg.Description = sg.Description
+	}
+	return nil
+}
+
+func (f *FakeCloud) ModifySecurityGroupRule(ctx context.Context, sgId string, permission ecsmodel.SecurityGroupPermission) error {
+	err := f.begin("ModifySecurityGroupRule")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	sg, err := f.getSecurityGroup("ModifySecurityGroupRule", sgId)
+	if err != nil {
+		return err
+	}
+	for i := range sg.sg.Permissions {
+		if sg.sg.Permissions[i].SecurityGroupRuleId == permission.SecurityGroupRuleId {
+			permission.Direction = sg.sg.Permissions[i].Direction
+			sg.sg.Permissions[i] = permission
+			return nil
+		}
+	}
+	return notFoundError("ModifySecurityGroupRule", "InvalidSecurityGroupRuleId.NotFound",
+		"SecurityGroupRuleId", permission.SecurityGroupRuleId)
+}
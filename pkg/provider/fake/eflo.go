@@ -0,0 +1,28 @@
+package fake
+
+import (
+	"context"
+
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+)
+
+// AddLingJunNode adds a LingJun node to the fake cloud.
+func (f *FakeCloud) AddLingJunNode(node *prvd.EFLONodeAttribute) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.lingjun[node.NodeID] = node
+}
+
+func (f *FakeCloud) DescribeLingJunNode(ctx context.Context, id string) (*prvd.EFLONodeAttribute, error) {
+	err := f.begin("DescribeLingJunNode")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	node, ok := f.lingjun[id]
+	if !ok {
+		return nil, nil
+	}
+	ret := *node
+	return &ret, nil
+}
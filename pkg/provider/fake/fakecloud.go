@@ -0,0 +1,324 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sync"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/vmock"
+)
+
+const (
+	// AnyAPI matches every api when injecting errors
+	AnyAPI = "*"
+
+	DefaultRegion = "cn-hangzhou"
+	DefaultVpcID  = "vpc-fake"
+	DefaultZoneID = "cn-hangzhou-a"
+)
+
+var _ prvd.Provider = &FakeCloud{}
+
+// FakeCloud is a stateful in-memory implementation of prvd.Provider for integration tests.
+// Resources created through the provider interface can be described, updated and deleted later,
+// and the constraints which the controllers rely on are enforced with the error codes of the real
+// OpenAPIs, e.g. InvalidLoadBalancerId.NotFound or InvalidCIDRBlock.Duplicate.
+// Errors and throttling of a single api can be injected with InjectError and InjectThrottling,
+// apis are identified by the method names of prvd.Provider.
+type FakeCloud struct {
+	prvd.IMetaData
+
+	lock   sync.Mutex
+	seq    int
+	faults map[string][]*fault
+	calls  map[string]int
+
+	// CLB
+	clbs    map[string]*fakeCLB
+	vgroups map[string]*fakeVGroup
+	acls    map[string]*fakeACL
+	domains map[string]*fakeDomainExtension
+
+	// NLB
+	nlbs       map[string]*fakeNLB
+	nlbSGs     map[string]*fakeNLBServerGroup
+	nlbLis     map[string]*fakeNLBListener
+	nlbJobs    map[string]error
+	clbCerts   map[string]*fakeServerCertificate
+	casCerts   map[int64]*fakeCASCertificate
+	albs       map[string]*fakeALB
+	albLis     map[string]*fakeALBListener
+	albRules   map[string]*fakeALBRule
+	albSGs     map[string]*fakeALBServerGroup
+	routes     map[string][]*fakeRoute
+	vswitches  map[string]*fakeVSwitch
+	instances  map[string]*prvd.NodeAttribute
+	enis       map[string]*fakeENI
+	sgs        map[string]*fakeSecurityGroup
+	pvtzRecord map[int64]*fakePvtzRecord
+	lingjun    map[string]*prvd.EFLONodeAttribute
+	// unhealthy is keyed by healthKey
+	unhealthy map[string]bool
+}
+
+type fault struct {
+	err error
+	// times is the number of remaining failures, a negative value means always
+	times int
+}
+
+// NewFakeCloud returns an empty fake cloud. If meta is nil, the metadata of vmock is used.
+func NewFakeCloud(meta prvd.IMetaData) *FakeCloud {
+	if meta == nil {
+		meta = vmock.NewMockMetaData(DefaultVpcID)
+	}
+	return &FakeCloud{
+		IMetaData:  meta,
+		faults:     make(map[string][]*fault),
+		calls:      make(map[string]int),
+		clbs:       make(map[string]*fakeCLB),
+		vgroups:    make(map[string]*fakeVGroup),
+		acls:       make(map[string]*fakeACL),
+		domains:    make(map[string]*fakeDomainExtension),
+		nlbs:       make(map[string]*fakeNLB),
+		nlbSGs:     make(map[string]*fakeNLBServerGroup),
+		nlbLis:     make(map[string]*fakeNLBListener),
+		nlbJobs:    make(map[string]error),
+		clbCerts:   make(map[string]*fakeServerCertificate),
+		casCerts:   make(map[int64]*fakeCASCertificate),
+		albs:       make(map[string]*fakeALB),
+		albLis:     make(map[string]*fakeALBListener),
+		albRules:   make(map[string]*fakeALBRule),
+		albSGs:     make(map[string]*fakeALBServerGroup),
+		routes:     make(map[string][]*fakeRoute),
+		vswitches:  make(map[string]*fakeVSwitch),
+		instances:  make(map[string]*prvd.NodeAttribute),
+		enis:       make(map[string]*fakeENI),
+		sgs:        make(map[string]*fakeSecurityGroup),
+		pvtzRecord: make(map[int64]*fakePvtzRecord),
+		lingjun:    make(map[string]*prvd.EFLONodeAttribute),
+		unhealthy:  make(map[string]bool),
+	}
+}
+
+// InjectError makes the next times calls of api fail with err, a negative times fails all the calls
+// and a zero times injects nothing. Use AnyAPI to inject the error to all apis.
+func (f *FakeCloud) InjectError(api string, err error, times int) {
+	if times == 0 {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.faults[api] = append(f.faults[api], &fault{err: err, times: times})
+}
+
+// SetBackendHealth sets the health check result of a clb or nlb backend, backends are healthy by default.
+func (f *FakeCloud) SetBackendHealth(serverId, serverIp string, port int, healthy bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if healthy {
+		delete(f.unhealthy, healthKey(serverId, serverIp, port))
+		return
+	}
+	f.unhealthy[healthKey(serverId, serverIp, port)] = true
+}
+
+func healthKey(serverId, serverIp string, port int) string {
+	return fmt.Sprintf("%s/%s/%d", serverId, serverIp, port)
+}
+
+// InjectThrottling makes the next times calls of api fail with a Throttling.User error.
+func (f *FakeCloud) InjectThrottling(api string, times int) {
+	f.InjectError(api, NewAPIError(api, http.StatusBadRequest, "Throttling.User",
+		"Request was denied due to user flow control."), times)
+}
+
+// ClearFaults removes all the injected errors.
+func (f *FakeCloud) ClearFaults() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.faults = make(map[string][]*fault)
+}
+
+// CallCount returns the number of calls of api, including the failed ones.
+func (f *FakeCloud) CallCount(api string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.calls[api]
+}
+
+// NewAPIError builds an error in the same format as the errors returned by the providers of alibaba cloud.
+func NewAPIError(api string, httpStatus int, code, message string) error {
+	content, _ := json.Marshal(map[string]string{
+		"Code":      code,
+		"Message":   message,
+		"RequestId": "fake-request-id",
+	})
+	return util.SDKError(api, errors.NewServerError(httpStatus, string(content), ""))
+}
+
+func notFoundError(api, code, resource, id string) error {
+	return NewAPIError(api, http.StatusNotFound, code, fmt.Sprintf("The specified %s %s does not exist.", resource, id))
+}
+
+func invalidParameterError(api, code, message string) error {
+	return NewAPIError(api, http.StatusBadRequest, code, message)
+}
+
+var apiErrorPattern = regexp.MustCompile(`ErrorCode: ([^,]*), RequestId: [^,]*, Message: (.*)$`)
+
+// errorCodeAndMessage extracts the error code and message from an error built by NewAPIError,
+// it is used by the batch apis which report the failed items in the response.
+func errorCodeAndMessage(err error) (string, string) {
+	m := apiErrorPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return "", err.Error()
+	}
+	return m[1], m[2]
+}
+
+// begin locks the cloud for an api call and returns the injected error if there is one,
+// the caller must call f.lock.Unlock when it is done.
+func (f *FakeCloud) begin(api string) error {
+	f.lock.Lock()
+	f.calls[api]++
+	if err := f.popFault(api); err != nil {
+		return err
+	}
+	return f.popFault(AnyAPI)
+}
+
+func (f *FakeCloud) popFault(api string) error {
+	faults := f.faults[api]
+	if len(faults) == 0 {
+		return nil
+	}
+	ft := faults[0]
+	if ft.times > 0 {
+		ft.times--
+		if ft.times == 0 {
+			f.faults[api] = faults[1:]
+		}
+	}
+	return ft.err
+}
+
+func (f *FakeCloud) nextID(prefix string) string {
+	f.seq++
+	return fmt.Sprintf("%s-fake%08d", prefix, f.seq)
+}
+
+func copyTags(tags []tag.Tag) []tag.Tag {
+	if tags == nil {
+		return nil
+	}
+	ret := make([]tag.Tag, len(tags))
+	copy(ret, tags)
+	return ret
+}
+
+// hasAnyTag matches tags using logic operator OR, as DescribeLoadBalancers does.
+func hasAnyTag(tags []tag.Tag, filters []tag.Tag) bool {
+	for _, filter := range filters {
+		for _, t := range tags {
+			if t.Key == filter.Key && t.Value == filter.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasAllTags matches tags using logic operator AND, as the list apis of NLB and ALB do.
+func hasAllTags(tags []tag.Tag, filters []tag.Tag) bool {
+	for _, filter := range filters {
+		found := false
+		for _, t := range tags {
+			if t.Key == filter.Key && t.Value == filter.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeTags(tags []tag.Tag, add []tag.Tag) []tag.Tag {
+	for _, a := range add {
+		found := false
+		for i := range tags {
+			if tags[i].Key == a.Key {
+				tags[i].Value = a.Value
+				found = true
+				break
+			}
+		}
+		if !found {
+			tags = append(tags, a)
+		}
+	}
+	return tags
+}
+
+func removeTags(tags []tag.Tag, keys []string) []tag.Tag {
+	var ret []tag.Tag
+	for _, t := range tags {
+		remove := false
+		for _, k := range keys {
+			if t.Key == k {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+func tagsToMap(tags []tag.Tag) map[string]string {
+	ret := make(map[string]string, len(tags))
+	for _, t := range tags {
+		ret[t.Key] = t.Value
+	}
+	return ret
+}
+
+func mapToTags(m map[string]string) []tag.Tag {
+	var ret []tag.Tag
+	for k, v := range m {
+		ret = append(ret, tag.Tag{Key: k, Value: v})
+	}
+	return ret
+}
+
+// mergeNonZero sets the non-zero fields of src to dst, dst and src must be pointers to the same struct type.
+func mergeNonZero(dst, src interface{}, skip ...string) {
+	d := reflect.ValueOf(dst).Elem()
+	s := reflect.ValueOf(src).Elem()
+	for i := 0; i < s.NumField(); i++ {
+		name := s.Type().Field(i).Name
+		skipped := false
+		for _, k := range skip {
+			if k == name {
+				skipped = true
+				break
+			}
+		}
+		if skipped || s.Field(i).IsZero() {
+			continue
+		}
+		d.Field(i).Set(s.Field(i))
+	}
+}
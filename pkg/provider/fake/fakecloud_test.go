@@ -0,0 +1,204 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	ecsmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/ecs"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+)
+
+func TestLoadBalancerLifecycle(t *testing.T) {
+	f := NewFakeCloud(nil)
+	ctx := context.TODO()
+
+	mdl := &model.LoadBalancer{
+		LoadBalancerAttribute: model.LoadBalancerAttribute{
+			LoadBalancerName: "test",
+			Tags:             []tag.Tag{{Key: "kubernetes.do.not.delete", Value: "svc"}},
+		},
+	}
+	assert.NoError(t, f.CreateLoadBalancer(ctx, mdl, "token"))
+	lbId := mdl.LoadBalancerAttribute.LoadBalancerId
+	assert.NotEmpty(t, lbId)
+
+	// creating with the same client token is idempotent
+	retry := &model.LoadBalancer{}
+	assert.NoError(t, f.CreateLoadBalancer(ctx, retry, "token"))
+	assert.Equal(t, lbId, retry.LoadBalancerAttribute.LoadBalancerId)
+	assert.Len(t, f.LoadBalancerIds(), 1)
+
+	found := &model.LoadBalancer{
+		LoadBalancerAttribute: model.LoadBalancerAttribute{
+			Tags: []tag.Tag{{Key: "kubernetes.do.not.delete", Value: "svc"}},
+		},
+	}
+	assert.NoError(t, f.FindLoadBalancer(ctx, found))
+	assert.Equal(t, lbId, found.LoadBalancerAttribute.LoadBalancerId)
+
+	assert.NoError(t, f.SetLoadBalancerDeleteProtection(ctx, lbId, string(model.OnFlag)))
+	err := f.DeleteLoadBalancer(ctx, mdl)
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "DeleteProtectionIsOn"))
+
+	assert.NoError(t, f.SetLoadBalancerDeleteProtection(ctx, lbId, string(model.OffFlag)))
+	assert.NoError(t, f.DeleteLoadBalancer(ctx, mdl))
+	err = f.DescribeLoadBalancer(ctx, mdl)
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "InvalidLoadBalancerId.NotFound"))
+}
+
+func TestInjectError(t *testing.T) {
+	f := NewFakeCloud(nil)
+	ctx := context.TODO()
+
+	f.InjectThrottling("CreateLoadBalancer", 2)
+	for i := 0; i < 2; i++ {
+		err := f.CreateLoadBalancer(ctx, &model.LoadBalancer{}, "")
+		assert.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "Throttling.User"))
+	}
+	assert.NoError(t, f.CreateLoadBalancer(ctx, &model.LoadBalancer{}, ""))
+	assert.Equal(t, 3, f.CallCount("CreateLoadBalancer"))
+
+	injected := errors.New("injected")
+	// zero times injects nothing
+	f.InjectError("CreateLoadBalancer", injected, 0)
+	assert.NoError(t, f.CreateLoadBalancer(ctx, &model.LoadBalancer{}, ""))
+
+	// the faults are keyed by the name of the method
+	f.InjectError("DeleteALBListenerRule", injected, 1)
+	assert.NoError(t, f.DeleteALBListenerRules(ctx, nil))
+	assert.Equal(t, injected, f.DeleteALBListenerRule(ctx, "rule-1"))
+	assert.Equal(t, 1, f.CallCount("DeleteALBListenerRule"))
+	assert.Equal(t, 1, f.CallCount("DeleteALBListenerRules"))
+
+	f.InjectError(AnyAPI, injected, -1)
+	_, err := f.ListRoute(ctx, DefaultRouteTableID)
+	assert.Error(t, err)
+	f.ClearFaults()
+	_, err = f.ListRoute(ctx, DefaultRouteTableID)
+	assert.NoError(t, err)
+}
+
+func TestRoutes(t *testing.T) {
+	f := NewFakeCloud(nil)
+	ctx := context.TODO()
+	pvid := "cn-hangzhou.i-xxx"
+
+	route, err := f.CreateRoute(ctx, DefaultRouteTableID, pvid, "172.16.0.0/24")
+	assert.NoError(t, err)
+	assert.Equal(t, pvid, route.ProviderId)
+
+	_, err = f.CreateRoute(ctx, DefaultRouteTableID, pvid, "172.16.0.0/24")
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "InvalidCIDRBlock.Duplicate"))
+
+	_, statuses, err := f.CreateRoutes(ctx, DefaultRouteTableID, []*model.Route{
+		{ProviderId: pvid, DestinationCIDR: "172.16.0.0/24"},
+		{ProviderId: "cn-hangzhou.i-yyy", DestinationCIDR: "172.16.1.0/24"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, statuses[0].Failed)
+	assert.Equal(t, "InvalidCIDRBlock.Duplicate", statuses[0].FailedCode)
+	assert.False(t, statuses[1].Failed)
+
+	found, err := f.FindRoute(ctx, DefaultRouteTableID, pvid, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "172.16.0.0/24", found.DestinationCIDR)
+
+	assert.NoError(t, f.DeleteRoute(ctx, DefaultRouteTableID, pvid, "172.16.0.0/24"))
+	// deleting a removed route succeeds
+	assert.NoError(t, f.DeleteRoute(ctx, DefaultRouteTableID, pvid, "172.16.0.0/24"))
+	found, err = f.FindRoute(ctx, DefaultRouteTableID, pvid, "")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+	assert.Equal(t, 1, f.RouteCount(DefaultRouteTableID))
+}
+
+func TestSecurityGroup(t *testing.T) {
+	f := NewFakeCloud(nil)
+	ctx := context.TODO()
+	tags := []tag.Tag{{Key: "cluster", Value: "c1"}}
+
+	assert.NoError(t, f.CreateSecurityGroup(ctx, ecsmodel.SecurityGroup{Name: "sg", VpcID: DefaultVpcID, Tags: tags}))
+	sgs, err := f.DescribeSecurityGroups(ctx, tags)
+	assert.NoError(t, err)
+	assert.Len(t, sgs, 1)
+
+	perm := ecsmodel.SecurityGroupPermission{
+		Policy: ecsmodel.SecurityGroupPolicyAccept, Priority: "1", IpProtocol: "TCP",
+		PortRange: "80/80", SourceCidrIp: "0.0.0.0/0",
+	}
+	assert.NoError(t, f.AuthorizeSecurityGroup(ctx, sgs[0].ID, []ecsmodel.SecurityGroupPermission{perm}))
+	assert.NoError(t, f.AuthorizeSecurityGroup(ctx, sgs[0].ID, []ecsmodel.SecurityGroupPermission{perm}))
+	sg, err := f.DescribeSecurityGroupAttribute(ctx, sgs[0].ID)
+	assert.NoError(t, err)
+	assert.Len(t, sg.Permissions, 1)
+
+	assert.NoError(t, f.RevokeSecurityGroup(ctx, sgs[0].ID, []ecsmodel.SecurityGroupPermission{perm}))
+	sg, err = f.DescribeSecurityGroupAttribute(ctx, sgs[0].ID)
+	assert.NoError(t, err)
+	assert.Len(t, sg.Permissions, 0)
+}
+
+func TestPVTZ(t *testing.T) {
+	f := NewFakeCloud(nil)
+	ctx := context.TODO()
+	f.AddUnmanagedPvtzRecord("svc.ns", model.RecordTypeA, "10.0.0.1")
+
+	ep := &model.PvtzEndpoint{
+		Rr:     "svc.ns",
+		Type:   model.RecordTypeA,
+		Ttl:    60,
+		Values: []model.PvtzValue{{Data: "10.0.0.2"}, {Data: "10.0.0.3"}},
+	}
+	assert.NoError(t, f.UpdatePVTZ(ctx, ep))
+	eps, err := f.SearchPVTZ(ctx, &model.PvtzEndpoint{Rr: "svc.ns"}, true)
+	assert.NoError(t, err)
+	assert.Len(t, eps, 1)
+	assert.Equal(t, "10.0.0.2,10.0.0.3", eps[0].ValueString())
+
+	ep = &model.PvtzEndpoint{Rr: "svc.ns", Type: model.RecordTypeA, Values: []model.PvtzValue{{Data: "10.0.0.3"}}}
+	assert.NoError(t, f.UpdatePVTZ(ctx, ep))
+	eps, err = f.ListPVTZ(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, eps, 1)
+	assert.Equal(t, "10.0.0.3", eps[0].ValueString())
+
+	assert.NoError(t, f.DeletePVTZ(ctx, &model.PvtzEndpoint{Rr: "svc.ns", Type: model.RecordTypeA}))
+	eps, err = f.ListPVTZ(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, eps, 0)
+}
+
+func TestHealthStatus(t *testing.T) {
+	f := NewFakeCloud(nil)
+	ctx := context.TODO()
+
+	mdl := &model.LoadBalancer{}
+	assert.NoError(t, f.CreateLoadBalancer(ctx, mdl, ""))
+	lbId := mdl.LoadBalancerAttribute.LoadBalancerId
+	vg := &model.VServerGroup{VGroupName: "vg"}
+	assert.NoError(t, f.CreateVServerGroup(ctx, vg, lbId))
+	assert.NoError(t, f.AddVServerGroupBackendServers(ctx, vg.VGroupId,
+		`[{"ServerId":"eni-1","ServerIp":"10.0.0.1","Port":80,"Type":"eni","Weight":100},`+
+			`{"ServerId":"eni-1","ServerIp":"10.0.0.2","Port":80,"Type":"eni","Weight":100}]`))
+	assert.NoError(t, f.CreateLoadBalancerTCPListener(ctx, lbId, model.ListenerAttribute{ListenerPort: 80, VGroupId: vg.VGroupId}))
+
+	f.SetBackendHealth("eni-1", "10.0.0.2", 80, false)
+	status, err := f.DescribeHealthStatus(ctx, lbId)
+	assert.NoError(t, err)
+	assert.Len(t, status, 2)
+	for _, s := range status {
+		if s.ServerIp == "10.0.0.2" {
+			assert.Equal(t, model.HealthStatusAbnormal, s.Status)
+		} else {
+			assert.Equal(t, model.HealthStatusNormal, s.Status)
+		}
+	}
+}
@@ -0,0 +1,1037 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	nlbmodel "k8s.io/cloud-provider-alibaba-cloud/pkg/model/nlb"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+)
+
+const nlbListenerStatusRunning = nlbmodel.ListenerStatus("Running")
+
+type fakeNLB struct {
+	attr        nlbmodel.LoadBalancerAttribute
+	clientToken string
+}
+
+type fakeNLBServerGroup struct {
+	sg      nlbmodel.ServerGroup
+	servers []nlbmodel.ServerGroupServer
+}
+
+type fakeNLBListener struct {
+	lis   nlbmodel.ListenerAttribute
+	certs []string
+}
+
+// GetNLB returns a copy of the nlb in the fake cloud with its listeners, or nil if it does not exist.
+func (f *FakeCloud) GetNLB(lbId string) *nlbmodel.NetworkLoadBalancer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	lb, ok := f.nlbs[lbId]
+	if !ok {
+		return nil
+	}
+	mdl := &nlbmodel.NetworkLoadBalancer{LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{}}
+	loadNLB(lb.attr, mdl)
+	for _, l := range f.nlbLis {
+		if l.lis.LoadBalancerId == lbId {
+			lis := l.lis
+			mdl.Listeners = append(mdl.Listeners, &lis)
+		}
+	}
+	return mdl
+}
+
+func loadNLB(attr nlbmodel.LoadBalancerAttribute, mdl *nlbmodel.NetworkLoadBalancer) {
+	a := mdl.LoadBalancerAttribute
+	a.LoadBalancerId = attr.LoadBalancerId
+	a.VpcId = attr.VpcId
+	a.Name = attr.Name
+	a.AddressType = attr.AddressType
+	a.IPv6AddressType = attr.IPv6AddressType
+	a.AddressIpVersion = attr.AddressIpVersion
+	a.LoadBalancerStatus = attr.LoadBalancerStatus
+	a.ResourceGroupId = attr.ResourceGroupId
+	a.DNSName = attr.DNSName
+	a.SecurityGroupIds = append([]string(nil), attr.SecurityGroupIds...)
+	a.BandwidthPackageId = attr.BandwidthPackageId
+	a.CrossZoneEnabled = attr.CrossZoneEnabled
+	a.ZoneMappings = append([]nlbmodel.ZoneMapping(nil), attr.ZoneMappings...)
+	a.Tags = copyTags(attr.Tags)
+}
+
+func (f *FakeCloud) getNLB(api, lbId string) (*fakeNLB, error) {
+	lb, ok := f.nlbs[lbId]
+	if !ok {
+		return nil, notFoundError(api, "ResourceNotFound.loadBalancer", "loadBalancer", lbId)
+	}
+	return lb, nil
+}
+
+// newJob records a finished async job of nlb
+func (f *FakeCloud) newJob(err error) string {
+	id := f.nextID("job")
+	f.nlbJobs[id] = err
+	return id
+}
+
+// Tag
+
+func (f *FakeCloud) TagNLBResource(ctx context.Context, resourceId string, resourceType nlbmodel.TagResourceType, tags []tag.Tag) error {
+	err := f.begin("TagNLBResource")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	switch resourceType {
+	case nlbmodel.LoadBalancerTagType:
+		lb, err := f.getNLB("TagResources", resourceId)
+		if err != nil {
+			return err
+		}
+		lb.attr.Tags = mergeTags(lb.attr.Tags, tags)
+	case nlbmodel.ServerGroupTagType:
+		sg, err := f.getNLBServerGroup("TagResources", resourceId)
+		if err != nil {
+			return err
+		}
+		sg.sg.Tags = mergeTags(sg.sg.Tags, tags)
+	default:
+		return invalidParameterError("TagResources", "IllegalParam.ResourceType",
+			fmt.Sprintf("The resource type %s is invalid.", resourceType))
+	}
+	return nil
+}
+
+func (f *FakeCloud) UntagNLBResources(ctx context.Context, resourceId string, resourceType nlbmodel.TagResourceType, tagKey []*string) error {
+	err := f.begin("UntagNLBResources")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	var keys []string
+	for _, k := range tagKey {
+		if k != nil {
+			keys = append(keys, *k)
+		}
+	}
+	switch resourceType {
+	case nlbmodel.LoadBalancerTagType:
+		lb, err := f.getNLB("UntagResources", resourceId)
+		if err != nil {
+			return err
+		}
+		lb.attr.Tags = removeTags(lb.attr.Tags, keys)
+	case nlbmodel.ServerGroupTagType:
+		sg, err := f.getNLBServerGroup("UntagResources", resourceId)
+		if err != nil {
+			return err
+		}
+		sg.sg.Tags = removeTags(sg.sg.Tags, keys)
+	default:
+		return invalidParameterError("UntagResources", "IllegalParam.ResourceType",
+			fmt.Sprintf("The resource type %s is invalid.", resourceType))
+	}
+	return nil
+}
+
+func (f *FakeCloud) ListNLBTagResources(ctx context.Context, lbId string) ([]tag.Tag, error) {
+	err := f.begin("ListNLBTagResources")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	lb, err := f.getNLB("ListTagResources", lbId)
+	if err != nil {
+		return nil, err
+	}
+	return copyTags(lb.attr.Tags), nil
+}
+
+// NetworkLoadBalancer
+
+func (f *FakeCloud) FindNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	if mdl.LoadBalancerAttribute.LoadBalancerId != "" {
+		return f.DescribeNLB(ctx, mdl)
+	}
+
+	err := f.begin("FindNLB")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	find := func(match func(lb *fakeNLB) bool, by string) (bool, error) {
+		var found []*fakeNLB
+		for _, lb := range f.nlbs {
+			if match(lb) {
+				found = append(found, lb)
+			}
+		}
+		if len(found) > 1 {
+			var ids []string
+			for _, lb := range found {
+				ids = append(ids, lb.attr.LoadBalancerId)
+			}
+			return false, fmt.Errorf("[%s] find multiple loadbalances by %s, lbIds%v", mdl.NamespacedName, by, ids)
+		}
+		if len(found) == 1 {
+			loadNLB(found[0].attr, mdl)
+			return true, nil
+		}
+		return false, nil
+	}
+
+	if len(mdl.LoadBalancerAttribute.Tags) != 0 {
+		ok, err := find(func(lb *fakeNLB) bool {
+			return hasAllTags(lb.attr.Tags, mdl.LoadBalancerAttribute.Tags)
+		}, "tag")
+		if ok || err != nil {
+			return err
+		}
+	}
+	if mdl.LoadBalancerAttribute.Name != "" {
+		_, err := find(func(lb *fakeNLB) bool {
+			return lb.attr.Name == mdl.LoadBalancerAttribute.Name
+		}, "name")
+		return err
+	}
+	return nil
+}
+
+func (f *FakeCloud) ListNLBs(ctx context.Context, tags []tag.Tag) ([]*nlbmodel.NetworkLoadBalancer, error) {
+	err := f.begin("ListNLBs")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var ret []*nlbmodel.NetworkLoadBalancer
+	for _, lb := range f.nlbs {
+		if hasAllTags(lb.attr.Tags, tags) {
+			mdl := &nlbmodel.NetworkLoadBalancer{LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{}}
+			loadNLB(lb.attr, mdl)
+			ret = append(ret, mdl)
+		}
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) DescribeNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	err := f.begin("DescribeNLB")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	lb, err := f.getNLB("GetLoadBalancerAttribute", mdl.LoadBalancerAttribute.LoadBalancerId)
+	if err != nil {
+		return err
+	}
+	loadNLB(lb.attr, mdl)
+	return nil
+}
+
+func (f *FakeCloud) CreateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer, clientToken string) error {
+	err := f.begin("CreateNLB")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if clientToken != "" {
+		for _, lb := range f.nlbs {
+			if lb.clientToken == clientToken {
+				mdl.LoadBalancerAttribute.LoadBalancerId = lb.attr.LoadBalancerId
+				return nil
+			}
+		}
+	}
+
+	in := mdl.LoadBalancerAttribute
+	if in.VpcId == "" {
+		return invalidParameterError("CreateLoadBalancer", "MissingParam.VpcId", "The parameter VpcId is required.")
+	}
+	if len(in.ZoneMappings) < 2 {
+		return invalidParameterError("CreateLoadBalancer", "IllegalParam.ZoneMappings",
+			"At least two zones are required in ZoneMappings.")
+	}
+	for _, z := range in.ZoneMappings {
+		if vsw, ok := f.vswitches[z.VSwitchId]; ok && vsw.vsw.AvailableIpAddressCount == 0 {
+			return NewAPIError("CreateLoadBalancer", http.StatusBadRequest, "QuotaInsufficient.VSwitchAvailableIp",
+				fmt.Sprintf("The specified VSwitch %s has no available ip.", z.VSwitchId))
+		}
+	}
+
+	attr := nlbmodel.LoadBalancerAttribute{
+		Name:               in.Name,
+		AddressType:        nlbmodel.GetAddressType(in.AddressType),
+		AddressIpVersion:   nlbmodel.GetAddressIpVersion(in.AddressIpVersion),
+		VpcId:              in.VpcId,
+		ResourceGroupId:    in.ResourceGroupId,
+		Tags:               copyTags(in.Tags),
+		BandwidthPackageId: in.BandwidthPackageId,
+		LoadBalancerId:     f.nextID("nlb"),
+		LoadBalancerStatus: "Active",
+	}
+	if attr.AddressIpVersion == "" {
+		attr.AddressIpVersion = nlbmodel.IPv4
+	}
+	if attr.AddressIpVersion == nlbmodel.DualStack {
+		attr.IPv6AddressType = nlbmodel.IntranetAddressType
+	}
+	attr.DNSName = fmt.Sprintf("%s.%s.nlb.aliyuncsslb.com", attr.LoadBalancerId, DefaultRegion)
+	for _, z := range in.ZoneMappings {
+		zm := z
+		if zm.IPv4Addr == "" {
+			f.seq++
+			zm.IPv4Addr = fmt.Sprintf("192.168.%d.%d", f.seq/250%250, f.seq%250+1)
+		}
+		attr.ZoneMappings = append(attr.ZoneMappings, zm)
+	}
+	if in.DeletionProtectionConfig != nil {
+		cfg := *in.DeletionProtectionConfig
+		attr.DeletionProtectionConfig = &cfg
+	}
+	if in.ModificationProtectionConfig != nil {
+		cfg := *in.ModificationProtectionConfig
+		attr.ModificationProtectionConfig = &cfg
+	}
+
+	f.nlbs[attr.LoadBalancerId] = &fakeNLB{attr: attr, clientToken: clientToken}
+	mdl.LoadBalancerAttribute.LoadBalancerId = attr.LoadBalancerId
+	return nil
+}
+
+func (f *FakeCloud) DeleteNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	err := f.begin("DeleteNLB")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	lbId := mdl.LoadBalancerAttribute.LoadBalancerId
+	lb, err := f.getNLB("DeleteLoadBalancer", lbId)
+	if err != nil {
+		return err
+	}
+	if lb.attr.DeletionProtectionConfig != nil && lb.attr.DeletionProtectionConfig.Enabled {
+		return NewAPIError("DeleteLoadBalancer", http.StatusBadRequest, "OperationDenied.DeletionProtectionEnabled",
+			fmt.Sprintf("The loadbalancer %s can not be deleted because the deletion protection is enabled.", lbId))
+	}
+	for id, l := range f.nlbLis {
+		if l.lis.LoadBalancerId == lbId {
+			delete(f.nlbLis, id)
+		}
+	}
+	delete(f.nlbs, lbId)
+	return nil
+}
+
+func (f *FakeCloud) modifyNLB(api, lbId string, fn func(attr *nlbmodel.LoadBalancerAttribute) error) error {
+	err := f.begin(api)
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	lb, err := f.getNLB(api, lbId)
+	if err != nil {
+		return err
+	}
+	return fn(&lb.attr)
+}
+
+func (f *FakeCloud) UpdateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	return f.modifyNLB("UpdateNLB", mdl.LoadBalancerAttribute.LoadBalancerId,
+		func(attr *nlbmodel.LoadBalancerAttribute) error {
+			if mdl.LoadBalancerAttribute.Name != "" {
+				attr.Name = mdl.LoadBalancerAttribute.Name
+			}
+			if mdl.LoadBalancerAttribute.CrossZoneEnabled != nil {
+				enabled := *mdl.LoadBalancerAttribute.CrossZoneEnabled
+				attr.CrossZoneEnabled = &enabled
+			}
+			return nil
+		})
+}
+
+func (f *FakeCloud) UpdateNLBAddressType(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	return f.modifyNLB("UpdateNLBAddressType", mdl.LoadBalancerAttribute.LoadBalancerId,
+		func(attr *nlbmodel.LoadBalancerAttribute) error {
+			attr.AddressType = nlbmodel.GetAddressType(mdl.LoadBalancerAttribute.AddressType)
+			return nil
+		})
+}
+
+func (f *FakeCloud) UpdateNLBIPv6AddressType(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	return f.modifyNLB("UpdateNLBIPv6AddressType", mdl.LoadBalancerAttribute.LoadBalancerId,
+		func(attr *nlbmodel.LoadBalancerAttribute) error {
+			switch mdl.LoadBalancerAttribute.IPv6AddressType {
+			case nlbmodel.InternetAddressType, nlbmodel.IntranetAddressType:
+			default:
+				return fmt.Errorf("invalid ipv6 address type %s", mdl.LoadBalancerAttribute.IPv6AddressType)
+			}
+			if attr.AddressIpVersion != nlbmodel.DualStack {
+				return NewAPIError("EnableLoadBalancerIpv6Internet", http.StatusBadRequest,
+					"OperationDenied.IpVersionNotSupport", "The loadbalancer does not support ipv6.")
+			}
+			attr.IPv6AddressType = mdl.LoadBalancerAttribute.IPv6AddressType
+			return nil
+		})
+}
+
+func (f *FakeCloud) UpdateNLBZones(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
+	return f.modifyNLB("UpdateNLBZones", mdl.LoadBalancerAttribute.LoadBalancerId,
+		func(attr *nlbmodel.LoadBalancerAttribute) error {
+			if len(mdl.LoadBalancerAttribute.ZoneMappings) < 2 {
+				return invalidParameterError("UpdateLoadBalancerZones", "IllegalParam.ZoneMappings",
+					"At least two zones are required in ZoneMappings.")
+			}
+			var zones []nlbmodel.ZoneMapping
+			for _, z := range mdl.LoadBalancerAttribute.ZoneMappings {
+				zm := z
+				for _, old := range attr.ZoneMappings {
+					if old.ZoneId == z.ZoneId && zm.IPv4Addr == "" {
+						zm.IPv4Addr = old.IPv4Addr
+					}
+				}
+				if zm.IPv4Addr == "" {
+					f.seq++
+					zm.IPv4Addr = fmt.Sprintf("192.168.%d.%d", f.seq/250%250, f.seq%250+1)
+				}
+				zones = append(zones, zm)
+			}
+			attr.ZoneMappings = zones
+			return nil
+		})
+}
+
+func (f *FakeCloud) NLBJoinSecurityGroup(ctx context.Context, lbId string, sgIds []string) error {
+	return f.modifyNLB("NLBJoinSecurityGroup", lbId, func(attr *nlbmodel.LoadBalancerAttribute) error {
+		for _, id := range sgIds {
+			if _, ok := f.sgs[id]; !ok {
+				return notFoundError("LoadBalancerJoinSecurityGroup", "ResourceNotFound.securityGroup", "securityGroup", id)
+			}
+		}
+		for _, id := range sgIds {
+			if !containsString(attr.SecurityGroupIds, id) {
+				attr.SecurityGroupIds = append(attr.SecurityGroupIds, id)
+			}
+		}
+		return nil
+	})
+}
+
+func (f *FakeCloud) NLBLeaveSecurityGroup(ctx context.Context, lbId string, sgIds []string) error {
+	return f.modifyNLB("NLBLeaveSecurityGroup", lbId, func(attr *nlbmodel.LoadBalancerAttribute) error {
+		var ret []string
+		for _, id := range attr.SecurityGroupIds {
+			if !containsString(sgIds, id) {
+				ret = append(ret, id)
+			}
+		}
+		attr.SecurityGroupIds = ret
+		return nil
+	})
+}
+
+func (f *FakeCloud) UpdateLoadBalancerProtection(ctx context.Context, lbId string,
+	delCfg *nlbmodel.DeletionProtectionConfig, modCfg *nlbmodel.ModificationProtectionConfig) error {
+	return f.modifyNLB("UpdateLoadBalancerProtection", lbId, func(attr *nlbmodel.LoadBalancerAttribute) error {
+		if delCfg != nil {
+			cfg := *delCfg
+			attr.DeletionProtectionConfig = &cfg
+		}
+		if modCfg != nil {
+			cfg := *modCfg
+			attr.ModificationProtectionConfig = &cfg
+		}
+		return nil
+	})
+}
+
+func (f *FakeCloud) AttachCommonBandwidthPackageToLoadBalancer(ctx context.Context, lbId string, bandwidthPackageId string) error {
+	return f.modifyNLB("AttachCommonBandwidthPackageToLoadBalancer", lbId, func(attr *nlbmodel.LoadBalancerAttribute) error {
+		if attr.BandwidthPackageId != nil && *attr.BandwidthPackageId != "" {
+			return NewAPIError("AttachCommonBandwidthPackageToLoadBalancer", http.StatusBadRequest,
+				"OperationDenied.BandwidthPackageAlreadyAttached",
+				fmt.Sprintf("The loadbalancer %s is already attached to bandwidth package %s.", lbId, *attr.BandwidthPackageId))
+		}
+		id := bandwidthPackageId
+		attr.BandwidthPackageId = &id
+		return nil
+	})
+}
+
+func (f *FakeCloud) DetachCommonBandwidthPackageFromLoadBalancer(ctx context.Context, lbId string, bandwidthPackageId string) error {
+	return f.modifyNLB("DetachCommonBandwidthPackageFromLoadBalancer", lbId, func(attr *nlbmodel.LoadBalancerAttribute) error {
+		if attr.BandwidthPackageId == nil || *attr.BandwidthPackageId != bandwidthPackageId {
+			return notFoundError("DetachCommonBandwidthPackageFromLoadBalancer",
+				"ResourceNotFound.bandwidthPackage", "bandwidthPackage", bandwidthPackageId)
+		}
+		attr.BandwidthPackageId = nil
+		return nil
+	})
+}
+
+// ServerGroup
+
+func (f *FakeCloud) getNLBServerGroup(api, sgId string) (*fakeNLBServerGroup, error) {
+	sg, ok := f.nlbSGs[sgId]
+	if !ok {
+		return nil, notFoundError(api, "ResourceNotFound.serverGroup", "serverGroup", sgId)
+	}
+	return sg, nil
+}
+
+func (sg *fakeNLBServerGroup) toModel() *nlbmodel.ServerGroup {
+	ret := sg.sg
+	ret.Tags = copyTags(sg.sg.Tags)
+	ret.Servers = append([]nlbmodel.ServerGroupServer(nil), sg.servers...)
+	namedKey, err := nlbmodel.LoadNLBSGNamedKey(ret.ServerGroupName)
+	if err != nil {
+		ret.IsUserManaged = true
+	}
+	ret.NamedKey = namedKey
+	return &ret
+}
+
+func (f *FakeCloud) ListNLBServerGroups(ctx context.Context, tags []tag.Tag) ([]*nlbmodel.ServerGroup, error) {
+	err := f.begin("ListNLBServerGroups")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var ret []*nlbmodel.ServerGroup
+	for _, sg := range f.nlbSGs {
+		if hasAllTags(sg.sg.Tags, tags) {
+			ret = append(ret, sg.toModel())
+		}
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) GetNLBServerGroup(ctx context.Context, sgId string) (*nlbmodel.ServerGroup, error) {
+	err := f.begin("GetNLBServerGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	sg, ok := f.nlbSGs[sgId]
+	if !ok {
+		return nil, nil
+	}
+	return sg.toModel(), nil
+}
+
+func (f *FakeCloud) CreateNLBServerGroup(ctx context.Context, sg *nlbmodel.ServerGroup) error {
+	jobId, err := f.CreateNLBServerGroupAsync(ctx, sg)
+	if err != nil {
+		return err
+	}
+	return f.WaitJobFinish(ctx, "CreateServerGroup", jobId)
+}
+
+func (f *FakeCloud) DeleteNLBServerGroup(ctx context.Context, sgId string) error {
+	_, err := f.DeleteNLBServerGroupAsync(ctx, sgId)
+	return err
+}
+
+func (f *FakeCloud) UpdateNLBServerGroup(ctx context.Context, sg *nlbmodel.ServerGroup) error {
+	_, err := f.UpdateNLBServerGroupAsync(ctx, sg)
+	return err
+}
+
+func (f *FakeCloud) CreateNLBServerGroupAsync(ctx context.Context, sg *nlbmodel.ServerGroup) (string, error) {
+	err := f.begin("CreateNLBServerGroupAsync")
+	defer f.lock.Unlock()
+	if err != nil {
+		return "", err
+	}
+	if sg.ServerGroupName == "" {
+		return "", invalidParameterError("CreateServerGroup", "MissingParam.ServerGroupName",
+			"The parameter ServerGroupName is required.")
+	}
+	stored := nlbmodel.ServerGroup{
+		VPCId:                   sg.VPCId,
+		ServerGroupName:         sg.ServerGroupName,
+		ServerGroupType:         sg.ServerGroupType,
+		ResourceGroupId:         sg.ResourceGroupId,
+		AddressIPVersion:        sg.AddressIPVersion,
+		IpVersionAffinityMode:   sg.IpVersionAffinityMode,
+		Protocol:                nlbmodel.GetListenerProtocolType(sg.Protocol),
+		ConnectionDrainEnabled:  sg.ConnectionDrainEnabled,
+		ConnectionDrainTimeout:  sg.ConnectionDrainTimeout,
+		Scheduler:               sg.Scheduler,
+		PreserveClientIpEnabled: sg.PreserveClientIpEnabled,
+		HealthCheckConfig:       sg.HealthCheckConfig,
+		Tags:                    copyTags(sg.Tags),
+		AnyPortEnabled:          sg.AnyPortEnabled,
+		ServerGroupId:           f.nextID("sgp"),
+	}
+	if stored.ServerGroupType == "" {
+		stored.ServerGroupType = nlbmodel.InstanceServerGroupType
+	}
+	if stored.AddressIPVersion == "" {
+		stored.AddressIPVersion = nlbmodel.IPv4
+	}
+	f.nlbSGs[stored.ServerGroupId] = &fakeNLBServerGroup{sg: stored}
+	sg.ServerGroupId = stored.ServerGroupId
+	return f.newJob(nil), nil
+}
+
+func (f *FakeCloud) DeleteNLBServerGroupAsync(ctx context.Context, sgId string) (string, error) {
+	err := f.begin("DeleteNLBServerGroupAsync")
+	defer f.lock.Unlock()
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.getNLBServerGroup("DeleteServerGroup", sgId); err != nil {
+		return "", err
+	}
+	for _, l := range f.nlbLis {
+		if l.lis.ServerGroupId == sgId {
+			return "", NewAPIError("DeleteServerGroup", http.StatusBadRequest, "ResourceInUse.serverGroup",
+				fmt.Sprintf("The server group %s is used by listener %s.", sgId, l.lis.ListenerId))
+		}
+	}
+	delete(f.nlbSGs, sgId)
+	return f.newJob(nil), nil
+}
+
+func (f *FakeCloud) UpdateNLBServerGroupAsync(ctx context.Context, sg *nlbmodel.ServerGroup) (string, error) {
+	err := f.begin("UpdateNLBServerGroupAsync")
+	defer f.lock.Unlock()
+	if err != nil {
+		return "", err
+	}
+	remote, err := f.getNLBServerGroup("UpdateServerGroupAttribute", sg.ServerGroupId)
+	if err != nil {
+		return "", err
+	}
+	update := nlbmodel.ServerGroup{
+		ServerGroupName:         sg.ServerGroupName,
+		ConnectionDrainEnabled:  sg.ConnectionDrainEnabled,
+		ConnectionDrainTimeout:  sg.ConnectionDrainTimeout,
+		Scheduler:               sg.Scheduler,
+		PreserveClientIpEnabled: sg.PreserveClientIpEnabled,
+		HealthCheckConfig:       sg.HealthCheckConfig,
+		IpVersionAffinityMode:   sg.IpVersionAffinityMode,
+	}
+	mergeNonZero(&remote.sg, &update)
+	return f.newJob(nil), nil
+}
+
+func nlbServerKey(s nlbmodel.ServerGroupServer) string {
+	return fmt.Sprintf("%s/%s/%d", s.ServerId, s.ServerIp, s.Port)
+}
+
+func (f *FakeCloud) AddNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error {
+	jobId, err := f.AddNLBServersAsync(ctx, sgId, backends)
+	if err != nil {
+		return err
+	}
+	return f.WaitJobFinish(ctx, "AddServersToServerGroup", jobId)
+}
+
+func (f *FakeCloud) RemoveNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error {
+	jobId, err := f.RemoveNLBServersAsync(ctx, sgId, backends)
+	if err != nil {
+		return err
+	}
+	return f.WaitJobFinish(ctx, "RemoveServersFromServerGroup", jobId)
+}
+
+func (f *FakeCloud) UpdateNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error {
+	jobId, err := f.UpdateNLBServersAsync(ctx, sgId, backends)
+	if err != nil {
+		return err
+	}
+	return f.WaitJobFinish(ctx, "UpdateServerGroupServersAttribute", jobId)
+}
+
+func (f *FakeCloud) updateNLBServers(api, sgId string, backends []nlbmodel.ServerGroupServer,
+	fn func(sg *fakeNLBServerGroup) error) (string, error) {
+	err := f.begin(api)
+	defer f.lock.Unlock()
+	if err != nil {
+		return "", err
+	}
+	sg, err := f.getNLBServerGroup(api, sgId)
+	if err != nil {
+		return "", err
+	}
+	if len(backends) > MaxBackendNumPerRequest {
+		return "", invalidParameterError(api, "IllegalParam.Servers",
+			fmt.Sprintf("The number of servers %d exceeds the limit %d.", len(backends), MaxBackendNumPerRequest))
+	}
+	if err := fn(sg); err != nil {
+		return "", err
+	}
+	return f.newJob(nil), nil
+}
+
+func (f *FakeCloud) AddNLBServersAsync(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) (string, error) {
+	return f.updateNLBServers("AddNLBServersAsync", sgId, backends, func(sg *fakeNLBServerGroup) error {
+		for _, b := range backends {
+			for _, old := range sg.servers {
+				if nlbServerKey(old) == nlbServerKey(b) {
+					return invalidParameterError("AddServersToServerGroup", "ResourceAlreadyAssociated.server",
+						fmt.Sprintf("The server %s is already associated with the server group.", nlbServerKey(b)))
+				}
+			}
+		}
+		for _, b := range backends {
+			s := nlbmodel.ServerGroupServer{
+				ServerGroupId: sgId,
+				Description:   b.Description,
+				ServerId:      b.ServerId,
+				ServerIp:      b.ServerIp,
+				ServerType:    b.ServerType,
+				Port:          b.Port,
+				Weight:        b.Weight,
+				ZoneId:        b.ZoneId,
+				Status:        "Available",
+			}
+			sg.servers = append(sg.servers, s)
+		}
+		return nil
+	})
+}
+
+func (f *FakeCloud) RemoveNLBServersAsync(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) (string, error) {
+	return f.updateNLBServers("RemoveNLBServersAsync", sgId, backends, func(sg *fakeNLBServerGroup) error {
+		var ret []nlbmodel.ServerGroupServer
+		for _, s := range sg.servers {
+			found := false
+			for _, b := range backends {
+				if nlbServerKey(s) == nlbServerKey(b) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				ret = append(ret, s)
+			}
+		}
+		sg.servers = ret
+		return nil
+	})
+}
+
+func (f *FakeCloud) UpdateNLBServersAsync(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) (string, error) {
+	return f.updateNLBServers("UpdateNLBServersAsync", sgId, backends, func(sg *fakeNLBServerGroup) error {
+		for _, b := range backends {
+			for i := range sg.servers {
+				if nlbServerKey(sg.servers[i]) == nlbServerKey(b) {
+					sg.servers[i].Weight = b.Weight
+					sg.servers[i].Description = b.Description
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Listener
+
+func (f *FakeCloud) getNLBListener(api, listenerId string) (*fakeNLBListener, error) {
+	l, ok := f.nlbLis[listenerId]
+	if !ok {
+		return nil, notFoundError(api, "ResourceNotFound.listener", "listener", listenerId)
+	}
+	return l, nil
+}
+
+func (f *FakeCloud) ListNLBListeners(ctx context.Context, lbId string) ([]*nlbmodel.ListenerAttribute, error) {
+	err := f.begin("ListNLBListeners")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var ret []*nlbmodel.ListenerAttribute
+	for _, l := range f.nlbLis {
+		if l.lis.LoadBalancerId != lbId {
+			continue
+		}
+		lis := l.lis
+		lis.CertificateIds = append([]string(nil), l.lis.CertificateIds...)
+		lis.CaCertificateIds = append([]string(nil), l.lis.CaCertificateIds...)
+		namedKey, err := nlbmodel.LoadNLBListenerNamedKey(lis.ListenerDescription)
+		if err != nil {
+			lis.IsUserManaged = true
+		}
+		lis.NamedKey = namedKey
+		ret = append(ret, &lis)
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) CreateNLBListener(ctx context.Context, lbId string, lis *nlbmodel.ListenerAttribute) error {
+	jobId, err := f.CreateNLBListenerAsync(ctx, lbId, lis)
+	if err != nil {
+		return err
+	}
+	return f.WaitJobFinish(ctx, "CreateListener", jobId)
+}
+
+func (f *FakeCloud) UpdateNLBListener(ctx context.Context, lis *nlbmodel.ListenerAttribute) error {
+	jobId, err := f.UpdateNLBListenerAsync(ctx, lis)
+	if err != nil {
+		return err
+	}
+	return f.WaitJobFinish(ctx, "UpdateListenerAttribute", jobId)
+}
+
+func (f *FakeCloud) DeleteNLBListener(ctx context.Context, listenerId string) error {
+	jobId, err := f.DeleteNLBListenerAsync(ctx, listenerId)
+	if err != nil {
+		return err
+	}
+	return f.WaitJobFinish(ctx, "DeleteListener", jobId)
+}
+
+func (f *FakeCloud) StartNLBListener(ctx context.Context, listenerId string) error {
+	err := f.begin("StartNLBListener")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	l, err := f.getNLBListener("StartListener", listenerId)
+	if err != nil {
+		return err
+	}
+	l.lis.ListenerStatus = nlbListenerStatusRunning
+	return nil
+}
+
+func nlbPortOverlap(a, b nlbmodel.ListenerAttribute) bool {
+	if (a.ListenerProtocol == nlbmodel.UDP) != (b.ListenerProtocol == nlbmodel.UDP) {
+		return false
+	}
+	aStart, aEnd := a.ListenerPort, a.ListenerPort
+	if a.ListenerPort == 0 {
+		aStart, aEnd = a.StartPort, a.EndPort
+	}
+	bStart, bEnd := b.ListenerPort, b.ListenerPort
+	if b.ListenerPort == 0 {
+		bStart, bEnd = b.StartPort, b.EndPort
+	}
+	return aStart <= bEnd && bStart <= aEnd
+}
+
+func (f *FakeCloud) validateNLBListener(api string, lis *nlbmodel.ListenerAttribute) error {
+	if lis.ServerGroupId == "" {
+		return nil
+	}
+	sg, err := f.getNLBServerGroup(api, lis.ServerGroupId)
+	if err != nil {
+		return err
+	}
+	if sg.sg.AnyPortEnabled != (lis.ListenerPort == 0) {
+		return invalidParameterError(api, "IllegalParam.ServerGroupId",
+			fmt.Sprintf("The any port setting of server group %s does not match listener %s.", lis.ServerGroupId, lis.PortString()))
+	}
+	return nil
+}
+
+func (f *FakeCloud) CreateNLBListenerAsync(ctx context.Context, lbId string, lis *nlbmodel.ListenerAttribute) (string, error) {
+	err := f.begin("CreateNLBListenerAsync")
+	defer f.lock.Unlock()
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.getNLB("CreateListener", lbId); err != nil {
+		return "", err
+	}
+	stored := *lis
+	stored.IsUserManaged = false
+	stored.NamedKey = nil
+	stored.ServerGroupName = ""
+	stored.ServicePort = nil
+	stored.AdditionalCertificateIds = nil
+	stored.ListenerProtocol = nlbmodel.GetListenerProtocolType(lis.ListenerProtocol)
+	stored.LoadBalancerId = lbId
+	stored.CertificateIds = append([]string(nil), lis.CertificateIds...)
+	stored.CaCertificateIds = append([]string(nil), lis.CaCertificateIds...)
+	for _, l := range f.nlbLis {
+		if l.lis.LoadBalancerId == lbId && nlbPortOverlap(l.lis, stored) {
+			return "", invalidParameterError("CreateListener", "Conflict.Port",
+				fmt.Sprintf("The port %s conflicts with listener %s.", stored.PortString(), l.lis.ListenerId))
+		}
+	}
+	if stored.ListenerProtocol == nlbmodel.TCPSSL && len(stored.CertificateIds) == 0 {
+		return "", invalidParameterError("CreateListener", "MissingParam.CertificateIds",
+			"The parameter CertificateIds is required for TCPSSL listener.")
+	}
+	if err := f.validateNLBListener("CreateListener", &stored); err != nil {
+		return "", err
+	}
+	stored.ListenerId = f.nextID("lsn")
+	stored.ListenerStatus = nlbListenerStatusRunning
+	f.nlbLis[stored.ListenerId] = &fakeNLBListener{lis: stored}
+	lis.ListenerId = stored.ListenerId
+	return f.newJob(nil), nil
+}
+
+func (f *FakeCloud) UpdateNLBListenerAsync(ctx context.Context, lis *nlbmodel.ListenerAttribute) (string, error) {
+	err := f.begin("UpdateNLBListenerAsync")
+	defer f.lock.Unlock()
+	if err != nil {
+		return "", err
+	}
+	remote, err := f.getNLBListener("UpdateListenerAttribute", lis.ListenerId)
+	if err != nil {
+		return "", err
+	}
+	update := *lis
+	update.ListenerPort = remote.lis.ListenerPort
+	update.StartPort = remote.lis.StartPort
+	update.EndPort = remote.lis.EndPort
+	if err := f.validateNLBListener("UpdateListenerAttribute", &update); err != nil {
+		return "", err
+	}
+	mergeNonZero(&remote.lis, &update, "IsUserManaged", "NamedKey", "ServerGroupName", "ServicePort",
+		"ListenerProtocol", "ListenerPort", "StartPort", "EndPort", "LoadBalancerId", "ListenerId",
+		"ListenerStatus", "AdditionalCertificateIds")
+	return f.newJob(nil), nil
+}
+
+func (f *FakeCloud) DeleteNLBListenerAsync(ctx context.Context, listenerId string) (string, error) {
+	err := f.begin("DeleteNLBListenerAsync")
+	defer f.lock.Unlock()
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.getNLBListener("DeleteListener", listenerId); err != nil {
+		return "", err
+	}
+	delete(f.nlbLis, listenerId)
+	return f.newJob(nil), nil
+}
+
+func (f *FakeCloud) GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error) {
+	err := f.begin("GetNLBListenerHealthStatus")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	l, err := f.getNLBListener("GetListenerHealthStatus", listenerId)
+	if err != nil {
+		return nil, err
+	}
+	sg, ok := f.nlbSGs[l.lis.ServerGroupId]
+	if !ok {
+		return nil, nil
+	}
+	status := nlbmodel.ServerGroupHealthStatus{ServerGroupId: l.lis.ServerGroupId, HealthCheckEnabled: true}
+	for _, s := range sg.servers {
+		if f.unhealthy[healthKey(s.ServerId, s.ServerIp, int(s.Port))] {
+			status.NonNormalServers = append(status.NonNormalServers, nlbmodel.ServerHealthStatus{
+				ServerId: s.ServerId,
+				ServerIp: s.ServerIp,
+				Port:     s.Port,
+				Status:   "Unhealthy",
+			})
+		}
+	}
+	return []nlbmodel.ServerGroupHealthStatus{status}, nil
+}
+
+// Certificates
+
+func (f *FakeCloud) ListNLBListenerCertificates(ctx context.Context, listenerId string) ([]nlbmodel.ListenerCertificate, error) {
+	err := f.begin("ListNLBListenerCertificates")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	l, err := f.getNLBListener("ListListenerCertificates", listenerId)
+	if err != nil {
+		return nil, err
+	}
+	var ret []nlbmodel.ListenerCertificate
+	for i, id := range l.lis.CertificateIds {
+		ret = append(ret, nlbmodel.ListenerCertificate{Id: id, IsDefault: i == 0, Type: "Server", Status: "Associated"})
+	}
+	for _, id := range l.certs {
+		ret = append(ret, nlbmodel.ListenerCertificate{Id: id, Type: "Server", Status: "Associated"})
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) AssociateAdditionalCertificatesWithNLBListener(ctx context.Context, listenerId string, certIds []string) error {
+	err := f.begin("AssociateAdditionalCertificatesWithNLBListener")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	l, err := f.getNLBListener("AssociateAdditionalCertificatesWithListener", listenerId)
+	if err != nil {
+		return err
+	}
+	for _, id := range certIds {
+		if !containsString(l.certs, id) {
+			l.certs = append(l.certs, id)
+		}
+	}
+	return nil
+}
+
+func (f *FakeCloud) DisassociateAdditionalCertificatesWithNLBListener(ctx context.Context, listenerId string, certIds []string) error {
+	err := f.begin("DisassociateAdditionalCertificatesWithNLBListener")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	l, err := f.getNLBListener("DisassociateAdditionalCertificatesWithListener", listenerId)
+	if err != nil {
+		return err
+	}
+	var ret []string
+	for _, id := range l.certs {
+		if !containsString(certIds, id) {
+			ret = append(ret, id)
+		}
+	}
+	l.certs = ret
+	return nil
+}
+
+// Jobs
+
+func (f *FakeCloud) BatchWaitJobsFinish(ctx context.Context, api string, jobIds []string, args ...time.Duration) error {
+	for _, id := range jobIds {
+		if err := f.WaitJobFinish(ctx, api, id, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitJobFinish returns immediately since the fake cloud finishes the async jobs when they are created.
+func (f *FakeCloud) WaitJobFinish(ctx context.Context, api, jobId string, args ...time.Duration) error {
+	err := f.begin("WaitJobFinish")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	jobErr, ok := f.nlbJobs[jobId]
+	if !ok {
+		return fmt.Errorf("[%s] job %s not found", api, jobId)
+	}
+	if jobErr != nil {
+		return fmt.Errorf("[%s] job %s failed: %s", api, jobId, jobErr.Error())
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+)
+
+type fakePvtzRecord struct {
+	rr        string
+	value     string
+	ttl       int64
+	typ       string
+	unmanaged bool
+}
+
+// AddUnmanagedPvtzRecord adds a zone record which is not created by the controller, it is invisible to
+// the provider interface like the records without the managed remark.
+func (f *FakeCloud) AddUnmanagedPvtzRecord(rr, recordType, value string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.seq++
+	f.pvtzRecord[int64(f.seq)] = &fakePvtzRecord{rr: rr, typ: recordType, value: value, ttl: 60, unmanaged: true}
+}
+
+func pvtzSupportedType(recordType string) bool {
+	switch recordType {
+	case model.RecordTypeA, model.RecordTypeAAAA, model.RecordTypeCNAME, model.RecordTypePTR, model.RecordTypeSRV, model.RecordTypeTXT:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f *FakeCloud) searchPVTZ(ep *model.PvtzEndpoint, exact bool) []*model.PvtzEndpoint {
+	typed := make(map[string]map[string]*model.PvtzEndpoint)
+	for id, r := range f.pvtzRecord {
+		if r.unmanaged || !pvtzSupportedType(r.typ) {
+			continue
+		}
+		if ep.Rr != "" {
+			if exact && r.rr != ep.Rr {
+				continue
+			}
+			if !exact && !strings.Contains(r.rr, ep.Rr) {
+				continue
+			}
+		}
+		if typed[r.typ] == nil {
+			typed[r.typ] = make(map[string]*model.PvtzEndpoint)
+		}
+		if e := typed[r.typ][r.rr]; e == nil {
+			typed[r.typ][r.rr] = &model.PvtzEndpoint{
+				Rr:     r.rr,
+				Values: []model.PvtzValue{{Data: r.value, RecordId: id}},
+				Ttl:    r.ttl,
+				Type:   r.typ,
+			}
+		} else {
+			e.Values = append(e.Values, model.PvtzValue{Data: r.value, RecordId: id})
+		}
+	}
+	ret := make([]*model.PvtzEndpoint, 0)
+	for _, m := range typed {
+		for _, e := range m {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// records returns the values of the managed records matching the rr and type of ep
+func (f *FakeCloud) records(ep *model.PvtzEndpoint) ([]model.PvtzValue, error) {
+	if ep.Rr == "" {
+		return nil, fmt.Errorf("endpoint %s %s not found", ep.Rr, ep.Type)
+	}
+	for _, e := range f.searchPVTZ(ep, true) {
+		if e.Rr == ep.Rr && (ep.Type == "" || e.Type == ep.Type) {
+			return e.Values, nil
+		}
+	}
+	return []model.PvtzValue{}, nil
+}
+
+func (f *FakeCloud) ListPVTZ(ctx context.Context) ([]*model.PvtzEndpoint, error) {
+	err := f.begin("ListPVTZ")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return f.searchPVTZ(&model.PvtzEndpoint{}, false), nil
+}
+
+func (f *FakeCloud) SearchPVTZ(ctx context.Context, ep *model.PvtzEndpoint, exact bool) ([]*model.PvtzEndpoint, error) {
+	err := f.begin("SearchPVTZ")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return f.searchPVTZ(ep, exact), nil
+}
+
+func (f *FakeCloud) UpdatePVTZ(ctx context.Context, ep *model.PvtzEndpoint) error {
+	err := f.begin("UpdatePVTZ")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	oldValues, err := f.records(ep)
+	if err != nil {
+		return fmt.Errorf("UpdatePVTZ query old zone records error: %s", err.Error())
+	}
+	for _, newVal := range ep.Values {
+		if !newVal.InVals(oldValues) {
+			f.seq++
+			f.pvtzRecord[int64(f.seq)] = &fakePvtzRecord{rr: ep.Rr, value: newVal.Data, ttl: ep.Ttl, typ: ep.Type}
+		}
+	}
+	for _, oldVal := range oldValues {
+		if !oldVal.InVals(ep.Values) {
+			delete(f.pvtzRecord, oldVal.RecordId)
+		}
+	}
+	ep.Values = oldValues
+	return nil
+}
+
+func (f *FakeCloud) DeletePVTZ(ctx context.Context, ep *model.PvtzEndpoint) error {
+	err := f.begin("DeletePVTZ")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	oldValues, err := f.records(ep)
+	if err != nil {
+		return fmt.Errorf("DeletePVTZ query old zone records error: %s", err.Error())
+	}
+	for _, val := range oldValues {
+		delete(f.pvtzRecord, val.RecordId)
+	}
+	ep.Values = oldValues
+	return nil
+}
@@ -0,0 +1,1087 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model/tag"
+)
+
+const (
+	// MaxBackendNumPerRequest is the max number of backends in one request of the vserver group apis
+	MaxBackendNumPerRequest = 40
+
+	clbListenerStatusRunning = model.ListenerStatus("running")
+)
+
+type fakeCLB struct {
+	attr        model.LoadBalancerAttribute
+	clientToken string
+	// listeners is keyed by listenerKey
+	listeners map[string]*model.ListenerAttribute
+}
+
+type fakeVGroup struct {
+	lbId     string
+	id       string
+	name     string
+	backends []model.BackendAttribute
+}
+
+type fakeACL struct {
+	acl model.AccessControlList
+}
+
+type fakeDomainExtension struct {
+	lbId string
+	port int
+	ext  model.DomainExtension
+}
+
+type fakeServerCertificate struct {
+	cert model.CertAttribute
+}
+
+// AddServerCertificate adds a clb server certificate which can be used by https listeners.
+func (f *FakeCloud) AddServerCertificate(cert model.CertAttribute) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.clbCerts[cert.ServerCertificateId] = &fakeServerCertificate{cert: cert}
+}
+
+// GetLoadBalancer returns a copy of the clb in the fake cloud, or nil if it does not exist.
+func (f *FakeCloud) GetLoadBalancer(lbId string) *model.LoadBalancer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	lb, ok := f.clbs[lbId]
+	if !ok {
+		return nil
+	}
+	mdl := &model.LoadBalancer{LoadBalancerAttribute: lb.attr}
+	mdl.LoadBalancerAttribute.Tags = copyTags(lb.attr.Tags)
+	for _, l := range lb.listeners {
+		mdl.Listeners = append(mdl.Listeners, *l)
+	}
+	for _, vg := range f.vgroups {
+		if vg.lbId == lbId {
+			mdl.VServerGroups = append(mdl.VServerGroups, vg.toModel())
+		}
+	}
+	return mdl
+}
+
+// LoadBalancerIds returns the ids of all the clbs in the fake cloud.
+func (f *FakeCloud) LoadBalancerIds() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var ids []string
+	for id := range f.clbs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func listenerKey(port int, proto string) string {
+	// udp listeners can share the port with listeners of other protocols
+	if proto == model.UDP {
+		return fmt.Sprintf("udp:%d", port)
+	}
+	return fmt.Sprintf("tcp:%d", port)
+}
+
+func (vg *fakeVGroup) toModel() model.VServerGroup {
+	ret := model.VServerGroup{
+		VGroupId:   vg.id,
+		VGroupName: vg.name,
+	}
+	for _, b := range vg.backends {
+		ret.Backends = append(ret.Backends, model.BackendAttribute{
+			Description: b.Description,
+			ServerId:    b.ServerId,
+			ServerIp:    b.ServerIp,
+			Weight:      b.Weight,
+			Port:        b.Port,
+			Type:        b.Type,
+		})
+	}
+	return ret
+}
+
+func loadCLB(attr model.LoadBalancerAttribute, mdl *model.LoadBalancer) {
+	a := &mdl.LoadBalancerAttribute
+	a.LoadBalancerId = attr.LoadBalancerId
+	a.LoadBalancerName = attr.LoadBalancerName
+	a.Address = attr.Address
+	a.AddressType = attr.AddressType
+	a.AddressIPVersion = attr.AddressIPVersion
+	a.NetworkType = attr.NetworkType
+	a.VpcId = attr.VpcId
+	a.VSwitchId = attr.VSwitchId
+	a.Bandwidth = attr.Bandwidth
+	a.MasterZoneId = attr.MasterZoneId
+	a.SlaveZoneId = attr.SlaveZoneId
+	a.DeleteProtection = attr.DeleteProtection
+	a.LoadBalancerSpec = attr.LoadBalancerSpec
+	a.ModificationProtectionStatus = attr.ModificationProtectionStatus
+	a.ResourceGroupId = attr.ResourceGroupId
+	a.InstanceChargeType = attr.InstanceChargeType
+	a.InternetChargeType = attr.InternetChargeType
+	a.RegionId = attr.RegionId
+	a.LoadBalancerStatus = attr.LoadBalancerStatus
+	a.Tags = copyTags(attr.Tags)
+}
+
+func (f *FakeCloud) getCLB(api, lbId string) (*fakeCLB, error) {
+	lb, ok := f.clbs[lbId]
+	if !ok {
+		return nil, NewAPIError(api, http.StatusBadRequest, "InvalidLoadBalancerId.NotFound",
+			fmt.Sprintf("The specified LoadBalancerId does not exist. lbId: %s", lbId))
+	}
+	return lb, nil
+}
+
+func (f *FakeCloud) FindLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error {
+	if mdl.LoadBalancerAttribute.LoadBalancerId != "" {
+		return f.DescribeLoadBalancer(ctx, mdl)
+	}
+
+	err := f.begin("FindLoadBalancer")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	var found []*fakeCLB
+	if len(mdl.LoadBalancerAttribute.Tags) != 0 {
+		for _, lb := range f.clbs {
+			if hasAnyTag(lb.attr.Tags, mdl.LoadBalancerAttribute.Tags) {
+				found = append(found, lb)
+			}
+		}
+	}
+	if len(found) == 0 && mdl.LoadBalancerAttribute.LoadBalancerName != "" {
+		for _, lb := range f.clbs {
+			if lb.attr.LoadBalancerName == mdl.LoadBalancerAttribute.LoadBalancerName {
+				found = append(found, lb)
+			}
+		}
+	}
+	if len(found) > 1 {
+		var ids []string
+		for _, lb := range found {
+			ids = append(ids, lb.attr.LoadBalancerId)
+		}
+		return fmt.Errorf("[%s] find multiple loadbalances, lbIds%v", mdl.NamespacedName, ids)
+	}
+	if len(found) == 1 {
+		loadCLB(found[0].attr, mdl)
+	}
+	return nil
+}
+
+func (f *FakeCloud) ListLoadBalancers(ctx context.Context, tags []tag.Tag) ([]*model.LoadBalancer, error) {
+	err := f.begin("ListLoadBalancers")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var ret []*model.LoadBalancer
+	for _, lb := range f.clbs {
+		if len(tags) == 0 || hasAnyTag(lb.attr.Tags, tags) {
+			mdl := &model.LoadBalancer{}
+			loadCLB(lb.attr, mdl)
+			ret = append(ret, mdl)
+		}
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) CreateLoadBalancer(ctx context.Context, mdl *model.LoadBalancer, clientToken string) error {
+	err := f.begin("CreateLoadBalancer")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if clientToken != "" {
+		for _, lb := range f.clbs {
+			if lb.clientToken == clientToken {
+				mdl.LoadBalancerAttribute.LoadBalancerId = lb.attr.LoadBalancerId
+				mdl.LoadBalancerAttribute.Address = lb.attr.Address
+				return nil
+			}
+		}
+	}
+
+	attr := mdl.LoadBalancerAttribute
+	if attr.VSwitchId != "" {
+		if vsw, ok := f.vswitches[attr.VSwitchId]; ok && vsw.vsw.AvailableIpAddressCount == 0 {
+			return NewAPIError("CreateLoadBalancer", http.StatusBadRequest, "VSwitchAvailableIpNotExist",
+				fmt.Sprintf("The specified VSwitch %s has no available ip.", attr.VSwitchId))
+		}
+	}
+	if attr.Address != "" {
+		for _, lb := range f.clbs {
+			if lb.attr.Address == attr.Address {
+				return invalidParameterError("CreateLoadBalancer", "InvalidParam.Address",
+					fmt.Sprintf("The specified address %s is already used.", attr.Address))
+			}
+		}
+	}
+
+	attr.IsUserManaged = false
+	attr.PreserveOnDelete = false
+	attr.SourceRanges = nil
+	attr.SourceRangesAclId = ""
+	attr.LoadBalancerId = f.nextID("lb")
+	attr.LoadBalancerStatus = "active"
+	attr.RegionId = DefaultRegion
+	attr.Tags = copyTags(attr.Tags)
+	if attr.AddressType == "" {
+		attr.AddressType = model.InternetAddressType
+	}
+	if attr.AddressIPVersion == "" {
+		attr.AddressIPVersion = model.IPv4
+	}
+	if attr.NetworkType == "" {
+		attr.NetworkType = model.ClassicNetworkType
+		if attr.VSwitchId != "" {
+			attr.NetworkType = model.VpcNetworkType
+		}
+	}
+	if attr.VSwitchId != "" && attr.VpcId == "" {
+		attr.VpcId = DefaultVpcID
+		if vsw, ok := f.vswitches[attr.VSwitchId]; ok {
+			attr.VpcId = vsw.vsw.VpcId
+		}
+	}
+	if attr.InternetChargeType == "" {
+		attr.InternetChargeType = "paybytraffic"
+	}
+	if attr.InstanceChargeType == "" {
+		attr.InstanceChargeType = model.PayByCLCU
+		if attr.LoadBalancerSpec != "" {
+			attr.InstanceChargeType = model.PayBySpec
+		}
+	}
+	if attr.DeleteProtection == "" {
+		attr.DeleteProtection = model.OffFlag
+	}
+	if attr.ModificationProtectionStatus == "" {
+		attr.ModificationProtectionStatus = model.NonProtection
+	}
+	if attr.MasterZoneId == "" {
+		attr.MasterZoneId = DefaultZoneID
+	}
+	if attr.Address == "" {
+		attr.Address = f.nextAddress(attr.AddressType)
+	}
+
+	f.clbs[attr.LoadBalancerId] = &fakeCLB{
+		attr:        attr,
+		clientToken: clientToken,
+		listeners:   make(map[string]*model.ListenerAttribute),
+	}
+	mdl.LoadBalancerAttribute.LoadBalancerId = attr.LoadBalancerId
+	mdl.LoadBalancerAttribute.Address = attr.Address
+	return nil
+}
+
+func (f *FakeCloud) nextAddress(addressType model.AddressType) string {
+	f.seq++
+	if addressType == model.IntranetAddressType {
+		return fmt.Sprintf("192.168.%d.%d", f.seq/250%250, f.seq%250+1)
+	}
+	return fmt.Sprintf("47.100.%d.%d", f.seq/250%250, f.seq%250+1)
+}
+
+func (f *FakeCloud) DescribeLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error {
+	err := f.begin("DescribeLoadBalancer")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	lb, err := f.getCLB("DescribeLoadBalancerAttribute", mdl.LoadBalancerAttribute.LoadBalancerId)
+	if err != nil {
+		return err
+	}
+	loadCLB(lb.attr, mdl)
+	return nil
+}
+
+func (f *FakeCloud) DeleteLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error {
+	err := f.begin("DeleteLoadBalancer")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	lbId := mdl.LoadBalancerAttribute.LoadBalancerId
+	lb, err := f.getCLB("DeleteLoadBalancer", lbId)
+	if err != nil {
+		return err
+	}
+	if lb.attr.DeleteProtection == model.OnFlag {
+		return NewAPIError("DeleteLoadBalancer", http.StatusBadRequest, "DeleteProtectionIsOn",
+			fmt.Sprintf("The loadbalancer %s can not be deleted because the delete protection is on.", lbId))
+	}
+	for id, vg := range f.vgroups {
+		if vg.lbId == lbId {
+			delete(f.vgroups, id)
+		}
+	}
+	for id, d := range f.domains {
+		if d.lbId == lbId {
+			delete(f.domains, id)
+		}
+	}
+	delete(f.clbs, lbId)
+	return nil
+}
+
+func (f *FakeCloud) modifyCLB(api, lbId string, fn func(attr *model.LoadBalancerAttribute)) error {
+	err := f.begin(api)
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	lb, err := f.getCLB(api, lbId)
+	if err != nil {
+		return err
+	}
+	fn(&lb.attr)
+	return nil
+}
+
+func (f *FakeCloud) ModifyLoadBalancerInstanceSpec(ctx context.Context, lbId string, spec string) error {
+	return f.modifyCLB("ModifyLoadBalancerInstanceSpec", lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.LoadBalancerSpec = model.LoadBalancerSpecType(spec)
+	})
+}
+
+func (f *FakeCloud) ModifyLoadBalancerInstanceChargeType(ctx context.Context, lbId string, instanceChargeType string, spec string) error {
+	return f.modifyCLB("ModifyLoadBalancerInstanceChargeType", lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.InstanceChargeType = model.InstanceChargeType(instanceChargeType)
+		attr.LoadBalancerSpec = model.LoadBalancerSpecType(spec)
+	})
+}
+
+func (f *FakeCloud) SetLoadBalancerDeleteProtection(ctx context.Context, lbId string, flag string) error {
+	return f.modifyCLB("SetLoadBalancerDeleteProtection", lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.DeleteProtection = model.FlagType(flag)
+	})
+}
+
+func (f *FakeCloud) SetLoadBalancerName(ctx context.Context, lbId string, name string) error {
+	return f.modifyCLB("SetLoadBalancerName", lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.LoadBalancerName = name
+	})
+}
+
+func (f *FakeCloud) ModifyLoadBalancerInternetSpec(ctx context.Context, lbId string, chargeType string, bandwidth int) error {
+	return f.modifyCLB("ModifyLoadBalancerInternetSpec", lbId, func(attr *model.LoadBalancerAttribute) {
+		if chargeType != "" {
+			attr.InternetChargeType = model.InternetChargeType(chargeType)
+		}
+		if bandwidth != 0 {
+			attr.Bandwidth = bandwidth
+		}
+	})
+}
+
+func (f *FakeCloud) SetLoadBalancerModificationProtection(ctx context.Context, lbId string, flag string) error {
+	return f.modifyCLB("SetLoadBalancerModificationProtection", lbId, func(attr *model.LoadBalancerAttribute) {
+		attr.ModificationProtectionStatus = model.ModificationProtectionType(flag)
+		if attr.ModificationProtectionStatus == model.ConsoleProtection {
+			attr.ModificationProtectionReason = model.ModificationProtectionReason
+		} else {
+			attr.ModificationProtectionReason = ""
+		}
+	})
+}
+
+// Listener
+
+func (f *FakeCloud) DescribeLoadBalancerListeners(ctx context.Context, lbId string) ([]model.ListenerAttribute, error) {
+	err := f.begin("DescribeLoadBalancerListeners")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	lb, err := f.getCLB("DescribeLoadBalancerListeners", lbId)
+	if err != nil {
+		return nil, err
+	}
+	var ret []model.ListenerAttribute
+	for _, l := range lb.listeners {
+		lis := *l
+		lis.AclIds = append([]string(nil), l.AclIds...)
+		namedKey, err := model.LoadListenerNamedKey(lis.Description)
+		if err != nil {
+			lis.IsUserManaged = true
+		}
+		lis.NamedKey = namedKey
+		ret = append(ret, lis)
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) getListener(api string, lbId string, port int, proto string) (*model.ListenerAttribute, error) {
+	lb, err := f.getCLB(api, lbId)
+	if err != nil {
+		return nil, err
+	}
+	lis, ok := lb.listeners[listenerKey(port, proto)]
+	if !ok || (proto != "" && lis.Protocol != proto) {
+		return nil, notFoundError(api, "ListenerNotFound", "listener", fmt.Sprintf("%s:%d", proto, port))
+	}
+	return lis, nil
+}
+
+func (f *FakeCloud) setListenerStatus(api string, lbId string, port int, proto string, status model.ListenerStatus) error {
+	err := f.begin(api)
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	lis, err := f.getListener(api, lbId, port, proto)
+	if err != nil {
+		return err
+	}
+	lis.Status = status
+	return nil
+}
+
+func (f *FakeCloud) StartLoadBalancerListener(ctx context.Context, lbId string, port int, proto string) error {
+	return f.setListenerStatus("StartLoadBalancerListener", lbId, port, proto, clbListenerStatusRunning)
+}
+
+func (f *FakeCloud) StopLoadBalancerListener(ctx context.Context, lbId string, port int, proto string) error {
+	return f.setListenerStatus("StopLoadBalancerListener", lbId, port, proto, model.Stopped)
+}
+
+func (f *FakeCloud) DeleteLoadBalancerListener(ctx context.Context, lbId string, port int, proto string) error {
+	err := f.begin("DeleteLoadBalancerListener")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, err := f.getListener("DeleteLoadBalancerListener", lbId, port, proto); err != nil {
+		return err
+	}
+	delete(f.clbs[lbId].listeners, listenerKey(port, proto))
+	for id, d := range f.domains {
+		if d.lbId == lbId && d.port == port {
+			delete(f.domains, id)
+		}
+	}
+	return nil
+}
+
+func (f *FakeCloud) createListener(api string, lbId string, proto string, listener model.ListenerAttribute) error {
+	err := f.begin(api)
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	lb, err := f.getCLB(api, lbId)
+	if err != nil {
+		return err
+	}
+	key := listenerKey(listener.ListenerPort, proto)
+	if _, ok := lb.listeners[key]; ok {
+		return invalidParameterError(api, "ListenerAlreadyExists",
+			fmt.Sprintf("The specified port %d is already used by another listener.", listener.ListenerPort))
+	}
+	if err := f.validateListener(api, lbId, proto, &listener); err != nil {
+		return err
+	}
+
+	lis := &model.ListenerAttribute{}
+	mergeListener(lis, listener)
+	lis.ListenerPort = listener.ListenerPort
+	lis.Protocol = proto
+	lis.Status = model.Stopped
+	if lis.AclStatus == "" {
+		lis.AclStatus = model.OffFlag
+	}
+	lb.listeners[key] = lis
+	return nil
+}
+
+func (f *FakeCloud) validateListener(api, lbId, proto string, listener *model.ListenerAttribute) error {
+	if listener.VGroupId != "" {
+		vg, ok := f.vgroups[listener.VGroupId]
+		if !ok || vg.lbId != lbId {
+			return notFoundError(api, "InvalidParameter.VServerGroupId", "VServerGroupId", listener.VGroupId)
+		}
+	}
+	if listener.AclStatus == model.OnFlag {
+		if listener.AclId == "" {
+			return invalidParameterError(api, "InvalidParameter.AclId", "AclId is required when AclStatus is on.")
+		}
+		if _, ok := f.acls[listener.AclId]; !ok {
+			return notFoundError(api, "AclNotExist", "AclId", listener.AclId)
+		}
+	}
+	if proto == model.HTTPS && listener.CertId != "" && len(f.clbCerts) != 0 {
+		if _, ok := f.clbCerts[listener.CertId]; !ok {
+			return notFoundError(api, "ServerCertificateId.NotFound", "ServerCertificateId", listener.CertId)
+		}
+	}
+	return nil
+}
+
+func (f *FakeCloud) setListener(api string, lbId string, proto string, listener model.ListenerAttribute) error {
+	err := f.begin(api)
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	lis, err := f.getListener(api, lbId, listener.ListenerPort, proto)
+	if err != nil {
+		return err
+	}
+	if err := f.validateListener(api, lbId, proto, &listener); err != nil {
+		return err
+	}
+	mergeListener(lis, listener)
+	return nil
+}
+
+// mergeListener sets the non-zero parameters of update to lis, parameters which are not
+// specified in the request keep their values like the SetListenerAttribute apis.
+func mergeListener(lis *model.ListenerAttribute, update model.ListenerAttribute) {
+	mergeNonZero(lis, &update, "IsUserManaged", "NamedKey", "ListenerPort", "Protocol",
+		"Status", "VGroupName", "DomainExtensions")
+}
+
+func (f *FakeCloud) CreateLoadBalancerTCPListener(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return f.createListener("CreateLoadBalancerTCPListener", lbId, model.TCP, listener)
+}
+
+func (f *FakeCloud) SetLoadBalancerTCPListenerAttribute(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return f.setListener("SetLoadBalancerTCPListenerAttribute", lbId, model.TCP, listener)
+}
+
+func (f *FakeCloud) CreateLoadBalancerUDPListener(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return f.createListener("CreateLoadBalancerUDPListener", lbId, model.UDP, listener)
+}
+
+func (f *FakeCloud) SetLoadBalancerUDPListenerAttribute(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return f.setListener("SetLoadBalancerUDPListenerAttribute", lbId, model.UDP, listener)
+}
+
+func (f *FakeCloud) CreateLoadBalancerHTTPListener(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return f.createListener("CreateLoadBalancerHTTPListener", lbId, model.HTTP, listener)
+}
+
+func (f *FakeCloud) SetLoadBalancerHTTPListenerAttribute(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return f.setListener("SetLoadBalancerHTTPListenerAttribute", lbId, model.HTTP, listener)
+}
+
+func (f *FakeCloud) CreateLoadBalancerHTTPSListener(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return f.createListener("CreateLoadBalancerHTTPSListener", lbId, model.HTTPS, listener)
+}
+
+func (f *FakeCloud) SetLoadBalancerHTTPSListenerAttribute(ctx context.Context, lbId string, listener model.ListenerAttribute) error {
+	return f.setListener("SetLoadBalancerHTTPSListenerAttribute", lbId, model.HTTPS, listener)
+}
+
+// VServerGroup
+
+func (f *FakeCloud) DescribeVServerGroups(ctx context.Context, lbId string) ([]model.VServerGroup, error) {
+	err := f.begin("DescribeVServerGroups")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.getCLB("DescribeVServerGroups", lbId); err != nil {
+		return nil, err
+	}
+	var ret []model.VServerGroup
+	for _, vg := range f.vgroups {
+		if vg.lbId != lbId {
+			continue
+		}
+		v := model.VServerGroup{
+			VGroupId:   vg.id,
+			VGroupName: vg.name,
+		}
+		namedKey, err := model.LoadVGroupNamedKey(vg.name)
+		if err != nil {
+			v.IsUserManaged = true
+		}
+		v.NamedKey = namedKey
+		ret = append(ret, v)
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) CreateVServerGroup(ctx context.Context, vg *model.VServerGroup, lbId string) error {
+	err := f.begin("CreateVServerGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, err := f.getCLB("CreateVServerGroup", lbId); err != nil {
+		return err
+	}
+	id := f.nextID("rsp")
+	f.vgroups[id] = &fakeVGroup{
+		lbId: lbId,
+		id:   id,
+		name: vg.VGroupName,
+	}
+	vg.VGroupId = id
+	return nil
+}
+
+func (f *FakeCloud) getVGroup(api, vGroupId string) (*fakeVGroup, error) {
+	vg, ok := f.vgroups[vGroupId]
+	if !ok {
+		return nil, notFoundError(api, "InvalidParameter.VServerGroupId", "VServerGroupId", vGroupId)
+	}
+	return vg, nil
+}
+
+func (f *FakeCloud) DescribeVServerGroupAttribute(ctx context.Context, vGroupId string) (model.VServerGroup, error) {
+	err := f.begin("DescribeVServerGroupAttribute")
+	defer f.lock.Unlock()
+	if err != nil {
+		return model.VServerGroup{}, err
+	}
+	vg, err := f.getVGroup("DescribeVServerGroupAttribute", vGroupId)
+	if err != nil {
+		return model.VServerGroup{}, err
+	}
+	return vg.toModel(), nil
+}
+
+func (f *FakeCloud) DeleteVServerGroup(ctx context.Context, vGroupId string) error {
+	err := f.begin("DeleteVServerGroup")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	vg, err := f.getVGroup("DeleteVServerGroup", vGroupId)
+	if err != nil {
+		return err
+	}
+	for _, l := range f.clbs[vg.lbId].listeners {
+		if l.VGroupId == vGroupId {
+			return NewAPIError("DeleteVServerGroup", http.StatusBadRequest, "RspoolVipExist",
+				fmt.Sprintf("The vserver group %s is used by listener %s:%d.", vGroupId, l.Protocol, l.ListenerPort))
+		}
+	}
+	delete(f.vgroups, vGroupId)
+	return nil
+}
+
+func backendKey(b model.BackendAttribute) string {
+	if b.Type == model.ENIBackendType {
+		return fmt.Sprintf("%s/%s/%d", b.ServerId, b.ServerIp, b.Port)
+	}
+	return fmt.Sprintf("%s/%d", b.ServerId, b.Port)
+}
+
+func parseBackends(api, backends string) ([]model.BackendAttribute, error) {
+	var ret []model.BackendAttribute
+	if err := json.Unmarshal([]byte(backends), &ret); err != nil {
+		return nil, invalidParameterError(api, "InvalidParameter.BackendServers",
+			fmt.Sprintf("The backend servers %s is invalid: %s", backends, err.Error()))
+	}
+	if len(ret) > MaxBackendNumPerRequest {
+		return nil, invalidParameterError(api, "InvalidParameter.BackendServers",
+			fmt.Sprintf("The number of backend servers %d exceeds the limit %d.", len(ret), MaxBackendNumPerRequest))
+	}
+	for i := range ret {
+		if ret[i].Type == "" {
+			ret[i].Type = model.ECSBackendType
+		}
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) updateBackends(api, vGroupId string, backends string,
+	fn func(vg *fakeVGroup, backends []model.BackendAttribute) error) error {
+	err := f.begin(api)
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	vg, err := f.getVGroup(api, vGroupId)
+	if err != nil {
+		return err
+	}
+	list, err := parseBackends(api, backends)
+	if err != nil {
+		return err
+	}
+	return fn(vg, list)
+}
+
+func (f *FakeCloud) AddVServerGroupBackendServers(ctx context.Context, vGroupId string, backends string) error {
+	return f.updateBackends("AddVServerGroupBackendServers", vGroupId, backends,
+		func(vg *fakeVGroup, list []model.BackendAttribute) error {
+			for _, b := range list {
+				for _, old := range vg.backends {
+					if backendKey(old) == backendKey(b) {
+						return invalidParameterError("AddVServerGroupBackendServers", "BackendServer.AlreadyExist",
+							fmt.Sprintf("The backend server %s already exists.", backendKey(b)))
+					}
+				}
+			}
+			vg.backends = append(vg.backends, list...)
+			return nil
+		})
+}
+
+func (f *FakeCloud) RemoveVServerGroupBackendServers(ctx context.Context, vGroupId string, backends string) error {
+	return f.updateBackends("RemoveVServerGroupBackendServers", vGroupId, backends,
+		func(vg *fakeVGroup, list []model.BackendAttribute) error {
+			vg.backends = removeBackends(vg.backends, list)
+			return nil
+		})
+}
+
+func (f *FakeCloud) SetVServerGroupAttribute(ctx context.Context, vGroupId string, backends string) error {
+	return f.updateBackends("SetVServerGroupAttribute", vGroupId, backends,
+		func(vg *fakeVGroup, list []model.BackendAttribute) error {
+			for _, b := range list {
+				for i := range vg.backends {
+					if backendKey(vg.backends[i]) == backendKey(b) {
+						vg.backends[i].Weight = b.Weight
+						vg.backends[i].Description = b.Description
+					}
+				}
+			}
+			return nil
+		})
+}
+
+func (f *FakeCloud) ModifyVServerGroupBackendServers(ctx context.Context, vGroupId string, old string, new string) error {
+	return f.updateBackends("ModifyVServerGroupBackendServers", vGroupId, old,
+		func(vg *fakeVGroup, oldList []model.BackendAttribute) error {
+			newList, err := parseBackends("ModifyVServerGroupBackendServers", new)
+			if err != nil {
+				return err
+			}
+			vg.backends = append(removeBackends(vg.backends, oldList), newList...)
+			return nil
+		})
+}
+
+func (f *FakeCloud) DescribeHealthStatus(ctx context.Context, lbId string) ([]model.BackendHealthStatus, error) {
+	err := f.begin("DescribeHealthStatus")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	lb, err := f.getCLB("DescribeHealthStatus", lbId)
+	if err != nil {
+		return nil, err
+	}
+	var ret []model.BackendHealthStatus
+	for _, l := range lb.listeners {
+		vg, ok := f.vgroups[l.VGroupId]
+		if !ok {
+			continue
+		}
+		for _, b := range vg.backends {
+			status := model.HealthStatusNormal
+			if f.unhealthy[healthKey(b.ServerId, b.ServerIp, b.Port)] {
+				status = model.HealthStatusAbnormal
+			}
+			ret = append(ret, model.BackendHealthStatus{
+				ListenerPort:     l.ListenerPort,
+				ListenerProtocol: l.Protocol,
+				ServerId:         b.ServerId,
+				ServerIp:         b.ServerIp,
+				Port:             b.Port,
+				Status:           status,
+			})
+		}
+	}
+	return ret, nil
+}
+
+func removeBackends(backends, del []model.BackendAttribute) []model.BackendAttribute {
+	var ret []model.BackendAttribute
+	for _, b := range backends {
+		found := false
+		for _, d := range del {
+			if backendKey(b) == backendKey(d) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, b)
+		}
+	}
+	return ret
+}
+
+// Tag
+
+func (f *FakeCloud) TagCLBResource(ctx context.Context, resourceId string, tags []tag.Tag) error {
+	return f.modifyCLB("TagCLBResource", resourceId, func(attr *model.LoadBalancerAttribute) {
+		attr.Tags = mergeTags(attr.Tags, tags)
+	})
+}
+
+func (f *FakeCloud) UntagResources(ctx context.Context, lbId string, tagKey *[]string) error {
+	return f.modifyCLB("UntagResources", lbId, func(attr *model.LoadBalancerAttribute) {
+		if tagKey != nil {
+			attr.Tags = removeTags(attr.Tags, *tagKey)
+		}
+	})
+}
+
+func (f *FakeCloud) ListCLBTagResources(ctx context.Context, lbId string) ([]tag.Tag, error) {
+	err := f.begin("ListCLBTagResources")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	lb, err := f.getCLB("ListTagResources", lbId)
+	if err != nil {
+		return nil, err
+	}
+	return copyTags(lb.attr.Tags), nil
+}
+
+// Cert
+
+func (f *FakeCloud) DescribeServerCertificateById(ctx context.Context, serverCertificateId string) (*model.CertAttribute, error) {
+	err := f.begin("DescribeServerCertificateById")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	cert, ok := f.clbCerts[serverCertificateId]
+	if !ok {
+		return nil, nil
+	}
+	ret := cert.cert
+	return &ret, nil
+}
+
+// Domain Extension
+
+func (f *FakeCloud) DescribeDomainExtensions(ctx context.Context, lbId string, port int) ([]model.DomainExtension, error) {
+	err := f.begin("DescribeDomainExtensions")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.getCLB("DescribeDomainExtensions", lbId); err != nil {
+		return nil, err
+	}
+	var ret []model.DomainExtension
+	for _, d := range f.domains {
+		if d.lbId == lbId && d.port == port {
+			ret = append(ret, d.ext)
+		}
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) CreateDomainExtension(ctx context.Context, lbId string, port int, domain string, certId string) error {
+	err := f.begin("CreateDomainExtension")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, err := f.getListener("CreateDomainExtension", lbId, port, model.HTTPS); err != nil {
+		return err
+	}
+	for _, d := range f.domains {
+		if d.lbId == lbId && d.port == port && d.ext.Domain == domain {
+			return invalidParameterError("CreateDomainExtension", "DomainExtensionAlreadyExists",
+				fmt.Sprintf("The domain %s already exists.", domain))
+		}
+	}
+	id := f.nextID("de")
+	f.domains[id] = &fakeDomainExtension{
+		lbId: lbId,
+		port: port,
+		ext: model.DomainExtension{
+			DomainExtensionId:   id,
+			Domain:              domain,
+			ServerCertificateId: certId,
+		},
+	}
+	return nil
+}
+
+func (f *FakeCloud) DeleteDomainExtension(ctx context.Context, id string) error {
+	err := f.begin("DeleteDomainExtension")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.domains[id]; !ok {
+		return notFoundError("DeleteDomainExtension", "DomainExtensionNotExist", "DomainExtensionId", id)
+	}
+	delete(f.domains, id)
+	return nil
+}
+
+func (f *FakeCloud) SetDomainExtensionAttribute(ctx context.Context, id string, certId string) error {
+	err := f.begin("SetDomainExtensionAttribute")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	d, ok := f.domains[id]
+	if !ok {
+		return notFoundError("SetDomainExtensionAttribute", "DomainExtensionNotExist", "DomainExtensionId", id)
+	}
+	d.ext.ServerCertificateId = certId
+	return nil
+}
+
+// Access Control List
+
+func (f *FakeCloud) CreateAccessControlList(ctx context.Context, acl *model.AccessControlList) (string, error) {
+	err := f.begin("CreateAccessControlList")
+	defer f.lock.Unlock()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range f.acls {
+		if a.acl.AclName == acl.AclName {
+			return "", invalidParameterError("CreateAccessControlList", "AclNameExist",
+				fmt.Sprintf("The acl name %s already exists.", acl.AclName))
+		}
+	}
+	a := model.AccessControlList{
+		AclId:            f.nextID("acl"),
+		AclName:          acl.AclName,
+		AddressIPVersion: acl.AddressIPVersion,
+		ResourceGroupId:  acl.ResourceGroupId,
+		Tags:             copyTags(acl.Tags),
+	}
+	if a.AddressIPVersion == "" {
+		a.AddressIPVersion = model.IPv4
+	}
+	f.acls[a.AclId] = &fakeACL{acl: a}
+	return a.AclId, nil
+}
+
+func (f *FakeCloud) DescribeAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error) {
+	err := f.begin("DescribeAccessControlLists")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var ret []model.AccessControlList
+	for _, a := range f.acls {
+		if !hasAllTags(a.acl.Tags, tags) {
+			continue
+		}
+		acl := a.acl
+		acl.Entries = nil
+		acl.Tags = copyTags(a.acl.Tags)
+		ret = append(ret, acl)
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) getACL(api, aclId string) (*fakeACL, error) {
+	a, ok := f.acls[aclId]
+	if !ok {
+		return nil, notFoundError(api, "AclNotExist", "AclId", aclId)
+	}
+	return a, nil
+}
+
+func (f *FakeCloud) DescribeAccessControlListAttribute(ctx context.Context, aclId string) (*model.AccessControlList, error) {
+	err := f.begin("DescribeAccessControlListAttribute")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	a, err := f.getACL("DescribeAccessControlListAttribute", aclId)
+	if err != nil {
+		return nil, err
+	}
+	acl := a.acl
+	acl.Entries = append([]model.AclEntry(nil), a.acl.Entries...)
+	acl.Tags = copyTags(a.acl.Tags)
+	return &acl, nil
+}
+
+func (f *FakeCloud) AddAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	err := f.begin("AddAccessControlListEntry")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	a, err := f.getACL("AddAccessControlListEntry", aclId)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		for _, old := range a.acl.Entries {
+			if old.Entry == e.Entry {
+				return invalidParameterError("AddAccessControlListEntry", "AclEntryExist",
+					fmt.Sprintf("The acl entry %s already exists.", e.Entry))
+			}
+		}
+	}
+	a.acl.Entries = append(a.acl.Entries, entries...)
+	return nil
+}
+
+func (f *FakeCloud) RemoveAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	err := f.begin("RemoveAccessControlListEntry")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	a, err := f.getACL("RemoveAccessControlListEntry", aclId)
+	if err != nil {
+		return err
+	}
+	var ret []model.AclEntry
+	for _, old := range a.acl.Entries {
+		found := false
+		for _, e := range entries {
+			if old.Entry == e.Entry {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, old)
+		}
+	}
+	a.acl.Entries = ret
+	return nil
+}
+
+func (f *FakeCloud) DeleteAccessControlList(ctx context.Context, aclId string) error {
+	err := f.begin("DeleteAccessControlList")
+	defer f.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, err := f.getACL("DeleteAccessControlList", aclId); err != nil {
+		return err
+	}
+	for _, lb := range f.clbs {
+		for _, l := range lb.listeners {
+			if l.AclStatus == model.OnFlag && l.AclId == aclId {
+				return NewAPIError("DeleteAccessControlList", http.StatusBadRequest, "AclInUsed",
+					fmt.Sprintf("The acl %s is used by listener %s:%d.", aclId, l.Protocol, l.ListenerPort))
+			}
+		}
+	}
+	delete(f.acls, aclId)
+	return nil
+}
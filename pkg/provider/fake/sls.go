@@ -0,0 +1,17 @@
+package fake
+
+import (
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/sls"
+)
+
+func (f *FakeCloud) AnalyzeProductLog(request *sls.AnalyzeProductLogRequest) (*sls.AnalyzeProductLogResponse, error) {
+	err := f.begin("AnalyzeProductLog")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	resp := sls.CreateAnalyzeProductLogResponse()
+	resp.RequestId = "fake-request-id"
+	resp.Success = "true"
+	return resp, nil
+}
@@ -0,0 +1,293 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/model"
+	prvd "k8s.io/cloud-provider-alibaba-cloud/pkg/provider"
+	"k8s.io/cloud-provider-alibaba-cloud/pkg/provider/alibaba/util"
+)
+
+const (
+	DefaultRouteTableID = "vtb-fake"
+	DefaultVpcCIDR      = "192.168.0.0/16"
+	DefaultVpcIPv6CIDR  = "2408:4005:3ff::/56"
+)
+
+type fakeRoute struct {
+	region     string
+	instanceID string
+	cidr       string
+}
+
+type fakeVSwitch struct {
+	vsw vpc.VSwitch
+}
+
+// AddVSwitch adds a vswitch to the fake cloud, the vpc id is DefaultVpcID if it is empty.
+func (f *FakeCloud) AddVSwitch(vsw vpc.VSwitch) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if vsw.VpcId == "" {
+		vsw.VpcId = DefaultVpcID
+	}
+	f.vswitches[vsw.VSwitchId] = &fakeVSwitch{vsw: vsw}
+}
+
+// RouteCount returns the number of route entries in table.
+func (f *FakeCloud) RouteCount(table string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.routes[table])
+}
+
+func (f *FakeCloud) routeTable(table string) ([]*fakeRoute, error) {
+	if table != DefaultRouteTableID {
+		if _, ok := f.routes[table]; !ok {
+			return nil, notFoundError("DescribeRouteEntryList", "InvalidRouteTableId.NotFound", "RouteTableId", table)
+		}
+	}
+	return f.routes[table], nil
+}
+
+func (f *FakeCloud) toRoute(r *fakeRoute) *model.Route {
+	pvid := util.ProviderIDFromInstance(r.region, r.instanceID)
+	return &model.Route{
+		Name:            fmt.Sprintf("%s-%s", pvid, r.cidr),
+		DestinationCIDR: r.cidr,
+		ProviderId:      pvid,
+	}
+}
+
+func (f *FakeCloud) createRoute(table, provideID, cidr string) (*model.Route, string, error) {
+	region, instance, err := util.NodeFromProviderID(provideID)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid provide id: %v, err: %v", provideID, err)
+	}
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return nil, "", invalidParameterError("CreateRouteEntry", "InvalidDestinationCidrBlock.Malformed",
+			fmt.Sprintf("The specified DestinationCidrBlock %s is malformed.", cidr))
+	}
+	routes, err := f.routeTable(table)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, r := range routes {
+		if r.cidr == cidr {
+			return nil, "", invalidParameterError("CreateRouteEntry", "InvalidCIDRBlock.Duplicate",
+				fmt.Sprintf("The specified route entry %s already exists.", cidr))
+		}
+	}
+	r := &fakeRoute{region: region, instanceID: instance, cidr: cidr}
+	f.routes[table] = append(routes, r)
+	return f.toRoute(r), f.nextID("rte"), nil
+}
+
+func (f *FakeCloud) deleteRoute(table, provideID, cidr string) (bool, error) {
+	_, instance, err := util.NodeFromProviderID(provideID)
+	if err != nil {
+		return false, fmt.Errorf("invalid provide id: %v, err: %v", provideID, err)
+	}
+	routes, err := f.routeTable(table)
+	if err != nil {
+		return false, err
+	}
+	for i, r := range routes {
+		if r.cidr == cidr && r.instanceID == instance {
+			f.routes[table] = append(routes[:i:i], routes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *FakeCloud) CreateRoute(ctx context.Context, table string, provideID string, destinationCIDR string) (*model.Route, error) {
+	err := f.begin("CreateRoute")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("error create route entry for %s, %s, error: %v", provideID, destinationCIDR, err)
+	}
+	route, _, err := f.createRoute(table, provideID, destinationCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("error create route entry for %s, %s, error: %v", provideID, destinationCIDR, err)
+	}
+	return route, nil
+}
+
+func (f *FakeCloud) CreateRoutes(ctx context.Context, table string, routes []*model.Route) ([]string, []prvd.RouteUpdateStatus, error) {
+	err := f.begin("CreateRoutes")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(routes) == 0 {
+		return nil, nil, nil
+	}
+	var ids []string
+	var statuses []prvd.RouteUpdateStatus
+	for _, r := range routes {
+		_, id, err := f.createRoute(table, r.ProviderId, r.DestinationCIDR)
+		if err != nil {
+			code, message := errorCodeAndMessage(err)
+			statuses = append(statuses, prvd.RouteUpdateStatus{
+				Route: r, Failed: true, FailedCode: code, FailedMessage: message,
+			})
+			continue
+		}
+		ids = append(ids, id)
+		statuses = append(statuses, prvd.RouteUpdateStatus{Route: r})
+	}
+	return ids, statuses, nil
+}
+
+func (f *FakeCloud) DeleteRoute(ctx context.Context, table, provideID, destinationCIDR string) error {
+	err := f.begin("DeleteRoute")
+	defer f.lock.Unlock()
+	if err != nil {
+		return fmt.Errorf("error delete route entry for %s, %s, error: %v", provideID, destinationCIDR, err)
+	}
+	// route already removed is not an error
+	_, err = f.deleteRoute(table, provideID, destinationCIDR)
+	return err
+}
+
+func (f *FakeCloud) DeleteRoutes(ctx context.Context, table string, routes []*model.Route) ([]prvd.RouteUpdateStatus, error) {
+	err := f.begin("DeleteRoutes")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	var statuses []prvd.RouteUpdateStatus
+	for _, r := range routes {
+		found, err := f.deleteRoute(table, r.ProviderId, r.DestinationCIDR)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			statuses = append(statuses, prvd.RouteUpdateStatus{
+				Route: r, Failed: true, FailedCode: "InvalidRouteEntry.NotFound",
+				FailedMessage: fmt.Sprintf("The specified route entry %s does not exist.", r.DestinationCIDR),
+			})
+			continue
+		}
+		statuses = append(statuses, prvd.RouteUpdateStatus{Route: r})
+	}
+	return statuses, nil
+}
+
+func (f *FakeCloud) ListRoute(ctx context.Context, table string) ([]*model.Route, error) {
+	err := f.begin("ListRoute")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("table %s get route entries error ,err %s", table, err.Error())
+	}
+	routes, err := f.routeTable(table)
+	if err != nil {
+		return nil, fmt.Errorf("table %s get route entries error ,err %s", table, err.Error())
+	}
+	var ret []*model.Route
+	for _, r := range routes {
+		ret = append(ret, f.toRoute(r))
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) FindRoute(ctx context.Context, table, pvid, cidr string) (*model.Route, error) {
+	err := f.begin("FindRoute")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("error describe route entry list: %v", err)
+	}
+	instance := ""
+	if pvid != "" {
+		_, instance, err = util.NodeFromProviderID(pvid)
+		if err != nil {
+			return nil, fmt.Errorf("invalid provide id: %v, err: %v", pvid, err)
+		}
+	}
+	routes, err := f.routeTable(table)
+	if err != nil {
+		return nil, fmt.Errorf("error describe route entry list: %v", err)
+	}
+	for _, r := range routes {
+		if (instance == "" || r.instanceID == instance) && (cidr == "" || r.cidr == cidr) {
+			return f.toRoute(r), nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *FakeCloud) ListRouteTables(ctx context.Context, vpcID string) ([]string, error) {
+	err := f.begin("ListRouteTables")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("error describe vpc: %v route tables, error: %v", vpcID, err)
+	}
+	tables := []string{DefaultRouteTableID}
+	for t := range f.routes {
+		if t != DefaultRouteTableID {
+			tables = append(tables, t)
+		}
+	}
+	return tables, nil
+}
+
+func (f *FakeCloud) DescribeEipAddresses(ctx context.Context, instanceType string, instanceId string) ([]string, error) {
+	err := f.begin("DescribeEipAddresses")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return []string{}, nil
+}
+
+func (f *FakeCloud) DescribeVSwitches(ctx context.Context, vpcID string) ([]vpc.VSwitch, error) {
+	err := f.begin("DescribeVSwitches")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var ret []vpc.VSwitch
+	for _, vsw := range f.vswitches {
+		if vsw.vsw.VpcId == vpcID {
+			ret = append(ret, vsw.vsw)
+		}
+	}
+	return ret, nil
+}
+
+func (f *FakeCloud) DescribeVswitchByID(ctx context.Context, vswId string) (vpc.VSwitch, error) {
+	err := f.begin("DescribeVswitchByID")
+	defer f.lock.Unlock()
+	if err != nil {
+		return vpc.VSwitch{}, err
+	}
+	vsw, ok := f.vswitches[vswId]
+	if !ok {
+		return vpc.VSwitch{}, fmt.Errorf("vsw %s not found", vswId)
+	}
+	return vsw.vsw, nil
+}
+
+func (f *FakeCloud) DescribeVpcCIDRBlock(ctx context.Context, vpcId string, ipVersion model.AddressIPVersionType) ([]*net.IPNet, error) {
+	err := f.begin("DescribeVpcCIDRBlock")
+	defer f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	cidr := DefaultVpcCIDR
+	if ipVersion == model.IPv6 {
+		cidr = DefaultVpcIPv6CIDR
+	}
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	return []*net.IPNet{ipNet}, nil
+}
@@ -80,8 +80,24 @@ type NodeAttribute struct {
 	SpotStrategy              string
 	PrimaryNetworkInterfaceID string
 	Tags                      map[string]string
+	// Status is the status of the instance, e.g. Running, Stopping, Stopped
+	Status string
+	// ExpiredTime is the time when a subscription instance expires, it is zero for pay-as-you-go instances
+	ExpiredTime time.Time
+	// LockReasons are the reasons of the operation locks on the instance, e.g. Recycling is set on
+	// a spot instance which is about to be reclaimed
+	LockReasons []string
 }
 
+const (
+	InstanceStatusRunning  = "Running"
+	InstanceStatusStopping = "Stopping"
+	InstanceStatusStopped  = "Stopped"
+
+	InstanceChargeTypePrePaid = "PrePaid"
+	InstanceLockRecycling     = "Recycling"
+)
+
 type EniAttribute struct {
 	NetworkInterfaceID string
 	Status             string
@@ -93,9 +109,9 @@ type IInstance interface {
 	ListInstances(ctx context.Context, ids []string) (map[string]*NodeAttribute, error)
 	GetInstancesByIP(ctx context.Context, ips []string) (*NodeAttribute, error)
 	// DescribeNetworkInterfaces query one or more elastic network interfaces (ENIs)
-	DescribeNetworkInterfaces(vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error)
-	DescribeNetworkInterfacesByIDs(ids []string) ([]*EniAttribute, error)
-	ModifyNetworkInterfaceSourceDestCheck(id string, enabled bool) error
+	DescribeNetworkInterfaces(ctx context.Context, vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error)
+	DescribeNetworkInterfacesByIDs(ctx context.Context, ids []string) ([]*EniAttribute, error)
+	ModifyNetworkInterfaceSourceDestCheck(ctx context.Context, id string, enabled bool) error
 	CreateSecurityGroup(ctx context.Context, sg ecsmodel.SecurityGroup) error
 	DescribeSecurityGroups(ctx context.Context, tag []tag.Tag) ([]ecsmodel.SecurityGroup, error)
 	DescribeSecurityGroupAttribute(ctx context.Context, sgId string) (ecsmodel.SecurityGroup, error)
@@ -130,6 +146,8 @@ type IVPC interface {
 type ILoadBalancer interface {
 	// LoadBalancer
 	FindLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error
+	// ListLoadBalancers lists the clbs having any of the tags
+	ListLoadBalancers(ctx context.Context, tags []tag.Tag) ([]*model.LoadBalancer, error)
 	CreateLoadBalancer(ctx context.Context, mdl *model.LoadBalancer, clientToken string) error
 	DescribeLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error
 	DeleteLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error
@@ -163,6 +181,7 @@ type ILoadBalancer interface {
 	RemoveVServerGroupBackendServers(ctx context.Context, vGroupId string, backends string) error
 	SetVServerGroupAttribute(ctx context.Context, vGroupId string, backends string) error
 	ModifyVServerGroupBackendServers(ctx context.Context, vGroupId string, old string, new string) error
+	DescribeHealthStatus(ctx context.Context, lbId string) ([]model.BackendHealthStatus, error)
 
 	// Tag
 	TagCLBResource(ctx context.Context, resourceId string, tags []tag.Tag) error
@@ -177,6 +196,14 @@ type ILoadBalancer interface {
 	CreateDomainExtension(ctx context.Context, lbId string, port int, domain string, certId string) error
 	DeleteDomainExtension(ctx context.Context, id string) error
 	SetDomainExtensionAttribute(ctx context.Context, id string, certId string) error
+
+	// Access Control List
+	CreateAccessControlList(ctx context.Context, acl *model.AccessControlList) (string, error)
+	DescribeAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error)
+	DescribeAccessControlListAttribute(ctx context.Context, aclId string) (*model.AccessControlList, error)
+	AddAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error
+	RemoveAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error
+	DeleteAccessControlList(ctx context.Context, aclId string) error
 }
 
 type IPrivateZone interface {
@@ -193,11 +220,14 @@ type ISLS interface {
 type ICAS interface {
 	DescribeSSLCertificatePublicKeyDetail(ctx context.Context, certId string) (*model.CertificateInfo, error)
 	DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error)
+	UploadUserCertificate(ctx context.Context, name, cert, key string, tags map[string]string) (int64, error)
+	DeleteUserCertificate(ctx context.Context, certId int64) error
+	ListUserCertificateTags(ctx context.Context, certId int64) (map[string]string, error)
 }
 
 type IALB interface {
-	DescribeALBZones(request *alb.DescribeZonesRequest) (response *alb.DescribeZonesResponse, err error)
-	TagALBResources(request *alb.TagResourcesRequest) (response *alb.TagResourcesResponse, err error)
+	DescribeALBZones(ctx context.Context, request *alb.DescribeZonesRequest) (response *alb.DescribeZonesResponse, err error)
+	TagALBResources(ctx context.Context, request *alb.TagResourcesRequest) (response *alb.TagResourcesResponse, err error)
 	// ApplicationLoadBalancer
 	CreateALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, trackingProvider tracking.TrackingProvider) (albmodel.LoadBalancerStatus, error)
 	ReuseALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, lbID string, trackingProvider tracking.TrackingProvider) (albmodel.LoadBalancerStatus, error)
@@ -222,6 +252,7 @@ type IALB interface {
 	RegisterALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error
 	DeregisterALBServers(ctx context.Context, serverGroupID string, sdkServers []alb.BackendServer) error
 	ReplaceALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem, sdkServers []alb.BackendServer) error
+	UpdateALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error
 	ListALBServers(ctx context.Context, serverGroupID string) ([]alb.BackendServer, error)
 
 	// ALB ServerGroup
@@ -241,6 +272,8 @@ type INLB interface {
 	ListNLBTagResources(ctx context.Context, lbId string) ([]tag.Tag, error)
 	// NetworkLoadBalancer
 	FindNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error
+	// ListNLBs lists the nlbs having all the tags
+	ListNLBs(ctx context.Context, tags []tag.Tag) ([]*nlbmodel.NetworkLoadBalancer, error)
 	DescribeNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error
 	CreateNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer, clientToken string) error
 	DeleteNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error
@@ -279,6 +312,7 @@ type INLB interface {
 	CreateNLBListenerAsync(ctx context.Context, lbId string, lis *nlbmodel.ListenerAttribute) (string, error)
 	UpdateNLBListenerAsync(ctx context.Context, lis *nlbmodel.ListenerAttribute) (string, error)
 	DeleteNLBListenerAsync(ctx context.Context, listenerId string) (string, error)
+	GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error)
 
 	// Certificates
 	ListNLBListenerCertificates(ctx context.Context, listenerId string) ([]nlbmodel.ListenerCertificate, error)
@@ -287,7 +321,7 @@ type INLB interface {
 
 	// Jobs
 	BatchWaitJobsFinish(ctx context.Context, api string, jobIds []string, args ...time.Duration) error
-	WaitJobFinish(api, jobId string, args ...time.Duration) error
+	WaitJobFinish(ctx context.Context, api, jobId string, args ...time.Duration) error
 }
 
 // EFLONodeAttribute EFLO node attribute from cloud LingJun instance
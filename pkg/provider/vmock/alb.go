@@ -20,11 +20,11 @@ type MockALB struct {
 	auth *base.ClientMgr
 }
 
-func (p MockALB) TagALBResources(request *albsdk.TagResourcesRequest) (response *albsdk.TagResourcesResponse, err error) {
+func (p MockALB) TagALBResources(ctx context.Context, request *albsdk.TagResourcesRequest) (response *albsdk.TagResourcesResponse, err error) {
 	return nil, nil
 }
 
-func (p MockALB) DescribeALBZones(request *albsdk.DescribeZonesRequest) (response *albsdk.DescribeZonesResponse, err error) {
+func (p MockALB) DescribeALBZones(ctx context.Context, request *albsdk.DescribeZonesRequest) (response *albsdk.DescribeZonesResponse, err error) {
 	return nil, nil
 }
 func (p MockALB) CreateALB(ctx context.Context, resLB *albmodel.AlbLoadBalancer, trackingProvider tracking.TrackingProvider) (albmodel.LoadBalancerStatus, error) {
@@ -88,6 +88,9 @@ func (p MockALB) DeregisterALBServers(ctx context.Context, serverGroupID string,
 func (p MockALB) ReplaceALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem, sdkServers []albsdk.BackendServer) error {
 	return nil
 }
+func (p MockALB) UpdateALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	return nil
+}
 func (p MockALB) ListALBServers(ctx context.Context, serverGroupID string) ([]albsdk.BackendServer, error) {
 	return nil, nil
 }
@@ -24,3 +24,15 @@ func (c MockCAS) DescribeSSLCertificatePublicKeyDetail(ctx context.Context, cert
 func (c MockCAS) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
 	return nil, nil
 }
+
+func (c MockCAS) UploadUserCertificate(ctx context.Context, name, cert, key string, tags map[string]string) (int64, error) {
+	return 0, nil
+}
+
+func (c MockCAS) DeleteUserCertificate(ctx context.Context, certId int64) error {
+	return nil
+}
+
+func (c MockCAS) ListUserCertificateTags(ctx context.Context, certId int64) (map[string]string, error) {
+	return nil, nil
+}
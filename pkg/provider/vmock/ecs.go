@@ -54,6 +54,7 @@ func (d *MockECS) ListInstances(ctx context.Context, ids []string) (map[string]*
 			Tags: map[string]string{
 				tagKeyNodePoolID: NodePoolID,
 			},
+			Status: prvd.InstanceStatusRunning,
 		}
 	}
 	return mins, nil
@@ -63,7 +64,7 @@ func (d *MockECS) GetInstancesByIP(ctx context.Context, ips []string) (*prvd.Nod
 	return nil, nil
 }
 
-func (d *MockECS) DescribeNetworkInterfaces(vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error) {
+func (d *MockECS) DescribeNetworkInterfaces(ctx context.Context, vpcId string, ips []string, ipVersionType model.AddressIPVersionType) (map[string]string, error) {
 	eniids := make(map[string]string)
 	for _, ip := range ips {
 		eniids[ip] = "eni-id"
@@ -71,7 +72,7 @@ func (d *MockECS) DescribeNetworkInterfaces(vpcId string, ips []string, ipVersio
 	return eniids, nil
 }
 
-func (d *MockECS) DescribeNetworkInterfacesByIDs(ids []string) ([]*prvd.EniAttribute, error) {
+func (d *MockECS) DescribeNetworkInterfacesByIDs(ctx context.Context, ids []string) ([]*prvd.EniAttribute, error) {
 	var ret []*prvd.EniAttribute
 	for _, i := range ids {
 		ret = append(ret, &prvd.EniAttribute{
@@ -84,7 +85,7 @@ func (d *MockECS) DescribeNetworkInterfacesByIDs(ids []string) ([]*prvd.EniAttri
 	return ret, nil
 }
 
-func (d *MockECS) ModifyNetworkInterfaceSourceDestCheck(id string, enabled bool) error {
+func (d *MockECS) ModifyNetworkInterfaceSourceDestCheck(ctx context.Context, id string, enabled bool) error {
 	return nil
 }
 
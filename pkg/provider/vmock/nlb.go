@@ -29,6 +29,10 @@ func (m MockNLB) DeleteNLBListenerAsync(ctx context.Context, listenerId string)
 	return "job-delete-listener", nil
 }
 
+func (m MockNLB) GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error) {
+	return nil, nil
+}
+
 func (m MockNLB) UpdateNLBListenerAsync(ctx context.Context, lis *nlbmodel.ListenerAttribute) (string, error) {
 	return "job-update-listener", nil
 }
@@ -95,6 +99,10 @@ func (m MockNLB) FindNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer)
 	return nil
 }
 
+func (m MockNLB) ListNLBs(ctx context.Context, tags []tag.Tag) ([]*nlbmodel.NetworkLoadBalancer, error) {
+	return nil, nil
+}
+
 func (m MockNLB) DescribeNLB(ctx context.Context, mdl *nlbmodel.NetworkLoadBalancer) error {
 	mdl.LoadBalancerAttribute.LoadBalancerId = ExistNLBID
 	mdl.LoadBalancerAttribute.Name = "nlb-name"
@@ -408,7 +416,7 @@ func (m MockNLB) BatchWaitJobsFinish(ctx context.Context, api string, jobIds []s
 	return nil
 }
 
-func (m MockNLB) WaitJobFinish(api, jobId string, args ...time.Duration) error {
+func (m MockNLB) WaitJobFinish(ctx context.Context, api, jobId string, args ...time.Duration) error {
 	return nil
 }
 
@@ -48,6 +48,10 @@ func (m *MockCLB) FindLoadBalancer(ctx context.Context, mdl *model.LoadBalancer)
 	}
 	return nil
 }
+func (m *MockCLB) ListLoadBalancers(ctx context.Context, tags []tag.Tag) ([]*model.LoadBalancer, error) {
+	return nil, nil
+}
+
 func (m *MockCLB) CreateLoadBalancer(ctx context.Context, mdl *model.LoadBalancer, clientToken string) error {
 	mdl.LoadBalancerAttribute.LoadBalancerId = "lb-new-created-id"
 	return nil
@@ -454,6 +458,10 @@ func (m *MockCLB) ModifyVServerGroupBackendServers(ctx context.Context, vGroupId
 	return nil
 }
 
+func (m *MockCLB) DescribeHealthStatus(ctx context.Context, lbId string) ([]model.BackendHealthStatus, error) {
+	return nil, nil
+}
+
 func (m *MockCLB) DescribeServerCertificateById(ctx context.Context, serverCertificateId string) (*model.CertAttribute, error) {
 	return nil, nil
 }
@@ -473,3 +481,27 @@ func (m *MockCLB) DeleteDomainExtension(ctx context.Context, id string) error {
 func (m *MockCLB) SetDomainExtensionAttribute(ctx context.Context, id string, certId string) error {
 	return nil
 }
+
+func (m *MockCLB) CreateAccessControlList(ctx context.Context, acl *model.AccessControlList) (string, error) {
+	return "acl-new-created-id", nil
+}
+
+func (m *MockCLB) DescribeAccessControlLists(ctx context.Context, tags []tag.Tag) ([]model.AccessControlList, error) {
+	return nil, nil
+}
+
+func (m *MockCLB) DescribeAccessControlListAttribute(ctx context.Context, aclId string) (*model.AccessControlList, error) {
+	return &model.AccessControlList{AclId: aclId}, nil
+}
+
+func (m *MockCLB) AddAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	return nil
+}
+
+func (m *MockCLB) RemoveAccessControlListEntry(ctx context.Context, aclId string, entries []model.AclEntry) error {
+	return nil
+}
+
+func (m *MockCLB) DeleteAccessControlList(ctx context.Context, aclId string) error {
+	return nil
+}
@@ -52,6 +52,7 @@ const (
 	RemoveALBServersFromServerGroup             = "RemoveALBServersFromServerGroup"
 	ReplaceALBServersInServerGroupAsynchronous  = "ReplaceALBServersInServerGroupAsynchronous"
 	ReplaceALBServersInServerGroup              = "ReplaceALBServersInServerGroup"
+	UpdateALBServersAttribute                   = "UpdateALBServersAttribute"
 )
 const (
 	// IngressClass
@@ -186,6 +187,7 @@ const (
 
 const (
 	IngressFinalizer = IngressTagKeyPrefix + "/resources"
+	GatewayFinalizer = IngressTagKeyPrefix + "/gateway-resources"
 )
 
 const (
@@ -194,12 +196,28 @@ const (
 
 const (
 	DefaultListenerFlag = "-listener-"
+	// AlbConfigDefaultActionFlag is the suffix of the owner name of the server groups forwarded by the
+	// default actions of the listeners of an AlbConfig
+	AlbConfigDefaultActionFlag = "-albconfig-default"
 )
 
 const (
 	RuleActionTypeFixedResponse string = "FixedResponse"
 	RuleActionTypeRedirect      string = "Redirect"
 	RuleActionTypeForward       string = "ForwardGroup"
+	RuleActionTypeRewrite       string = "Rewrite"
+	RuleActionTypeInsertHeader  string = "InsertHeader"
+	RuleActionTypeRemoveHeader  string = "RemoveHeader"
+	RuleActionTypeTrafficMirror string = "TrafficMirror"
+	RuleActionTypeTrafficLimit  string = "TrafficLimit"
+)
+
+const (
+	InsertHeaderValueTypeUserDefined     string = "UserDefined"
+	InsertHeaderValueTypeReferenceHeader string = "ReferenceHeader"
+	InsertHeaderValueTypeSystemDefined   string = "SystemDefined"
+
+	TrafficMirrorTargetTypeForwardGroupMirror string = "ForwardGroupMirror"
 )
 
 const (
@@ -209,6 +227,15 @@ const (
 	RuleConditionFieldQueryString string = "QueryString"
 	RuleConditionFieldMethod      string = "Method"
 	RuleConditionFieldCookie      string = "Cookie"
+
+	RuleConditionFieldSourceIp           string = "SourceIp"
+	RuleConditionFieldResponseHeader     string = "ResponseHeader"
+	RuleConditionFieldResponseStatusCode string = "ResponseStatusCode"
+)
+
+const (
+	RuleDirectionRequest  string = "Request"
+	RuleDirectionResponse string = "Response"
 )
 
 const (
@@ -245,6 +272,13 @@ const (
 	//The load balancer finds that the user has customized the cookie and will rewrite the original cookie. The next time the client visits with a new cookie, the load balancer service will direct the request to the back-end server that was previously recorded.
 	DefaultServerGroupStickySessionType = ServerGroupStickySessionTypeInsert
 
+	// Connection drain timeout. Unit: second
+	// Value: 0~900
+	DefaultServerGroupConnectionDrainTimeout = 300
+	// Slow start duration. Unit: second
+	// Value: 30~900
+	DefaultServerGroupSlowStartDuration = 30
+
 	DefaultLoadBalancerAddressType                        string = LoadBalancerAddressTypeInternet
 	DefaultLoadBalancerAddressAllocatedMode               string = LoadBalancerAddressAllocatedModeDynamic
 	DefaultLoadBalancerEdition                            string = LoadBalancerEditionBasic
@@ -265,14 +299,19 @@ const (
 	ServerGroupSchedulerWrr = "Wrr"
 	ServerGroupSchedulerWlc = "Wlc"
 	ServerGroupSchedulerSch = "Sch"
+	ServerGroupSchedulerUch = "Uch"
+
+	ServerGroupUchConfigTypeQueryString = "QueryString"
 
 	ServerGroupProtocolHTTP  = "HTTP"
 	ServerGroupProtocolHTTPS = "HTTPS"
+	ServerGroupProtocolGRPC  = "gRPC"
 
 	ServerGroupHealthCheckMethodGET     = "GET"
 	ServerGroupHealthCheckMethodHEAD    = "HEAD"
 	ServerGroupHealthCheckProtocolHTTP  = "HTTP"
 	ServerGroupHealthCheckProtocolHTTPS = "HTTPS"
+	ServerGroupHealthCheckProtocolGRPC  = "gRPC"
 	ServerGroupHealthCheckCodes2xx      = "http_2xx"
 	ServerGroupHealthCheckCodes3xx      = "http_3xx"
 	ServerGroupHealthCheckCodes4xx      = "http_4xx"
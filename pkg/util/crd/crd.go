@@ -75,6 +75,8 @@ type Interface interface {
 	// is present at regular intervals until it timesout, in case of timeout
 	// will return an error.
 	WaitToBePresent(name string, timeout time.Duration) error
+	// IsPresent will return an error if the CRD is not present.
+	IsPresent(name string) error
 	// Delete will delete the CRD.
 	Delete(name string) error
 }
@@ -188,6 +190,18 @@ func (c *Client) WaitToBePresent(name string, timeout time.Duration) error {
 	}
 }
 
+// IsPresent satisfies crd.Interface.
+func (c *Client) IsPresent(name string) error {
+	_, err := c.client.
+		ApiextensionsV1beta1().
+		CustomResourceDefinitions().
+		Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get crd %s: %s", name, err.Error())
+	}
+	return nil
+}
+
 // Delete satisfies crd.Interface.
 func (c *Client) Delete(name string) error {
 	err := c.validateCRD()
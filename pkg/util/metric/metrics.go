@@ -29,6 +29,9 @@ type OperationResult string
 var (
 	ResultFail    = "Fail"
 	ResultSuccess = "Success"
+	// ResultStale and ResultDryRun are the results of the stale routes found and kept in dry run mode
+	ResultStale  = "Stale"
+	ResultDryRun = "DryRun"
 )
 
 var (
@@ -53,6 +56,15 @@ var (
 		},
 		[]string{"verb"},
 	)
+
+	// RouteGC counts the stale routes found and cleaned up by the route controller for each result
+	RouteGC = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ccm_route_gc_total",
+			Help: "CCM stale routes garbage collected for each result",
+		},
+		[]string{"result"},
+	)
 	// SLBLatency reconcile SLB latency
 	SLBLatency = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -72,6 +84,72 @@ var (
 		},
 		[]string{"type", "verb", "status"},
 	)
+
+	// OpenAPIRequests counts the openapi calls
+	OpenAPIRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ccm_openapi_requests_total",
+			Help: "CCM openapi calls for each product and api",
+		},
+		[]string{"product", "api"},
+	)
+
+	// OpenAPILatency the latency of openapi calls, including the time waiting for the rate limiter which
+	// is recorded by OpenAPIRateLimitWait as well
+	OpenAPILatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ccm_openapi_latencies_duration_milliseconds",
+			Help:    "CCM openapi call latency distribution in milliseconds for each product and api.",
+			Buckets: []float64{10, 50, 100, 200, 300, 500, 700, 1000, 2000, 3000, 5000, 10000, 20000},
+		},
+		[]string{"product", "api"},
+	)
+
+	// OpenAPIErrors counts the failed openapi calls by the error code
+	OpenAPIErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ccm_openapi_errors_total",
+			Help: "CCM openapi failed calls for each product, api and error code",
+		},
+		[]string{"product", "api", "code"},
+	)
+
+	// OpenAPIRateLimitWait the time openapi calls wait for the client side rate limiter
+	OpenAPIRateLimitWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ccm_openapi_rate_limit_wait_duration_milliseconds",
+			Help:    "CCM openapi rate limiter wait time distribution in milliseconds for each product and priority.",
+			Buckets: []float64{0, 10, 50, 100, 200, 500, 1000, 2000, 5000, 10000, 30000, 60000},
+		},
+		[]string{"product", "priority"},
+	)
+
+	// OpenAPIThrottled counts the openapi calls throttled by the server
+	OpenAPIThrottled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ccm_openapi_throttled_total",
+			Help: "CCM openapi calls rejected with throttling errors",
+		},
+		[]string{"product", "api"},
+	)
+
+	// OpenAPIRateLimitQPS the current qps of the client side rate limiter
+	OpenAPIRateLimitQPS = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ccm_openapi_rate_limit_qps",
+			Help: "CCM openapi rate limiter current qps after adapting to throttling errors",
+		},
+		[]string{"bucket"},
+	)
+
+	// DriftDetected the number of changes made out of the controller to the loadbalancer of each service
+	DriftDetected = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ccm_drift_detected",
+			Help: "CCM number of drifted cloud resources of the loadbalancer of each service",
+		},
+		[]string{"type", "namespace", "service"},
+	)
 )
 
 // MsSince returns milliseconds since start.
@@ -92,7 +170,15 @@ func UniqueServiceCnt(uid string) float64 {
 // RegisterPrometheus register metrics to prometheus server
 func RegisterPrometheus() {
 	metrics.Registry.MustRegister(RouteLatency)
+	metrics.Registry.MustRegister(RouteGC)
 	metrics.Registry.MustRegister(NodeLatency)
 	metrics.Registry.MustRegister(SLBLatency)
 	metrics.Registry.MustRegister(SLBOperationStatus)
+	metrics.Registry.MustRegister(OpenAPIRequests)
+	metrics.Registry.MustRegister(OpenAPILatency)
+	metrics.Registry.MustRegister(OpenAPIErrors)
+	metrics.Registry.MustRegister(OpenAPIRateLimitWait)
+	metrics.Registry.MustRegister(OpenAPIThrottled)
+	metrics.Registry.MustRegister(OpenAPIRateLimitQPS)
+	metrics.Registry.MustRegister(DriftDetected)
 }
@@ -0,0 +1,14 @@
+//go:build !zipkin
+
+package tracing
+
+import (
+	"fmt"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newExporter fails as no exporter is built in without the zipkin build tag
+func newExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	return nil, fmt.Errorf("export spans to %s: built without the zipkin build tag", endpoint)
+}
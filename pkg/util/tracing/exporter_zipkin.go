@@ -0,0 +1,19 @@
+//go:build zipkin
+
+package tracing
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/zipkin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newExporter exports the spans to the zipkin compatible endpoint
+func newExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	exporter, err := zipkin.New(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("create zipkin exporter: %s", err.Error())
+	}
+	return exporter, nil
+}
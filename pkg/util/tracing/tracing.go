@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cloud-provider-alibaba-cloud/version"
+)
+
+const (
+	TracerName  = "k8s.io/cloud-provider-alibaba-cloud"
+	ServiceName = "cloud-controller-manager"
+)
+
+// propagator passes the span context from the provider calls to the openapi transport through the
+// headers of the requests, as the legacy sdk does not take a context.
+var propagator = propagation.TraceContext{}
+
+// Setup installs the global tracer provider which exports the spans to a zipkin compatible endpoint,
+// e.g. http://otel-collector:9411/api/v2/spans. All the spans are no-op if the endpoint is empty.
+// The exporter is only built in with the zipkin build tag, Setup fails on a non-empty endpoint otherwise.
+func Setup(endpoint string, sampleRatio float64, clusterID string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := newExporter(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+		semconv.ServiceVersion(version.Version),
+		attribute.String("k8s.cluster.id", clusterID),
+	)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagator)
+	return tp.Shutdown, nil
+}
+
+// Start starts a span which is a child of the span in ctx
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+// StartReconcile starts the span of a reconcile of the object of the kind, the spans of the openapi
+// calls made by the reconcile are its children.
+func StartReconcile(ctx context.Context, kind string, key types.NamespacedName) (context.Context, trace.Span) {
+	return Start(ctx, "Reconcile "+kind, trace.WithAttributes(
+		attribute.String("k8s.kind", kind),
+		attribute.String("k8s.namespace", key.Namespace),
+		attribute.String("k8s.name", key.Name),
+	))
+}
+
+// Background returns a background context carrying the span of ctx, for the requests which should
+// not be canceled with ctx
+func Background(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
+// End records the error of the span if any and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject writes the span context in ctx to the headers of a request of the legacy sdk
+func Inject(ctx context.Context, headers map[string]string) {
+	carrier := propagation.HeaderCarrier{}
+	propagator.Inject(ctx, carrier)
+	for k := range carrier {
+		// the headers are copied as is to the http request by the sdk
+		headers[k] = carrier.Get(k)
+	}
+}
+
+// Extract returns ctx with the span context in the headers if it has no span context
+func Extract(ctx context.Context, header http.Header) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
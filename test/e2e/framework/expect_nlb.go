@@ -803,7 +803,7 @@ func lookupENIsByIPVersion(f *Framework, servers []nlbmodel.ServerGroupServer, i
 		if len(ips) == 0 {
 			return make(map[string]string), nil
 		}
-		return f.Client.CloudClient.DescribeNetworkInterfaces(options.TestConfig.VPCID, ips, ipVersion)
+		return f.Client.CloudClient.DescribeNetworkInterfaces(context.TODO(), options.TestConfig.VPCID, ips, ipVersion)
 	}
 
 	// DualStack: separate by IP family and call DescribeNetworkInterfaces for each
@@ -827,7 +827,7 @@ func lookupENIsByIPVersion(f *Framework, servers []nlbmodel.ServerGroupServer, i
 		if len(pair.ips) == 0 {
 			continue
 		}
-		r, err := f.Client.CloudClient.DescribeNetworkInterfaces(options.TestConfig.VPCID, pair.ips, pair.ver)
+		r, err := f.Client.CloudClient.DescribeNetworkInterfaces(context.TODO(), options.TestConfig.VPCID, pair.ips, pair.ver)
 		if err != nil {
 			return nil, fmt.Errorf("call DescribeNetworkInterfaces: %w", err)
 		}
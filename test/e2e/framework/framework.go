@@ -250,7 +250,7 @@ func (f *Framework) CreateCloudResource() error {
 			return fmt.Errorf("DescribeAccessControlList error: %s", err.Error())
 		}
 		if aclId == "" {
-			aclId, err = f.Client.CloudClient.CreateAccessControlList(context.TODO(), aclName)
+			aclId, err = f.Client.CloudClient.CreateAccessControlList(context.TODO(), &model.AccessControlList{AclName: aclName})
 			if err != nil {
 				return fmt.Errorf("CreateAccessControlList error: %s", err.Error())
 			}
@@ -266,7 +266,7 @@ func (f *Framework) CreateCloudResource() error {
 			return fmt.Errorf("DescribeAccessControlList error: %s", err.Error())
 		}
 		if aclId == "" {
-			aclId, err = f.Client.CloudClient.CreateAccessControlList(context.TODO(), aclName)
+			aclId, err = f.Client.CloudClient.CreateAccessControlList(context.TODO(), &model.AccessControlList{AclName: aclName})
 			if err != nil {
 				return fmt.Errorf("CreateAccessControlList error: %s", err.Error())
 			}